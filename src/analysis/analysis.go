@@ -0,0 +1,196 @@
+package analysis
+
+import (
+	"math"
+)
+
+// Post-game analysis. A finished game is reviewed ply by ply: every position
+// in it is evaluated at a fixed depth, each move is charged the centipawns it
+// gave away against the engine's evaluation of the position it was played in,
+// and judged by that cost — best, good, inaccuracy, mistake, blunder, or a
+// forced mate let slip. Each side's accuracy is derived from the same numbers.
+// The worker (worker.go) does the engine work and stores the result; this file
+// is the arithmetic, which needs nothing but the evals.
+
+// Judgement is the verdict on one move. The values are stored, so they are
+// append-only.
+type Judgement int16
+
+const (
+	Best Judgement = iota
+	Good
+	Inaccuracy
+	Mistake
+	Blunder
+	MissedMate
+)
+
+// String names the judgement as the client keys it.
+func (j Judgement) String() string {
+	switch j {
+	case Best:
+		return "best"
+	case Good:
+		return "good"
+	case Inaccuracy:
+		return "inaccuracy"
+	case Mistake:
+		return "mistake"
+	case Blunder:
+		return "blunder"
+	case MissedMate:
+		return "missed-mate"
+	}
+	return ""
+}
+
+const (
+	// The centipawn loss each judgement starts at. A pawn is 100; a 4x4 board
+	// has so little material that a pawn given away is usually the game, so
+	// these sit a little under the familiar chess thresholds.
+	bestLoss       = 10
+	inaccuracyLoss = 50
+	mistakeLoss    = 100
+	blunderLoss    = 200
+
+	// lossCap bounds the loss charged for one move, so a move between two
+	// mate-ish scores counts as a very bad move rather than three hundred of
+	// them — the usual average-centipawn-loss convention.
+	lossCap = 1000
+
+	// mateScore is the eval past which a position is a forced mate: the engine
+	// scores a found mate far beyond the int16 range, and the review stores it
+	// saturated at ±32000, as the evaluator does.
+	mateScore = 30000
+
+	// winSlope is the logistic slope that turns centipawns into winning
+	// chances, the same curve the eval bar draws.
+	winSlope = 0.004
+)
+
+// Side is one player's score in a reviewed game.
+type Side struct {
+	// Accuracy is the mean of the side's per-move accuracies, 0-100.
+	Accuracy float32
+	// ACPL is the side's average centipawn loss per move.
+	ACPL int16
+
+	Inaccuracies int
+	Mistakes     int
+	Blunders     int
+	MissedMates  int
+}
+
+// Result is a reviewed game: the judgement of every ply, and each side's score.
+type Result struct {
+	Judgements []Judgement
+	White      Side
+	Black      Side
+}
+
+// Review judges a game from the evals of every position in it, white-positive
+// centipawns: evals[0] the starting position, evals[i] the position after ply
+// i. whiteFirst says who made the first move.
+func Review(evals []int16, whiteFirst bool) Result {
+	if len(evals) < 2 {
+		return Result{}
+	}
+	res := Result{Judgements: make([]Judgement, 0, len(evals)-1)}
+
+	var sums [2]struct {
+		loss, accuracy float64
+		moves          int
+	}
+	for ply := 1; ply < len(evals); ply++ {
+		white := whiteFirst == (ply%2 == 1)
+		before, after := float64(evals[ply-1]), float64(evals[ply])
+		if !white {
+			before, after = -before, -after
+		}
+
+		loss := math.Max(0, clampLoss(before)-clampLoss(after))
+		j := judge(loss, before, after)
+		res.Judgements = append(res.Judgements, j)
+
+		s := &sums[0]
+		if !white {
+			s = &sums[1]
+		}
+		s.loss += loss
+		s.accuracy += moveAccuracy(before, after)
+		s.moves++
+	}
+
+	res.White, res.Black = Tally(res.Judgements, whiteFirst)
+	for i, side := range []*Side{&res.White, &res.Black} {
+		s := sums[i]
+		if s.moves == 0 {
+			continue
+		}
+		side.ACPL = int16(math.Round(s.loss / float64(s.moves)))
+		side.Accuracy = float32(math.Round(10*s.accuracy/float64(s.moves)) / 10)
+	}
+	return res
+}
+
+// Tally counts each side's inaccuracies, mistakes, blunders and missed mates
+// from a game's judgements; whiteFirst says who made the first move. The
+// scores are left zero: they are stored, the counts are derived.
+func Tally(judgements []Judgement, whiteFirst bool) (white, black Side) {
+	for i, j := range judgements {
+		side := &white
+		if whiteFirst != (i%2 == 0) {
+			side = &black
+		}
+		switch j {
+		case Inaccuracy:
+			side.Inaccuracies++
+		case Mistake:
+			side.Mistakes++
+		case Blunder:
+			side.Blunders++
+		case MissedMate:
+			side.MissedMates++
+		}
+	}
+	return white, black
+}
+
+// judge classifies one move from its loss and the mover's evals around it. A
+// forced mate thrown away is its own verdict, whatever it cost: the mover may
+// well still be winning, but not by force.
+func judge(loss, before, after float64) Judgement {
+	switch {
+	case before >= mateScore && after < mateScore:
+		return MissedMate
+	case loss >= blunderLoss:
+		return Blunder
+	case loss >= mistakeLoss:
+		return Mistake
+	case loss >= inaccuracyLoss:
+		return Inaccuracy
+	case loss > bestLoss:
+		return Good
+	}
+	return Best
+}
+
+// clampLoss bounds an eval to ±lossCap for loss accounting.
+func clampLoss(cp float64) float64 {
+	return math.Max(-lossCap, math.Min(lossCap, cp))
+}
+
+// winChance is the mover's winning chances in percent at an eval from their
+// side.
+func winChance(cp float64) float64 {
+	return 50 + 50*(2/(1+math.Exp(-winSlope*cp))-1)
+}
+
+// moveAccuracy scores one move 0-100 by the winning chances it gave away, on
+// the exponential curve lichess fits its accuracy to: a move that keeps the
+// mover's chances scores 100, one that gives away a third of them about 20.
+func moveAccuracy(before, after float64) float64 {
+	drop := math.Max(0, winChance(before)-winChance(after))
+	acc := 103.1668*math.Exp(-0.04354*drop) - 3.1669
+	return math.Max(0, math.Min(100, acc))
+}
@@ -0,0 +1,83 @@
+package analysis
+
+import (
+	"testing"
+)
+
+// TestReviewJudgesByLoss walks a short game whose evals name each move's cost
+// and checks every verdict, the per-side tallies, and the average loss.
+func TestReviewJudgesByLoss(t *testing.T) {
+	evals := []int16{
+		0,    // start
+		-250, // 1. white gives away 250: blunder
+		-250, // 1... black holds: best
+		-310, // 2. white gives away 60: inaccuracy
+		-180, // 2... black gives away 130: mistake
+		-160, // 3. white gains: best
+	}
+	res := Review(evals, true)
+
+	want := []Judgement{Blunder, Best, Inaccuracy, Mistake, Best}
+	if len(res.Judgements) != len(want) {
+		t.Fatalf("judgements = %v, want %v", res.Judgements, want)
+	}
+	for i, j := range want {
+		if res.Judgements[i] != j {
+			t.Errorf("ply %d = %s, want %s", i+1, res.Judgements[i], j)
+		}
+	}
+	if res.White.Blunders != 1 || res.White.Inaccuracies != 1 || res.White.Mistakes != 0 {
+		t.Errorf("white tally = %+v", res.White)
+	}
+	if res.Black.Mistakes != 1 || res.Black.Blunders != 0 {
+		t.Errorf("black tally = %+v", res.Black)
+	}
+	// white lost 250 + 60 + 0 over three moves; black 0 + 130 over two
+	if res.White.ACPL != 103 || res.Black.ACPL != 65 {
+		t.Errorf("acpl = %d/%d, want 103/65", res.White.ACPL, res.Black.ACPL)
+	}
+	if res.White.Accuracy >= res.Black.Accuracy {
+		t.Errorf("accuracy = %.1f/%.1f, want white below black", res.White.Accuracy, res.Black.Accuracy)
+	}
+}
+
+// TestReviewPerfectGame: a game that never gives anything away is all best
+// moves at full accuracy.
+func TestReviewPerfectGame(t *testing.T) {
+	res := Review([]int16{20, 20, 20, 20}, true)
+	for i, j := range res.Judgements {
+		if j != Best {
+			t.Errorf("ply %d = %s, want best", i+1, j)
+		}
+	}
+	if res.White.Accuracy != 100 || res.Black.Accuracy != 100 || res.White.ACPL != 0 {
+		t.Errorf("scores = %+v / %+v, want perfect", res.White, res.Black)
+	}
+}
+
+// TestReviewMissedMate: letting a forced mate go is its own verdict even when
+// the mover stays winning, and delivering the mate is not.
+func TestReviewMissedMate(t *testing.T) {
+	res := Review([]int16{evalCap, 900}, true)
+	if res.Judgements[0] != MissedMate || res.White.MissedMates != 1 {
+		t.Errorf("let the mate go = %s (%+v), want missed-mate", res.Judgements[0], res.White)
+	}
+	res = Review([]int16{evalCap, evalCap}, true)
+	if res.Judgements[0] != Best {
+		t.Errorf("delivered the mate = %s, want best", res.Judgements[0])
+	}
+	// black's mate is a negative eval
+	res = Review([]int16{0, -evalCap, 0}, true)
+	if res.Judgements[1] != MissedMate || res.Black.MissedMates != 1 {
+		t.Errorf("black let the mate go = %s, want missed-mate", res.Judgements[1])
+	}
+}
+
+// TestReviewBlackFirst: a game from a black-to-move position charges the first
+// ply to black.
+func TestReviewBlackFirst(t *testing.T) {
+	res := Review([]int16{0, 250}, false)
+	if res.Judgements[0] != Blunder || res.Black.Blunders != 1 || res.White.Blunders != 0 {
+		t.Errorf("first ply = %s, tallies %+v / %+v, want black's blunder", res.Judgements[0], res.White, res.Black)
+	}
+}
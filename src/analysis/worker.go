@@ -0,0 +1,261 @@
+package analysis
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/dispatch"
+	"github.com/dechristopher/lio/fairplay"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/tablebase"
+	"github.com/dechristopher/lio/util"
+)
+
+// The worker reviews queued games one at a time, claiming them from the
+// game_analysis table the way the mail worker claims the outbox, so any number
+// of nodes share one queue. Games are queued on request from the archive page
+// (Request), and automatically as they are archived when the lio_pg_analysis
+// secret/env is "1" — opt-in, like the evaluator, because every review is a
+// few dozen engine searches.
+const (
+	// Depth is the fixed depth every position is searched at. It matches the
+	// background evaluator's, so an eval the evaluator already cached is the
+	// number the review would compute, and is reused; what the review computes
+	// is cached for the evaluator in turn.
+	Depth = 8
+
+	// searchBudget caps one position's search, as the evaluator's does.
+	searchBudget = 750 * time.Millisecond
+
+	// workTick is how often the worker looks for queued games when nothing woke
+	// it: the pace of automatic reviews and retries.
+	workTick = 10 * time.Second
+
+	// workLease is how long a claimed review is this worker's: far longer than
+	// the longest game takes.
+	workLease = 15 * time.Minute
+
+	// MaxAttempts is how many failed reviews a game gets before it gives up
+	// and waits for somebody to ask again.
+	MaxAttempts = 3
+
+	// centiUnit converts engine decipawns to centipawns; evalCap saturates a
+	// mate-ish score into the stored int16, as the evaluator does.
+	centiUnit = 10
+	evalCap   = 32000
+)
+
+// wake nudges the worker to take a just-requested review now rather than at
+// the next tick.
+var wake = make(chan struct{}, 1)
+
+// Up starts the worker when Postgres is configured, and turns on automatic
+// review when it is enabled. No-op without Postgres.
+func Up() {
+	if db.Pool == nil {
+		return
+	}
+	auto := config.ReadSecretFallback("lio_pg_analysis") == "1"
+	if auto {
+		db.EnableAutoAnalysis()
+	}
+	go worker()
+	util.Debug(str.CAnly, "game analysis worker online (automatic=%t)", auto)
+}
+
+// Request queues a game for review, reporting whether anything was queued:
+// false for a game already queued or reviewed.
+func Request(gameRef int32) (bool, error) {
+	queued, err := db.EnqueueAnalysis(gameRef, MaxAttempts)
+	if err != nil || !queued {
+		return false, err
+	}
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+	return true, nil
+}
+
+// Get returns a game's review, nil when none was ever requested.
+func Get(gameRef int32) (*db.GameAnalysis, error) {
+	return db.GetGameAnalysis(gameRef, MaxAttempts)
+}
+
+// worker reviews queued games until the process exits.
+func worker() {
+	ticker := time.NewTicker(workTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-wake:
+		}
+		for safeReviewNext() {
+		}
+	}
+}
+
+// safeReviewNext reviews the next due game, reporting whether there was one.
+// A panic becomes an error log: one game the engine trips over must not stop
+// the queue, let alone the process.
+func safeReviewNext() (more bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			util.Error(str.CAnly, "game analysis panicked: %v", r)
+			more = false
+		}
+	}()
+	return reviewNext()
+}
+
+// reviewNext claims and reviews one due game, recording the outcome.
+func reviewNext() bool {
+	claimed, err := db.ClaimDueAnalysis(time.Now().Add(workLease), MaxAttempts, 1)
+	if err != nil {
+		util.Error(str.CAnly, "analysis claim failed: %s", err.Error())
+		return false
+	}
+	if len(claimed) == 0 {
+		return false
+	}
+	c := claimed[0]
+
+	start := time.Now()
+	a, err := review(c.GameRef)
+	if err == nil {
+		err = db.SaveAnalysis(c.GameRef, a)
+	}
+	if err != nil {
+		attempt := c.Attempts + 1
+		util.Error(str.CAnly, "analysis failed game=%d attempt=%d error=%s", c.GameRef, attempt, err.Error())
+		if err := db.MarkAnalysisFailed(c.GameRef, err.Error(), time.Now().Add(time.Minute<<attempt)); err != nil {
+			util.Error(str.CAnly, "analysis mark failed failed game=%d error=%s", c.GameRef, err.Error())
+		}
+		return true
+	}
+	util.DebugFlag("analysis", str.CAnly, "reviewed game %d (%d plies) in %s",
+		c.GameRef, len(a.Evals), time.Since(start).Round(time.Millisecond))
+	// a rated game between two accounts is also a fair-play sample
+	fairplay.Observe(c.GameRef, a.Evals)
+	return true
+}
+
+// review replays a game, evaluating every position in it, and judges it.
+func review(gameRef int32) (db.GameAnalysis, error) {
+	startOFEN, _, err := db.AnalysisStart(gameRef)
+	if err != nil {
+		return db.GameAnalysis{}, err
+	}
+	plies, err := db.GamePlies(gameRef)
+	if err != nil {
+		return db.GameAnalysis{}, err
+	}
+	if len(plies) == 0 {
+		return db.GameAnalysis{}, errors.New("game has no plies")
+	}
+
+	opt, err := octad.OFEN(startOFEN)
+	if err != nil {
+		return db.GameAnalysis{}, err
+	}
+	g, err := octad.NewGame(opt)
+	if err != nil {
+		return db.GameAnalysis{}, err
+	}
+	whiteFirst := g.Position().Turn() == octad.White
+
+	evals := make([]int16, 0, len(plies)+1)
+	cp, _, _ := evaluate(g)
+	evals = append(evals, cp)
+	for _, p := range plies {
+		if !playUOI(g, p.Move) {
+			return db.GameAnalysis{}, errors.New("illegal move in archive: " + p.Move)
+		}
+		// a decided position is scored exactly, whatever the cache holds
+		if p.EvalCp != nil && g.Outcome() == octad.NoOutcome {
+			evals = append(evals, *p.EvalCp)
+			continue
+		}
+		cp, best, searched := evaluate(g)
+		evals = append(evals, cp)
+		if searched && p.EvalCp == nil {
+			if err := db.CachePositionEval(p.PositionID, cp, Depth, best); err != nil {
+				util.Error(str.CAnly, "analysis cache write failed position=%d error=%s", p.PositionID, err.Error())
+			}
+		}
+	}
+
+	res := Review(evals, whiteFirst)
+	judgements := make([]int16, len(res.Judgements))
+	for i, j := range res.Judgements {
+		judgements[i] = int16(j)
+	}
+	return db.GameAnalysis{
+		Done:       true,
+		Depth:      Depth,
+		Evals:      evals[1:],
+		Judgements: judgements,
+		White:      db.SideAnalysis{Accuracy: res.White.Accuracy, ACPL: res.White.ACPL},
+		Black:      db.SideAnalysis{Accuracy: res.Black.Accuracy, ACPL: res.Black.ACPL},
+	}, nil
+}
+
+// evaluate scores g's position in white-positive centipawns: exactly for a
+// decided game or a tablebase position, else by a fixed-depth search, whose
+// best move (packed; nil for none) is returned with searched set.
+func evaluate(g *octad.Game) (cp int16, best *int16, searched bool) {
+	switch g.Outcome() {
+	case octad.WhiteWon:
+		return evalCap, nil, false
+	case octad.BlackWon:
+		return -evalCap, nil, false
+	case octad.Draw:
+		return 0, nil, false
+	}
+
+	pos := g.Position()
+	if r, ok := tablebase.Probe(pos); ok {
+		return evalCap * int16(r.White(pos.Turn()).WDL), nil, false
+	}
+
+	me := dispatch.Search(dispatch.SearchRequest{
+		Kind:   "review",
+		OFEN:   pos.String(),
+		Depth:  Depth,
+		Budget: searchBudget,
+	})
+	cp = clamp(me.Eval * centiUnit)
+	// the zero move ("a1a1") is the engine's no-move idiom
+	if me.Move.String() != "a1a1" {
+		packed := game.PackMove(&me.Move)
+		best = &packed
+	}
+	return cp, best, true
+}
+
+// playUOI plays the move given in UOI on g, reporting whether it was legal
+func playUOI(g *octad.Game, uoi string) bool {
+	for _, m := range g.ValidMoves() {
+		if m.String() == uoi {
+			return g.Move(m) == nil
+		}
+	}
+	return false
+}
+
+// clamp saturates an engine eval in centipawns into the stored int16 range
+func clamp(cp float64) int16 {
+	if cp > evalCap {
+		return evalCap
+	}
+	if cp < -evalCap {
+		return -evalCap
+	}
+	return int16(cp)
+}
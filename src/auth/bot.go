@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Bot accounts (www/handlers/handle_bot.go) authenticate their program with a
+// bearer token instead of a cookie: a program has no cookie jar worth the name,
+// and has no business holding a session that could render pages or reach the
+// account settings. The token is the session token's shape — 32 bytes of
+// crypto/rand, base64url, stored only as its SHA-256 — and resolves through
+// the same cache, so a ban or a reissue evicts it the way it evicts a cookie.
+//
+// It is honoured on exactly two surfaces, the socket and the bot API
+// (BotTokenPaths); everywhere else the header is ignored.
+
+// BotAPIPrefix is the path prefix of the bot API.
+const BotAPIPrefix = "/api/bot"
+
+// bearerPrefix is the Authorization scheme a bot presents its token under.
+const bearerPrefix = "Bearer "
+
+// IssueBotToken mints a fresh token for a bot account, replacing (and so
+// revoking) the last. The plaintext is returned to show once; only its hash is
+// stored. found is false when the account is not a bot.
+func IssueBotToken(userID int64) (token string, found bool, err error) {
+	token, hash := NewToken()
+	// base64url can spell a personal token's prefix, and the middleware would
+	// then route this one to the wrong table
+	for IsPersonalToken(token) {
+		token, hash = NewToken()
+	}
+	found, err = db.SetBotToken(userID, hash[:])
+	if err != nil || !found {
+		return "", found, err
+	}
+	// the previous token may still be cached for up to cacheTTL
+	DropUserSessions(userID)
+	return token, true, nil
+}
+
+// BotTokenPaths reports whether a path is one a bot's bearer token
+// authenticates: the socket, and the bot API.
+func BotTokenPaths(path string) bool {
+	return strings.HasPrefix(path, "/socket") ||
+		path == BotAPIPrefix || strings.HasPrefix(path, BotAPIPrefix+"/")
+}
+
+// bearerToken extracts the request's bearer token, "" when there is none.
+func bearerToken(c fiber.Ctx) string {
+	return parseBearer(c.Get(fiber.HeaderAuthorization))
+}
+
+// parseBearer reads the token out of an Authorization header value. The scheme
+// is case-insensitive (RFC 9110); any other scheme is no token at all.
+func parseBearer(h string) string {
+	if len(h) <= len(bearerPrefix) || !strings.EqualFold(h[:len(bearerPrefix)], bearerPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(h[len(bearerPrefix):])
+}
+
+// BotFromRequest resolves the request's bearer token to its bot's identity,
+// serving from the session cache when fresh. Returns nil when there is no
+// token, it is malformed or unknown, the account is banned, or accounts are
+// unavailable.
+func BotFromRequest(c fiber.Ctx) *Session {
+	token := bearerToken(c)
+	if token == "" || !Enabled() {
+		return nil
+	}
+	hash, ok := hashToken(token)
+	if !ok {
+		return nil
+	}
+
+	if s, hit := cacheGet(hash); hit && s.Bot {
+		return &s
+	}
+
+	rec, found, err := db.GetBotByTokenHash(hash[:])
+	if err != nil {
+		util.Error(str.CAuth, "bot token resolve failed error=%s", err.Error())
+		return nil
+	}
+	// not cached either way, like a banned session: lifting the ban takes
+	// effect on the next request
+	if !found || rec.Banned {
+		return nil
+	}
+	uid := rec.UserID
+	sess := Session{
+		UID:       rec.UID,
+		UserID:    &uid,
+		Username:  rec.Username,
+		Title:     rec.Title,
+		Role:      rec.Role,
+		Bot:       true,
+		tokenHash: hash,
+	}
+	cachePut(sess)
+	return &sess
+}
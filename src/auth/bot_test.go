@@ -0,0 +1,40 @@
+package auth
+
+import "testing"
+
+// TestParseBearer: only the Bearer scheme carries a token, in any case, and a
+// scheme with nothing after it is no token.
+func TestParseBearer(t *testing.T) {
+	token, _ := NewToken()
+	cases := []struct{ header, want string }{
+		{"Bearer " + token, token},
+		{"bearer " + token, token},
+		{"Bearer  " + token + " ", token},
+		{"Bearer ", ""},
+		{"Basic dXNlcjpwYXNz", ""},
+		{token, ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := parseBearer(c.header); got != c.want {
+			t.Errorf("parseBearer(%q) = %q, want %q", c.header, got, c.want)
+		}
+	}
+}
+
+// TestBotTokenPaths: a bearer token authenticates the socket and the bot API,
+// and nothing else — least of all the account pages.
+func TestBotTokenPaths(t *testing.T) {
+	for _, p := range []string{"/socket/me", "/socket/abc1234", "/api/bot/account",
+		"/api/bot/challenge/abc1234/accept"} {
+		if !BotTokenPaths(p) {
+			t.Errorf("%s refuses a bot token", p)
+		}
+	}
+	for _, p := range []string{"/", "/settings", "/api/me/notifications", "/api/auth/login",
+		"/abc1234/join", "/api/botany"} {
+		if BotTokenPaths(p) {
+			t.Errorf("%s takes a bot token", p)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Email links (package mail): the signed, expiring tokens in the verification,
+// password reset and unsubscribe links the site mails out. Unlike a session or
+// an API token they are never stored. A token is the account id, an expiry and
+// an HMAC over both, the link's purpose, and a binding — a value the action
+// the link performs will change:
+//
+//   - a reset link is bound to the account's current password hash, so the
+//     reset it performs (or any other password change) spends it, and every
+//     other reset link sent before it
+//   - a verification link is bound to the address it was sent to, so replacing
+//     the address makes it worthless
+//
+// That makes each link single-use without a table of used links, and lets a
+// reset link be revoked by the one thing its owner would do anyway: change the
+// password.
+
+// EmailPurpose is what a link does. It is signed into the token, so a link
+// minted for one purpose cannot be replayed at another's endpoint.
+type EmailPurpose string
+
+const (
+	EmailVerify      EmailPurpose = "verify"
+	EmailReset       EmailPurpose = "reset"
+	EmailUnsubscribe EmailPurpose = "unsubscribe"
+)
+
+// ttl is how long a link of this purpose works. A reset link is the one that
+// grants something, so it is short; the others only confirm or decline.
+func (p EmailPurpose) ttl() time.Duration {
+	switch p {
+	case EmailReset:
+		return time.Hour
+	case EmailVerify:
+		return 48 * time.Hour
+	default:
+		return 60 * 24 * time.Hour
+	}
+}
+
+// ErrEmailLink is every way a link can fail: expired, tampered, spent, or for
+// an account that is gone. One error, so the page a visitor lands on says the
+// same thing whichever it was.
+var ErrEmailLink = errors.New("that link has expired or was already used")
+
+// emailKey derives the signing key from the crypto key, domain-separated so a
+// MAC here can never be mistaken for anything else the key protects.
+func emailKey() []byte {
+	sum := sha256.Sum256([]byte("lio/email-link/" + config.CryptoKey))
+	return sum[:]
+}
+
+// emailMAC signs one link.
+func emailMAC(p EmailPurpose, userID, expires int64, binding string) []byte {
+	m := hmac.New(sha256.New, emailKey())
+	m.Write([]byte(string(p) + "|" + strconv.FormatInt(userID, 10) + "|" +
+		strconv.FormatInt(expires, 10) + "|" + binding))
+	return m.Sum(nil)
+}
+
+// EmailToken mints a link token of the given purpose for an account.
+func EmailToken(p EmailPurpose, userID int64, binding string) string {
+	return emailToken(p, userID, binding, time.Now().Add(p.ttl()))
+}
+
+func emailToken(p EmailPurpose, userID int64, binding string, expires time.Time) string {
+	exp := expires.Unix()
+	return strconv.FormatInt(userID, 10) + "." + strconv.FormatInt(exp, 10) + "." +
+		base64.RawURLEncoding.EncodeToString(emailMAC(p, userID, exp, binding))
+}
+
+// OpenEmailToken checks a link token and returns the account it is for.
+// bindingOf reports the account's current binding for this purpose, and false
+// when the account cannot be found.
+func OpenEmailToken(token string, p EmailPurpose, bindingOf func(userID int64) (string, bool)) (int64, error) {
+	return openEmailToken(token, p, bindingOf, time.Now())
+}
+
+func openEmailToken(token string, p EmailPurpose, bindingOf func(int64) (string, bool), now time.Time) (int64, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, ErrEmailLink
+	}
+	userID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, ErrEmailLink
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > exp {
+		return 0, ErrEmailLink
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return 0, ErrEmailLink
+	}
+	binding, ok := bindingOf(userID)
+	if !ok || !hmac.Equal(mac, emailMAC(p, userID, exp, binding)) {
+		return 0, ErrEmailLink
+	}
+	return userID, nil
+}
+
+// ResetBinding is what a reset link for an account is bound to.
+func ResetBinding(passwordHash string) string {
+	return passwordHash
+}
+
+// VerifyBinding is what a verification link for an address is bound to.
+// Case-folded, since the address is compared case-insensitively everywhere.
+func VerifyBinding(addr string) string {
+	return strings.ToLower(addr)
+}
+
+// ResetPassword completes a reset link: it sets the new password and signs
+// the account out everywhere, since whoever forgot the password may not be the
+// only one who knew it. It does not log the visitor in, and changes no second
+// factor — the next login still asks for one. Returns the account's username,
+// for the page to prefill.
+func ResetPassword(token, password string) (string, error) {
+	var rec db.UserRecord
+	userID, err := OpenEmailToken(token, EmailReset, func(id int64) (string, bool) {
+		r, found, err := db.GetUserByID(id)
+		if err != nil || !found {
+			return "", false
+		}
+		rec = r
+		return ResetBinding(r.PasswordHash), true
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := ValidatePassword(password); err != nil {
+		return "", err
+	}
+	phc, err := HashPassword(password)
+	if err != nil {
+		return "", err
+	}
+	if err := db.UpdatePasswordHash(userID, phc); err != nil {
+		return "", err
+	}
+	if err := db.DeleteSessionsForUser(userID); err != nil {
+		util.Error(str.CAuth, "reset session sweep failed user=%d error=%s", userID, err.Error())
+	}
+	DropUserSessions(userID)
+	if err := db.LogModAction(userID, &userID, "password-reset", nil, ""); err != nil {
+		util.Error(str.CDB, "password reset audit failed user=%d error=%s", userID, err.Error())
+	}
+	return rec.Username, nil
+}
+
+// VerifyEmail completes a verification link, marking the address it was sent
+// to verified.
+func VerifyEmail(token string) error {
+	var addr string
+	userID, err := OpenEmailToken(token, EmailVerify, func(id int64) (string, bool) {
+		r, found, err := db.GetUserByID(id)
+		if err != nil || !found || r.Email == nil {
+			return "", false
+		}
+		addr = *r.Email
+		return VerifyBinding(addr), true
+	})
+	if err != nil {
+		return err
+	}
+	ok, err := db.MarkEmailVerified(userID, addr)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// replaced between the read and the write
+		return ErrEmailLink
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// TestEmailTokenSpentByItsBinding: a link opens only for its own purpose,
+// before its expiry, untampered — and stops opening the moment the value it is
+// bound to changes, which is what makes a reset link single-use.
+func TestEmailTokenSpentByItsBinding(t *testing.T) {
+	now := time.Now()
+	binding := "$argon2id$old"
+	bindingOf := func(id int64) (string, bool) { return binding, id == 42 }
+	token := emailToken(EmailReset, 42, binding, now.Add(time.Hour))
+
+	if id, err := openEmailToken(token, EmailReset, bindingOf, now); err != nil || id != 42 {
+		t.Fatalf("open = %d, %v; want 42, nil", id, err)
+	}
+	if _, err := openEmailToken(token, EmailVerify, bindingOf, now); err != ErrEmailLink {
+		t.Error("a reset link opened as a verification link")
+	}
+	if _, err := openEmailToken(token, EmailReset, bindingOf, now.Add(2*time.Hour)); err != ErrEmailLink {
+		t.Error("an expired link opened")
+	}
+	if _, err := openEmailToken("43"+token[2:], EmailReset, bindingOf, now); err != ErrEmailLink {
+		t.Error("a link opened for an account it was not minted for")
+	}
+	for _, bad := range []string{"", "42", "42.1.x", token + "x"} {
+		if _, err := openEmailToken(bad, EmailReset, bindingOf, now); err != ErrEmailLink {
+			t.Errorf("malformed token %q opened", bad)
+		}
+	}
+
+	binding = "$argon2id$new"
+	if _, err := openEmailToken(token, EmailReset, bindingOf, now); err != ErrEmailLink {
+		t.Error("a reset link still opened after the password changed")
+	}
+}
@@ -16,6 +16,25 @@ var legacyCookies = []string{"lio", "uid"}
 func SessionMiddleware(c fiber.Ctx) error {
 	path := c.Path()
 
+	// A personal API token (tokens.go) authenticates as its account on the
+	// paths its scopes reach and is refused on every other; like a bot's, it
+	// is never minted a cookie session.
+	if token := bearerToken(c); IsPersonalToken(token) {
+		return tokenRequest(c, token, path)
+	}
+
+	// A bot account's program authenticates with its bearer token on the
+	// surfaces that take one (see bot.go) and is never minted a cookie
+	// session: an unknown or revoked token passes through with no identity,
+	// which the socket closes on and the bot API refuses.
+	if BotTokenPaths(path) && bearerToken(c) != "" {
+		if sess := BotFromRequest(c); sess != nil {
+			c.SetContext(UserContext(sess))
+			c.Locals("uid", sess.UID)
+		}
+		return c.Next()
+	}
+
 	// WebSocket upgrades authenticate with the cookie they present, or not at
 	// all: never mint a session for a socket and never touch cookies. iOS
 	// Safari intermittently omits cookies from WS upgrade requests (see
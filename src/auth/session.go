@@ -62,6 +62,10 @@ type Session struct {
 	Username  string
 	Title     title.Title // account's optional display title, zero for anon
 	Role      role.Role   // account's permission level, Player for anon
+	Bot       bool        // resolved from a bot account's bearer token (BotFromRequest); ID is zero
+	TokenID   int64       // the personal API token the request presented (tokens.go); ID is zero
+	tokenName string
+	scopes    []string
 	tokenHash [32]byte
 	lastSeen  time.Time
 	expiresAt time.Time
@@ -381,11 +385,17 @@ func DropUserSessions(userID int64) {
 	}
 }
 
-// CurrentSession resolves the request's session (cache-first, no mint). Nil
+// CurrentSession resolves the request's session (cache-first, no mint), or the
+// personal token's it was authenticated with. Nil
 // when there is no live session. Handlers use it for the account-admin gate
 // and to get the current session id (the one to keep on a password change /
 // "log out everywhere else").
 func CurrentSession(c fiber.Ctx) *Session {
+	// a personal token authenticates as its account; the paths it reaches
+	// never include the account-admin ones (ScopeFor)
+	if s, ok := c.Locals(tokenSessionLocal).(*Session); ok {
+		return s
+	}
 	return FromRequest(c)
 }
 
@@ -483,6 +493,8 @@ func UserContext(s *Session) *user.Context {
 			Username: s.Username,
 			Title:    s.Title,
 			Role:     s.Role,
+			Bot:      s.Bot,
+			TokenID:  s.TokenID,
 		}
 	}
 	return ctx
@@ -0,0 +1,329 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/role"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Personal API tokens: a person's own scripts calling the JSON API as them.
+// A token is a session token's 32 bytes of crypto/rand behind a fixed prefix,
+// shown once at creation and stored only as its SHA-256 — high-entropy like a
+// recovery code, so a fast hash suffices. The prefix is what tells the
+// middleware a personal token from a bot account's (bot.go), and makes a
+// leaked one recognizable to a secret scanner.
+//
+// A token carries scopes, and the scope table (ScopeFor) is the whole of what
+// it can reach: a path the table does not name refuses every token, whatever
+// its scopes. Everything under /api/auth — passwords, sessions, second factors,
+// and these tokens themselves — is deliberately absent, so a leaked token can
+// neither lock its owner out nor mint its own successor.
+//
+// Each token's use is written to the moderation log (mod_actions) as one
+// "token-use" entry per token per tokenAuditInterval, carrying the request
+// count since the last: an entry per request would bury every other entry in
+// the feed.
+
+// Scope is one permission a personal token can carry.
+type Scope string
+
+const (
+	// ScopeReadGames reads archived and correspondence games.
+	ScopeReadGames Scope = "games:read"
+	// ScopePlay is the socket, correspondence moves, and the account's own
+	// notification inbox (where challenges arrive, and are declined).
+	ScopePlay Scope = "play"
+	// ScopeFollow reads and edits the follow graph, and the account's blocks.
+	ScopeFollow Scope = "follow"
+	// ScopeMod is the moderation API. Only staff may create a token with it,
+	// and every moderation handler still checks the account's role.
+	ScopeMod Scope = "mod"
+)
+
+// Scopes are every scope, in the order the creation form offers them.
+var Scopes = []Scope{ScopeReadGames, ScopePlay, ScopeFollow, ScopeMod}
+
+// Label describes a scope for the token page.
+func (s Scope) Label() string {
+	switch s {
+	case ScopeReadGames:
+		return "Read games"
+	case ScopePlay:
+		return "Play"
+	case ScopeFollow:
+		return "Follow"
+	case ScopeMod:
+		return "Moderate"
+	}
+	return string(s)
+}
+
+const (
+	// personalTokenPrefix marks a personal token. Bot tokens have none.
+	personalTokenPrefix = "lio_"
+	// MaxAPITokens bounds how many tokens one account holds.
+	MaxAPITokens = 20
+	// tokenNameMax bounds a token's name, in characters.
+	tokenNameMax = 40
+	// tokenAuditInterval is how often one token's use is written to the log.
+	tokenAuditInterval = 10 * time.Minute
+)
+
+// tokenSessionLocal is the fiber local a token-authenticated request carries
+// its session under, for CurrentSession.
+const tokenSessionLocal = "apiTokenSession"
+
+var (
+	ErrTokenName   = errors.New("name the token (up to 40 characters)")
+	ErrTokenScopes = errors.New("choose at least one scope")
+	ErrTokenMod    = errors.New("only staff accounts can create a token with the moderate scope")
+	ErrTokenLimit  = errors.New("this account already holds the maximum number of tokens — revoke one first")
+)
+
+// ScopeFor names the scope a request needs when it presents a personal token,
+// and ok=false for a path no token reaches.
+func ScopeFor(method, path string) (scope Scope, ok bool) {
+	read := method == fiber.MethodGet || method == fiber.MethodHead
+	switch {
+	case under(path, "/socket"), under(path, "/api/me"):
+		return ScopePlay, true
+	case under(path, "/api/follow"), under(path, "/api/block"):
+		return ScopeFollow, true
+	case under(path, "/api/mod"):
+		return ScopeMod, true
+	case under(path, "/api/room") && read:
+		return ScopeReadGames, true
+	case under(path, "/api/correspondence"):
+		if read {
+			return ScopeReadGames, true
+		}
+		return ScopePlay, true
+	}
+	return "", false
+}
+
+// under reports whether path is prefix or a path beneath it.
+func under(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// ParseScopes validates submitted scope values, dropping duplicates.
+func ParseScopes(values []string) ([]Scope, error) {
+	var out []Scope
+	seen := map[Scope]bool{}
+	for _, v := range values {
+		s := Scope(v)
+		valid := false
+		for _, known := range Scopes {
+			valid = valid || s == known
+		}
+		if !valid {
+			return nil, errors.New("unknown scope " + v)
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrTokenScopes
+	}
+	return out, nil
+}
+
+// IsPersonalToken reports whether a presented bearer token is a personal
+// token rather than a bot's.
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, personalTokenPrefix)
+}
+
+// IssueAPIToken creates a token for an account and returns its plaintext, to
+// show once. accountRole is the creator's role, which decides whether the mod
+// scope may be granted.
+func IssueAPIToken(userID int64, accountRole role.Role, name string, scopes []Scope) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > tokenNameMax {
+		return "", ErrTokenName
+	}
+	if len(scopes) == 0 {
+		return "", ErrTokenScopes
+	}
+	names := make([]string, len(scopes))
+	for i, s := range scopes {
+		if s == ScopeMod && !accountRole.CanModerate() {
+			return "", ErrTokenMod
+		}
+		names[i] = string(s)
+	}
+	if n, err := db.CountAPITokens(userID); err != nil {
+		return "", err
+	} else if n >= MaxAPITokens {
+		return "", ErrTokenLimit
+	}
+
+	raw, hash := NewToken()
+	id, err := db.CreateAPIToken(userID, name, hash[:], names,
+		config.GenerateCode(16, config.Base58))
+	if err != nil {
+		return "", err
+	}
+	auditToken(userID, "token-create", map[string]any{
+		"token":   name,
+		"tokenId": id,
+		"scopes":  strings.Join(names, ", "),
+	})
+	return personalTokenPrefix + raw, nil
+}
+
+// RevokeAPIToken deletes one of an account's tokens. found is false when the
+// id is not one of its tokens.
+func RevokeAPIToken(userID, id int64) (found bool, err error) {
+	name, found, err := db.DeleteAPIToken(id, userID)
+	if err != nil || !found {
+		return found, err
+	}
+	// the token may still be cached for up to cacheTTL
+	DropUserSessions(userID)
+	auditToken(userID, "token-revoke", map[string]any{"token": name, "tokenId": id})
+	return true, nil
+}
+
+// tokenSession resolves a personal token to its session, serving from the
+// session cache when fresh. Returns nil when the token is malformed or
+// unknown, the account is banned, or accounts are unavailable.
+func tokenSession(token string) *Session {
+	if !Enabled() {
+		return nil
+	}
+	hash, ok := hashToken(strings.TrimPrefix(token, personalTokenPrefix))
+	if !ok {
+		return nil
+	}
+	if s, hit := cacheGet(hash); hit && s.TokenID != 0 {
+		return &s
+	}
+
+	rec, found, err := db.GetAPITokenByHash(hash[:])
+	if err != nil {
+		util.Error(str.CAuth, "api token resolve failed error=%s", err.Error())
+		return nil
+	}
+	// not cached either way, like a banned session
+	if !found || rec.Banned {
+		return nil
+	}
+	uid := rec.UserID
+	sess := Session{
+		UID:       rec.UID,
+		UserID:    &uid,
+		Username:  rec.Username,
+		Title:     rec.Title,
+		Role:      rec.Role,
+		TokenID:   rec.ID,
+		tokenName: rec.Name,
+		scopes:    rec.Scopes,
+		tokenHash: hash,
+	}
+	cachePut(sess)
+	return &sess
+}
+
+// HasScope reports whether a token session carries a scope.
+func (s *Session) HasScope(scope Scope) bool {
+	for _, have := range s.scopes {
+		if Scope(have) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenRequest authenticates a request presenting a personal token, refusing
+// it outright when the token cannot reach the path: a script is owed a clear
+// answer, where passing through anonymously would read as "not logged in".
+func tokenRequest(c fiber.Ctx, token, path string) error {
+	scope, ok := ScopeFor(c.Method(), path)
+	if !ok {
+		return c.Status(fiber.StatusForbidden).
+			JSON(fiber.Map{"error": "personal tokens are not accepted here"})
+	}
+	sess := tokenSession(token)
+	if sess == nil {
+		return c.Status(fiber.StatusUnauthorized).
+			JSON(fiber.Map{"error": "invalid or revoked token"})
+	}
+	if !sess.HasScope(scope) {
+		return c.Status(fiber.StatusForbidden).
+			JSON(fiber.Map{"error": "this token lacks the " + string(scope) + " scope"})
+	}
+
+	c.SetContext(UserContext(sess))
+	c.Locals("uid", sess.UID)
+	c.Locals(tokenSessionLocal, sess)
+	noteTokenUse(sess, scope, c.Method(), strings.Clone(path))
+	return c.Next()
+}
+
+// tokenUse is one token's use since its last audit entry.
+type tokenUse struct {
+	logged   time.Time
+	requests int
+}
+
+var tokenUses = struct {
+	sync.Mutex
+	m map[int64]*tokenUse
+}{m: make(map[int64]*tokenUse)}
+
+// noteTokenUse counts a request against its token and, once per
+// tokenAuditInterval, writes the audit entry and touches last_used_at. The
+// writes run off the request path: a slow log is no reason to slow the API.
+func noteTokenUse(s *Session, scope Scope, method, path string) {
+	tokenUses.Lock()
+	u, ok := tokenUses.m[s.TokenID]
+	if !ok {
+		u = &tokenUse{}
+		tokenUses.m[s.TokenID] = u
+	}
+	u.requests++
+	if time.Since(u.logged) < tokenAuditInterval {
+		tokenUses.Unlock()
+		return
+	}
+	requests := u.requests
+	u.logged, u.requests = time.Now(), 0
+	tokenUses.Unlock()
+
+	userID, id, name := *s.UserID, s.TokenID, s.tokenName
+	go func() {
+		if err := db.TouchAPIToken(id); err != nil {
+			util.Error(str.CAuth, "api token touch failed id=%d error=%s", id, err.Error())
+		}
+		auditToken(userID, "token-use", map[string]any{
+			"token":    name,
+			"tokenId":  id,
+			"scope":    string(scope),
+			"request":  method + " " + path,
+			"requests": requests,
+		})
+	}()
+}
+
+// auditToken writes a token event to the moderation log, the account acting
+// on itself. Logged and swallowed on failure, like every audit write.
+func auditToken(userID int64, action string, detail map[string]any) {
+	if err := db.LogModAction(userID, &userID, action, detail, ""); err != nil {
+		util.Error(str.CDB, "token audit failed action=%s user=%d error=%s",
+			action, userID, err.Error())
+	}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// TestScopeFor pins the scope table: what each token-reachable path needs, and
+// that the account-admin and page routes refuse every token.
+func TestScopeFor(t *testing.T) {
+	cases := []struct {
+		method, path string
+		want         Scope
+		ok           bool
+	}{
+		{fiber.MethodGet, "/socket/me", ScopePlay, true},
+		{fiber.MethodGet, "/api/me/notifications", ScopePlay, true},
+		{fiber.MethodPost, "/api/me/challenge/decline", ScopePlay, true},
+		{fiber.MethodPost, "/api/follow/somebody", ScopeFollow, true},
+		{fiber.MethodGet, "/api/follow/mine", ScopeFollow, true},
+		{fiber.MethodDelete, "/api/block/somebody", ScopeFollow, true},
+		{fiber.MethodPost, "/api/mod/ban", ScopeMod, true},
+		{fiber.MethodGet, "/api/room/abc/game/1", ScopeReadGames, true},
+		{fiber.MethodGet, "/api/correspondence/abc", ScopeReadGames, true},
+		{fiber.MethodPost, "/api/correspondence/abc", ScopePlay, true},
+
+		{fiber.MethodPost, "/api/room/abc/game/1", "", false},
+		{fiber.MethodPost, "/api/auth/password", "", false},
+		{fiber.MethodGet, "/api/auth/sessions", "", false},
+		{fiber.MethodPost, "/account/tokens", "", false},
+		{fiber.MethodGet, "/api/bot/challenges", "", false},
+		{fiber.MethodGet, "/api/mediator", "", false},
+		{fiber.MethodGet, "/", "", false},
+	}
+	for _, tc := range cases {
+		got, ok := ScopeFor(tc.method, tc.path)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("ScopeFor(%s %s) = %q, %v; want %q, %v",
+				tc.method, tc.path, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	got, err := ParseScopes([]string{"play", "games:read", "play"})
+	if err != nil || len(got) != 2 || got[0] != ScopePlay || got[1] != ScopeReadGames {
+		t.Errorf("ParseScopes = %v, %v", got, err)
+	}
+	if _, err := ParseScopes(nil); err != ErrTokenScopes {
+		t.Errorf("no scopes: err = %v, want ErrTokenScopes", err)
+	}
+	if _, err := ParseScopes([]string{"admin"}); err == nil {
+		t.Error("an unknown scope was accepted")
+	}
+}
+
+// TestPersonalTokenShape: issued tokens carry the prefix the middleware routes
+// on, and their body still hashes like a session token.
+func TestPersonalTokenShape(t *testing.T) {
+	raw, want := NewToken()
+	token := personalTokenPrefix + raw
+	if !IsPersonalToken(token) {
+		t.Fatalf("%q not recognized as a personal token", token)
+	}
+	if IsPersonalToken("Bearer-less-and-unprefixed") {
+		t.Error("an unprefixed token was taken for a personal one")
+	}
+	sess := Session{scopes: []string{"play"}}
+	if !sess.HasScope(ScopePlay) || sess.HasScope(ScopeMod) {
+		t.Error("HasScope disagrees with the session's scopes")
+	}
+	got, ok := hashToken(token[len(personalTokenPrefix):])
+	if !ok || got != want {
+		t.Error("the prefixed token does not hash to its stored hash")
+	}
+}
@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The multi-node namespaces (package cluster), beside the room snapshots:
+//
+//	lio:node:<node>    the node's internal address, while its heartbeat lives
+//	lio:online:<node>  the account ids holding a socket on that node
+//	lio:owner:<room>   the node that owns a live room, as a renewed lease
+//	lio:fanout         the pub/sub channel every node's sends are relayed on
+//
+// The scripts below read a node key whose name is built from a value rather
+// than passed in KEYS. That is fine on the single Redis lio runs against, and
+// would need hash tags on a Redis Cluster.
+const (
+	nodeKeyPrefix   = "lio:node:"
+	onlineKeyPrefix = "lio:online:"
+	ownerKeyPrefix  = "lio:owner:"
+	fanoutChannel   = "lio:fanout"
+)
+
+func nodeKey(id string) string {
+	return nodeKeyPrefix + id
+}
+
+func ownerKey(roomID string) string {
+	return ownerKeyPrefix + roomID
+}
+
+// claimScript takes a room lease for a node: when the room is unowned, when
+// the node already holds it, or when its owner's heartbeat has lapsed (a dead
+// node's rooms are up for adoption before their leases run out).
+var claimScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner and owner ~= ARGV[1] and redis.call("EXISTS", ARGV[3] .. owner) == 1 then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// renewScript extends a lease only if the node still holds it.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes a lease only if the node still holds it, so a node
+// can never release a room another has since adopted.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// ownerScript resolves a room to its owner and the owner's address in one
+// round trip, and to nothing when either is missing: a lease whose node has
+// no heartbeat is an orphan, not an owner.
+var ownerScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if not owner then
+	return false
+end
+local addr = redis.call("GET", ARGV[1] .. owner)
+if not addr then
+	return false
+end
+return {owner, addr}
+`)
+
+// RoomOwners is the Redis-backed room directory for one node; it satisfies
+// the room package's ownerDirectory interface (structurally, like
+// RoomSnapshots).
+type RoomOwners struct {
+	Node string
+}
+
+// ClaimRoom takes a room's lease for this node; false means a live node
+// holds it.
+func (o RoomOwners) ClaimRoom(id string, ttl time.Duration) (bool, error) {
+	if C == nil {
+		return false, errOffline
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	n, err := claimScript.Run(ctx, C, []string{ownerKey(id)},
+		o.Node, ttl.Milliseconds(), nodeKeyPrefix).Int()
+	return n == 1, err
+}
+
+// RenewRooms extends this node's leases on a batch of rooms in one pipelined
+// round trip.
+func (o RoomOwners) RenewRooms(ids []string, ttl time.Duration) error {
+	if C == nil {
+		return errOffline
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+
+	// Eval rather than Run: Run's fall back from EVALSHA to EVAL on a cold
+	// script cache cannot happen inside a pipeline, and the script is short
+	pipe := C.Pipeline()
+	for _, id := range ids {
+		renewScript.Eval(ctx, pipe, []string{ownerKey(id)}, o.Node, ttl.Milliseconds())
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ReleaseRoom gives up this node's lease on a room, if it still holds it.
+func (o RoomOwners) ReleaseRoom(id string) error {
+	if C == nil {
+		return errOffline
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return releaseScript.Run(ctx, C, []string{ownerKey(id)}, o.Node).Err()
+}
+
+// RoomOwner returns the node that owns a live room and its address.
+// found is false when the room has no owner with a live heartbeat.
+func RoomOwner(roomID string) (node, addr string, found bool, err error) {
+	if C == nil {
+		return "", "", false, errOffline
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	res, err := ownerScript.Run(ctx, C, []string{ownerKey(roomID)}, nodeKeyPrefix).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return "", "", false, nil
+	}
+	if err != nil || len(res) != 2 {
+		return "", "", false, err
+	}
+	return res[0], res[1], true, nil
+}
+
+// PutNode writes a node's heartbeat: its address and the accounts online on
+// it, both expiring after ttl unless refreshed.
+func PutNode(id, addr string, online []int64, ttl time.Duration) error {
+	if C == nil {
+		return errOffline
+	}
+	ids, err := json.Marshal(online)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+
+	pipe := C.Pipeline()
+	pipe.Set(ctx, nodeKey(id), addr, ttl)
+	pipe.Set(ctx, onlineKeyPrefix+id, ids, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// DeleteNode removes a node's heartbeat: a drained node leaving on purpose.
+func DeleteNode(id string) error {
+	if C == nil {
+		return errOffline
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return C.Del(ctx, nodeKey(id), onlineKeyPrefix+id).Err()
+}
+
+// Nodes returns every node with a live heartbeat, mapped to the account ids
+// online on it.
+func Nodes() (map[string][]int64, error) {
+	if C == nil {
+		return nil, errOffline
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+
+	var ids []string
+	iter := C.Scan(ctx, 0, nodeKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), nodeKeyPrefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]int64, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = onlineKeyPrefix + id
+	}
+	vals, err := C.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range vals {
+		var online []int64
+		if s, ok := v.(string); ok {
+			_ = json.Unmarshal([]byte(s), &online)
+		}
+		out[ids[i]] = online
+	}
+	return out, nil
+}
+
+// Publish relays a message to every node subscribed to the fan-out channel,
+// this one included.
+func Publish(msg []byte) error {
+	if C == nil {
+		return errOffline
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return C.Publish(ctx, fanoutChannel, msg).Err()
+}
+
+// Subscribe calls fn with every message published on the fan-out channel,
+// from a goroutine of its own, for the life of the process. The client
+// resubscribes by itself after a dropped connection; what was published in
+// between is lost, which every relayed send tolerates (see package cluster).
+func Subscribe(fn func(msg []byte)) error {
+	if C == nil {
+		return errOffline
+	}
+	sub := C.Subscribe(context.Background(), fanoutChannel)
+	ctx, cancel := Ctx()
+	defer cancel()
+	// wait for the confirmation, so a send made right after boot is not
+	// published before this node listens
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return err
+	}
+	go func() {
+		for m := range sub.Channel() {
+			fn([]byte(m.Payload))
+		}
+	}()
+	return nil
+}
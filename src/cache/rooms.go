@@ -4,10 +4,12 @@ import (
 	"errors"
 	"strings"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // roomKeyPrefix namespaces room snapshots in Redis. Layer 2 (multi-instance)
-// will add sibling namespaces (owner leases, home digests) beside it.
+// adds its sibling namespaces beside it (nodes.go).
 const roomKeyPrefix = "lio:room:"
 
 // errOffline is returned when no cache is configured; the room persister
@@ -73,3 +75,21 @@ func (RoomSnapshots) LoadRooms() (map[string][]byte, error) {
 	}
 	return snaps, iter.Err()
 }
+
+// LoadRoom returns one room's snapshot (adoption by another node, see
+// room.Adopt). found is false when there is none.
+func (RoomSnapshots) LoadRoom(id string) ([]byte, bool, error) {
+	if C == nil {
+		return nil, false, errOffline
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	data, err := C.Get(ctx, roomKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
@@ -129,9 +129,10 @@ func Connected() map[string]Account {
 // from account to socket that Track and UnTrack must maintain correctly. Add
 // that index only if this walk ever becomes hot.
 //
-// Returns the number of connections the message was queued for. A return of 0
-// means the account is offline, which is not an error: the row is in the
-// database, and the next socket connect reads the count.
+// Returns the number of connections the message was queued for on this node,
+// and other nodes deliver it too (relay.go). A return of 0 means the account
+// holds no socket here, which is not an error: the row is in the database, and
+// the next socket connect reads the count.
 // EachSocket calls fn once for every live connection on every channel, with the
 // channel name each one is on.
 //
@@ -176,8 +177,18 @@ func EachSocket(fn func(channelName string, s *Socket)) {
 // always: two tabs of the same browser are two connections under one uid, and
 // both are showing the same chrome, so both have to be told.
 //
-// Returns the number of connections the message was queued for.
+// Returns the number of connections the message was queued for on this node;
+// other nodes deliver it too (relay.go).
 func SendToUID(uid string, d []byte) int {
+	if uid == "" {
+		return 0
+	}
+	relayOut(Fanout{Kind: FanoutUID, UID: uid, Data: d})
+	return sendToUID(uid, d)
+}
+
+// sendToUID is SendToUID on this node only.
+func sendToUID(uid string, d []byte) int {
 	if uid == "" {
 		return 0
 	}
@@ -200,6 +211,12 @@ func SendToAccount(acctID int64, d []byte) int {
 	if acctID == 0 {
 		return 0
 	}
+	relayOut(Fanout{Kind: FanoutAccounts, IDs: []int64{acctID}, Data: d})
+	return sendToAccount(acctID, d)
+}
+
+// sendToAccount is SendToAccount on this node only.
+func sendToAccount(acctID int64, d []byte) int {
 	sent := 0
 	Map.Range(func(_, v interface{}) bool {
 		sm, ok := v.(*SockMap)
@@ -226,11 +243,18 @@ func SendToAccount(acctID int64, d []byte) int {
 // is the expensive half, and repeating it per moderator would multiply the cost
 // by the size of the staff for no gain.
 //
-// Returns the number of connections the message was queued for.
+// Returns the number of connections the message was queued for on this node;
+// other nodes deliver it too (relay.go).
 func SendToAccounts(ids []int64, d []byte) int {
 	if len(ids) == 0 {
 		return 0
 	}
+	relayOut(Fanout{Kind: FanoutAccounts, IDs: ids, Data: d})
+	return sendToAccounts(ids, d)
+}
+
+// sendToAccounts is SendToAccounts on this node only.
+func sendToAccounts(ids []int64, d []byte) int {
 	want := make(map[int64]struct{}, len(ids))
 	for _, id := range ids {
 		// 0 is the anonymous marker, and every anonymous socket carries it. It
@@ -273,8 +297,15 @@ func SendToAccounts(ids []int64, d []byte) int {
 // every account and their counts all differ, so the payload carries the message
 // alone — see proto.NotifyBroadcastMessage for what the client does with that.
 //
-// Returns the number of connections the message was queued for.
+// Returns the number of connections the message was queued for on this node;
+// other nodes deliver it too (relay.go).
 func SendToEveryAccount(d []byte) int {
+	relayOut(Fanout{Kind: FanoutEveryAccount, Data: d})
+	return sendToEveryAccount(d)
+}
+
+// sendToEveryAccount is SendToEveryAccount on this node only.
+func sendToEveryAccount(d []byte) int {
 	sent := 0
 	Map.Range(func(_, v interface{}) bool {
 		sm, ok := v.(*SockMap)
@@ -301,8 +332,18 @@ func SendToEveryAccount(d []byte) int {
 // it authenticated once at upgrade time and is keyed by uid thereafter. Without
 // this, a banned player could keep playing the game they are sitting in.
 //
-// Returns the number of connections closed.
+// Returns the number of connections closed on this node; other nodes close
+// theirs too (relay.go).
 func CloseForUID(uid string, code int, reason string) int {
+	if uid == "" {
+		return 0
+	}
+	relayOut(Fanout{Kind: FanoutCloseUID, UID: uid, Code: code, Reason: reason})
+	return closeForUID(uid, code, reason)
+}
+
+// closeForUID is CloseForUID on this node only.
+func closeForUID(uid string, code int, reason string) int {
 	if uid == "" {
 		return 0
 	}
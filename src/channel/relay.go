@@ -0,0 +1,70 @@
+package channel
+
+import "sync/atomic"
+
+// Cross-node delivery. On a multi-node deployment a person's sockets may be
+// held by any node, so the site-wide sends (SendToUID, SendToAccount,
+// SendToAccounts, SendToEveryAccount) and CloseForUID deliver on this node and
+// then hand the same send to the relay, which publishes it for every other
+// node to Deliver locally (package cluster).
+//
+// The relay is a hook rather than an import for the same reason home's digest
+// source is one: the transport needs the cache and the node's identity, and
+// the socket layer should know neither. On a single node it is never set, and
+// every send is exactly the walk it always was.
+
+// FanoutKind names which send a relayed Fanout replays.
+type FanoutKind uint8
+
+const (
+	// FanoutUID is SendToUID.
+	FanoutUID FanoutKind = iota + 1
+	// FanoutAccounts is SendToAccount and SendToAccounts.
+	FanoutAccounts
+	// FanoutEveryAccount is SendToEveryAccount.
+	FanoutEveryAccount
+	// FanoutCloseUID is CloseForUID.
+	FanoutCloseUID
+)
+
+// Fanout is one site-wide send as it crosses between nodes. Only the fields
+// its Kind reads are set.
+type Fanout struct {
+	Kind   FanoutKind `json:"k"`
+	UID    string     `json:"u,omitempty"`
+	IDs    []int64    `json:"i,omitempty"`
+	Data   []byte     `json:"d,omitempty"`
+	Code   int        `json:"c,omitempty"`
+	Reason string     `json:"r,omitempty"`
+}
+
+var relay atomic.Pointer[func(Fanout)]
+
+// SetRelay installs the hook every site-wide send is handed to after its local
+// delivery. Called once at boot by package cluster.
+func SetRelay(fn func(Fanout)) {
+	relay.Store(&fn)
+}
+
+// relayOut hands a send to the relay, if one is installed.
+func relayOut(f Fanout) {
+	if fn := relay.Load(); fn != nil {
+		(*fn)(f)
+	}
+}
+
+// Deliver performs a send relayed from another node, on this node only: it is
+// never relayed again. Returns the number of connections reached.
+func Deliver(f Fanout) int {
+	switch f.Kind {
+	case FanoutUID:
+		return sendToUID(f.UID, f.Data)
+	case FanoutAccounts:
+		return sendToAccounts(f.IDs, f.Data)
+	case FanoutEveryAccount:
+		return sendToEveryAccount(f.Data)
+	case FanoutCloseUID:
+		return closeForUID(f.UID, f.Code, f.Reason)
+	}
+	return 0
+}
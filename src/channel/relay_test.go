@@ -0,0 +1,41 @@
+package channel
+
+import "testing"
+
+// TestRelayCarriesSitewideSends: a site-wide send is delivered here and handed
+// to the relay once, and a relayed send Delivered from another node reaches
+// this node's sockets without being relayed again — or every node would
+// republish every message forever.
+func TestRelayCarriesSitewideSends(t *testing.T) {
+	var relayed []Fanout
+	SetRelay(func(f Fanout) { relayed = append(relayed, f) })
+	t.Cleanup(func() { relay.Store(nil) })
+
+	sm := Map.GetSockMap("relay-room")
+	s := NewSocket(nil, "uid-relay", "c1", "", Account{ID: 7})
+	sm.Track(s)
+	t.Cleanup(func() { sm.UnTrack("uid-relay", "c1") })
+
+	if sent := SendToAccount(7, []byte(`{"t":"nt"}`)); sent != 1 {
+		t.Fatalf("SendToAccount reached %d local connections, want 1", sent)
+	}
+	if len(relayed) != 1 || relayed[0].Kind != FanoutAccounts ||
+		len(relayed[0].IDs) != 1 || relayed[0].IDs[0] != 7 {
+		t.Fatalf("relayed %+v, want one FanoutAccounts for account 7", relayed)
+	}
+	drain(s)
+
+	relayed = nil
+	if got := Deliver(Fanout{Kind: FanoutUID, UID: "uid-relay", Data: []byte(`{}`)}); got != 1 {
+		t.Fatalf("Deliver reached %d connections, want 1", got)
+	}
+	if got := Deliver(Fanout{Kind: FanoutEveryAccount, Data: []byte(`{}`)}); got != 1 {
+		t.Fatalf("Deliver to every account reached %d connections, want 1", got)
+	}
+	if len(relayed) != 0 {
+		t.Fatalf("a delivered send was relayed again: %+v", relayed)
+	}
+	if got := len(drain(s)); got != 2 {
+		t.Fatalf("socket received %d messages, want 2", got)
+	}
+}
@@ -250,6 +250,21 @@ func (s *SockMap) Length() int {
 	return len(s.sockets)
 }
 
+// Connections returns the number of live connections across every uid, the
+// per-socket counterpart of Length.
+func (s *SockMap) Connections() int {
+	if s == nil {
+		return 0
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	n := 0
+	for _, conns := range s.sockets {
+		n += len(conns)
+	}
+	return n
+}
+
 type Listener chan int
 
 // UnListen closes a listener channel and un-tracks it from
@@ -350,10 +365,12 @@ type Socket struct {
 	// (arch/HOME_ACTIVITY_STREAMING.md). The htmx poll it replaced asked
 	// Postgres that once per viewer per five seconds.
 	//
-	// Read-only after NewSocket returns. A follow made during the connection's
-	// life is not reflected until the page is reloaded, which is acceptable:
-	// following somebody is a deliberate act on another page, and arriving back
-	// at the home page is what the reader does next.
+	// Owned by the home hub's goroutine once the socket is tracked. A follow
+	// made during the connection's life is not reflected until the page is
+	// reloaded, which is acceptable: following somebody is a deliberate act on
+	// another page, and arriving back at the home page is what the reader does
+	// next. A block is the one change applied in place (home.Block), since the
+	// blocked account must stop reading as followed without waiting for one.
 	Follows map[int64]struct{}
 
 	send   chan []byte
@@ -468,7 +485,14 @@ type SocketContext struct {
 	// it is attached to. Spectator messages must never reach game-affecting
 	// paths; the ws handlers drop them before the room's seat checks ever run.
 	IsSpectator bool
-	MT          int // websocket message type
+	// SocketID is the per-connection id (Socket.ID), for anything kept per
+	// connection rather than per session — chat's rate limit, which a second
+	// tab must not be able to double.
+	SocketID string
+	// Acct is the account the connection authenticated as at upgrade time,
+	// zero-valued for an anonymous session (Socket.Acct).
+	Acct Account
+	MT   int // websocket message type
 }
 
 // IsHuman returns true if the context belongs to a human player
@@ -0,0 +1,285 @@
+// Package chat is in-game chat. Every room has two conversations: the player
+// chat between its two seats (proto.PlayerChatTag) and the spectator chat of
+// everybody watching (proto.SpectatorChatTag). Neither side reads the other's,
+// so a crowd cannot coach a player and a player cannot be heckled mid-game.
+//
+// A line goes through five gates before anybody sees it, cheapest first: the
+// sender's rate limit (per socket, see limit.go), their account's sanction
+// state — a ban suppresses sending on a socket that outlived it — the word
+// filter (config.NaughtyChat), a per-room mute on the reader's side, and a
+// block between the sender's account and the reader's (db/blocks.go), which
+// hides each one's lines from the other, backlog included. A line the filter
+// withholds is still logged, flagged, because it is the
+// evidence a report about abuse needs; a line refused earlier is not, since
+// nothing was said.
+//
+// The log is kept per room in chat_messages, where a moderator working a
+// report that names one of the room's games reads it (/moderation/chat/<room>).
+// A socket joining the room is caught up from the same table, so the
+// conversation survives a reload, a reconnect and a restart alike.
+//
+// Spectator chat is for accounts only: an anonymous spectator reads along but
+// does not write, because a sanction has nothing to attach to a session that
+// can be thrown away. The seats may always talk, signed in or not — they are
+// already accountable for the game — except against a bot, which has nothing
+// to say.
+package chat
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+const (
+	// MaxLength bounds a line, in characters.
+	MaxLength = 140
+	// backlog is how many lines a joining socket is caught up with.
+	backlog = 50
+
+	// the chat_messages.channel values
+	playerChannel    = "player"
+	spectatorChannel = "spectator"
+)
+
+// The notices a sender may be told instead of their line being delivered.
+const (
+	noticeTooLong  = "That message is too long."
+	noticeSlowDown = "You are sending messages too quickly."
+	noticeBanned   = "Your account cannot chat."
+	noticeFiltered = "That message was not sent."
+	noticeSignIn   = "Sign in to chat with other spectators."
+	noticeFailed   = "Chat is unavailable right now."
+)
+
+// Say delivers one line from the socket described by meta to the chat its tag
+// names, and returns the frame, if any, that its sender alone should get back.
+func Say(tag proto.PayloadTag, text string, meta channel.SocketContext) []byte {
+	r, ch, ok := chatFor(tag, meta)
+	if !ok {
+		return nil
+	}
+	if ch == spectatorChannel && meta.Acct.ID == 0 {
+		return notice(tag, noticeSignIn)
+	}
+
+	text = clean(text)
+	if text == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(text) > MaxLength {
+		return notice(tag, noticeTooLong)
+	}
+
+	if !limits.allow(meta.SocketID, time.Now()) {
+		return notice(tag, noticeSlowDown)
+	}
+
+	if meta.Acct.ID != 0 {
+		ban, err := db.BanStateOf(meta.Acct.ID)
+		if err != nil {
+			// a sanction that cannot be read is not assumed absent
+			util.Error(str.CChat, "ban lookup failed uid=%s error=%s", meta.UID, err.Error())
+			return notice(tag, noticeFailed)
+		}
+		if ban.Banned {
+			return notice(tag, noticeBanned)
+		}
+	}
+
+	// the readers are fixed here, before the save, so a block lookup that
+	// fails can still refuse the line as unsaid
+	socks := channel.Map.GetSockMap(meta.Channel).Sockets()
+	hidden, err := blockedReaders(meta.Acct.ID, socks)
+	if err != nil {
+		// a block that cannot be read is not assumed absent either
+		util.Error(str.CChat, "block lookup failed uid=%s error=%s", meta.UID, err.Error())
+		return notice(tag, noticeFailed)
+	}
+
+	line := db.ChatLine{
+		RoomID:   r.ID,
+		Channel:  ch,
+		UID:      meta.UID,
+		Username: meta.Acct.Name,
+		Body:     text,
+		Filtered: config.NaughtyChat(text),
+		Created:  time.Now(),
+	}
+	if meta.Acct.ID != 0 {
+		id := meta.Acct.ID
+		line.UserID = &id
+	}
+	// off the socket's read loop: a slow write must not hold up the sender's
+	// moves, and the line is delivered from memory either way
+	go func() {
+		if err := db.SaveChatLine(line); err != nil {
+			util.Error(str.CChat, "[%s] chat line save failed uid=%s error=%s", r.ID, meta.UID, err.Error())
+		}
+	}()
+
+	if line.Filtered {
+		util.DebugFlag("chat", str.CChat, "[%s] withheld a %s line from uid=%s", r.ID, ch, meta.UID)
+		return notice(tag, noticeFiltered)
+	}
+
+	frame := proto.ChatMessage(tag, proto.ChatPayload{
+		Lines: []proto.ChatLine{lineOf(line)},
+	})
+	white, black := r.PlayerIDs()
+	for _, sock := range socks {
+		seated := sock.UID == white || sock.UID == black
+		_, blocked := hidden[sock.Acct.ID]
+		switch {
+		case ch == spectatorChannel && seated:
+		case ch == playerChannel && !seated:
+		case ch == playerChannel && sock.UID != meta.UID && r.ChatMuted(sock.UID):
+		case blocked:
+		default:
+			sock.Enqueue(frame)
+		}
+	}
+	return nil
+}
+
+// Mute sets whether the seat sending meta has muted the room's player chat,
+// and tells every tab of that seat.
+func Mute(muted bool, meta channel.SocketContext) {
+	r, ch, ok := chatFor(proto.PlayerChatTag, meta)
+	if !ok || ch != playerChannel {
+		return
+	}
+	r.MuteChat(meta.UID, muted)
+	channel.Unicast(proto.ChatMessage(proto.PlayerChatTag, proto.ChatPayload{
+		Muted: &muted,
+	}), meta)
+}
+
+// Connect catches a socket that has just joined a room's game channel up with
+// the conversation it can read: the player chat for a seat, the spectator
+// chat for everybody else. It queries the log, so callers run it off the
+// connection's goroutine.
+func Connect(socket *channel.Socket, roomID string, spectator bool) {
+	r, err := room.Get(roomID)
+	if r == nil || err != nil {
+		return
+	}
+	tag, ch := proto.SpectatorChatTag, spectatorChannel
+	if !spectator {
+		if r.HasBot() {
+			return
+		}
+		tag, ch = proto.PlayerChatTag, playerChannel
+	}
+
+	lines, err := db.RecentChat(roomID, ch, backlog)
+	if err != nil {
+		util.Error(str.CChat, "[%s] chat backlog load failed error=%s", roomID, err.Error())
+		return
+	}
+	var authors []int64
+	for _, l := range lines {
+		if l.UserID != nil {
+			authors = append(authors, *l.UserID)
+		}
+	}
+	hidden, err := db.BlockedAmong(socket.Acct.ID, authors)
+	if err != nil {
+		util.Error(str.CChat, "[%s] chat backlog block lookup failed error=%s", roomID, err.Error())
+		return
+	}
+	p := proto.ChatPayload{Backlog: true, Lines: []proto.ChatLine{}}
+	muted := !spectator && r.ChatMuted(socket.UID)
+	for _, l := range lines {
+		if muted && l.UID != socket.UID {
+			continue
+		}
+		if l.UserID != nil {
+			if _, blocked := hidden[*l.UserID]; blocked {
+				continue
+			}
+		}
+		p.Lines = append(p.Lines, lineOf(l))
+	}
+	if !spectator {
+		p.Muted = &muted
+	}
+	socket.Enqueue(proto.ChatMessage(tag, p))
+}
+
+// blockedReaders returns the accounts among the readers that share a block
+// with the sender, whose lines neither side sees. An anonymous sender has no
+// blocks, and asks nothing.
+func blockedReaders(sender int64, socks []*channel.Socket) (map[int64]struct{}, error) {
+	if sender == 0 {
+		return nil, nil
+	}
+	var ids []int64
+	for _, sock := range socks {
+		if sock.Acct.ID != 0 && sock.Acct.ID != sender {
+			ids = append(ids, sock.Acct.ID)
+		}
+	}
+	return db.BlockedAmong(sender, ids)
+}
+
+// chatFor resolves the room and the conversation a frame is addressed to,
+// refusing anything but a room's own game channel and a sender on the side
+// of the tag they used.
+func chatFor(tag proto.PayloadTag, meta channel.SocketContext) (*room.Instance, string, bool) {
+	// the waiting page, the TV and the notification channel carry no chat
+	if meta.RoomID == "" || meta.Channel != meta.RoomID {
+		return nil, "", false
+	}
+	r, err := room.Get(meta.RoomID)
+	if r == nil || err != nil {
+		return nil, "", false
+	}
+	switch tag {
+	case proto.PlayerChatTag:
+		if meta.IsSpectator || !r.IsPlayer(meta.UID) || r.HasBot() {
+			return nil, "", false
+		}
+		return r, playerChannel, true
+	case proto.SpectatorChatTag:
+		if !meta.IsSpectator {
+			return nil, "", false
+		}
+		return r, spectatorChannel, true
+	}
+	return nil, "", false
+}
+
+// clean trims a line and drops control characters, so nothing a reader sees
+// can break a line or hide itself.
+func clean(text string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text))
+}
+
+// lineOf is how a logged line reads on the wire.
+func lineOf(l db.ChatLine) proto.ChatLine {
+	from := l.Username
+	if from == "" {
+		from = "Anonymous"
+	}
+	return proto.ChatLine{From: from, Text: l.Body}
+}
+
+// notice builds the frame telling a sender why their line went nowhere.
+func notice(tag proto.PayloadTag, text string) []byte {
+	return proto.ChatMessage(tag, proto.ChatPayload{Notice: text})
+}
@@ -0,0 +1,33 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/dechristopher/lio/db"
+)
+
+// TestClean strips what could break or hide a line, and nothing else.
+func TestClean(t *testing.T) {
+	for in, want := range map[string]string{
+		"  gg  ":          "gg",
+		"good\ngame":      "goodgame",
+		"a\u0000b\u0007c": "abc",
+		"½ draw? 🤝":       "½ draw? 🤝",
+		"\t\r\n":          "",
+	} {
+		if got := clean(in); got != want {
+			t.Errorf("clean(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestLineOfNamesAnonymous gives a line with no account name a readable
+// sender rather than an empty one.
+func TestLineOfNamesAnonymous(t *testing.T) {
+	if got := lineOf(db.ChatLine{Body: "hi"}); got.From != "Anonymous" || got.Text != "hi" {
+		t.Fatalf("lineOf = %+v", got)
+	}
+	if got := lineOf(db.ChatLine{Username: "drew", Body: "gl"}); got.From != "drew" {
+		t.Fatalf("lineOf = %+v", got)
+	}
+}
@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// burst is how many lines a socket may send back to back.
+	burst = 4
+	// refill is how often a socket earns one more line once it has spent its
+	// burst: a conversation, not a flood.
+	refill = 2 * time.Second
+	// idleFor is how long a socket's bucket is kept after its last line. A
+	// full bucket is the same as no bucket, so anything idle this long is
+	// forgotten rather than kept for a socket that may already be gone.
+	idleFor = time.Minute
+)
+
+// limiter is a token bucket per socket. Per socket rather than per session on
+// purpose: the socket is what the ws handler has in hand, and a bucket keyed
+// by connection dies with it. A player who opens a second tab gets a second
+// bucket, which doubles a rate that is already only a nuisance bound; the ban
+// and the filter are the real gates.
+type limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+var limits = &limiter{buckets: make(map[string]*bucket)}
+
+// allow spends one token from the socket's bucket, reporting whether it had
+// one to spend.
+func (l *limiter) allow(socketID string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) > idleFor {
+		for id, b := range l.buckets {
+			if now.Sub(b.last) > idleFor {
+				delete(l.buckets, id)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.buckets[socketID]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[socketID] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() / refill.Seconds()
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
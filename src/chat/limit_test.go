@@ -0,0 +1,45 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLimiterBurstAndRefill spends a socket's burst, is refused, and earns a
+// line back after one refill.
+func TestLimiterBurstAndRefill(t *testing.T) {
+	l := &limiter{buckets: make(map[string]*bucket)}
+	t0 := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < burst; i++ {
+		if !l.allow("s1", t0) {
+			t.Fatalf("line %d of the burst refused", i+1)
+		}
+	}
+	if l.allow("s1", t0) {
+		t.Fatal("a line past the burst was allowed")
+	}
+	if !l.allow("s2", t0) {
+		t.Fatal("another socket was limited by the first")
+	}
+	if l.allow("s1", t0.Add(refill/2)) {
+		t.Fatal("a line was allowed before a refill")
+	}
+	if !l.allow("s1", t0.Add(refill)) {
+		t.Fatal("no line allowed after a refill")
+	}
+}
+
+// TestLimiterForgetsIdleSockets drops a bucket nobody has used for idleFor.
+func TestLimiterForgetsIdleSockets(t *testing.T) {
+	l := &limiter{buckets: make(map[string]*bucket)}
+	t0 := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	l.allow("gone", t0)
+	l.allow("here", t0.Add(2*idleFor))
+	if _, ok := l.buckets["gone"]; ok {
+		t.Fatal("an idle socket's bucket was kept")
+	}
+	if _, ok := l.buckets["here"]; !ok {
+		t.Fatal("the active socket's bucket was dropped")
+	}
+}
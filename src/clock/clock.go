@@ -7,6 +7,7 @@ import (
 	"github.com/dechristopher/octad/v2"
 
 	"github.com/dechristopher/lio/bus"
+	"github.com/dechristopher/lio/util"
 )
 
 // Channel is the engine monitoring bus channel
@@ -29,6 +30,13 @@ type Clock struct {
 	StateChannel   chan State
 	ackChannels    map[octad.Color]chan FlipAck
 
+	// rewindTo is the state a pending Rewind command applies, and rewound
+	// acknowledges it back to Rewind once applied (buffered, so the clock
+	// goroutine never blocks on it while holding mutex). rewindTo is guarded
+	// by mutex.
+	rewindTo Snapshot
+	rewound  chan struct{}
+
 	// quit terminates the running clock goroutine. Start creates a fresh one
 	// per run; Stop closes it. It is guarded by mutex.
 	quit chan struct{}
@@ -47,6 +55,10 @@ type Clock struct {
 	mutex            *sync.Mutex // prevent concurrent clock state changes
 
 	publisher *bus.Publisher
+
+	// log carries the owning game's ID (see SetLogger). Set once before the
+	// clock starts and read-only after, so it needs no guard.
+	log util.Logger
 }
 
 func (c *Clock) hasIncrement() bool {
@@ -101,6 +113,7 @@ func NewClock(tc TimeControl) *Clock {
 		// flip acknowledgement — see Stop and handleCommand
 		StateChannel: make(chan State, 1),
 		ackChannels:  make(map[octad.Color]chan FlipAck),
+		rewound:      make(chan struct{}, 1),
 		mutex:        &sync.Mutex{},
 		publisher:    bus.NewPublisher("clock", Channel),
 	}
@@ -114,6 +127,13 @@ func NewClock(tc TimeControl) *Clock {
 	return clock
 }
 
+// SetLogger sets the logger the clock's lines are written through, so they
+// carry the fields of the game the clock belongs to. It must be called before
+// the clock is started.
+func (c *Clock) SetLogger(l util.Logger) {
+	c.log = l
+}
+
 // flagged returns true if someone wins on time
 // and updates the victor in the clock state
 func (c *Clock) flagged() bool {
@@ -386,6 +406,100 @@ type Snapshot struct {
 	Victor         Victor      `json:"v,omitempty"`
 }
 
+// Berserk halves color's time budget: half of the starting time is charged to
+// them up front, the Arena tournament's trade of clock for a bonus point. It
+// reports false, changing nothing, once the game is decided or the player has
+// already spent half their budget (which includes having berserked). Whether
+// it is still early enough in the game is the caller's call — the clock cannot
+// tell White's uncharged first move from not having moved. A restored clock
+// carries the charge in its elapsed time, so a snapshot needs nothing extra.
+func (c *Clock) Berserk(color octad.Color) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.victor != NoVictor {
+		return false
+	}
+	pc := c.players[color]
+	half := CTime{t: pc.control.Time.t / 2}
+	if pc.elapsed.t >= half.t {
+		return false
+	}
+	pc.takeTime(half)
+	// the side to move's flag timer was armed for their full budget
+	if c.turn == color && !c.firstMove && !c.clockPaused && c.flagTimer != nil {
+		c.flagTimer.Reset(pc.remaining().t - time.Since(c.timestamp))
+	}
+	return true
+}
+
+// Rewind winds the clock back to an earlier point of the game for a takeback:
+// each side's elapsed time, the side to move and the first-move grace are set
+// from s, and the side to move is charged from now, with the flag timer armed
+// for their restored budget — or, rewound to the first move, the pre-start
+// countdown re-armed. A running clock applies it on its own goroutine,
+// through ControlChannel like a flip, so it can never interleave with one; a
+// paused clock (restored, not yet resumed) simply takes the values. It reports
+// false, changing nothing, once the clock has a victor or stops before the
+// command is taken.
+func (c *Clock) Rewind(s Snapshot) bool {
+	c.mutex.Lock()
+	if c.victor != NoVictor {
+		c.mutex.Unlock()
+		return false
+	}
+	if c.clockPaused {
+		c.applyRewind(s)
+		c.mutex.Unlock()
+		return true
+	}
+	c.rewindTo = s
+	quit := c.quit
+	c.mutex.Unlock()
+
+	// the goroutine may be flagging the side to move at this very moment, in
+	// which case it exits without ever reading the command
+	select {
+	case c.ControlChannel <- Rewind:
+	case <-quit:
+		return false
+	}
+	<-c.rewound
+	return true
+}
+
+// applyRewind sets the clock to the rewound state. The caller must hold mutex.
+func (c *Clock) applyRewind(s Snapshot) {
+	c.players[octad.White].elapsed = ToCTime(time.Duration(s.WhiteElapsedMs) * Millisecond)
+	c.players[octad.Black].elapsed = ToCTime(time.Duration(s.BlackElapsedMs) * Millisecond)
+	c.turn = s.Turn
+	c.firstMove = s.FirstMove
+	c.timestamp = time.Now()
+
+	if c.clockPaused {
+		return
+	}
+
+	// the side now to move gets a fresh delay, as after a flip
+	if c.delayTimer != nil && c.control.Delay.t != 0 {
+		c.delayTimer.Reset(c.control.Delay.t)
+		c.delayExpired = false
+	}
+	// rewound all the way to the start: back in the uncharged first-move
+	// grace, where nobody can flag, bounded by a fresh pre-start countdown
+	// as at Start (the first flip disarmed the old one, or it expired)
+	if c.firstMove {
+		if c.control.PreStart.t > 0 && c.preStartTimer != nil {
+			c.preStartDeadline = c.timestamp.Add(c.control.PreStart.t)
+			c.preStartTimer.Reset(c.control.PreStart.t)
+		}
+	} else if c.flagTimer != nil {
+		c.flagTimer.Reset(c.players[c.turn].remaining().t)
+	}
+
+	// publish clock state to monitors
+	c.publisher.Publish(Rewind, c.State(false))
+}
+
 // Snapshot captures the clock's persistable state. Safe to call on a running
 // clock: elapsed time is only ever advanced at a flip, so a mid-think capture
 // reads as-of-last-flip — exactly the restore semantics we want.
@@ -218,3 +218,84 @@ func TestFlipAckReportsMoveTiming(t *testing.T) {
 
 	c.Stop(false, true)
 }
+
+// TestPremoveChargesMinimumThink verifies a premove flip charges the
+// configured premove think time even when it lands at once, and reports that
+// charge in the acknowledgement.
+func TestPremoveChargesMinimumThink(t *testing.T) {
+	t.Setenv("PREMOVE_THINK", "250ms")
+	c := NewClock(TimeControl{Time: ToCTime(time.Minute)})
+	c.Start()
+	defer c.Stop(false, true)
+
+	withTimeout(t, time.Second, "premove handshake deadlocked", func() {
+		flip(c) // white's free first move
+
+		ack := c.GetAck()
+		c.ControlChannel <- Premove
+		fa := <-ack
+		if fa.Think.t < 250*time.Millisecond || fa.Think.t > 300*time.Millisecond {
+			t.Errorf("premove charged %s, want the 250ms floor", fa.Think)
+		}
+		if want := time.Minute - fa.Think.t; fa.Remaining.t != want {
+			t.Errorf("remaining %s, want %s", fa.Remaining, ToCTime(want))
+		}
+	})
+}
+
+// TestClockRewindRunning verifies a takeback's rewind on a running clock: the
+// elapsed times and the side to move are restored through the command channel,
+// and the restored side then flags on the rewound budget, not the old one.
+func TestClockRewindRunning(t *testing.T) {
+	c := NewClock(TimeControl{Time: ToCTime(time.Minute)})
+	c.Start()
+	defer c.Stop(false, true)
+
+	withTimeout(t, time.Second, "flips deadlocked", func() {
+		flip(c) // white's free first move
+		flip(c) // black's reply
+	})
+
+	var ok bool
+	withTimeout(t, time.Second, "rewind deadlocked", func() {
+		ok = c.Rewind(Snapshot{
+			WhiteElapsedMs: 59950,
+			BlackElapsedMs: 2000,
+			Turn:           octad.White,
+		})
+	})
+	if !ok {
+		t.Fatal("rewind of a running clock was refused")
+	}
+	if got := c.Snapshot(); got.Turn != octad.White || got.BlackElapsedMs != 2000 || got.FirstMove {
+		t.Fatalf("rewound snapshot = %+v", got)
+	}
+
+	select {
+	case s := <-c.StateChannel:
+		if s.Victor != Black {
+			t.Fatalf("flag victor = %v, want black", s.Victor)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("rewound clock never flagged white on the restored budget")
+	}
+}
+
+// TestClockRewindPausedAndDecided verifies a paused clock takes a rewind
+// directly, and a clock that already has a victor refuses one.
+func TestClockRewindPausedAndDecided(t *testing.T) {
+	tc := TimeControl{Time: ToCTime(time.Minute)}
+	c := Restore(tc, Snapshot{WhiteElapsedMs: 5000, BlackElapsedMs: 7000, Turn: octad.Black})
+	want := Snapshot{Turn: octad.White, FirstMove: true}
+	if !c.Rewind(want) {
+		t.Fatal("rewind of a paused clock was refused")
+	}
+	if got := c.Snapshot(); got != want {
+		t.Fatalf("rewound snapshot = %+v, want %+v", got, want)
+	}
+
+	done := Restore(tc, Snapshot{Turn: octad.White, Victor: Black})
+	if done.Rewind(want) {
+		t.Fatal("a decided clock accepted a rewind")
+	}
+}
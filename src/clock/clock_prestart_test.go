@@ -133,3 +133,35 @@ func TestPreStartResetRearms(t *testing.T) {
 		t.Fatal("pre-start countdown should be re-armed after Reset + Start")
 	}
 }
+
+// TestPreStartRewindRearms ensures a takeback all the way to the first move
+// puts the game back in a bounded grace: the countdown runs again, and when it
+// lapses white goes on the clock without moving.
+func TestPreStartRewindRearms(t *testing.T) {
+	c := NewClock(TimeControl{Time: ToCTime(time.Minute), PreStart: ToCTime(30 * time.Millisecond)})
+	c.Start()
+	defer c.Stop(false, true)
+
+	withTimeout(t, time.Second, "flips deadlocked", func() {
+		flip(c) // white's free first move disarms the countdown
+		flip(c)
+	})
+	withTimeout(t, time.Second, "rewind deadlocked", func() {
+		if !c.Rewind(Snapshot{Turn: octad.White, FirstMove: true}) {
+			t.Error("rewind to the first move was refused")
+		}
+	})
+
+	if rem := c.State(true).PreStart; rem.t <= 0 || rem.t > 30*time.Millisecond {
+		t.Fatalf("expected a re-armed pre-start countdown, got %s", rem)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	state := c.State(true)
+	if state.PreStart.t != 0 {
+		t.Fatal("pre-start remaining should be zero after the re-armed expiry")
+	}
+	if state.WhiteTime.t >= time.Minute {
+		t.Fatalf("white should be draining after expiry, got %s remaining", state.WhiteTime)
+	}
+}
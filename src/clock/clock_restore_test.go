@@ -118,3 +118,32 @@ func TestTimeControlJSONRoundTrip(t *testing.T) {
 		t.Fatalf("round trip = %+v, want %+v", got, tc)
 	}
 }
+
+// TestBerserkHalvesBudget verifies berserk charges half the starting time once:
+// a second berserk (or one after the player has spent half their budget) is
+// refused, and the charge survives a snapshot round trip.
+func TestBerserkHalvesBudget(t *testing.T) {
+	tc := TimeControl{Time: ToCTime(60 * time.Second), Increment: ToCTime(time.Second)}
+
+	c := NewClock(tc)
+	if !c.Berserk(octad.Black) {
+		t.Fatal("first berserk refused")
+	}
+	if c.Berserk(octad.Black) {
+		t.Fatal("second berserk accepted")
+	}
+	state := c.State(true)
+	if state.WhiteTime.Milli() != 60000 || state.BlackTime.Milli() != 30000 {
+		t.Fatalf("remaining = %s / %s, want 60s / 30s", state.WhiteTime, state.BlackTime)
+	}
+
+	r := Restore(tc, c.Snapshot())
+	if got := r.State(true).BlackTime.Milli(); got != 30000 {
+		t.Fatalf("restored black remaining = %dms, want 30000ms", got)
+	}
+
+	c.players[octad.White].elapsed = ToCTime(40 * time.Second)
+	if c.Berserk(octad.White) {
+		t.Fatal("berserk accepted with less than half the budget left")
+	}
+}
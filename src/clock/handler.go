@@ -3,9 +3,9 @@ package clock
 import (
 	"time"
 
+	"github.com/dechristopher/lio/config"
 	"github.com/dechristopher/lio/lag"
 	"github.com/dechristopher/lio/str"
-	"github.com/dechristopher/lio/util"
 )
 
 // handleCommand will perform the command on the given clock and
@@ -18,10 +18,10 @@ func (c *Clock) handleCommand(cmd Command) bool {
 		c.flagTimer.Stop()
 	}
 
-	util.DebugFlag("clock", str.CClk, "Command: %d", cmd)
+	c.log.DebugFlag("clock", str.CClk, "Command: %d", cmd)
 
 	switch cmd {
-	case Flip:
+	case Flip, Premove:
 		// the flip ack reports this ply's cost: think time as actually charged
 		// (the drop in the mover's remaining budget, so lag compensation and
 		// the cap at a full budget are already reflected; zero on the
@@ -41,10 +41,18 @@ func (c *Clock) handleCommand(cmd Command) bool {
 			c.preStartDeadline = time.Time{}
 		} else {
 			// update elapsed time of current player
-			c.players[c.turn].takeTime(ToCTime(time.Since(c.timestamp)))
+			think := time.Since(c.timestamp)
+			if cmd == Premove {
+				// a premove costs at least the configured floor
+				think = max(think, config.PremoveThink())
+			}
+			c.players[c.turn].takeTime(ToCTime(think))
 
-			// compensate player for move processing lag
-			c.players[c.turn].giveTime(ToCTime(lag.Move.Get()))
+			// compensate player for move processing lag; a premove made no
+			// round trip, so there is no lag to give back
+			if cmd == Flip {
+				c.players[c.turn].giveTime(ToCTime(lag.Move.Get()))
+			}
 
 			// the charged think time is final here — before any increment
 			ack.Think = before.Diff(c.players[c.turn].remaining())
@@ -93,6 +101,13 @@ func (c *Clock) handleCommand(cmd Command) bool {
 		// publish clock state to monitors
 		c.publisher.Publish(cmd, c.State(false))
 
+		return false
+	case Rewind:
+		// the flag timer was stopped above; applyRewind re-arms it for the
+		// side the takeback puts back on move (or, back at the first move,
+		// re-arms the pre-start countdown instead)
+		c.applyRewind(c.rewindTo)
+		c.rewound <- struct{}{}
 		return false
 	default:
 		return false
@@ -102,8 +102,18 @@ type FlipAck struct {
 // Command constant for clock operations
 type Command int
 
-// Flip clock command
-const Flip Command = iota
+const (
+	// Flip ends the mover's turn: their think time is charged, any increment
+	// added, and the other side goes on the clock
+	Flip Command = iota
+	// Rewind winds the clock back to an earlier point of the game for a
+	// takeback (see Clock.Rewind)
+	Rewind
+	// Premove is a Flip for a move the server played from the mover's premove
+	// queue: it is charged at least the configured premove think time, and
+	// never compensated for lag (no round trip was made)
+	Premove
+)
 
 // Victor of the game
 type Victor int
@@ -0,0 +1,201 @@
+// Package cluster runs lio as several nodes sharing one Redis.
+//
+// A node is one lio process. Everything live about a room — its actor, its
+// clock, its sockets — is in the memory of exactly one node, and the rest of
+// the site only has to find it. So rather than share room state, a room is
+// pinned to the node that created it (the owner), and the others send
+// everything addressed to it there:
+//
+//   - the room directory (cache/nodes.go, room/directory.go) maps each live
+//     room to its owner as a lease the owner renews;
+//   - an HTTP request or a socket for a room lands on whichever node the
+//     load balancer picked, and a node that does not own the room forwards
+//     it to the one that does (forward.go, socket.go);
+//   - the sends that reach a *person* rather than a room — SendToAccount and
+//     its siblings — and the home page's live-games grid are published on one
+//     Redis channel and replayed on every node (fanout.go).
+//
+// A node announces itself with a heartbeat carrying its address and the
+// accounts online on it. When the heartbeat stops, its rooms are orphans:
+// the next request for one, on any node, adopts it from its snapshot
+// (room.Adopt) — the restart persistence this builds on, applied across
+// nodes. A draining node releases its rooms and leaves the cluster on the way
+// out, so a deploy hands every game over without waiting for a timeout.
+//
+// Without Redis none of this runs. cache.C is nil, Up leaves every hook unset,
+// and lio is the single process it always was.
+//
+// What stays per node, deliberately: the home digest (open challenges and the
+// roster, see home/cluster.go), matchmaking's queue and the tournaments
+// director, each of which holds its state in one process's memory and is
+// reached through that process.
+//
+// Two rules are NOT enforced across nodes, and a cluster does not support
+// them: the seat index behind one game at a time (room/busy.go) only knows
+// the rooms this node owns, so an account seated on one node reads as free
+// on another and can start a second game there; and the quick-pairing queue
+// only pairs the sessions queued on the same node, so two players whose
+// requests landed on different nodes wait without ever meeting. Both hold on
+// a single node, and across nodes only with sticky sessions that keep an
+// account on one node — which the load balancer has to provide. Up says so in
+// the log when a node joins.
+package cluster
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/dechristopher/lio/cache"
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/home"
+	"github.com/dechristopher/lio/presence"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+const (
+	// beat is how often a node refreshes its heartbeat and reads everybody
+	// else's.
+	beat = 5 * time.Second
+	// nodeTTL is how long a heartbeat outlives the node that stopped sending
+	// it: three missed beats. Past it the node is gone, its rooms can be
+	// adopted, and its games leave every other node's grid.
+	nodeTTL = 3 * beat
+)
+
+var (
+	// enabled is set once Up has joined the cluster.
+	enabled atomic.Bool
+	// leaving is set by Leave, and stops the heartbeat re-announcing a node
+	// that is on its way out.
+	leaving atomic.Bool
+	// node is this process's id (config.NodeID), fixed at Up.
+	node string
+	// elsewhere is the account ids online on every other node, as of the last
+	// beat.
+	elsewhere atomic.Pointer[[]int64]
+)
+
+// Up joins the cluster: it subscribes to the fan-out channel, writes the
+// first heartbeat, and installs the hooks that carry sends, grid events and
+// presence across nodes. Called at boot after cache.Up and before room
+// rehydration, which claims rooms in this node's name — a claim made before
+// the heartbeat exists would read as an orphan to every other node.
+//
+// A failure here leaves the node running alone rather than refusing to boot:
+// it can still serve every room it owns.
+func Up() {
+	if !cache.Ready() {
+		return
+	}
+	node = config.NodeID()
+	addr := config.NodeAddr()
+
+	if err := cache.Subscribe(receive); err != nil {
+		util.Error(str.CNode, "fan-out subscribe failed, running as a single node: %v", err)
+		return
+	}
+	if err := cache.PutNode(node, addr, onlineHere(), nodeTTL); err != nil {
+		util.Error(str.CNode, "heartbeat failed, running as a single node: %v", err)
+		return
+	}
+
+	go publisher()
+	channel.SetRelay(relayFanout)
+	home.SetRelay(relayHome)
+	presence.SetElsewhere(onlineElsewhere)
+	enabled.Store(true)
+	go heartbeat(addr)
+
+	util.Info(str.CNode, "node %s joined the cluster at %s", node, addr)
+	util.Info(str.CNode, "one game at a time and quick pairing are enforced per node only; keep each account on one node")
+}
+
+// Enabled reports whether this node joined a cluster at boot.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Node returns this node's id, empty when it is not in a cluster.
+func Node() string {
+	return node
+}
+
+// Leave takes this node out of the cluster for shutdown: it stops the
+// heartbeat, deletes it so the other nodes stop sending here at once, and
+// closes every socket it is relaying to another node with 1012 Service
+// Restart — the sockets channel.CloseAll does not know about. Called from the
+// drain, after room.Drain has released this node's rooms.
+func Leave() {
+	if !Enabled() {
+		return
+	}
+	leaving.Store(true)
+	if err := cache.DeleteNode(node); err != nil {
+		util.Error(str.CNode, "leaving the cluster failed: %v", err)
+	}
+	closeProxies(1012, "server restarting")
+}
+
+// heartbeat refreshes this node's heartbeat every beat, and reads the
+// others': the accounts online on them, and which nodes have gone since the
+// last beat.
+func heartbeat(addr string) {
+	tick := time.NewTicker(beat)
+	defer tick.Stop()
+
+	seen := make(map[string]struct{})
+	for range tick.C {
+		if leaving.Load() {
+			return
+		}
+		if err := cache.PutNode(node, addr, onlineHere(), nodeTTL); err != nil {
+			util.Error(str.CNode, "heartbeat failed, will retry: %v", err)
+			continue
+		}
+		nodes, err := cache.Nodes()
+		if err != nil {
+			util.Error(str.CNode, "reading the other nodes failed, will retry: %v", err)
+			continue
+		}
+
+		var online []int64
+		live := make(map[string]struct{}, len(nodes))
+		for id, ids := range nodes {
+			if id == node {
+				continue
+			}
+			live[id] = struct{}{}
+			online = append(online, ids...)
+		}
+		elsewhere.Store(&online)
+
+		for id := range seen {
+			if _, ok := live[id]; !ok {
+				util.Info(str.CNode, "node %s left the cluster", id)
+				home.NodeGone(id)
+			}
+		}
+		seen = live
+	}
+}
+
+// onlineHere returns the account ids holding a socket on this node.
+func onlineHere() []int64 {
+	var ids []int64
+	for _, acct := range channel.Connected() {
+		if acct.ID != 0 {
+			ids = append(ids, acct.ID)
+		}
+	}
+	return ids
+}
+
+// onlineElsewhere is presence's view of the other nodes.
+func onlineElsewhere() []int64 {
+	if ids := elsewhere.Load(); ids != nil {
+		return *ids
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"encoding/json"
+
+	"github.com/dechristopher/lio/cache"
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/home"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Fan-out: every node publishes its site-wide sends and its grid events on
+// one Redis channel, and every node replays what the others published.
+//
+// Delivery is at most once. Pub/sub keeps nothing for a subscriber that was
+// disconnected when a message went out, and the outbound queue below drops
+// rather than block a room routine. Both are the same bargain the socket layer
+// already makes for a slow client: a notification is a database row first and
+// every socket connect re-reads the count, and the grid reconciles on the next
+// move of the game concerned.
+
+// outBuffer bounds the queue of messages waiting to be published. Sized like
+// the home hub's inbound queue, for the same reason: generous enough that a
+// drop means Redis is down, not that the site is busy.
+const outBuffer = 256
+
+var outbound = make(chan []byte, outBuffer)
+
+// envelope is one message on the fan-out channel. Exactly one of Send and
+// Home is set.
+type envelope struct {
+	From string          `json:"f"`
+	Send *channel.Fanout `json:"s,omitempty"`
+	Home *home.Event     `json:"h,omitempty"`
+}
+
+// relayFanout is the channel package's relay.
+func relayFanout(f channel.Fanout) {
+	publish(envelope{Send: &f})
+}
+
+// relayHome is the home hub's relay.
+func relayHome(e home.Event) {
+	publish(envelope{Home: &e})
+}
+
+// publish queues an envelope for the publisher goroutine. It never blocks:
+// the callers are room routines and send paths that must not wait on Redis.
+func publish(e envelope) {
+	e.From = node
+	msg, err := json.Marshal(e)
+	if err != nil {
+		util.Error(str.CNode, "fan-out marshal failed: %v", err)
+		return
+	}
+	select {
+	case outbound <- msg:
+	default:
+		// queue full: Redis is slow or gone. Drop, as above.
+	}
+}
+
+// publisher drains the outbound queue in order, one publish at a time.
+func publisher() {
+	for msg := range outbound {
+		if err := cache.Publish(msg); err != nil {
+			util.Error(str.CNode, "fan-out publish failed: %v", err)
+		}
+	}
+}
+
+// receive replays a message another node published. This node's own
+// messages come back too, and are skipped: they were delivered locally before
+// they were published.
+func receive(msg []byte) {
+	var e envelope
+	if err := json.Unmarshal(msg, &e); err != nil {
+		util.Error(str.CNode, "fan-out message unreadable: %v", err)
+		return
+	}
+	if e.From == "" || e.From == node {
+		return
+	}
+	switch {
+	case e.Send != nil:
+		channel.Deliver(*e.Send)
+	case e.Home != nil:
+		e.Home.Node = e.From
+		home.Receive(*e.Home)
+	}
+}
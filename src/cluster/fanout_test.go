@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dechristopher/lio/channel"
+)
+
+// TestReceiveReplaysOtherNodesOnly: every node hears its own publishes back
+// from Redis, and must skip them — they were delivered locally before they
+// were sent — while another node's send is delivered here.
+func TestReceiveReplaysOtherNodesOnly(t *testing.T) {
+	node = "n1"
+	t.Cleanup(func() { node = "" })
+
+	sm := channel.Map.GetSockMap("fanout-test")
+	s := channel.NewSocket(nil, "uid-fanout", "c1", "", channel.Account{ID: 5})
+	sm.Track(s)
+	t.Cleanup(func() { sm.UnTrack("uid-fanout", "c1") })
+
+	msg := func(from string) []byte {
+		b, err := json.Marshal(envelope{From: from, Send: &channel.Fanout{
+			Kind: channel.FanoutAccounts, IDs: []int64{5}, Data: []byte(`{"t":"nt"}`),
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	receive(msg("n1"))
+	if got := s.Queued(); got != 0 {
+		t.Fatalf("this node's own publish was delivered again (%d queued)", got)
+	}
+	receive(msg("n2"))
+	if got := s.Queued(); got != 1 {
+		t.Fatalf("socket has %d messages queued, want 1 (the other node's)", got)
+	}
+}
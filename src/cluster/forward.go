@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+
+	"github.com/dechristopher/lio/cache"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Forwarding: a request for a room another node owns is sent on to that node
+// and its answer returned as this node's. The request goes over unchanged —
+// cookies, bearer token and the edge's client-address headers included — so
+// the owner authenticates and rate-limits it exactly as if the load balancer
+// had picked it in the first place.
+//
+// A forwarded request is marked, and a marked request is never forwarded
+// again: two nodes that briefly disagree about an owner must not bounce a
+// request between them. The owner answers from what it has, which at worst is
+// "room gone".
+
+// forwardedHeader marks a request one node forwarded to another, naming the
+// node that sent it.
+const forwardedHeader = "X-Lio-Node"
+
+// forwardTimeout bounds a forwarded request. Room pages and posts are quick,
+// so anything slower is a node in trouble.
+const forwardTimeout = 10 * time.Second
+
+// clients holds one pooled HTTP client per owner address.
+var clients sync.Map
+
+// Locate finds a live room on the site. It returns the room when this node
+// runs it (adopting it first when it was orphaned by a node that has gone),
+// or the owning node's address when another node does; neither when the room
+// is not live anywhere.
+func Locate(id string) (r *room.Instance, owner string) {
+	if r, err := room.Get(id); err == nil {
+		return r, ""
+	}
+	if !Enabled() {
+		return nil, ""
+	}
+	owned, addr, found, err := cache.RoomOwner(id)
+	if err != nil {
+		util.Error(str.CNode, "[%s] room owner lookup failed: %v", id, err)
+		return nil, ""
+	}
+	if found && owned != node {
+		return nil, addr
+	}
+	if r, ok := room.Adopt(id); ok {
+		return r, ""
+	}
+	return nil, ""
+}
+
+// Forwarded reports whether a request was forwarded here by another node.
+func Forwarded(c fiber.Ctx) bool {
+	return c.Get(forwardedHeader) != ""
+}
+
+// Room resolves the room an HTTP request is about. When another node owns it
+// the request is forwarded there, forwarded is true, and the caller returns
+// err as its own result; otherwise r is the room, nil if it is not live.
+func Room(c fiber.Ctx, id string) (r *room.Instance, forwarded bool, err error) {
+	r, owner := Locate(id)
+	if owner == "" || Forwarded(c) {
+		return r, false, nil
+	}
+	return nil, true, forward(c, owner)
+}
+
+// forward sends the request to the node at addr and answers with its
+// response.
+func forward(c fiber.Ctx, addr string) error {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	c.Request().CopyTo(req)
+	req.Header.Set(forwardedHeader, node)
+	// the answer is compressed on the way out of this node, like any other;
+	// a compressed body from the owner would be compressed twice
+	req.Header.Del(fiber.HeaderAcceptEncoding)
+
+	if err := hostClient(addr).DoTimeout(req, c.Response(), forwardTimeout); err != nil {
+		util.Error(str.CNode, "forward to %s failed path=%s error=%s", addr, c.Path(), err.Error())
+		return c.SendStatus(fiber.StatusBadGateway)
+	}
+	return nil
+}
+
+// hostClient returns the pooled client for one node's address.
+func hostClient(addr string) *fasthttp.HostClient {
+	if hc, ok := clients.Load(addr); ok {
+		return hc.(*fasthttp.HostClient)
+	}
+	hc, _ := clients.LoadOrStore(addr, &fasthttp.HostClient{
+		Addr:         addr,
+		ReadTimeout:  forwardTimeout,
+		WriteTimeout: forwardTimeout,
+	})
+	return hc.(*fasthttp.HostClient)
+}
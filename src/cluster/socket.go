@@ -0,0 +1,168 @@
+package cluster
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	fws "github.com/fasthttp/websocket"
+	"github.com/gofiber/contrib/v3/websocket"
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Socket relaying: a room socket that lands on a node which does not own the
+// room is accepted here and relayed, frame for frame, to the same path on the
+// owner. The browser sees one socket; the owner sees an ordinary connection
+// carrying the browser's own cookies, and seats it exactly as it would have.
+//
+// Relayed rather than redirected because the socket has no redirect: a
+// WebSocket handshake cannot be answered with a 3xx, and a redirect frame
+// would need the client to reach a particular node, which the load balancer
+// in front of them does not offer.
+//
+// Close frames cross in both directions with their codes intact. The client
+// acts on those codes (closeNoIdentity, 1012, the moderation closes), so a
+// relay that swallowed them would break every recovery path it has.
+
+// dialTimeout bounds the handshake with the owner.
+const dialTimeout = 5 * time.Second
+
+// closeTryAgain is the close sent when the owner cannot be reached: 1013 Try
+// Again Later, which the client treats as any other drop and reconnects —
+// by then the room has likely been adopted by a node it can reach.
+const closeTryAgain = 1013
+
+// handshakeHeaders are the request headers that belong to this hop's
+// handshake, which the dialer writes itself.
+var handshakeHeaders = map[string]bool{
+	"Host":                     true,
+	"Upgrade":                  true,
+	"Connection":               true,
+	"Content-Length":           true,
+	"Sec-Websocket-Key":        true,
+	"Sec-Websocket-Version":    true,
+	"Sec-Websocket-Extensions": true,
+	"Sec-Websocket-Protocol":   true,
+}
+
+// proxies is every client connection this node is relaying, for Leave.
+var proxies sync.Map
+
+// ProxySocket returns the socket handler that relays a connection to the
+// node at addr. Everything it needs is copied out of ctx now: the ctx is
+// recycled once the handshake completes, and the handler runs for the
+// socket's whole life (see www/ws connHandler).
+func ProxySocket(ctx fiber.Ctx, addr string) func(*websocket.Conn) {
+	target := "ws://" + addr + strings.Clone(string(ctx.Request().URI().RequestURI()))
+	header := http.Header{}
+	for k, v := range ctx.Request().Header.All() {
+		key := http.CanonicalHeaderKey(string(k))
+		if !handshakeHeaders[key] {
+			header.Add(key, string(v))
+		}
+	}
+	header.Set(forwardedHeader, node)
+
+	return func(c *websocket.Conn) {
+		dialer := fws.Dialer{
+			HandshakeTimeout:  dialTimeout,
+			EnableCompression: true,
+		}
+		up, resp, err := dialer.Dial(target, header)
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+		if err != nil {
+			util.Error(str.CNode, "socket relay to %s failed: %v", addr, err)
+			_ = c.WriteControl(fws.CloseMessage,
+				fws.FormatCloseMessage(closeTryAgain, "room moving"),
+				time.Now().Add(channel.WriteWait))
+			_ = c.Close()
+			return
+		}
+
+		proxies.Store(c.Conn, struct{}{})
+		defer proxies.Delete(c.Conn)
+
+		relaySocket(c.Conn, up)
+	}
+}
+
+// relaySocket pumps frames both ways until either side closes, then closes
+// both. The client side is pinged like any socket this node serves; the
+// owner pings the relay, which answers for the client.
+func relaySocket(client, up *fws.Conn) {
+	for _, conn := range []*fws.Conn{client, up} {
+		conn := conn
+		_ = conn.SetReadDeadline(time.Now().Add(channel.PongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(channel.PongWait))
+		})
+	}
+	up.SetPingHandler(func(data string) error {
+		_ = up.SetReadDeadline(time.Now().Add(channel.PongWait))
+		return up.WriteControl(fws.PongMessage, []byte(data), time.Now().Add(channel.WriteWait))
+	})
+
+	done := make(chan struct{}, 2)
+	go pipe(client, up, done)
+	go pipe(up, client, done)
+
+	ping := time.NewTicker(channel.PingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case <-done:
+			_ = client.Close()
+			_ = up.Close()
+			<-done
+			return
+		case <-ping.C:
+			_ = client.WriteControl(fws.PingMessage, nil, time.Now().Add(channel.WriteWait))
+		}
+	}
+}
+
+// pipe copies frames from src to dst until src fails, then hands its close
+// code on to dst.
+func pipe(dst, src *fws.Conn, done chan<- struct{}) {
+	defer func() { done <- struct{}{} }()
+	for {
+		mt, data, err := src.ReadMessage()
+		if err != nil {
+			code, text := fws.CloseGoingAway, ""
+			var ce *fws.CloseError
+			// 1005 and 1006 describe a close without a frame, and may not be
+			// sent in one
+			if errors.As(err, &ce) && ce.Code != fws.CloseNoStatusReceived &&
+				ce.Code != fws.CloseAbnormalClosure {
+				code, text = ce.Code, ce.Text
+			}
+			_ = dst.WriteControl(fws.CloseMessage, fws.FormatCloseMessage(code, text),
+				time.Now().Add(channel.WriteWait))
+			return
+		}
+		_ = src.SetReadDeadline(time.Now().Add(channel.PongWait))
+		_ = dst.SetWriteDeadline(time.Now().Add(channel.WriteWait))
+		if err := dst.WriteMessage(mt, data); err != nil {
+			return
+		}
+	}
+}
+
+// closeProxies closes every relayed client connection with the given code.
+func closeProxies(code int, reason string) {
+	proxies.Range(func(k, _ interface{}) bool {
+		conn := k.(*fws.Conn)
+		_ = conn.WriteControl(fws.CloseMessage, fws.FormatCloseMessage(code, reason),
+			time.Now().Add(channel.WriteWait))
+		_ = conn.Close()
+		return true
+	})
+}
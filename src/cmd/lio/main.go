@@ -3,24 +3,40 @@ package main
 import (
 	"embed"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"strings"
+	"runtime"
 	"time"
 
 	"github.com/dechristopher/lio/crypt"
 	"github.com/joho/godotenv"
 
+	"github.com/dechristopher/lio/analysis"
 	"github.com/dechristopher/lio/auth"
 	"github.com/dechristopher/lio/backfill"
+	"github.com/dechristopher/lio/bus"
 	"github.com/dechristopher/lio/cache"
+	"github.com/dechristopher/lio/cluster"
 	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/correspondence"
 	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/dispatch"
+	"github.com/dechristopher/lio/dump"
 	"github.com/dechristopher/lio/env"
+	"github.com/dechristopher/lio/fairplay"
+	"github.com/dechristopher/lio/mail"
+	"github.com/dechristopher/lio/matchmaking"
+	"github.com/dechristopher/lio/puzzle"
 	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/selfplay"
+	"github.com/dechristopher/lio/simul"
 	"github.com/dechristopher/lio/str"
 	"github.com/dechristopher/lio/systems"
+	"github.com/dechristopher/lio/tablebase"
+	"github.com/dechristopher/lio/tournament"
+	"github.com/dechristopher/lio/tune"
 	"github.com/dechristopher/lio/util"
 	"github.com/dechristopher/lio/www"
 )
@@ -30,6 +46,39 @@ import (
 // object store + db up first).
 var runBackfill *bool
 
+// genTablebase is the --gen-tablebase flag: solve the endgame tablebase
+// through tablebasePieces pieces into config.TablebaseDir and exit.
+var (
+	genTablebase    *bool
+	tablebasePieces *int
+)
+
+// selfPlay is the --selfplay flag and its options: play the bot personas
+// against each other and report their ratings, then exit (see package
+// selfplay).
+var (
+	selfPlay         *bool
+	selfPlayControl  *string
+	selfPlayVariants *string
+	selfPlaySweep    *bool
+	selfPlayRounds   *int
+	selfPlayParallel *int
+	selfPlayGap      *float64
+	selfPlayOut      *string
+)
+
+// tuneEval is the --tune flag and its options: fit the evaluation weights to
+// the archived games, write them and a before/after report, then exit (see
+// package tune). Consumed in main after systems.Run, since it reads the db.
+var (
+	tuneEval       *bool
+	tunePositions  *int
+	tuneIterations *int
+	tuneControl    *string
+	tuneRounds     *int
+	tuneOut        *string
+)
+
 var (
 	//go:embed static/*
 	static embed.FS
@@ -43,13 +92,28 @@ func init() {
 	// parse command line flags
 	isHealthCheck := flag.Bool(str.FHealth, false, str.FHealthUsage)
 	runBackfill = flag.Bool(str.FBackfill, false, str.FBackfillUsage)
+	genTablebase = flag.Bool(str.FGenTablebase, false, str.FGenTablebaseUsage)
+	tablebasePieces = flag.Int(str.FTablebasePieces, tablebase.DefaultPieces, str.FTablebasePiecesUsage)
+	selfPlay = flag.Bool(str.FSelfPlay, false, str.FSelfPlayUsage)
+	selfPlayControl = flag.String(str.FSelfPlayControl, selfplay.DefaultControl, str.FSelfPlayControlUsage)
+	selfPlayVariants = flag.String(str.FSelfPlayVariants, "", str.FSelfPlayVariantsUsage)
+	selfPlaySweep = flag.Bool(str.FSelfPlaySweep, false, str.FSelfPlaySweepUsage)
+	selfPlayRounds = flag.Int(str.FSelfPlayRounds, selfplay.DefaultRounds, str.FSelfPlayRoundsUsage)
+	selfPlayParallel = flag.Int(str.FSelfPlayParallel, runtime.GOMAXPROCS(0), str.FSelfPlayParallelUsage)
+	selfPlayGap = flag.Float64(str.FSelfPlayGap, selfplay.DefaultGap, str.FSelfPlayGapUsage)
+	selfPlayOut = flag.String(str.FSelfPlayOut, selfplay.DefaultOut, str.FSelfPlayOutUsage)
+	tuneEval = flag.Bool(str.FTune, false, str.FTuneUsage)
+	tunePositions = flag.Int(str.FTunePositions, tune.DefaultPositions, str.FTunePositionsUsage)
+	tuneIterations = flag.Int(str.FTuneIterations, tune.DefaultIterations, str.FTuneIterationsUsage)
+	tuneControl = flag.String(str.FTuneControl, selfplay.DefaultControl, str.FTuneControlUsage)
+	tuneRounds = flag.Int(str.FTuneRounds, tune.DefaultRounds, str.FTuneRoundsUsage)
+	tuneOut = flag.String(str.FTuneOut, tune.DefaultOut, str.FTuneOutUsage)
 	config.DebugFlagPtr = flag.String(str.FDebugFlags, "", str.FDebugFlagsUsage)
 	flag.Parse()
 
-	// parse out debug flags from command line options
-	for _, debugFlag := range strings.Split(*config.DebugFlagPtr, ",") {
-		config.DebugFlags[debugFlag] = true
-	}
+	// each debug flag boots its package at debug level; /system/log can
+	// change levels from there without a restart
+	util.EnableDebug(*config.DebugFlagPtr)
 
 	// run health check if told (this exits the process; the server never starts)
 	if *isHealthCheck {
@@ -57,6 +121,12 @@ func init() {
 		return
 	}
 
+	// tablebase generation and self-play (handled in main) need no crypto
+	// key; the former runs at image build time, where there is none
+	if *genTablebase || *selfPlay {
+		return
+	}
+
 	if !env.IsProd() {
 		// print development mode warning
 		util.Debug(str.CMain, str.MDevMode)
@@ -91,11 +161,77 @@ func executeHealthCheck() {
 	os.Exit(0)
 }
 
+// runSelfPlay brings up what self-play games need and runs the --selfplay
+// flags, printing the report it also writes to disk.
+func runSelfPlay() error {
+	cfg, err := selfplay.NewConfig(*selfPlayControl, *selfPlayVariants, *selfPlaySweep)
+	if err != nil {
+		return err
+	}
+	cfg.Rounds, cfg.Parallel, cfg.Gap, cfg.Out = *selfPlayRounds, *selfPlayParallel, *selfPlayGap, *selfPlayOut
+
+	bus.Up()
+	tablebase.Up()
+	dispatch.UpEngine()
+
+	report, err := selfplay.Run(cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report.String())
+	return nil
+}
+
+// runTune runs the --tune flags on the subsystems systems.Run brought up,
+// printing the report it also writes to disk.
+func runTune() error {
+	cfg := tune.NewConfig()
+	cfg.Positions, cfg.Iterations, cfg.Control, cfg.Rounds, cfg.Out =
+		*tunePositions, *tuneIterations, *tuneControl, *tuneRounds, *tuneOut
+
+	report, err := tune.Run(cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report.String())
+	return nil
+}
+
 // main does the things
 func main() {
 	// load .env if any
 	_ = godotenv.Load()
 
+	// keep the log ring's evicted lines on disk for /system/log, if configured
+	if dir := config.LogSpillDir(); dir != "" {
+		if err := util.StartLogSpill(dir); err != nil {
+			util.Error(str.CMain, "log spill disabled dir=%s error=%s", dir, err.Error())
+		}
+	}
+
+	// endgame tablebase generation: solve every table and write it out, then
+	// exit without serving. It needs no subsystem, so it runs before
+	// systems.Run — the Dockerfile runs it at image build time, where there
+	// are no secrets and no network:
+	//   lio --gen-tablebase --tablebase-pieces 4
+	if *genTablebase {
+		if err := tablebase.Generate(config.TablebaseDir(), *tablebasePieces); err != nil {
+			log.Fatalln(str.CMain, err.Error())
+		}
+		os.Exit(0)
+	}
+
+	// bot self-play: rate the persona ladder and exit without serving. It
+	// needs only the bus (clock flips publish to it), the tablebase and the
+	// engine dispatcher, so it runs before systems.Run and on any machine:
+	//   lio --selfplay --selfplay-sweep --selfplay-rounds 20
+	if *selfPlay {
+		if err := runSelfPlay(); err != nil {
+			log.Fatalln(str.CMain, err.Error())
+		}
+		os.Exit(0)
+	}
+
 	// initialize subsystems synchronously: the bus must be online before any
 	// room exists (clock flips publish to it), and everything below depends on
 	// config/secrets being readable. The chain is fast — the only network touch
@@ -113,20 +249,77 @@ func main() {
 		os.Exit(0)
 	}
 
+	// evaluation tuning: fit the weights to the archive and measure them in
+	// self-play, then exit without serving. Runs after systems.Run (db, the
+	// tablebase and the engine dispatcher up) and before the background
+	// evaluator would start competing with the self-play for the engine:
+	//   docker compose run --rm lio --tune --tune-rounds 10
+	if *tuneEval {
+		if err := runTune(); err != nil {
+			log.Fatalln(str.CMain, err.Error())
+		}
+		os.Exit(0)
+	}
+
 	// restart persistence (arch/STATE_PERSISTENCE_SCALING.md): bring the cache
 	// online, restore persisted rooms, then start the write-behind persister.
 	// Rehydration MUST complete before the listener below accepts connections,
 	// or reconnecting clients race the restore and get bounced as "room gone".
+	//
+	// With Redis up this node also joins the cluster (package cluster), before
+	// rehydration: on a multi-node deployment every node sees every snapshot,
+	// and the room directory decides which of them are this node's to restore.
 	cache.Up()
+	cluster.Up()
 	if cache.Ready() {
+		if cluster.Enabled() {
+			room.UpDirectory(cache.RoomOwners{Node: cluster.Node()})
+		}
 		room.RehydrateAll(cache.RoomSnapshots{})
 		room.UpPersister(cache.RoomSnapshots{})
 	}
 
+	// tournaments: restore the unfinished events and their pairings. After
+	// rehydration, so a restored pairing finds its room (a pairing whose room
+	// did not survive the restart is scored void), and before the listener, so
+	// a player reconnecting to an event page finds it running.
+	tournament.Up()
+
+	// simuls: likewise, after rehydration so a restored board finds its room
+	simul.Up()
+
+	// the quick-pairing matcher (see package matchmaking)
+	matchmaking.Up()
+
+	// the correspondence scheduler, flagging games whose side to move has run
+	// out of days (no-op unless Postgres is up: the row is the game)
+	correspondence.Up()
+
 	// optional background position evaluator (fills the deduped positions eval
 	// cache off the game path; no-op unless Postgres + the evaluator are enabled)
 	db.UpEvaluator()
 
+	// optional training puzzle miner, working through the archive behind the
+	// evaluator (no-op unless Postgres + lio_pg_puzzles are enabled)
+	puzzle.Up()
+
+	// fair-play sampling of rated games, fed by the analysis worker below, so
+	// it comes up first (no-op unless Postgres + lio_pg_fairplay are enabled)
+	fairplay.Up()
+
+	// post-game analysis: reviews games on request from the archive page, and
+	// every game as it is archived when lio_pg_analysis is enabled (no-op
+	// unless Postgres is up)
+	analysis.Up()
+
+	// monthly PGN database dumps behind /db (no-op unless Postgres, the object
+	// store and the dump bucket are all configured)
+	dump.Up()
+
+	// outbound email: the outbox worker and the weekly digest (no-op unless
+	// Postgres is up and a transport is configured, see package mail)
+	mail.Up()
+
 	// hourly expired-session sweep for the unified session system
 	// (arch/ACCOUNTS_AUTH_RATINGS.md)
 	auth.UpSweeper()
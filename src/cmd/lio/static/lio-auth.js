@@ -262,6 +262,9 @@
 	// --- edit profile modal (email + one-time casing-only username change) --
 	initEditProfileModal();
 
+	// --- delete account modal ----------------------------------------------
+	initDeleteAccountModal();
+
 	// --- logged-out: auth modal --------------------------------------------
 	const modal = document.getElementById('modalAccount');
 	if (!modal) { return; }
@@ -626,6 +629,92 @@
 		}
 	}
 
+	// ========================================================================
+	// Delete account modal (logged in). The form always sends the typed-out
+	// username and the password; the code field is sent as typed, and the server
+	// decides whether the account needed it. The passkey button runs the
+	// assertion against a challenge begun with the same password, then submits
+	// the assertion in place of the code. Success lands on the home page with no
+	// session left to come back to.
+	// ========================================================================
+	function initDeleteAccountModal() {
+		const delModal = document.getElementById('modalDeleteAccount');
+		const openBtn = document.getElementById('deleteAccountButton');
+		const form = document.getElementById('deleteAccountForm');
+		if (!delModal || !openBtn || !form) { return; }
+		const submitBtn = form.querySelector('button[type="submit"]');
+		const passkeyBtn = form.querySelector('[data-delete-passkey]');
+		if (passkeyBtn && !webAuthnSupported()) { passkeyBtn.classList.add('hidden'); }
+
+		const close = () => { delModal.classList.remove('open'); };
+		openBtn.addEventListener('click', () => {
+			// dismiss the profile popover (and its scrim) before opening, resetting
+			// it so it reopens pristine (navScript owns the reset)
+			if (window.__resetProfilePopover) { window.__resetProfilePopover(); }
+			const pp = document.getElementById('profilePopover');
+			if (pp) { pp.classList.add('hidden'); }
+			const scrim = document.getElementById('menuScrim');
+			if (scrim) { scrim.classList.remove('is-open'); }
+			form.reset();
+			showError(form);
+			delModal.classList.add('open');
+			form.confirm.focus();
+		});
+		const closeBtn = delModal.querySelector('.modal-close');
+		if (closeBtn) { closeBtn.addEventListener('click', close); }
+		delModal.addEventListener('click', (e) => { if (e.target === delModal) { close(); } });
+
+		const setBusy = (busy) => {
+			if (submitBtn) { submitBtn.disabled = busy; }
+			if (passkeyBtn) { passkeyBtn.disabled = busy; }
+		};
+
+		const send = async (passkey) => {
+			const body = {
+				confirm: form.confirm.value.trim(),
+				password: form.password.value,
+				code: form.code.value.trim(),
+			};
+			if (passkey) { body.passkey = passkey; }
+			const { status, data } = await post('/api/auth/delete', body);
+			if (status === 204) { window.location.href = '/'; return; }
+			showError(form, data.error || 'Could not delete the account.');
+		};
+
+		form.addEventListener('submit', async (e) => {
+			e.preventDefault();
+			showError(form);
+			setBusy(true);
+			try {
+				await send(null);
+			} catch (err) {
+				showError(form, 'Network error — try again.');
+			} finally {
+				setBusy(false);
+			}
+		});
+
+		if (passkeyBtn) {
+			passkeyBtn.addEventListener('click', async () => {
+				showError(form);
+				setBusy(true);
+				try {
+					const begin = await post('/api/auth/delete/passkey', { password: form.password.value });
+					if (begin.status !== 200 || !begin.data.publicKey) {
+						showError(form, begin.data.error || 'Could not start passkey verification.');
+						return;
+					}
+					const assertion = await navigator.credentials.get({ publicKey: prepAssertion(begin.data.publicKey) });
+					await send(credentialToJSON(assertion));
+				} catch (err) {
+					showError(form, 'Passkey verification was cancelled.');
+				} finally {
+					setBusy(false);
+				}
+			});
+		}
+	}
+
 	// ========================================================================
 	// Two-factor & passkey management modal (logged in). The body is rendered
 	// entirely here from GET /api/auth/mfa/status and swapped through the enroll
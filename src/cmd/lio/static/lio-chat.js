@@ -0,0 +1,94 @@
+// lio-chat.js — the room chat panel (package chat).
+//
+// The panel reads one conversation: the player chat ('ch') for a seat, the
+// spectator chat ('cs') for everybody else, as the server rendered it into
+// data-chat. Lines arrive on the room socket lio.js owns; a backlog frame on
+// connect replaces the list, so a reconnect never doubles it. The server is
+// the only gate — seat, rate limit, ban, filter — and anything it refused
+// comes back as a notice addressed to this socket alone.
+(function () {
+	const root = document.getElementById('chat');
+	if (!root || !window.handlers) {
+		return;
+	}
+	const tag = root.dataset.chat === 'spectator' ? 'cs' : 'ch';
+	const list = document.getElementById('chat-lines');
+	const notice = document.getElementById('chat-notice');
+	const form = document.getElementById('chat-form');
+	const input = document.getElementById('chat-input');
+	const mute = document.getElementById('chat-mute');
+
+	// the list is a short scrolling window, not an archive
+	const keep = 100;
+	let noticeTimer = null;
+
+	const showNotice = (text) => {
+		notice.textContent = text;
+		notice.classList.remove('hidden');
+		clearTimeout(noticeTimer);
+		noticeTimer = setTimeout(() => notice.classList.add('hidden'), 4000);
+	};
+
+	const append = (line) => {
+		const li = document.createElement('li');
+		const from = document.createElement('span');
+		from.className = 'game-chat-from';
+		from.textContent = line.u;
+		li.appendChild(from);
+		li.appendChild(document.createTextNode(line.x));
+		list.appendChild(li);
+		while (list.children.length > keep) {
+			list.removeChild(list.firstChild);
+		}
+	};
+
+	const setMuted = (muted) => {
+		if (!mute) {
+			return;
+		}
+		mute.setAttribute('aria-pressed', muted ? 'true' : 'false');
+		mute.textContent = muted ? 'Unmute' : 'Mute';
+		mute.title = muted
+			? "Show your opponent's messages again"
+			: "Hide your opponent's messages in this game";
+	};
+
+	window.handlers.set(tag, (message) => {
+		const d = message.d || {};
+		if (d.n) {
+			showNotice(d.n);
+		}
+		if (d.mu !== undefined) {
+			setMuted(d.mu);
+		}
+		if (d.b) {
+			list.replaceChildren();
+		}
+		(d.l || []).forEach(append);
+		if (d.b || (d.l && d.l.length)) {
+			list.scrollTop = list.scrollHeight;
+		}
+	});
+
+	if (form && input) {
+		form.addEventListener('submit', (e) => {
+			e.preventDefault();
+			const text = input.value.trim();
+			if (!text) {
+				return;
+			}
+			if (send(buildCommand(tag, {x: text}))) {
+				input.value = '';
+			} else {
+				showNotice('Not connected — try again in a moment.');
+			}
+		});
+	}
+
+	if (mute) {
+		mute.addEventListener('click', () => {
+			const muted = mute.getAttribute('aria-pressed') !== 'true';
+			send(buildCommand(tag, {mu: muted}));
+		});
+	}
+})();
@@ -0,0 +1,300 @@
+// lio-correspondence.js — a correspondence game's board.
+//
+// Like the trainer (lio-training.js), this owns none of the rules: it renders
+// the board from the #correspondence-data payload, sends one action at a time
+// to /api/correspondence/<id>, and redraws from the state the server answers
+// with. There is no socket; while the page waits on the opponent it polls the
+// same endpoint, slowly, since a reply may be days away. A change of status
+// (the game accepted, or over) reloads the page, whose controls depend on it.
+(function () {
+	'use strict';
+
+	const mount = document.getElementById('corr-board');
+	const dataEl = document.getElementById('correspondence-data');
+	if (!mount || !dataEl || typeof Octadground === 'undefined') {
+		return;
+	}
+
+	let state;
+	try {
+		state = JSON.parse(dataEl.textContent);
+	} catch (e) {
+		return;
+	}
+
+	const api = '/api/correspondence/' + encodeURIComponent(state.id);
+	// the poll interval while waiting on the opponent
+	const pollEvery = 30000;
+
+	const sfx = {};
+	if (typeof Howl !== 'undefined') {
+		sfx.move = new Howl({src: ['/res/sfx/move.ogg', '/res/sfx/move.mp3'], preload: true, volume: 0.75});
+		sfx.check = new Howl({src: ['/res/sfx/check.ogg', '/res/sfx/check.mp3'], preload: true, volume: 0.9});
+	}
+	const play = (name) => {
+		const s = sfx[name];
+		if (s) {
+			try {
+				s.play();
+			} catch (e) { /* audio is a nicety, never a failure */ }
+		}
+	};
+
+	const el = {
+		status: document.getElementById('corr-status'),
+		deadline: document.getElementById('corr-deadline'),
+		error: document.getElementById('corr-error'),
+		draw: document.getElementById('corr-draw'),
+		decline: document.getElementById('corr-decline'),
+		resign: document.getElementById('corr-resign'),
+		moves: document.getElementById('corr-moves'),
+		promoShade: document.getElementById('corr-promo-shade'),
+		promo: document.getElementById('corr-promo'),
+	};
+
+	const seat = state.seat || '';
+	const seatCode = seat === 'black' ? 'b' : 'w';
+	let busy = false;
+
+	const boardOf = (o) => (o || '').split(' ')[0];
+	const frozen = {free: false, color: undefined, dests: new Map()};
+
+	function destMap(v) {
+		const m = new Map();
+		Object.keys(v || {}).forEach((k) => m.set(k, v[k]));
+		return m;
+	}
+
+	const myMove = (s) => s.status === 'active' && seat !== '' && s.t === seat;
+
+	function movableFor(s) {
+		if (!myMove(s)) {
+			return frozen;
+		}
+		return {free: false, color: seat, dests: destMap(s.v)};
+	}
+
+	// no premoves: the opponent's reply may be days away, and a move is only
+	// ever sent by hand
+	const og = Octadground(mount, {
+		ofen: boardOf(state.o),
+		orientation: seat === 'black' ? 'black' : 'white',
+		turnColor: state.t,
+		coordinates: true,
+		lastMove: state.lm || [],
+		check: !!state.k,
+		highlight: {lastMove: true, check: true},
+		movable: movableFor(state),
+		draggable: {enabled: true},
+		premovable: {enabled: false},
+		selectable: {enabled: !!window.isMobile},
+		events: {move: onBoardMove},
+	});
+
+	function say(text) {
+		if (el.error) {
+			el.error.textContent = text || '';
+		}
+	}
+
+	function timeLeft(deadline) {
+		const left = deadline - Date.now();
+		if (left <= 0) {
+			return 'out of time';
+		}
+		const hour = 3600000;
+		const days = Math.floor(left / (24 * hour));
+		const hours = Math.floor((left % (24 * hour)) / hour);
+		const unit = (n, w) => n + ' ' + w + (n === 1 ? '' : 's');
+		if (days > 0 && hours > 0) {
+			return unit(days, 'day') + ' ' + unit(hours, 'hour') + ' left';
+		}
+		if (days > 0) {
+			return unit(days, 'day') + ' left';
+		}
+		if (hours > 0) {
+			return unit(hours, 'hour') + ' left';
+		}
+		return unit(Math.floor(left / 60000) + 1, 'minute') + ' left';
+	}
+
+	function renderMoves(san) {
+		if (!el.moves) {
+			return;
+		}
+		el.moves.textContent = '';
+		for (let i = 0; i < san.length; i += 2) {
+			const li = document.createElement('li');
+			const no = document.createElement('span');
+			no.className = 'corr-move-no';
+			no.textContent = (i / 2 + 1) + '.';
+			const w = document.createElement('span');
+			w.textContent = san[i];
+			const b = document.createElement('span');
+			b.textContent = san[i + 1] || '';
+			li.append(no, ' ', w, ' ', b);
+			el.moves.appendChild(li);
+		}
+	}
+
+	// renderDraw shows the draw controls for the standing offer: none, the
+	// viewer's own, or the opponent's to accept or decline
+	function renderDraw(s) {
+		if (!el.draw) {
+			return;
+		}
+		const mine = s.draw === seatCode;
+		const theirs = !!s.draw && !mine;
+		el.draw.textContent = theirs ? 'Accept draw' : (mine ? 'Draw offered' : 'Offer draw');
+		el.draw.disabled = mine;
+		if (el.decline) {
+			el.decline.classList.toggle('hidden', !theirs);
+		}
+	}
+
+	function render(s) {
+		const before = state;
+		state = s;
+		if (s.status !== before.status) {
+			window.location.reload();
+			return;
+		}
+		og.set({
+			ofen: boardOf(s.o),
+			turnColor: s.t,
+			lastMove: s.lm || [],
+			check: !!s.k,
+			movable: movableFor(s),
+		});
+		if (s.san.length !== before.san.length) {
+			play(s.k ? 'check' : 'move');
+		}
+		if (el.status && s.status === 'active') {
+			el.status.textContent = seat === ''
+				? (s.t === 'white' ? 'White' : 'Black') + ' to move'
+				: (myMove(s) ? 'Your move' : 'Waiting for your opponent\'s move');
+		}
+		if (el.deadline) {
+			el.deadline.textContent = s.deadline ? timeLeft(s.deadline) : '';
+		}
+		renderMoves(s.san || []);
+		renderDraw(s);
+	}
+
+	function send(body) {
+		if (busy) {
+			return;
+		}
+		busy = true;
+		say('');
+		og.set({movable: frozen});
+		fetch(api, {
+			method: 'POST',
+			headers: {'Content-Type': 'application/json'},
+			body: JSON.stringify(body),
+		}).then((r) => r.json().then((res) => ({ok: r.ok, res: res})))
+			.then(({ok, res}) => {
+				busy = false;
+				if (!ok) {
+					say(res.error || 'That did not work — try again.');
+					refresh();
+					return;
+				}
+				render(res);
+			})
+			.catch(() => {
+				busy = false;
+				say('Could not reach the server — check your connection and try again.');
+				og.set({ofen: boardOf(state.o), movable: movableFor(state)});
+			});
+	}
+
+	function refresh() {
+		fetch(api).then((r) => (r.ok ? r.json() : Promise.reject(r.status)))
+			.then(render)
+			.catch(() => { /* the next poll tries again */ });
+	}
+
+	function onBoardMove(orig, dest) {
+		if (!myMove(state) || busy) {
+			og.set({ofen: boardOf(state.o), turnColor: state.t});
+			return;
+		}
+		const piece = og.state.pieces.get(dest);
+		const last = seat === 'white' ? '4' : '1';
+		if (piece && piece.role === 'pawn' && dest[1] === last) {
+			showPromo(orig, dest);
+			return;
+		}
+		send({action: 'move', uoi: orig + dest});
+	}
+
+	if (el.draw) {
+		el.draw.addEventListener('click', () => send({action: 'draw'}));
+	}
+	if (el.decline) {
+		el.decline.addEventListener('click', () => send({action: 'decline'}));
+	}
+	if (el.resign) {
+		el.resign.addEventListener('click', () => {
+			if (window.confirm('Resign this game?')) {
+				send({action: 'resign'});
+			}
+		});
+	}
+
+	function showPromo(orig, dest) {
+		if (!el.promo || !el.promoShade) {
+			send({action: 'move', uoi: orig + dest + 'q'});
+			return;
+		}
+		el.promoShade.classList.remove('hidden');
+		el.promo.classList.remove('hidden');
+		el.promo.classList.add('f' + dest[0]);
+		const stale = el.promo.getElementsByTagName('piece');
+		for (let i = stale.length - 1; i >= 0; i--) {
+			stale[i].replaceWith(stale[i].cloneNode(true));
+		}
+		const pieces = el.promo.getElementsByTagName('piece');
+		for (let i = 0; i < pieces.length; i++) {
+			const node = pieces[i];
+			node.classList.add(seat);
+			const promo = node.classList.contains('queen') ? 'q'
+				: node.classList.contains('rook') ? 'r'
+					: node.classList.contains('bishop') ? 'b' : 'n';
+			node.addEventListener('click', () => {
+				hidePromo(dest);
+				send({action: 'move', uoi: orig + dest + promo});
+			});
+		}
+	}
+
+	function hidePromo(dest) {
+		el.promoShade.classList.add('hidden');
+		el.promo.classList.add('hidden');
+		el.promo.classList.remove('f' + dest[0]);
+		const pieces = el.promo.getElementsByTagName('piece');
+		for (let i = 0; i < pieces.length; i++) {
+			pieces[i].classList.remove('white', 'black');
+		}
+	}
+
+	renderDraw(state);
+
+	// poll while there is something to wait for: an open game's opponent, or
+	// the opponent's move. Paused while the tab is hidden.
+	setInterval(() => {
+		if (document.hidden || busy) {
+			return;
+		}
+		if (state.status === 'open' || (state.status === 'active' && !myMove(state))) {
+			refresh();
+		}
+	}, pollEvery);
+	// the countdown, to the minute
+	setInterval(() => {
+		if (el.deadline && state.deadline) {
+			el.deadline.textContent = timeLeft(state.deadline);
+		}
+	}, 60000);
+})();
@@ -55,6 +55,8 @@ const ratingUpdateTag = "rc";
 const rematchUpdateTag = "ru";
 const nextGameTag = "ng";
 const drawOfferTag = "do";
+const takebackTag = "tb";
+const premoveTag = "pm";
 const deployTag = "d";
 
 // Blind deploy phase state. While deployMode is true the board is in
@@ -260,6 +262,15 @@ let og = Octadground(document.getElementById('game'), {
 	},
 	premovable: {
 		enabled: !isSpec,
+		events: {
+			set: (orig, dest) => sendPremove(premoveUOI(orig, dest)),
+			// a premove the server already dropped or played needs no cancel
+			unset: () => {
+				if (serverPremove) {
+					sendPremove(null);
+				}
+			},
+		},
 	},
 	selectable: {
 		enabled: !isSpec && window.isMobile,
@@ -337,6 +348,58 @@ const reconcilePending = () => {
  */
 const sendMoveOnWire = (uoi, num) => send(buildCommand("m", {u: uoi, a: num}));
 
+/**
+ * UOI for a premove from orig to dest. A pawn premoved onto the last rank
+ * promotes to a queen — there is no time to ask in the middle of the
+ * opponent's move.
+ * @param orig - origin square
+ * @param dest - destination square
+ */
+const premoveUOI = (orig, dest) => {
+	const piece = og.state.pieces.get(orig);
+	if (piece && piece.role === "pawn" &&
+		((piece.color === "white" && dest[1] === "4") || (piece.color === "black" && dest[1] === "1"))) {
+		return orig + dest + "q";
+	}
+	return orig + dest;
+};
+
+/**
+ * Register a premove with the server, or cancel ours with null.
+ * @param uoi - premove UOI, or null to cancel
+ */
+const sendPremove = (uoi) => {
+	if (isSpec) {
+		return;
+	}
+	send(buildCommand(premoveTag, {s: uoi ? [{m: uoi}] : []}));
+};
+
+/**
+ * handlePremove reflects the server's acknowledgement of our premove queue
+ * ('pm'). A queue still holding a step is what the server will play. An empty
+ * one means ours was played or cancelled, and the board's highlight of it goes
+ * (a premove set since is kept); a dropped one means the server discarded what
+ * we sent — illegal in the position it met — so the board's premove goes too.
+ * @param message - premove message
+ */
+const handlePremove = (message) => {
+	const d = message.d || {};
+	if (d.i && currentGameID && d.i !== currentGameID) {
+		return;
+	}
+	if (d.s && d.s.length > 0 && !d.x) {
+		serverPremove = d.s[0].m;
+		return;
+	}
+	const held = serverPremove;
+	serverPremove = null;
+	const current = og.state.premovable.current;
+	if (current && (d.x || (held && held.startsWith(current[0] + current[1])))) {
+		og.cancelPremove();
+	}
+};
+
 /**
  * Resend the pending move after reconciliation shows the server never got it.
  * Caps attempts so a persistently-rejected move can't spin.
@@ -706,6 +769,10 @@ const gameGridEl = document.querySelector('.game-grid');
 const resignBtn = document.getElementById('btn-resign');
 const drawBtn = document.getElementById('btn-draw');
 const railRematchBtn = document.getElementById('btn-rematch');
+// only rendered for a seated player in an Arena game that allows berserk
+const berserkBtn = document.getElementById('btn-berserk');
+// only rendered for a seated player in a casual or bot game
+const takebackBtn = document.getElementById('btn-takeback');
 
 // two-step resign confirm state, and draw-offer state mirrored from the server's
 // 'do' broadcasts (reset on each new game / whenever a move supersedes the offer)
@@ -713,6 +780,21 @@ let resignArmed = false;       // resign button is showing its confirm prompt
 let resignArmTimer = null;     // auto-disarm timeout for the confirm prompt
 let drawOfferedByMe = false;   // we have a standing draw offer out
 let drawOfferedByOpp = false;  // the opponent has offered us a draw to accept
+// takeback-request state mirrored from the server's 'tb' broadcasts, reset like
+// the draw offer's. takebacksSeen is the game's takeback count (MovePayload.tb)
+// as of the last board state applied: a board state with a higher count is a
+// rewound position to adopt even though its ply went backwards, and one with a
+// lower count predates a takeback and is stale.
+let takebackAskedByMe = false;  // we have a standing takeback request out
+let takebackAskedByOpp = false; // the opponent asked us for a takeback
+let takebacksSeen = 0;
+
+// The server plays a registered premove the instant the opponent's move lands
+// (room/premove.go), so the board's own premove is mirrored to it: serverPremove
+// is the UOI the server has acknowledged holding for us, and while it is set
+// the board must not also play the premove itself when the opponent's move
+// renders.
+let serverPremove = null;
 
 // tracks whether the "opponent wants a rematch" cue has already sounded for the
 // current standing request, so the resync poll (which re-invokes
@@ -832,6 +914,7 @@ const setControlsMode = (over) => {
 	}
 	resetResignButton();
 	clearDrawOfferUI();
+	clearTakebackUI();
 };
 
 /**
@@ -889,6 +972,38 @@ const clearDrawOfferUI = () => {
 	}
 };
 
+/**
+ * clearTakebackUI drops any takeback-request affordance and returns the
+ * Takeback button to its idle state.
+ */
+const clearTakebackUI = () => {
+	takebackAskedByMe = false;
+	takebackAskedByOpp = false;
+	if (takebackBtn) {
+		takebackBtn.classList.remove('wants-takeback');
+		takebackBtn.disabled = false;
+		takebackBtn.innerHTML = '↶ Takeback';
+	}
+};
+
+/**
+ * updateBerserkButton shows the berserk button only while it can still be
+ * used: before this player's first move (White with an empty board, Black
+ * with at most White's move on it) and until they have berserked. The server
+ * enforces the same window; this just stops offering a click it would drop.
+ */
+const updateBerserkButton = (message) => {
+	if (!berserkBtn) {
+		return;
+	}
+	const ply = messagePly(message);
+	const open = ply <= (playerWhite ? 0 : 1);
+	berserkBtn.classList.toggle('hidden', !open && !berserkBtn.classList.contains('berserked'));
+	if (!open) {
+		berserkBtn.disabled = true;
+	}
+};
+
 // backend reason codes -> human-readable method subtitles
 const resultReasons = {
 	checkmate: 'by checkmate',
@@ -1040,6 +1155,33 @@ const rematchWindowLabel = (remaining) => {
 	return `Rematch &middot; ${remaining}s`;
 };
 
+// tournamentReturnSeconds is how long a finished tournament game's result
+// shows before its players are sent back to the tournament page, where their
+// next pairing finds them. Well inside the room's own close window.
+const tournamentReturnSeconds = 6;
+
+/**
+ * Countdown label for a finished tournament game: nothing to decide, just the
+ * way back to the event.
+ */
+const tournamentReturnLabel = (remaining) => {
+	if (remaining <= 0) {
+		return 'Returning&hellip;';
+	}
+	return `Back to tournament in ${remaining}s`;
+};
+
+/**
+ * Countdown label for a finished simul board. The host moves on to their next
+ * board waiting on a move; a participant goes back to the simul's page.
+ */
+const simulReturnLabel = (host) => (remaining) => {
+	if (remaining <= 0) {
+		return host ? 'Next board&hellip;' : 'Returning&hellip;';
+	}
+	return host ? `Next board in ${remaining}s` : `Back to simul in ${remaining}s`;
+};
+
 /**
  * Countdown label for an undecided race-to match's interlude: the next game
  * starts automatically when it lapses, so no action is needed from either
@@ -1508,8 +1650,18 @@ const showResult = (message) => {
 	// (they auto-advance): hide the overlay's button and disable the rail's
 	const roomOver = !!message.d.o;
 	const midMatch = isMidMatch(message.d);
+	// a tournament game or simul board is a single game: its players go back
+	// to the event (a simul's host on to their next board)
+	const tournament = message.d.tn || '';
+	const simul = message.d.sm || '';
 	if (rematchBtn) {
-		rematchBtn.style.display = (roomOver || midMatch) ? 'none' : '';
+		rematchBtn.style.display = (roomOver || midMatch || tournament || simul) ? 'none' : '';
+	}
+	if (railRematchBtn && (tournament || simul)) {
+		railRematchBtn.disabled = true;
+	}
+	if (berserkBtn) {
+		berserkBtn.disabled = true;
 	}
 	// a closed room can't be rematched from; mid-match the rail button is not
 	// disabled but repurposed as the interlude control just below
@@ -1565,7 +1717,16 @@ const showResult = (message) => {
 	// room closing; bot games are not time-boxed and carry no countdown (the
 	// finished room stays open for review + manual rematch), so a missing
 	// ng/rw just clears the countdown.
-	if (message.d.ng) {
+	if (tournament && !isSpec) {
+		startCountdown(tournamentReturnSeconds, tournamentReturnLabel, () => {
+			window.location.href = `/tournament/${tournament}`;
+		});
+	} else if (simul && !isSpec) {
+		const next = document.getElementById('simul-next');
+		startCountdown(tournamentReturnSeconds, simulReturnLabel(!!next), () => {
+			window.location.href = next ? next.href : `/simul/${simul}`;
+		});
+	} else if (message.d.ng) {
 		// the interlude lapsing is itself a handoff: fade the card out as the
 		// server starts the next game, exactly as a both-ready skip does
 		startCountdown(message.d.ng, nextGameLabel, startNextGameTransition);
@@ -1838,6 +1999,18 @@ if (resignBtn && !isSpec) {
 	});
 }
 
+// Berserk (Arena tournaments) halves our clock for a bonus point on a win. It
+// is a one-way choice, so the button locks in as soon as it is pressed; the
+// halved clock arrives with the server's next game state.
+if (berserkBtn) {
+	berserkBtn.addEventListener('click', () => {
+		berserkBtn.disabled = true;
+		berserkBtn.classList.add('berserked');
+		berserkBtn.innerHTML = '⚔ Berserk!';
+		send(buildCommand("r", {bz: true}));
+	});
+}
+
 // Draw offers/accepts a draw. If the opponent has a standing offer this click
 // accepts it (the game ends by agreement); otherwise it sends our offer and
 // shows a pending state until the opponent answers, the bot's engine decides, or
@@ -1862,6 +2035,72 @@ if (drawBtn && !isSpec) {
 	});
 }
 
+// Takeback asks to take back our last move, or accepts the opponent's standing
+// request while one is shown. Against a bot the answer is immediate: the rewound
+// board state, or a decline once the bot's allowance for the game is spent.
+if (takebackBtn) {
+	takebackBtn.addEventListener('click', () => {
+		if (gameOver || takebackAskedByMe) {
+			return;
+		}
+		send(buildCommand("r", {tb: true}));
+		takebackBtn.disabled = true;
+		if (takebackAskedByOpp) {
+			// accepting: the rewound board state follows
+			takebackBtn.classList.remove('wants-takeback');
+		} else {
+			takebackAskedByMe = true;
+			takebackBtn.innerHTML = 'Asked&hellip;';
+		}
+	});
+}
+
+/**
+ * handleTakeback reflects server takeback-request state ('tb'), the takeback
+ * analogue of handleDrawOffer: a standing request (by = requesting uid) shows a
+ * pending state for the requester and an "accept takeback" affordance for the
+ * opponent; a decline (dc) briefly notes it and restores the button. The
+ * takeback itself arrives as a board state (see handleMove).
+ * @param message - takeback message
+ */
+const handleTakeback = (message) => {
+	if (!takebackBtn || gameOver) {
+		return;
+	}
+	const d = message.d || {};
+	if (d.dc) {
+		const wasPending = takebackAskedByMe || takebackAskedByOpp;
+		clearTakebackUI();
+		if (wasPending) {
+			takebackBtn.disabled = true;
+			takebackBtn.innerHTML = 'Declined';
+			setTimeout(() => {
+				if (!gameOver) {
+					takebackBtn.disabled = false;
+					takebackBtn.innerHTML = '↶ Takeback';
+				}
+			}, 1500);
+		}
+		return;
+	}
+	if (!d.by) {
+		return;
+	}
+	if (d.by === myUid()) {
+		takebackAskedByMe = true;
+		takebackBtn.disabled = true;
+		takebackBtn.innerHTML = 'Asked&hellip;';
+	} else {
+		if (!takebackAskedByOpp) {
+			window.drawSound.play();
+		}
+		takebackAskedByOpp = true;
+		takebackBtn.disabled = false;
+		takebackBtn.classList.add('wants-takeback');
+		takebackBtn.innerHTML = '↶ Accept takeback';
+	}
+};
+
 /**
  * handleDrawOffer reflects server draw-offer state ('do'): a standing offer
  * (by = offering uid) shows a pending state for the offerer and an "accept draw"
@@ -2051,18 +2290,38 @@ const handleMove = (message) => {
 	const ofenParts = message.d.o.split(' ');
 	const serverPly = messagePly(message);
 
+	// a takeback is the one board state that moves the ply backwards: it is
+	// recognized by the game's takeback count rising, and a snapshot with a
+	// lower count than we have applied predates one (see takebacksSeen)
+	const takebacks = message.d.tb || 0;
+	if (!newGame && takebacks < takebacksSeen) {
+		return;
+	}
+	const rewound = !newGame && takebacks > takebacksSeen;
+
 	// ignore a stale board snapshot that would regress the board to an older
 	// position (e.g. a late board-state response landing after newer state). A
 	// game start legitimately resets the ply, so always honor it.
-	if (!newGame && serverPly < lastPly) {
+	if (!newGame && !rewound && serverPly < lastPly) {
 		return;
 	}
+	takebacksSeen = takebacks;
 
-	// a played move supersedes any standing draw offer (the server withdraws it
-	// on the move too); drop the affordance so a stale "accept draw" / "offered…"
-	// can't linger past the position it referred to
-	if (!newGame && serverPly > lastPly) {
+	// a played move supersedes any standing draw offer or takeback request (the
+	// server withdraws them on the move too), and so does a takeback; drop the
+	// affordances so a stale "accept draw" / "offered…" can't linger past the
+	// position it referred to
+	if (!newGame && (serverPly > lastPly || rewound)) {
 		clearDrawOfferUI();
+		clearTakebackUI();
+	}
+
+	// a rewound position voids any move we sent for the position it replaced,
+	// and any premove queued against it
+	if (rewound) {
+		clearPending();
+		serverPremove = null;
+		og.cancelPremove();
 	}
 
 	// reconcile any move we sent but haven't seen confirmed yet
@@ -2122,6 +2381,7 @@ const handleMove = (message) => {
 	// cache the player's color for the result overlay, and clear any prior
 	// result when a new game (e.g. a rematch) starts
 	playerWhite = isPlayerWhite(message);
+	updateBerserkButton(message);
 	if (newGame) {
 		gameOver = false;
 		gameResult = '*';
@@ -2133,8 +2393,10 @@ const handleMove = (message) => {
 		if (deployMode || deploySpectating) {
 			exitDeployMode();
 		}
-		// a new game invalidates any move left unconfirmed from the prior one
+		// a new game invalidates any move left unconfirmed from the prior one,
+		// and the server holds no premove of ours into it
 		clearPending();
+		serverPremove = null;
 		// analysis leftovers die with the old game: drop any exploration line
 		// and restore the premove behavior analysis arming disabled (arming
 		// only ever happens once a game is over, so this can't fight the live
@@ -2194,7 +2456,7 @@ const handleMove = (message) => {
 
 	// perform pre-move if set (spectators can never set one — premovable is
 	// disabled — but never even ask the board to play one for them)
-	if (followingLive && !isSpec) {
+	if (followingLive && !isSpec && !serverPremove) {
 		og.playPremove();
 	}
 
@@ -4186,6 +4448,23 @@ const renderReviewPosition = (ply) => {
 
 // ---- engine evaluation bar ----
 const evalBarEl = document.getElementById('eval-bar');
+const evalBarTitle = evalBarEl ? evalBarEl.title : '';
+
+/**
+ * tablebaseLabel spells an analysis response's exact endgame result for the
+ * eval bar's tooltip: "White mates in 3", "Tablebase draw", or '' when the
+ * eval is a search estimate (d.tb unset). d.mate is white-positive moves.
+ * @param d - /api/analysis response
+ */
+const tablebaseLabel = (d) => {
+	if (!d.tb) {
+		return '';
+	}
+	if (!d.mate) {
+		return 'Tablebase draw';
+	}
+	return (d.mate > 0 ? 'White' : 'Black') + ' mates in ' + Math.abs(d.mate);
+};
 
 /**
  * updateEvalBar renders the eval bar for the viewed ply from the cached
@@ -4196,6 +4475,8 @@ const evalBarEl = document.getElementById('eval-bar');
  * to white's end of the board per the current orientation.
  */
 const updateEvalBar = () => {
+	// the explorer panel follows the same viewed position
+	updateExplorer();
 	if (!evalBarEl) {
 		return;
 	}
@@ -4220,6 +4501,8 @@ const updateEvalBar = () => {
 	const cp = inLine && explore
 		? explore.evals[exploreView - 1]
 		: (viewPly === 0 ? 0 : evals[viewPly - 1]);
+	// a solved endgame in the explored line names its exact result
+	evalBarEl.title = (inLine && explore && explore.tbs[exploreView - 1]) || evalBarTitle;
 	if (cp === null || cp === undefined) {
 		evalBarEl.classList.add('eval-unknown');
 		return;
@@ -4234,6 +4517,147 @@ const updateEvalBar = () => {
 	if (fill) { fill.style.height = pct + '%'; }
 };
 
+// opening explorer panel (archive pages): what the archive's players chose
+// from the viewed position and how it scored, per /api/explorer. Only fetched
+// while the panel is open; responses are cached per position + filter.
+const explorerEl = document.getElementById('explorer');
+const explorerCache = new Map(); // query string -> /api/explorer response
+let explorerKey = '';            // query string the panel currently shows
+
+/** explorerQuery builds the /api/explorer query for an OFEN and the filters. */
+const explorerQuery = (ofen) => {
+	const q = new URLSearchParams({ ofen: ofen });
+	[['category', 'explorer-category'], ['since', 'explorer-since'], ['until', 'explorer-until']]
+		.forEach(([key, id]) => {
+			const el = document.getElementById(id);
+			if (el && el.value) { q.set(key, el.value); }
+		});
+	return q.toString();
+};
+
+/**
+ * explorerEval renders a continuation's cached eval the way the analysis
+ * board reads it: a forced mate as "#N", else white-positive pawns.
+ */
+const explorerEval = (m) => {
+	if (typeof m.mate === 'number') {
+		return '#' + m.mate;
+	}
+	if (typeof m.cp !== 'number') {
+		return '';
+	}
+	return (m.cp > 0 ? '+' : '') + (m.cp / 100).toFixed(1);
+};
+
+/**
+ * renderExplorer fills the panel from one /api/explorer response: a row per
+ * continuation (clicking one explores it on the board) and the position's
+ * notable games, linked to their archives.
+ */
+const renderExplorer = (d) => {
+	const movesEl = document.getElementById('explorer-moves');
+	const gamesEl = document.getElementById('explorer-games');
+	if (!movesEl || !gamesEl) {
+		return;
+	}
+	movesEl.replaceChildren();
+	gamesEl.replaceChildren();
+	if (!d.moves.length) {
+		const empty = document.createElement('div');
+		empty.className = 'explorer-empty';
+		empty.textContent = 'No archived games continued from this position';
+		movesEl.append(empty);
+	}
+	d.moves.forEach((m) => {
+		const row = document.createElement('button');
+		row.type = 'button';
+		row.className = 'explorer-move';
+		row.setAttribute('role', 'listitem');
+		row.title = m.n + (m.n === 1 ? ' game' : ' games')
+			+ (m.r ? ' · average rating ' + m.r : '');
+		const san = document.createElement('span');
+		san.className = 'explorer-san';
+		san.textContent = m.san;
+		const n = document.createElement('span');
+		n.className = 'explorer-n';
+		n.textContent = m.n;
+		const bar = document.createElement('span');
+		bar.className = 'explorer-bar';
+		[['w', m.w], ['d', m.d], ['b', m.b]].forEach(([cls, pct]) => {
+			const seg = document.createElement('span');
+			seg.className = 'explorer-' + cls;
+			seg.style.width = pct + '%';
+			seg.textContent = pct >= 15 ? pct + '%' : '';
+			bar.append(seg);
+		});
+		const ev = document.createElement('span');
+		ev.className = 'explorer-eval';
+		ev.textContent = explorerEval(m);
+		row.append(san, n, bar, ev);
+		row.addEventListener('click', () => {
+			if (canExplore()) { exploreApply(m.uoi); }
+		});
+		movesEl.append(row);
+	});
+	d.top.forEach((g) => {
+		const a = document.createElement('a');
+		a.className = 'explorer-game';
+		a.href = g.url;
+		a.setAttribute('role', 'listitem');
+		const seat = (name, rating) => name + (rating ? ' (' + rating + ')' : '');
+		a.textContent = seat(g.white, g.wr) + ' – ' + seat(g.black, g.br)
+			+ ' · ' + g.res + ' · ' + g.date;
+		gamesEl.append(a);
+	});
+};
+
+/**
+ * updateExplorer points the open explorer panel at the viewed position (the
+ * explored line's, while one is on the board), fetching it unless cached. A
+ * response for a position the view has since left is dropped.
+ */
+const updateExplorer = () => {
+	if (!explorerEl || !explorerEl.open) {
+		return;
+	}
+	const ofen = inLine && explore ? explore.ofens[exploreView - 1] : history.ofens[viewPly];
+	if (!ofen) {
+		return;
+	}
+	const key = explorerQuery(ofen);
+	if (key === explorerKey) {
+		return;
+	}
+	explorerKey = key;
+	const cached = explorerCache.get(key);
+	if (cached) {
+		renderExplorer(cached);
+		return;
+	}
+	fetch('/api/explorer?' + key)
+		.then((res) => res.ok ? res.json() : null)
+		.then((d) => {
+			if (!d) {
+				// let the next view change retry
+				if (key === explorerKey) { explorerKey = ''; }
+				return;
+			}
+			explorerCache.set(key, d);
+			if (key === explorerKey) { renderExplorer(d); }
+		})
+		.catch(() => {
+			if (key === explorerKey) { explorerKey = ''; }
+		});
+};
+
+if (explorerEl) {
+	explorerEl.addEventListener('toggle', updateExplorer);
+	['explorer-category', 'explorer-since', 'explorer-until'].forEach((id) => {
+		const el = document.getElementById(id);
+		if (el) { el.addEventListener('change', updateExplorer); }
+	});
+}
+
 /**
  * fetchEvalsInto pulls a finished game's cached evals from the server and
  * applies them to the current history when the view still shows that game
@@ -4504,11 +4928,11 @@ const exploreApply = async (uoi) => {
 		}
 		if (branchPly !== null) {
 			// branching from a game ply replaces any previous line entirely
-			explore = { basePly: branchPly, uois: [], sans: [], ofens: [], checks: [], overs: [], reasons: [], evals: [], dests: [] };
+			explore = { basePly: branchPly, uois: [], sans: [], ofens: [], checks: [], overs: [], reasons: [], evals: [], tbs: [], dests: [] };
 			exploreView = 0;
 		} else if (exploreView < explore.uois.length) {
 			// rewound mid-line: the new move replaces the tail
-			['uois', 'sans', 'ofens', 'checks', 'overs', 'reasons', 'evals', 'dests']
+			['uois', 'sans', 'ofens', 'checks', 'overs', 'reasons', 'evals', 'tbs', 'dests']
 				.forEach((k) => { explore[k].length = exploreView; });
 		}
 		explore.uois.push(uoi);
@@ -4518,6 +4942,7 @@ const exploreApply = async (uoi) => {
 		explore.overs.push(d.over || '');
 		explore.reasons.push(d.rr || '');
 		explore.evals.push(typeof d.cp === 'number' ? d.cp : null);
+		explore.tbs.push(tablebaseLabel(d));
 		explore.dests.push(d.v || {});
 		inLine = true;
 		exploreView = explore.uois.length;
@@ -4774,6 +5199,15 @@ const renderMoveList = () => {
 	const timeCell = (ply) => showTimes
 		? '<span class="move-time">' + formatMoveTime(times[ply - 1]) + '</span>'
 		: '';
+	// a finished post-game review marks each questionable move with its glyph
+	const judgements = history.judgements || [];
+	const judgeCell = (ply) => {
+		const glyph = judgementGlyphs[judgements[ply - 1]];
+		return glyph
+			? '<span class="move-judge j-' + judgements[ply - 1] + '" title="'
+				+ judgementTitles[judgements[ply - 1]] + '">' + glyph + '</span>'
+			: '';
+	};
 	// the active exploration line renders as an indented variation block right
 	// after the move-row containing its branch point (before all rows when the
 	// line branches from the start position)
@@ -4790,11 +5224,11 @@ const renderMoveList = () => {
 		html += '<span class="move-num">' + num + '.</span>';
 		html += '<span class="move' + (!inLine && viewPly === wPly ? ' active' : '')
 			+ '" data-ply="' + wPly + '" role="listitem">' + escapeHtml(sans[i])
-			+ timeCell(wPly) + '</span>';
+			+ judgeCell(wPly) + timeCell(wPly) + '</span>';
 		if (sans[i + 1] !== undefined) {
 			html += '<span class="move' + (!inLine && viewPly === bPly ? ' active' : '')
 				+ '" data-ply="' + bPly + '" role="listitem">' + escapeHtml(sans[i + 1])
-				+ timeCell(bPly) + '</span>';
+				+ judgeCell(bPly) + timeCell(bPly) + '</span>';
 		} else {
 			html += '<span class="move move-empty"></span>';
 		}
@@ -5187,6 +5621,95 @@ if (copyPgnBtn) {
 
 // ---- archive mode (permanent room/game permalinks) ----
 
+// post-game review (archive pages): the review's state travels inline in
+// #archive-data (an); without one the panel offers to request it, and while
+// queued it polls until the worker finishes
+const judgementGlyphs = { inaccuracy: '?!', mistake: '?', blunder: '??', 'missed-mate': '#?' };
+const judgementTitles = {
+	inaccuracy: 'Inaccuracy', mistake: 'Mistake', blunder: 'Blunder', 'missed-mate': 'Missed a forced mate',
+};
+const analysisPoll = 5000;
+
+/** analysisSideHTML renders one side's line of the review summary. */
+const analysisSideHTML = (label, side) => '<div class="analysis-side">'
+	+ '<span class="analysis-label">' + label + '</span>'
+	+ '<span class="analysis-acc" title="Accuracy">' + side.acc.toFixed(1) + '%</span>'
+	+ '<span class="analysis-acpl" title="Average centipawn loss">' + side.acpl + ' ACPL</span>'
+	+ '<span class="analysis-counts">'
+	+ [['inaccuracy', side.i], ['mistake', side.m], ['blunder', side.b], ['missed-mate', side.mm]]
+		.map(([k, n]) => '<span class="j-' + k + '" title="' + judgementTitles[k] + 's">'
+			+ judgementGlyphs[k] + ' ' + n + '</span>')
+		.join('')
+	+ '</span></div>';
+
+/**
+ * renderAnalysis shows a review state in the panel: the request button (none,
+ * or failed), the queued note, or the summary — which also hands the review's
+ * evals to the eval bar and its judgements to the move list.
+ */
+const renderAnalysis = (an) => {
+	const summary = document.getElementById('analysis-summary');
+	const btn = document.getElementById('btn-analysis');
+	const status = document.getElementById('analysis-status');
+	if (!summary || !btn || !status) {
+		return;
+	}
+	const state = an ? an.s : 'none';
+	btn.hidden = state !== 'none' && state !== 'failed';
+	btn.disabled = false;
+	summary.hidden = state !== 'done';
+	status.textContent = state === 'queued' ? 'Analysing… this takes a minute'
+		: state === 'failed' ? 'Analysis failed — try again' : '';
+	if (state === 'queued') {
+		setTimeout(pollAnalysis, analysisPoll);
+		return;
+	}
+	if (state !== 'done') {
+		return;
+	}
+	summary.innerHTML = analysisSideHTML('White', an.w) + analysisSideHTML('Black', an.b);
+	if (an.ev && an.ev.length === history.uois.length) {
+		history.evals = an.ev;
+	}
+	history.judgements = an.j || [];
+	renderMoveList();
+	updateEvalBar();
+};
+
+/** pollAnalysis re-reads a queued review's state. */
+const pollAnalysis = async () => {
+	try {
+		const res = await fetch('/api/game/' + archiveData.gameId + '/analysis');
+		if (res.ok) {
+			renderAnalysis(await res.json());
+			return;
+		}
+	} catch (err) {
+		// fall through to the next poll
+	}
+	setTimeout(pollAnalysis, analysisPoll);
+};
+
+const analysisBtn = document.getElementById('btn-analysis');
+if (analysisBtn && isArchive) {
+	analysisBtn.addEventListener('click', async () => {
+		const status = document.getElementById('analysis-status');
+		analysisBtn.disabled = true;
+		try {
+			const res = await fetch('/api/game/' + archiveData.gameId + '/analysis', { method: 'POST' });
+			const d = await res.json();
+			if (!res.ok) {
+				analysisBtn.disabled = false;
+				if (status) { status.textContent = d.error || 'Analysis unavailable'; }
+				return;
+			}
+			renderAnalysis(d);
+		} catch (err) {
+			analysisBtn.disabled = false;
+		}
+	});
+}
+
 /**
  * hydrateArchive boots the socket-less archive view from the inline
  * #archive-data payload: the finished game's final position on the board, the
@@ -5239,6 +5762,9 @@ const hydrateArchive = () => {
 	// mid-board result pill ("White wins: by checkmate")
 	endAnnotationText = resultSummary({ w: archiveData.w, r: archiveData.r });
 	updateEndAnnotation();
+
+	// the game's post-game review, or the offer of one
+	renderAnalysis(archiveData.an);
 };
 
 // keyboard navigation: ←/→ step one move, ↑/Home to start, ↓/End to live. Left
@@ -5320,5 +5846,7 @@ window.handlers.set(ratingUpdateTag, handleRatingUpdate);
 window.handlers.set(rematchUpdateTag, handleRematchUpdate);
 window.handlers.set(nextGameTag, handleNextGame);
 window.handlers.set(drawOfferTag, handleDrawOffer);
+window.handlers.set(takebackTag, handleTakeback);
+window.handlers.set(premoveTag, handlePremove);
 window.handlers.set(deployTag, handleDeploy);
 window.handlers.set("id", handleIdentity);
@@ -28,6 +28,7 @@
   const modForm = document.getElementById("modForm");
   const settingsForm = document.getElementById("settingsForm");
   const broadcastForm = document.getElementById("broadcastForm");
+  const logLevelForm = document.getElementById("logLevelForm");
 
   // { kind: "mod" | "setting", btn } awaiting confirmation, or null when closed
   let pending = null;
@@ -50,7 +51,7 @@
   // Safe at init: nothing has been typed yet on a fresh load, and a bfcache
   // restore does not re-run this script (that path is handled separately, by
   // the pageshow reset in navScript).
-  [modForm, settingsForm, broadcastForm].forEach(function (f) {
+  [modForm, settingsForm, broadcastForm, logLevelForm].forEach(function (f) {
     if (f) f.reset();
   });
 
@@ -150,6 +151,24 @@
     return { label: btn.dataset.confirm || "Broadcast", value: body, effect: effect };
   }
 
+  // A log level change. A reset button carries its package and level; the form's
+  // button reads them from the two selects.
+  function logLevelOf(btn) {
+    if (btn.dataset.package !== undefined) {
+      return { pkg: btn.dataset.package, level: btn.dataset.level };
+    }
+    return { pkg: field(logLevelForm, "package"), level: field(logLevelForm, "level") };
+  }
+
+  function describeLogLevel(btn) {
+    const l = logLevelOf(btn);
+    return {
+      label: btn.dataset.confirm || "Set log level — " + l.pkg,
+      value: btn.dataset.package !== undefined ? "" : l.pkg + " → " + l.level,
+      effect: btn.dataset.effect || "",
+    };
+  }
+
   // A queue or ops action. Both are single-button: the server-rendered
   // data-confirm already names the specific report or room, so there is no
   // field to read back.
@@ -171,6 +190,8 @@
       d = describeSetting(btn);
     } else if (kind === "broadcast") {
       d = describeBroadcast(btn);
+    } else if (kind === "log-level") {
+      d = describeLogLevel(btn);
     } else {
       d = describeSimple(btn);
     }
@@ -301,6 +322,14 @@
         url = "/api/mod/report/resolve";
         body = { id: Number(btn.dataset.resolveReport), resolution: reason };
         break;
+      case "fairplay-report":
+        url = "/api/mod/fairplay/report";
+        body = { userId: Number(btn.dataset.fairplayReport), reason: reason };
+        break;
+      case "fairplay-dismiss":
+        url = "/api/mod/fairplay/dismiss";
+        body = { userId: Number(btn.dataset.fairplayDismiss), reason: reason };
+        break;
       case "room":
         url = "/api/mod/room/close";
         body = { roomId: btn.dataset.closeRoom, reason: reason };
@@ -313,6 +342,12 @@
         url = "/api/mod/broadcast/retire";
         body = { id: Number(btn.dataset.retireBroadcast), reason: reason };
         break;
+      case "log-level": {
+        const l = logLevelOf(btn);
+        url = "/api/mod/log-level";
+        body = { package: l.pkg, level: l.level, reason: reason };
+        break;
+      }
     }
 
     submitting = true;
@@ -373,6 +408,18 @@
       open("report", repBtn);
       return;
     }
+    const fpReportBtn = ev.target.closest("[data-fairplay-report]");
+    if (fpReportBtn) {
+      ev.preventDefault();
+      open("fairplay-report", fpReportBtn);
+      return;
+    }
+    const fpDismissBtn = ev.target.closest("[data-fairplay-dismiss]");
+    if (fpDismissBtn) {
+      ev.preventDefault();
+      open("fairplay-dismiss", fpDismissBtn);
+      return;
+    }
     const roomBtn = ev.target.closest("[data-close-room]");
     if (roomBtn) {
       ev.preventDefault();
@@ -391,6 +438,12 @@
       open("retire", retireBtn);
       return;
     }
+    const levelBtn = ev.target.closest("[data-log-level]");
+    if (levelBtn) {
+      ev.preventDefault();
+      open("log-level", levelBtn);
+      return;
+    }
     if (!modal.classList.contains("open")) return;
     if (ev.target === modal || ev.target.closest("#confirmCancel") ||
         ev.target.closest("#modalConfirmChange .modal-close")) {
@@ -409,7 +462,7 @@
   });
 
   // none of these is a real form submission; Enter must never navigate
-  [modForm, settingsForm, broadcastForm].forEach(function (f) {
+  [modForm, settingsForm, broadcastForm, logLevelForm].forEach(function (f) {
     if (f) f.addEventListener("submit", function (ev) { ev.preventDefault(); });
   });
 })();
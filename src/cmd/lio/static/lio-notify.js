@@ -95,6 +95,7 @@
   //   challenge   iconSwords         the challenge control everywhere else
   //   follow      iconUsers          the "vs Human" glyph; a follower is a person
   //   announce    lucide "megaphone" a broadcast: the site talking to everybody
+  //   correspondence lucide "mail"   a game played by post, one move at a time
   //
   // New kinds belong here. Copy the glyph from its templ twin rather than
   // drawing a second version of it.
@@ -123,6 +124,10 @@
       "M16 3.13a4 4 0 0 1 0 7.75",
     ],
     announce: ["m3 11 18-5v12L3 14v-3z", "M11.6 16.8a3 3 0 1 1-5.8-1.6"],
+    correspondence: [
+      ["rect", { x: 2, y: 4, width: 20, height: 16, rx: 2 }],
+      "m22 7-8.97 5.7a1.94 1.94 0 0 1-2.06 0L2 7",
+    ],
   };
 
   // lucide "info", for a kind this build does not know — a row written by a
@@ -962,6 +967,12 @@
       if (msg.t === "wg" && window.lioCard) {
         window.lioCard.live(msg.d || {});
       }
+      // the matchmaking queue paired this session: go to the room. A queued
+      // player waits on /queue, whose only socket is this one.
+      if (msg.t === "mm" && msg.d && msg.d.r) {
+        stopped = true;
+        location.href = "/" + msg.d.r;
+      }
     };
     sock.onclose = function () {
       sock = null;
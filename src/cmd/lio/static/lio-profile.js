@@ -661,4 +661,48 @@
       busy = false;
     });
   })();
+
+  // --- block ---------------------------------------------------------------
+  //
+  // The block control (db/blocks.go). A block also removes the follows between
+  // the two accounts, which moves the follow button and both counts on this
+  // page, so a successful write reloads rather than patching each of them.
+  (function () {
+    const btn = document.querySelector("[data-block]");
+    if (!btn) return;
+    const status = document.querySelector("[data-block-status]");
+    const username = btn.dataset.block;
+    let busy = false;
+
+    btn.addEventListener("click", async function () {
+      if (busy) return;
+      const blocking = btn.getAttribute("aria-pressed") === "true";
+      if (
+        !blocking &&
+        !window.confirm(
+          "Block " + username + "? Neither of you will be able to challenge or follow the other, " +
+            "and you will not see each other's chat."
+        )
+      ) {
+        return;
+      }
+      busy = true;
+      if (status) status.textContent = "";
+      try {
+        const res = await fetch("/api/block/" + encodeURIComponent(username), {
+          method: blocking ? "DELETE" : "POST",
+          headers: { Accept: "application/json" },
+        });
+        if (res.ok) {
+          window.location.reload();
+          return;
+        }
+        const err = await res.json().catch(() => null);
+        if (status) status.textContent = (err && err.error) || "Could not save that.";
+      } catch (e) {
+        if (status) status.textContent = "Network error — that did not save.";
+      }
+      busy = false;
+    });
+  })();
 })();
@@ -0,0 +1,22 @@
+// lio-queue.js — the matchmaking queue page's wait clock.
+//
+// Counts up from when the player joined the queue (data-since, server time in
+// ms). The pairing itself needs nothing from this file: it arrives as an 'mm'
+// frame on whichever socket the page holds — lio-notify.js here — and every
+// socket owner navigates to the room it names.
+(function () {
+	const root = document.getElementById('queue');
+	const el = document.getElementById('queue-elapsed');
+	if (!root || !el) {
+		return;
+	}
+	const since = parseInt(root.dataset.since, 10) || Date.now();
+
+	const render = () => {
+		const s = Math.max(0, Math.floor((Date.now() - since) / 1000));
+		el.textContent = Math.floor(s / 60) + ':' + String(s % 60).padStart(2, '0');
+	};
+
+	render();
+	setInterval(render, 1000);
+})();
@@ -0,0 +1,280 @@
+// lio-simul.js — a simul's page, which is also its host's dashboard.
+//
+// The page is server-rendered with the simul's state; this file follows it
+// over /socket/simul/<id>. Every 'sm' frame is the whole picture (the tally,
+// every board with its clocks and whose move it is, and the next board waiting
+// on the host), so a frame simply replaces what is drawn. When the host starts
+// the simul each participant gets an 'e' redirect addressed to their connection
+// alone, which sends them to their board. Like lio-tournament.js it owns its
+// connection (jittered reconnect + stale-socket watchdog) and is the page's one
+// socket, so it also carries the notification, reconnect-bar and follow-badge
+// frames.
+//
+// A finished simul has nothing to follow: the page renders with data-live
+// false and this file leaves the socket to lio-notify.js.
+(function () {
+	const root = document.getElementById('simul');
+	if (!root || root.dataset.live !== 'true') {
+		return;
+	}
+	const id = root.dataset.id;
+	const statusEl = document.getElementById('sm-status');
+	const tallyEl = document.getElementById('sm-tally');
+	const boardsEl = document.getElementById('sm-boards');
+	const emptyEl = document.getElementById('sm-empty');
+	const nextEl = document.getElementById('sm-next');
+
+	// ---- rendering: the same labels and markup as view/simul.go ----
+	const text = (tag, cls, t) => {
+		const el = document.createElement(tag);
+		if (cls) {
+			el.className = cls;
+		}
+		el.textContent = t;
+		return el;
+	};
+
+	const statusLabel = (s) => {
+		if (s === 'created') {
+			return 'Waiting for the host to start';
+		}
+		if (s === 'started') {
+			return 'In progress';
+		}
+		return 'Finished';
+	};
+
+	const resultLabel = (b) => {
+		switch (b.res) {
+		case 'win':
+			return 'Host won';
+		case 'draw':
+			return 'Drawn';
+		case 'loss':
+			return 'Host lost';
+		case 'void':
+			return 'Not played';
+		}
+		if (!b.r) {
+			return 'Waiting';
+		}
+		return b.h ? 'Host to move' : 'Player to move';
+	};
+
+	const clock = (centi) => {
+		if (!centi || centi <= 0) {
+			return '';
+		}
+		const secs = Math.floor(centi / 100);
+		const s = secs % 60;
+		return Math.floor(secs / 60) + ':' + (s < 10 ? '0' : '') + s;
+	};
+
+	const renderRow = (b) => {
+		const tr = document.createElement('tr');
+		if (b.h) {
+			tr.className = 'simul-host-move';
+		}
+		const who = document.createElement('td');
+		who.className = 'flex items-baseline gap-1';
+		// the same markup as the server-rendered row (the playerName component)
+		const a = document.createElement('a');
+		a.className = 'player-link';
+		a.href = '/@/' + encodeURIComponent(b.n);
+		if (b.t) {
+			const badge = text('span', 'player-title', b.t);
+			if (b.tn) {
+				badge.title = b.tn;
+			}
+			a.appendChild(badge);
+		}
+		a.appendChild(text('span', 'min-w-0 truncate', b.n));
+		who.appendChild(a);
+		tr.appendChild(who);
+		const state = document.createElement('td');
+		if (b.r) {
+			const link = text('a', 'text-accent hover:underline', resultLabel(b));
+			link.href = '/' + b.r;
+			state.appendChild(link);
+		} else {
+			state.textContent = resultLabel(b);
+		}
+		tr.appendChild(state);
+		tr.appendChild(text('td', 'text-right font-mono', clock(b.hc)));
+		tr.appendChild(text('td', 'text-right font-mono', clock(b.pc)));
+		return tr;
+	};
+
+	const render = (d) => {
+		if (statusEl) {
+			statusEl.textContent = statusLabel(d.s);
+		}
+		if (tallyEl) {
+			tallyEl.textContent = '+' + (d.w || 0) + ' =' + (d.d || 0) + ' -' + (d.l || 0);
+		}
+		if (boardsEl) {
+			boardsEl.replaceChildren(...(d.b || []).map(renderRow));
+		}
+		if (emptyEl) {
+			emptyEl.classList.toggle('hidden', (d.b || []).length > 0);
+		}
+		if (nextEl) {
+			nextEl.classList.toggle('opacity-50', !d.nx);
+		}
+		// the simul has moved on from the page as rendered (started, finished
+		// or called off): its controls are stale, so render it again
+		if (d.s !== root.dataset.status) {
+			stopped = true;
+			if (ws) {
+				ws.close();
+			}
+			location.reload();
+		}
+	};
+
+	// ---- connection: jittered backoff + stale-socket watchdog (cf. lio-tv.js) ----
+	let ws = null;
+	let stopped = false;
+	let attempts = 0;
+	let pingTimer = null;
+	let pingsSincePong = 0;
+	let lastPingTime = 0;
+	let latency = 0;
+	let pongCount = 0;
+	const pingDelay = 5000;
+	const maxMissedPongs = 3;
+	const reconnectBaseMs = 1000;
+	const reconnectCapMs = 30000;
+
+	const connect = () => {
+		// claim the page's one socket, so lio-notify.js does not open a second
+		window.lioSocketOwner = 'simul';
+		ws = new WebSocket(location.origin.replace(/^http/, 'ws') + '/socket/simul/' + encodeURIComponent(id));
+		ws.onopen = () => {
+			attempts = 0;
+			pingsSincePong = 0;
+			if (window.lioConn) {
+				window.lioConn.set('online');
+			}
+			schedulePing(500);
+		};
+		ws.onclose = () => {
+			ws = null;
+			clearTimeout(pingTimer);
+			pingsSincePong = 0;
+			if (stopped) {
+				return;
+			}
+			if (window.lioConn) {
+				window.lioConn.set('reconnecting');
+			}
+			reconnect();
+		};
+		ws.onmessage = (evt) => handle(evt.data);
+	};
+
+	const reconnect = () => {
+		attempts++;
+		const ceil = Math.min(reconnectCapMs, reconnectBaseMs * Math.pow(2, attempts));
+		setTimeout(connect, Math.random() * ceil);
+	};
+
+	const schedulePing = (delay) => {
+		clearTimeout(pingTimer);
+		pingTimer = setTimeout(ping, delay);
+	};
+
+	const ping = () => {
+		if (pingsSincePong >= maxMissedPongs) {
+			if (ws) {
+				ws.close(4000, 'stale connection');
+			}
+			return;
+		}
+		try {
+			if (ws && ws.readyState === WebSocket.OPEN) {
+				ws.send(JSON.stringify({pi: 1}));
+				lastPingTime = Date.now();
+				pingsSincePong++;
+			}
+		} catch (e) { /* ignore */ }
+		schedulePing(pingDelay);
+	};
+
+	// ---- message handling ----
+	const handle = (raw) => {
+		if (!raw) {
+			return;
+		}
+		let msg;
+		try {
+			msg = JSON.parse(raw);
+		} catch (e) {
+			return;
+		}
+		if (msg.po && msg.po === 1) {
+			pingsSincePong = 0;
+			const currentLag = Math.min(Date.now() - lastPingTime, 10000);
+			pongCount++;
+			const weight = pongCount > 4 ? 0.1 : 1 / pongCount;
+			latency += weight * (currentLag - latency);
+			if (window.lioConn) {
+				window.lioConn.set('online', latency);
+			}
+			return;
+		}
+		switch (msg.t) {
+		case 'sm':
+			if (msg.d) {
+				render(msg.d);
+			}
+			return;
+		case 'e':
+			// the simul started (or is gone): go where the server says
+			if (msg.d && msg.d.l) {
+				stopped = true;
+				location.href = msg.d.l;
+			}
+			return;
+		case 'mm':
+			// the matchmaking queue paired this session
+			if (msg.d && msg.d.r) {
+				stopped = true;
+				location.href = '/' + msg.d.r;
+			}
+			return;
+		case 'nt':
+			if (msg.d && window.lioNotify) {
+				window.lioNotify.apply(msg.d);
+			}
+			return;
+		case 'lg':
+			if (window.lioLiveGame) {
+				window.lioLiveGame.apply(msg.d || {});
+			}
+			return;
+		case 'si':
+			if (msg.d && msg.d.v && window.lioUpdateNotice) {
+				window.lioUpdateNotice(msg.d.v);
+			}
+			return;
+		case 'fo':
+			if (msg.d) {
+				window.__lioFollowOnline = msg.d.o;
+				if (window.lioFollowBadge) {
+					window.lioFollowBadge.apply(msg.d.o);
+				}
+			}
+			return;
+		}
+	};
+
+	window.addEventListener('pagehide', () => {
+		stopped = true;
+		if (ws) {
+			ws.close();
+		}
+	});
+
+	connect();
+})();
@@ -0,0 +1,289 @@
+// lio-tournament.js — a tournament page's live standings.
+//
+// The page is server-rendered with the event's state; this file follows it
+// over /socket/tournament/<id>. Every 'tn' frame is the whole picture (the
+// standings, the games in progress and the clock), so a frame simply replaces
+// what is drawn. A pairing arrives as an 'e' redirect addressed to this
+// connection alone, which sends the player to their board; they come back here
+// from the game-over screen. Like lio-tv.js it owns its connection (jittered
+// reconnect + stale-socket watchdog) and is the page's one socket, so it also
+// carries the notification, reconnect-bar and follow-badge frames.
+//
+// A finished event has nothing to follow: the page renders with data-live
+// false and this file leaves the socket to lio-notify.js.
+(function () {
+	const root = document.getElementById('tournament');
+	if (!root) {
+		return;
+	}
+	const id = root.dataset.id;
+	const statusEl = document.getElementById('tn-status');
+	const clockEl = document.getElementById('tn-clock');
+	const standingsEl = document.getElementById('tn-standings');
+	const gamesEl = document.getElementById('tn-games');
+	const emptyEl = document.getElementById('tn-empty');
+
+	// ---- countdown: the server sends seconds left, the page ticks them ----
+	let deadline = 0;
+	let clockTimer = null;
+
+	const setClock = (seconds) => {
+		deadline = seconds > 0 ? Date.now() + seconds * 1000 : 0;
+		renderClock();
+	};
+
+	const renderClock = () => {
+		clearTimeout(clockTimer);
+		if (!clockEl) {
+			return;
+		}
+		const left = Math.max(0, Math.round((deadline - Date.now()) / 1000));
+		if (!deadline || left === 0) {
+			clockEl.textContent = '';
+			return;
+		}
+		const m = Math.floor(left / 60);
+		const s = left % 60;
+		clockEl.textContent = m + ':' + (s < 10 ? '0' : '') + s;
+		clockTimer = setTimeout(renderClock, 250);
+	};
+
+	setClock(parseInt(clockEl ? clockEl.dataset.seconds : '0', 10) || 0);
+
+	if (root.dataset.live !== 'true') {
+		return;
+	}
+
+	// ---- rendering ----
+	const text = (tag, cls, t) => {
+		const el = document.createElement(tag);
+		if (cls) {
+			el.className = cls;
+		}
+		el.textContent = t;
+		return el;
+	};
+
+	const score = (n) => String(Math.round(n * 10) / 10);
+
+	const statusLabel = (d) => {
+		if (d.s === 'created') {
+			return 'Starts in';
+		}
+		if (d.s === 'started') {
+			return d.rs ? 'Round ' + (d.r || 0) + ' of ' + d.rs : 'Ends in';
+		}
+		return 'Finished';
+	};
+
+	const renderRow = (r) => {
+		const tr = document.createElement('tr');
+		if (r.w) {
+			tr.className = 'opacity-50';
+		}
+		tr.appendChild(text('td', '', String(r.k)));
+		const who = document.createElement('td');
+		who.className = 'flex items-baseline gap-1';
+		// the same markup as the server-rendered row (the playerName component)
+		const a = document.createElement('a');
+		a.className = 'player-link';
+		a.href = '/@/' + encodeURIComponent(r.n);
+		if (r.t) {
+			const badge = text('span', 'player-title', r.t);
+			if (r.tn) {
+				badge.title = r.tn;
+			}
+			a.appendChild(badge);
+		}
+		a.appendChild(text('span', 'min-w-0 truncate', r.n));
+		who.appendChild(a);
+		who.appendChild(text('span', 'text-xs text-fg-subtle', String(r.rg)));
+		if (r.f) {
+			who.appendChild(text('span', 'text-xs', '🔥'));
+		}
+		if (r.p) {
+			who.appendChild(text('span', 'text-xs text-fg-subtle', 'playing'));
+		}
+		tr.appendChild(who);
+		tr.appendChild(text('td', 'font-mono text-xs', r.sh || ''));
+		tr.appendChild(text('td', 'text-right font-semibold', score(r.sc)));
+		tr.appendChild(text('td', 'text-right text-fg-subtle', r.tb ? score(r.tb) : ''));
+		return tr;
+	};
+
+	const render = (d) => {
+		if (statusEl) {
+			statusEl.textContent = statusLabel(d);
+		}
+		setClock(d.sec || 0);
+		if (standingsEl) {
+			standingsEl.replaceChildren(...(d.st || []).map(renderRow));
+		}
+		if (emptyEl) {
+			emptyEl.classList.toggle('hidden', (d.st || []).length > 0);
+		}
+		if (gamesEl) {
+			gamesEl.replaceChildren(...(d.g || []).map((g) => {
+				const li = document.createElement('li');
+				li.className = 'py-1';
+				const a = text('a', 'text-accent hover:underline', g.w + ' – ' + g.b);
+				a.href = '/' + g.r;
+				li.appendChild(a);
+				return li;
+			}));
+		}
+		// the event is over: stop following it and show the final page
+		if (d.s === 'finished') {
+			stopped = true;
+			if (ws) {
+				ws.close();
+			}
+		}
+	};
+
+	// ---- connection: jittered backoff + stale-socket watchdog (cf. lio-tv.js) ----
+	let ws = null;
+	let stopped = false;
+	let attempts = 0;
+	let pingTimer = null;
+	let pingsSincePong = 0;
+	let lastPingTime = 0;
+	let latency = 0;
+	let pongCount = 0;
+	const pingDelay = 5000;
+	const maxMissedPongs = 3;
+	const reconnectBaseMs = 1000;
+	const reconnectCapMs = 30000;
+
+	const connect = () => {
+		// claim the page's one socket, so lio-notify.js does not open a second
+		window.lioSocketOwner = 'tournament';
+		ws = new WebSocket(location.origin.replace(/^http/, 'ws') + '/socket/tournament/' + encodeURIComponent(id));
+		ws.onopen = () => {
+			attempts = 0;
+			pingsSincePong = 0;
+			if (window.lioConn) {
+				window.lioConn.set('online');
+			}
+			schedulePing(500);
+		};
+		ws.onclose = () => {
+			ws = null;
+			clearTimeout(pingTimer);
+			pingsSincePong = 0;
+			if (stopped) {
+				return;
+			}
+			if (window.lioConn) {
+				window.lioConn.set('reconnecting');
+			}
+			reconnect();
+		};
+		ws.onmessage = (evt) => handle(evt.data);
+	};
+
+	const reconnect = () => {
+		attempts++;
+		const ceil = Math.min(reconnectCapMs, reconnectBaseMs * Math.pow(2, attempts));
+		setTimeout(connect, Math.random() * ceil);
+	};
+
+	const schedulePing = (delay) => {
+		clearTimeout(pingTimer);
+		pingTimer = setTimeout(ping, delay);
+	};
+
+	const ping = () => {
+		if (pingsSincePong >= maxMissedPongs) {
+			if (ws) {
+				ws.close(4000, 'stale connection');
+			}
+			return;
+		}
+		try {
+			if (ws && ws.readyState === WebSocket.OPEN) {
+				ws.send(JSON.stringify({pi: 1}));
+				lastPingTime = Date.now();
+				pingsSincePong++;
+			}
+		} catch (e) { /* ignore */ }
+		schedulePing(pingDelay);
+	};
+
+	// ---- message handling ----
+	const handle = (raw) => {
+		if (!raw) {
+			return;
+		}
+		let msg;
+		try {
+			msg = JSON.parse(raw);
+		} catch (e) {
+			return;
+		}
+		if (msg.po && msg.po === 1) {
+			pingsSincePong = 0;
+			const currentLag = Math.min(Date.now() - lastPingTime, 10000);
+			pongCount++;
+			const weight = pongCount > 4 ? 0.1 : 1 / pongCount;
+			latency += weight * (currentLag - latency);
+			if (window.lioConn) {
+				window.lioConn.set('online', latency);
+			}
+			return;
+		}
+		switch (msg.t) {
+		case 'tn':
+			if (msg.d) {
+				render(msg.d);
+			}
+			return;
+		case 'e':
+			// paired (or the event is gone): go where the server says
+			if (msg.d && msg.d.l) {
+				stopped = true;
+				location.href = msg.d.l;
+			}
+			return;
+		case 'mm':
+			// the matchmaking queue paired this session
+			if (msg.d && msg.d.r) {
+				stopped = true;
+				location.href = '/' + msg.d.r;
+			}
+			return;
+		case 'nt':
+			if (msg.d && window.lioNotify) {
+				window.lioNotify.apply(msg.d);
+			}
+			return;
+		case 'lg':
+			if (window.lioLiveGame) {
+				window.lioLiveGame.apply(msg.d || {});
+			}
+			return;
+		case 'si':
+			if (msg.d && msg.d.v && window.lioUpdateNotice) {
+				window.lioUpdateNotice(msg.d.v);
+			}
+			return;
+		case 'fo':
+			if (msg.d) {
+				window.__lioFollowOnline = msg.d.o;
+				if (window.lioFollowBadge) {
+					window.lioFollowBadge.apply(msg.d.o);
+				}
+			}
+			return;
+		}
+	};
+
+	window.addEventListener('pagehide', () => {
+		stopped = true;
+		if (ws) {
+			ws.close();
+		}
+	});
+
+	connect();
+})();
@@ -0,0 +1,268 @@
+// lio-training.js — the /training puzzle client.
+//
+// Like the tutorial (lio-learn.js), this owns none of the rules: it renders the
+// board, collects the solver's move and asks POST /api/training what happened.
+// The server rebuilds the position from the puzzle and the step, so the only
+// state kept here is how many moves have been played. The solution arrives
+// only once the puzzle is over, solved or not, and "Show solution" replays it
+// from the start.
+(function () {
+	'use strict';
+
+	const mount = document.getElementById('training-board');
+	const dataEl = document.getElementById('training-data');
+	if (!mount || !dataEl || typeof Octadground === 'undefined') {
+		return;
+	}
+
+	let puzzle;
+	try {
+		puzzle = JSON.parse(dataEl.textContent);
+	} catch (e) {
+		return;
+	}
+
+	const api = '/api/training';
+	// the pause between the solver's move and the reply, so the two read as
+	// separate events rather than one board jump (the tutorial's pacing)
+	const replyDelay = 420;
+	// the pace of the solution replay
+	const replayStep = 700;
+
+	const sfx = {};
+	if (typeof Howl !== 'undefined') {
+		sfx.move = new Howl({src: ['/res/sfx/move.ogg', '/res/sfx/move.mp3'], preload: true, volume: 0.75});
+		sfx.capture = new Howl({src: ['/res/sfx/capture.ogg', '/res/sfx/capture.mp3'], preload: true, volume: 0.9});
+		sfx.check = new Howl({src: ['/res/sfx/check.ogg', '/res/sfx/check.mp3'], preload: true, volume: 0.9});
+	}
+	const play = (name) => {
+		const s = sfx[name];
+		if (s) {
+			try {
+				s.play();
+			} catch (e) { /* audio is a nicety, never a failure */ }
+		}
+	};
+
+	const el = {
+		feedback: document.getElementById('training-feedback'),
+		result: document.getElementById('training-result'),
+		rating: document.getElementById('training-rating'),
+		solution: document.getElementById('training-solution'),
+		next: document.getElementById('training-next'),
+		promoShade: document.getElementById('training-promo-shade'),
+		promo: document.getElementById('training-promo'),
+	};
+
+	const solver = puzzle.t;
+	let ofen = puzzle.o;
+	let step = 0;         // solver moves played
+	let over = false;     // solved or failed; the board is frozen
+	let busy = false;     // a request is in flight
+	let solution = null;  // revealed once the puzzle is over
+	let lastDests = puzzle.v; // the legal moves of the position on the board
+
+	const boardOf = (o) => (o || '').split(' ')[0];
+	const turnOf = (o) => ((o || '').split(' ')[1] === 'b' ? 'black' : 'white');
+	const frozen = {free: false, color: undefined, dests: new Map()};
+
+	function destMap(v) {
+		const m = new Map();
+		Object.keys(v || {}).forEach((k) => m.set(k, v[k]));
+		return m;
+	}
+
+	function movableFor(o, dests) {
+		if (over || turnOf(o) !== solver) {
+			return frozen;
+		}
+		return {free: false, color: solver, dests: destMap(dests)};
+	}
+
+	// configured as the tutorial's board is, for the same reasons: drag-only on
+	// desktop, and no premoves, since the server judges every move
+	const og = Octadground(mount, {
+		ofen: boardOf(ofen),
+		orientation: solver,
+		turnColor: solver,
+		coordinates: true,
+		lastMove: puzzle.lm || [],
+		highlight: {lastMove: true, check: true},
+		movable: movableFor(ofen, puzzle.v),
+		draggable: {enabled: true},
+		premovable: {enabled: false},
+		selectable: {enabled: !!window.isMobile},
+		events: {move: onBoardMove},
+	});
+
+	function say(text, tone) {
+		el.feedback.textContent = text || '';
+		el.feedback.classList.remove('good', 'bad');
+		if (tone) {
+			el.feedback.classList.add(tone);
+		}
+	}
+
+	function request(body) {
+		busy = true;
+		return fetch(api, {
+			method: 'POST',
+			headers: {'Content-Type': 'application/json'},
+			body: JSON.stringify(body),
+		}).then((r) => (r.ok ? r.json() : Promise.reject(r.status)))
+			.then((res) => {
+				busy = false;
+				return res;
+			})
+			.catch(() => {
+				busy = false;
+				say('Could not reach the server — check your connection and try again.', 'bad');
+				return null;
+			});
+	}
+
+	function onBoardMove(orig, dest) {
+		if (over || busy) {
+			og.set({ofen: boardOf(ofen), turnColor: turnOf(ofen)});
+			return;
+		}
+		const piece = og.state.pieces.get(dest);
+		const last = solver === 'white' ? '4' : '1';
+		if (piece && piece.role === 'pawn' && dest[1] === last) {
+			showPromo(orig, dest);
+			return;
+		}
+		sendMove(orig + dest);
+	}
+
+	function sendMove(uoi) {
+		og.set({movable: frozen});
+		request({puzzle: puzzle.id, step: step, uoi: uoi}).then((res) => {
+			if (!res) {
+				og.set({ofen: boardOf(ofen), turnColor: turnOf(ofen), movable: movableFor(ofen, lastDests)});
+				return;
+			}
+			applyResult(res);
+		});
+	}
+
+	function showMove(mv) {
+		og.set({
+			ofen: boardOf(mv.o),
+			turnColor: turnOf(mv.o),
+			lastMove: mv.lm || [],
+			check: !!mv.k,
+		});
+		play(mv.k ? 'check' : (mv.x ? 'capture' : 'move'));
+	}
+
+	function applyResult(res) {
+		if (res.mv) {
+			showMove(res.mv);
+		}
+		ofen = res.o || ofen;
+		lastDests = res.v;
+
+		if (res.done || res.failed) {
+			finish(res);
+			return;
+		}
+		step++;
+		say('Best move! Keep going.', 'good');
+		setTimeout(() => {
+			if (res.rp) {
+				showMove(res.rp);
+			}
+			og.set({movable: movableFor(ofen, res.v)});
+		}, replyDelay);
+	}
+
+	function finish(res) {
+		over = true;
+		solution = res.sol || null;
+		og.set({movable: frozen});
+		if (res.done) {
+			say('Solved!', 'good');
+		} else {
+			say('That is not the move.', 'bad');
+		}
+
+		const parts = [];
+		if (res.pr) {
+			parts.push('Puzzle rated ' + res.pr + '.');
+		}
+		if (res.r) {
+			const d = res.d || 0;
+			parts.push('Your rating: ' + res.r + ' (' + (d >= 0 ? '+' : '') + d + ').');
+			if (el.rating) {
+				el.rating.textContent = res.r;
+			}
+		}
+		el.result.textContent = parts.join(' ');
+		el.result.classList.toggle('hidden', parts.length === 0);
+
+		if (solution && el.solution) {
+			el.solution.classList.remove('hidden');
+		}
+		el.next.classList.remove('hidden');
+	}
+
+	// replaySolution plays the whole line from the puzzle's start. Every move
+	// is a plain from/to, which the board can show without the rules.
+	function replaySolution() {
+		if (!solution) {
+			return;
+		}
+		el.solution.disabled = true;
+		og.set({ofen: boardOf(puzzle.o), turnColor: solver, lastMove: puzzle.lm || [], check: false});
+		solution.forEach((uoi, i) => {
+			setTimeout(() => {
+				og.move(uoi.slice(0, 2), uoi.slice(2, 4));
+				play('move');
+				if (i === solution.length - 1) {
+					el.solution.disabled = false;
+				}
+			}, replayStep * (i + 1));
+		});
+	}
+
+	if (el.solution) {
+		el.solution.addEventListener('click', replaySolution);
+	}
+
+	function showPromo(orig, dest) {
+		if (!el.promo || !el.promoShade) {
+			sendMove(orig + dest + 'q');
+			return;
+		}
+		el.promoShade.classList.remove('hidden');
+		el.promo.classList.remove('hidden');
+		el.promo.classList.add('f' + dest[0]);
+		const stale = el.promo.getElementsByTagName('piece');
+		for (let i = stale.length - 1; i >= 0; i--) {
+			stale[i].replaceWith(stale[i].cloneNode(true));
+		}
+		const pieces = el.promo.getElementsByTagName('piece');
+		for (let i = 0; i < pieces.length; i++) {
+			const node = pieces[i];
+			node.classList.add(solver);
+			const promo = node.classList.contains('queen') ? 'q'
+				: node.classList.contains('rook') ? 'r'
+					: node.classList.contains('bishop') ? 'b' : 'n';
+			node.addEventListener('click', () => {
+				hidePromo(dest);
+				sendMove(orig + dest + promo);
+			});
+		}
+	}
+
+	function hidePromo(dest) {
+		el.promoShade.classList.add('hidden');
+		el.promo.classList.add('hidden');
+		el.promo.classList.remove('f' + dest[0]);
+		const pieces = el.promo.getElementsByTagName('piece');
+		for (let i = 0; i < pieces.length; i++) {
+			pieces[i].classList.remove('white', 'black');
+		}
+	}
+})();
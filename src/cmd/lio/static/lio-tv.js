@@ -211,6 +211,15 @@
 			}
 			return;
 		}
+		// the matchmaking queue paired this session while it was browsing the
+		// home page: go to the room
+		if (msg.t === 'mm') {
+			if (msg.d && msg.d.r) {
+				stopped = true;
+				location.href = '/' + msg.d.r;
+			}
+			return;
+		}
 		if (msg.t !== 'tv' || !msg.d) {
 			return;
 		}
@@ -415,6 +415,16 @@ window.handlers.set("e", (message) => {
 	}
 });
 
+// The matchmaking queue paired this session (see package matchmaking). It rides
+// every socket, like the redirect above, because a queued player may be
+// watching somebody else's game while they wait.
+window.handlers.set("mm", (message) => {
+	if (message.d && message.d.r) {
+		disconnected = true;
+		window.navigateTo("/" + message.d.r);
+	}
+});
+
 // Server version hello: every socket connect carries the running build's
 // version. window.lioUpdateNotice (inlined in the header — see
 // updateNoticeScript in view/components.templ) compares it against the
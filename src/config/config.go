@@ -39,10 +39,9 @@ var (
 	// CryptoKey for use with cryptographic operations in lio
 	CryptoKey = ReadSecretFallback("crypto_key")
 
-	// DebugFlagPtr contains raw debug flags direct from STDIN
+	// DebugFlagPtr contains raw debug flags direct from STDIN. Each names a
+	// package that boots at debug level; see util.EnableDebug.
 	DebugFlagPtr *string
-	// DebugFlags holds all active, parsed debug flags
-	DebugFlags = make(map[string]bool)
 )
 
 // ReadSecretFallback attempts to read a secret from the secret
@@ -126,11 +125,6 @@ func devPrefix() string {
 	return ""
 }
 
-// IsDebugFlag returns true if a given debug flag is enabled in this instance
-func IsDebugFlag(flag string) bool {
-	return DebugFlags[flag] == true
-}
-
 // GetPort returns the configured primary HTTP port
 func GetPort() string {
 	return os.Getenv("PORT")
@@ -144,6 +138,36 @@ func PlausibleDomain() string {
 	return os.Getenv("PLAUSIBLE_DOMAIN")
 }
 
+// TablebaseDir returns the directory the endgame tablebase files are written
+// to by --gen-tablebase and loaded from at boot (TABLEBASE_DIR env var).
+// Defaults to "tablebase" relative to the working directory — /tablebase in
+// the container image, where the Dockerfile bakes the generated set.
+func TablebaseDir() string {
+	if dir := os.Getenv("TABLEBASE_DIR"); dir != "" {
+		return dir
+	}
+	return "tablebase"
+}
+
+// LogSpillDir returns the directory log lines evicted from the in-memory log
+// ring are appended to (LOG_SPILL_DIR env var), so /system/log can search
+// further back than the ring holds. Empty — the default — keeps only the ring.
+func LogSpillDir() string {
+	return os.Getenv("LOG_SPILL_DIR")
+}
+
+// PremoveThink returns the think time charged for a premove the server plays
+// on a player's behalf (PREMOVE_THINK env var, a Go duration such as "150ms";
+// defaults to 100ms). A premove lands the instant the opponent's move does, so
+// the time it actually took is next to nothing; charging a floor keeps a queue
+// of premoves from being a way to play bullet without spending any clock.
+func PremoveThink() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("PREMOVE_THINK")); err == nil && d >= 0 {
+		return d
+	}
+	return 100 * time.Millisecond
+}
+
 // GetListenPort returns the colon-formatted listen port
 func GetListenPort() string {
 	return fmt.Sprintf(":%s", GetPort())
@@ -166,6 +190,42 @@ func GetHealthAddr() string {
 	return "127.0.0.1:" + GetHealthPort()
 }
 
+// NodeID returns this process's name in a multi-node deployment (package
+// cluster): NODE_ID, else the Fly machine id, else the hostname. It must be
+// stable across a restart of the same node, so a node coming back reclaims its
+// own rooms rather than waiting for them to be adopted elsewhere, and unique
+// among the nodes sharing one Redis.
+func NodeID() string {
+	if id := os.Getenv("NODE_ID"); id != "" {
+		return id
+	}
+	if id := os.Getenv("FLY_MACHINE_ID"); id != "" {
+		return id
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "lio"
+}
+
+// NodeAddr returns the address the other nodes reach this one's primary
+// listener at, over the private network (NODE_ADDR env var). Defaults to the
+// Fly private address when there is one, else the hostname, on the primary
+// port.
+func NodeAddr() string {
+	if addr := os.Getenv("NODE_ADDR"); addr != "" {
+		return addr
+	}
+	if ip := os.Getenv("FLY_PRIVATE_IP"); ip != "" {
+		return "[" + ip + "]:" + GetPort()
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	return host + ":" + GetPort()
+}
+
 // SiteHost returns the canonical public host (no scheme), env-overridable so a
 // future domain move is one env var + DNS. Production defaults to "octad.gg"
 // (SITE_DOMAIN overrides); non-prod is the local listen host.
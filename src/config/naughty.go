@@ -186,3 +186,105 @@ func NaughtyUsername(name string) bool {
 	}
 	return false
 }
+
+// Chat filtering ------------------------------------------------------------
+//
+// NaughtyChat matches the same wordlist against a line of in-game chat. A line
+// is prose, not an identifier, so the match unit is the word: every entry must
+// match a whole token (or, for a phrase, a whole run of tokens), never a
+// fragment. That is what keeps "class", "assess" and "analysis" sayable — the
+// username matcher's substring pass would flag all three in running text,
+// where the Scunthorpe problem is far more common than in names.
+//
+// Evasion is handled per token, as for usernames: leetspeak is folded and
+// separators inside a token are dropped, and a word is also checked with its
+// repeated letters collapsed ("fuuuck"). Symbol entries (a$$) are matched
+// against the raw whitespace-separated words, which is the only form in which
+// they can appear.
+var (
+	naughtyChatOnce    sync.Once
+	naughtyChatWords   map[string]struct{} // folded single words, 3+ chars
+	naughtyChatSquash  map[string]struct{} // 4+ chars, no doubled letters
+	naughtyChatPhrase  []string            // " a b " token runs
+	naughtyChatSymbols map[string]struct{} // raw entries carrying symbols
+)
+
+// buildNaughtyChatIndex sorts the wordlist into the chat matcher's buckets.
+func buildNaughtyChatIndex() {
+	naughtyChatWords = make(map[string]struct{})
+	naughtyChatSquash = make(map[string]struct{})
+	naughtyChatSymbols = make(map[string]struct{})
+	for _, word := range loadNaughty() {
+		lw := strings.ToLower(strings.TrimSpace(word))
+		switch {
+		case lw == "":
+		case strings.Contains(lw, " "):
+			// normalized word by word, exactly as a line is
+			var toks []string
+			for _, f := range strings.Fields(lw) {
+				if tok := stripNaughty(f); tok != "" {
+					toks = append(toks, tok)
+				}
+			}
+			if len(toks) > 1 {
+				naughtyChatPhrase = append(naughtyChatPhrase, " "+strings.Join(toks, " ")+" ")
+			}
+		case strings.ContainsFunc(lw, func(r rune) bool {
+			return !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'))
+		}):
+			naughtyChatSymbols[lw] = struct{}{}
+		default:
+			norm := stripNaughty(lw)
+			if len(norm) < 3 {
+				continue
+			}
+			naughtyChatWords[norm] = struct{}{}
+			// only a word with no doubled letters of its own is matched
+			// squashed, since collapsing "asses" would leave a fragment that
+			// "assess" squashes to as well, and never a three-letter one,
+			// which ordinary words collapse onto ("good")
+			if len(norm) > 3 && squashNaughty(lw) == norm {
+				naughtyChatSquash[norm] = struct{}{}
+			}
+		}
+	}
+	if len(naughtyChatWords) == 0 {
+		panic("naughty chat index empty")
+	}
+}
+
+// NaughtyChat reports whether a chat line contains disallowed language, using
+// the whole-word matching described above. Like NaughtyUsername, callers
+// should never echo which word matched.
+func NaughtyChat(line string) bool {
+	naughtyChatOnce.Do(buildNaughtyChatIndex)
+
+	fields := strings.Fields(strings.ToLower(line))
+	var tokens []string
+	for _, f := range fields {
+		if _, ok := naughtyChatSymbols[strings.Trim(f, ".,!?;:\"'()")]; ok {
+			return true
+		}
+		// a word is a whitespace-separated field with its separators and
+		// leetspeak folded away, so "f_u_c_k" and "5h1t" are one word each
+		tok := stripNaughty(f)
+		if tok == "" {
+			continue
+		}
+		if _, ok := naughtyChatWords[tok]; ok {
+			return true
+		}
+		if _, ok := naughtyChatSquash[squashNaughty(f)]; ok {
+			return true
+		}
+		tokens = append(tokens, tok)
+	}
+
+	joined := " " + strings.Join(tokens, " ") + " "
+	for _, phrase := range naughtyChatPhrase {
+		if strings.Contains(joined, phrase) {
+			return true
+		}
+	}
+	return false
+}
@@ -60,3 +60,28 @@ func TestNaughtyUsername(t *testing.T) {
 		}
 	}
 }
+
+// TestNaughtyChat checks the chat matcher catches words and phrases in running
+// text, with the usual evasions, and leaves ordinary chess talk alone.
+func TestNaughtyChat(t *testing.T) {
+	blocked := []string{
+		"fuck", "well FUCK.", "you absolute 5h1t", "f_u_c_k off", "fuuuck",
+		"what a biiiitch move", "you're a bollocks player",
+	}
+	for _, l := range blocked {
+		if !NaughtyChat(l) {
+			t.Errorf("naughty chat line not caught: %q", l)
+		}
+	}
+
+	allowed := []string{
+		"gg wp", "good game!", "nice sac on e4", "what a class move",
+		"let me assess this", "my analysis says draw", "that passion though",
+		"the compass rose opening", "",
+	}
+	for _, l := range allowed {
+		if NaughtyChat(l) {
+			t.Errorf("ordinary chat line wrongly blocked: %q", l)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+// Package correspondence runs days-per-move games: each side has a fixed
+// number of days for every move (variant.Days), the allowance starts again with
+// each move, and running out of it loses the game.
+//
+// A realtime room cannot carry such a game. Its actor lives in memory, its
+// timeouts assume players who are at the board, and its restart persistence
+// (room.Persist / Rehydrate) is a Redis snapshot meant to outlive a deploy, not
+// a month. So a correspondence game has no actor at all: its Postgres row is
+// the whole game (db/correspondence.go), and every action — accepting, moving,
+// resigning, offering a draw — is one locked read-modify-write of that row.
+// Nothing about a game is held in memory between requests. That is also what
+// lets a move come from any page: it is a plain HTTP request naming the game,
+// with no room socket to be connected to.
+//
+// A player learns that it is their move from a notify.Push, which reaches
+// whichever page they have open, and otherwise waits in their notifications.
+// Up starts the scheduler that flags games whose side to move has run out of
+// time.
+//
+// A finished game is archived through room.Archive, under its own id as the
+// room id, so it rates and reads back like any other game. Each variant is its
+// own rating category (pools.Correspondence). Games start from the standard
+// position: the blind deploy pre-game needs both players at the board at once,
+// which is exactly what a correspondence game never has.
+package correspondence
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+	"github.com/google/uuid"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/pools"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/settings"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/variant"
+)
+
+var (
+	// ErrUnavailable refuses every action when Postgres is unconfigured: the
+	// row is the game, so without a database there is nowhere for one to live.
+	ErrUnavailable = errors.New("correspondence games are not available right now")
+	// ErrNotFound is an unknown game.
+	ErrNotFound = errors.New("no such game")
+	// ErrBadConfig refuses a creation it cannot run.
+	ErrBadConfig = errors.New("that game configuration is not valid")
+	// ErrSelf refuses a challenge to, or a seat opposite, yourself.
+	ErrSelf = errors.New("you cannot play yourself")
+	// ErrNotOpen refuses to seat anybody in a game that is no longer waiting.
+	ErrNotOpen = errors.New("that game is no longer open")
+	// ErrNotInvited refuses the seat of a direct challenge to anybody else.
+	ErrNotInvited = errors.New("that challenge is for somebody else")
+	// ErrNotPlayer refuses an action from somebody with no seat in the game.
+	ErrNotPlayer = errors.New("you are not playing in that game")
+	// ErrNotActive refuses a game action outside a game in progress.
+	ErrNotActive = errors.New("that game is not in progress")
+	// ErrNotYourTurn refuses a move out of turn.
+	ErrNotYourTurn = errors.New("it is not your move")
+	// ErrIllegal refuses a move the position does not allow.
+	ErrIllegal = errors.New("that move is not legal")
+	// ErrBlocked refuses a challenge to, or a seat opposite, an account that
+	// shares a block with the player (db/blocks.go).
+	ErrBlocked = errors.New("you cannot play this player")
+)
+
+// Config describes a game to create.
+type Config struct {
+	// Creator is the creating account; correspondence needs one, since the
+	// game outlives any session.
+	Creator player.Identity
+	Variant variant.Variant
+	// Color is the creator's side; NoColor picks one at random.
+	Color octad.Color
+	Rated bool
+	// Invited makes the game a direct challenge to that account; nil leaves
+	// it a seek anybody may accept.
+	Invited *int64
+}
+
+// Create opens a game: a seek, or a challenge to Config.Invited, who is told
+// about it. The creator's seat is filled; the other waits for Join.
+func Create(cfg Config) (db.CorrespondenceGame, error) {
+	if !db.Ready() {
+		return db.CorrespondenceGame{}, ErrUnavailable
+	}
+	if cfg.Creator.UserID == nil || cfg.Variant.Days <= 0 {
+		return db.CorrespondenceGame{}, ErrBadConfig
+	}
+	if _, ok := pools.Map[cfg.Variant.HTMLName]; !ok {
+		return db.CorrespondenceGame{}, ErrBadConfig
+	}
+	creator := *cfg.Creator.UserID
+	if cfg.Invited != nil && *cfg.Invited == creator {
+		return db.CorrespondenceGame{}, ErrSelf
+	}
+	if cfg.Invited != nil {
+		if err := refuseBlocked(creator, *cfg.Invited); err != nil {
+			return db.CorrespondenceGame{}, err
+		}
+	}
+
+	color := cfg.Color
+	if color == octad.NoColor {
+		color = util.RandomColor()
+	}
+	start, err := octad.NewGame()
+	if err != nil {
+		return db.CorrespondenceGame{}, err
+	}
+
+	g := db.CorrespondenceGame{
+		ID:           newID(),
+		Variant:      cfg.Variant.HTMLName,
+		Rated:        cfg.Rated && settings.Current().RatedEnabled,
+		Creator:      creator,
+		Invited:      cfg.Invited,
+		CreatorUID:   cfg.Creator.UID,
+		Status:       db.CorrespondenceOpen,
+		GameID:       uuid.NewString(),
+		StartingOFEN: start.Position().String(),
+		Created:      time.Now(),
+	}
+	if color == octad.White {
+		g.White, g.WhiteUID = &creator, cfg.Creator.UID
+	} else {
+		g.Black, g.BlackUID = &creator, cfg.Creator.UID
+	}
+	if err := db.InsertCorrespondenceGame(g); err != nil {
+		return db.CorrespondenceGame{}, err
+	}
+
+	util.Info(str.CCorr, "[%s] %s game created by %s", g.ID, g.Variant, cfg.Creator.Username)
+	if g.Invited != nil {
+		tell(*g.Invited, creator, cfg.Creator.Username,
+			cfg.Creator.Username+" challenged you to a correspondence game ("+perMoveLabel(cfg.Variant)+").", g.ID)
+	}
+	return g, nil
+}
+
+// Join takes a game's open seat for the given account, and tells the creator.
+func Join(id string, who player.Identity) (db.CorrespondenceGame, error) {
+	if who.UserID == nil {
+		return db.CorrespondenceGame{}, ErrNotPlayer
+	}
+	// the creator never changes, so the block check can read it ahead of the
+	// locked update rather than inside it
+	open, err := Get(id)
+	if err != nil {
+		return open, err
+	}
+	if err := refuseBlocked(open.Creator, *who.UserID); err != nil {
+		return db.CorrespondenceGame{}, err
+	}
+	now := time.Now()
+	g, err := update(id, func(g *db.CorrespondenceGame) error {
+		return join(g, *who.UserID, who.UID, now)
+	})
+	if err != nil {
+		return g, err
+	}
+	util.Info(str.CCorr, "[%s] joined by %s", g.ID, who.Username)
+	room.GameStarted(VariantOf(g))
+	// a creator playing White hears that it is their move, which says the
+	// game was accepted; one notification is enough
+	if next := ToMove(g); next != nil && *next == g.Creator {
+		announceTurn(g, *who.UserID, who.Username)
+	} else {
+		tell(g.Creator, *who.UserID, who.Username, who.Username+" accepted your correspondence game.", g.ID)
+	}
+	return g, nil
+}
+
+// refuseBlocked returns ErrBlocked when the two accounts share a block. A
+// failed read is returned as it is, which refuses as well.
+func refuseBlocked(a, b int64) error {
+	blocked, err := db.Blocked(a, b)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// Withdraw ends a game that has not started: the creator takes back a seek or
+// a challenge, or the invited player declines one. Neither counts for
+// anything.
+func Withdraw(id string, who player.Identity) (db.CorrespondenceGame, error) {
+	if who.UserID == nil {
+		return db.CorrespondenceGame{}, ErrNotPlayer
+	}
+	now := time.Now()
+	g, err := update(id, func(g *db.CorrespondenceGame) error {
+		return withdraw(g, *who.UserID, now)
+	})
+	if err != nil {
+		return g, err
+	}
+	if g.Invited != nil && *g.Invited == *who.UserID {
+		tell(g.Creator, *who.UserID, who.Username, who.Username+" declined your correspondence challenge.", g.ID)
+	}
+	return g, nil
+}
+
+// Get reads one game.
+func Get(id string) (db.CorrespondenceGame, error) {
+	if !db.Ready() {
+		return db.CorrespondenceGame{}, ErrUnavailable
+	}
+	g, ok, err := db.GetCorrespondenceGame(id)
+	if err != nil {
+		return db.CorrespondenceGame{}, err
+	}
+	if !ok {
+		return db.CorrespondenceGame{}, ErrNotFound
+	}
+	return g, nil
+}
+
+// VariantOf resolves a game's variant. A game whose variant is no longer
+// offered still resolves, by its days, so it can be finished.
+func VariantOf(g db.CorrespondenceGame) variant.Variant {
+	if v, ok := pools.Map[g.Variant]; ok {
+		return v
+	}
+	return variant.SevenDayCorrespondence
+}
+
+// update runs one action through db.UpdateCorrespondenceGame.
+func update(id string, apply func(*db.CorrespondenceGame) error) (db.CorrespondenceGame, error) {
+	if !db.Ready() {
+		return db.CorrespondenceGame{}, ErrUnavailable
+	}
+	g, ok, err := db.UpdateCorrespondenceGame(id, apply)
+	if err != nil {
+		return g, err
+	}
+	if !ok {
+		return g, ErrNotFound
+	}
+	return g, nil
+}
+
+// newID draws a game id from the room id space, unused by any room, live or
+// archived, and by any other game. The archived game takes it as its room id,
+// so it must never have been one.
+func newID() string {
+	for {
+		id := config.GenerateCode(7, config.Base58)
+		if _, err := room.Get(id); err == nil {
+			continue
+		}
+		if !db.RoomIDExists(id) {
+			return id
+		}
+	}
+}
+
+// perMoveLabel is a variant's allowance as a sentence fragment: "3 days per
+// move".
+func perMoveLabel(v variant.Variant) string {
+	return v.Name + " per move"
+}
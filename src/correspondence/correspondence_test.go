@@ -0,0 +1,263 @@
+package correspondence
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/variant"
+)
+
+const (
+	alice int64 = 1
+	bob   int64 = 2
+	carol int64 = 3
+)
+
+var t0 = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// openGame is a three-day seek alice created as White.
+func openGame(t *testing.T) db.CorrespondenceGame {
+	t.Helper()
+	start, err := octad.NewGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := alice
+	return db.CorrespondenceGame{
+		ID:           "corr123",
+		Variant:      variant.ThreeDayCorrespondence.HTMLName,
+		Creator:      alice,
+		White:        &a,
+		WhiteUID:     "uid-alice",
+		Status:       db.CorrespondenceOpen,
+		GameID:       "00000000-0000-0000-0000-000000000001",
+		StartingOFEN: start.Position().String(),
+		Created:      t0,
+	}
+}
+
+// activeGame is openGame with bob seated as Black.
+func activeGame(t *testing.T) db.CorrespondenceGame {
+	t.Helper()
+	g := openGame(t)
+	if err := join(&g, bob, "uid-bob", t0); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// anyMove is some legal move for the side to move.
+func anyMove(t *testing.T, g db.CorrespondenceGame) string {
+	t.Helper()
+	b, err := board(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.ValidMoves()[0].String()
+}
+
+func TestJoinSeatsTheOpenSideAndStartsTheClock(t *testing.T) {
+	g := openGame(t)
+	if err := join(&g, alice, "uid-alice", t0); !errors.Is(err, ErrSelf) {
+		t.Fatalf("creator joining own game: err = %v, want ErrSelf", err)
+	}
+	if err := join(&g, bob, "uid-bob", t0); err != nil {
+		t.Fatal(err)
+	}
+	if g.Black == nil || *g.Black != bob || g.BlackUID != "uid-bob" {
+		t.Fatalf("black seat = %v %q, want bob", g.Black, g.BlackUID)
+	}
+	if g.Status != db.CorrespondenceActive {
+		t.Fatalf("status = %q, want active", g.Status)
+	}
+	if want := t0.Add(72 * time.Hour); !g.Deadline.Equal(want) {
+		t.Fatalf("deadline = %s, want %s", g.Deadline, want)
+	}
+	if err := join(&g, carol, "uid-carol", t0); !errors.Is(err, ErrNotOpen) {
+		t.Fatalf("joining a started game: err = %v, want ErrNotOpen", err)
+	}
+}
+
+func TestChallengeSeatsOnlyTheInvitee(t *testing.T) {
+	g := openGame(t)
+	b := bob
+	g.Invited = &b
+	if err := join(&g, carol, "uid-carol", t0); !errors.Is(err, ErrNotInvited) {
+		t.Fatalf("stranger accepting a challenge: err = %v, want ErrNotInvited", err)
+	}
+	if err := withdraw(&g, carol, t0); !errors.Is(err, ErrNotPlayer) {
+		t.Fatalf("stranger declining a challenge: err = %v, want ErrNotPlayer", err)
+	}
+	if err := withdraw(&g, bob, t0); err != nil {
+		t.Fatal(err)
+	}
+	if g.Status != db.CorrespondenceAborted {
+		t.Fatalf("status = %q, want aborted", g.Status)
+	}
+}
+
+func TestMoveTakesTurnsAndRenewsTheDeadline(t *testing.T) {
+	g := activeGame(t)
+	first := anyMove(t, g)
+	if err := move(&g, bob, first, t0); !errors.Is(err, ErrNotYourTurn) {
+		t.Fatalf("black moving first: err = %v, want ErrNotYourTurn", err)
+	}
+	if err := move(&g, carol, first, t0); !errors.Is(err, ErrNotPlayer) {
+		t.Fatalf("spectator moving: err = %v, want ErrNotPlayer", err)
+	}
+	if err := move(&g, alice, "a1h8", t0); !errors.Is(err, ErrIllegal) {
+		t.Fatalf("nonsense move: err = %v, want ErrIllegal", err)
+	}
+
+	later := t0.Add(30 * time.Hour)
+	if err := move(&g, alice, first, later); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Moves) != 1 || g.Moves[0] != first {
+		t.Fatalf("moves = %v, want [%s]", g.Moves, first)
+	}
+	if want := later.Add(72 * time.Hour); !g.Deadline.Equal(want) {
+		t.Fatalf("deadline = %s, want a full allowance from the move, %s", g.Deadline, want)
+	}
+	if next := ToMove(g); next == nil || *next != bob {
+		t.Fatalf("to move = %v, want bob", next)
+	}
+}
+
+func TestDrawOfferAcceptedByTheOpponent(t *testing.T) {
+	g := activeGame(t)
+	if err := offerDraw(&g, alice, t0); err != nil {
+		t.Fatal(err)
+	}
+	if g.DrawOffer != "w" {
+		t.Fatalf("draw offer = %q, want w", g.DrawOffer)
+	}
+	// offering again is not accepting your own offer
+	if err := offerDraw(&g, alice, t0); err != nil || g.Status != db.CorrespondenceActive {
+		t.Fatalf("repeat offer: err = %v, status = %q", err, g.Status)
+	}
+	if err := offerDraw(&g, bob, t0); err != nil {
+		t.Fatal(err)
+	}
+	if g.Status != db.CorrespondenceFinished || g.Outcome != string(octad.Draw) || g.Reason != "agreement" {
+		t.Fatalf("accepted draw left %q %q %q", g.Status, g.Outcome, g.Reason)
+	}
+}
+
+func TestMoveAndDeclineClearTheOffer(t *testing.T) {
+	g := activeGame(t)
+	if err := offerDraw(&g, alice, t0); err != nil {
+		t.Fatal(err)
+	}
+	// the offerer cannot decline their own offer
+	if err := declineDraw(&g, alice); err != nil || g.DrawOffer != "w" {
+		t.Fatalf("self-decline: err = %v, offer = %q", err, g.DrawOffer)
+	}
+	if err := declineDraw(&g, bob); err != nil || g.DrawOffer != "" {
+		t.Fatalf("decline: err = %v, offer = %q", err, g.DrawOffer)
+	}
+
+	if err := offerDraw(&g, alice, t0); err != nil {
+		t.Fatal(err)
+	}
+	if err := move(&g, alice, anyMove(t, g), t0); err != nil {
+		t.Fatal(err)
+	}
+	if g.DrawOffer != "" {
+		t.Fatalf("a move left the offer %q standing", g.DrawOffer)
+	}
+}
+
+func TestResign(t *testing.T) {
+	g := activeGame(t)
+	if err := resign(&g, bob, t0); err != nil {
+		t.Fatal(err)
+	}
+	if g.Status != db.CorrespondenceFinished || g.Outcome != string(octad.WhiteWon) || g.Reason != "resignation" {
+		t.Fatalf("resignation left %q %q %q", g.Status, g.Outcome, g.Reason)
+	}
+	if err := resign(&g, alice, t0); !errors.Is(err, ErrNotActive) {
+		t.Fatalf("resigning a finished game: err = %v, want ErrNotActive", err)
+	}
+}
+
+func TestFlagAbortsAGameNeitherSideStarted(t *testing.T) {
+	g := activeGame(t)
+	if flagged, err := flag(&g, g.Deadline.Add(-time.Second)); err != nil || flagged {
+		t.Fatalf("flag before the deadline: flagged = %v, err = %v", flagged, err)
+	}
+	if err := move(&g, alice, anyMove(t, g), t0); err != nil {
+		t.Fatal(err)
+	}
+	flagged, err := flag(&g, g.Deadline)
+	if err != nil || !flagged {
+		t.Fatalf("flag at the deadline: flagged = %v, err = %v", flagged, err)
+	}
+	if g.Status != db.CorrespondenceAborted {
+		t.Fatalf("status = %q, want aborted: black never moved", g.Status)
+	}
+}
+
+func TestFlagLosesOnTime(t *testing.T) {
+	g := activeGame(t)
+	for _, who := range []int64{alice, bob, alice} {
+		if err := move(&g, who, anyMove(t, g), t0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flagged, err := flag(&g, g.Deadline.Add(time.Minute))
+	if err != nil || !flagged {
+		t.Fatalf("flag: flagged = %v, err = %v", flagged, err)
+	}
+	// black was to move
+	if g.Status != db.CorrespondenceFinished || g.Outcome != string(octad.WhiteWon) || g.Reason != "time" {
+		t.Fatalf("flag left %q %q %q", g.Status, g.Outcome, g.Reason)
+	}
+	if !g.Deadline.IsZero() {
+		t.Fatalf("finished game kept deadline %s", g.Deadline)
+	}
+}
+
+func TestDescribeShowsMovesOnlyToTheSideToMove(t *testing.T) {
+	g := activeGame(t)
+	white, err := Describe(g, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if white.Seat != "white" || white.Turn != "white" || len(white.Dests) == 0 {
+		t.Fatalf("white's view = %+v, want their own legal moves", white)
+	}
+	black, err := Describe(g, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if black.Seat != "black" || len(black.Dests) != 0 {
+		t.Fatalf("black's view = %+v, want no moves out of turn", black)
+	}
+	spectator, err := Describe(g, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spectator.Seat != "" || len(spectator.Dests) != 0 || spectator.Deadline != g.Deadline.UnixMilli() {
+		t.Fatalf("spectator's view = %+v", spectator)
+	}
+}
+
+func TestResultLine(t *testing.T) {
+	for _, tc := range []struct {
+		outcome, reason, want string
+	}{
+		{"1-0", "checkmate", "White won by checkmate"},
+		{"0-1", "time", "Black won on time"},
+		{"1/2-1/2", "agreement", "drawn by agreement"},
+		{"*", "", "aborted"},
+	} {
+		if got := ResultLine(tc.outcome, tc.reason); got != tc.want {
+			t.Errorf("ResultLine(%q, %q) = %q, want %q", tc.outcome, tc.reason, got, tc.want)
+		}
+	}
+}
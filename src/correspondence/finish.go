@@ -0,0 +1,150 @@
+package correspondence
+
+import (
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/notify"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// finished archives a game that has just ended and tells whoever did not end
+// it. actor is the player whose action ended it, nil when the scheduler
+// flagged it; both players hear about a flag, since neither was there.
+func finished(g db.CorrespondenceGame, actor *int64, actorName string) {
+	util.Info(str.CCorr, "[%s] finished %s by %s", g.ID, g.Outcome, g.Reason)
+	room.GameFinished(VariantOf(g))
+	archive(g)
+
+	line := "Your correspondence game is over: " + ResultLine(g.Outcome, g.Reason) + "."
+	for _, seat := range []*int64{g.White, g.Black} {
+		if seat == nil || (actor != nil && *seat == *actor) {
+			continue
+		}
+		if actor == nil {
+			tell(*seat, 0, "", line, g.ID)
+		} else {
+			tell(*seat, *actor, actorName, line, g.ID)
+		}
+	}
+}
+
+// archive writes a finished game to the archive under its own id, through the
+// seam every room's game takes.
+func archive(g db.CorrespondenceGame) {
+	og, err := restore(g)
+	if err != nil {
+		util.Error(str.CCorr, "[%s] archive rebuild failed: %s", g.ID, err.Error())
+		return
+	}
+	// a declared result is the archive's to re-apply, as a rehydrated room's is
+	// (see game.RestoreOctadGame); the board re-derives the rest from the moves
+	if og.Outcome() == octad.NoOutcome {
+		switch octad.Outcome(g.Outcome) {
+		case octad.Draw:
+			_ = og.Draw(octad.DrawOffer)
+		case octad.WhiteWon:
+			og.Resign(octad.Black)
+		case octad.BlackWon:
+			og.Resign(octad.White)
+		}
+	}
+
+	whiteName, whiteTitle := db.UserDisplayForID(g.White)
+	blackName, blackTitle := db.UserDisplayForID(g.Black)
+	creator := g.Creator
+	var whiteScore, blackScore float64
+	switch octad.Outcome(g.Outcome) {
+	case octad.WhiteWon:
+		whiteScore = 1
+	case octad.BlackWon:
+		blackScore = 1
+	case octad.Draw:
+		whiteScore, blackScore = 0.5, 0.5
+	}
+	room.Archive(*og, db.GameRecord{
+		RoomID:        g.ID,
+		Creator:       g.CreatorUID,
+		CreatorUserID: &creator,
+		Rated:         g.Rated,
+		// one game is the whole match
+		WhiteMatchScore: whiteScore,
+		BlackMatchScore: blackScore,
+		Reason:          g.Reason,
+		WhiteUserID:     g.White,
+		BlackUserID:     g.Black,
+		WhiteName:       game.PGNSeatName(whiteName, whiteTitle.Code, "", "", false),
+		BlackName:       game.PGNSeatName(blackName, blackTitle.Code, "", "", false),
+	}, g.Finished)
+}
+
+// announceTurn tells the player to move that it is their move, unless they are
+// the one who just acted.
+func announceTurn(g db.CorrespondenceGame, actor int64, actorName string) {
+	next := ToMove(g)
+	if next == nil || *next == actor {
+		return
+	}
+	tell(*next, actor, actorName, "Your move against "+actorName+" ("+perMoveLabel(VariantOf(g))+").", g.ID)
+}
+
+// tell pushes one correspondence notification, linking to the game. actor is
+// 0 for news from the site itself. A failure is logged and swallowed, like
+// every other producer's: the action it reports has already happened.
+func tell(userID, actor int64, actorName, body, id string) {
+	n := db.NewNotification{
+		UserID: userID,
+		Kind:   db.KindCorrespondence,
+		Body:   body,
+		Link:   URL(id),
+	}
+	if actor != 0 {
+		n.ActorID = &actor
+	}
+	if err := notify.Push(n, actorName); err != nil {
+		util.Error(str.CCorr, "[%s] notification failed: %s", id, err.Error())
+	}
+}
+
+// URL is a game's page.
+func URL(id string) string {
+	return "/correspondence/" + id
+}
+
+// ResultLine describes a finished game's result in a sentence fragment:
+// "White won by checkmate", "drawn by agreement".
+func ResultLine(outcome, reason string) string {
+	var head string
+	switch octad.Outcome(outcome) {
+	case octad.WhiteWon:
+		head = "White won"
+	case octad.BlackWon:
+		head = "Black won"
+	case octad.Draw:
+		head = "drawn"
+	default:
+		return "aborted"
+	}
+	switch reason {
+	case "checkmate":
+		return head + " by checkmate"
+	case "resignation":
+		return head + " by resignation"
+	case "time":
+		return head + " on time"
+	case "agreement":
+		return head + " by agreement"
+	case "stalemate":
+		return head + " by stalemate"
+	case "insufficient":
+		return head + " by insufficient material"
+	case "repetition":
+		return head + " by repetition"
+	case "moverule":
+		return head + " by the 25-move rule"
+	}
+	return head
+}
@@ -0,0 +1,330 @@
+package correspondence
+
+import (
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/player"
+)
+
+// The actions below are each one call to update: the row is locked, the game
+// rebuilt from its moves, the action applied, and the row written back. The
+// transitions themselves (join, move, ...) only edit the row, so they are
+// checked without a database; the exported wrappers add what follows a
+// transition — the notifications, and the archive once a game is over.
+
+// Move plays one move for the given player, in UOI.
+func Move(id string, who player.Identity, uoi string) (db.CorrespondenceGame, error) {
+	if who.UserID == nil {
+		return db.CorrespondenceGame{}, ErrNotPlayer
+	}
+	now := time.Now()
+	g, err := update(id, func(g *db.CorrespondenceGame) error {
+		return move(g, *who.UserID, uoi, now)
+	})
+	if err != nil {
+		return g, err
+	}
+	if g.Status == db.CorrespondenceFinished {
+		finished(g, who.UserID, who.Username)
+		return g, nil
+	}
+	announceTurn(g, *who.UserID, who.Username)
+	return g, nil
+}
+
+// Resign ends the game as a loss for the given player.
+func Resign(id string, who player.Identity) (db.CorrespondenceGame, error) {
+	if who.UserID == nil {
+		return db.CorrespondenceGame{}, ErrNotPlayer
+	}
+	now := time.Now()
+	g, err := update(id, func(g *db.CorrespondenceGame) error {
+		return resign(g, *who.UserID, now)
+	})
+	if err != nil {
+		return g, err
+	}
+	finished(g, who.UserID, who.Username)
+	return g, nil
+}
+
+// OfferDraw offers a draw for the given player, or accepts the opponent's
+// standing offer, which draws the game. The opponent is told of an offer.
+func OfferDraw(id string, who player.Identity) (db.CorrespondenceGame, error) {
+	if who.UserID == nil {
+		return db.CorrespondenceGame{}, ErrNotPlayer
+	}
+	now := time.Now()
+	g, err := update(id, func(g *db.CorrespondenceGame) error {
+		return offerDraw(g, *who.UserID, now)
+	})
+	if err != nil {
+		return g, err
+	}
+	if g.Status == db.CorrespondenceFinished {
+		finished(g, who.UserID, who.Username)
+		return g, nil
+	}
+	if opp := opponentOf(g, *who.UserID); opp != nil {
+		tell(*opp, *who.UserID, who.Username, who.Username+" offers a draw in your correspondence game.", g.ID)
+	}
+	return g, nil
+}
+
+// DeclineDraw turns down the opponent's standing offer.
+func DeclineDraw(id string, who player.Identity) (db.CorrespondenceGame, error) {
+	if who.UserID == nil {
+		return db.CorrespondenceGame{}, ErrNotPlayer
+	}
+	return update(id, func(g *db.CorrespondenceGame) error {
+		return declineDraw(g, *who.UserID)
+	})
+}
+
+// join seats userID in the open seat and starts the clock on White's first
+// move.
+func join(g *db.CorrespondenceGame, userID int64, uid string, now time.Time) error {
+	if g.Status != db.CorrespondenceOpen {
+		return ErrNotOpen
+	}
+	if userID == g.Creator {
+		return ErrSelf
+	}
+	if g.Invited != nil && *g.Invited != userID {
+		return ErrNotInvited
+	}
+	if g.White == nil {
+		g.White, g.WhiteUID = &userID, uid
+	} else {
+		g.Black, g.BlackUID = &userID, uid
+	}
+	g.Status = db.CorrespondenceActive
+	g.Started = now
+	g.Deadline = now.Add(VariantOf(*g).PerMove())
+	return nil
+}
+
+// withdraw aborts an open game at the creator's or the invited player's
+// request.
+func withdraw(g *db.CorrespondenceGame, userID int64, now time.Time) error {
+	if g.Status != db.CorrespondenceOpen {
+		return ErrNotOpen
+	}
+	if userID != g.Creator && (g.Invited == nil || *g.Invited != userID) {
+		return ErrNotPlayer
+	}
+	g.Status = db.CorrespondenceAborted
+	g.Finished = now
+	return nil
+}
+
+// move applies one move and either ends the game, when the board decides it,
+// or hands the move over with a fresh allowance.
+func move(g *db.CorrespondenceGame, userID int64, uoi string, now time.Time) error {
+	b, color, err := playing(g, userID)
+	if err != nil {
+		return err
+	}
+	if b.Position().Turn() != color {
+		return ErrNotYourTurn
+	}
+	var legal *octad.Move
+	for _, m := range b.ValidMoves() {
+		if m.String() == uoi {
+			legal = m
+			break
+		}
+	}
+	if legal == nil {
+		return ErrIllegal
+	}
+	if err := b.Move(legal); err != nil {
+		return ErrIllegal
+	}
+
+	g.Moves = append(g.Moves, uoi)
+	g.LastMove = now
+	// a move supersedes any standing offer, the mover's own included, as it
+	// does in a room
+	g.DrawOffer = ""
+	if b.Outcome() != octad.NoOutcome {
+		settle(g, b, boardReason(b), now)
+		return nil
+	}
+	g.Deadline = now.Add(VariantOf(*g).PerMove())
+	return nil
+}
+
+// resign ends the game as a loss for userID.
+func resign(g *db.CorrespondenceGame, userID int64, now time.Time) error {
+	b, color, err := playing(g, userID)
+	if err != nil {
+		return err
+	}
+	b.Resign(color)
+	settle(g, b, "resignation", now)
+	return nil
+}
+
+// offerDraw records userID's offer, or draws the game when the opponent's
+// offer is already standing.
+func offerDraw(g *db.CorrespondenceGame, userID int64, now time.Time) error {
+	b, color, err := playing(g, userID)
+	if err != nil {
+		return err
+	}
+	if g.DrawOffer != "" && g.DrawOffer != colorCode(color) {
+		if err := b.Draw(octad.DrawOffer); err != nil {
+			return err
+		}
+		settle(g, b, "agreement", now)
+		return nil
+	}
+	g.DrawOffer = colorCode(color)
+	return nil
+}
+
+// declineDraw clears the opponent's offer. Declining nothing is not an error:
+// the offer may simply have been superseded by a move first.
+func declineDraw(g *db.CorrespondenceGame, userID int64) error {
+	_, color, err := playing(g, userID)
+	if err != nil {
+		return err
+	}
+	if g.DrawOffer != "" && g.DrawOffer != colorCode(color) {
+		g.DrawOffer = ""
+	}
+	return nil
+}
+
+// flag ends a game whose deadline has passed at now: the side to move loses
+// on time. A game in which either side has yet to move is aborted instead,
+// unrated and unarchived, since one side never played it. A game whose
+// deadline has not passed (a move landed just before the sweep locked the
+// row) is left alone, and flag reports false.
+func flag(g *db.CorrespondenceGame, now time.Time) (bool, error) {
+	if g.Status != db.CorrespondenceActive || g.Deadline.IsZero() || now.Before(g.Deadline) {
+		return false, nil
+	}
+	if len(g.Moves) < 2 {
+		g.Status = db.CorrespondenceAborted
+		g.Deadline = time.Time{}
+		g.DrawOffer = ""
+		g.Finished = now
+		return true, nil
+	}
+	b, err := board(*g)
+	if err != nil {
+		return false, err
+	}
+	b.Resign(b.Position().Turn())
+	settle(g, b, "time", now)
+	return true, nil
+}
+
+// settle records a decided board as the game's result.
+func settle(g *db.CorrespondenceGame, b *octad.Game, reason string, now time.Time) {
+	g.Status = db.CorrespondenceFinished
+	g.Outcome = string(b.Outcome())
+	g.Reason = reason
+	g.Deadline = time.Time{}
+	g.DrawOffer = ""
+	g.Finished = now
+}
+
+// playing rebuilds an active game's board and resolves userID's side in it.
+func playing(g *db.CorrespondenceGame, userID int64) (*octad.Game, octad.Color, error) {
+	if g.Status != db.CorrespondenceActive {
+		return nil, octad.NoColor, ErrNotActive
+	}
+	color := SeatOf(*g, userID)
+	if color == octad.NoColor {
+		return nil, octad.NoColor, ErrNotPlayer
+	}
+	b, err := board(*g)
+	if err != nil {
+		return nil, octad.NoColor, err
+	}
+	return b, color, nil
+}
+
+// board rebuilds a game's position from its moves.
+func board(g db.CorrespondenceGame) (*octad.Game, error) {
+	og, err := restore(g)
+	if err != nil {
+		return nil, err
+	}
+	return &og.Game, nil
+}
+
+// restore rebuilds a game as the archive takes it. The game has no clock, and
+// so no move times: it archives untimed.
+func restore(g db.CorrespondenceGame) (*game.OctadGame, error) {
+	return game.RestoreOctadGame(game.OctadGameConfig{
+		White:   g.WhiteUID,
+		Black:   g.BlackUID,
+		Variant: VariantOf(g),
+	}, g.GameID, g.Started, g.StartingOFEN, g.Moves, nil, nil)
+}
+
+// SeatOf is userID's side in the game, or NoColor for anybody else.
+func SeatOf(g db.CorrespondenceGame, userID int64) octad.Color {
+	switch {
+	case userID == 0:
+		return octad.NoColor
+	case g.White != nil && *g.White == userID:
+		return octad.White
+	case g.Black != nil && *g.Black == userID:
+		return octad.Black
+	}
+	return octad.NoColor
+}
+
+// opponentOf is the other seat's account, nil while it is empty.
+func opponentOf(g db.CorrespondenceGame, userID int64) *int64 {
+	switch SeatOf(g, userID) {
+	case octad.White:
+		return g.Black
+	case octad.Black:
+		return g.White
+	}
+	return nil
+}
+
+// ToMove is the account whose move it is in an active game.
+func ToMove(g db.CorrespondenceGame) *int64 {
+	if len(g.Moves)%2 == 0 {
+		return g.White
+	}
+	return g.Black
+}
+
+// boardReason is the games.reason token of a game the board decided, as the
+// room's gameOverReasonLocked names it.
+func boardReason(b *octad.Game) string {
+	switch b.Method() {
+	case octad.Checkmate:
+		return "checkmate"
+	case octad.InsufficientMaterial:
+		return "insufficient"
+	case octad.Stalemate:
+		return "stalemate"
+	case octad.ThreefoldRepetition:
+		return "repetition"
+	case octad.TwentyFiveMoveRule:
+		return "moverule"
+	}
+	return ""
+}
+
+// colorCode is the draw_offer column's spelling of a side.
+func colorCode(c octad.Color) string {
+	if c == octad.White {
+		return "w"
+	}
+	return "b"
+}
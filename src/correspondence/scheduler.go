@@ -0,0 +1,82 @@
+package correspondence
+
+import (
+	"time"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// sweepTick is how often the scheduler looks for overdue games. A deadline is
+// days away, so a minute late is nothing anybody can notice.
+const sweepTick = time.Minute
+
+// sweepBatch bounds the games flagged in one sweep; a backlog (the process was
+// down over a deadline) drains over the following ticks.
+const sweepBatch = 50
+
+// Up starts the scheduler that flags overdue games. Without Postgres there are
+// no games to flag.
+//
+// Every instance may run it. Each flag is a locked read-modify-write that
+// re-checks the deadline under the lock, so a game two sweeps reach at once is
+// flagged by whichever locks it first, and the other finds it already over.
+func Up() {
+	if !db.Ready() {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(sweepTick)
+		defer ticker.Stop()
+		for range ticker.C {
+			safeSweep()
+		}
+	}()
+	util.Debug(str.CCorr, "correspondence scheduler online")
+}
+
+// safeSweep runs one sweep, converting a panic into an error log so one bad
+// row cannot stop every other game's clock.
+func safeSweep() {
+	defer func() {
+		if r := recover(); r != nil {
+			util.Error(str.CCorr, "correspondence sweep panicked: %v", r)
+		}
+	}()
+	sweep(time.Now())
+}
+
+// sweep flags every game whose deadline had passed by now.
+func sweep(now time.Time) {
+	due, err := db.DueCorrespondenceGames(now, sweepBatch)
+	if err != nil {
+		util.Error(str.CCorr, "correspondence sweep list failed: %s", err.Error())
+		return
+	}
+	for _, id := range due {
+		var flagged bool
+		g, err := update(id, func(g *db.CorrespondenceGame) error {
+			var err error
+			flagged, err = flag(g, now)
+			return err
+		})
+		if err != nil {
+			util.Error(str.CCorr, "[%s] flag failed: %s", id, err.Error())
+			continue
+		}
+		if !flagged {
+			continue
+		}
+		if g.Status == db.CorrespondenceAborted {
+			util.Info(str.CCorr, "[%s] aborted: nobody moved in time", id)
+			for _, seat := range []*int64{g.White, g.Black} {
+				if seat != nil {
+					tell(*seat, 0, "", "Your correspondence game was aborted: the first moves were not made in time.", id)
+				}
+			}
+			continue
+		}
+		finished(g, nil, "")
+	}
+}
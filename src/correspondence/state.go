@@ -0,0 +1,81 @@
+package correspondence
+
+import (
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/learn"
+)
+
+// State is a game as one viewer sees it. It is the #correspondence-data payload
+// lio-correspondence.js boots from and the API's answer to every action, so
+// field names are the wire's, and the board fields match the trainer's.
+type State struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	// Seat is the viewer's side, "white" or "black", "" for a spectator.
+	Seat string `json:"seat,omitempty"`
+
+	OFEN     string   `json:"o"`
+	Turn     string   `json:"t"`
+	Check    bool     `json:"k,omitempty"`
+	LastMove []string `json:"lm,omitempty"`
+	// Dests are the viewer's legal moves, present only when it is their move.
+	Dests map[string][]string `json:"v,omitempty"`
+	SAN   []string            `json:"san"`
+
+	// Deadline is when the side to move loses on time, in unix milliseconds; 0
+	// unless the game is in progress.
+	Deadline  int64  `json:"deadline,omitempty"`
+	DrawOffer string `json:"draw,omitempty"`
+	Outcome   string `json:"outcome,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	// Result is ResultLine's sentence for a game that is over.
+	Result string `json:"result,omitempty"`
+}
+
+// Describe is the game as viewerID (0 when anonymous) sees it.
+func Describe(g db.CorrespondenceGame, viewerID int64) (State, error) {
+	s := State{
+		ID:        g.ID,
+		Status:    g.Status,
+		SAN:       []string{},
+		DrawOffer: g.DrawOffer,
+	}
+	seat := SeatOf(g, viewerID)
+	switch seat {
+	case octad.White:
+		s.Seat = "white"
+	case octad.Black:
+		s.Seat = "black"
+	}
+
+	og, err := restore(g)
+	if err != nil {
+		return s, err
+	}
+	pos := og.Position()
+	s.OFEN = pos.String()
+	s.Check = pos.InCheck()
+	s.Turn = "white"
+	if pos.Turn() == octad.Black {
+		s.Turn = "black"
+	}
+	if n := len(g.Moves); n > 0 && len(g.Moves[n-1]) >= 4 {
+		s.LastMove = []string{g.Moves[n-1][0:2], g.Moves[n-1][2:4]}
+	}
+	s.SAN = og.SANHistory()
+
+	switch g.Status {
+	case db.CorrespondenceActive:
+		s.Deadline = g.Deadline.UnixMilli()
+		if seat != octad.NoColor && pos.Turn() == seat {
+			s.Dests = learn.LegalDests(&og.Game)
+		}
+	case db.CorrespondenceFinished, db.CorrespondenceAborted:
+		s.Outcome = g.Outcome
+		s.Reason = g.Reason
+		s.Result = ResultLine(g.Outcome, g.Reason)
+	}
+	return s, nil
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/dechristopher/lio/db/gen"
+)
+
+// Post-game analysis (package analysis): the review queue and its results.
+// Like the evaluator's accessors these degrade to no-ops without a pool, so an
+// archive page simply offers no review.
+
+// autoAnalysis queues every finished game for review as it is archived. Set by
+// the analysis package when automatic review is enabled; otherwise a game is
+// only reviewed when somebody asks.
+var autoAnalysis atomic.Bool
+
+// EnableAutoAnalysis makes ArchiveGame queue each game it archives for review.
+func EnableAutoAnalysis() {
+	autoAnalysis.Store(true)
+}
+
+// GameAnalysis is a game's review: queued until Done, then the engine's eval
+// of the position after each ply (white-positive centipawns), the judgement of
+// each move, and each side's accuracy and average centipawn loss.
+type GameAnalysis struct {
+	Done bool
+	// Failed marks a review that gave up; a fresh request starts it over.
+	Failed     bool
+	Depth      int
+	Evals      []int16
+	Judgements []int16
+	White      SideAnalysis
+	Black      SideAnalysis
+}
+
+// SideAnalysis is one side's score in a reviewed game.
+type SideAnalysis struct {
+	Accuracy float32
+	ACPL     int16
+}
+
+// Accuracy is an account's accuracy across its reviewed games.
+type Accuracy struct {
+	Games    int64
+	Accuracy float32
+}
+
+// EnqueueAnalysis queues a game for review, reporting whether anything was
+// queued: false for a game already queued or reviewed. A review that gave up
+// after maxAttempts is started over.
+func EnqueueAnalysis(gameRef int32, maxAttempts int) (bool, error) {
+	if Pool == nil {
+		return false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	n, err := gen.New(Pool).EnqueueAnalysis(ctx, gen.EnqueueAnalysisParams{
+		GameRef:     gameRef,
+		MaxAttempts: int32(maxAttempts),
+	})
+	return n > 0, err
+}
+
+// ClaimedAnalysis is a claimed review on its way to the worker.
+type ClaimedAnalysis struct {
+	GameRef  int32
+	Attempts int
+}
+
+// ClaimDueAnalysis takes up to batch due reviews that have failed fewer than
+// maxAttempts times, leasing them to the caller until leaseUntil.
+func ClaimDueAnalysis(leaseUntil time.Time, maxAttempts, batch int) ([]ClaimedAnalysis, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ClaimDueAnalysis(ctx, gen.ClaimDueAnalysisParams{
+		LeaseUntil:  pgtype.Timestamptz{Time: leaseUntil, Valid: true},
+		MaxAttempts: int32(maxAttempts),
+		Batch:       int32(batch),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ClaimedAnalysis, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, ClaimedAnalysis{GameRef: r.GameRef, Attempts: int(r.Attempts)})
+	}
+	return out, nil
+}
+
+// AnalysisStart returns the starting OFEN and outcome of the game to review.
+func AnalysisStart(gameRef int32) (ofen, outcome string, err error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetAnalysisStart(ctx, gameRef)
+	if err != nil {
+		return "", "", err
+	}
+	return row.StartingOfen, row.Outcome, nil
+}
+
+// SaveAnalysis records a finished review.
+func SaveAnalysis(gameRef int32, a GameAnalysis) error {
+	ctx, cancel := Ctx()
+	defer cancel()
+	depth := int16(a.Depth)
+	return gen.New(Pool).SaveAnalysis(ctx, gen.SaveAnalysisParams{
+		GameRef:       gameRef,
+		Depth:         &depth,
+		Evals:         a.Evals,
+		Judgements:    a.Judgements,
+		WhiteAccuracy: &a.White.Accuracy,
+		BlackAccuracy: &a.Black.Accuracy,
+		WhiteAcpl:     &a.White.ACPL,
+		BlackAcpl:     &a.Black.ACPL,
+	})
+}
+
+// MarkAnalysisFailed records a failed attempt and when to try again.
+func MarkAnalysisFailed(gameRef int32, reason string, next time.Time) error {
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).MarkAnalysisFailed(ctx, gen.MarkAnalysisFailedParams{
+		GameRef:       gameRef,
+		LastError:     reason,
+		NextAttemptAt: pgtype.Timestamptz{Time: next, Valid: true},
+	})
+}
+
+// GetGameAnalysis returns a game's review, nil when it was never requested
+// (or Postgres is unconfigured). Failed is judged against maxAttempts.
+func GetGameAnalysis(gameRef int32, maxAttempts int) (*GameAnalysis, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetGameAnalysis(ctx, gameRef)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	a := &GameAnalysis{
+		Done:       row.CompletedAt.Valid,
+		Failed:     !row.CompletedAt.Valid && int(row.Attempts) >= maxAttempts,
+		Evals:      row.Evals,
+		Judgements: row.Judgements,
+	}
+	if row.Depth != nil {
+		a.Depth = int(*row.Depth)
+	}
+	if row.WhiteAccuracy != nil {
+		a.White.Accuracy = *row.WhiteAccuracy
+	}
+	if row.BlackAccuracy != nil {
+		a.Black.Accuracy = *row.BlackAccuracy
+	}
+	if row.WhiteAcpl != nil {
+		a.White.ACPL = *row.WhiteAcpl
+	}
+	if row.BlackAcpl != nil {
+		a.Black.ACPL = *row.BlackAcpl
+	}
+	return a, nil
+}
+
+// AccuracyForUser returns an account's accuracy across its reviewed games.
+func AccuracyForUser(userID int64) (Accuracy, error) {
+	if Pool == nil {
+		return Accuracy{}, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).AccuracyForUser(ctx, &userID)
+	if err != nil {
+		return Accuracy{}, err
+	}
+	return Accuracy{Games: row.Games, Accuracy: row.Accuracy}, nil
+}
+
+// CachePositionEval stores a searched eval the review computed in the
+// positions cache, for a position the background evaluator has not reached:
+// both search at the same depth, so the review's number is the one the
+// evaluator would have stored. It overwrites, so the caller only writes a
+// position it found uncached.
+func CachePositionEval(positionID int32, cp int16, depth int, best *int16) error {
+	ctx, cancel := Ctx()
+	defer cancel()
+	d := int16(depth)
+	return gen.New(Pool).SetPositionEval(ctx, gen.SetPositionEvalParams{
+		ID:        positionID,
+		EvalCp:    &cp,
+		EvalDepth: &d,
+		BestMove:  best,
+	})
+}
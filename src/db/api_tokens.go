@@ -0,0 +1,131 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dechristopher/lio/db/gen"
+	"github.com/dechristopher/lio/role"
+	"github.com/dechristopher/lio/title"
+)
+
+// Personal API tokens (auth/tokens.go). Reached only behind auth.Enabled(),
+// like the rest of the accounts data plane.
+
+// APIToken is one token as its owner's list shows it. The hash never leaves
+// the table.
+type APIToken struct {
+	ID      int64
+	Name    string
+	Scopes  []string
+	Created time.Time
+	// LastUsed is the zero time for a token never presented. It is touched at
+	// most once per auth.tokenTouchInterval, so it is approximate by that much.
+	LastUsed time.Time
+}
+
+// APITokenIdentity is a presented token resolved to its account: what the auth
+// package builds the request identity from, as SessionRecord is for a cookie.
+type APITokenIdentity struct {
+	ID       int64
+	UserID   int64
+	Name     string
+	Scopes   []string
+	UID      string
+	Username string
+	Title    title.Title
+	Role     role.Role
+	Banned   bool
+}
+
+// CreateAPIToken stores a new token's hash and returns its id.
+func CreateAPIToken(userID int64, name string, tokenHash []byte, scopes []string, uid string) (int64, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).CreateAPIToken(ctx, gen.CreateAPITokenParams{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		Uid:       uid,
+	})
+}
+
+// CountAPITokens counts an account's tokens, for the per-account cap.
+func CountAPITokens(userID int64) (int64, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).CountAPITokens(ctx, userID)
+}
+
+// ListAPITokens returns an account's tokens, newest first.
+func ListAPITokens(userID int64) ([]APIToken, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListAPITokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]APIToken, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, APIToken{
+			ID:       r.ID,
+			Name:     r.Name,
+			Scopes:   r.Scopes,
+			Created:  r.CreatedAt.Time,
+			LastUsed: r.LastUsedAt.Time,
+		})
+	}
+	return out, nil
+}
+
+// DeleteAPIToken revokes one of an account's tokens and returns its name.
+// found is false when the id is not one of this account's.
+func DeleteAPIToken(id, userID int64) (name string, found bool, err error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	name, err = gen.New(Pool).DeleteAPIToken(ctx, gen.DeleteAPITokenParams{
+		ID: id, UserID: userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return name, true, nil
+}
+
+// GetAPITokenByHash resolves a presented token's hash to its account.
+// Returns found=false on a miss.
+func GetAPITokenByHash(tokenHash []byte) (APITokenIdentity, bool, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetAPITokenByHash(ctx, tokenHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return APITokenIdentity{}, false, nil
+	}
+	if err != nil {
+		return APITokenIdentity{}, false, err
+	}
+	return APITokenIdentity{
+		ID:       row.ID,
+		UserID:   row.UserID,
+		Name:     row.Name,
+		Scopes:   row.Scopes,
+		UID:      row.Uid,
+		Username: row.Username,
+		Title:    title.New(row.TitleCode, row.TitleName),
+		Role:     role.Parse(row.Role),
+		Banned:   banFrom(row.BannedUntil, nil).Banned,
+	}, true, nil
+}
+
+// TouchAPIToken records that a token was just used.
+func TouchAPIToken(id int64) error {
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).TouchAPIToken(ctx, id)
+}
@@ -51,6 +51,15 @@ type GameRecord struct {
 	// the display layer resolves NULL to the Queen.
 	BotPersona string
 
+	// TournamentID references the tournaments row of a tournament game; ""
+	// (archived as NULL) for every other game. TournamentName is the event's
+	// name as the PGN Event tag prints it.
+	TournamentID   string
+	TournamentName string
+	// SimulID references the simuls row of a simul board, the reference every
+	// board of one exhibition shares; "" (NULL) for every other game.
+	SimulID string
+
 	// game-level (filled from the finished game copy in storeGame)
 	GameID       string
 	StartTs      time.Time
@@ -133,6 +142,7 @@ func BuildPlies(g *octad.Game, times []game.MoveTime) ([]byte, []PlyRecord) {
 // both players' ratings — the room broadcasts it so the game-over popup shows
 // each side's delta and the clocks refresh for a rematch.
 func ArchiveGame(ctx context.Context, rec GameRecord, plies []PlyRecord) (*RatingResult, error) {
+	start := time.Now()
 	_, res, err := archiveGame(ctx, rec, plies, false)
 	// only the live seam is counted for the console's archive health: the
 	// backfill (ArchiveGameIfNew) reports its own progress, and folding a
@@ -140,6 +150,12 @@ func ArchiveGame(ctx context.Context, rec GameRecord, plies []PlyRecord) (*Ratin
 	// the signal the panel exists to show
 	if Pool != nil {
 		noteArchive(err)
+		if err == nil {
+			archiveCommit.Since(start)
+		}
+	}
+	if res != nil {
+		ratingUpdates.Inc()
 	}
 	return res, err
 }
@@ -241,6 +257,12 @@ func archiveGame(ctx context.Context, rec GameRecord, plies []PlyRecord, ifNew b
 	if rec.BotPersona != "" {
 		params.BotPersona = &rec.BotPersona
 	}
+	if rec.TournamentID != "" {
+		params.TournamentID = &rec.TournamentID
+	}
+	if rec.SimulID != "" {
+		params.SimulID = &rec.SimulID
+	}
 	// Only a rated game's category is meaningful. Stamping it on unrated rows
 	// would put a category on games that never touched a rating, which is
 	// exactly what the rating curve's `WHERE rated AND rating_category IS NOT
@@ -312,6 +334,17 @@ func archiveGame(ctx context.Context, rec GameRecord, plies []PlyRecord, ifNew b
 		}
 	}
 
+	// automatic review (package analysis): queued with the game, so a game is
+	// never archived without its review request or the other way round. Only
+	// the live seam queues — a backfill would bury the queue under history.
+	// Fair play queues the games it samples whether or not every game is.
+	review := autoAnalysis.Load() || (fairPlay.Load() && fairPlayGame(rec))
+	if !ifNew && review && len(plies) > 0 {
+		if _, err := q.EnqueueAnalysis(ctx, gen.EnqueueAnalysisParams{GameRef: gameRef}); err != nil {
+			return false, nil, err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return false, nil, err
 	}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dechristopher/lio/db/gen"
+	"github.com/dechristopher/lio/title"
+)
+
+// Blocks: one account refusing another. A block is written by one side but
+// binds both: neither account may challenge the other, follow the other, or
+// take a seat in a room the other is sitting in, and each side's chat lines
+// are hidden from the other. The direction decides only who may lift it.
+//
+// Enforcement is spread over the places that act on a pair of accounts — the
+// challenge paths in www/handlers, room.Instance.Join, the correspondence
+// package, Follow here, and chat — and every one of them asks Blocked, the
+// symmetric question. Like the follow accessors these degrade quietly: an
+// unconfigured Postgres reports that nobody has blocked anybody.
+
+// MaxBlocks caps how many accounts one account may block. An abuse bound like
+// MaxFollowing, for the same reason: it bounds one account's rows.
+const MaxBlocks = 1000
+
+var (
+	// ErrBlockLimit is returned by Block when the blocker is already at
+	// MaxBlocks.
+	ErrBlockLimit = errors.New("block limit reached")
+	// ErrBlocked is returned by Follow when either account has blocked the
+	// other.
+	ErrBlocked = errors.New("blocked")
+)
+
+// BlockedAccount is one row of a block list: whom, and since when.
+type BlockedAccount struct {
+	ID       int64
+	Username string
+	Title    title.Title
+	Since    time.Time
+}
+
+// Block records that blockerID blocks blockedID, and reports whether that
+// created a new block. The follow edges between the two go in the same
+// transaction, both directions: a block that left the blocked account
+// following along would not have blocked much.
+//
+// As with Follow, the cap is checked ahead of the insert rather than with it,
+// so a deliberate race can overshoot it by a row.
+func Block(blockerID, blockedID int64) (created bool, err error) {
+	if Pool == nil || blockerID == blockedID || blockerID == 0 || blockedID == 0 {
+		return false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	tx, err := Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // no-op once Commit succeeds
+	q := gen.New(tx)
+
+	n, err := q.CountBlocks(ctx, blockerID)
+	if err != nil {
+		return false, err
+	}
+	if n >= MaxBlocks {
+		return false, ErrBlockLimit
+	}
+	rows, err := q.Block(ctx, gen.BlockParams{BlockerID: blockerID, BlockedID: blockedID})
+	if err != nil {
+		return false, err
+	}
+	if _, err = q.DeleteFollowsBetween(ctx, gen.DeleteFollowsBetweenParams{
+		UserA: blockerID, UserB: blockedID,
+	}); err != nil {
+		return false, err
+	}
+	return rows > 0, tx.Commit(ctx)
+}
+
+// Unblock lifts blockerID's block of blockedID, and reports whether there was
+// one to lift. The follows the block removed are not restored.
+func Unblock(blockerID, blockedID int64) (removed bool, err error) {
+	if Pool == nil {
+		return false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).Unblock(ctx, gen.UnblockParams{
+		BlockerID: blockerID, BlockedID: blockedID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// Blocked reports whether either account has blocked the other. A zero id (an
+// anonymous session) is never blocked: a block names an account.
+//
+// Unlike IsBlocking this returns its error. Its callers are refusing something
+// on the answer, and each decides for itself which way a failed read falls.
+func Blocked(a, b int64) (bool, error) {
+	if Pool == nil || a == 0 || b == 0 || a == b {
+		return false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).IsBlockedEither(ctx, gen.IsBlockedEitherParams{UserA: a, UserB: b})
+}
+
+// IsBlocking reports whether blockerID has blocked blockedID. It swallows its
+// error for the reason IsFollowing does: it only labels a button.
+func IsBlocking(blockerID, blockedID int64) bool {
+	if Pool == nil || blockerID == 0 || blockedID == 0 || blockerID == blockedID {
+		return false
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	blocking, err := gen.New(Pool).IsBlocking(ctx, gen.IsBlockingParams{
+		BlockerID: blockerID, BlockedID: blockedID,
+	})
+	if err != nil {
+		return false
+	}
+	return blocking
+}
+
+// BlockedAmong returns which of ids share a block with userID, in either
+// direction, as a set. Bounded by len(ids), like FollowedAmong. A zero user or
+// an empty id set returns an empty set without a query.
+func BlockedAmong(userID int64, ids []int64) (map[int64]struct{}, error) {
+	out := make(map[int64]struct{})
+	if Pool == nil || userID == 0 || len(ids) == 0 {
+		return out, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).BlockedAmong(ctx, gen.BlockedAmongParams{UserID: userID, Ids: ids})
+	if err != nil {
+		return out, err
+	}
+	for _, id := range rows {
+		out[id] = struct{}{}
+	}
+	return out, nil
+}
+
+// BlockPair is two accounts, smaller id first, so a block reads the same
+// whichever of them made it.
+type BlockPair [2]int64
+
+// PairOf is the BlockPair of two accounts.
+func PairOf(a, b int64) BlockPair {
+	if b < a {
+		a, b = b, a
+	}
+	return BlockPair{a, b}
+}
+
+// BlockedPairs returns every pair of ids that shares a block, in either
+// direction, in one query. Pairing code (matchmaking, tournament) asks it
+// about a whole field and never pairs what it returns. Fewer than two ids
+// return an empty set without a query.
+func BlockedPairs(ids []int64) (map[BlockPair]bool, error) {
+	out := make(map[BlockPair]bool)
+	if Pool == nil || len(ids) < 2 {
+		return out, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).BlocksWithin(ctx, ids)
+	if err != nil {
+		return out, err
+	}
+	for _, r := range rows {
+		out[PairOf(r.BlockerID, r.BlockedID)] = true
+	}
+	return out, nil
+}
+
+// ListBlocks returns the accounts userID has blocked, newest first. MaxBlocks
+// bounds it, so it is read whole.
+func ListBlocks(userID int64) ([]BlockedAccount, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListBlocks(ctx, gen.ListBlocksParams{
+		BlockerID: userID, Limit: MaxBlocks,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]BlockedAccount, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, BlockedAccount{
+			ID:       r.ID,
+			Username: r.Username,
+			Title:    title.New(r.TitleCode, r.TitleName),
+			Since:    r.CreatedAt.Time,
+		})
+	}
+	return out, nil
+}
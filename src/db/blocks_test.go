@@ -0,0 +1,73 @@
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestBlockRemovesFollowsAndBindsBothWays covers what a block is: it reports
+// whether it was new, takes both follow edges with it, refuses a follow in
+// either direction while it stands, and is lifted only by its own blocker.
+func TestBlockRemovesFollowsAndBindsBothWays(t *testing.T) {
+	skipNoDB(t)
+
+	a, b := mkFollowUser(t, "bka"), mkFollowUser(t, "bkb")
+	if _, err := Follow(a, b); err != nil {
+		t.Fatalf("follow a->b: %v", err)
+	}
+	if _, err := Follow(b, a); err != nil {
+		t.Fatalf("follow b->a: %v", err)
+	}
+
+	created, err := Block(a, b)
+	if err != nil || !created {
+		t.Fatalf("block: created = %v, err = %v", created, err)
+	}
+	if created, _ = Block(a, b); created {
+		t.Fatal("repeat block reported a new block")
+	}
+	if IsFollowing(a, b) || IsFollowing(b, a) {
+		t.Fatal("a block left a follow edge standing")
+	}
+	if _, err := Follow(b, a); !errors.Is(err, ErrBlocked) {
+		t.Fatalf("follow by the blocked account: err = %v, want ErrBlocked", err)
+	}
+	if _, err := Follow(a, b); !errors.Is(err, ErrBlocked) {
+		t.Fatalf("follow by the blocker: err = %v, want ErrBlocked", err)
+	}
+
+	for _, pair := range [][2]int64{{a, b}, {b, a}} {
+		if blocked, err := Blocked(pair[0], pair[1]); err != nil || !blocked {
+			t.Fatalf("Blocked(%d, %d) = %v, %v", pair[0], pair[1], blocked, err)
+		}
+	}
+	if !IsBlocking(a, b) || IsBlocking(b, a) {
+		t.Fatal("IsBlocking is not directional")
+	}
+	among, err := BlockedAmong(b, []int64{a})
+	if err != nil {
+		t.Fatalf("blocked among: %v", err)
+	}
+	if _, ok := among[a]; !ok {
+		t.Fatal("BlockedAmong missed the blocker from the blocked side")
+	}
+	list, err := ListBlocks(a)
+	if err != nil || len(list) != 1 || list[0].ID != b {
+		t.Fatalf("list = %+v, err = %v", list, err)
+	}
+
+	// the blocked account cannot lift somebody else's block
+	if removed, _ := Unblock(b, a); removed {
+		t.Fatal("the blocked account lifted the block")
+	}
+	if removed, err := Unblock(a, b); err != nil || !removed {
+		t.Fatalf("unblock: removed = %v, err = %v", removed, err)
+	}
+	if blocked, _ := Blocked(a, b); blocked {
+		t.Fatal("unblock did not take")
+	}
+	// the follows the block removed stay removed
+	if IsFollowing(a, b) || IsFollowing(b, a) {
+		t.Fatal("unblock restored a follow")
+	}
+}
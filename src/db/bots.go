@@ -0,0 +1,148 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dechristopher/lio/db/gen"
+	"github.com/dechristopher/lio/role"
+	"github.com/dechristopher/lio/title"
+)
+
+// Bot accounts (www/handlers/handle_bot.go): accounts an admin has flagged
+// for an external engine to play through. Like the rest of the accounts data
+// plane, the writes are only reached behind auth.Enabled(); the reads degrade
+// to "not a bot" without a pool, since a PG-less instance has no accounts to
+// flag.
+
+// BotAccount is one bot_accounts row.
+type BotAccount struct {
+	UserID int64
+	// UID is the seat identity every one of the bot's connections takes.
+	UID string
+	// TokenIssued is when the current token was issued, zero when there is
+	// none yet.
+	TokenIssued time.Time
+}
+
+// BotIdentity is a bearer token resolved to its bot: the account fields the
+// auth package builds the request identity from, as SessionRecord is for a
+// cookie.
+type BotIdentity struct {
+	UserID   int64
+	UID      string
+	Username string
+	Title    title.Title
+	Role     role.Role
+	Banned   bool
+}
+
+// FlagBot makes an account a bot, under the given seat uid, and gives it the
+// BOT title. Flagging a bot again changes nothing but the title.
+func FlagBot(userID int64, uid string) error {
+	ctx, cancel := Ctx()
+	defer cancel()
+	tx, err := Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // no-op once Commit succeeds
+	q := gen.New(tx)
+
+	if err = q.CreateBotAccount(ctx, gen.CreateBotAccountParams{
+		UserID: userID, Uid: uid,
+	}); err != nil {
+		return err
+	}
+	if err = q.SetBotTitle(ctx, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UnflagBot returns a bot to an ordinary account: its token stops resolving
+// and the BOT title comes off. Reports whether it was a bot at all.
+func UnflagBot(userID int64) (bool, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	tx, err := Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // no-op once Commit succeeds
+	q := gen.New(tx)
+
+	n, err := q.DeleteBotAccount(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if err = q.ClearBotTitle(ctx, userID); err != nil {
+		return false, err
+	}
+	return n > 0, tx.Commit(ctx)
+}
+
+// GetBotAccount fetches an account's bot row. found is false for every
+// account that is not a bot, and always without a pool.
+func GetBotAccount(userID int64) (BotAccount, bool, error) {
+	if Pool == nil {
+		return BotAccount{}, false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetBotAccount(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return BotAccount{}, false, nil
+	}
+	if err != nil {
+		return BotAccount{}, false, err
+	}
+	return BotAccount{
+		UserID:      row.UserID,
+		UID:         row.Uid,
+		TokenIssued: row.TokenCreatedAt.Time,
+	}, true, nil
+}
+
+// IsBot reports whether an account is a bot. A failed lookup answers false:
+// the callers decide which rating pool a game plays for, and the human pool
+// is the one every game used before bots existed.
+func IsBot(userID int64) bool {
+	_, found, err := GetBotAccount(userID)
+	return err == nil && found
+}
+
+// SetBotToken stores the hash of a newly issued token, replacing (and so
+// revoking) the last. found is false when the account is not a bot.
+func SetBotToken(userID int64, tokenHash []byte) (bool, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	n, err := gen.New(Pool).SetBotToken(ctx, gen.SetBotTokenParams{
+		UserID: userID, TokenHash: tokenHash,
+	})
+	return n > 0, err
+}
+
+// GetBotByTokenHash resolves a presented bearer token's hash to its bot.
+// Returns found=false on a miss.
+func GetBotByTokenHash(tokenHash []byte) (BotIdentity, bool, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetBotByTokenHash(ctx, tokenHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return BotIdentity{}, false, nil
+	}
+	if err != nil {
+		return BotIdentity{}, false, err
+	}
+	return BotIdentity{
+		UserID:   row.UserID,
+		UID:      row.Uid,
+		Username: row.Username,
+		Title:    title.New(row.TitleCode, row.TitleName),
+		Role:     role.Parse(row.Role),
+		Banned:   banFrom(row.BannedUntil, nil).Banned,
+	}, true, nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/dechristopher/lio/db/gen"
+)
+
+// In-game chat (the chat package). The log is written as lines are said and is
+// the moderator's record of a room: a report naming one of the room's games
+// links here through the game's room.
+
+// ChatLine is one line of a room's chat.
+type ChatLine struct {
+	RoomID string
+	// Channel is "player" or "spectator".
+	Channel string
+	// UserID is the speaker's account, nil for an anonymous player.
+	UserID   *int64
+	UID      string
+	Username string
+	Body     string
+	// Filtered marks a line the word filter withheld: logged, never delivered.
+	Filtered bool
+	Created  time.Time
+}
+
+// SaveChatLine appends one line to a room's log.
+func SaveChatLine(l ChatLine) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).InsertChatMessage(ctx, gen.InsertChatMessageParams{
+		RoomID:    l.RoomID,
+		Channel:   l.Channel,
+		UserID:    l.UserID,
+		Uid:       l.UID,
+		Username:  l.Username,
+		Body:      l.Body,
+		Filtered:  l.Filtered,
+		CreatedAt: pgtype.Timestamptz{Time: l.Created, Valid: true},
+	})
+}
+
+// RecentChat returns the last limit delivered lines of one channel of a room,
+// oldest first.
+func RecentChat(roomID, channel string, limit int32) ([]ChatLine, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListRecentChat(ctx, gen.ListRecentChatParams{
+		RoomID: roomID, Channel: channel, Limit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ChatLine, len(rows))
+	for i, r := range rows {
+		// the query reads newest first to take the tail; hand it back in the
+		// order it was said
+		out[len(rows)-1-i] = chatLineFrom(r)
+	}
+	return out, nil
+}
+
+// RoomChat returns a room's whole log, both channels and withheld lines
+// included, oldest first.
+func RoomChat(roomID string, limit int32) ([]ChatLine, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListRoomChat(ctx, gen.ListRoomChatParams{
+		RoomID: roomID, Limit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ChatLine, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, chatLineFrom(r))
+	}
+	return out, nil
+}
+
+func chatLineFrom(r gen.ChatMessage) ChatLine {
+	return ChatLine{
+		RoomID:   r.RoomID,
+		Channel:  r.Channel,
+		UserID:   r.UserID,
+		UID:      r.Uid,
+		Username: r.Username,
+		Body:     r.Body,
+		Filtered: r.Filtered,
+		Created:  r.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,281 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dechristopher/lio/db/gen"
+)
+
+// Correspondence games (the correspondence package). Unlike a room, whose game
+// lives in its actor's memory, a correspondence game lives here: the row is the
+// whole game, and every action is one locked read-modify-write of it (see
+// UpdateCorrespondenceGame). Without Postgres there is nowhere for such a game
+// to live, so the package refuses to create one; these accessors degrade the
+// usual quiet way underneath it.
+
+// The correspondence_games.status values.
+const (
+	// CorrespondenceOpen is a game waiting for its second player.
+	CorrespondenceOpen = "open"
+	// CorrespondenceActive is a game in progress, with a deadline.
+	CorrespondenceActive = "active"
+	// CorrespondenceFinished is a decided game, archived to games.
+	CorrespondenceFinished = "finished"
+	// CorrespondenceAborted is a game that never counted: a seek its creator
+	// withdrew, a challenge declined, or a game that ran out of time before
+	// both sides had moved. Nothing of it is archived.
+	CorrespondenceAborted = "aborted"
+)
+
+// CorrespondenceGame is one correspondence_games row.
+type CorrespondenceGame struct {
+	ID string
+	// Variant is the pools.Map key the game is played at.
+	Variant string
+	Rated   bool
+	Creator int64
+	// White and Black are the seats' accounts; the one the creator did not
+	// take is nil while the game is open. Invited is a direct challenge's
+	// recipient, nil for a seek.
+	White   *int64
+	Black   *int64
+	Invited *int64
+	// The session uids the game was created and its seats taken with.
+	CreatorUID string
+	WhiteUID   string
+	BlackUID   string
+	Status     string
+	// GameID is the archived game's id, fixed at creation.
+	GameID       string
+	StartingOFEN string
+	// Moves is the game so far, UOI.
+	Moves []string
+	// DrawOffer is the side with a standing offer: "w", "b", or "".
+	DrawOffer string
+	// Deadline is when the side to move loses on time; zero unless active.
+	Deadline time.Time
+	Outcome  string
+	Reason   string
+	Created  time.Time
+	Started  time.Time
+	LastMove time.Time
+	Finished time.Time
+}
+
+// InsertCorrespondenceGame records a newly created game.
+func InsertCorrespondenceGame(g CorrespondenceGame) error {
+	if Pool == nil {
+		return nil
+	}
+	gameID, err := uuid.Parse(g.GameID)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).InsertCorrespondenceGame(ctx, gen.InsertCorrespondenceGameParams{
+		ID:            g.ID,
+		Variant:       g.Variant,
+		Rated:         g.Rated,
+		CreatorUserID: g.Creator,
+		WhiteUserID:   g.White,
+		BlackUserID:   g.Black,
+		InvitedUserID: g.Invited,
+		CreatorUid:    g.CreatorUID,
+		WhiteUid:      g.WhiteUID,
+		BlackUid:      g.BlackUID,
+		GameID:        gameID,
+		StartingOfen:  g.StartingOFEN,
+	})
+}
+
+// GetCorrespondenceGame reads one game; ok is false when there is no such game.
+func GetCorrespondenceGame(id string) (CorrespondenceGame, bool, error) {
+	if Pool == nil {
+		return CorrespondenceGame{}, false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetCorrespondenceGame(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CorrespondenceGame{}, false, nil
+		}
+		return CorrespondenceGame{}, false, err
+	}
+	return correspondenceGame(row), true, nil
+}
+
+// UpdateCorrespondenceGame applies one action to a game under its row lock and
+// writes the result back, so concurrent actions on one game serialize: a move
+// and the scheduler's flag can never both land on the same position. apply sees
+// the game as it stands and edits it in place; an error from it rolls the whole
+// action back and is returned as is. ok is false when there is no such game.
+func UpdateCorrespondenceGame(id string, apply func(*CorrespondenceGame) error) (CorrespondenceGame, bool, error) {
+	if Pool == nil {
+		return CorrespondenceGame{}, false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+
+	tx, err := Pool.Begin(ctx)
+	if err != nil {
+		return CorrespondenceGame{}, false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // no-op once Commit succeeds
+	q := gen.New(tx)
+
+	row, err := q.GetCorrespondenceGameForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CorrespondenceGame{}, false, nil
+		}
+		return CorrespondenceGame{}, false, err
+	}
+	g := correspondenceGame(row)
+	if err := apply(&g); err != nil {
+		return CorrespondenceGame{}, true, err
+	}
+
+	var drawOffer *string
+	if g.DrawOffer != "" {
+		drawOffer = &g.DrawOffer
+	}
+	moves := g.Moves
+	if moves == nil {
+		moves = []string{}
+	}
+	if err := q.UpdateCorrespondenceGame(ctx, gen.UpdateCorrespondenceGameParams{
+		ID:          g.ID,
+		WhiteUserID: g.White,
+		BlackUserID: g.Black,
+		WhiteUid:    g.WhiteUID,
+		BlackUid:    g.BlackUID,
+		Status:      g.Status,
+		Moves:       moves,
+		DrawOffer:   drawOffer,
+		Deadline:    optionalTs(g.Deadline),
+		Outcome:     g.Outcome,
+		Reason:      g.Reason,
+		StartedAt:   optionalTs(g.Started),
+		LastMoveAt:  optionalTs(g.LastMove),
+		FinishedAt:  optionalTs(g.Finished),
+	}); err != nil {
+		return CorrespondenceGame{}, true, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return CorrespondenceGame{}, true, err
+	}
+	return g, true, nil
+}
+
+// CorrespondenceGamesFor lists a player's unfinished games and the challenges
+// waiting on them, the most pressing deadline first.
+func CorrespondenceGamesFor(userID int64) ([]CorrespondenceGame, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListCorrespondenceGamesForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return correspondenceGames(rows), nil
+}
+
+// ActiveCorrespondenceCount counts the correspondence games userID is seated
+// in and still playing.
+func ActiveCorrespondenceCount(userID int64) (int64, error) {
+	if Pool == nil {
+		return 0, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).CountActiveCorrespondenceForUser(ctx, userID)
+}
+
+// FinishedCorrespondenceGamesFor lists a player's most recently finished games.
+func FinishedCorrespondenceGamesFor(userID int64, limit int) ([]CorrespondenceGame, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListFinishedCorrespondenceGamesForUser(ctx,
+		gen.ListFinishedCorrespondenceGamesForUserParams{UserID: userID, MaxRows: int32(limit)})
+	if err != nil {
+		return nil, err
+	}
+	return correspondenceGames(rows), nil
+}
+
+// OpenCorrespondenceSeeks lists the seeks anybody may accept, newest first.
+func OpenCorrespondenceSeeks(limit int) ([]CorrespondenceGame, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListOpenCorrespondenceSeeks(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	return correspondenceGames(rows), nil
+}
+
+// DueCorrespondenceGames lists up to limit active games whose deadline had
+// passed by now, the longest overdue first.
+func DueCorrespondenceGames(now time.Time, limit int) ([]string, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).ListDueCorrespondenceGames(ctx, gen.ListDueCorrespondenceGamesParams{
+		Deadline: ts(now),
+		Limit:    int32(limit),
+	})
+}
+
+func correspondenceGames(rows []gen.CorrespondenceGame) []CorrespondenceGame {
+	out := make([]CorrespondenceGame, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, correspondenceGame(r))
+	}
+	return out
+}
+
+func correspondenceGame(r gen.CorrespondenceGame) CorrespondenceGame {
+	g := CorrespondenceGame{
+		ID:           r.ID,
+		Variant:      r.Variant,
+		Rated:        r.Rated,
+		Creator:      r.CreatorUserID,
+		White:        r.WhiteUserID,
+		Black:        r.BlackUserID,
+		Invited:      r.InvitedUserID,
+		CreatorUID:   r.CreatorUid,
+		WhiteUID:     r.WhiteUid,
+		BlackUID:     r.BlackUid,
+		Status:       r.Status,
+		GameID:       r.GameID.String(),
+		StartingOFEN: r.StartingOfen,
+		Moves:        r.Moves,
+		Deadline:     r.Deadline.Time,
+		Outcome:      r.Outcome,
+		Reason:       r.Reason,
+		Created:      r.CreatedAt.Time,
+		Started:      r.StartedAt.Time,
+		LastMove:     r.LastMoveAt.Time,
+		Finished:     r.FinishedAt.Time,
+	}
+	if r.DrawOffer != nil {
+		g.DrawOffer = *r.DrawOffer
+	}
+	return g
+}
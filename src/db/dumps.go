@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dechristopher/lio/db/gen"
+)
+
+// Catalogue reads and writes for the monthly PGN database dumps (the dump
+// package builds the files; pgn_dumps records what is published). Like the
+// other archive accessors these degrade quietly: without Postgres there is
+// nothing to dump and nothing to list.
+
+// Dump is one published monthly dump, as listed on the /db page.
+type Dump struct {
+	// Month is the UTC calendar month the dump covers, "YYYY-MM".
+	Month     string
+	ObjectKey string
+	Games     int64
+	// Bytes is the compressed download size; RawBytes what it unpacks to.
+	Bytes    int64
+	RawBytes int64
+	BuiltAt  time.Time
+}
+
+// MonthCount is one month's archived game count, by start_ts in UTC.
+type MonthCount struct {
+	Month string
+	Games int64
+}
+
+// GamesByMonth returns the archived game count of every month that has games,
+// oldest first. Empty when Postgres is unconfigured.
+func GamesByMonth() ([]MonthCount, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).CountGamesByMonth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	months := make([]MonthCount, 0, len(rows))
+	for _, r := range rows {
+		months = append(months, MonthCount{Month: r.Month, Games: r.Games})
+	}
+	return months, nil
+}
+
+// DumpGamesPage returns up to limit of the games started in [from, to), in
+// (start_ts, id) order, strictly after the cursor (afterTs, afterID). Pass the
+// zero time and 0 for the first page, then the last row's start_ts and id.
+// The caller's context bounds the page; a month dump is a long job, so it does
+// not borrow the per-request opTimeout for the whole month.
+func DumpGamesPage(ctx context.Context, from, to, afterTs time.Time, afterID int32, limit int32) ([]gen.Game, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	return gen.New(Pool).ListDumpGames(ctx, gen.ListDumpGamesParams{
+		FromTs:    ts(from),
+		ToTs:      ts(to),
+		AfterTs:   ts(afterTs),
+		AfterID:   afterID,
+		BatchSize: limit,
+	})
+}
+
+// RecordDump publishes (or republishes) a month's dump in the catalogue.
+func RecordDump(d Dump) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).UpsertPgnDump(ctx, gen.UpsertPgnDumpParams{
+		Month:     d.Month,
+		ObjectKey: d.ObjectKey,
+		Games:     d.Games,
+		Bytes:     d.Bytes,
+		RawBytes:  d.RawBytes,
+	})
+}
+
+// ListDumps returns every published dump, newest month first.
+func ListDumps() ([]Dump, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListPgnDumps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dumps := make([]Dump, 0, len(rows))
+	for _, r := range rows {
+		dumps = append(dumps, dumpFromRow(r))
+	}
+	return dumps, nil
+}
+
+// GetDump looks up one month's published dump, found=false when the month has
+// none (or Postgres is unconfigured).
+func GetDump(month string) (Dump, bool, error) {
+	if Pool == nil {
+		return Dump{}, false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetPgnDump(ctx, month)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Dump{}, false, nil
+	}
+	if err != nil {
+		return Dump{}, false, err
+	}
+	return dumpFromRow(row), true, nil
+}
+
+// dumpFromRow converts a generated catalogue row.
+func dumpFromRow(r gen.PgnDump) Dump {
+	return Dump{
+		Month:     r.Month,
+		ObjectKey: r.ObjectKey,
+		Games:     r.Games,
+		Bytes:     r.Bytes,
+		RawBytes:  r.RawBytes,
+		BuiltAt:   r.BuiltAt.Time,
+	}
+}
@@ -3,11 +3,14 @@ package db
 import (
 	"time"
 
+	"github.com/dechristopher/octad/v2"
+
 	"github.com/dechristopher/lio/config"
 	"github.com/dechristopher/lio/db/gen"
-	"github.com/dechristopher/lio/engine"
+	"github.com/dechristopher/lio/dispatch"
 	"github.com/dechristopher/lio/game"
 	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/tablebase"
 	"github.com/dechristopher/lio/util"
 )
 
@@ -72,10 +75,23 @@ func evalBatchOnce() {
 		// Search rebuilds a fresh game from the bare OFEN, so passing the stored
 		// position string is safe for the parallel root (nil history disables
 		// repetition scoring — a standalone position has no game line).
-		me := engine.Search(row.Ofen, nil, evalDepth, evalBudget, engine.MinimaxAB)
+		me := dispatch.Search(dispatch.SearchRequest{
+			Kind:   "eval",
+			OFEN:   row.Ofen,
+			Depth:  evalDepth,
+			Budget: evalBudget,
+		})
 		cp := clampEval(me.Eval * evalCentiUnit)
 		depth := int16(evalDepth)
 
+		// a solved endgame stores the tablebase's exact result over the
+		// search's estimate. The search's move needs no override: it probes the
+		// tablebase at every node, so it already plays the fastest mate.
+		exactCp, mate, exact := exactEval(row.Ofen)
+		if exact {
+			cp = exactCp
+		}
+
 		// a terminal position (mate/stalemate) has no best move: Search returns
 		// the zero move (the "a1a1" idiom, see engine.bestOf) — store the exact
 		// terminal eval and leave best_move NULL rather than packing a non-move
@@ -91,6 +107,8 @@ func evalBatchOnce() {
 			EvalCp:    &cp,
 			EvalDepth: &depth,
 			BestMove:  bestPtr,
+			EvalMate:  mate,
+			EvalExact: exact,
 		})
 		setCancel()
 		if err != nil {
@@ -99,6 +117,33 @@ func evalBatchOnce() {
 	}
 }
 
+// exactEval probes the endgame tablebase for ofen, returning its white-positive
+// centipawns (±evalCap for a forced mate, 0 for a draw) and the white-positive
+// moves to mate (nil for a draw, or a side already mated).
+func exactEval(ofen string) (int16, *int16, bool) {
+	o, err := octad.OFEN(ofen)
+	if err != nil {
+		return 0, nil, false
+	}
+	g, err := octad.NewGame(o)
+	if err != nil {
+		return 0, nil, false
+	}
+	r, ok := tablebase.Probe(g.Position())
+	if !ok {
+		return 0, nil, false
+	}
+	r = r.White(g.Position().Turn())
+	if r.WDL == tablebase.Draw {
+		return 0, nil, true
+	}
+	var mate *int16
+	if m := int16(r.MateIn()); m != 0 {
+		mate = &m
+	}
+	return evalCap * int16(r.WDL), mate, true
+}
+
 // CachedEvalByHash reads a position's cached eval (white-positive centipawns)
 // by its clock-independent Position.Hash(), returning nil on a miss, an
 // unevaluated row, or an unconfigured Postgres. The analysis endpoint reads
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/dechristopher/lio/db/gen"
+	"github.com/dechristopher/lio/game"
+)
+
+// The opening explorer: what was played from a position across the archive,
+// and how it scored. It reads the per-ply analytics index the archive already
+// writes — every ply's moves row links the deduped positions row it reached —
+// so a position is found by its clock-independent hash, the way the evaluator
+// caches evals, and its continuations are the next ply of every game that
+// reached it. Like the other read accessors, an unconfigured Postgres explores
+// an empty archive.
+
+const (
+	// explorerMoves caps the continuations listed for one position. A 4x4
+	// board rarely offers more legal moves than this, so in practice the cap
+	// only trims a long tail nobody reads.
+	explorerMoves = 16
+	// explorerGames is how many notable games a position lists.
+	explorerGames = 5
+)
+
+// ExplorerFilter narrows the explorer to one rating category (a variant
+// HTMLName, which only rated games carry) and a window of game start times,
+// Since inclusive and Until exclusive. Zero values disable each.
+type ExplorerFilter struct {
+	Category string
+	Since    time.Time
+	Until    time.Time
+}
+
+// ExplorerMove is one continuation from an explored position: the move, how
+// the games it was played in ended, the average rating of those games' seats
+// (0 when none was rated), and the cached eval of the position it reaches —
+// nil until the background evaluator gets to it.
+type ExplorerMove struct {
+	UOI       string
+	Games     int64
+	WhiteWins int64
+	Draws     int64
+	BlackWins int64
+	AvgRating int
+	EvalCp    *int16
+	EvalMate  *int16
+}
+
+// ExplorerGame is one notable game through an explored position. The names
+// are empty for a seat with no account, the ratings for an unrated game.
+type ExplorerGame struct {
+	GameID      string
+	Start       time.Time
+	Outcome     string
+	White       string
+	Black       string
+	WhiteRating string
+	BlackRating string
+}
+
+// ExplorerMatchup is how the games that began from one starting position went.
+type ExplorerMatchup struct {
+	StartingOFEN string
+	Games        int64
+	WhiteWins    int64
+	Draws        int64
+	BlackWins    int64
+}
+
+// Explore returns the continuations played from pos across the archive, most
+// played first, and its notable games. ofen is pos as the caller has it: a
+// game's starting position has no moves row to find it by, so games are also
+// matched by their starting OFEN.
+func Explore(pos *octad.Position, ofen string, f ExplorerFilter) ([]ExplorerMove, []ExplorerGame, error) {
+	if Pool == nil {
+		return nil, nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	q := gen.New(Pool)
+
+	// a position no archived game reached after a move has no row; the start
+	// arm of the queries may still find games that began there
+	var positionID *int32
+	hash := pos.Hash()
+	if p, err := q.GetPositionByHash(ctx, hash[:]); err == nil {
+		positionID = &p.ID
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, err
+	}
+
+	category, since, until := f.params()
+	rows, err := q.ExploreMoves(ctx, gen.ExploreMovesParams{
+		PositionID: positionID,
+		Ofen:       ofen,
+		Category:   category,
+		Since:      since,
+		Until:      until,
+		MaxMoves:   explorerMoves,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	moves := make([]ExplorerMove, 0, len(rows))
+	for _, r := range rows {
+		moves = append(moves, ExplorerMove{
+			UOI:       game.UnpackMoveUOI(r.Mv),
+			Games:     r.Games,
+			WhiteWins: r.WhiteWins,
+			Draws:     r.Draws,
+			BlackWins: r.BlackWins,
+			AvgRating: int(r.AvgRating),
+			EvalCp:    r.EvalCp,
+			EvalMate:  r.EvalMate,
+		})
+	}
+
+	gameRows, err := q.ExploreGames(ctx, gen.ExploreGamesParams{
+		PositionID: positionID,
+		Ofen:       ofen,
+		Category:   category,
+		Since:      since,
+		Until:      until,
+		MaxGames:   explorerGames,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	games := make([]ExplorerGame, 0, len(gameRows))
+	for _, r := range gameRows {
+		g := ExplorerGame{
+			GameID:  r.GameID.String(),
+			Start:   r.StartTs.Time,
+			Outcome: r.Outcome,
+			White:   r.WhiteName,
+			Black:   r.BlackName,
+		}
+		if r.WhiteRating != nil {
+			g.WhiteRating = *r.WhiteRating
+		}
+		if r.BlackRating != nil {
+			g.BlackRating = *r.BlackRating
+		}
+		games = append(games, g)
+	}
+	return moves, games, nil
+}
+
+// ExploreMatchups returns how the games that began from each starting position
+// went, most played first — the explorer's per-matchup view.
+func ExploreMatchups(f ExplorerFilter) ([]ExplorerMatchup, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+
+	category, since, until := f.params()
+	rows, err := gen.New(Pool).ExploreMatchups(ctx, gen.ExploreMatchupsParams{
+		Category: category,
+		Since:    since,
+		Until:    until,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ExplorerMatchup, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, ExplorerMatchup{
+			StartingOFEN: r.StartingOfen,
+			Games:        r.Games,
+			WhiteWins:    r.WhiteWins,
+			Draws:        r.Draws,
+			BlackWins:    r.BlackWins,
+		})
+	}
+	return out, nil
+}
+
+// params converts the filter to the queries' nullable arguments
+func (f ExplorerFilter) params() (*string, pgtype.Timestamptz, pgtype.Timestamptz) {
+	var category *string
+	if f.Category != "" {
+		category = &f.Category
+	}
+	return category,
+		pgtype.Timestamptz{Time: f.Since, Valid: !f.Since.IsZero()},
+		pgtype.Timestamptz{Time: f.Until, Valid: !f.Until.IsZero()}
+}
@@ -0,0 +1,235 @@
+package db
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/dechristopher/lio/db/gen"
+)
+
+// Fair play (package fairplay): per-game samples of how closely an account's
+// rated play tracked the engine, and the rolling score built from them. Like
+// the review accessors these degrade to no-ops without a pool. Nothing here
+// sanctions anybody — the only consumer of a score is the moderation page.
+
+// fairPlay queues every rated game between two accounts for review as it is
+// archived, since a review is what a fair-play sample is measured from. Set by
+// the fairplay package when fair play is enabled.
+var fairPlay atomic.Bool
+
+// EnableFairPlay makes ArchiveGame queue rated human games for review.
+func EnableFairPlay() {
+	fairPlay.Store(true)
+}
+
+// fairPlayGame reports whether an archived game is one fair play samples:
+// rated, an account in each seat, no bot.
+func fairPlayGame(rec GameRecord) bool {
+	return rec.Rated && rec.WhiteUserID != nil && rec.BlackUserID != nil && rec.BotPersona == ""
+}
+
+// FairPlayGame is what the sampler needs to know about a reviewed game.
+type FairPlayGame struct {
+	// Sampled reports the game is rated, between two accounts, with no bot.
+	Sampled      bool
+	WhiteUserID  int64
+	BlackUserID  int64
+	StartingOFEN string
+}
+
+// FairPlayPly is one ply of a reviewed game as the sampler reads it.
+type FairPlayPly struct {
+	// Move is the packed move played; Best the engine's packed best move in
+	// the position it reached (nil when never searched).
+	Move    int16
+	Best    *int16
+	ThinkMs *int32
+}
+
+// FairPlaySample is one account's side of one reviewed game.
+type FairPlaySample struct {
+	GameRef int32
+	GameID  string
+	When    time.Time
+	// Moves are the judged moves; EngineMatches those that were the engine's
+	// choice and TopMatches those that cost next to nothing.
+	Moves         int
+	EngineMatches int
+	TopMatches    int
+	ACPL          int
+	// ThinkMeanMs and ThinkCV describe the side's think times; nil when too
+	// few moves were timed.
+	ThinkMeanMs *int32
+	ThinkCV     *float32
+}
+
+// FairPlayScore is an account's rolling score and what it was built from.
+type FairPlayScore struct {
+	Score     float32
+	Games     int
+	MatchRate float32
+	TopRate   float32
+	ACPL      float32
+	// ThinkCV is the median think-time variation across the games; MatchSpread
+	// the spread of per-game match rates. Nil when too few games carry them.
+	ThinkCV     *float32
+	MatchSpread *float32
+}
+
+// FlaggedAccount is one account on the moderation page's fair-play list.
+type FlaggedAccount struct {
+	UserID   int64
+	Username string
+	FairPlayScore
+	Updated time.Time
+	// Reviewed is when a moderator last dismissed the account; zero if never.
+	Reviewed time.Time
+}
+
+// GetFairPlayGame reads what decides whether a reviewed game is sampled.
+func GetFairPlayGame(gameRef int32) (FairPlayGame, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetFairPlayGame(ctx, gameRef)
+	if err != nil {
+		return FairPlayGame{}, err
+	}
+	g := FairPlayGame{
+		Sampled:      row.Rated && row.WhiteUserID != nil && row.BlackUserID != nil && row.BotPersona == nil,
+		StartingOFEN: row.StartingOfen,
+	}
+	if g.Sampled {
+		g.WhiteUserID, g.BlackUserID = *row.WhiteUserID, *row.BlackUserID
+	}
+	return g, nil
+}
+
+// FairPlayPlies returns a reviewed game's plies, in order.
+func FairPlayPlies(gameRef int32) ([]FairPlayPly, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListFairPlayPlies(ctx, gameRef)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]FairPlayPly, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, FairPlayPly{Move: r.Mv, Best: r.BestMove, ThinkMs: r.MoveMs})
+	}
+	return out, nil
+}
+
+// SaveFairPlaySample records an account's sample from a game, replacing any
+// earlier one (a game reviewed again is measured again).
+func SaveFairPlaySample(userID int64, s FairPlaySample) error {
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).UpsertFairPlaySample(ctx, gen.UpsertFairPlaySampleParams{
+		GameRef:       s.GameRef,
+		UserID:        userID,
+		Moves:         int16(s.Moves),
+		EngineMatches: int16(s.EngineMatches),
+		TopMatches:    int16(s.TopMatches),
+		Acpl:          int16(s.ACPL),
+		ThinkMeanMs:   s.ThinkMeanMs,
+		ThinkCv:       s.ThinkCV,
+	})
+}
+
+// FairPlaySamples returns an account's most recent samples, newest first.
+func FairPlaySamples(userID int64, limit int) ([]FairPlaySample, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListFairPlaySamples(ctx, gen.ListFairPlaySamplesParams{
+		UserID: userID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]FairPlaySample, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, FairPlaySample{
+			GameRef:       r.GameRef,
+			GameID:        r.GameID.String(),
+			When:          r.CreatedAt.Time,
+			Moves:         int(r.Moves),
+			EngineMatches: int(r.EngineMatches),
+			TopMatches:    int(r.TopMatches),
+			ACPL:          int(r.Acpl),
+			ThinkMeanMs:   r.ThinkMeanMs,
+			ThinkCV:       r.ThinkCv,
+		})
+	}
+	return out, nil
+}
+
+// SaveFairPlayScore records an account's rolled score.
+func SaveFairPlayScore(userID int64, s FairPlayScore) error {
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).UpsertFairPlayScore(ctx, gen.UpsertFairPlayScoreParams{
+		UserID:      userID,
+		Score:       s.Score,
+		Games:       int16(s.Games),
+		MatchRate:   s.MatchRate,
+		TopRate:     s.TopRate,
+		Acpl:        s.ACPL,
+		ThinkCv:     s.ThinkCV,
+		MatchSpread: s.MatchSpread,
+	})
+}
+
+// FlaggedAccounts returns up to limit accounts scoring at least minScore over
+// at least minGames, highest first. An account a moderator dismissed stays off
+// the list until its score climbs margin past the score it was dismissed at.
+func FlaggedAccounts(minScore float32, minGames int, margin float32, limit int) ([]FlaggedAccount, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListFlaggedAccounts(ctx, gen.ListFlaggedAccountsParams{
+		MinScore: minScore,
+		MinGames: int16(minGames),
+		Margin:   margin,
+		Lim:      int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]FlaggedAccount, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, FlaggedAccount{
+			UserID:   r.UserID,
+			Username: r.Username,
+			FairPlayScore: FairPlayScore{
+				Score:       r.Score,
+				Games:       int(r.Games),
+				MatchRate:   r.MatchRate,
+				TopRate:     r.TopRate,
+				ACPL:        r.Acpl,
+				ThinkCV:     r.ThinkCv,
+				MatchSpread: r.MatchSpread,
+			},
+			Updated:  r.UpdatedAt.Time,
+			Reviewed: r.ReviewedAt.Time,
+		})
+	}
+	return out, nil
+}
+
+// DismissFairPlayFlag records that a moderator reviewed an account's score
+// and found nothing, reporting whether the account had one.
+func DismissFairPlayFlag(userID, moderatorID int64) (bool, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	n, err := gen.New(Pool).DismissFairPlayFlag(ctx, gen.DismissFairPlayFlagParams{
+		UserID:     userID,
+		ReviewedBy: &moderatorID,
+	})
+	return n > 0, err
+}
@@ -51,6 +51,9 @@ type FollowCounts struct {
 // one account can both pass it and land at MaxFollowing+1. That is acceptable:
 // this bounds storage against a script, and a cap overshot by one row under a
 // deliberate race has not failed at that.
+//
+// A block between the two, in either direction, refuses the follow with
+// ErrBlocked (db/blocks.go).
 func Follow(followerID, followeeID int64) (created bool, err error) {
 	if Pool == nil {
 		return false, nil
@@ -64,6 +67,15 @@ func Follow(followerID, followeeID int64) (created bool, err error) {
 	defer cancel()
 
 	q := gen.New(Pool)
+	blocked, err := q.IsBlockedEither(ctx, gen.IsBlockedEitherParams{
+		UserA: followerID, UserB: followeeID,
+	})
+	if err != nil {
+		return false, err
+	}
+	if blocked {
+		return false, ErrBlocked
+	}
 	n, err := q.CountFollowing(ctx, followerID)
 	if err != nil {
 		return false, err
@@ -12,6 +12,114 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const accountGameIDs = `-- name: AccountGameIDs :many
+SELECT id FROM games
+WHERE white_user_id = $1::bigint OR black_user_id = $1::bigint
+   OR white_uid = ANY($2::text[]) OR black_uid = ANY($2::text[])
+ORDER BY id
+`
+
+type AccountGameIDsParams struct {
+	UserID int64
+	Uids   []string
+}
+
+// Every game an account played, from either seat, or under one of its session
+// uids (AccountUIDs) before it signed in. Read just before the account is
+// deleted, for the stored PGN objects that still name it or carry those uids.
+func (q *Queries) AccountGameIDs(ctx context.Context, arg AccountGameIDsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, accountGameIDs, arg.UserID, arg.Uids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const accountUIDs = `-- name: AccountUIDs :many
+SELECT DISTINCT seats.uid::text AS uid FROM (
+    SELECT white_uid AS uid FROM games WHERE white_user_id = $1::bigint
+    UNION ALL SELECT black_uid FROM games WHERE black_user_id = $1::bigint
+    UNION ALL SELECT creator_uid FROM games WHERE creator_user_id = $1::bigint
+    UNION ALL SELECT white_uid FROM rooms WHERE white_user_id = $1::bigint
+    UNION ALL SELECT black_uid FROM rooms WHERE black_user_id = $1::bigint
+    UNION ALL SELECT creator_uid FROM rooms WHERE creator_user_id = $1::bigint
+    UNION ALL SELECT uid FROM chat_messages WHERE user_id = $1::bigint
+) seats
+WHERE seats.uid <> ''
+`
+
+// Every session uid an account sat, created a game or spoke under. Read just
+// before the account is deleted: once the user ids are gone, a uid is what
+// would still join its games, rooms and chat lines back into one person.
+func (q *Queries) AccountUIDs(ctx context.Context, userID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, accountUIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			return nil, err
+		}
+		items = append(items, uid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const anonymiseGameUIDs = `-- name: AnonymiseGameUIDs :execrows
+UPDATE games SET
+    white_uid   = CASE WHEN white_uid = ANY($1::text[])
+                        AND (white_user_id IS NULL OR white_user_id = $2::bigint)
+                       THEN $3::text ELSE white_uid END,
+    black_uid   = CASE WHEN black_uid = ANY($1::text[])
+                        AND (black_user_id IS NULL OR black_user_id = $2::bigint)
+                       THEN $3::text ELSE black_uid END,
+    creator_uid = CASE WHEN creator_uid = ANY($1::text[])
+                        AND (creator_user_id IS NULL OR creator_user_id = $2::bigint)
+                       THEN $3::text ELSE creator_uid END
+WHERE white_uid = ANY($1::text[])
+   OR black_uid = ANY($1::text[])
+   OR creator_uid = ANY($1::text[])
+`
+
+type AnonymiseGameUIDsParams struct {
+	Uids    []string
+	UserID  int64
+	AnonUid string
+}
+
+// Replace an account's session uids (AccountUIDs) on the archived games with
+// one anonymous uid, in every seat that is the account's or nobody's: a seat
+// another account took under the same session keeps its uid.
+func (q *Queries) AnonymiseGameUIDs(ctx context.Context, arg AnonymiseGameUIDsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, anonymiseGameUIDs,
+		arg.Uids,
+		arg.UserID,
+		arg.AnonUid,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const countGames = `-- name: CountGames :one
 SELECT count(*) FROM games
 `
@@ -24,7 +132,7 @@ func (q *Queries) CountGames(ctx context.Context) (int64, error) {
 }
 
 const getGameByUUID = `-- name: GetGameByUUID :one
-SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category FROM games WHERE game_id = $1
+SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category, tournament_id, simul_id FROM games WHERE game_id = $1
 `
 
 func (q *Queries) GetGameByUUID(ctx context.Context, gameID uuid.UUID) (Game, error) {
@@ -63,12 +171,14 @@ func (q *Queries) GetGameByUUID(ctx context.Context, gameID uuid.UUID) (Game, er
 		&i.BlackRatingDelta,
 		&i.BotPersona,
 		&i.RatingCategory,
+		&i.TournamentID,
+		&i.SimulID,
 	)
 	return i, err
 }
 
 const getRoomGameByIndex = `-- name: GetRoomGameByIndex :one
-SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category FROM games WHERE room_id = $1 AND game_index = $2
+SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category, tournament_id, simul_id FROM games WHERE room_id = $1 AND game_index = $2
 `
 
 type GetRoomGameByIndexParams struct {
@@ -112,6 +222,8 @@ func (q *Queries) GetRoomGameByIndex(ctx context.Context, arg GetRoomGameByIndex
 		&i.BlackRatingDelta,
 		&i.BotPersona,
 		&i.RatingCategory,
+		&i.TournamentID,
+		&i.SimulID,
 	)
 	return i, err
 }
@@ -205,10 +317,11 @@ INSERT INTO games (
     variant_group, outcome, reason, starting_ofen, moves, pgn_object_key,
     game_index, white_user_id, black_user_id, creator_user_id, rated,
     white_rating, black_rating, white_rating_delta, black_rating_delta,
-    bot_persona, rating_category
+    bot_persona, rating_category, tournament_id, simul_id
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
-    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31,
+    $32
 )
 RETURNING id
 `
@@ -244,6 +357,8 @@ type InsertGameParams struct {
 	BlackRatingDelta *int16
 	BotPersona       *string
 	RatingCategory   *string
+	TournamentID     *string
+	SimulID          *string
 }
 
 func (q *Queries) InsertGame(ctx context.Context, arg InsertGameParams) (int32, error) {
@@ -278,6 +393,8 @@ func (q *Queries) InsertGame(ctx context.Context, arg InsertGameParams) (int32,
 		arg.BlackRatingDelta,
 		arg.BotPersona,
 		arg.RatingCategory,
+		arg.TournamentID,
+		arg.SimulID,
 	)
 	var id int32
 	err := row.Scan(&id)
@@ -291,10 +408,11 @@ INSERT INTO games (
     variant_group, outcome, reason, starting_ofen, moves, pgn_object_key,
     game_index, white_user_id, black_user_id, creator_user_id, rated,
     white_rating, black_rating, white_rating_delta, black_rating_delta,
-    bot_persona, rating_category
+    bot_persona, rating_category, tournament_id, simul_id
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
-    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31,
+    $32
 )
 ON CONFLICT (pgn_object_key) DO NOTHING
 RETURNING id
@@ -331,6 +449,8 @@ type InsertGameIfNewParams struct {
 	BlackRatingDelta *int16
 	BotPersona       *string
 	RatingCategory   *string
+	TournamentID     *string
+	SimulID          *string
 }
 
 // Same columns/order as InsertGame (so the generated param structs are
@@ -368,14 +488,146 @@ func (q *Queries) InsertGameIfNew(ctx context.Context, arg InsertGameIfNewParams
 		arg.BlackRatingDelta,
 		arg.BotPersona,
 		arg.RatingCategory,
+		arg.TournamentID,
+		arg.SimulID,
 	)
 	var id int32
 	err := row.Scan(&id)
 	return id, err
 }
 
+const listAccountGames = `-- name: ListAccountGames :many
+SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category, tournament_id, simul_id FROM games
+WHERE (white_user_id = $1::bigint OR black_user_id = $1::bigint)
+  AND id > $2::int
+ORDER BY id
+LIMIT $3
+`
+
+type ListAccountGamesParams struct {
+	UserID    int64
+	AfterID   int32
+	BatchSize int32
+}
+
+// One page of every game an account played, from either seat, oldest first and
+// keyset-paged on id: the games file of the account's data export.
+func (q *Queries) ListAccountGames(ctx context.Context, arg ListAccountGamesParams) ([]Game, error) {
+	rows, err := q.db.Query(ctx, listAccountGames, arg.UserID, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameID,
+			&i.StartTs,
+			&i.EndTs,
+			&i.CreatedAt,
+			&i.RaceTo,
+			&i.WhiteMatchScore,
+			&i.BlackMatchScore,
+			&i.Method,
+			&i.Casual,
+			&i.RoomID,
+			&i.CreatorUid,
+			&i.WhiteUid,
+			&i.BlackUid,
+			&i.VariantName,
+			&i.VariantGroup,
+			&i.Outcome,
+			&i.Reason,
+			&i.StartingOfen,
+			&i.Moves,
+			&i.PgnObjectKey,
+			&i.GameIndex,
+			&i.WhiteUserID,
+			&i.BlackUserID,
+			&i.CreatorUserID,
+			&i.Rated,
+			&i.WhiteRating,
+			&i.BlackRating,
+			&i.WhiteRatingDelta,
+			&i.BlackRatingDelta,
+			&i.BotPersona,
+			&i.RatingCategory,
+			&i.TournamentID,
+			&i.SimulID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGamesByID = `-- name: ListGamesByID :many
+SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category, tournament_id, simul_id FROM games WHERE id = ANY($1::int[]) ORDER BY id
+`
+
+func (q *Queries) ListGamesByID(ctx context.Context, ids []int32) ([]Game, error) {
+	rows, err := q.db.Query(ctx, listGamesByID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameID,
+			&i.StartTs,
+			&i.EndTs,
+			&i.CreatedAt,
+			&i.RaceTo,
+			&i.WhiteMatchScore,
+			&i.BlackMatchScore,
+			&i.Method,
+			&i.Casual,
+			&i.RoomID,
+			&i.CreatorUid,
+			&i.WhiteUid,
+			&i.BlackUid,
+			&i.VariantName,
+			&i.VariantGroup,
+			&i.Outcome,
+			&i.Reason,
+			&i.StartingOfen,
+			&i.Moves,
+			&i.PgnObjectKey,
+			&i.GameIndex,
+			&i.WhiteUserID,
+			&i.BlackUserID,
+			&i.CreatorUserID,
+			&i.Rated,
+			&i.WhiteRating,
+			&i.BlackRating,
+			&i.WhiteRatingDelta,
+			&i.BlackRatingDelta,
+			&i.BotPersona,
+			&i.RatingCategory,
+			&i.TournamentID,
+			&i.SimulID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPlayerGames = `-- name: ListPlayerGames :many
-SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category FROM games
+SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category, tournament_id, simul_id FROM games
 WHERE white_uid = $1 OR black_uid = $1
 ORDER BY start_ts DESC
 LIMIT $2 OFFSET $3
@@ -429,6 +681,8 @@ func (q *Queries) ListPlayerGames(ctx context.Context, arg ListPlayerGamesParams
 			&i.BlackRatingDelta,
 			&i.BotPersona,
 			&i.RatingCategory,
+			&i.TournamentID,
+			&i.SimulID,
 		); err != nil {
 			return nil, err
 		}
@@ -441,7 +695,7 @@ func (q *Queries) ListPlayerGames(ctx context.Context, arg ListPlayerGamesParams
 }
 
 const listRoomGames = `-- name: ListRoomGames :many
-SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category FROM games WHERE room_id = $1 ORDER BY game_index
+SELECT id, game_id, start_ts, end_ts, created_at, race_to, white_match_score, black_match_score, method, casual, room_id, creator_uid, white_uid, black_uid, variant_name, variant_group, outcome, reason, starting_ofen, moves, pgn_object_key, game_index, white_user_id, black_user_id, creator_user_id, rated, white_rating, black_rating, white_rating_delta, black_rating_delta, bot_persona, rating_category, tournament_id, simul_id FROM games WHERE room_id = $1 ORDER BY game_index
 `
 
 func (q *Queries) ListRoomGames(ctx context.Context, roomID string) ([]Game, error) {
@@ -486,6 +740,8 @@ func (q *Queries) ListRoomGames(ctx context.Context, roomID string) ([]Game, err
 			&i.BlackRatingDelta,
 			&i.BotPersona,
 			&i.RatingCategory,
+			&i.TournamentID,
+			&i.SimulID,
 		); err != nil {
 			return nil, err
 		}
@@ -9,6 +9,31 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiToken struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	TokenHash  []byte
+	Scopes     []string
+	Uid        string
+	CreatedAt  pgtype.Timestamptz
+	LastUsedAt pgtype.Timestamptz
+}
+
+type Block struct {
+	BlockerID int64
+	BlockedID int64
+	CreatedAt pgtype.Timestamptz
+}
+
+type BotAccount struct {
+	UserID         int64
+	CreatedAt      pgtype.Timestamptz
+	Uid            string
+	TokenHash      []byte
+	TokenCreatedAt pgtype.Timestamptz
+}
+
 type Broadcast struct {
 	ID        int64
 	CreatedAt pgtype.Timestamptz
@@ -26,6 +51,70 @@ type BroadcastAck struct {
 	CreatedAt   pgtype.Timestamptz
 }
 
+type ChatMessage struct {
+	ID        int64
+	RoomID    string
+	Channel   string
+	UserID    *int64
+	Uid       string
+	Username  string
+	Body      string
+	Filtered  bool
+	CreatedAt pgtype.Timestamptz
+}
+
+type CorrespondenceGame struct {
+	ID            string
+	Variant       string
+	Rated         bool
+	CreatorUserID int64
+	WhiteUserID   *int64
+	BlackUserID   *int64
+	InvitedUserID *int64
+	CreatorUid    string
+	WhiteUid      string
+	BlackUid      string
+	Status        string
+	GameID        uuid.UUID
+	StartingOfen  string
+	Moves         []string
+	DrawOffer     *string
+	Deadline      pgtype.Timestamptz
+	Outcome       string
+	Reason        string
+	CreatedAt     pgtype.Timestamptz
+	StartedAt     pgtype.Timestamptz
+	LastMoveAt    pgtype.Timestamptz
+	FinishedAt    pgtype.Timestamptz
+}
+
+type FairPlaySample struct {
+	GameRef       int32
+	UserID        int64
+	CreatedAt     pgtype.Timestamptz
+	Moves         int16
+	EngineMatches int16
+	TopMatches    int16
+	Acpl          int16
+	ThinkMeanMs   *int32
+	ThinkCv       *float32
+}
+
+type FairPlayScore struct {
+	UserID        int64
+	Score         float32
+	Games         int16
+	MatchRate     float32
+	TopRate       float32
+	Acpl          float32
+	ThinkCv       *float32
+	MatchSpread   *float32
+	UpdatedAt     pgtype.Timestamptz
+	ReviewedAt    pgtype.Timestamptz
+	ReviewedBy    *int64
+	ReviewedScore *float32
+}
+
 type Feedback struct {
 	ID        int64
 	CreatedAt pgtype.Timestamptz
@@ -76,12 +165,46 @@ type Game struct {
 	BlackRatingDelta *int16
 	BotPersona       *string
 	RatingCategory   *string
+	TournamentID     *string
+	SimulID          *string
+}
+
+type GameAnalysis struct {
+	GameRef       int32
+	RequestedAt   pgtype.Timestamptz
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+	LastError     string
+	CompletedAt   pgtype.Timestamptz
+	Depth         *int16
+	Evals         []int16
+	Judgements    []int16
+	WhiteAccuracy *float32
+	BlackAccuracy *float32
+	WhiteAcpl     *int16
+	BlackAcpl     *int16
+}
+
+type MailOutbox struct {
+	ID            int64
+	CreatedAt     pgtype.Timestamptz
+	UserID        *int64
+	Kind          string
+	ToAddr        string
+	Subject       string
+	Body          string
+	Headers       []byte
+	DedupeKey     *string
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+	LastError     string
+	SentAt        pgtype.Timestamptz
 }
 
 type ModAction struct {
 	ID           int64
 	CreatedAt    pgtype.Timestamptz
-	ActorUserID  int64
+	ActorUserID  *int64
 	TargetUserID *int64
 	Action       string
 	Detail       []byte
@@ -111,6 +234,15 @@ type Notification struct {
 	Response  *string
 }
 
+type PgnDump struct {
+	Month     string
+	ObjectKey string
+	Games     int64
+	Bytes     int64
+	RawBytes  int64
+	BuiltAt   pgtype.Timestamptz
+}
+
 type Position struct {
 	ID          int32
 	Hash        []byte
@@ -119,6 +251,39 @@ type Position struct {
 	EvalDepth   *int16
 	BestMove    *int16
 	EvaluatedAt pgtype.Timestamptz
+	EvalMate    *int16
+	EvalExact   bool
+}
+
+type Puzzle struct {
+	ID         int64
+	PositionID int32
+	GameRef    *int32
+	Ofen       string
+	LastMove   string
+	Solution   string
+	Theme      string
+	Rating     float64
+	Rd         float64
+	Volatility float64
+	Plays      int32
+	CreatedAt  pgtype.Timestamptz
+}
+
+type PuzzleAttempt struct {
+	UserID    int64
+	PuzzleID  int64
+	Solved    bool
+	CreatedAt pgtype.Timestamptz
+}
+
+type PuzzleRating struct {
+	UserID     int64
+	Rating     float64
+	Rd         float64
+	Volatility float64
+	Plays      int32
+	UpdatedAt  pgtype.Timestamptz
 }
 
 type Rating struct {
@@ -143,7 +308,7 @@ type Report struct {
 	ID             int64
 	CreatedAt      pgtype.Timestamptz
 	ReporterUserID int64
-	TargetUserID   int64
+	TargetUserID   *int64
 	GameID         pgtype.UUID
 	Category       string
 	Note           string
@@ -151,6 +316,7 @@ type Report struct {
 	ResolvedAt     pgtype.Timestamptz
 	ResolvedBy     *int64
 	Resolution     *string
+	TargetUsername *string
 }
 
 type Room struct {
@@ -194,6 +360,32 @@ type Setting struct {
 	UpdatedBy *int64
 }
 
+type Simul struct {
+	ID         string
+	Name       string
+	Variant    string
+	HostUserID *int64
+	HostName   string
+	HostColor  string
+	MaxBoards  int16
+	Status     string
+	Wins       int16
+	Draws      int16
+	Losses     int16
+	CreatedAt  pgtype.Timestamptz
+	StartedAt  pgtype.Timestamptz
+	FinishedAt pgtype.Timestamptz
+}
+
+type SimulBoard struct {
+	SimulID  string
+	UserID   int64
+	Username string
+	RoomID   string
+	Result   string
+	JoinedAt pgtype.Timestamptz
+}
+
 type Title struct {
 	ID        int16
 	CreatedAt pgtype.Timestamptz
@@ -201,6 +393,50 @@ type Title struct {
 	Name      string
 }
 
+type Tournament struct {
+	ID           string
+	Name         string
+	Format       string
+	Variant      string
+	Rated        bool
+	Berserk      bool
+	Rounds       int16
+	DurationSecs int32
+	Status       string
+	Round        int16
+	CreatedBy    *int64
+	CreatedAt    pgtype.Timestamptz
+	StartsAt     pgtype.Timestamptz
+	StartedAt    pgtype.Timestamptz
+	FinishedAt   pgtype.Timestamptz
+}
+
+type TournamentPairing struct {
+	ID           int64
+	TournamentID string
+	Round        int16
+	RoomID       string
+	WhiteUserID  int64
+	BlackUserID  *int64
+	Result       string
+	CreatedAt    pgtype.Timestamptz
+}
+
+type TournamentPlayer struct {
+	TournamentID string
+	UserID       int64
+	Rating       int32
+	Score        float32
+	Tiebreak     float32
+	Wins         int16
+	Draws        int16
+	Losses       int16
+	Sheet        string
+	Rank         int16
+	Withdrawn    bool
+	JoinedAt     pgtype.Timestamptz
+}
+
 type User struct {
 	ID                 int64
 	CreatedAt          pgtype.Timestamptz
@@ -216,6 +452,7 @@ type User struct {
 	BannedUntil        pgtype.Timestamptz
 	BanReason          *string
 	BroadcastSeenAt    pgtype.Timestamptz
+	EmailVerifiedAt    pgtype.Timestamptz
 }
 
 type UserPref struct {
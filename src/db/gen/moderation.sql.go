@@ -12,7 +12,7 @@ import (
 )
 
 const adminGrantor = `-- name: AdminGrantor :one
-SELECT actor_user_id
+SELECT COALESCE(actor_user_id, 0)::bigint AS actor_user_id
 FROM mod_actions
 WHERE target_user_id = $1
   AND action = 'role'
@@ -24,7 +24,8 @@ LIMIT 1
 // Who last promoted this account to admin, from the audit log itself — the
 // log is the record of who did what, so it is also the record of who may undo
 // it. No row means the account's admin came from outside the app (the SQL
-// bootstrap), and nobody may demote it through the UI.
+// bootstrap), and nobody may demote it through the UI. A grantor that has
+// since been deleted reads as 0, which matches no one: the same outcome.
 func (q *Queries) AdminGrantor(ctx context.Context, targetUserID *int64) (int64, error) {
 	row := q.db.QueryRow(ctx, adminGrantor, targetUserID)
 	var actor_user_id int64
@@ -58,7 +59,7 @@ func (q *Queries) CountAdmins(ctx context.Context) (int64, error) {
 const countModActions = `-- name: CountModActions :one
 SELECT count(*)
 FROM mod_actions m
-         JOIN users a ON a.id = m.actor_user_id
+         LEFT JOIN users a ON a.id = m.actor_user_id
          LEFT JOIN users t ON t.id = m.target_user_id
 WHERE ($1::text IS NULL OR m.action = $1::text)
   AND ($2::text IS NULL
@@ -121,7 +122,7 @@ RETURNING id
 `
 
 type InsertModActionParams struct {
-	ActorUserID  int64
+	ActorUserID  *int64
 	TargetUserID *int64
 	Action       string
 	Detail       []byte
@@ -148,10 +149,10 @@ func (q *Queries) InsertModAction(ctx context.Context, arg InsertModActionParams
 
 const listModActions = `-- name: ListModActions :many
 SELECT m.id, m.created_at, m.action, m.detail, m.reason,
-       a.username AS actor_username,
+       COALESCE(a.username, '')::text AS actor_username,
        t.username AS target_username
 FROM mod_actions m
-         JOIN users a ON a.id = m.actor_user_id
+         LEFT JOIN users a ON a.id = m.actor_user_id
          LEFT JOIN users t ON t.id = m.target_user_id
 WHERE ($3::text IS NULL OR m.action = $3::text)
   AND ($4::text IS NULL
@@ -225,9 +226,9 @@ func (q *Queries) ListModActions(ctx context.Context, arg ListModActionsParams)
 
 const listModActionsForUser = `-- name: ListModActionsForUser :many
 SELECT m.id, m.created_at, m.action, m.detail, m.reason,
-       a.username AS actor_username
+       COALESCE(a.username, '')::text AS actor_username
 FROM mod_actions m
-         JOIN users a ON a.id = m.actor_user_id
+         LEFT JOIN users a ON a.id = m.actor_user_id
 WHERE m.target_user_id = $1
 ORDER BY m.created_at DESC
 LIMIT $2 OFFSET $3
@@ -323,7 +324,7 @@ FROM users u
          LEFT JOIN LATERAL (
     SELECT m.created_at, a.username AS actor_username
     FROM mod_actions m
-             JOIN users a ON a.id = m.actor_user_id
+             LEFT JOIN users a ON a.id = m.actor_user_id
     WHERE m.target_user_id = u.id
       AND m.action = 'role'
       AND m.detail ->> 'to' = u.role
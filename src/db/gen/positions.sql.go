@@ -10,7 +10,7 @@ import (
 )
 
 const getPositionByHash = `-- name: GetPositionByHash :one
-SELECT id, hash, ofen, eval_cp, eval_depth, best_move, evaluated_at FROM positions WHERE hash = $1
+SELECT id, hash, ofen, eval_cp, eval_depth, best_move, evaluated_at, eval_mate, eval_exact FROM positions WHERE hash = $1
 `
 
 func (q *Queries) GetPositionByHash(ctx context.Context, hash []byte) (Position, error) {
@@ -24,6 +24,8 @@ func (q *Queries) GetPositionByHash(ctx context.Context, hash []byte) (Position,
 		&i.EvalDepth,
 		&i.BestMove,
 		&i.EvaluatedAt,
+		&i.EvalMate,
+		&i.EvalExact,
 	)
 	return i, err
 }
@@ -62,7 +64,8 @@ func (q *Queries) ListPositionsNeedingEval(ctx context.Context, limit int32) ([]
 
 const setPositionEval = `-- name: SetPositionEval :exec
 UPDATE positions
-SET eval_cp = $2, eval_depth = $3, best_move = $4, evaluated_at = now()
+SET eval_cp = $2, eval_depth = $3, best_move = $4, eval_mate = $5, eval_exact = $6,
+    evaluated_at = now()
 WHERE id = $1
 `
 
@@ -71,6 +74,8 @@ type SetPositionEvalParams struct {
 	EvalCp    *int16
 	EvalDepth *int16
 	BestMove  *int16
+	EvalMate  *int16
+	EvalExact bool
 }
 
 func (q *Queries) SetPositionEval(ctx context.Context, arg SetPositionEvalParams) error {
@@ -79,6 +84,8 @@ func (q *Queries) SetPositionEval(ctx context.Context, arg SetPositionEvalParams
 		arg.EvalCp,
 		arg.EvalDepth,
 		arg.BestMove,
+		arg.EvalMate,
+		arg.EvalExact,
 	)
 	return err
 }
@@ -28,7 +28,7 @@ SELECT count(*) FROM reports WHERE target_user_id = $1 AND status = 'open'
 
 // "Is anyone complaining about this account?" — shown on their player page so a
 // moderator sees the context before acting.
-func (q *Queries) CountOpenReportsForUser(ctx context.Context, targetUserID int64) (int64, error) {
+func (q *Queries) CountOpenReportsForUser(ctx context.Context, targetUserID *int64) (int64, error) {
 	row := q.db.QueryRow(ctx, countOpenReportsForUser, targetUserID)
 	var count int64
 	err := row.Scan(&count)
@@ -44,7 +44,7 @@ RETURNING id
 
 type CreateReportParams struct {
 	ReporterUserID int64
-	TargetUserID   int64
+	TargetUserID   *int64
 	GameID         pgtype.UUID
 	Category       string
 	Note           string
@@ -71,12 +71,14 @@ const listClosedReports = `-- name: ListClosedReports :many
 SELECT r.id, r.created_at, r.category, r.note, r.game_id,
        r.resolved_at, r.resolution,
        rep.username AS reporter_username,
-       tgt.username AS target_username,
-       res.username AS resolver_username
+       COALESCE(tgt.username, r.target_username, '')::text AS target_username,
+       res.username AS resolver_username,
+       COALESCE(g.room_id, '')::text AS room_id
 FROM reports r
          JOIN users rep ON rep.id = r.reporter_user_id
-         JOIN users tgt ON tgt.id = r.target_user_id
+         LEFT JOIN users tgt ON tgt.id = r.target_user_id
          LEFT JOIN users res ON res.id = r.resolved_by
+         LEFT JOIN games g ON g.game_id = r.game_id
 WHERE r.status = 'closed'
 ORDER BY r.resolved_at DESC NULLS LAST
 LIMIT $1
@@ -93,6 +95,7 @@ type ListClosedReportsRow struct {
 	ReporterUsername string
 	TargetUsername   string
 	ResolverUsername *string
+	RoomID           string
 }
 
 // Recently resolved, newest first: the queue's own history, so a moderator can
@@ -117,6 +120,7 @@ func (q *Queries) ListClosedReports(ctx context.Context, limit int32) ([]ListClo
 			&i.ReporterUsername,
 			&i.TargetUsername,
 			&i.ResolverUsername,
+			&i.RoomID,
 		); err != nil {
 			return nil, err
 		}
@@ -131,11 +135,13 @@ func (q *Queries) ListClosedReports(ctx context.Context, limit int32) ([]ListClo
 const listOpenReports = `-- name: ListOpenReports :many
 SELECT r.id, r.created_at, r.category, r.note, r.game_id,
        rep.username AS reporter_username,
-       tgt.username AS target_username,
-       tgt.id       AS target_user_id
+       COALESCE(tgt.username, r.target_username, '')::text AS target_username,
+       COALESCE(r.target_user_id, 0)::bigint AS target_user_id,
+       COALESCE(g.room_id, '')::text AS room_id
 FROM reports r
          JOIN users rep ON rep.id = r.reporter_user_id
-         JOIN users tgt ON tgt.id = r.target_user_id
+         LEFT JOIN users tgt ON tgt.id = r.target_user_id
+         LEFT JOIN games g ON g.game_id = r.game_id
 WHERE r.status = 'open'
 ORDER BY r.created_at
 LIMIT $1 OFFSET $2
@@ -155,11 +161,15 @@ type ListOpenReportsRow struct {
 	ReporterUsername string
 	TargetUsername   string
 	TargetUserID     int64
+	RoomID           string
 }
 
 // The queue, oldest first — it is worked in the order things were reported, so
 // nothing sits at the bottom forever. Both parties' names are resolved here
-// because a queue of user ids is unreadable.
+// because a queue of user ids is unreadable, and a named game's room because
+// that is what its chat log is kept by. The target join is LEFT, like every
+// read of reports since 00036: a deleted account is named by the username it
+// had, and its id reads as 0.
 func (q *Queries) ListOpenReports(ctx context.Context, arg ListOpenReportsParams) ([]ListOpenReportsRow, error) {
 	rows, err := q.db.Query(ctx, listOpenReports, arg.Limit, arg.Offset)
 	if err != nil {
@@ -178,6 +188,61 @@ func (q *Queries) ListOpenReports(ctx context.Context, arg ListOpenReportsParams
 			&i.ReporterUsername,
 			&i.TargetUsername,
 			&i.TargetUserID,
+			&i.RoomID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReportsFiledBy = `-- name: ListReportsFiledBy :many
+SELECT r.id, r.created_at, r.category, r.note, r.game_id, r.status,
+       r.resolved_at, r.resolution,
+       COALESCE(tgt.username, r.target_username, '')::text AS target_username
+FROM reports r
+         LEFT JOIN users tgt ON tgt.id = r.target_user_id
+WHERE r.reporter_user_id = $1
+ORDER BY r.created_at DESC
+`
+
+type ListReportsFiledByRow struct {
+	ID             int64
+	CreatedAt      pgtype.Timestamptz
+	Category       string
+	Note           string
+	GameID         pgtype.UUID
+	Status         string
+	ResolvedAt     pgtype.Timestamptz
+	Resolution     *string
+	TargetUsername string
+}
+
+// Every report an account filed, newest first, for its data export. What was
+// decided is the reporter's to know; which moderator decided it is not.
+func (q *Queries) ListReportsFiledBy(ctx context.Context, reporterUserID int64) ([]ListReportsFiledByRow, error) {
+	rows, err := q.db.Query(ctx, listReportsFiledBy, reporterUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReportsFiledByRow
+	for rows.Next() {
+		var i ListReportsFiledByRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.Category,
+			&i.Note,
+			&i.GameID,
+			&i.Status,
+			&i.ResolvedAt,
+			&i.Resolution,
+			&i.TargetUsername,
 		); err != nil {
 			return nil, err
 		}
@@ -208,10 +273,26 @@ type ResolveReportParams struct {
 
 // Close one open report. Scoped to status = 'open' so two moderators working
 // the queue at once cannot both claim the same row: the second gets no row and
-// is told it was already handled.
-func (q *Queries) ResolveReport(ctx context.Context, arg ResolveReportParams) (int64, error) {
+// is told it was already handled. The target is NULL if the account has since
+// been deleted.
+func (q *Queries) ResolveReport(ctx context.Context, arg ResolveReportParams) (*int64, error) {
 	row := q.db.QueryRow(ctx, resolveReport, arg.ID, arg.ResolvedBy, arg.Resolution)
-	var target_user_id int64
+	var target_user_id *int64
 	err := row.Scan(&target_user_id)
 	return target_user_id, err
 }
+
+const snapshotReportTargets = `-- name: SnapshotReportTargets :exec
+UPDATE reports r
+SET target_username = u.username
+FROM users u
+WHERE u.id = $1
+  AND r.target_user_id = u.id
+`
+
+// Keep an account's username on the reports against it, just before the
+// account is deleted and the reference with it (00036).
+func (q *Queries) SnapshotReportTargets(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, snapshotReportTargets, id)
+	return err
+}
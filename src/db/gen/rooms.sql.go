@@ -11,6 +11,42 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymiseRoomUIDs = `-- name: AnonymiseRoomUIDs :execrows
+UPDATE rooms SET
+    white_uid   = CASE WHEN white_uid = ANY($1::text[])
+                        AND (white_user_id IS NULL OR white_user_id = $2::bigint)
+                       THEN $3::text ELSE white_uid END,
+    black_uid   = CASE WHEN black_uid = ANY($1::text[])
+                        AND (black_user_id IS NULL OR black_user_id = $2::bigint)
+                       THEN $3::text ELSE black_uid END,
+    creator_uid = CASE WHEN creator_uid = ANY($1::text[])
+                        AND (creator_user_id IS NULL OR creator_user_id = $2::bigint)
+                       THEN $3::text ELSE creator_uid END
+WHERE white_uid = ANY($1::text[])
+   OR black_uid = ANY($1::text[])
+   OR creator_uid = ANY($1::text[])
+`
+
+type AnonymiseRoomUIDsParams struct {
+	Uids    []string
+	UserID  int64
+	AnonUid string
+}
+
+// AnonymiseGameUIDs for the rooms rows, which carry the seats as of their
+// latest game.
+func (q *Queries) AnonymiseRoomUIDs(ctx context.Context, arg AnonymiseRoomUIDsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, anonymiseRoomUIDs,
+		arg.Uids,
+		arg.UserID,
+		arg.AnonUid,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const closeRoom = `-- name: CloseRoom :exec
 UPDATE rooms SET closed_at = now()
 WHERE room_id = $1 AND closed_at IS NULL
@@ -56,16 +92,19 @@ func (q *Queries) GetRoom(ctx context.Context, roomID string) (Room, error) {
 }
 
 const roomIDExists = `-- name: RoomIDExists :one
-SELECT EXISTS(SELECT 1 FROM rooms WHERE room_id = $1)
+SELECT (EXISTS(SELECT 1 FROM rooms WHERE room_id = $1)
+        OR EXISTS(SELECT 1 FROM correspondence_games WHERE id = $1)) AS taken
 `
 
 // All-time room-ID uniqueness check for the room-creation re-roll loop: a new
 // room may never reuse the ID of any archived room (its permalink is forever).
+// A correspondence game holds its id from creation and archives under it, so
+// its ids are taken from the same space.
 func (q *Queries) RoomIDExists(ctx context.Context, roomID string) (bool, error) {
 	row := q.db.QueryRow(ctx, roomIDExists, roomID)
-	var exists bool
-	err := row.Scan(&exists)
-	return exists, err
+	var taken bool
+	err := row.Scan(&taken)
+	return taken, err
 }
 
 const upsertRoom = `-- name: UpsertRoom :exec
@@ -35,8 +35,23 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = $1
+`
+
+// Delete an account. What only it owns goes with it by cascade; what has to
+// outlive it (the archived games, the audit log) loses the reference instead
+// (00036).
+func (q *Queries) DeleteUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getUserByID = `-- name: GetUserByID :one
-SELECT u.id, u.created_at, u.username, u.email, u.password_hash, u.totp_secret_enc, u.totp_confirmed_at, u.webauthn_user_handle, u.username_changed_at, u.title_id, u.role, u.banned_until, u.ban_reason, u.broadcast_seen_at, t.code AS title_code, t.name AS title_name
+SELECT u.id, u.created_at, u.username, u.email, u.password_hash, u.totp_secret_enc, u.totp_confirmed_at, u.webauthn_user_handle, u.username_changed_at, u.title_id, u.role, u.banned_until, u.ban_reason, u.broadcast_seen_at, u.email_verified_at, t.code AS title_code, t.name AS title_name
 FROM users u
          LEFT JOIN titles t ON t.id = u.title_id
 WHERE u.id = $1
@@ -68,6 +83,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id int64) (GetUserByIDRow, er
 		&i.User.BannedUntil,
 		&i.User.BanReason,
 		&i.User.BroadcastSeenAt,
+		&i.User.EmailVerifiedAt,
 		&i.TitleCode,
 		&i.TitleName,
 	)
@@ -75,7 +91,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id int64) (GetUserByIDRow, er
 }
 
 const getUserByUsernameLower = `-- name: GetUserByUsernameLower :one
-SELECT u.id, u.created_at, u.username, u.email, u.password_hash, u.totp_secret_enc, u.totp_confirmed_at, u.webauthn_user_handle, u.username_changed_at, u.title_id, u.role, u.banned_until, u.ban_reason, u.broadcast_seen_at, t.code AS title_code, t.name AS title_name
+SELECT u.id, u.created_at, u.username, u.email, u.password_hash, u.totp_secret_enc, u.totp_confirmed_at, u.webauthn_user_handle, u.username_changed_at, u.title_id, u.role, u.banned_until, u.ban_reason, u.broadcast_seen_at, u.email_verified_at, t.code AS title_code, t.name AS title_name
 FROM users u
          LEFT JOIN titles t ON t.id = u.title_id
 WHERE lower(u.username) = lower($1)
@@ -108,6 +124,7 @@ func (q *Queries) GetUserByUsernameLower(ctx context.Context, lower string) (Get
 		&i.User.BannedUntil,
 		&i.User.BanReason,
 		&i.User.BroadcastSeenAt,
+		&i.User.EmailVerifiedAt,
 		&i.TitleCode,
 		&i.TitleName,
 	)
@@ -150,7 +167,10 @@ func (q *Queries) GetUsernameByID(ctx context.Context, id int64) (string, error)
 }
 
 const updateEmail = `-- name: UpdateEmail :exec
-UPDATE users SET email = $2 WHERE id = $1
+UPDATE users
+SET email             = $2,
+    email_verified_at = CASE WHEN email IS NOT DISTINCT FROM $2 THEN email_verified_at END
+WHERE id = $1
 `
 
 type UpdateEmailParams struct {
@@ -158,8 +178,9 @@ type UpdateEmailParams struct {
 	Email *string
 }
 
-// Set / replace / clear the account email ($2 NULL clears it). There is no
-// email infrastructure yet, so this is a plain overwrite — no verification.
+// Set / replace / clear the account email ($2 NULL clears it). Any change of
+// address clears its verification, which belongs to the old one; saving the
+// same address again keeps it.
 func (q *Queries) UpdateEmail(ctx context.Context, arg UpdateEmailParams) error {
 	_, err := q.db.Exec(ctx, updateEmail, arg.ID, arg.Email)
 	return err
@@ -0,0 +1,221 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/dechristopher/lio/db/gen"
+)
+
+// Email (package mail): the outbox and the account lookups around it. Reached
+// only behind mail.Enabled(), which requires Postgres, so these assume a live
+// pool like the rest of the accounts data plane.
+
+// Mail kinds. They match the CHECK constraint on mail_outbox.kind.
+const (
+	MailVerify = "verify"
+	MailReset  = "reset"
+	MailDigest = "digest"
+)
+
+// OutboundMail is one message to queue.
+type OutboundMail struct {
+	// UserID is the account the message is about, 0 for none.
+	UserID  int64
+	Kind    string
+	To      string
+	Subject string
+	Body    string
+	// Headers are extra message headers, e.g. List-Unsubscribe.
+	Headers map[string]string
+	// DedupeKey makes the message queue at most once; empty for a message only
+	// one request can cause.
+	DedupeKey string
+}
+
+// QueuedMail is a claimed outbox row on its way to the transport.
+type QueuedMail struct {
+	ID       int64
+	Kind     string
+	To       string
+	Subject  string
+	Body     string
+	Headers  map[string]string
+	Attempts int
+}
+
+// EnqueueMail writes a message to the outbox. queued is false, with no error,
+// when its dedupe key is already taken.
+func EnqueueMail(m OutboundMail) (queued bool, err error) {
+	headers := []byte("{}")
+	if len(m.Headers) > 0 {
+		if headers, err = json.Marshal(m.Headers); err != nil {
+			return false, err
+		}
+	}
+	var userID *int64
+	if m.UserID != 0 {
+		userID = &m.UserID
+	}
+	var dedupe *string
+	if m.DedupeKey != "" {
+		dedupe = &m.DedupeKey
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	_, err = gen.New(Pool).EnqueueMail(ctx, gen.EnqueueMailParams{
+		UserID:    userID,
+		Kind:      m.Kind,
+		ToAddr:    m.To,
+		Subject:   m.Subject,
+		Body:      m.Body,
+		Headers:   headers,
+		DedupeKey: dedupe,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ClaimDueMail takes up to batch due messages that have failed fewer than
+// maxAttempts times, leasing them to the caller until leaseUntil.
+func ClaimDueMail(leaseUntil time.Time, maxAttempts, batch int) ([]QueuedMail, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ClaimDueMail(ctx, gen.ClaimDueMailParams{
+		LeaseUntil:  pgtype.Timestamptz{Time: leaseUntil, Valid: true},
+		MaxAttempts: int32(maxAttempts),
+		Batch:       int32(batch),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]QueuedMail, 0, len(rows))
+	for _, r := range rows {
+		m := QueuedMail{
+			ID:       r.ID,
+			Kind:     r.Kind,
+			To:       r.ToAddr,
+			Subject:  r.Subject,
+			Body:     r.Body,
+			Attempts: int(r.Attempts),
+		}
+		// the column is only ever written by EnqueueMail, from a map
+		_ = json.Unmarshal(r.Headers, &m.Headers)
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// MarkMailSent records a delivered message.
+func MarkMailSent(id int64) error {
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).MarkMailSent(ctx, id)
+}
+
+// MarkMailFailed records a failed attempt and when to try again.
+func MarkMailFailed(id int64, reason string, next time.Time) error {
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).MarkMailFailed(ctx, gen.MarkMailFailedParams{
+		ID:            id,
+		LastError:     reason,
+		NextAttemptAt: pgtype.Timestamptz{Time: next, Valid: true},
+	})
+}
+
+// PruneMail deletes the messages created before cutoff that were sent or gave
+// up, and returns how many.
+func PruneMail(cutoff time.Time, maxAttempts int) (int64, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).PruneMail(ctx, gen.PruneMailParams{
+		Cutoff:      pgtype.Timestamptz{Time: cutoff, Valid: true},
+		MaxAttempts: int32(maxAttempts),
+	})
+}
+
+// DigestRecipient is an account owed a weekly digest.
+type DigestRecipient struct {
+	ID       int64
+	Username string
+	Email    string
+}
+
+// DigestRecipients returns up to batch accounts, by id after afterID, that
+// opted into the digest, hold a verified address, and have unread
+// notifications from after since with no digest queued after it.
+func DigestRecipients(afterID int64, since time.Time, batch int) ([]DigestRecipient, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).DigestRecipients(ctx, gen.DigestRecipientsParams{
+		AfterID: afterID,
+		Since:   pgtype.Timestamptz{Time: since, Valid: true},
+		Batch:   int32(batch),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DigestRecipient, 0, len(rows))
+	for _, r := range rows {
+		if r.Email == nil {
+			continue
+		}
+		out = append(out, DigestRecipient{ID: r.ID, Username: r.Username, Email: *r.Email})
+	}
+	return out, nil
+}
+
+// EmailAccount is an account found by its address, with what a password reset
+// link is built from.
+type EmailAccount struct {
+	ID           int64
+	Username     string
+	Email        string
+	PasswordHash string
+}
+
+// UsersByEmail returns the accounts holding an address, case-insensitively,
+// that have verified it.
+func UsersByEmail(addr string) ([]EmailAccount, error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListUsersByEmail(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]EmailAccount, 0, len(rows))
+	for _, r := range rows {
+		if r.Email == nil {
+			continue
+		}
+		out = append(out, EmailAccount{
+			ID:           r.ID,
+			Username:     r.Username,
+			Email:        *r.Email,
+			PasswordHash: r.PasswordHash,
+		})
+	}
+	return out, nil
+}
+
+// MarkEmailVerified stamps an account's address verified. ok is false when
+// the account no longer holds that address.
+func MarkEmailVerified(id int64, addr string) (ok bool, err error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	n, err := gen.New(Pool).MarkEmailVerified(ctx, gen.MarkEmailVerifiedParams{
+		ID:    id,
+		Email: &addr,
+	})
+	return n > 0, err
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// TestUsersByEmailVerifiedOnly: the forgotten-password lookup does not find an
+// account by an address it never verified, and does once it has.
+func TestUsersByEmailVerifiedOnly(t *testing.T) {
+	skipNoDB(t)
+
+	addr := "rst" + uuid.NewString()[:6] + "@example.invalid"
+	id := mkFollowUser(t, "rst")
+	if err := UpdateEmail(id, &addr); err != nil {
+		t.Fatalf("set email: %v", err)
+	}
+
+	found, err := UsersByEmail(addr)
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if len(found) != 0 {
+		t.Fatalf("unverified address found %+v", found)
+	}
+
+	if ok, err := MarkEmailVerified(id, addr); err != nil || !ok {
+		t.Fatalf("verify: ok=%v err=%v", ok, err)
+	}
+	found, err = UsersByEmail(addr)
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != id {
+		t.Fatalf("verified address found %+v, want account %d", found, id)
+	}
+}
@@ -0,0 +1,32 @@
+-- +goose Up
+
+-- The monthly PGN database: one row per published dump, written by the dump
+-- job (the dump package) after it uploads a month's compressed PGN to the
+-- object store. The /db page lists straight from this table, so a month only
+-- appears once its file actually exists.
+--
+-- A month is a calendar month in UTC, keyed "YYYY-MM" and grouped by
+-- games.start_ts — the column the BRIN index already covers, and the date the
+-- PGN Date tag carries, so a game is found in the dump its own tags name.
+--
+-- The rows are a catalogue, not the data: the games table stays the source of
+-- truth and a dump is rebuilt from it whenever the month's archived game count
+-- moves (the --backfill replay can land games in a month long closed).
+CREATE TABLE pgn_dumps (
+    month      TEXT        PRIMARY KEY CHECK (month ~ '^[0-9]{4}-[0-9]{2}$'),
+    -- The key in the dump bucket (lio_obj_bucket_db), e.g.
+    -- "lio_octad_db_2026-09.pgn.gz". Stored rather than derived so a change of
+    -- naming never orphans a file that is already published.
+    object_key TEXT        NOT NULL,
+    -- How many games the file holds; compared against a fresh per-month count
+    -- to decide whether the dump is stale.
+    games      BIGINT      NOT NULL,
+    -- Compressed size (what a download costs) and the uncompressed PGN size
+    -- (what it unpacks to), both in bytes.
+    bytes      BIGINT      NOT NULL,
+    raw_bytes  BIGINT      NOT NULL,
+    built_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS pgn_dumps;
@@ -0,0 +1,27 @@
+-- +goose Up
+
+-- Exact endgame results. Positions the endgame tablebase covers (see the
+-- tablebase package) are solved, not estimated: the evaluator stores the
+-- tablebase's result in place of its search — eval_cp saturated to ±32000 for
+-- a forced mate and 0 for a draw — with eval_exact set, and the distance to
+-- mate in eval_mate: white-positive full moves, so 3 is "white mates in 3" and
+-- -2 "black mates in 2". NULL when there is no forced mate (a draw, or an
+-- ordinary search eval).
+ALTER TABLE positions
+    ADD COLUMN eval_mate  SMALLINT,
+    ADD COLUMN eval_exact BOOLEAN NOT NULL DEFAULT false;
+
+-- Re-queue the small endgames the evaluator already searched, so they pick up
+-- their exact result: four pieces or fewer (the default tablebase) and no
+-- castling rights (the tables assume none). Anything the running set does not
+-- cover is simply searched again.
+UPDATE positions
+SET eval_cp = NULL, eval_depth = NULL, best_move = NULL, evaluated_at = NULL
+WHERE eval_cp IS NOT NULL
+  AND split_part(ofen, ' ', 3) = '-'
+  AND length(regexp_replace(split_part(ofen, ' ', 1), '[^A-Za-z]', '', 'g')) <= 4;
+
+-- +goose Down
+ALTER TABLE positions
+    DROP COLUMN IF EXISTS eval_exact,
+    DROP COLUMN IF EXISTS eval_mate;
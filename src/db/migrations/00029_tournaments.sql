@@ -0,0 +1,91 @@
+-- +goose Up
+
+-- Tournaments: Arena (time-boxed, continuous pairing) and Swiss (fixed rounds)
+-- events run by the tournament package. The package keeps each open event in
+-- memory and writes through to these tables as it goes, so a restart resumes
+-- an event from here: the row carries its configuration and progress, the
+-- players table its standings, and the pairings table every game it has paired
+-- (the history Swiss needs to avoid rematches and balance colours).
+CREATE TABLE tournaments (
+    -- Base58, like room ids; also the /tournament/<id> path segment.
+    id            TEXT        PRIMARY KEY,
+    name          TEXT        NOT NULL,
+    format        TEXT        NOT NULL CHECK (format IN ('arena', 'swiss')),
+    -- The pools.Map key (variant HTMLName) every game is played at.
+    variant       TEXT        NOT NULL,
+    rated         BOOLEAN     NOT NULL DEFAULT false,
+    -- Arena only: players may halve their clock for a bonus point on a win.
+    berserk       BOOLEAN     NOT NULL DEFAULT false,
+    -- Swiss: the number of rounds. Arena: 0.
+    rounds        SMALLINT    NOT NULL DEFAULT 0,
+    -- Arena: the event's length from its start. Swiss: 0.
+    duration_secs INT         NOT NULL DEFAULT 0,
+    status        TEXT        NOT NULL DEFAULT 'created'
+                              CHECK (status IN ('created', 'started', 'finished')),
+    -- Swiss: the round in progress (0 before the first pairing).
+    round         SMALLINT    NOT NULL DEFAULT 0,
+    created_by    BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    starts_at     TIMESTAMPTZ NOT NULL,
+    started_at    TIMESTAMPTZ,
+    finished_at   TIMESTAMPTZ
+);
+
+-- Boot restore reads the unfinished events; the /tournament list the rest.
+CREATE INDEX tournaments_open_idx ON tournaments (starts_at) WHERE status <> 'finished';
+CREATE INDEX tournaments_starts_idx ON tournaments (starts_at DESC);
+
+-- Standings: one row per entrant, rewritten whenever their score moves.
+CREATE TABLE tournament_players (
+    tournament_id TEXT        NOT NULL REFERENCES tournaments (id) ON DELETE CASCADE,
+    user_id       BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- Rating at entry, which seeds the Swiss pairing order.
+    rating        INT         NOT NULL,
+    -- Arena points are whole; Swiss scores run in halves.
+    score         REAL        NOT NULL DEFAULT 0,
+    -- Arena: performance rating. Swiss: Buchholz.
+    tiebreak      REAL        NOT NULL DEFAULT 0,
+    wins          SMALLINT    NOT NULL DEFAULT 0,
+    draws         SMALLINT    NOT NULL DEFAULT 0,
+    losses        SMALLINT    NOT NULL DEFAULT 0,
+    -- One mark per game in order, the score sheet the standings table prints:
+    -- Swiss "1", "=", "0", "+" for a bye and "-" for a forfeit; Arena the
+    -- points each game earned (see tournament/standings.go).
+    sheet         TEXT        NOT NULL DEFAULT '',
+    rank          SMALLINT    NOT NULL DEFAULT 0,
+    withdrawn     BOOLEAN     NOT NULL DEFAULT false,
+    joined_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tournament_id, user_id)
+);
+
+-- Every pairing the event has made, in order. black_user_id is NULL for a
+-- Swiss bye, and room_id empty. result is '' while the game is on, then
+-- 'w', 'b' or 'd', or 'v' for a game that never finished (its room was lost).
+CREATE TABLE tournament_pairings (
+    id            BIGSERIAL   PRIMARY KEY,
+    tournament_id TEXT        NOT NULL REFERENCES tournaments (id) ON DELETE CASCADE,
+    round         SMALLINT    NOT NULL DEFAULT 0,
+    room_id       TEXT        NOT NULL DEFAULT '',
+    white_user_id BIGINT      NOT NULL,
+    black_user_id BIGINT,
+    result        TEXT        NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX tournament_pairings_idx ON tournament_pairings (tournament_id, id);
+
+-- The archived games of an event. NULL for every other game, so the index is
+-- partial and costs nothing outside tournaments.
+ALTER TABLE games
+    ADD COLUMN tournament_id TEXT REFERENCES tournaments (id) ON DELETE SET NULL;
+
+CREATE INDEX games_tournament_idx ON games (tournament_id, start_ts)
+    WHERE tournament_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS games_tournament_idx;
+ALTER TABLE games
+    DROP COLUMN IF EXISTS tournament_id;
+DROP TABLE IF EXISTS tournament_pairings;
+DROP TABLE IF EXISTS tournament_players;
+DROP TABLE IF EXISTS tournaments;
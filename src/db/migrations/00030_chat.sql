@@ -0,0 +1,31 @@
+-- +goose Up
+
+-- In-game chat (the chat package): every line said in a room, on either of its
+-- two channels, kept so a moderator working a report that names one of the
+-- room's games can read what was said around it. Keyed by room rather than by
+-- game because the conversation is: it runs across a match's games and its
+-- rematches, and a report's game resolves to its room through games.room_id.
+CREATE TABLE chat_messages (
+    id         BIGSERIAL   PRIMARY KEY,
+    room_id    TEXT        NOT NULL,
+    -- 'player' is the two seats talking to each other; 'spectator' is the
+    -- crowd. Neither side reads the other's.
+    channel    TEXT        NOT NULL CHECK (channel IN ('player', 'spectator')),
+    -- The speaker's account, NULL for an anonymous player. uid is kept either
+    -- way, so an anonymous session's lines can still be told apart.
+    user_id    BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    uid        TEXT        NOT NULL,
+    -- The name shown at the time, so the log reads as the room did even after
+    -- a rename or a deleted account.
+    username   TEXT        NOT NULL DEFAULT '',
+    body       TEXT        NOT NULL,
+    -- A line the word filter withheld. It was never delivered, but it is kept:
+    -- it is exactly the evidence a report about abuse needs.
+    filtered   BOOLEAN     NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX chat_messages_room_idx ON chat_messages (room_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS chat_messages;
@@ -0,0 +1,61 @@
+-- +goose Up
+
+-- Training puzzles (the puzzle package), mined from archived games: a position
+-- where the side to move has just been handed a win — the opponent's last move
+-- threw the game away, or walked into a forced mate — and exactly one move
+-- cashes it in. The miner finds them from the evaluator's cached evals and
+-- keeps only those the engine confirms have a unique solution.
+CREATE TABLE puzzles (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    -- The puzzle's starting position. One puzzle per distinct position, so a
+    -- position reached in many games is mined once.
+    position_id INT              NOT NULL UNIQUE REFERENCES positions (id),
+    -- The game it was found in, for "see the game" links. Kept loose: a
+    -- puzzle stands on its own once mined.
+    game_ref    INT              REFERENCES games (id) ON DELETE SET NULL,
+    ofen        TEXT             NOT NULL,
+    -- The opponent's move that led here (UOI), shown as the board's last move.
+    last_move   TEXT             NOT NULL DEFAULT '',
+    -- Space-separated UOI moves, solver first, alternating with the replies
+    -- the solver has to meet, always ending on a solver move.
+    solution    TEXT             NOT NULL,
+    -- 'mate' when the solution mates, 'advantage' when it wins decisively.
+    theme       TEXT             NOT NULL CHECK (theme IN ('mate', 'advantage')),
+    -- The puzzle's own Glicko-2 rating: every first attempt is a game it
+    -- plays against the solver.
+    rating      DOUBLE PRECISION NOT NULL,
+    rd          DOUBLE PRECISION NOT NULL,
+    volatility  DOUBLE PRECISION NOT NULL,
+    plays       INTEGER          NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+-- the trainer serves puzzles nearest a player's rating
+CREATE INDEX puzzles_rating_idx ON puzzles (rating);
+
+-- A player's puzzle rating. Its own table rather than a ratings category: the
+-- ratings table is the time-control ladder, and every reader of it (profile
+-- tiles, rating history, the account popover) assumes a category is one.
+CREATE TABLE puzzle_ratings (
+    user_id    BIGINT           PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    rating     DOUBLE PRECISION NOT NULL,
+    rd         DOUBLE PRECISION NOT NULL,
+    volatility DOUBLE PRECISION NOT NULL,
+    plays      INTEGER          NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+-- Every puzzle a signed-in player has finished, solved or not. Only the first
+-- attempt is rated, and a puzzle once seen is not served to them again.
+CREATE TABLE puzzle_attempts (
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    puzzle_id  BIGINT      NOT NULL REFERENCES puzzles (id) ON DELETE CASCADE,
+    solved     BOOLEAN     NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, puzzle_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS puzzle_attempts;
+DROP TABLE IF EXISTS puzzle_ratings;
+DROP TABLE IF EXISTS puzzles;
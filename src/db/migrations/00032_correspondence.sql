@@ -0,0 +1,82 @@
+-- +goose Up
+
+-- Correspondence games: days per move rather than a running clock (the
+-- correspondence package). A realtime room lives in its actor's memory, with a
+-- Redis snapshot to outlive a deploy; a game that lasts weeks cannot depend on
+-- either, so this row *is* the game. Every move is a locked read-modify-write
+-- of it, and nothing about the game is held anywhere else between moves.
+--
+-- A finished game is archived to games like any other, under this row's id as
+-- its room id, so the /<id>/1 permalink serves it from then on. The row stays
+-- behind as the record of how the game ended.
+CREATE TABLE correspondence_games (
+    -- Base58, drawn from the room id space so the archived game's permalink
+    -- cannot collide with a room's.
+    id              TEXT        PRIMARY KEY,
+    -- The pools.Map key (variant HTMLName); the variant fixes the days per move.
+    variant         TEXT        NOT NULL,
+    rated           BOOLEAN     NOT NULL DEFAULT false,
+    creator_user_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- The seats. The creator's is filled at creation and the other when
+    -- somebody accepts; an open game has exactly one.
+    white_user_id   BIGINT      REFERENCES users (id) ON DELETE CASCADE,
+    black_user_id   BIGINT      REFERENCES users (id) ON DELETE CASCADE,
+    -- A direct challenge: only this account may take the open seat. NULL for a
+    -- seek anybody may accept.
+    invited_user_id BIGINT      REFERENCES users (id) ON DELETE CASCADE,
+    -- The session uids each seat was taken with, and the creator's, for the
+    -- archived row's uid columns.
+    creator_uid     TEXT        NOT NULL DEFAULT '',
+    white_uid       TEXT        NOT NULL DEFAULT '',
+    black_uid       TEXT        NOT NULL DEFAULT '',
+    status          TEXT        NOT NULL DEFAULT 'open'
+                                CHECK (status IN ('open', 'active', 'finished', 'aborted')),
+    -- The archived game's games.game_id, fixed at creation.
+    game_id         UUID        NOT NULL,
+    starting_ofen   TEXT        NOT NULL,
+    -- The moves so far, UOI, in order.
+    moves           TEXT[]      NOT NULL DEFAULT '{}',
+    -- The side with a standing draw offer, if any.
+    draw_offer      TEXT        CHECK (draw_offer IN ('w', 'b')),
+    -- When the side to move loses on time. NULL unless the game is active.
+    deadline        TIMESTAMPTZ,
+    outcome         TEXT        NOT NULL DEFAULT '*',
+    -- The games.reason token of a finished game.
+    reason          TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at      TIMESTAMPTZ,
+    last_move_at    TIMESTAMPTZ,
+    finished_at     TIMESTAMPTZ
+);
+
+-- The scheduler's sweep: active games past their deadline.
+CREATE INDEX correspondence_games_deadline_idx ON correspondence_games (deadline)
+    WHERE status = 'active';
+-- A player's games, from either seat.
+CREATE INDEX correspondence_games_white_idx ON correspondence_games (white_user_id);
+CREATE INDEX correspondence_games_black_idx ON correspondence_games (black_user_id);
+CREATE INDEX correspondence_games_invited_idx ON correspondence_games (invited_user_id)
+    WHERE status = 'open';
+-- The open seeks the /correspondence page lists.
+CREATE INDEX correspondence_games_open_idx ON correspondence_games (created_at DESC)
+    WHERE status = 'open' AND invited_user_id IS NULL;
+
+-- It is your move: the notification that replaces the socket a correspondence
+-- game does not hold open. Durable like a follow, with no expiry and no action;
+-- its link is the game.
+ALTER TABLE notifications
+    DROP CONSTRAINT notifications_kind_check;
+ALTER TABLE notifications
+    ADD CONSTRAINT notifications_kind_check
+        CHECK (kind IN ('mod_action', 'milestone', 'system', 'challenge', 'follow',
+                        'correspondence'));
+
+-- +goose Down
+DELETE FROM notifications WHERE kind = 'correspondence';
+ALTER TABLE notifications
+    DROP CONSTRAINT notifications_kind_check;
+ALTER TABLE notifications
+    ADD CONSTRAINT notifications_kind_check
+        CHECK (kind IN ('mod_action', 'milestone', 'system', 'challenge', 'follow'));
+
+DROP TABLE IF EXISTS correspondence_games;
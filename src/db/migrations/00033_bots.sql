@@ -0,0 +1,32 @@
+-- +goose Up
+
+-- Bot accounts: ordinary accounts an admin has flagged for an external engine
+-- to play through (www/handlers/handle_bot.go). The flag lives in its own
+-- table rather than as columns on users, since a handful of rows need it and
+-- every users read would otherwise carry it.
+--
+-- A bot never logs in. Its program presents a bearer token on the socket and
+-- the bot API, and only the token's SHA-256 is stored, like a session's: a
+-- leaked table does not leak a working credential.
+CREATE TABLE bot_accounts (
+    user_id          BIGINT      PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- The seat identity every connection the bot makes takes, in the session
+    -- uid shape. Sessions mint a uid each; a bot holds one for good, so the
+    -- socket it plays on is the seat it accepted with however often it
+    -- reconnects.
+    uid              TEXT        NOT NULL UNIQUE,
+    -- NULL until the owner issues a token, and replaced on every reissue,
+    -- which is what revokes the last one.
+    token_hash       BYTEA       UNIQUE,
+    token_created_at TIMESTAMPTZ
+);
+
+-- The title a flagged account wears, so nobody mistakes the program across the
+-- board for a person. ON CONFLICT: an operator may already have added one.
+INSERT INTO titles (code, name)
+VALUES ('BOT', 'Bot account')
+ON CONFLICT (lower(code)) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS bot_accounts;
@@ -0,0 +1,24 @@
+-- +goose Up
+
+-- Personal API tokens: a person's own scripts calling the JSON API as them,
+-- without a browser. Each token carries the scopes it was created with
+-- (auth/tokens.go) and reaches only the endpoints those name; the plaintext is
+-- shown once at creation and only its SHA-256 is stored, like a recovery code.
+CREATE TABLE api_tokens (
+    id           BIGSERIAL   PRIMARY KEY,
+    user_id      BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- What the owner called it, so the list says which script is which.
+    name         TEXT        NOT NULL,
+    token_hash   BYTEA       NOT NULL UNIQUE,
+    scopes       TEXT[]      NOT NULL,
+    -- The seat identity a token's socket takes, in the session uid shape: a
+    -- token has no session to borrow one from.
+    uid          TEXT        NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX api_tokens_user_idx ON api_tokens (user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_tokens;
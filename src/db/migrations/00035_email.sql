@@ -0,0 +1,45 @@
+-- +goose Up
+
+-- Email (package mail): verification of the address an account holds, and the
+-- outbox every message is sent through.
+
+-- When the account's current address was proven to reach its owner. Cleared by
+-- any change of address (UpdateEmail), so it always describes the address in
+-- the email column, never a previous one.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+-- Every message the site sends is a row here first, and a send is an update of
+-- that row. A failed send stays unsent with a later next_attempt_at, so the
+-- worker retries it; a message that keeps failing gives up after
+-- mail.maxAttempts and keeps its last error for whoever looks.
+--
+-- The body is stored rendered. A password reset link is in it, so the rows are
+-- pruned once sent (mail.keepSent) rather than kept as a history.
+CREATE TABLE mail_outbox (
+    id              BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- The account the message is about. It cascades: mail queued for an
+    -- account that no longer exists must not go out.
+    user_id         BIGINT      REFERENCES users (id) ON DELETE CASCADE,
+    kind            TEXT        NOT NULL CHECK (kind IN ('verify', 'reset', 'digest')),
+    to_addr         TEXT        NOT NULL,
+    subject         TEXT        NOT NULL,
+    body            TEXT        NOT NULL,
+    -- Headers beyond the standard set, e.g. List-Unsubscribe on a digest.
+    headers         JSONB       NOT NULL DEFAULT '{}',
+    -- Makes a message that more than one node may decide to queue — the weekly
+    -- digest — be queued once. NULL for a message only one request can cause.
+    dedupe_key      TEXT        UNIQUE,
+    attempts        INT         NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error      TEXT        NOT NULL DEFAULT '',
+    sent_at         TIMESTAMPTZ
+);
+
+-- The worker's queue: unsent rows by when they are due.
+CREATE INDEX mail_outbox_due_idx ON mail_outbox (next_attempt_at)
+    WHERE sent_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS mail_outbox;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
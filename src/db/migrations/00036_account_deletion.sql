@@ -0,0 +1,134 @@
+-- +goose Up
+
+-- Self-service account deletion (package privacy). Deleting the users row is
+-- the deletion: everything that is only the account's — sessions, ratings,
+-- follows, prefs, notifications, tokens, the MFA factors — already goes with
+-- it by ON DELETE CASCADE. What remains here is every reference that must
+-- outlive the account, and so far would have refused the delete instead.
+--
+-- The archive keeps the games, without the person. A seat whose account is
+-- gone reads exactly like a logged-out player's did: a session uid and no
+-- user id, which game.SeatIsBot still tells apart from the engine, and which
+-- db.ArchivedPGN names "Anonymous". So the rebuilt PGN stays a pure function
+-- of the row, and the stored object is rewritten to match it. The session
+-- uids themselves would still tie those seats, the rooms and the chat log
+-- (whose names 00030 kept past a deleted account) back to one person, so
+-- db.DeleteAccount replaces them with a fresh anonymous uid as it deletes.
+ALTER TABLE games
+    DROP CONSTRAINT games_white_user_id_fkey,
+    DROP CONSTRAINT games_black_user_id_fkey,
+    DROP CONSTRAINT games_creator_user_id_fkey,
+    ADD CONSTRAINT games_white_user_id_fkey
+        FOREIGN KEY (white_user_id) REFERENCES users (id) ON DELETE SET NULL,
+    ADD CONSTRAINT games_black_user_id_fkey
+        FOREIGN KEY (black_user_id) REFERENCES users (id) ON DELETE SET NULL,
+    ADD CONSTRAINT games_creator_user_id_fkey
+        FOREIGN KEY (creator_user_id) REFERENCES users (id) ON DELETE SET NULL;
+
+ALTER TABLE rooms
+    DROP CONSTRAINT rooms_white_user_id_fkey,
+    DROP CONSTRAINT rooms_black_user_id_fkey,
+    DROP CONSTRAINT rooms_creator_user_id_fkey,
+    ADD CONSTRAINT rooms_white_user_id_fkey
+        FOREIGN KEY (white_user_id) REFERENCES users (id) ON DELETE SET NULL,
+    ADD CONSTRAINT rooms_black_user_id_fkey
+        FOREIGN KEY (black_user_id) REFERENCES users (id) ON DELETE SET NULL,
+    ADD CONSTRAINT rooms_creator_user_id_fkey
+        FOREIGN KEY (creator_user_id) REFERENCES users (id) ON DELETE SET NULL;
+
+-- The audit log is never deleted from, and an account leaving does not change
+-- that: its entries stay, and only lose the name. 00015 made the actor
+-- mandatory so a moderator could not be deleted out from under the log; the
+-- log now survives that instead, and the deletion itself is an entry in it.
+ALTER TABLE mod_actions
+    ALTER COLUMN actor_user_id DROP NOT NULL,
+    DROP CONSTRAINT mod_actions_actor_user_id_fkey,
+    DROP CONSTRAINT mod_actions_target_user_id_fkey,
+    ADD CONSTRAINT mod_actions_actor_user_id_fkey
+        FOREIGN KEY (actor_user_id) REFERENCES users (id) ON DELETE SET NULL,
+    ADD CONSTRAINT mod_actions_target_user_id_fkey
+        FOREIGN KEY (target_user_id) REFERENCES users (id) ON DELETE SET NULL;
+
+-- A report against an account outlives it too. 00017 cascaded them, which made
+-- deleting the account the way to erase every complaint about it; now the
+-- reference goes, the name it had is kept beside the report (set at deletion,
+-- see db.DeleteAccount), and the queue's history still says who was reported
+-- and what was decided. privacy.Deletable refuses the deletion while a report
+-- is still open, so only decided reports are ever left without an account.
+ALTER TABLE reports
+    ADD COLUMN target_username TEXT,
+    ALTER COLUMN target_user_id DROP NOT NULL,
+    DROP CONSTRAINT reports_target_user_id_fkey,
+    ADD CONSTRAINT reports_target_user_id_fkey
+        FOREIGN KEY (target_user_id) REFERENCES users (id) ON DELETE SET NULL;
+
+-- who last changed a setting, resolved a report, or read feedback: the change,
+-- the resolution and the read all stand without knowing who
+ALTER TABLE settings
+    DROP CONSTRAINT settings_updated_by_fkey,
+    ADD CONSTRAINT settings_updated_by_fkey
+        FOREIGN KEY (updated_by) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE reports
+    DROP CONSTRAINT reports_resolved_by_fkey,
+    ADD CONSTRAINT reports_resolved_by_fkey
+        FOREIGN KEY (resolved_by) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE feedback
+    DROP CONSTRAINT feedback_read_by_fkey,
+    ADD CONSTRAINT feedback_read_by_fkey
+        FOREIGN KEY (read_by) REFERENCES users (id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE feedback
+    DROP CONSTRAINT feedback_read_by_fkey,
+    ADD CONSTRAINT feedback_read_by_fkey
+        FOREIGN KEY (read_by) REFERENCES users (id);
+ALTER TABLE reports
+    DROP CONSTRAINT reports_resolved_by_fkey,
+    ADD CONSTRAINT reports_resolved_by_fkey
+        FOREIGN KEY (resolved_by) REFERENCES users (id);
+ALTER TABLE settings
+    DROP CONSTRAINT settings_updated_by_fkey,
+    ADD CONSTRAINT settings_updated_by_fkey
+        FOREIGN KEY (updated_by) REFERENCES users (id);
+
+-- reports whose account was deleted cannot go back under NOT NULL
+DELETE FROM reports WHERE target_user_id IS NULL;
+ALTER TABLE reports
+    DROP CONSTRAINT reports_target_user_id_fkey,
+    ADD CONSTRAINT reports_target_user_id_fkey
+        FOREIGN KEY (target_user_id) REFERENCES users (id) ON DELETE CASCADE,
+    ALTER COLUMN target_user_id SET NOT NULL,
+    DROP COLUMN target_username;
+
+-- entries whose actor was deleted cannot go back under NOT NULL
+DELETE FROM mod_actions WHERE actor_user_id IS NULL;
+ALTER TABLE mod_actions
+    DROP CONSTRAINT mod_actions_target_user_id_fkey,
+    DROP CONSTRAINT mod_actions_actor_user_id_fkey,
+    ADD CONSTRAINT mod_actions_target_user_id_fkey
+        FOREIGN KEY (target_user_id) REFERENCES users (id),
+    ADD CONSTRAINT mod_actions_actor_user_id_fkey
+        FOREIGN KEY (actor_user_id) REFERENCES users (id),
+    ALTER COLUMN actor_user_id SET NOT NULL;
+
+ALTER TABLE rooms
+    DROP CONSTRAINT rooms_creator_user_id_fkey,
+    DROP CONSTRAINT rooms_black_user_id_fkey,
+    DROP CONSTRAINT rooms_white_user_id_fkey,
+    ADD CONSTRAINT rooms_white_user_id_fkey
+        FOREIGN KEY (white_user_id) REFERENCES users (id),
+    ADD CONSTRAINT rooms_black_user_id_fkey
+        FOREIGN KEY (black_user_id) REFERENCES users (id),
+    ADD CONSTRAINT rooms_creator_user_id_fkey
+        FOREIGN KEY (creator_user_id) REFERENCES users (id);
+
+ALTER TABLE games
+    DROP CONSTRAINT games_creator_user_id_fkey,
+    DROP CONSTRAINT games_black_user_id_fkey,
+    DROP CONSTRAINT games_white_user_id_fkey,
+    ADD CONSTRAINT games_white_user_id_fkey
+        FOREIGN KEY (white_user_id) REFERENCES users (id),
+    ADD CONSTRAINT games_black_user_id_fkey
+        FOREIGN KEY (black_user_id) REFERENCES users (id),
+    ADD CONSTRAINT games_creator_user_id_fkey
+        FOREIGN KEY (creator_user_id) REFERENCES users (id);
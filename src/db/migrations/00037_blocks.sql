@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- Blocks: one account refusing another. Like follows, one directed edge per
+-- row and no state beyond the row; unlike follows, the edge binds both ways
+-- (db/blocks.go) — neither side may challenge, follow or sit down opposite the
+-- other, and each side's chat lines are hidden from the other. The direction
+-- only records who may undo it.
+CREATE TABLE blocks (
+    blocker_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    blocked_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT blocks_not_self CHECK (blocker_id <> blocked_id)
+);
+
+-- The primary key answers "whom did I block"; this answers the reverse
+-- direction every symmetric check also has to ask.
+CREATE INDEX blocks_blocked_idx ON blocks (blocked_id, blocker_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS blocks;
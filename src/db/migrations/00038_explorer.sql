@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- The opening explorer (db/explorer.go) reaches a position either through the
+-- moves it occurs in (moves_position_id_idx) or, for a starting position, which
+-- has no moves row of its own, through the games that began from it. Every
+-- game starts from one of the 144 deploy matchups or the classic start, so the
+-- column is low-cardinality, but without an index each explorer request at a
+-- start would read the whole games table.
+CREATE INDEX games_starting_ofen_idx ON games (starting_ofen);
+
+-- +goose Down
+DROP INDEX IF EXISTS games_starting_ofen_idx;
@@ -0,0 +1,36 @@
+-- +goose Up
+
+-- Post-game analysis (package analysis): a finished game reviewed ply by ply at
+-- a fixed depth, each move judged by what it cost, and an accuracy score for
+-- each side. One row per game, and the row is both the request and the result:
+-- it is queued when the game is (automatically, or on somebody's request from
+-- the archive page), claimed by a worker the way the mail outbox is, and filled
+-- in once the review is done. The results live here rather than on the games
+-- row, which is written once and packed (fillfactor 100); a game nobody asked
+-- about costs nothing.
+CREATE TABLE game_analysis (
+    game_ref        INT         PRIMARY KEY REFERENCES games (id) ON DELETE CASCADE,
+    requested_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    attempts        INT         NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error      TEXT        NOT NULL DEFAULT '',
+    completed_at    TIMESTAMPTZ,
+    -- the search depth every position was reviewed at
+    depth           SMALLINT,
+    -- white-positive centipawns of the position after each ply, indexed ply-1
+    evals           SMALLINT[],
+    -- each ply's judgement (analysis.Judgement), indexed ply-1
+    judgements      SMALLINT[],
+    -- each side's accuracy (0-100) and average centipawn loss
+    white_accuracy  REAL,
+    black_accuracy  REAL,
+    white_acpl      SMALLINT,
+    black_acpl      SMALLINT
+);
+
+-- The worker's queue: unfinished rows by when they are due.
+CREATE INDEX game_analysis_due_idx ON game_analysis (next_attempt_at)
+    WHERE completed_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS game_analysis;
@@ -0,0 +1,54 @@
+-- +goose Up
+
+-- Fair play (package fairplay): how closely each account's rated play tracks
+-- the engine. A sample is one account's side of one reviewed game — how many
+-- of its judged moves were the engine's best, how many were within a whisker
+-- of it, what it gave away on average, and how evenly it spent its clock —
+-- recorded as the game's post-game review (game_analysis) completes.
+CREATE TABLE fair_play_samples (
+    game_ref       INT         NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+    user_id        BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- judged moves: out of the opening, not forced, not already decided
+    moves          SMALLINT    NOT NULL,
+    -- of those, the engine's own choice, and the ones costing nothing to speak of
+    engine_matches SMALLINT    NOT NULL,
+    top_matches    SMALLINT    NOT NULL,
+    acpl           SMALLINT    NOT NULL,
+    -- think time over the side's moves: mean, and its coefficient of variation
+    -- (NULL when too few moves were timed to say)
+    think_mean_ms  INT,
+    think_cv       REAL,
+    PRIMARY KEY (game_ref, user_id)
+);
+
+-- An account's recent samples, which its score is rolled from.
+CREATE INDEX fair_play_samples_user_idx ON fair_play_samples (user_id, created_at DESC);
+
+-- The rolling score per account, recomputed from its recent samples each time
+-- one lands, with the aggregates the score was built from so the moderation
+-- page can show its working. Nothing here acts on an account: a high score only
+-- puts it in front of a moderator. reviewed_score is the score a moderator last
+-- dismissed the account at; it stays off the list until it climbs past that.
+CREATE TABLE fair_play_scores (
+    user_id        BIGINT      PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    score          REAL        NOT NULL,
+    games          SMALLINT    NOT NULL,
+    match_rate     REAL        NOT NULL,
+    top_rate       REAL        NOT NULL,
+    acpl           REAL        NOT NULL,
+    think_cv       REAL,
+    -- standard deviation of the per-game match rates: low is consistent
+    match_spread   REAL,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    reviewed_at    TIMESTAMPTZ,
+    reviewed_by    BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    reviewed_score REAL
+);
+
+-- The moderation page's list: highest scores first.
+CREATE INDEX fair_play_scores_score_idx ON fair_play_scores (score DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS fair_play_scores;
+DROP TABLE IF EXISTS fair_play_samples;
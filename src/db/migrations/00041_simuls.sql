@@ -0,0 +1,59 @@
+-- +goose Up
+
+-- Simultaneous exhibitions run by the simul package: one host against a number
+-- of participants at once, the host on the same colour on every board. Like
+-- tournaments, an open simul lives in memory and writes through to these rows,
+-- so a restart resumes it; once finished the row is its results summary.
+CREATE TABLE simuls (
+    -- Base58, like room ids; also the /simul/<id> path segment.
+    id            TEXT        PRIMARY KEY,
+    name          TEXT        NOT NULL,
+    -- The pools.Map key (variant HTMLName) every board is played at.
+    variant       TEXT        NOT NULL,
+    host_user_id  BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    -- The host's name as it was, so a summary outlives a renamed account.
+    host_name     TEXT        NOT NULL,
+    host_color    TEXT        NOT NULL CHECK (host_color IN ('white', 'black')),
+    -- How many participants the host will take on.
+    max_boards    SMALLINT    NOT NULL,
+    status        TEXT        NOT NULL DEFAULT 'created'
+                              CHECK (status IN ('created', 'started', 'finished')),
+    -- The host's tally, from the host's side.
+    wins          SMALLINT    NOT NULL DEFAULT 0,
+    draws         SMALLINT    NOT NULL DEFAULT 0,
+    losses        SMALLINT    NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at    TIMESTAMPTZ,
+    finished_at   TIMESTAMPTZ
+);
+
+-- Boot restore reads the unfinished simuls.
+CREATE INDEX simuls_open_idx ON simuls (created_at) WHERE status <> 'finished';
+
+-- One row per participant. room_id is empty until the simul starts; result is
+-- '' while the board is on, then 'w', 'b' or 'd', or 'v' for a board that
+-- never finished (its room was lost, or nobody played).
+CREATE TABLE simul_boards (
+    simul_id   TEXT        NOT NULL REFERENCES simuls (id) ON DELETE CASCADE,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    username   TEXT        NOT NULL,
+    room_id    TEXT        NOT NULL DEFAULT '',
+    result     TEXT        NOT NULL DEFAULT '',
+    joined_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (simul_id, user_id)
+);
+
+-- The archived games of a simul, sharing its reference. NULL for every other
+-- game, so the index is partial and costs nothing outside simuls.
+ALTER TABLE games
+    ADD COLUMN simul_id TEXT REFERENCES simuls (id) ON DELETE SET NULL;
+
+CREATE INDEX games_simul_idx ON games (simul_id, start_ts)
+    WHERE simul_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS games_simul_idx;
+ALTER TABLE games
+    DROP COLUMN IF EXISTS simul_id;
+DROP TABLE IF EXISTS simul_boards;
+DROP TABLE IF EXISTS simuls;
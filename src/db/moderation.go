@@ -87,18 +87,14 @@ type ModAction struct {
 // before/after payload and may be nil.
 func LogModAction(actorID int64, targetID *int64, action string,
 	detail map[string]any, reason string) error {
-	raw := []byte("{}")
-	if len(detail) > 0 {
-		encoded, err := json.Marshal(detail)
-		if err != nil {
-			return err
-		}
-		raw = encoded
+	raw, err := encodeDetail(detail)
+	if err != nil {
+		return err
 	}
 	ctx, cancel := Ctx()
 	defer cancel()
-	_, err := gen.New(Pool).InsertModAction(ctx, gen.InsertModActionParams{
-		ActorUserID:  actorID,
+	_, err = gen.New(Pool).InsertModAction(ctx, gen.InsertModActionParams{
+		ActorUserID:  &actorID,
 		TargetUserID: targetID,
 		Action:       action,
 		Detail:       raw,
@@ -107,10 +103,19 @@ func LogModAction(actorID int64, targetID *int64, action string,
 	return err
 }
 
+// encodeDetail packs a mod_actions.detail payload; no payload is the empty
+// object the column defaults to.
+func encodeDetail(detail map[string]any) ([]byte, error) {
+	if len(detail) == 0 {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(detail)
+}
+
 // ModActionFilter narrows the audit feed. Both fields are optional: an empty
 // Action means every verb, an empty Query means no text filter.
 type ModActionFilter struct {
-	// Action is one verb (ban/unban/title/role/rename/setting).
+	// Action is one verb (ban/unban/title/role/rename/setting/bot/unbot/token-*).
 	Action string
 	// Query matches the reason or either party's username. One box rather than
 	// separate actor/target fields: "everything involving this account" is the
@@ -235,6 +240,26 @@ func UnbanUser(userID int64) error {
 	return gen.New(Pool).ClearUserBan(ctx, userID)
 }
 
+// BanStateOf reads an account's sanction status on its own, for a path that
+// has an account id but no user row: a live socket, which authenticated once at
+// upgrade time and may outlive a ban landing afterwards. An unknown account
+// reads as not banned.
+func BanStateOf(userID int64) (BanState, error) {
+	if Pool == nil {
+		return BanState{}, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetUserByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return BanState{}, nil
+	}
+	if err != nil {
+		return BanState{}, err
+	}
+	return banFrom(row.User.BannedUntil, row.User.BanReason), nil
+}
+
 // SetUserTitle assigns (or clears, with a nil titleID) an account's display
 // title by titles row id.
 func SetUserTitle(userID int64, titleID *int16) error {
@@ -31,14 +31,19 @@ const (
 	// KindFollow is a new follower (arch/FOLLOWING.md). Durable like a
 	// moderation decision: no expiry, no action, it simply waits to be read.
 	KindFollow = "follow"
+	// KindCorrespondence is news from a correspondence game: it is the
+	// recipient's move, or the game has ended. Durable, and its link is the
+	// game, since there is no live room to bring the news instead.
+	KindCorrespondence = "correspondence"
 )
 
 // NotificationKinds are the accepted kinds. They match the CHECK constraint in
-// migrations 00021, 00022 and 00024. Exported so a writer validates against the
-// same set the database accepts, rather than a second list that can drift from
-// it.
+// migrations 00021, 00022, 00024 and 00032. Exported so a writer validates
+// against the same set the database accepts, rather than a second list that can
+// drift from it.
 var NotificationKinds = []string{
 	KindModAction, KindMilestone, KindSystem, KindChallenge, KindFollow,
+	KindCorrespondence,
 }
 
 // ValidNotificationKind reports whether k is one of NotificationKinds.
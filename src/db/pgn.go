@@ -0,0 +1,69 @@
+package db
+
+import (
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db/gen"
+	"github.com/dechristopher/lio/engine"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/opening"
+)
+
+// ArchivedPGN rebuilds a finished game's canonical PGN from its archived row,
+// via the same game.BuildPGN the live archival path uses — so anything that
+// serves it (the archive page's copy button, the monthly database dumps) yields
+// byte-for-byte what was archived, without fetching the stored object. g must
+// be the row's replayed game (game.ReplayArchive). Per-ply %clk comments come
+// from the moves table; a game archived before timing was recorded (or with
+// Postgres unavailable) emits plain movetext. The opening/matchup names derive
+// purely from the starting OFEN.
+func ArchivedPGN(row gen.Game, g *octad.Game) string {
+	times, err := ListGameMoveTimes(row.ID)
+	if err != nil || len(times) != len(g.Moves()) {
+		times = nil
+	}
+	white, black, matchup, _ := opening.Names(row.StartingOfen)
+	persona := ""
+	if row.BotPersona != nil {
+		persona = *row.BotPersona
+	}
+	return game.BuildPGN(game.PGNMeta{
+		Site:           config.SiteOrigin(),
+		Variant:        row.VariantName,
+		Group:          row.VariantGroup,
+		White:          archivedSeatName(row.WhiteUid, row.WhiteUserID, persona),
+		Black:          archivedSeatName(row.BlackUid, row.BlackUserID, persona),
+		WhiteUID:       row.WhiteUid,
+		BlackUID:       row.BlackUid,
+		Result:         row.Outcome,
+		Reason:         row.Reason,
+		Start:          row.StartTs.Time,
+		End:            row.EndTs.Time,
+		StartOFEN:      row.StartingOfen,
+		WhiteFormation: white,
+		BlackFormation: black,
+		Matchup:        matchup,
+		// Event-tag situation inputs, straight off the row (the live path
+		// derives the same three from its archive record)
+		Rated:  row.Rated,
+		RaceTo: int(row.RaceTo),
+		VsBot: game.SeatIsBot(row.WhiteUid, row.WhiteUserID) ||
+			game.SeatIsBot(row.BlackUid, row.BlackUserID),
+		Tournament: archivedTournamentName(row.TournamentID),
+	}, g, times)
+}
+
+// archivedSeatName reproduces room.seatArchiveName from the archived row so the
+// rebuilt PGN's White/Black tags match what the live path wrote: "BOT <glyph>
+// <persona>" for the engine seat (no uid and no account; persona is the row's
+// bot_persona, NULL/"" resolving to the Queen), "<title> <username>" /
+// "<username>" for a logged-in human, else "Anonymous".
+func archivedSeatName(uid string, userID *int64, personaKey string) string {
+	if game.SeatIsBot(uid, userID) {
+		persona := engine.PersonaByKey(personaKey)
+		return game.PGNSeatName("", "", persona.Glyph, persona.Name, true)
+	}
+	name, seatTitle := UserDisplayForID(userID)
+	return game.PGNSeatName(name, seatTitle.Code, "", "", false)
+}
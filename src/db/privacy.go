@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+
+	"github.com/dechristopher/lio/db/gen"
+)
+
+// Reads and writes for a player's personal data (package privacy): the pages
+// of their games the export streams, and the account deletion. Everything else
+// an export carries is read through the accessor that already owns it.
+
+// AccountGamesPage returns up to limit of the games userID played, from either
+// seat, in id order strictly after afterID (0 for the first page). The caller's
+// context bounds the page: an export of a long history is one long job.
+func AccountGamesPage(ctx context.Context, userID int64, afterID int32, limit int32) ([]gen.Game, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	return gen.New(Pool).ListAccountGames(ctx, gen.ListAccountGamesParams{
+		UserID:    userID,
+		AfterID:   afterID,
+		BatchSize: limit,
+	})
+}
+
+// GamesByID fetches the archived rows with the given ids, in id order. Ids
+// with no row are skipped.
+func GamesByID(ids []int32) ([]gen.Game, error) {
+	if Pool == nil || len(ids) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).ListGamesByID(ctx, ids)
+}
+
+// DeleteAccount deletes an account in one transaction, and writes the audit
+// entry that says so in the same one. detail is that entry's payload; the
+// caller puts the username in it, because once the row is gone the entry is
+// the only place that still knows which account it was.
+//
+// The archived games stay, anonymised: the schema drops the user ids (00036),
+// and every session uid the account sat or spoke under is replaced here by
+// anonUID, on the games, the rooms and the chat log alike, so nothing left
+// joins them back into one person. One uid for all of them keeps a deleted
+// player's games reading as one anonymous player's, the way they were played.
+// The stored PGN objects still carry the name and the old uids; games lists
+// those rows so the caller can rewrite the objects. The published monthly dumps
+// that hold any of them are withdrawn here, before the rows lose what finds
+// them, for the dump job to rebuild.
+//
+// found is false when there was no such account, which also writes nothing.
+func DeleteAccount(userID int64, anonUID string, detail map[string]any) (games []int32, found bool, err error) {
+	ctx, cancel := Ctx()
+	defer cancel()
+	tx, err := Pool.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // no-op once Commit succeeds
+	q := gen.New(tx)
+
+	uids, err := q.AccountUIDs(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if games, err = q.AccountGameIDs(ctx, gen.AccountGameIDsParams{UserID: userID, Uids: uids}); err != nil {
+		return nil, false, err
+	}
+	if _, err = q.WithdrawAccountDumps(ctx, gen.WithdrawAccountDumpsParams{UserID: userID, Uids: uids}); err != nil {
+		return nil, false, err
+	}
+	if len(uids) > 0 {
+		if _, err = q.AnonymiseGameUIDs(ctx, gen.AnonymiseGameUIDsParams{Uids: uids, UserID: userID, AnonUid: anonUID}); err != nil {
+			return nil, false, err
+		}
+		if _, err = q.AnonymiseRoomUIDs(ctx, gen.AnonymiseRoomUIDsParams{Uids: uids, UserID: userID, AnonUid: anonUID}); err != nil {
+			return nil, false, err
+		}
+		if _, err = q.AnonymiseChatUIDs(ctx, gen.AnonymiseChatUIDsParams{AnonUid: anonUID, Uids: uids, UserID: userID}); err != nil {
+			return nil, false, err
+		}
+	}
+	if detail == nil {
+		detail = map[string]any{}
+	}
+	detail["games"] = len(games)
+	raw, err := encodeDetail(detail)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err = q.InsertModAction(ctx, gen.InsertModActionParams{
+		ActorUserID:  &userID,
+		TargetUserID: &userID,
+		Action:       "delete",
+		Detail:       raw,
+	}); err != nil {
+		return nil, false, err
+	}
+	if err = q.SnapshotReportTargets(ctx, userID); err != nil {
+		return nil, false, err
+	}
+	n, err := q.DeleteUser(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if n == 0 {
+		return nil, false, nil
+	}
+	return games, true, tx.Commit(ctx)
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dechristopher/lio/game"
+)
+
+// TestDeleteAccountReplacesUIDs deletes an account that played signed in and,
+// under the same session, before it signed in, and spoke in the room's chat.
+// Afterwards no games, rooms or chat_messages row carries its uid, nor does the
+// PGN rebuilt from any of its games: the anonymous uid stands in everywhere.
+func TestDeleteAccountReplacesUIDs(t *testing.T) {
+	skipNoDB(t)
+	ctx := context.Background()
+
+	email := "del" + uuid.NewString()[:6] + "@example.invalid"
+	userID, err := CreateUser("del"+time.Now().Format("150405.000000"), &email, "$argon2id$fake")
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	oldUID := "u_del" + uuid.NewString()[:8]
+	anonUID := "u_anon" + uuid.NewString()[:8]
+	roomID := "tstDl" + uuid.NewString()[:2]
+	t.Cleanup(func() {
+		_, _ = Pool.Exec(context.Background(), "DELETE FROM users WHERE id = $1", userID)
+		_, _ = Pool.Exec(context.Background(), "DELETE FROM chat_messages WHERE room_id = $1", roomID)
+		_, _ = Pool.Exec(context.Background(), "DELETE FROM games WHERE room_id = $1", roomID)
+		_, _ = Pool.Exec(context.Background(), "DELETE FROM rooms WHERE room_id = $1", roomID)
+	})
+
+	plies, blob, startOFEN := buildGamePlies(t, 4)
+	rec := GameRecord{
+		RoomID: roomID, Creator: oldUID, RaceTo: 2,
+		WhiteMatchScore: 1, Reason: "checkmate",
+		GameID: uuid.NewString(), StartTs: time.Now(), EndTs: time.Now(),
+		WhiteUID: oldUID, BlackUID: "u_other",
+		VariantName: "Test", VariantGroup: "blitz", Casual: true,
+		Outcome: "1-0", Method: 1, StartingOFEN: startOFEN,
+		Moves: blob, PGNObjectKey: "test/delete-" + rec1Key(),
+	}
+	// the first game before signing in, the second after, same session
+	if _, err := ArchiveGame(ctx, rec, plies); err != nil {
+		t.Fatalf("archive anonymous game: %v", err)
+	}
+	rec2 := rec
+	rec2.GameID = uuid.NewString()
+	rec2.CreatorUserID, rec2.WhiteUserID = &userID, &userID
+	rec2.WhiteMatchScore = 2
+	if _, err := ArchiveGame(ctx, rec2, plies); err != nil {
+		t.Fatalf("archive signed-in game: %v", err)
+	}
+	for _, uid := range []*int64{nil, &userID} {
+		if err := SaveChatLine(ChatLine{
+			RoomID: roomID, Channel: "player", UserID: uid, UID: oldUID,
+			Username: "someone", Body: "gg", Created: time.Now(),
+		}); err != nil {
+			t.Fatalf("save chat line: %v", err)
+		}
+	}
+
+	games, found, err := DeleteAccount(userID, anonUID, nil)
+	if err != nil || !found {
+		t.Fatalf("delete account: found=%v err=%v", found, err)
+	}
+	if len(games) != 2 {
+		t.Errorf("%d games to rewrite, want both", len(games))
+	}
+
+	for _, c := range []struct {
+		query string
+		args  []any
+	}{
+		{"SELECT count(*) FROM games WHERE $1 IN (white_uid, black_uid, creator_uid)", []any{oldUID}},
+		{"SELECT count(*) FROM rooms WHERE $1 IN (white_uid, black_uid, creator_uid)", []any{oldUID}},
+		{"SELECT count(*) FROM chat_messages WHERE uid = $1 OR (room_id = $2 AND username <> '')", []any{oldUID, roomID}},
+	} {
+		var n int64
+		if err := Pool.QueryRow(ctx, c.query, c.args...).Scan(&n); err != nil {
+			t.Fatalf("%s: %v", c.query, err)
+		}
+		if n != 0 {
+			t.Errorf("%d rows still carry the deleted account: %s", n, c.query)
+		}
+	}
+
+	rows, err := GamesByID(games)
+	if err != nil {
+		t.Fatalf("games by id: %v", err)
+	}
+	for _, row := range rows {
+		replayed, err := game.ReplayArchive(row.StartingOfen, row.Moves)
+		if err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+		pgn := ArchivedPGN(row, replayed)
+		if strings.Contains(pgn, oldUID) || !strings.Contains(pgn, `[WhiteUID "`+anonUID+`"]`) {
+			t.Errorf("rebuilt PGN of game %d:\n%s", row.ID, pgn)
+		}
+	}
+}
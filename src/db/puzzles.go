@@ -0,0 +1,289 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dechristopher/lio/db/gen"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/rating"
+)
+
+// Training puzzles data plane (see the puzzle package). The miner reads
+// archived games through GamesAfter and GamePlies and writes what it validates
+// with SavePuzzle; the trainer serves with PuzzlesNear and rates a finished
+// attempt with RecordPuzzleAttempt. Like the other accessors these are no-ops
+// without a live pool, so the trainer simply has nothing to serve.
+
+// PuzzlePly is one ply of an archived game as the miner sees it: the move, the
+// position it reached, and that position's cached eval (nil until the
+// background evaluator gets to it).
+type PuzzlePly struct {
+	Ply        int
+	Move       string // UOI
+	PositionID int32
+	OFEN       string
+	EvalCp     *int16 // white-positive centipawns
+	EvalMate   *int16 // white-positive moves to mate, exact results only
+}
+
+// Puzzle is one stored training puzzle.
+type Puzzle struct {
+	ID         int64
+	PositionID int32
+	GameRef    *int32
+	OFEN       string
+	LastMove   string
+	Solution   []string
+	Theme      string
+	Rating     rating.Rating
+	Created    time.Time
+}
+
+// PuzzleResult is a rated attempt's effect on the solver, for the trainer to
+// show: their new rating and its signed, rounded change.
+type PuzzleResult struct {
+	Display string
+	Delta   int
+}
+
+// LatestPuzzleGame returns the newest game a puzzle was mined from, 0 if none.
+func LatestPuzzleGame() (int32, error) {
+	if Pool == nil {
+		return 0, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).LatestPuzzleGame(ctx)
+}
+
+// GamesAfter returns up to n archived game refs after the cursor, oldest first.
+func GamesAfter(cursor int32, n int) ([]int32, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).ListGamesAfter(ctx, gen.ListGamesAfterParams{
+		ID:    cursor,
+		Limit: int32(n),
+	})
+}
+
+// GamePlies returns one archived game's plies with their cached evals.
+func GamePlies(gameRef int32) ([]PuzzlePly, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListGamePlyEvals(ctx, gameRef)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PuzzlePly, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, PuzzlePly{
+			Ply:        int(r.Ply),
+			Move:       game.UnpackMoveUOI(r.Mv),
+			PositionID: r.PositionID,
+			OFEN:       r.Ofen,
+			EvalCp:     r.EvalCp,
+			EvalMate:   r.EvalMate,
+		})
+	}
+	return out, nil
+}
+
+// PuzzleAt reports whether a position has already been mined, so the miner
+// spends no engine time validating it a second time.
+func PuzzleAt(positionID int32) (bool, error) {
+	if Pool == nil {
+		return false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).PuzzleAtPosition(ctx, positionID)
+}
+
+// SavePuzzle stores a validated puzzle. A position that already has one keeps
+// it.
+func SavePuzzle(p Puzzle) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).InsertPuzzle(ctx, gen.InsertPuzzleParams{
+		PositionID: p.PositionID,
+		GameRef:    p.GameRef,
+		Ofen:       p.OFEN,
+		LastMove:   p.LastMove,
+		Solution:   strings.Join(p.Solution, " "),
+		Theme:      p.Theme,
+		Rating:     p.Rating.R,
+		Rd:         p.Rating.RD,
+		Volatility: p.Rating.Sigma,
+	})
+}
+
+// GetPuzzle returns one puzzle, or nil when there is no such puzzle.
+func GetPuzzle(id int64) (*Puzzle, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetPuzzle(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := puzzleFrom(row)
+	return &p, nil
+}
+
+// PuzzlesNear returns up to n puzzles rated nearest r that the user has not
+// attempted. userID 0 is an anonymous player, for whom nothing is excluded.
+func PuzzlesNear(userID int64, r float64, n int) ([]Puzzle, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListPuzzlesNear(ctx, gen.ListPuzzlesNearParams{
+		UserID:  userID,
+		Rating:  r,
+		MaxRows: int32(n),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Puzzle, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, puzzleFrom(row))
+	}
+	return out, nil
+}
+
+// PuzzleRatingOf returns a player's puzzle rating, or the unrated default.
+func PuzzleRatingOf(userID int64) rating.Rating {
+	if Pool == nil {
+		return rating.New()
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetPuzzleRating(ctx, userID)
+	if err != nil {
+		return rating.New()
+	}
+	return rating.Rating{R: row.Rating, RD: row.Rd, Sigma: row.Volatility, Games: int(row.Plays)}
+}
+
+// RecordPuzzleAttempt records a signed-in player's finished attempt at a
+// puzzle. Their first attempt is a rated game between them and the puzzle —
+// a solve is the player's win — and both ratings move in one transaction; any
+// later attempt is recorded as nothing and returns a nil result.
+func RecordPuzzleAttempt(userID, puzzleID int64, solved bool) (*PuzzleResult, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+
+	tx, err := Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // no-op once Commit succeeds
+
+	q := gen.New(tx)
+	n, err := q.InsertPuzzleAttempt(ctx, gen.InsertPuzzleAttemptParams{
+		UserID:   userID,
+		PuzzleID: puzzleID,
+		Solved:   solved,
+	})
+	if err != nil || n == 0 {
+		return nil, err
+	}
+
+	// the player's row first, then the puzzle's: the one lock order every
+	// attempt takes (see GetPuzzleRatingForUpdate)
+	player, err := puzzleRatingForUpdate(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	row, err := q.GetPuzzleForUpdate(ctx, puzzleID)
+	if err != nil {
+		return nil, err
+	}
+	puzzle := rating.Rating{R: row.Rating, RD: row.Rd, Sigma: row.Volatility, Games: int(row.Plays)}
+
+	score := rating.Loss
+	if solved {
+		score = rating.Win
+	}
+	// both updates use the pre-attempt ratings, as a rated game does
+	newPlayer := player.Update(puzzle, score)
+	newPuzzle := puzzle.Update(player, rating.Win-score)
+
+	if err := q.UpsertPuzzleRating(ctx, gen.UpsertPuzzleRatingParams{
+		UserID:     userID,
+		Rating:     newPlayer.R,
+		Rd:         newPlayer.RD,
+		Volatility: newPlayer.Sigma,
+		Plays:      int32(newPlayer.Games),
+	}); err != nil {
+		return nil, err
+	}
+	if err := q.SetPuzzleRating(ctx, gen.SetPuzzleRatingParams{
+		ID:         puzzleID,
+		Rating:     newPuzzle.R,
+		Rd:         newPuzzle.RD,
+		Volatility: newPuzzle.Sigma,
+		Plays:      int32(newPuzzle.Games),
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &PuzzleResult{
+		Display: newPlayer.Display(),
+		Delta:   int(math.Round(newPlayer.R - player.R)),
+	}, nil
+}
+
+// puzzleRatingForUpdate row-locks and reads a player's puzzle rating,
+// defaulting to unrated when they have none yet.
+func puzzleRatingForUpdate(ctx context.Context, q *gen.Queries, userID int64) (rating.Rating, error) {
+	row, err := q.GetPuzzleRatingForUpdate(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rating.New(), nil
+	}
+	if err != nil {
+		return rating.Rating{}, err
+	}
+	return rating.Rating{R: row.Rating, RD: row.Rd, Sigma: row.Volatility, Games: int(row.Plays)}, nil
+}
+
+func puzzleFrom(row gen.Puzzle) Puzzle {
+	return Puzzle{
+		ID:         row.ID,
+		PositionID: row.PositionID,
+		GameRef:    row.GameRef,
+		OFEN:       row.Ofen,
+		LastMove:   row.LastMove,
+		Solution:   strings.Fields(row.Solution),
+		Theme:      row.Theme,
+		Rating:     rating.Rating{R: row.Rating, RD: row.Rd, Sigma: row.Volatility, Games: int(row.Plays)},
+		Created:    row.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,70 @@
+-- Post-game analysis (package analysis): the queue a finished game's review
+-- waits in, its results, and the accuracy a profile aggregates from them.
+
+-- name: EnqueueAnalysis :execrows
+-- Queue a game for review. A game already queued or reviewed is left alone —
+-- except one whose review gave up, which a fresh request starts over.
+INSERT INTO game_analysis (game_ref)
+VALUES (sqlc.arg(game_ref))
+ON CONFLICT (game_ref) DO UPDATE
+SET attempts        = 0,
+    next_attempt_at = now(),
+    last_error      = ''
+WHERE game_analysis.completed_at IS NULL
+  AND game_analysis.attempts >= sqlc.arg(max_attempts);
+
+-- name: ClaimDueAnalysis :many
+-- Take a batch of due reviews and push their next attempt out to the lease, as
+-- ClaimDueMail does: SKIP LOCKED gives two nodes' workers disjoint batches, and
+-- a review a dead worker held is retried once its lease runs out.
+UPDATE game_analysis
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE game_ref IN (SELECT a.game_ref
+                   FROM game_analysis a
+                   WHERE a.completed_at IS NULL
+                     AND a.next_attempt_at <= now()
+                     AND a.attempts < sqlc.arg(max_attempts)
+                   ORDER BY a.next_attempt_at
+                   LIMIT sqlc.arg(batch) FOR UPDATE SKIP LOCKED)
+RETURNING game_ref, attempts;
+
+-- name: GetAnalysisStart :one
+-- The position a reviewed game began from, and how it ended.
+SELECT starting_ofen, outcome
+FROM games
+WHERE id = $1;
+
+-- name: SaveAnalysis :exec
+UPDATE game_analysis
+SET completed_at   = now(),
+    attempts       = attempts + 1,
+    last_error     = '',
+    depth          = $2,
+    evals          = $3,
+    judgements     = $4,
+    white_accuracy = $5,
+    black_accuracy = $6,
+    white_acpl     = $7,
+    black_acpl     = $8
+WHERE game_ref = $1;
+
+-- name: MarkAnalysisFailed :exec
+UPDATE game_analysis
+SET attempts        = attempts + 1,
+    last_error      = $2,
+    next_attempt_at = $3
+WHERE game_ref = $1;
+
+-- name: GetGameAnalysis :one
+SELECT * FROM game_analysis WHERE game_ref = $1;
+
+-- name: AccuracyForUser :one
+-- An account's accuracy across its reviewed games: the mean of its own side's
+-- score in each, from its own perspective.
+SELECT count(*) AS games,
+       COALESCE(avg(CASE WHEN g.white_user_id = $1 THEN a.white_accuracy
+                         ELSE a.black_accuracy END), 0)::real AS accuracy
+FROM game_analysis a
+         JOIN games g ON g.id = a.game_ref
+WHERE a.completed_at IS NOT NULL
+  AND (g.white_user_id = $1 OR g.black_user_id = $1);
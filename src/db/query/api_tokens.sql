@@ -0,0 +1,35 @@
+-- Personal API tokens (auth/tokens.go): the owner's list, the bearer lookup,
+-- and revocation.
+
+-- name: CountAPITokens :one
+SELECT count(*) FROM api_tokens WHERE user_id = $1;
+
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (user_id, name, token_hash, scopes, uid)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id;
+
+-- name: DeleteAPIToken :one
+-- Scoped to the owner, so a guessed id revokes nothing. Returns the name for
+-- the audit entry.
+DELETE FROM api_tokens WHERE id = $1 AND user_id = $2
+RETURNING name;
+
+-- name: GetAPITokenByHash :one
+-- The bearer identity lookup, the token's counterpart of
+-- GetSessionByTokenHash: the account, its title and role, and its ban.
+SELECT a.id, a.user_id, a.name, a.scopes, a.uid, u.username,
+       t.code AS title_code, t.name AS title_name, u.role, u.banned_until
+FROM api_tokens a
+JOIN users u ON u.id = a.user_id
+LEFT JOIN titles t ON t.id = u.title_id
+WHERE a.token_hash = $1;
+
+-- name: ListAPITokens :many
+SELECT id, name, scopes, created_at, last_used_at
+FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: TouchAPIToken :exec
+UPDATE api_tokens SET last_used_at = now() WHERE id = $1;
@@ -0,0 +1,87 @@
+-- Blocks (db/blocks.go). An edge is written by its blocker but read both ways:
+-- every check that enforces a block asks "is there a row between these two, in
+-- either direction", which is one probe of the primary key and one of
+-- blocks_blocked_idx. Only the list and the cap are one-directional, because
+-- they are about the rows a person owns.
+--
+-- Unlike the follow lists, nothing here filters banned accounts. A ban hides an
+-- account from other people; a block list is the blocker's own record, and an
+-- entry that vanished for the length of a ban would read as an unblock.
+
+-- name: Block :execrows
+-- Idempotent, like Follow: 1 means a new block, 0 means it already stood.
+INSERT INTO blocks (blocker_id, blocked_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: Unblock :execrows
+-- Also idempotent. Only the blocker's own row goes: if the other side blocked
+-- back, their block stands on its own.
+DELETE
+FROM blocks
+WHERE blocker_id = $1
+  AND blocked_id = $2;
+
+-- name: DeleteFollowsBetween :execrows
+-- Both follow edges between two accounts, whichever exist. A block is not
+-- undone by an unblock in this respect: the follows stay gone.
+DELETE
+FROM follows
+WHERE (follower_id = sqlc.arg(user_a) AND followee_id = sqlc.arg(user_b))
+   OR (follower_id = sqlc.arg(user_b) AND followee_id = sqlc.arg(user_a));
+
+-- name: CountBlocks :one
+-- Rows this account owns, for the block cap. An index-only count of the
+-- primary key, cheap enough to run before every block.
+SELECT count(*) AS blocks
+FROM blocks
+WHERE blocker_id = $1;
+
+-- name: IsBlockedEither :one
+-- Has either account blocked the other? The question every enforcement point
+-- asks: a challenge, a seat, a follow.
+SELECT EXISTS (SELECT 1
+               FROM blocks
+               WHERE (blocker_id = sqlc.arg(user_a) AND blocked_id = sqlc.arg(user_b))
+                  OR (blocker_id = sqlc.arg(user_b) AND blocked_id = sqlc.arg(user_a))) AS blocked;
+
+-- name: IsBlocking :one
+-- Has $1 blocked $2? Directional, unlike IsBlockedEither: this is the state of
+-- the block button on a player page, which only the blocker can press back.
+SELECT EXISTS (SELECT 1
+               FROM blocks
+               WHERE blocker_id = $1
+                 AND blocked_id = $2) AS blocking;
+
+-- name: BlockedAmong :many
+-- Of these accounts, which share a block with this one, in either direction?
+-- Bounded by the size of the array, like FollowedAmong: chat asks it about the
+-- accounts in a channel, once per line.
+SELECT blocked_id AS id
+FROM blocks
+WHERE blocker_id = sqlc.arg(user_id)
+  AND blocked_id = ANY (sqlc.arg(ids)::bigint[])
+UNION
+SELECT blocker_id AS id
+FROM blocks
+WHERE blocked_id = sqlc.arg(user_id)
+  AND blocker_id = ANY (sqlc.arg(ids)::bigint[]);
+
+-- name: BlocksWithin :many
+-- Every block between two accounts of one set, each row as its blocker wrote
+-- it: a pairing pass (the queue, a tournament round) asks it once about the
+-- whole field rather than BlockedAmong once per player.
+SELECT blocker_id, blocked_id
+FROM blocks
+WHERE blocker_id = ANY (sqlc.arg(ids)::bigint[])
+  AND blocked_id = ANY (sqlc.arg(ids)::bigint[]);
+
+-- name: ListBlocks :many
+-- The accounts this one has blocked, newest first, for the account page.
+SELECT u.id, u.username, t.code AS title_code, t.name AS title_name, b.created_at
+FROM blocks b
+         JOIN users u ON u.id = b.blocked_id
+         LEFT JOIN titles t ON t.id = u.title_id
+WHERE b.blocker_id = $1
+ORDER BY b.created_at DESC, b.blocked_id DESC
+LIMIT $2;
@@ -0,0 +1,43 @@
+-- Bot accounts (www/handlers/handle_bot.go): the flag, the fixed seat uid,
+-- and the hash of the token the bot's program authenticates with.
+
+-- name: CreateBotAccount :exec
+-- Flagging twice is a no-op: the uid a bot already plays under must not move.
+INSERT INTO bot_accounts (user_id, uid)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: DeleteBotAccount :execrows
+DELETE FROM bot_accounts WHERE user_id = $1;
+
+-- name: GetBotAccount :one
+SELECT user_id, uid, token_created_at FROM bot_accounts WHERE user_id = $1;
+
+-- name: GetBotByTokenHash :one
+-- The bearer-token identity lookup, the bot's counterpart of
+-- GetSessionByTokenHash: the account, its title and role, and its ban, so a
+-- banned bot stops resolving like a banned player's session does.
+SELECT b.user_id, b.uid, u.username, t.code AS title_code, t.name AS title_name,
+       u.role, u.banned_until
+FROM bot_accounts b
+JOIN users u ON u.id = b.user_id
+LEFT JOIN titles t ON t.id = u.title_id
+WHERE b.token_hash = $1;
+
+-- name: SetBotToken :execrows
+-- Issue (or reissue, revoking the last) the account's token.
+UPDATE bot_accounts SET token_hash = $2, token_created_at = now() WHERE user_id = $1;
+
+-- name: SetBotTitle :exec
+-- Give a newly flagged account the BOT title, replacing any other.
+UPDATE users
+SET title_id = (SELECT id FROM titles WHERE lower(code) = 'bot')
+WHERE id = $1;
+
+-- name: ClearBotTitle :exec
+-- Take the BOT title off an account that is no longer a bot. Any other title
+-- a moderator has since given it stays.
+UPDATE users
+SET title_id = NULL
+WHERE id = $1
+  AND title_id = (SELECT id FROM titles WHERE lower(code) = 'bot');
@@ -0,0 +1,34 @@
+-- In-game chat (the chat package). Written as lines are said; read back when a
+-- socket joins a room, and by a moderator reading a reported game's room.
+
+-- name: InsertChatMessage :exec
+INSERT INTO chat_messages (room_id, channel, user_id, uid, username, body, filtered, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListRecentChat :many
+-- The tail of one channel as its readers saw it, newest first: what a socket
+-- joining the room is caught up with. Withheld lines were never delivered, so
+-- they are not replayed either.
+SELECT id, room_id, channel, user_id, uid, username, body, filtered, created_at
+FROM chat_messages
+WHERE room_id = $1
+  AND channel = $2
+  AND NOT filtered
+ORDER BY created_at DESC, id DESC
+LIMIT $3;
+
+-- name: ListRoomChat :many
+-- A room's whole log, both channels and withheld lines included, oldest first:
+-- the moderator's view.
+SELECT id, room_id, channel, user_id, uid, username, body, filtered, created_at
+FROM chat_messages
+WHERE room_id = $1
+ORDER BY created_at, id
+LIMIT $2;
+
+-- name: AnonymiseChatUIDs :execrows
+-- A deleted account's chat lines (AccountUIDs) keep what was said, under one
+-- anonymous uid and no name, which is how an anonymous player's lines read.
+UPDATE chat_messages SET uid = @anon_uid::text, username = ''
+WHERE uid = ANY(@uids::text[])
+  AND (user_id IS NULL OR user_id = @user_id::bigint);
@@ -0,0 +1,74 @@
+-- Correspondence games (the correspondence package). The row is the game:
+-- every action reads it FOR UPDATE, applies itself, and writes the whole
+-- progress back, so two moves at once serialize on the row.
+
+-- name: InsertCorrespondenceGame :exec
+INSERT INTO correspondence_games (id, variant, rated, creator_user_id,
+                                  white_user_id, black_user_id, invited_user_id,
+                                  creator_uid, white_uid, black_uid, game_id,
+                                  starting_ofen)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: GetCorrespondenceGame :one
+SELECT * FROM correspondence_games WHERE id = $1;
+
+-- name: GetCorrespondenceGameForUpdate :one
+SELECT * FROM correspondence_games WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateCorrespondenceGame :exec
+-- Progress only; the variant, the stakes and the creator never change.
+UPDATE correspondence_games
+SET white_user_id = $2,
+    black_user_id = $3,
+    white_uid     = $4,
+    black_uid     = $5,
+    status        = $6,
+    moves         = $7,
+    draw_offer    = $8,
+    deadline      = $9,
+    outcome       = $10,
+    reason        = $11,
+    started_at    = $12,
+    last_move_at  = $13,
+    finished_at   = $14
+WHERE id = $1;
+
+-- name: ListCorrespondenceGamesForUser :many
+-- A player's unfinished games from either seat, and the challenges waiting on
+-- them. The nearest deadline first: that is the game most in need of a move.
+SELECT * FROM correspondence_games
+WHERE status IN ('open', 'active')
+  AND (white_user_id = sqlc.arg(user_id)::BIGINT
+       OR black_user_id = sqlc.arg(user_id)::BIGINT
+       OR invited_user_id = sqlc.arg(user_id)::BIGINT)
+ORDER BY deadline NULLS LAST, created_at DESC;
+
+-- name: ListFinishedCorrespondenceGamesForUser :many
+SELECT * FROM correspondence_games
+WHERE status = 'finished'
+  AND (white_user_id = sqlc.arg(user_id)::BIGINT
+       OR black_user_id = sqlc.arg(user_id)::BIGINT)
+ORDER BY finished_at DESC
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListOpenCorrespondenceSeeks :many
+-- The seeks anybody may accept, newest first.
+SELECT * FROM correspondence_games
+WHERE status = 'open' AND invited_user_id IS NULL
+ORDER BY created_at DESC
+LIMIT $1;
+
+-- name: ListDueCorrespondenceGames :many
+-- The scheduler's sweep: active games whose side to move has run out of time.
+SELECT id FROM correspondence_games
+WHERE status = 'active' AND deadline <= $1
+ORDER BY deadline
+LIMIT $2;
+
+-- name: CountActiveCorrespondenceForUser :one
+-- How many correspondence games an account is seated in and still playing. An
+-- account cannot be deleted out of one: the row would go with it, and the
+-- opponent's game with the row.
+SELECT count(*) FROM correspondence_games
+WHERE status = 'active'
+  AND (white_user_id = @user_id::bigint OR black_user_id = @user_id::bigint);
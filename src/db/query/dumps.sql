@@ -0,0 +1,46 @@
+-- name: CountGamesByMonth :many
+-- Archived games per UTC calendar month (by start_ts), oldest first: the dump
+-- job's work list, compared against pgn_dumps.games to find stale months.
+SELECT to_char(start_ts AT TIME ZONE 'UTC', 'YYYY-MM')::text AS month,
+       count(*)::bigint AS games
+FROM games
+GROUP BY 1
+ORDER BY 1;
+
+-- name: ListDumpGames :many
+-- One page of a month's games in chronological order, keyset-paged on
+-- (start_ts, id) so a month of any size streams in bounded batches.
+SELECT * FROM games
+WHERE start_ts >= @from_ts AND start_ts < @to_ts
+  AND (start_ts, id) > (@after_ts::timestamptz, @after_id::int)
+ORDER BY start_ts, id
+LIMIT @batch_size;
+
+-- name: UpsertPgnDump :exec
+INSERT INTO pgn_dumps (month, object_key, games, bytes, raw_bytes, built_at)
+VALUES ($1, $2, $3, $4, $5, now())
+ON CONFLICT (month) DO UPDATE
+SET object_key = EXCLUDED.object_key,
+    games      = EXCLUDED.games,
+    bytes      = EXCLUDED.bytes,
+    raw_bytes  = EXCLUDED.raw_bytes,
+    built_at   = EXCLUDED.built_at;
+
+-- name: ListPgnDumps :many
+SELECT * FROM pgn_dumps ORDER BY month DESC;
+
+-- name: GetPgnDump :one
+SELECT * FROM pgn_dumps WHERE month = $1;
+
+-- name: WithdrawAccountDumps :execrows
+-- Take down the published dumps that hold any of an account's games (those of
+-- AccountGameIDs). Run just before the account is deleted, while the rows still
+-- name it: the months drop out of the catalogue, and the dump job sees them as
+-- unpublished and rebuilds them from the anonymised rows on its next pass.
+DELETE FROM pgn_dumps
+WHERE month IN (SELECT DISTINCT to_char(start_ts AT TIME ZONE 'UTC', 'YYYY-MM')
+                FROM games
+                WHERE white_user_id = @user_id::bigint
+                   OR black_user_id = @user_id::bigint
+                   OR white_uid = ANY(@uids::text[])
+                   OR black_uid = ANY(@uids::text[]));
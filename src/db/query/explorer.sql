@@ -0,0 +1,92 @@
+-- name: ExploreMoves :many
+-- The opening explorer's continuations (db/explorer.go): every move played
+-- from a position across the archive, with how the games it was played in
+-- ended. A position is reached either mid-game — a moves row whose position_id
+-- is it — or as a game's start, which has no moves row of its own, so the hits
+-- CTE takes both; a NULL position_id (a position no archived game reached
+-- after a move) leaves just the starts. Counts are DISTINCT on the game, so a
+-- game that came back to the position counts once per move it tried from it.
+--
+-- avg_rating averages the two seats' ratings going into the game over the
+-- games that carry both (rated games: "1650", or "1500?" while provisional),
+-- 0 when none does. The optional filters narrow to one rating category, which
+-- only rated games carry, and to a window of start times.
+WITH hits AS (
+    SELECT m.game_ref, m.ply
+    FROM moves m
+    WHERE m.position_id = sqlc.narg('position_id')::int
+    UNION
+    SELECT s.id, 0::smallint
+    FROM games s
+    WHERE s.starting_ofen = @ofen::text
+)
+SELECT nxt.mv,
+       p.eval_cp,
+       p.eval_mate,
+       count(DISTINCT g.id)                                          AS games,
+       count(DISTINCT g.id) FILTER (WHERE g.outcome = '1-0')         AS white_wins,
+       count(DISTINCT g.id) FILTER (WHERE g.outcome = '1/2-1/2')     AS draws,
+       count(DISTINCT g.id) FILTER (WHERE g.outcome = '0-1')         AS black_wins,
+       COALESCE(avg((NULLIF(regexp_replace(g.white_rating, '[^0-9]', '', 'g'), '')::int +
+                     NULLIF(regexp_replace(g.black_rating, '[^0-9]', '', 'g'), '')::int) / 2.0), 0)::int AS avg_rating
+FROM hits h
+         JOIN moves nxt ON nxt.game_ref = h.game_ref AND nxt.ply = h.ply + 1
+         JOIN games g ON g.id = h.game_ref
+         JOIN positions p ON p.id = nxt.position_id
+WHERE (sqlc.narg('category')::text IS NULL OR g.rating_category = sqlc.narg('category')::text)
+  AND (sqlc.narg('since')::timestamptz IS NULL OR g.start_ts >= sqlc.narg('since')::timestamptz)
+  AND (sqlc.narg('until')::timestamptz IS NULL OR g.start_ts < sqlc.narg('until')::timestamptz)
+GROUP BY nxt.mv, p.id
+ORDER BY games DESC, nxt.mv
+LIMIT @max_moves;
+
+-- name: ExploreGames :many
+-- A position's notable games: the highest rated of the games that reached it
+-- (by the seats' combined rating going in), newest first among equals — which
+-- is every unrated game. Same reach and filters as ExploreMoves; a seat with no
+-- account reads as the empty name.
+WITH hits AS (
+    SELECT m.game_ref
+    FROM moves m
+    WHERE m.position_id = sqlc.narg('position_id')::int
+    UNION
+    SELECT s.id
+    FROM games s
+    WHERE s.starting_ofen = @ofen::text
+)
+SELECT g.game_id,
+       g.start_ts,
+       g.outcome,
+       COALESCE(wu.username, '')::text AS white_name,
+       COALESCE(bu.username, '')::text AS black_name,
+       g.white_rating,
+       g.black_rating
+FROM games g
+         LEFT JOIN users wu ON wu.id = g.white_user_id
+         LEFT JOIN users bu ON bu.id = g.black_user_id
+WHERE g.id IN (SELECT game_ref FROM hits)
+  AND (sqlc.narg('category')::text IS NULL OR g.rating_category = sqlc.narg('category')::text)
+  AND (sqlc.narg('since')::timestamptz IS NULL OR g.start_ts >= sqlc.narg('since')::timestamptz)
+  AND (sqlc.narg('until')::timestamptz IS NULL OR g.start_ts < sqlc.narg('until')::timestamptz)
+ORDER BY COALESCE(NULLIF(regexp_replace(g.white_rating, '[^0-9]', '', 'g'), '')::int +
+                  NULLIF(regexp_replace(g.black_rating, '[^0-9]', '', 'g'), '')::int, 0) DESC,
+         g.end_ts DESC
+LIMIT @max_games;
+
+-- name: ExploreMatchups :many
+-- The explorer's formation-matchup table: how many games began from each
+-- starting position and how they ended. Package opening names the 144 deploy
+-- matchups from the OFEN; anything else (an unlimited game from a custom
+-- position) is dropped by the caller. Same filters as ExploreMoves.
+SELECT g.starting_ofen,
+       count(*)                                      AS games,
+       count(*) FILTER (WHERE g.outcome = '1-0')     AS white_wins,
+       count(*) FILTER (WHERE g.outcome = '1/2-1/2') AS draws,
+       count(*) FILTER (WHERE g.outcome = '0-1')     AS black_wins
+FROM games g
+WHERE (sqlc.narg('category')::text IS NULL OR g.rating_category = sqlc.narg('category')::text)
+  AND (sqlc.narg('since')::timestamptz IS NULL OR g.start_ts >= sqlc.narg('since')::timestamptz)
+  AND (sqlc.narg('until')::timestamptz IS NULL OR g.start_ts < sqlc.narg('until')::timestamptz)
+GROUP BY g.starting_ofen
+ORDER BY games DESC
+LIMIT 200;
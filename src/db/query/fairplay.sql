@@ -0,0 +1,75 @@
+-- name: GetFairPlayGame :one
+-- What decides whether a reviewed game is sampled — rated, two accounts, no
+-- bot — and the position it began from.
+SELECT rated, white_user_id, black_user_id, bot_persona, starting_ofen
+FROM games
+WHERE id = $1;
+
+-- name: ListFairPlayPlies :many
+-- Every ply of one game with its think time and the engine's best move in the
+-- position it reached (the move the next ply is compared with), in ply order.
+SELECT m.ply, m.mv, m.move_ms, p.best_move
+FROM moves m
+JOIN positions p ON p.id = m.position_id
+WHERE m.game_ref = $1
+ORDER BY m.ply;
+
+-- name: UpsertFairPlaySample :exec
+INSERT INTO fair_play_samples (game_ref, user_id, moves, engine_matches, top_matches,
+                               acpl, think_mean_ms, think_cv)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (game_ref, user_id) DO UPDATE
+SET moves          = excluded.moves,
+    engine_matches = excluded.engine_matches,
+    top_matches    = excluded.top_matches,
+    acpl           = excluded.acpl,
+    think_mean_ms  = excluded.think_mean_ms,
+    think_cv       = excluded.think_cv;
+
+-- name: ListFairPlaySamples :many
+-- An account's most recent samples, newest first, with the game each came from.
+SELECT s.game_ref, g.game_id, s.created_at, s.moves, s.engine_matches, s.top_matches,
+       s.acpl, s.think_mean_ms, s.think_cv
+FROM fair_play_samples s
+JOIN games g ON g.id = s.game_ref
+WHERE s.user_id = $1
+ORDER BY s.created_at DESC
+LIMIT $2;
+
+-- name: UpsertFairPlayScore :exec
+-- Records an account's rolled score. A dismissal survives the update: it is
+-- compared against, not reset by, the next score.
+INSERT INTO fair_play_scores (user_id, score, games, match_rate, top_rate, acpl,
+                              think_cv, match_spread)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (user_id) DO UPDATE
+SET score        = excluded.score,
+    games        = excluded.games,
+    match_rate   = excluded.match_rate,
+    top_rate     = excluded.top_rate,
+    acpl         = excluded.acpl,
+    think_cv     = excluded.think_cv,
+    match_spread = excluded.match_spread,
+    updated_at   = now();
+
+-- name: ListFlaggedAccounts :many
+-- Accounts scoring at least min_score over at least min_games, highest first,
+-- leaving out any a moderator dismissed unless its score has since climbed
+-- margin past the score it was dismissed at.
+SELECT f.user_id, u.username, f.score, f.games, f.match_rate, f.top_rate, f.acpl,
+       f.think_cv, f.match_spread, f.updated_at, f.reviewed_at
+FROM fair_play_scores f
+JOIN users u ON u.id = f.user_id
+WHERE f.score >= sqlc.arg(min_score)
+  AND f.games >= sqlc.arg(min_games)
+  AND (f.reviewed_score IS NULL OR f.score >= f.reviewed_score + sqlc.arg(margin))
+ORDER BY f.score DESC
+LIMIT sqlc.arg(lim);
+
+-- name: DismissFairPlayFlag :execrows
+-- A moderator looked and found nothing: remember the score they looked at.
+UPDATE fair_play_scores
+SET reviewed_at    = now(),
+    reviewed_by    = $2,
+    reviewed_score = score
+WHERE user_id = $1;
@@ -5,10 +5,11 @@ INSERT INTO games (
     variant_group, outcome, reason, starting_ofen, moves, pgn_object_key,
     game_index, white_user_id, black_user_id, creator_user_id, rated,
     white_rating, black_rating, white_rating_delta, black_rating_delta,
-    bot_persona, rating_category
+    bot_persona, rating_category, tournament_id, simul_id
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
-    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31,
+    $32
 )
 RETURNING id;
 
@@ -22,10 +23,11 @@ INSERT INTO games (
     variant_group, outcome, reason, starting_ofen, moves, pgn_object_key,
     game_index, white_user_id, black_user_id, creator_user_id, rated,
     white_rating, black_rating, white_rating_delta, black_rating_delta,
-    bot_persona, rating_category
+    bot_persona, rating_category, tournament_id, simul_id
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
-    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31,
+    $32
 )
 ON CONFLICT (pgn_object_key) DO NOTHING
 RETURNING id;
@@ -98,3 +100,57 @@ SELECT
 FROM games
 WHERE bot_persona = @persona
   AND (white_user_id = @user_id OR black_user_id = @user_id);
+
+-- name: ListAccountGames :many
+-- One page of every game an account played, from either seat, oldest first and
+-- keyset-paged on id: the games file of the account's data export.
+SELECT * FROM games
+WHERE (white_user_id = @user_id::bigint OR black_user_id = @user_id::bigint)
+  AND id > @after_id::int
+ORDER BY id
+LIMIT @batch_size;
+
+-- name: AccountGameIDs :many
+-- Every game an account played, from either seat, or under one of its session
+-- uids (AccountUIDs) before it signed in. Read just before the account is
+-- deleted, for the stored PGN objects that still name it or carry those uids.
+SELECT id FROM games
+WHERE white_user_id = @user_id::bigint OR black_user_id = @user_id::bigint
+   OR white_uid = ANY(@uids::text[]) OR black_uid = ANY(@uids::text[])
+ORDER BY id;
+
+-- name: AccountUIDs :many
+-- Every session uid an account sat, created a game or spoke under. Read just
+-- before the account is deleted: once the user ids are gone, a uid is what
+-- would still join its games, rooms and chat lines back into one person.
+SELECT DISTINCT seats.uid::text AS uid FROM (
+    SELECT white_uid AS uid FROM games WHERE white_user_id = @user_id::bigint
+    UNION ALL SELECT black_uid FROM games WHERE black_user_id = @user_id::bigint
+    UNION ALL SELECT creator_uid FROM games WHERE creator_user_id = @user_id::bigint
+    UNION ALL SELECT white_uid FROM rooms WHERE white_user_id = @user_id::bigint
+    UNION ALL SELECT black_uid FROM rooms WHERE black_user_id = @user_id::bigint
+    UNION ALL SELECT creator_uid FROM rooms WHERE creator_user_id = @user_id::bigint
+    UNION ALL SELECT uid FROM chat_messages WHERE user_id = @user_id::bigint
+) seats
+WHERE seats.uid <> '';
+
+-- name: AnonymiseGameUIDs :execrows
+-- Replace an account's session uids (AccountUIDs) on the archived games with
+-- one anonymous uid, in every seat that is the account's or nobody's: a seat
+-- another account took under the same session keeps its uid.
+UPDATE games SET
+    white_uid   = CASE WHEN white_uid = ANY(@uids::text[])
+                        AND (white_user_id IS NULL OR white_user_id = @user_id::bigint)
+                       THEN @anon_uid::text ELSE white_uid END,
+    black_uid   = CASE WHEN black_uid = ANY(@uids::text[])
+                        AND (black_user_id IS NULL OR black_user_id = @user_id::bigint)
+                       THEN @anon_uid::text ELSE black_uid END,
+    creator_uid = CASE WHEN creator_uid = ANY(@uids::text[])
+                        AND (creator_user_id IS NULL OR creator_user_id = @user_id::bigint)
+                       THEN @anon_uid::text ELSE creator_uid END
+WHERE white_uid = ANY(@uids::text[])
+   OR black_uid = ANY(@uids::text[])
+   OR creator_uid = ANY(@uids::text[]);
+
+-- name: ListGamesByID :many
+SELECT * FROM games WHERE id = ANY(@ids::int[]) ORDER BY id;
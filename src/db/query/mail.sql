@@ -0,0 +1,96 @@
+-- Email (package mail): the outbox, the weekly digest's recipients, and the
+-- account lookups a password reset and an address verification need.
+
+-- name: EnqueueMail :one
+-- A message with a dedupe_key that is already queued writes nothing and returns
+-- no row: the digest's guard against two nodes deciding to send it at once.
+INSERT INTO mail_outbox (user_id, kind, to_addr, subject, body, headers, dedupe_key)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (dedupe_key) DO NOTHING
+RETURNING id;
+
+-- name: ClaimDueMail :many
+-- Take a batch of due messages and push their next attempt out to the lease, so
+-- no other node's worker takes the same rows while this one sends them. SKIP
+-- LOCKED lets two workers claim disjoint batches instead of waiting on each
+-- other. A worker that dies mid-send leaves its rows to be retried once the
+-- lease runs out — at least once, not exactly once, which is the right side to
+-- err on for a password reset.
+UPDATE mail_outbox
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE id IN (SELECT o.id
+             FROM mail_outbox o
+             WHERE o.sent_at IS NULL
+               AND o.next_attempt_at <= now()
+               AND o.attempts < sqlc.arg(max_attempts)
+             ORDER BY o.next_attempt_at
+             LIMIT sqlc.arg(batch) FOR UPDATE SKIP LOCKED)
+RETURNING id, kind, to_addr, subject, body, headers, attempts;
+
+-- name: MarkMailSent :exec
+UPDATE mail_outbox
+SET sent_at    = now(),
+    attempts   = attempts + 1,
+    last_error = ''
+WHERE id = $1;
+
+-- name: MarkMailFailed :exec
+UPDATE mail_outbox
+SET attempts        = attempts + 1,
+    last_error      = $2,
+    next_attempt_at = $3
+WHERE id = $1;
+
+-- name: PruneMail :execrows
+-- Drop what was sent before the cutoff, and what gave up before it. Neither is
+-- read again, and both hold a rendered body that may carry a live link.
+DELETE FROM mail_outbox
+WHERE created_at < sqlc.arg(cutoff)
+  AND (sent_at IS NOT NULL OR attempts >= sqlc.arg(max_attempts));
+
+-- name: DigestRecipients :many
+-- The accounts owed a weekly digest: opted in (the prefs key email.digest), a
+-- verified address, not banned, something unread that arrived since the last
+-- week began, and no digest queued inside that week. Keyed after the last id
+-- seen so a long list pages instead of loading at once.
+SELECT u.id, u.username, u.email
+FROM users u
+         JOIN user_prefs p ON p.user_id = u.id AND p.key = 'email.digest' AND p.value = '1'
+WHERE u.id > sqlc.arg(after_id)
+  AND u.email IS NOT NULL
+  AND u.email_verified_at IS NOT NULL
+  AND (u.banned_until IS NULL OR u.banned_until < now())
+  AND EXISTS (SELECT 1
+              FROM notifications n
+              WHERE n.user_id = u.id
+                AND n.read_at IS NULL
+                AND n.created_at > sqlc.arg(since))
+  AND NOT EXISTS (SELECT 1
+                  FROM mail_outbox o
+                  WHERE o.user_id = u.id
+                    AND o.kind = 'digest'
+                    AND o.created_at > sqlc.arg(since))
+ORDER BY u.id
+LIMIT sqlc.arg(batch);
+
+-- name: ListUsersByEmail :many
+-- The forgotten-password lookup by address. An address is not unique — two
+-- accounts may share one — so this is a list, bounded so an address attached to
+-- many throwaway accounts cannot make one request send a flood. Only a verified
+-- address counts: anybody can type a victim's address into an account, or their
+-- own into an account they want, and a reset link must not go wherever that is.
+SELECT id, username, email, password_hash
+FROM users
+WHERE lower(email) = lower($1)
+  AND email_verified_at IS NOT NULL
+ORDER BY id
+LIMIT 5;
+
+-- name: MarkEmailVerified :execrows
+-- Stamp the address verified, but only while it is still the address the link
+-- was sent to: a link for an address the account has since replaced verifies
+-- nothing.
+UPDATE users
+SET email_verified_at = now()
+WHERE id = $1
+  AND email = $2;
@@ -18,14 +18,17 @@ RETURNING id;
 -- everything that account was involved in, by them or against them — which is
 -- the question a moderator actually asks. NULL/empty disables each.
 --
+-- Both joins are LEFT: an entry outlives the accounts in it (00036), and an
+-- actor that has since been deleted comes back as the empty name.
+--
 -- The ILIKE has no index behind it; at this table's size a sequential scan of
 -- a few thousand rows is cheaper than the pg_trgm extension it would take to
 -- index, and the created_at index still serves the ordering.
 SELECT m.id, m.created_at, m.action, m.detail, m.reason,
-       a.username AS actor_username,
+       COALESCE(a.username, '')::text AS actor_username,
        t.username AS target_username
 FROM mod_actions m
-         JOIN users a ON a.id = m.actor_user_id
+         LEFT JOIN users a ON a.id = m.actor_user_id
          LEFT JOIN users t ON t.id = m.target_user_id
 WHERE (sqlc.narg('action')::text IS NULL OR m.action = sqlc.narg('action')::text)
   AND (sqlc.narg('q')::text IS NULL
@@ -40,7 +43,7 @@ LIMIT $1 OFFSET $2;
 -- the two WHERE clauses must stay identical or the page count lies.
 SELECT count(*)
 FROM mod_actions m
-         JOIN users a ON a.id = m.actor_user_id
+         LEFT JOIN users a ON a.id = m.actor_user_id
          LEFT JOIN users t ON t.id = m.target_user_id
 WHERE (sqlc.narg('action')::text IS NULL OR m.action = sqlc.narg('action')::text)
   AND (sqlc.narg('q')::text IS NULL
@@ -51,9 +54,9 @@ WHERE (sqlc.narg('action')::text IS NULL OR m.action = sqlc.narg('action')::text
 -- name: ListModActionsForUser :many
 -- One account's moderation history, shown on their player page to mods only.
 SELECT m.id, m.created_at, m.action, m.detail, m.reason,
-       a.username AS actor_username
+       COALESCE(a.username, '')::text AS actor_username
 FROM mod_actions m
-         JOIN users a ON a.id = m.actor_user_id
+         LEFT JOIN users a ON a.id = m.actor_user_id
 WHERE m.target_user_id = $1
 ORDER BY m.created_at DESC
 LIMIT $2 OFFSET $3;
@@ -67,8 +70,9 @@ SELECT count(*) FROM mod_actions WHERE target_user_id = $1;
 -- Who last promoted this account to admin, from the audit log itself — the
 -- log is the record of who did what, so it is also the record of who may undo
 -- it. No row means the account's admin came from outside the app (the SQL
--- bootstrap), and nobody may demote it through the UI.
-SELECT actor_user_id
+-- bootstrap), and nobody may demote it through the UI. A grantor that has
+-- since been deleted reads as 0, which matches no one: the same outcome.
+SELECT COALESCE(actor_user_id, 0)::bigint AS actor_user_id
 FROM mod_actions
 WHERE target_user_id = $1
   AND action = 'role'
@@ -171,7 +175,7 @@ FROM users u
          LEFT JOIN LATERAL (
     SELECT m.created_at, a.username AS actor_username
     FROM mod_actions m
-             JOIN users a ON a.id = m.actor_user_id
+             LEFT JOIN users a ON a.id = m.actor_user_id
     WHERE m.target_user_id = u.id
       AND m.action = 'role'
       AND m.detail ->> 'to' = u.role
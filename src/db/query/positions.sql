@@ -24,5 +24,6 @@ LIMIT $1;
 
 -- name: SetPositionEval :exec
 UPDATE positions
-SET eval_cp = $2, eval_depth = $3, best_move = $4, evaluated_at = now()
+SET eval_cp = $2, eval_depth = $3, best_move = $4, eval_mate = $5, eval_exact = $6,
+    evaluated_at = now()
 WHERE id = $1;
@@ -0,0 +1,84 @@
+-- Training puzzles (the puzzle package): the miner's reads over archived games
+-- and its inserts, the trainer's serving reads, and the rating update each
+-- player's first attempt at a puzzle runs.
+
+-- name: LatestPuzzleGame :one
+-- The newest game a puzzle has been mined from: where the miner resumes after
+-- a restart.
+SELECT COALESCE(MAX(game_ref), 0)::int FROM puzzles;
+
+-- name: ListGamesAfter :many
+-- The next games the miner has not scanned, oldest first.
+SELECT id FROM games
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: ListGamePlyEvals :many
+-- Every ply of one game with the position it reached and that position's
+-- cached eval, in ply order: what the miner looks for swings in.
+SELECT m.ply, m.mv, m.position_id, p.ofen, p.eval_cp, p.eval_mate
+FROM moves m
+JOIN positions p ON p.id = m.position_id
+WHERE m.game_ref = $1
+ORDER BY m.ply;
+
+-- name: PuzzleAtPosition :one
+SELECT EXISTS (SELECT 1 FROM puzzles WHERE position_id = $1);
+
+-- name: InsertPuzzle :exec
+INSERT INTO puzzles (position_id, game_ref, ofen, last_move, solution, theme, rating, rd, volatility)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (position_id) DO NOTHING;
+
+-- name: GetPuzzle :one
+SELECT * FROM puzzles WHERE id = $1;
+
+-- name: ListPuzzlesNear :many
+-- The puzzles rated nearest a player's rating that they have not attempted.
+-- An anonymous player passes user 0, which excludes nothing.
+SELECT * FROM puzzles p
+WHERE NOT EXISTS (
+    SELECT 1 FROM puzzle_attempts a
+    WHERE a.user_id = sqlc.arg(user_id) AND a.puzzle_id = p.id
+)
+ORDER BY abs(p.rating - sqlc.arg(rating)::float8)
+LIMIT sqlc.arg(max_rows);
+
+-- name: InsertPuzzleAttempt :execrows
+-- Records a player's attempt at a puzzle, reporting no row on any attempt
+-- after their first — the only one that is rated.
+INSERT INTO puzzle_attempts (user_id, puzzle_id, solved)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, puzzle_id) DO NOTHING;
+
+-- name: GetPuzzleRating :one
+SELECT rating, rd, volatility, plays FROM puzzle_ratings
+WHERE user_id = $1;
+
+-- name: GetPuzzleRatingForUpdate :one
+-- Locks a player's puzzle rating row. An attempt locks the player before the
+-- puzzle, always, so two attempts can never wait on each other in a cycle.
+SELECT rating, rd, volatility, plays FROM puzzle_ratings
+WHERE user_id = $1
+FOR UPDATE;
+
+-- name: UpsertPuzzleRating :exec
+INSERT INTO puzzle_ratings (user_id, rating, rd, volatility, plays, updated_at)
+VALUES ($1, $2, $3, $4, $5, now())
+ON CONFLICT (user_id) DO UPDATE SET
+    rating     = EXCLUDED.rating,
+    rd         = EXCLUDED.rd,
+    volatility = EXCLUDED.volatility,
+    plays      = EXCLUDED.plays,
+    updated_at = now();
+
+-- name: GetPuzzleForUpdate :one
+SELECT rating, rd, volatility, plays FROM puzzles
+WHERE id = $1
+FOR UPDATE;
+
+-- name: SetPuzzleRating :exec
+UPDATE puzzles
+SET rating = $2, rd = $3, volatility = $4, plays = $5
+WHERE id = $1;
@@ -11,14 +11,19 @@ RETURNING id;
 -- name: ListOpenReports :many
 -- The queue, oldest first — it is worked in the order things were reported, so
 -- nothing sits at the bottom forever. Both parties' names are resolved here
--- because a queue of user ids is unreadable.
+-- because a queue of user ids is unreadable, and a named game's room because
+-- that is what its chat log is kept by. The target join is LEFT, like every
+-- read of reports since 00036: a deleted account is named by the username it
+-- had, and its id reads as 0.
 SELECT r.id, r.created_at, r.category, r.note, r.game_id,
        rep.username AS reporter_username,
-       tgt.username AS target_username,
-       tgt.id       AS target_user_id
+       COALESCE(tgt.username, r.target_username, '')::text AS target_username,
+       COALESCE(r.target_user_id, 0)::bigint AS target_user_id,
+       COALESCE(g.room_id, '')::text AS room_id
 FROM reports r
          JOIN users rep ON rep.id = r.reporter_user_id
-         JOIN users tgt ON tgt.id = r.target_user_id
+         LEFT JOIN users tgt ON tgt.id = r.target_user_id
+         LEFT JOIN games g ON g.game_id = r.game_id
 WHERE r.status = 'open'
 ORDER BY r.created_at
 LIMIT $1 OFFSET $2;
@@ -32,12 +37,14 @@ SELECT count(*) FROM reports WHERE status = 'open';
 SELECT r.id, r.created_at, r.category, r.note, r.game_id,
        r.resolved_at, r.resolution,
        rep.username AS reporter_username,
-       tgt.username AS target_username,
-       res.username AS resolver_username
+       COALESCE(tgt.username, r.target_username, '')::text AS target_username,
+       res.username AS resolver_username,
+       COALESCE(g.room_id, '')::text AS room_id
 FROM reports r
          JOIN users rep ON rep.id = r.reporter_user_id
-         JOIN users tgt ON tgt.id = r.target_user_id
+         LEFT JOIN users tgt ON tgt.id = r.target_user_id
          LEFT JOIN users res ON res.id = r.resolved_by
+         LEFT JOIN games g ON g.game_id = r.game_id
 WHERE r.status = 'closed'
 ORDER BY r.resolved_at DESC NULLS LAST
 LIMIT $1;
@@ -45,7 +52,8 @@ LIMIT $1;
 -- name: ResolveReport :one
 -- Close one open report. Scoped to status = 'open' so two moderators working
 -- the queue at once cannot both claim the same row: the second gets no row and
--- is told it was already handled.
+-- is told it was already handled. The target is NULL if the account has since
+-- been deleted.
 UPDATE reports
 SET status      = 'closed',
     resolved_at = now(),
@@ -59,3 +67,23 @@ RETURNING target_user_id;
 -- "Is anyone complaining about this account?" — shown on their player page so a
 -- moderator sees the context before acting.
 SELECT count(*) FROM reports WHERE target_user_id = $1 AND status = 'open';
+
+-- name: ListReportsFiledBy :many
+-- Every report an account filed, newest first, for its data export. What was
+-- decided is the reporter's to know; which moderator decided it is not.
+SELECT r.id, r.created_at, r.category, r.note, r.game_id, r.status,
+       r.resolved_at, r.resolution,
+       COALESCE(tgt.username, r.target_username, '')::text AS target_username
+FROM reports r
+         LEFT JOIN users tgt ON tgt.id = r.target_user_id
+WHERE r.reporter_user_id = $1
+ORDER BY r.created_at DESC;
+
+-- name: SnapshotReportTargets :exec
+-- Keep an account's username on the reports against it, just before the
+-- account is deleted and the reference with it (00036).
+UPDATE reports r
+SET target_username = u.username
+FROM users u
+WHERE u.id = $1
+  AND r.target_user_id = u.id;
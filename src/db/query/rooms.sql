@@ -30,7 +30,27 @@ WHERE room_id = $1 AND closed_at IS NULL;
 -- name: RoomIDExists :one
 -- All-time room-ID uniqueness check for the room-creation re-roll loop: a new
 -- room may never reuse the ID of any archived room (its permalink is forever).
-SELECT EXISTS(SELECT 1 FROM rooms WHERE room_id = $1);
+-- A correspondence game holds its id from creation and archives under it, so
+-- its ids are taken from the same space.
+SELECT (EXISTS(SELECT 1 FROM rooms WHERE room_id = $1)
+        OR EXISTS(SELECT 1 FROM correspondence_games WHERE id = $1)) AS taken;
 
 -- name: GetRoom :one
 SELECT * FROM rooms WHERE room_id = $1;
+
+-- name: AnonymiseRoomUIDs :execrows
+-- AnonymiseGameUIDs for the rooms rows, which carry the seats as of their
+-- latest game.
+UPDATE rooms SET
+    white_uid   = CASE WHEN white_uid = ANY(@uids::text[])
+                        AND (white_user_id IS NULL OR white_user_id = @user_id::bigint)
+                       THEN @anon_uid::text ELSE white_uid END,
+    black_uid   = CASE WHEN black_uid = ANY(@uids::text[])
+                        AND (black_user_id IS NULL OR black_user_id = @user_id::bigint)
+                       THEN @anon_uid::text ELSE black_uid END,
+    creator_uid = CASE WHEN creator_uid = ANY(@uids::text[])
+                        AND (creator_user_id IS NULL OR creator_user_id = @user_id::bigint)
+                       THEN @anon_uid::text ELSE creator_uid END
+WHERE white_uid = ANY(@uids::text[])
+   OR black_uid = ANY(@uids::text[])
+   OR creator_uid = ANY(@uids::text[]);
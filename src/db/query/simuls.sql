@@ -0,0 +1,53 @@
+-- Simuls (the simul package). Like tournaments, an open simul runs in memory
+-- and writes through here; on boot every unfinished simul is restored from
+-- these rows, so every write is idempotent on its key.
+
+-- name: InsertSimul :exec
+INSERT INTO simuls (id, name, variant, host_user_id, host_name, host_color,
+                    max_boards)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: UpdateSimulState :exec
+-- Progress and the host's tally; the configuration never changes once created.
+UPDATE simuls
+SET status      = $2,
+    wins        = $3,
+    draws       = $4,
+    losses      = $5,
+    started_at  = $6,
+    finished_at = $7
+WHERE id = $1;
+
+-- name: GetSimul :one
+SELECT * FROM simuls WHERE id = $1;
+
+-- name: ListOpenSimuls :many
+-- Everything the boot restore picks back up.
+SELECT * FROM simuls
+WHERE status <> 'finished'
+ORDER BY created_at;
+
+-- name: ListRecentSimuls :many
+-- The /simul page's finished simuls, newest first.
+SELECT * FROM simuls
+WHERE status = 'finished'
+ORDER BY finished_at DESC
+LIMIT $1;
+
+-- name: UpsertSimulBoard :exec
+INSERT INTO simul_boards (simul_id, user_id, username, room_id, result)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (simul_id, user_id) DO UPDATE
+SET room_id = EXCLUDED.room_id,
+    result  = EXCLUDED.result;
+
+-- name: DeleteSimulBoard :exec
+-- Leaving before the start takes the entry back entirely.
+DELETE FROM simul_boards
+WHERE simul_id = $1 AND user_id = $2;
+
+-- name: ListSimulBoards :many
+-- Boards in the order their participants joined.
+SELECT * FROM simul_boards
+WHERE simul_id = $1
+ORDER BY joined_at, user_id;
@@ -0,0 +1,81 @@
+-- Tournaments (the tournament package). The package runs open events in
+-- memory and writes through here; on boot it restores every unfinished event
+-- from these rows, so every write is idempotent on its key.
+
+-- name: InsertTournament :exec
+INSERT INTO tournaments (id, name, format, variant, rated, berserk, rounds,
+                         duration_secs, created_by, starts_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: UpdateTournamentState :exec
+-- Progress only; the configuration never changes once created.
+UPDATE tournaments
+SET status      = $2,
+    round       = $3,
+    started_at  = $4,
+    finished_at = $5
+WHERE id = $1;
+
+-- name: GetTournament :one
+SELECT * FROM tournaments WHERE id = $1;
+
+-- name: ListOpenTournaments :many
+-- Everything the boot restore picks back up.
+SELECT * FROM tournaments
+WHERE status <> 'finished'
+ORDER BY starts_at;
+
+-- name: ListRecentTournaments :many
+-- The /tournament page's finished events, newest first.
+SELECT * FROM tournaments
+WHERE status = 'finished'
+ORDER BY starts_at DESC
+LIMIT $1;
+
+-- name: TournamentName :one
+SELECT name FROM tournaments WHERE id = $1;
+
+-- name: UpsertTournamentPlayer :exec
+INSERT INTO tournament_players (tournament_id, user_id, rating, score, tiebreak,
+                                wins, draws, losses, sheet, rank, withdrawn)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (tournament_id, user_id) DO UPDATE
+SET score     = EXCLUDED.score,
+    tiebreak  = EXCLUDED.tiebreak,
+    wins      = EXCLUDED.wins,
+    draws     = EXCLUDED.draws,
+    losses    = EXCLUDED.losses,
+    sheet     = EXCLUDED.sheet,
+    rank      = EXCLUDED.rank,
+    withdrawn = EXCLUDED.withdrawn;
+
+-- name: DeleteTournamentPlayer :exec
+-- Leaving before the start takes the entry back entirely; once the event is
+-- underway the player is marked withdrawn instead, keeping their games.
+DELETE FROM tournament_players
+WHERE tournament_id = $1 AND user_id = $2;
+
+-- name: ListTournamentPlayers :many
+-- Standings order. The title join matches the rest of the user reads.
+SELECT tp.user_id, u.username, t.code AS title_code, tp.rating, tp.score,
+       tp.tiebreak, tp.wins, tp.draws, tp.losses, tp.sheet, tp.rank,
+       tp.withdrawn, tp.joined_at
+FROM tournament_players tp
+         JOIN users u ON u.id = tp.user_id
+         LEFT JOIN titles t ON t.id = u.title_id
+WHERE tp.tournament_id = $1
+ORDER BY tp.rank, tp.score DESC, tp.tiebreak DESC, tp.rating DESC;
+
+-- name: InsertTournamentPairing :one
+INSERT INTO tournament_pairings (tournament_id, round, room_id, white_user_id,
+                                 black_user_id, result)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
+
+-- name: SetTournamentPairingResult :exec
+UPDATE tournament_pairings SET result = $2 WHERE id = $1;
+
+-- name: ListTournamentPairings :many
+SELECT * FROM tournament_pairings
+WHERE tournament_id = $1
+ORDER BY id;
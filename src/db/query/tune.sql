@@ -0,0 +1,16 @@
+-- Evaluation tuning (the tune package, lio --tune): the positions of archived
+-- games with the result each game reached.
+
+-- name: ListTuningPlies :many
+-- Every position reached in the games after one game ref through another, with
+-- the game's result, in game and ply order. Games lost on time or abandoned are
+-- left out: their result says who ran out of clock or patience, not what the
+-- positions were worth.
+SELECT m.game_ref, m.ply, p.ofen, g.outcome
+FROM games g
+JOIN moves m ON m.game_ref = g.id
+JOIN positions p ON p.id = m.position_id
+WHERE g.id > sqlc.arg(after) AND g.id <= sqlc.arg(through)
+  AND g.outcome IN ('1-0', '0-1', '1/2-1/2')
+  AND g.reason NOT IN ('time', 'abandoned')
+ORDER BY m.game_ref, m.ply;
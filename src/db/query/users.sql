@@ -45,9 +45,13 @@ SELECT EXISTS(SELECT 1 FROM users WHERE lower(username) = lower($1));
 UPDATE users SET password_hash = $2 WHERE id = $1;
 
 -- name: UpdateEmail :exec
--- Set / replace / clear the account email ($2 NULL clears it). There is no
--- email infrastructure yet, so this is a plain overwrite — no verification.
-UPDATE users SET email = $2 WHERE id = $1;
+-- Set / replace / clear the account email ($2 NULL clears it). Any change of
+-- address clears its verification, which belongs to the old one; saving the
+-- same address again keeps it.
+UPDATE users
+SET email             = $2,
+    email_verified_at = CASE WHEN email IS NOT DISTINCT FROM $2 THEN email_verified_at END
+WHERE id = $1;
 
 -- name: UpdateUsernameCasing :one
 -- The one-time casing-only username change (arch polish pass): rewrite only the
@@ -62,3 +66,9 @@ WHERE id = $1
   AND username_changed_at IS NULL
   AND lower(username) = lower($2)
 RETURNING username;
+
+-- name: DeleteUser :execrows
+-- Delete an account. What only it owns goes with it by cascade; what has to
+-- outlive it (the archived games, the audit log) loses the reference instead
+-- (00036).
+DELETE FROM users WHERE id = $1;
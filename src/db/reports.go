@@ -39,14 +39,17 @@ func ValidCategory(c string) bool {
 
 // Report is one row of the moderation queue, with both parties resolved.
 type Report struct {
-	ID         int64
-	Created    time.Time
-	Category   string
-	Note       string
-	Reporter   string
-	Target     string
-	TargetID   int64
-	GameID     string // empty when the report names no specific game
+	ID       int64
+	Created  time.Time
+	Category string
+	Note     string
+	Reporter string
+	Target   string
+	TargetID int64
+	GameID   string // empty when the report names no specific game
+	// RoomID is the named game's room, whose chat log is the evidence for
+	// anything said in it; empty with GameID, or for a game with no room.
+	RoomID     string
 	Resolved   time.Time
 	Resolver   string
 	Resolution string
@@ -60,7 +63,7 @@ func FileReport(reporterID, targetID int64, gameID *uuid.UUID,
 	defer cancel()
 	_, err := gen.New(Pool).CreateReport(ctx, gen.CreateReportParams{
 		ReporterUserID: reporterID,
-		TargetUserID:   targetID,
+		TargetUserID:   &targetID,
 		GameID:         pgUUID(gameID),
 		Category:       category,
 		Note:           note,
@@ -95,6 +98,7 @@ func OpenReports(limit, offset int32) ([]Report, error) {
 			Target:   r.TargetUsername,
 			TargetID: r.TargetUserID,
 			GameID:   uuidOrEmpty(r.GameID),
+			RoomID:   r.RoomID,
 		})
 	}
 	return out, nil
@@ -124,6 +128,36 @@ func ClosedReports(limit int32) ([]Report, error) {
 			Resolved:   r.ResolvedAt.Time,
 			Resolver:   strOrEmpty(r.ResolverUsername),
 			Resolution: strOrEmpty(r.Resolution),
+			RoomID:     r.RoomID,
+		})
+	}
+	return out, nil
+}
+
+// ReportsFiledBy returns every report userID filed, newest first: their data
+// export's copy. Reporter and Resolver are left empty — the first is the reader
+// themselves, and the second is not theirs to know.
+func ReportsFiledBy(userID int64) ([]Report, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListReportsFiledBy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Report, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, Report{
+			ID:         r.ID,
+			Created:    r.CreatedAt.Time,
+			Category:   r.Category,
+			Note:       r.Note,
+			Target:     r.TargetUsername,
+			GameID:     uuidOrEmpty(r.GameID),
+			Resolved:   r.ResolvedAt.Time,
+			Resolution: strOrEmpty(r.Resolution),
 		})
 	}
 	return out, nil
@@ -146,14 +180,15 @@ func OpenReportsAgainst(userID int64) (int64, error) {
 	}
 	ctx, cancel := Ctx()
 	defer cancel()
-	return gen.New(Pool).CountOpenReportsForUser(ctx, userID)
+	return gen.New(Pool).CountOpenReportsForUser(ctx, &userID)
 }
 
-// ResolveReport closes an open report, returning the account it named. ok=false
-// (with no error) means the report was already closed — two moderators working
-// the queue at once, where the second should be told rather than silently
-// overwriting the first's decision.
-func ResolveReport(id, resolverID int64, resolution string) (targetID int64, ok bool, err error) {
+// ResolveReport closes an open report, returning the account it named — nil if
+// that account has since been deleted. ok=false (with no error) means the
+// report was already closed — two moderators working the queue at once, where
+// the second should be told rather than silently overwriting the first's
+// decision.
+func ResolveReport(id, resolverID int64, resolution string) (targetID *int64, ok bool, err error) {
 	ctx, cancel := Ctx()
 	defer cancel()
 	targetID, err = gen.New(Pool).ResolveReport(ctx, gen.ResolveReportParams{
@@ -162,10 +197,10 @@ func ResolveReport(id, resolverID int64, resolution string) (targetID int64, ok
 		Resolution: &resolution,
 	})
 	if errors.Is(err, pgx.ErrNoRows) {
-		return 0, false, nil
+		return nil, false, nil
 	}
 	if err != nil {
-		return 0, false, err
+		return nil, false, err
 	}
 	return targetID, true, nil
 }
@@ -18,7 +18,8 @@ import (
 // Every function degrades to a miss/no-op when Postgres is unconfigured, so
 // local dev without lio_pg_dsn keeps today's behavior (closed rooms 404).
 
-// RoomIDExists reports whether a room ID is already taken by an archived room.
+// RoomIDExists reports whether a room ID is already taken by an archived room
+// or by a correspondence game (which archives under its id).
 // Room creation re-rolls candidate IDs through this so a new room can never
 // reuse — and thereby hijack — a historical room's permalink. It returns false
 // on query errors (logged): a 58^7 collision is vastly less likely than a
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/dechristopher/lio/db/gen"
+)
+
+// Simul reads and writes. The simul package keeps every open simul in memory
+// and writes through here as it goes, like the tournament accessors: these rows
+// are what a restart resumes from and, once a simul is over, its results
+// summary. Without Postgres a simul simply lives and dies with the process.
+
+// SimulInfo is one simuls row.
+type SimulInfo struct {
+	ID         string
+	Name       string
+	Variant    string
+	HostUserID *int64
+	HostName   string
+	// HostColor is "white" or "black".
+	HostColor string
+	MaxBoards int
+	Status    string
+	// Wins, Draws and Losses are the host's tally.
+	Wins       int
+	Draws      int
+	Losses     int
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// SimulBoard is one participant's board. RoomID is "" before the simul starts;
+// Result is "" while the board is on, else "w", "b", "d", or "v" for a board
+// that never finished.
+type SimulBoard struct {
+	UserID   int64
+	Username string
+	RoomID   string
+	Result   string
+}
+
+// InsertSimul records a newly created simul.
+func InsertSimul(s SimulInfo) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).InsertSimul(ctx, gen.InsertSimulParams{
+		ID:         s.ID,
+		Name:       s.Name,
+		Variant:    s.Variant,
+		HostUserID: s.HostUserID,
+		HostName:   s.HostName,
+		HostColor:  s.HostColor,
+		MaxBoards:  int16(s.MaxBoards),
+	})
+}
+
+// UpdateSimulState writes a simul's progress and the host's tally. A zero
+// started or finished time is stored as NULL.
+func UpdateSimulState(s SimulInfo) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).UpdateSimulState(ctx, gen.UpdateSimulStateParams{
+		ID:         s.ID,
+		Status:     s.Status,
+		Wins:       int16(s.Wins),
+		Draws:      int16(s.Draws),
+		Losses:     int16(s.Losses),
+		StartedAt:  optionalTs(s.StartedAt),
+		FinishedAt: optionalTs(s.FinishedAt),
+	})
+}
+
+// GetSimul reads one simul. ok is false when it does not exist (or Postgres is
+// unconfigured).
+func GetSimul(id string) (SimulInfo, bool, error) {
+	if Pool == nil {
+		return SimulInfo{}, false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetSimul(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SimulInfo{}, false, nil
+		}
+		return SimulInfo{}, false, err
+	}
+	return simulInfo(row), true, nil
+}
+
+// OpenSimuls lists every unfinished simul, for the boot restore.
+func OpenSimuls() ([]SimulInfo, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListOpenSimuls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SimulInfo, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, simulInfo(r))
+	}
+	return out, nil
+}
+
+// RecentSimuls lists up to limit finished simuls, newest first.
+func RecentSimuls(limit int) ([]SimulInfo, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListRecentSimuls(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SimulInfo, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, simulInfo(r))
+	}
+	return out, nil
+}
+
+// SaveSimulBoard inserts or rewrites a participant's board.
+func SaveSimulBoard(id string, b SimulBoard) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).UpsertSimulBoard(ctx, gen.UpsertSimulBoardParams{
+		SimulID:  id,
+		UserID:   b.UserID,
+		Username: b.Username,
+		RoomID:   b.RoomID,
+		Result:   b.Result,
+	})
+}
+
+// DeleteSimulBoard removes an entry outright (a participant leaving before
+// the start).
+func DeleteSimulBoard(id string, userID int64) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).DeleteSimulBoard(ctx, gen.DeleteSimulBoardParams{
+		SimulID: id,
+		UserID:  userID,
+	})
+}
+
+// SimulBoards lists a simul's boards in the order their participants joined.
+func SimulBoards(id string) ([]SimulBoard, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListSimulBoards(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SimulBoard, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, SimulBoard{
+			UserID:   r.UserID,
+			Username: r.Username,
+			RoomID:   r.RoomID,
+			Result:   r.Result,
+		})
+	}
+	return out, nil
+}
+
+// simulInfo converts the generated row.
+func simulInfo(r gen.Simul) SimulInfo {
+	return SimulInfo{
+		ID:         r.ID,
+		Name:       r.Name,
+		Variant:    r.Variant,
+		HostUserID: r.HostUserID,
+		HostName:   r.HostName,
+		HostColor:  r.HostColor,
+		MaxBoards:  int(r.MaxBoards),
+		Status:     r.Status,
+		Wins:       int(r.Wins),
+		Draws:      int(r.Draws),
+		Losses:     int(r.Losses),
+		CreatedAt:  r.CreatedAt.Time,
+		StartedAt:  r.StartedAt.Time,
+		FinishedAt: r.FinishedAt.Time,
+	}
+}
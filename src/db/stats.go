@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/dechristopher/lio/metrics"
 )
 
 // Operational stats for the /system console: the connection pool's own
@@ -35,6 +37,17 @@ var (
 	lastErr     atomic.Pointer[ArchiveFailure]
 )
 
+// archiveCommit and ratingUpdates are the live archive seam's figures for
+// /metrics: how long a successful archive transaction took end to end, and how
+// many rated games moved both players' ratings. Like archiveOK, the backfill
+// is not counted.
+var (
+	archiveCommit = metrics.NewHistogram("lio_archive_commit_seconds",
+		"Wall time of a successful live game archive transaction.", metrics.LatencyBuckets)
+	ratingUpdates = metrics.NewCounter("lio_rating_updates_total",
+		"Rated games whose Glicko-2 update was committed with the archive.")
+)
+
 // ArchiveFailure is the most recent archive write error, kept for display.
 type ArchiveFailure struct {
 	When time.Time
@@ -0,0 +1,343 @@
+package db
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/dechristopher/lio/db/gen"
+)
+
+// Tournament reads and writes. The tournament package keeps every open event
+// in memory and writes through here as it progresses, so these rows are what a
+// restart resumes from and what the /tournament pages fall back to once an
+// event is over. Like the other archive accessors they degrade quietly: without
+// Postgres an event simply lives and dies with the process.
+
+// TournamentInfo is one tournaments row.
+type TournamentInfo struct {
+	ID      string
+	Name    string
+	Format  string
+	Variant string
+	Rated   bool
+	Berserk bool
+	// Rounds is a Swiss event's round count; Duration an Arena's length.
+	Rounds   int
+	Duration time.Duration
+	Status   string
+	// Round is the Swiss round in progress, 0 before the first pairing.
+	Round      int
+	CreatedBy  *int64
+	StartsAt   time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// TournamentStanding is one entrant's standings row.
+type TournamentStanding struct {
+	UserID    int64
+	Username  string
+	Title     string
+	Rating    int
+	Score     float64
+	Tiebreak  float64
+	Wins      int
+	Draws     int
+	Losses    int
+	Sheet     string
+	Rank      int
+	Withdrawn bool
+}
+
+// TournamentPairing is one game (or bye) an event has paired. Black is 0 for
+// a Swiss bye. Result is "" while the game is on, else "w", "b", "d", or "v"
+// for a game that never finished.
+type TournamentPairing struct {
+	ID     int64
+	Round  int
+	RoomID string
+	White  int64
+	Black  int64
+	Result string
+}
+
+// tournamentNames caches event names for the archive's PGN rebuild: a dump
+// month holds every game of an event, and a name never changes once created.
+var tournamentNames sync.Map
+
+// InsertTournament records a newly created event.
+func InsertTournament(t TournamentInfo) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).InsertTournament(ctx, gen.InsertTournamentParams{
+		ID:           t.ID,
+		Name:         t.Name,
+		Format:       t.Format,
+		Variant:      t.Variant,
+		Rated:        t.Rated,
+		Berserk:      t.Berserk,
+		Rounds:       int16(t.Rounds),
+		DurationSecs: int32(t.Duration / time.Second),
+		CreatedBy:    t.CreatedBy,
+		StartsAt:     ts(t.StartsAt),
+	})
+}
+
+// UpdateTournamentState writes an event's progress. A zero started or
+// finished time is stored as NULL.
+func UpdateTournamentState(id, status string, round int, started, finished time.Time) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).UpdateTournamentState(ctx, gen.UpdateTournamentStateParams{
+		ID:         id,
+		Status:     status,
+		Round:      int16(round),
+		StartedAt:  optionalTs(started),
+		FinishedAt: optionalTs(finished),
+	})
+}
+
+// GetTournament reads one event. ok is false when it does not exist (or
+// Postgres is unconfigured).
+func GetTournament(id string) (TournamentInfo, bool, error) {
+	if Pool == nil {
+		return TournamentInfo{}, false, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	row, err := gen.New(Pool).GetTournament(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TournamentInfo{}, false, nil
+		}
+		return TournamentInfo{}, false, err
+	}
+	return tournamentInfo(row), true, nil
+}
+
+// OpenTournaments lists every unfinished event, for the boot restore.
+func OpenTournaments() ([]TournamentInfo, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListOpenTournaments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TournamentInfo, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, tournamentInfo(r))
+	}
+	return out, nil
+}
+
+// RecentTournaments lists up to limit finished events, newest first.
+func RecentTournaments(limit int) ([]TournamentInfo, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListRecentTournaments(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TournamentInfo, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, tournamentInfo(r))
+	}
+	return out, nil
+}
+
+// SaveTournamentStanding inserts or rewrites an entrant's standings row.
+func SaveTournamentStanding(id string, s TournamentStanding) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).UpsertTournamentPlayer(ctx, gen.UpsertTournamentPlayerParams{
+		TournamentID: id,
+		UserID:       s.UserID,
+		Rating:       int32(s.Rating),
+		Score:        float32(s.Score),
+		Tiebreak:     float32(s.Tiebreak),
+		Wins:         int16(s.Wins),
+		Draws:        int16(s.Draws),
+		Losses:       int16(s.Losses),
+		Sheet:        s.Sheet,
+		Rank:         int16(s.Rank),
+		Withdrawn:    s.Withdrawn,
+	})
+}
+
+// DeleteTournamentStanding removes an entry outright (a player leaving
+// before the start).
+func DeleteTournamentStanding(id string, userID int64) error {
+	if Pool == nil {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).DeleteTournamentPlayer(ctx, gen.DeleteTournamentPlayerParams{
+		TournamentID: id,
+		UserID:       userID,
+	})
+}
+
+// TournamentStandings lists an event's entrants in standings order.
+func TournamentStandings(id string) ([]TournamentStanding, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListTournamentPlayers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TournamentStanding, 0, len(rows))
+	for _, r := range rows {
+		s := TournamentStanding{
+			UserID:    r.UserID,
+			Username:  r.Username,
+			Rating:    int(r.Rating),
+			Score:     float64(r.Score),
+			Tiebreak:  float64(r.Tiebreak),
+			Wins:      int(r.Wins),
+			Draws:     int(r.Draws),
+			Losses:    int(r.Losses),
+			Sheet:     r.Sheet,
+			Rank:      int(r.Rank),
+			Withdrawn: r.Withdrawn,
+		}
+		if r.TitleCode != nil {
+			s.Title = *r.TitleCode
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// InsertTournamentPairing records a new pairing, returning its id for the
+// result write (0 without Postgres).
+func InsertTournamentPairing(id string, p TournamentPairing) (int64, error) {
+	if Pool == nil {
+		return 0, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	var black *int64
+	if p.Black != 0 {
+		black = &p.Black
+	}
+	return gen.New(Pool).InsertTournamentPairing(ctx, gen.InsertTournamentPairingParams{
+		TournamentID: id,
+		Round:        int16(p.Round),
+		RoomID:       p.RoomID,
+		WhiteUserID:  p.White,
+		BlackUserID:  black,
+		Result:       p.Result,
+	})
+}
+
+// SetTournamentPairingResult records a pairing's result.
+func SetTournamentPairingResult(pairingID int64, result string) error {
+	if Pool == nil || pairingID == 0 {
+		return nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	return gen.New(Pool).SetTournamentPairingResult(ctx, gen.SetTournamentPairingResultParams{
+		ID:     pairingID,
+		Result: result,
+	})
+}
+
+// TournamentPairings lists every pairing an event has made, oldest first.
+func TournamentPairings(id string) ([]TournamentPairing, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListTournamentPairings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TournamentPairing, 0, len(rows))
+	for _, r := range rows {
+		p := TournamentPairing{
+			ID:     r.ID,
+			Round:  int(r.Round),
+			RoomID: r.RoomID,
+			White:  r.WhiteUserID,
+			Result: r.Result,
+		}
+		if r.BlackUserID != nil {
+			p.Black = *r.BlackUserID
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// archivedTournamentName resolves a game row's tournament reference to the
+// event name its PGN Event tag carries, "" for a game outside any tournament
+// (or a failed lookup, which falls back to the ordinary event name).
+func archivedTournamentName(id *string) string {
+	if id == nil || Pool == nil {
+		return ""
+	}
+	if name, ok := tournamentNames.Load(*id); ok {
+		return name.(string)
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	name, err := gen.New(Pool).TournamentName(ctx, *id)
+	if err != nil {
+		return ""
+	}
+	tournamentNames.Store(*id, name)
+	return name
+}
+
+// tournamentInfo converts the generated row.
+func tournamentInfo(r gen.Tournament) TournamentInfo {
+	return TournamentInfo{
+		ID:         r.ID,
+		Name:       r.Name,
+		Format:     r.Format,
+		Variant:    r.Variant,
+		Rated:      r.Rated,
+		Berserk:    r.Berserk,
+		Rounds:     int(r.Rounds),
+		Duration:   time.Duration(r.DurationSecs) * time.Second,
+		Status:     r.Status,
+		Round:      int(r.Round),
+		CreatedBy:  r.CreatedBy,
+		StartsAt:   r.StartsAt.Time,
+		StartedAt:  r.StartedAt.Time,
+		FinishedAt: r.FinishedAt.Time,
+	}
+}
+
+// optionalTs is ts for a nullable column: the zero time is NULL.
+func optionalTs(t time.Time) pgtype.Timestamptz {
+	if t.IsZero() {
+		return pgtype.Timestamptz{}
+	}
+	return ts(t)
+}
@@ -0,0 +1,45 @@
+package db
+
+import "github.com/dechristopher/lio/db/gen"
+
+// Evaluation tuning data plane (see the tune package). The tuner pages through
+// the archive with GamesAfter, as the puzzle miner does, and reads each batch's
+// positions and results through TuningPlies. Without a live pool there is
+// nothing to read, and the tuner has no positions to fit.
+
+// TuningPly is one position an archived game reached, with the game's result.
+type TuningPly struct {
+	GameRef int32
+	Ply     int
+	OFEN    string
+	// Outcome is the game's PGN result: "1-0", "0-1" or "1/2-1/2".
+	Outcome string
+}
+
+// TuningPlies returns every position reached in the games after the after ref
+// through the through ref, in game and ply order, leaving out games that ended
+// on time or by abandonment.
+func TuningPlies(after, through int32) ([]TuningPly, error) {
+	if Pool == nil {
+		return nil, nil
+	}
+	ctx, cancel := Ctx()
+	defer cancel()
+	rows, err := gen.New(Pool).ListTuningPlies(ctx, gen.ListTuningPliesParams{
+		After:   after,
+		Through: through,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TuningPly, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, TuningPly{
+			GameRef: r.GameRef,
+			Ply:     int(r.Ply),
+			OFEN:    r.Ofen,
+			Outcome: r.Outcome,
+		})
+	}
+	return out, nil
+}
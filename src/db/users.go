@@ -23,11 +23,15 @@ var ErrUsernameTaken = errors.New("username taken")
 
 // UserRecord is the decoupled user row handed to the auth package.
 type UserRecord struct {
-	ID           int64
-	Username     string
-	Email        *string
-	PasswordHash string
-	CreatedAt    time.Time
+	ID       int64
+	Username string
+	Email    *string
+	// EmailVerified reports whether Email was proven to reach the account's
+	// owner (package mail). It describes the current address only: a change of
+	// address clears it.
+	EmailVerified bool
+	PasswordHash  string
+	CreatedAt     time.Time
 	// Title is the account's optional display title (the zero Title when
 	// unset), resolved through the titles table and shown to the left of the
 	// username wherever the name renders. Carried into the session/Viewer and
@@ -88,6 +92,7 @@ func GetUserByID(id int64) (UserRecord, bool, error) {
 		ID:              u.ID,
 		Username:        u.Username,
 		Email:           u.Email,
+		EmailVerified:   u.EmailVerifiedAt.Valid,
 		PasswordHash:    u.PasswordHash,
 		CreatedAt:       u.CreatedAt.Time,
 		Title:           title.New(row.TitleCode, row.TitleName),
@@ -115,6 +120,7 @@ func GetUserByUsername(username string) (UserRecord, bool, error) {
 		ID:              u.ID,
 		Username:        u.Username,
 		Email:           u.Email,
+		EmailVerified:   u.EmailVerifiedAt.Valid,
 		PasswordHash:    u.PasswordHash,
 		CreatedAt:       u.CreatedAt.Time,
 		Title:           title.New(row.TitleCode, row.TitleName),
@@ -178,6 +184,7 @@ func UpdatePasswordHash(id int64, phc string) error {
 }
 
 // UpdateEmail sets, replaces, or clears (email == nil) a user's optional email.
+// A changed address is unverified until its owner follows the link sent to it.
 func UpdateEmail(id int64, email *string) error {
 	ctx, cancel := Ctx()
 	defer cancel()
@@ -244,7 +244,7 @@ func deliversMate(g *octad.Game, m *octad.Move) bool {
 // randomStart builds a white-to-move starting OFEN with a random home-rank
 // arrangement for each side. White occupies rank 1, black rank 4, both retaining
 // full castle rights (no piece has moved) — the same assembly the engine uses
-// for blind-deploy openings (engine/deploy.go deployOFEN).
+// for blind-deploy openings (engine/deploy.go DeployOFEN).
 func randomStart() string {
 	return randomRank(false) + "/4/4/" + randomRank(true) + " w NCFncf - 0 1"
 }
@@ -2,6 +2,7 @@ package dispatch
 
 import (
 	"math"
+	"runtime"
 	"time"
 
 	"github.com/dechristopher/octad/v2"
@@ -9,6 +10,7 @@ import (
 	"github.com/dechristopher/lio/channel"
 	"github.com/dechristopher/lio/engine"
 	"github.com/dechristopher/lio/message"
+	"github.com/dechristopher/lio/metrics"
 	"github.com/dechristopher/lio/str"
 	"github.com/dechristopher/lio/util"
 	"github.com/dechristopher/lio/www/ws/proto"
@@ -27,7 +29,12 @@ type EngineRequest struct {
 	// Budget bounds how long the search may run (0 = unbounded): the engine
 	// iteratively deepens toward Depth and returns the best move found when
 	// the budget expires, so the bot answers in time instead of flagging.
+	// Time spent waiting for a worker comes out of it.
 	Budget time.Duration
+	// Clock is the bot's remaining clock when the request was made. The worker
+	// pool serves the lowest first; zero means the game has no clock to run
+	// out (a casual game) and queues behind every timed request.
+	Clock time.Duration
 	// Persona is the bot's difficulty bundle (room.Params.BotPersona resolved):
 	// it caps Depth and applies the persona's imperfect move selection. The
 	// zero value plays full-strength (engine.SearchPersona treats it like the
@@ -48,6 +55,7 @@ type EngineRequest struct {
 // request is tagged with the game and position it evaluates so a verdict that
 // arrives after a move landed is dropped by the room.
 type DrawRequest struct {
+	RoomID string
 	GameID string
 	OFEN   string
 	// History mirrors EngineRequest.History so the draw-verdict search is
@@ -55,7 +63,9 @@ type DrawRequest struct {
 	History []string
 	Depth   int
 	// Budget bounds the verdict search like EngineRequest.Budget (0 = unbounded)
-	Budget          time.Duration
+	Budget time.Duration
+	// Clock queues the request like EngineRequest.Clock.
+	Clock           time.Duration
 	ResponseChannel chan *message.RoomDrawEval
 	// Done, if set, signals that the requesting room has been torn down so the
 	// worker can drop its result instead of blocking on the response channel.
@@ -68,30 +78,55 @@ type DrawRequest struct {
 // (buffered) so this send never blocks even if the deploy phase has already
 // ended (see room.handleDeploy).
 type DeployRequest struct {
-	Color octad.Color
+	RoomID string
+	Color  octad.Color
 	// Random skips the expected-value scoring and deploys a uniformly random
 	// arrangement — the easy-difficulty deploy (see engine.RandomDeployment).
 	Random          bool
 	ResponseChannel chan *message.RoomBotDeploy
 }
 
+// SearchRequest is a search for work no bot's clock is waiting on: a game
+// review, a position evaluation, a puzzle check, the analysis board or the
+// lesson tutor. Search runs it on the worker pool behind every game's request
+// and returns the result to the caller.
+type SearchRequest struct {
+	// Kind labels the request in the queue-wait metrics ("review", "eval",
+	// "puzzle", "board", "tutor").
+	Kind    string
+	OFEN    string
+	History []string
+	Depth   int
+	// Budget bounds the search like EngineRequest.Budget (0 = unbounded).
+	// Nothing is flagging, so time spent queued is not charged to it.
+	Budget time.Duration
+	// Persona handicaps the search like EngineRequest.Persona; the zero value
+	// is the full-strength search engine.Search runs.
+	Persona engine.Persona
+	// Pause takes the bot's handicap pause before the request queues, for a
+	// search that answers a person's move as a bot would. Other searches start
+	// as soon as a worker is free.
+	Pause bool
+}
+
 // EngineDispatcher is a dispatcher for engine evaluation requests
 type EngineDispatcher struct {
-	Requests       chan EngineRequest
-	DeployRequests chan DeployRequest
-	DrawRequests   chan DrawRequest
+	pool *pool
 }
 
 var instance EngineDispatcher
 
+// queueWait is how long a request waited in the worker pool's queue, per
+// request kind. A bot's clock runs the whole time, so a climbing figure is
+// bots losing think time to each other: the instance needs more cores.
+var queueWait = metrics.NewHistogramVec("lio_dispatch_queue_wait_seconds",
+	"Time an engine request waited for a worker.", "kind", metrics.LatencyBuckets)
+
 // UpEngine brings the engine dispatcher online
 func UpEngine() {
-	instance = EngineDispatcher{
-		Requests:       make(chan EngineRequest),
-		DeployRequests: make(chan DeployRequest),
-		DrawRequests:   make(chan DrawRequest),
-	}
-	go instance.run()
+	instance = EngineDispatcher{pool: newPool(runtime.GOMAXPROCS(0))}
+	instance.pool.start()
+	util.Debug(str.CEng, str.DEngOk)
 
 	// pre-compute the blind-deploy candidate lists off the boot path so the
 	// first bot deploy doesn't pay the scoring cost. Warming here (engine
@@ -100,46 +135,112 @@ func UpEngine() {
 	go engine.WarmDeployCache()
 }
 
-// SubmitEngine submits a request to the engine dispatcher
+// SubmitEngine submits a request to the engine dispatcher. The bot's handicap
+// pause is taken here, before the request queues, and comes out of its budget.
 func SubmitEngine(request EngineRequest) {
-	instance.Requests <- request
+	paused := engine.Pause(request.Budget)
+	instance.pool.submit(&job{
+		kind:   "move",
+		clock:  clockPriority(request.Clock),
+		search: true,
+		run: func(waited time.Duration, smp engine.SMP) {
+			request.Budget = charge(request.Budget, paused+waited)
+			instance.worker(request, smp)
+		},
+	})
 }
 
-// SubmitDeploy submits a bot deploy-selection request to the engine dispatcher
+// SubmitDeploy submits a bot deploy-selection request to the engine dispatcher.
+// It goes to the front of the queue: the deploy phase runs on a timer of
+// seconds, and the selection itself is a lookup in a warmed cache.
 func SubmitDeploy(request DeployRequest) {
-	instance.DeployRequests <- request
+	instance.pool.submit(&job{
+		kind: "deploy",
+		run: func(time.Duration, engine.SMP) {
+			instance.deployWorker(request)
+		},
+	})
 }
 
-// SubmitDraw submits a bot draw-offer evaluation request to the engine dispatcher
+// SubmitDraw submits a bot draw-offer evaluation request to the engine
+// dispatcher, paced and charged like SubmitEngine.
 func SubmitDraw(request DrawRequest) {
-	instance.DrawRequests <- request
+	paused := engine.Pause(request.Budget)
+	instance.pool.submit(&job{
+		kind:   "draw",
+		clock:  clockPriority(request.Clock),
+		search: true,
+		run: func(waited time.Duration, smp engine.SMP) {
+			request.Budget = charge(request.Budget, paused+waited)
+			instance.drawWorker(request, smp)
+		},
+	})
 }
 
-// dispatcher for engine evaluation requests from games against the engine
-func (d *EngineDispatcher) run() {
-	util.Debug(str.CEng, str.DEngOk)
-	for {
-		select {
-		case request := <-d.Requests:
-			go d.worker(request)
-		case request := <-d.DeployRequests:
-			go d.deployWorker(request)
-		case request := <-d.DrawRequests:
-			go d.drawWorker(request)
-		}
+// Search runs r on the engine worker pool as a background job and blocks until
+// it is done. Background jobs queue behind every game's request, casual ones
+// included, so reviews and batch work only ever take cores no bot is waiting
+// for. On an instance that never brought the engine up (tests, tools) the
+// search runs on the caller's goroutine, as engine.Search does.
+func Search(r SearchRequest) engine.MoveEval {
+	if r.Pause {
+		engine.Pause(r.Budget)
+	}
+	if instance.pool == nil {
+		return engine.SearchPersonaSMP(r.OFEN, r.History, r.Depth, r.Budget, r.Persona, engine.SMP{Paced: true})
+	}
+
+	// a panicking search is handed back to the caller, whose recover (the
+	// evaluator's, the review worker's, the puzzle miner's) expects it, instead of taking the
+	// worker and the process down with it
+	done := make(chan engine.MoveEval, 1)
+	failed := make(chan any, 1)
+	instance.pool.submit(&job{
+		kind:       r.Kind,
+		search:     true,
+		background: true,
+		run: func(_ time.Duration, smp engine.SMP) {
+			defer func() {
+				if p := recover(); p != nil {
+					failed <- p
+				}
+			}()
+			smp.Paced = true
+			done <- engine.SearchPersonaSMP(r.OFEN, r.History, r.Depth, r.Budget, r.Persona, smp)
+		},
+	})
+	select {
+	case me := <-done:
+		return me
+	case p := <-failed:
+		panic(p)
 	}
 }
 
+// charge takes the time a request spent pausing and queued out of its search
+// budget: the bot's clock was running all along. An unbounded budget stays
+// unbounded, and a spent one keeps a sliver, enough for the depth-1 search
+// that always finds a move.
+func charge(budget, spent time.Duration) time.Duration {
+	if budget <= 0 {
+		return budget
+	}
+	return max(budget-spent, time.Millisecond)
+}
+
 // drawWorker evaluates the current position and decides whether the bot accepts
 // a draw offer: it agrees only when neither side is winning by more than
 // engine.DrawEvalMargin, and otherwise declines and plays on. The verdict is
 // returned on the request's response channel, buffered by the caller so this
 // send never blocks even if the game already ended and no one is reading.
-func (d *EngineDispatcher) drawWorker(r DrawRequest) {
-	eval := engine.Search(r.OFEN, r.History, r.Depth, r.Budget, engine.MinimaxAB)
+func (d *EngineDispatcher) drawWorker(r DrawRequest, smp engine.SMP) {
+	// the zero persona is the full-strength search engine.Search runs
+	smp.Paced = true
+	eval := engine.SearchPersonaSMP(r.OFEN, r.History, r.Depth, r.Budget, engine.Persona{}, smp)
 	accept := math.Abs(eval.Eval) < engine.DrawEvalMargin
 
-	util.DebugFlag("dispatch", str.CEng, "[%s] draw eval %.1f -> accept=%t", r.OFEN, eval.Eval, accept)
+	log := util.With(util.RoomID(r.RoomID), util.GameID(r.GameID))
+	log.DebugFlag("dispatch", str.CEng, "draw eval %.1f -> accept=%t ofen=%s", eval.Eval, accept, r.OFEN)
 
 	response := &message.RoomDrawEval{
 		GameID: r.GameID,
@@ -150,14 +251,15 @@ func (d *EngineDispatcher) drawWorker(r DrawRequest) {
 	select {
 	case r.ResponseChannel <- response:
 	case <-r.Done:
-		util.DebugFlag("dispatch", str.CEng, "[%s] room gone, dropping draw verdict", r.OFEN)
+		log.DebugFlag("dispatch", str.CEng, "room gone, dropping draw verdict")
 	}
 }
 
 // deployWorker chooses the bot's blind home-rank arrangement via the engine and
 // returns it on the request's response channel.
 func (d *EngineDispatcher) deployWorker(r DeployRequest) {
-	util.DebugFlag("dispatch", str.CEng, "deploy request received for %s (random=%t), selecting..",
+	log := util.With(util.RoomID(r.RoomID))
+	log.DebugFlag("dispatch", str.CEng, "deploy request received for %s (random=%t), selecting..",
 		r.Color, r.Random)
 
 	var placement engine.DeployPlacement
@@ -167,7 +269,7 @@ func (d *EngineDispatcher) deployWorker(r DeployRequest) {
 		placement = engine.SelectDeployment(r.Color)
 	}
 
-	util.DebugFlag("dispatch", str.CEng, "deploy selected %s for %s", placement, r.Color)
+	log.DebugFlag("dispatch", str.CEng, "deploy selected %s for %s", placement, r.Color)
 
 	// the caller buffers the response channel, so this send never blocks
 	// even if the room's deploy phase already ended and no one is reading
@@ -178,21 +280,26 @@ func (d *EngineDispatcher) deployWorker(r DeployRequest) {
 }
 
 // worker to actually crunch, find, and return the engine move
-func (d *EngineDispatcher) worker(r EngineRequest) {
+func (d *EngineDispatcher) worker(r EngineRequest, smp engine.SMP) {
 	// ensure upstream handlers know this move is from a bot
 	r.Ctx.IsBot = true
 	r.Ctx.UID = ""
 
-	util.DebugFlag("dispatch", str.CEng, "[%s] request received, searching(%d)..", r.OFEN, r.Depth)
+	// the requesting room's ID is the context's channel
+	log := util.With(util.RoomID(r.Ctx.Channel), util.GameID(r.GameID))
+	log.DebugFlag("dispatch", str.CEng, "request received, searching(%d) in %s with %d helpers.. ofen=%s",
+		r.Depth, r.Budget, smp.Helpers, r.OFEN)
 
-	move := engine.SearchPersona(r.OFEN, r.History, r.Depth, r.Budget, r.Persona)
+	smp.Paced = true
+	move := engine.SearchPersonaSMP(r.OFEN, r.History, r.Depth, r.Budget, r.Persona, smp)
 
-	util.DebugFlag("dispatch", str.CEng, "[%s] found move %s", r.OFEN, move.Move.String())
+	log.DebugFlag("dispatch", str.CEng, "found move %s", move.Move.String())
 
 	// write response move to given channel, but bail if the room is gone
 	response := &message.RoomMove{
 		Player: "engine",
 		GameID: r.GameID,
+		OFEN:   r.OFEN,
 		Move: proto.MovePayload{
 			Clock: proto.ClockPayload{},
 			UOI:   move.Move.String(),
@@ -203,6 +310,6 @@ func (d *EngineDispatcher) worker(r EngineRequest) {
 	select {
 	case r.ResponseChannel <- response:
 	case <-r.Done:
-		util.DebugFlag("dispatch", str.CEng, "[%s] room gone, dropping engine move %s", r.OFEN, move.Move.String())
+		log.DebugFlag("dispatch", str.CEng, "room gone, dropping engine move %s", move.Move.String())
 	}
 }
@@ -0,0 +1,245 @@
+package dispatch
+
+import (
+	"container/heap"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dechristopher/lio/engine"
+	"github.com/dechristopher/lio/lag"
+)
+
+// The engine worker pool. Every engine request runs on one of a fixed number
+// of workers, one per core Go schedules on (GOMAXPROCS), instead of on a
+// goroutine of its own: with a goroutine per request, twenty bot games thinking
+// at once meant twenty searches sharing the cores evenly and every one of them
+// running slow, the bot on two seconds as slow as the one on ten minutes.
+//
+// Requests wait in a queue ordered by the bot's remaining clock, so under load
+// the game closest to flagging is served first. A worker holds its search to
+// one thread (engine.SMP.Threads); when it starts a search with nothing else
+// queued, the workers sitting idle are lent to it as Lazy SMP helpers, and
+// taken back the moment another request arrives.
+//
+// Searches no bot's clock is waiting on — post-game review (and the fair-play
+// sampling that rides on it), the position evaluator, the puzzle miner, the
+// analysis board and the lesson tutor — run on the same workers as background
+// jobs (see Search), queued behind every game's request, casual ones included,
+// and in submission order among themselves. Run beside the pool instead, they
+// took cores the bots' searches were counting on.
+
+// job is one queued engine request.
+type job struct {
+	// kind is the request kind, "move", "draw" or "deploy", or a background
+	// search's SearchRequest.Kind (the queueWait label).
+	kind string
+	// clock orders the queue, lowest first; see clockPriority.
+	clock time.Duration
+	// seq breaks clock ties in submission order.
+	seq    uint64
+	queued time.Time
+	// search marks a job that can put helpers to use.
+	search bool
+	// background queues the job behind every game's request, whatever its
+	// clock.
+	background bool
+	// run does the work. waited is how long the job sat queued.
+	run func(waited time.Duration, smp engine.SMP)
+}
+
+// clockPriority is the queue key for a request made with the bot's clock at
+// remaining. A request without a clock (a casual game's, or remaining 0) has
+// no flag to race and goes behind every timed one.
+func clockPriority(remaining time.Duration) time.Duration {
+	if remaining <= 0 {
+		return math.MaxInt64
+	}
+	return remaining
+}
+
+// jobQueue is a min-heap of jobs: game requests before background jobs, then
+// by clock, then seq.
+type jobQueue []*job
+
+func (q jobQueue) Len() int { return len(q) }
+
+func (q jobQueue) Less(i, j int) bool {
+	if q[i].background != q[j].background {
+		return q[j].background
+	}
+	if q[i].clock != q[j].clock {
+		return q[i].clock < q[j].clock
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q jobQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *jobQueue) Push(x any) { *q = append(*q, x.(*job)) }
+
+func (q *jobQueue) Pop() any {
+	old := *q
+	j := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return j
+}
+
+// loan is a running search's borrowed helpers.
+type loan struct {
+	helpers int
+	// yield is closed to take the helpers back early.
+	yield chan struct{}
+}
+
+// pool is the engine worker pool. busy counts workers running a job and lent
+// the idle workers on loan as helpers; a worker only takes a job while
+// busy+lent is under size.
+type pool struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	size  int
+	queue jobQueue
+	seq   uint64
+	busy  int
+	lent  int
+	loans map[*loan]struct{}
+	// served counts jobs taken off the queue.
+	served uint64
+}
+
+// waitAvg is the rolling average queue wait, for the /system panel. The
+// queueWait histogram has the distribution; this is the figure an operator
+// reads at a glance.
+var waitAvg = lag.MakeMonitor("engine-queue")
+
+func newPool(size int) *pool {
+	p := &pool{size: max(size, 1), loans: make(map[*loan]struct{})}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// start launches the workers.
+func (p *pool) start() {
+	for range p.size {
+		go p.work()
+	}
+}
+
+// submit queues j. It never blocks: a queued request costs a heap slot, and
+// the caller (a room's goroutine) has better things to do than wait for one.
+func (p *pool) submit(j *job) {
+	p.mu.Lock()
+	p.seq++
+	j.seq, j.queued = p.seq, time.Now()
+	heap.Push(&p.queue, j)
+	p.reclaimLocked()
+	p.mu.Unlock()
+	p.cond.Signal()
+}
+
+// reclaimLocked takes helpers back until every queued job has a worker free to
+// take it, or nothing is left on loan. The yielded helpers stop at their next
+// node, so the overlap is a moment of oversubscription, not a wait.
+func (p *pool) reclaimLocked() {
+	for l := range p.loans {
+		if p.size-p.busy-p.lent >= len(p.queue) {
+			return
+		}
+		close(l.yield)
+		p.lent -= l.helpers
+		delete(p.loans, l)
+	}
+}
+
+// work is one worker's loop.
+func (p *pool) work() {
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 || p.busy+p.lent >= p.size {
+			p.cond.Wait()
+		}
+		j := heap.Pop(&p.queue).(*job)
+		p.busy++
+		p.served++
+
+		// lend the idle workers to a search only when nothing is waiting for
+		// them; a request that arrives later takes them back (reclaimLocked)
+		smp := engine.SMP{Threads: 1}
+		var l *loan
+		if idle := p.size - p.busy - p.lent; j.search && idle > 0 && len(p.queue) == 0 {
+			l = &loan{helpers: idle, yield: make(chan struct{})}
+			p.lent += idle
+			p.loans[l] = struct{}{}
+			smp.Helpers, smp.Yield = idle, l.yield
+		}
+		p.mu.Unlock()
+
+		waited := time.Since(j.queued)
+		queueWait.With(j.kind).Observe(waited.Seconds())
+		waitAvg.Track(j.queued)
+
+		j.run(waited, smp)
+
+		p.mu.Lock()
+		p.busy--
+		returned := false
+		if _, ok := p.loans[l]; ok {
+			p.lent -= l.helpers
+			delete(p.loans, l)
+			returned = true
+		}
+		p.mu.Unlock()
+
+		// returned helpers are free workers again: wake any that a queued job
+		// could use (this worker takes the next job itself)
+		if returned {
+			p.cond.Broadcast()
+		}
+	}
+}
+
+// Stats is a sample of the engine worker pool.
+type Stats struct {
+	// Workers is the pool size: GOMAXPROCS when the engine came up.
+	Workers int
+	// Busy is how many workers are running a request, and Lent how many idle
+	// ones are on loan to those searches as Lazy SMP helpers.
+	Busy int
+	Lent int
+	// Queued is how many requests are waiting for a worker, and OldestWait how
+	// long the longest-waiting of them has been there.
+	Queued     int
+	OldestWait time.Duration
+	// AvgWait is the rolling average time a request waited for a worker.
+	AvgWait time.Duration
+	// Served counts requests taken off the queue since boot.
+	Served uint64
+}
+
+// GetStats samples the engine worker pool. It is the zero Stats before
+// UpEngine, and on an instance that never brings the engine up.
+func GetStats() Stats {
+	if instance.pool == nil {
+		return Stats{}
+	}
+	return instance.pool.stats()
+}
+
+func (p *pool) stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := Stats{
+		Workers: p.size,
+		Busy:    p.busy,
+		Lent:    p.lent,
+		Queued:  len(p.queue),
+		AvgWait: waitAvg.Get(),
+		Served:  p.served,
+	}
+	for _, j := range p.queue {
+		s.OldestWait = max(s.OldestWait, time.Since(j.queued))
+	}
+	return s
+}
@@ -0,0 +1,154 @@
+package dispatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dechristopher/lio/engine"
+)
+
+// wait fails the test if ch does not close or deliver within a few seconds.
+func wait[T any](t *testing.T, ch <-chan T, what string) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+		var zero T
+		return zero
+	}
+}
+
+// TestPoolServesLowestClockFirst: with the only worker busy, queued requests
+// run lowest remaining clock first, ties in submission order, and a request
+// without a clock last.
+func TestPoolServesLowestClockFirst(t *testing.T) {
+	p := newPool(1)
+	p.start()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.submit(&job{kind: "move", run: func(time.Duration, engine.SMP) {
+		close(started)
+		<-release
+	}})
+	wait(t, started, "the blocking job")
+
+	order := make(chan string, 5)
+	for _, q := range []struct {
+		name  string
+		clock time.Duration
+	}{
+		{"5s", 5 * time.Second},
+		{"untimed", 0},
+		{"1s", time.Second},
+		{"3s-a", 3 * time.Second},
+		{"3s-b", 3 * time.Second},
+	} {
+		p.submit(&job{kind: "move", clock: clockPriority(q.clock), run: func(time.Duration, engine.SMP) {
+			order <- q.name
+		}})
+	}
+	close(release)
+
+	for _, want := range []string{"1s", "3s-a", "3s-b", "5s", "untimed"} {
+		if got := wait(t, order, want); got != want {
+			t.Fatalf("served %s, want %s", got, want)
+		}
+	}
+}
+
+// TestPoolServesBackgroundLast: a background search queued first still runs
+// after every game's request, an untimed one included, and background searches
+// run in submission order among themselves.
+func TestPoolServesBackgroundLast(t *testing.T) {
+	p := newPool(1)
+	p.start()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.submit(&job{kind: "move", run: func(time.Duration, engine.SMP) {
+		close(started)
+		<-release
+	}})
+	wait(t, started, "the blocking job")
+
+	order := make(chan string, 4)
+	for _, q := range []struct {
+		name       string
+		clock      time.Duration
+		background bool
+	}{
+		{"review", 0, true},
+		{"untimed", 0, false},
+		{"eval", 0, true},
+		{"5s", 5 * time.Second, false},
+	} {
+		p.submit(&job{kind: q.name, clock: clockPriority(q.clock), background: q.background,
+			run: func(time.Duration, engine.SMP) {
+				order <- q.name
+			}})
+	}
+	close(release)
+
+	for _, want := range []string{"5s", "untimed", "review", "eval"} {
+		if got := wait(t, order, want); got != want {
+			t.Fatalf("served %s, want %s", got, want)
+		}
+	}
+}
+
+// TestPoolLendsIdleWorkers: a search started on an idle pool is lent every
+// other worker as a helper and held to one thread, and a request arriving
+// later takes the helpers back and runs beside it.
+func TestPoolLendsIdleWorkers(t *testing.T) {
+	p := newPool(3)
+	p.start()
+
+	release := make(chan struct{})
+	lent := make(chan engine.SMP, 1)
+	p.submit(&job{kind: "move", search: true, run: func(_ time.Duration, smp engine.SMP) {
+		lent <- smp
+		<-release
+	}})
+	smp := wait(t, lent, "the search to start")
+	if smp.Threads != 1 || smp.Helpers != 2 {
+		t.Fatalf("search got %+v, want one thread and two helpers", smp)
+	}
+	if s := p.stats(); s.Busy != 1 || s.Lent != 2 {
+		t.Errorf("stats while lent = %+v", s)
+	}
+
+	ran := make(chan engine.SMP, 1)
+	p.submit(&job{kind: "deploy", run: func(_ time.Duration, smp engine.SMP) {
+		ran <- smp
+	}})
+	wait(t, smp.Yield, "the helpers to be taken back")
+	if got := wait(t, ran, "the second request"); got.Helpers != 0 {
+		t.Errorf("deploy was lent %d helpers", got.Helpers)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for s := p.stats(); s.Busy != 0 || s.Lent != 0; s = p.stats() {
+		if time.Now().After(deadline) {
+			t.Fatalf("pool never settled: %+v", s)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestCharge: waiting comes out of a bounded budget, never below a sliver,
+// and an unbounded budget stays unbounded.
+func TestCharge(t *testing.T) {
+	for _, c := range []struct{ budget, spent, want time.Duration }{
+		{time.Second, 300 * time.Millisecond, 700 * time.Millisecond},
+		{time.Second, 2 * time.Second, time.Millisecond},
+		{0, time.Second, 0},
+	} {
+		if got := charge(c.budget, c.spent); got != c.want {
+			t.Errorf("charge(%s, %s) = %s, want %s", c.budget, c.spent, got, c.want)
+		}
+	}
+}
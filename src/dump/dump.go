@@ -0,0 +1,249 @@
+// Package dump publishes the monthly PGN database behind the /db page: every
+// archived game of a closed UTC calendar month, rebuilt from its games row
+// through db.ArchivedPGN — the same rebuild the archive page's copy button
+// serves, so a game reads byte-for-byte identically in both — concatenated in
+// chronological order, gzip-compressed, uploaded to store.DumpBucket and
+// catalogued in the pgn_dumps table.
+//
+// The job is a background loop (see Up). A month is built once it has closed,
+// and rebuilt whenever its archived game count no longer matches the catalogue
+// — the --backfill replay can land games in a month long published. It needs
+// Postgres, the object store and a dump bucket; without any of them it stays
+// off and /db simply lists nothing.
+package dump
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/store"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+const (
+	// tick is the catalogue check cadence. A month closes once, so this only
+	// decides how soon after midnight UTC on the 1st the new file appears (and
+	// how soon a backfilled month is republished); six hours is plenty.
+	tick = 6 * time.Hour
+	// pageSize bounds the games held in memory at once while a month streams.
+	pageSize = 500
+	// monthBudget bounds one month's reads end to end. Deliberately generous: a
+	// busy month is tens of thousands of rebuilds, each with its own timing
+	// read, and a dump that is an hour late harms nobody.
+	monthBudget = time.Hour
+	// keyPrefix names the published files: "lio_octad_db_2026-09.pgn.gz".
+	keyPrefix = "lio_octad_db_"
+)
+
+// Up starts the dump loop when Postgres, the object store and the dump bucket
+// are all configured. No-op otherwise. The first pass runs immediately so a
+// fresh deploy publishes its backlog without waiting a whole tick.
+func Up() {
+	if !db.Ready() || !store.Configured() || store.DumpBucket == "" {
+		return
+	}
+	go func() {
+		safeRun()
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		for range ticker.C {
+			safeRun()
+		}
+	}()
+	util.Debug(str.CDump, "pgn dump job online")
+}
+
+// safeRun runs one catalogue pass, converting any panic into an error log. The
+// dumps are a published by-product of the archive, never on the game path, so
+// an edge case in one month must not take the whole process down with it (the
+// same reasoning as the background evaluator's safeEvalBatch).
+func safeRun() {
+	defer func() {
+		if r := recover(); r != nil {
+			util.Error(str.CDump, "dump pass panicked: %v", r)
+		}
+	}()
+	if err := Run(time.Now()); err != nil {
+		util.Error(str.CDump, "dump pass failed error=%s", err.Error())
+	}
+}
+
+// Run builds every closed month whose dump is missing or stale as of now. One
+// failing month is logged and skipped; the next pass retries it.
+func Run(now time.Time) error {
+	months, err := db.GamesByMonth()
+	if err != nil {
+		return err
+	}
+	dumps, err := db.ListDumps()
+	if err != nil {
+		return err
+	}
+	published := make(map[string]int64, len(dumps))
+	for _, d := range dumps {
+		published[d.Month] = d.Games
+	}
+
+	current := MonthKey(now)
+	for _, m := range months {
+		if !due(m, published, current) {
+			continue
+		}
+		d, err := Build(m.Month)
+		if err != nil {
+			util.Error(str.CDump, "dump build failed month=%s error=%s", m.Month, err.Error())
+			continue
+		}
+		util.Info(str.CDump, "published %s: %d games, %d bytes", d.ObjectKey, d.Games, d.Bytes)
+	}
+	return nil
+}
+
+// due reports whether a month needs (re)building: it has closed (strictly
+// before the current month — "YYYY-MM" keys order lexically), and its published
+// game count, zero when unpublished, differs from the archive's.
+func due(m db.MonthCount, published map[string]int64, current string) bool {
+	return m.Month < current && published[m.Month] != m.Games
+}
+
+// Build rebuilds one month's dump from the archive, uploads it and records it
+// in the catalogue, returning the published entry.
+func Build(month string) (db.Dump, error) {
+	from, to, err := MonthRange(month)
+	if err != nil {
+		return db.Dump{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), monthBudget)
+	defer cancel()
+
+	// the compressed month is spooled to a temp file and uploaded from disk:
+	// a busy month runs to hundreds of megabytes, which the web node this job
+	// shares must not hold on its heap
+	spool, err := os.CreateTemp("", "lio-dump-*.pgn.gz")
+	if err != nil {
+		return db.Dump{}, err
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+
+	key := ObjectKey(month)
+	zw, err := gzip.NewWriterLevel(spool, gzip.BestCompression)
+	if err != nil {
+		return db.Dump{}, err
+	}
+	// the gzip header carries the inner file name, so unpacking yields
+	// "lio_octad_db_2026-09.pgn" rather than a nameless stream
+	zw.Name = key[:len(key)-len(".gz")]
+	zw.ModTime = to
+
+	raw := &countingWriter{w: zw}
+	games, err := writeMonth(ctx, raw, from, to)
+	if err != nil {
+		return db.Dump{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return db.Dump{}, err
+	}
+
+	size, err := spool.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return db.Dump{}, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return db.Dump{}, err
+	}
+	if err := store.DumpBucket.PutStream(key, spool, size); err != nil {
+		return db.Dump{}, err
+	}
+
+	d := db.Dump{
+		Month:     month,
+		ObjectKey: key,
+		Games:     games,
+		Bytes:     size,
+		RawBytes:  raw.n,
+		BuiltAt:   time.Now(),
+	}
+	// recorded only after the upload landed, so the catalogue never lists a
+	// file that is not there
+	return d, db.RecordDump(d)
+}
+
+// writeMonth streams every game started in [from, to) to w as PGN, each
+// followed by a blank line, returning how many rows it read. A row that will
+// not replay is corrupt and logged (the archive page 404s the same row) but
+// still counted: the count is compared against the archive's own to detect
+// staleness, and a permanently skipped row must not make the month look stale
+// forever.
+func writeMonth(ctx context.Context, w io.Writer, from, to time.Time) (int64, error) {
+	var (
+		read    int64
+		afterTs time.Time
+		afterID int32
+	)
+	for {
+		rows, err := db.DumpGamesPage(ctx, from, to, afterTs, afterID, pageSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			read++
+			replayed, err := game.ReplayArchive(row.StartingOfen, row.Moves)
+			if err != nil {
+				util.Error(str.CDump, "dump replay failed game=%s: %s",
+					row.GameID.String(), err.Error())
+				continue
+			}
+			if _, err := io.WriteString(w, db.ArchivedPGN(row, replayed)+"\n\n"); err != nil {
+				return 0, err
+			}
+		}
+		if len(rows) < pageSize {
+			return read, nil
+		}
+		last := rows[len(rows)-1]
+		afterTs, afterID = last.StartTs.Time, last.ID
+	}
+}
+
+// MonthKey formats the UTC calendar month containing t as "YYYY-MM".
+func MonthKey(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// MonthRange parses a "YYYY-MM" key into its UTC [start, end) bounds.
+func MonthRange(month string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01", month, time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("dump: bad month %q", month)
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// ObjectKey names a month's file in the dump bucket.
+func ObjectKey(month string) string {
+	return keyPrefix + month + ".pgn.gz"
+}
+
+// countingWriter counts the bytes passed through to w — the uncompressed PGN
+// size, taken on the way into the gzip writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,79 @@
+package dump
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dechristopher/lio/db"
+)
+
+// TestMonthRange pins the UTC calendar bounds a month key selects, including
+// the December rollover, and rejects malformed keys (the download route feeds
+// user input straight into it).
+func TestMonthRange(t *testing.T) {
+	from, to, err := MonthRange("2026-12")
+	if err != nil {
+		t.Fatalf("MonthRange: %v", err)
+	}
+	if want := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Errorf("from = %s, want %s", from, want)
+	}
+	if want := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC); !to.Equal(want) {
+		t.Errorf("to = %s, want %s", to, want)
+	}
+
+	for _, bad := range []string{"", "2026-13", "2026-9", "../2026-09", "2026-09-01"} {
+		if _, _, err := MonthRange(bad); err == nil {
+			t.Errorf("MonthRange(%q) accepted a malformed key", bad)
+		}
+	}
+}
+
+// TestMonthKeyUTC checks a month key is taken in UTC, not the server's zone: a
+// game started late on the 31st in UTC-5 belongs to the next month's dump.
+func TestMonthKeyUTC(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+	if got := MonthKey(time.Date(2026, 8, 31, 22, 0, 0, 0, est)); got != "2026-09" {
+		t.Errorf("MonthKey = %q, want 2026-09", got)
+	}
+}
+
+// TestDue covers the rebuild decision: only closed months, and only when the
+// catalogue is missing the month or disagrees with the archive's count.
+func TestDue(t *testing.T) {
+	published := map[string]int64{"2026-07": 40, "2026-08": 52}
+	cases := []struct {
+		m    db.MonthCount
+		want bool
+	}{
+		{db.MonthCount{Month: "2026-06", Games: 10}, true},  // never published
+		{db.MonthCount{Month: "2026-07", Games: 40}, false}, // current
+		{db.MonthCount{Month: "2026-08", Games: 60}, true},  // backfilled since
+		{db.MonthCount{Month: "2026-09", Games: 5}, false},  // still open
+	}
+	for _, c := range cases {
+		if got := due(c.m, published, "2026-09"); got != c.want {
+			t.Errorf("due(%s) = %t, want %t", c.m.Month, got, c.want)
+		}
+	}
+}
+
+// TestObjectKey pins the published file name, which the gzip header's inner
+// name is derived from by trimming ".gz".
+func TestObjectKey(t *testing.T) {
+	if got := ObjectKey("2026-09"); got != "lio_octad_db_2026-09.pgn.gz" {
+		t.Errorf("ObjectKey = %q", got)
+	}
+}
+
+// TestCountingWriter checks the uncompressed size is tallied across writes.
+func TestCountingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	cw := &countingWriter{w: &buf}
+	_, _ = cw.Write([]byte("[Event \"x\"]\n"))
+	_, _ = cw.Write([]byte("1. c2 *\n\n"))
+	if cw.n != int64(buf.Len()) || cw.n != 21 {
+		t.Errorf("counted %d bytes, buffer holds %d", cw.n, buf.Len())
+	}
+}
@@ -87,13 +87,14 @@ func deployOFENChar(pt octad.PieceType, c octad.Color) string {
 	return s
 }
 
-// deployOFEN builds the white-to-move starting OFEN for a fully deployed
+// DeployOFEN builds the white-to-move starting OFEN for a fully deployed
 // position from white's and black's board-order placements. White occupies rank
 // 1 (files a..d), black rank 4 (files a..d); both retain full castle rights (no
 // piece has moved). Board order means there is no perspective mirroring here —
 // index i is always file a+i — which is the engine's natural frame; room mirrors
-// per player only for the client protocol.
-func deployOFEN(white, black DeployPlacement) string {
+// per player only for the client protocol. The self-play harness (package
+// selfplay) starts its deployed games from it.
+func DeployOFEN(white, black DeployPlacement) string {
 	var rank1, rank4 strings.Builder
 	for i := 0; i < 4; i++ {
 		rank1.WriteString(deployOFENChar(white[i], octad.White))
@@ -122,11 +123,15 @@ func positionValue(node *octad.Game, depth int) float64 {
 
 	// moves[0] is only used by mmABMax/mmABMin for debug logging of the last
 	// move; a real move keeps their depth-0 String() call safe. Deploy scoring
-	// starts from a fresh game, so there is no repetition history to track.
+	// starts from a fresh game, so there is no repetition history to track, the
+	// halfmove clock is 0, and there is no transposition table: each candidate
+	// is a small, shallow search.
+	line := &searchLine{stop: noStop}
+	key := zobristHash(node.Position())
 	if node.Position().Turn() == octad.White {
-		return mmABMax(node, moves[0], depth, -WinVal, WinVal, noStop, nil)
+		return mmABMax(node, moves[0], depth, -WinVal, WinVal, key, 0, line)
 	}
-	return mmABMin(node, moves[0], depth, -WinVal, WinVal, noStop, nil)
+	return mmABMin(node, moves[0], depth, -WinVal, WinVal, key, 0, line)
 }
 
 // scoredPlacement pairs one of a color's candidate placements with its expected
@@ -159,9 +164,9 @@ func scoreDeployments(color octad.Color, depth int) []scoredPlacement {
 		for j, theirs := range placements {
 			var ofen string
 			if color == octad.White {
-				ofen = deployOFEN(mine, theirs)
+				ofen = DeployOFEN(mine, theirs)
 			} else {
-				ofen = deployOFEN(theirs, mine)
+				ofen = DeployOFEN(theirs, mine)
 			}
 			wg.Add(1)
 			go func(i, j int, ofen string) {
@@ -293,7 +298,7 @@ func candidatePlacements(color octad.Color, depth int) []DeployPlacement {
 }
 
 // mustGame builds a game from an engine-constructed OFEN. The OFEN is always
-// well-formed (assembled by deployOFEN), so a parse error is a programming bug.
+// well-formed (assembled by DeployOFEN), so a parse error is a programming bug.
 func mustGame(ofen string) *octad.Game {
 	o, err := octad.OFEN(ofen)
 	if err != nil {
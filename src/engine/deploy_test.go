@@ -52,13 +52,13 @@ func TestDeployOFENIsPlayable(t *testing.T) {
 	placements := deployPlacements()
 	for _, w := range placements {
 		for _, b := range placements {
-			ofen := deployOFEN(w, b)
+			ofen := DeployOFEN(w, b)
 			g := mustGame(ofen) // panics on a malformed OFEN
 			if g.Position().Turn() != octad.White {
-				t.Fatalf("deployOFEN(%s,%s) = %q: not white to move", w, b, ofen)
+				t.Fatalf("DeployOFEN(%s,%s) = %q: not white to move", w, b, ofen)
 			}
 			if len(g.ValidMoves()) == 0 {
-				t.Fatalf("deployOFEN(%s,%s) = %q: no legal moves", w, b, ofen)
+				t.Fatalf("DeployOFEN(%s,%s) = %q: no legal moves", w, b, ofen)
 			}
 		}
 	}
@@ -72,7 +72,7 @@ func TestDeployOFENIsPlayable(t *testing.T) {
 func TestStandardStartValue(t *testing.T) {
 	// board-order NKPP is the canonical starting arrangement for both colors
 	std := DeployPlacement{octad.Knight, octad.King, octad.Pawn, octad.Pawn}
-	v := positionValue(mustGame(deployOFEN(std, std)), 2)
+	v := positionValue(mustGame(DeployOFEN(std, std)), 2)
 	if v < -30 || v > 30 {
 		t.Fatalf("symmetric standard start value = %.2f, want within +/-30 of 0", v)
 	}
@@ -6,6 +6,7 @@ import (
 	"github.com/dechristopher/octad/v2"
 
 	"github.com/dechristopher/lio/bus"
+	"github.com/dechristopher/lio/metrics"
 )
 
 // MoveEval contains the best move and the evaluation of the best sequence
@@ -40,6 +41,17 @@ const DrawEvalMargin float64 = 20
 // pub is the engine publisher
 var pub = bus.NewPublisher("engine", Channel)
 
+// searchSeconds and searchDepth describe every search that reached a root
+// move list — bot moves, draw verdicts, and the background evaluator and
+// analysis alike. Depth is the deepest iteration that completed, so a budgeted
+// search that ran out of clock shows up as a shallower one.
+var (
+	searchSeconds = metrics.NewHistogram("lio_engine_search_seconds",
+		"Engine search wall time, including the bot's paced sleep.", metrics.LatencyBuckets)
+	searchDepth = metrics.NewHistogram("lio_engine_search_depth",
+		"Deepest fully completed search depth.", []float64{1, 2, 3, 4, 5, 6, 7, 8, 10, 12, 16, 24, 32})
+)
+
 // Search returns the best move after running a search algorithm on the given
 // position to the given depth. A positive budget bounds how long the search
 // may run: MinimaxAB then iteratively deepens toward depth and returns the
@@ -56,7 +68,7 @@ var pub = bus.NewPublisher("engine", Channel)
 func Search(ofen string, history []string, depth int, budget time.Duration, alg SearchAlg) MoveEval {
 	// the zero Persona is full-strength (no variety, no blunders), so search
 	// takes the untouched best-move path
-	return search(ofen, history, depth, budget, alg, Persona{})
+	return search(ofen, history, depth, budget, alg, Persona{}, SMP{})
 }
 
 // SearchPersona runs the production bot search with a persona's handicaps
@@ -64,11 +76,20 @@ func Search(ofen string, history []string, depth int, budget time.Duration, alg
 // imperfectly (see searchPersonaAB). A full-strength persona (Queen) behaves
 // exactly like Search with MinimaxAB.
 func SearchPersona(ofen string, history []string, depth int, budget time.Duration, p Persona) MoveEval {
-	return search(ofen, history, p.capDepth(depth), budget, MinimaxAB, p)
+	return search(ofen, history, p.capDepth(depth), budget, MinimaxAB, p, SMP{})
+}
+
+// SearchPersonaSMP is SearchPersona on the threads smp grants: the dispatcher's
+// worker pool runs every bot search through it, holding the search to one
+// worker and lending it whatever other workers are idle as Lazy SMP helpers.
+// The move is the one SearchPersona would choose; the helpers only make the
+// search reach its depth sooner (see smp.go).
+func SearchPersonaSMP(ofen string, history []string, depth int, budget time.Duration, p Persona, smp SMP) MoveEval {
+	return search(ofen, history, p.capDepth(depth), budget, MinimaxAB, p, smp)
 }
 
-// search is the shared core of Search and SearchPersona.
-func search(ofen string, history []string, depth int, budget time.Duration, alg SearchAlg, p Persona) MoveEval {
+// search is the shared core of Search, SearchPersona and SearchPersonaSMP.
+func search(ofen string, history []string, depth int, budget time.Duration, alg SearchAlg, p Persona, smp SMP) MoveEval {
 	// establish the deadline before any parsing/setup so all engine-side
 	// overhead counts against the caller's budget
 	var deadline time.Time
@@ -94,7 +115,7 @@ func search(ofen string, history []string, depth int, budget time.Duration, alg
 	// position evaluator (db.UpEvaluator) routinely feeds archived *final*
 	// positions here — this was a real process-killing panic.
 	if len(situation.ValidMoves()) == 0 {
-		e := Evaluate(situation) // side-to-move-relative
+		e := p.Weights.Evaluate(situation) // side-to-move-relative
 		if situation.Position().Turn() == octad.Black {
 			e = -e
 		}
@@ -102,6 +123,7 @@ func search(ofen string, history []string, depth int, budget time.Duration, alg
 	}
 
 	var eval MoveEval
+	var reached int
 
 	// publish search starting message
 	pub.Publish(ofen, alg)
@@ -110,19 +132,37 @@ func search(ofen string, history []string, depth int, budget time.Duration, alg
 
 	// run selected search algorithm
 	if alg == MinimaxAB {
+		if !smp.Paced {
+			handicapSleep(deadline)
+		}
+		repHist := RepetitionHistory(history)
+		w := p.Weights
+		// one table serves the whole search, helpers included: sharing it is
+		// the only way a helper's work reaches the main search
+		tt := acquireTransTable()
+		helpers := startHelpers(situation, depth, deadline, repHist, w, tt, smp.Helpers, smp.Yield)
 		if p.fullStrength() {
-			eval = searchMinimaxAB(situation, depth, deadline, RepetitionHistory(history))
+			eval, reached = searchMinimaxAB(situation, depth, deadline, repHist, w, tt, smp.Threads)
 		} else {
-			eval = searchPersonaAB(situation, depth, deadline, RepetitionHistory(history), p)
+			eval, reached = searchPersonaAB(situation, depth, deadline, repHist, p, tt, smp.Threads)
 		}
+		// the helpers must be off the table before it is cleared and pooled
+		helpers.finish()
+		releaseTransTable(tt)
 	} else if alg == NegamaxAB {
-		eval = searchNegamaxAB(situation, depth)
+		eval, reached = searchNegamaxAB(situation, depth), depth
 	} else if alg == Random {
 		eval = randomMove(situation)
 	} else {
 		panic("invalid search algorithm")
 	}
 
+	searchSeconds.Since(start)
+	// a random move (or a persona's blunder) searched nothing
+	if reached > 0 {
+		searchDepth.Observe(float64(reached))
+	}
+
 	// publish time taken, ofen, alg, and eval to engine channel
 	pub.Publish(time.Since(start).Seconds(), ofen, alg, eval)
 
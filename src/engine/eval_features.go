@@ -1,6 +1,10 @@
 package engine
 
-import "github.com/dechristopher/octad/v2"
+import (
+	"math/bits"
+
+	"github.com/dechristopher/octad/v2"
+)
 
 // This file implements the board-aware evaluation features — pawn structure,
 // connectivity (defended pieces), and king safety — that the octad library
@@ -8,8 +12,9 @@ import "github.com/dechristopher/octad/v2"
 // empty or enemy squares, so it can't tell us which friendly pieces are
 // defended, and its attack routines are unexported. Because the board is only
 // 4x4 we implement a small self-contained attack generator here, which lets us
-// score these features as proper differentials (computed for both colors)
-// rather than side-to-move-only.
+// count these features as proper differentials (computed for both colors)
+// rather than side-to-move-only. They are counts, not scores: staticEval
+// weighs them with Weights.Terms.
 
 // boardDim is the side length of the octad board.
 const boardDim = 4
@@ -20,6 +25,9 @@ var (
 	kingDirs   = [][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
 	diagDirs   = [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
 	orthoDirs  = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+	whitePawnDirs = [][2]int{{-1, 1}, {1, 1}}
+	blackPawnDirs = [][2]int{{-1, -1}, {1, -1}}
 )
 
 // onBoard reports whether the (file, rank) pair lies on the 4x4 board.
@@ -33,16 +41,47 @@ func sqAt(f, r int) octad.Square {
 	return octad.Square(r*boardDim + f)
 }
 
-// pieceAttacks records, into out, every square attacked (and thereby defended)
-// by the piece p standing on sq, given the current occupancy. Sliding pieces
-// stop at — and include — the first occupied square they encounter.
-func pieceAttacks(squares map[octad.Square]octad.Piece, sq octad.Square, p octad.Piece, out map[octad.Square]bool) {
+// squares is a board as an array indexed by square, octad.NoPiece where
+// empty. Evaluation reads the board through it rather than octad's SquareMap,
+// which allocates a fresh map on every call — on the search's hottest path.
+type squares [boardDim * boardDim]octad.Piece
+
+// squaresOf copies b into a squares array.
+func squaresOf(b *octad.Board) squares {
+	var out squares
+	for sq := range out {
+		out[sq] = b.Piece(octad.Square(sq))
+	}
+	return out
+}
+
+// squareSet is a set of squares as a bitboard, bit n standing for Square n.
+type squareSet uint16
+
+func (s *squareSet) add(sq octad.Square) {
+	*s |= 1 << sq
+}
+
+func (s squareSet) has(sq octad.Square) bool {
+	return s&(1<<sq) != 0
+}
+
+// len is the number of squares in the set.
+func (s squareSet) len() int {
+	return bits.OnesCount16(uint16(s))
+}
+
+// pieceAttacks returns every square attacked (and thereby defended) by the
+// piece p standing on sq, given the current occupancy. Sliding pieces stop
+// at — and include — the first occupied square they encounter.
+func pieceAttacks(board *squares, sq octad.Square, p octad.Piece) squareSet {
 	f, r := int(sq.File()), int(sq.Rank())
 
+	var out squareSet
 	mark := func(dirs [][2]int) {
 		for _, d := range dirs {
 			if onBoard(f+d[0], r+d[1]) {
-				out[sqAt(f+d[0], r+d[1])] = true
+				out.add(sqAt(f+d[0], r+d[1]))
 			}
 		}
 	}
@@ -54,88 +93,106 @@ func pieceAttacks(squares map[octad.Square]octad.Piece, sq octad.Square, p octad
 		mark(kingDirs)
 	case octad.Pawn:
 		// pawns attack diagonally forward (white up the board, black down)
-		dir := 1
 		if p.Color() == octad.Black {
-			dir = -1
+			mark(blackPawnDirs)
+		} else {
+			mark(whitePawnDirs)
 		}
-		mark([][2]int{{-1, dir}, {1, dir}})
 	case octad.Bishop:
-		slideAttacks(squares, f, r, diagDirs, out)
+		out |= slideAttacks(board, f, r, diagDirs)
 	case octad.Rook:
-		slideAttacks(squares, f, r, orthoDirs, out)
+		out |= slideAttacks(board, f, r, orthoDirs)
 	case octad.Queen:
-		slideAttacks(squares, f, r, diagDirs, out)
-		slideAttacks(squares, f, r, orthoDirs, out)
+		out |= slideAttacks(board, f, r, diagDirs) | slideAttacks(board, f, r, orthoDirs)
 	}
+	return out
 }
 
 // slideAttacks walks each ray from (f, r), marking squares until it steps off
 // the board or hits an occupied square (which is itself marked as attacked).
-func slideAttacks(squares map[octad.Square]octad.Piece, f, r int, dirs [][2]int, out map[octad.Square]bool) {
+func slideAttacks(board *squares, f, r int, dirs [][2]int) squareSet {
+	var out squareSet
 	for _, d := range dirs {
 		nf, nr := f+d[0], r+d[1]
 		for onBoard(nf, nr) {
 			ns := sqAt(nf, nr)
-			out[ns] = true
-			if _, occupied := squares[ns]; occupied {
+			out.add(ns)
+			if board[ns] != octad.NoPiece {
 				break
 			}
 			nf += d[0]
 			nr += d[1]
 		}
 	}
+	return out
 }
 
 // attackedSquares returns the set of squares attacked by all pieces of color.
-func attackedSquares(squares map[octad.Square]octad.Piece, color octad.Color) map[octad.Square]bool {
-	out := make(map[octad.Square]bool)
-	for sq, p := range squares {
-		if p.Color() == color {
-			pieceAttacks(squares, sq, p, out)
+func attackedSquares(board *squares, color octad.Color) squareSet {
+	var out squareSet
+	for sq, p := range board {
+		if p != octad.NoPiece && p.Color() == color {
+			out |= pieceAttacks(board, octad.Square(sq), p)
 		}
 	}
 	return out
 }
 
-// positionalFeatures returns the side-to-move-relative contribution of pawn
-// structure, connectivity, and king safety. Every term is a differential
-// (color minus opponent), so it is positive when it favors the side to move.
-func positionalFeatures(squares map[octad.Square]octad.Piece, color octad.Color) float64 {
+// boardTerms returns the board-aware positional terms relative to color: pawn
+// structure, connectivity, king safety and mop-up (see Term). Every one is a
+// differential (color minus opponent), so it is positive when it favors color,
+// and evaluating the same board for the other color negates it.
+func boardTerms(board *squares, color octad.Color) Terms {
 	other := color.Other()
-	friendlyAttacks := attackedSquares(squares, color)
-	enemyAttacks := attackedSquares(squares, other)
+	friendlyAttacks := attackedSquares(board, color)
+	enemyAttacks := attackedSquares(board, other)
 
-	eval := pawnStructureScore(squares, color) - pawnStructureScore(squares, other)
+	var terms Terms
+
+	// pawn structure: doubled and isolated pawns are penalties, so the count
+	// is the opponent's minus ours
+	doubled, isolated, passed := pawnStructure(board, color)
+	theirDoubled, theirIsolated, theirPassed := pawnStructure(board, other)
+	terms[TermDoubledPawns] = float64(theirDoubled - doubled)
+	terms[TermIsolatedPawns] = float64(theirIsolated - isolated)
+	terms[TermPassedPawns] = float64(passed - theirPassed)
 
 	// connectivity: own non-king pieces defended by a friendly piece
-	eval += ConnectivityWeight *
-		float64(defendedCount(squares, color, friendlyAttacks)-
-			defendedCount(squares, other, enemyAttacks))
+	terms[TermConnectivity] = float64(defendedCount(board, color, friendlyAttacks) -
+		defendedCount(board, other, enemyAttacks))
 
 	// king safety: safe squares each king could flee to
-	eval += KingSafetyWeight *
-		float64(kingEscapes(squares, color, enemyAttacks)-
-			kingEscapes(squares, other, friendlyAttacks))
+	terms[TermKingSafety] = float64(kingEscapes(board, color, enemyAttacks) -
+		kingEscapes(board, other, friendlyAttacks))
+
+	// mop-up: with a bare enemy king, reward driving it to the edge and
+	// closing in with our own king, so a won endgame has a progress gradient
+	// instead of an eval-flat shuffle into the threefold-repetition draw
+	center, proximity := mopUp(board, color)
+	terms[TermMopUpCenter] = float64(center)
+	terms[TermMopUpProximity] = float64(proximity)
 
-	return eval
+	return terms
 }
 
-// pawnStructureScore scores color's pawn structure: penalties for doubled and
-// isolated pawns, a bonus for passed pawns.
-func pawnStructureScore(squares map[octad.Square]octad.Piece, color octad.Color) float64 {
+// pawnStructure counts color's doubled, isolated and passed pawns.
+func pawnStructure(board *squares, color octad.Color) (doubled, isolated, passed int) {
 	var fileCount [boardDim]int
 	type pawn struct{ f, r int }
-	var pawns []pawn
+	var pawns [boardDim * boardDim]pawn
+	var n int
 	var enemyPawn [boardDim][boardDim]bool
 
-	for sq, p := range squares {
-		if p.Type() != octad.Pawn {
+	for i, p := range board {
+		if p == octad.NoPiece || p.Type() != octad.Pawn {
 			continue
 		}
+		sq := octad.Square(i)
 		f, r := int(sq.File()), int(sq.Rank())
 		if p.Color() == color {
 			fileCount[f]++
-			pawns = append(pawns, pawn{f, r})
+			pawns[n] = pawn{f, r}
+			n++
 		} else {
 			enemyPawn[f][r] = true
 		}
@@ -147,76 +204,79 @@ func pawnStructureScore(squares map[octad.Square]octad.Piece, color octad.Color)
 		forward = -1
 	}
 
-	score := 0.0
-	for _, pw := range pawns {
+	for _, pw := range pawns[:n] {
 		// doubled: shares its file with another friendly pawn
 		if fileCount[pw.f] > 1 {
-			score -= DoubledPawnPenalty
+			doubled++
 		}
 
 		// isolated: no friendly pawn on either adjacent file
-		isolated := true
+		alone := true
 		if pw.f-1 >= 0 && fileCount[pw.f-1] > 0 {
-			isolated = false
+			alone = false
 		}
 		if pw.f+1 < boardDim && fileCount[pw.f+1] > 0 {
-			isolated = false
+			alone = false
 		}
-		if isolated {
-			score -= IsolatedPawnPenalty
+		if alone {
+			isolated++
 		}
 
 		// passed: no enemy pawn ahead on its own or an adjacent file
-		passed := true
+		free := true
 		for nf := pw.f - 1; nf <= pw.f+1; nf++ {
 			if nf < 0 || nf >= boardDim {
 				continue
 			}
 			for nr := pw.r + forward; nr >= 0 && nr < boardDim; nr += forward {
 				if enemyPawn[nf][nr] {
-					passed = false
+					free = false
 				}
 			}
 		}
-		if passed {
-			score += PassedPawnBonus
+		if free {
+			passed++
 		}
 	}
 
-	return score
+	return doubled, isolated, passed
 }
 
 // defendedCount returns how many of color's non-king pieces stand on a square
 // that one of their own pieces attacks (i.e. could recapture on).
-func defendedCount(squares map[octad.Square]octad.Piece, color octad.Color, friendlyAttacks map[octad.Square]bool) int {
+func defendedCount(board *squares, color octad.Color, friendlyAttacks squareSet) int {
 	n := 0
-	for sq, p := range squares {
-		if p.Color() != color || p.Type() == octad.King {
+	for sq, p := range board {
+		if p == octad.NoPiece || p.Color() != color || p.Type() == octad.King {
 			continue
 		}
-		if friendlyAttacks[sq] {
+		if friendlyAttacks.has(octad.Square(sq)) {
 			n++
 		}
 	}
 	return n
 }
 
-// mopUpTerm returns the side-to-move-relative mop-up score for won endgames.
-// It activates only when exactly one side has nothing but its king left: the
-// armed side is rewarded for the bare king's distance from the board center
-// (mates against a lone king happen on the edge, ideally in a corner) and for
-// closing its own king in (checkmate needs the kings near each other). This
-// gives a decisive material advantage a progress gradient — without it, every
-// safe queen shuffle evaluates alike and the engine repeats moves instead of
-// converting. Like positionalFeatures, the term is antisymmetric: computed for
-// the winning color and negated when the side to move is the bare king.
-func mopUpTerm(squares map[octad.Square]octad.Piece, color octad.Color) float64 {
-	kings := map[octad.Color]octad.Square{}
-	armed := map[octad.Color]bool{}
-	for sq, p := range squares {
-		if p.Type() == octad.King {
-			kings[p.Color()] = sq
-		} else {
+// mopUp returns the side-to-move-relative mop-up counts for won endgames.
+// They are nonzero only when exactly one side has nothing but its king left:
+// the armed side is rewarded for the bare king's distance from the board
+// center (mates against a lone king happen on the edge, ideally in a corner)
+// and for closing its own king in (checkmate needs the kings near each other).
+// This gives a decisive material advantage a progress gradient — without it,
+// every safe queen shuffle evaluates alike and the engine repeats moves
+// instead of converting. Like the other board terms, the counts are
+// antisymmetric: computed for the winning color and negated when the side to
+// move is the bare king.
+func mopUp(board *squares, color octad.Color) (center, proximity int) {
+	// indexed by color: octad.White is 1, octad.Black 2
+	kings := [3]octad.Square{octad.NoSquare, octad.NoSquare, octad.NoSquare}
+	var armed [3]bool
+	for sq, p := range board {
+		switch {
+		case p == octad.NoPiece:
+		case p.Type() == octad.King:
+			kings[p.Color()] = octad.Square(sq)
+		default:
 			armed[p.Color()] = true
 		}
 	}
@@ -229,30 +289,28 @@ func mopUpTerm(squares map[octad.Square]octad.Piece, color octad.Color) float64
 		winner = octad.Black
 	default:
 		// both sides still armed (normal play) or both bare (dead draw)
-		return 0
+		return 0, 0
 	}
 
-	wk, haveWinner := kings[winner]
-	lk, haveLoser := kings[winner.Other()]
-	if !haveWinner || !haveLoser {
-		return 0
+	wk, lk := kings[winner], kings[winner.Other()]
+	if wk == octad.NoSquare || lk == octad.NoSquare {
+		return 0, 0
 	}
 
 	lf, lr := int(lk.File()), int(lk.Rank())
 	wf, wr := int(wk.File()), int(wk.Rank())
 
 	// drive the bare king out of the center: 0 (center) to 2 (corner) on 4x4
-	score := MopUpCenterWeight * float64(centerDistance(lf)+centerDistance(lr))
+	center = centerDistance(lf) + centerDistance(lr)
 
 	// close in with the winning king: Manhattan distance 2 (as near as legal)
 	// through 2*(boardDim-1) (opposite corners)
-	dist := intAbs(wf-lf) + intAbs(wr-lr)
-	score += MopUpProximityWeight * float64(2*(boardDim-1)-dist)
+	proximity = 2*(boardDim-1) - (intAbs(wf-lf) + intAbs(wr-lr))
 
 	if winner == color {
-		return score
+		return center, proximity
 	}
-	return -score
+	return -center, -proximity
 }
 
 // centerDistance is the distance of a file or rank index from the board's
@@ -274,11 +332,11 @@ func intAbs(i int) int {
 // kingEscapes counts the squares adjacent to color's king that it could legally
 // flee to: on the board, not occupied by a friendly piece, and not attacked by
 // the enemy.
-func kingEscapes(squares map[octad.Square]octad.Piece, color octad.Color, enemyAttacks map[octad.Square]bool) int {
+func kingEscapes(board *squares, color octad.Color, enemyAttacks squareSet) int {
 	kingSq := octad.NoSquare
-	for sq, p := range squares {
-		if p.Color() == color && p.Type() == octad.King {
-			kingSq = sq
+	for sq, p := range board {
+		if p != octad.NoPiece && p.Color() == color && p.Type() == octad.King {
+			kingSq = octad.Square(sq)
 			break
 		}
 	}
@@ -294,10 +352,10 @@ func kingEscapes(squares map[octad.Square]octad.Piece, color octad.Color, enemyA
 			continue
 		}
 		ns := sqAt(nf, nr)
-		if p, occupied := squares[ns]; occupied && p.Color() == color {
+		if p := board[ns]; p != octad.NoPiece && p.Color() == color {
 			continue
 		}
-		if enemyAttacks[ns] {
+		if enemyAttacks.has(ns) {
 			continue
 		}
 		n++
@@ -8,7 +8,7 @@ import (
 	"github.com/dechristopher/octad/v2"
 )
 
-func squaresFromOFEN(t *testing.T, ofen string) map[octad.Square]octad.Piece {
+func squaresFromOFEN(t *testing.T, ofen string) *squares {
 	t.Helper()
 	o, err := octad.OFEN(ofen)
 	if err != nil {
@@ -18,13 +18,16 @@ func squaresFromOFEN(t *testing.T, ofen string) map[octad.Square]octad.Piece {
 	if err != nil {
 		t.Fatalf("NewGame(%q): %v", ofen, err)
 	}
-	return g.Position().Board().SquareMap()
+	board := squaresOf(g.Position().Board())
+	return &board
 }
 
-func sortedSquares(set map[octad.Square]bool) []string {
-	out := make([]string, 0, len(set))
-	for sq := range set {
-		out = append(out, sq.String())
+func sortedSquares(set squareSet) []string {
+	out := make([]string, 0, set.len())
+	for sq := octad.Square(0); sq < boardDim*boardDim; sq++ {
+		if set.has(sq) {
+			out = append(out, sq.String())
+		}
 	}
 	sort.Strings(out)
 	return out
@@ -80,81 +83,83 @@ func TestKingEscapes(t *testing.T) {
 	}
 }
 
-func TestPawnStructureScore(t *testing.T) {
+func TestPawnStructure(t *testing.T) {
 	// white pawns doubled & isolated on the c-file (c2, c3), both blocked from
 	// passing by the black b4 pawn; black pawns a3 (passed) and b4 (not passed).
 	squares := squaresFromOFEN(t, "1p1k/p1P1/2P1/K3 w - - 0 1")
 
-	// white: 2 pawns each doubled (-4) and isolated (-5), neither passed = -18
-	if s := pawnStructureScore(squares, octad.White); s != -2*(DoubledPawnPenalty+IsolatedPawnPenalty) {
-		t.Errorf("white pawn structure = %.1f, want %.1f", s, -2*(DoubledPawnPenalty+IsolatedPawnPenalty))
+	// white: 2 pawns each doubled and isolated, neither passed
+	if d, i, p := pawnStructure(squares, octad.White); d != 2 || i != 2 || p != 0 {
+		t.Errorf("white pawn structure = %d doubled, %d isolated, %d passed, want 2, 2, 0", d, i, p)
 	}
-	// black: a3 passed (+6), b4 neither doubled/isolated/passed = +6
-	if s := pawnStructureScore(squares, octad.Black); s != PassedPawnBonus {
-		t.Errorf("black pawn structure = %.1f, want %.1f", s, PassedPawnBonus)
+	// black: a3 passed, b4 neither doubled/isolated/passed
+	if d, i, p := pawnStructure(squares, octad.Black); d != 0 || i != 0 || p != 1 {
+		t.Errorf("black pawn structure = %d doubled, %d isolated, %d passed, want 0, 0, 1", d, i, p)
 	}
 }
 
-// TestPositionalFeaturesAntisymmetric verifies the features are a pure
+// TestBoardTermsAntisymmetric verifies the board terms are a pure
 // differential: evaluating from white's perspective is the exact negation of
 // evaluating the same board from black's perspective.
-func TestPositionalFeaturesAntisymmetric(t *testing.T) {
+func TestBoardTermsAntisymmetric(t *testing.T) {
 	for _, ofen := range randomPositions(t, 60, 8) {
 		squares := squaresFromOFEN(t, ofen)
-		w := positionalFeatures(squares, octad.White)
-		b := positionalFeatures(squares, octad.Black)
-		if w != -b {
-			t.Errorf("OFEN %s: positionalFeatures white=%.1f black=%.1f (not antisymmetric)", ofen, w, b)
+		w := boardTerms(squares, octad.White)
+		b := boardTerms(squares, octad.Black)
+		for term := range NumTerms {
+			if w[term] != -b[term] {
+				t.Errorf("OFEN %s: %s white=%.1f black=%.1f (not antisymmetric)", ofen, term, w[term], b[term])
+			}
 		}
 	}
 }
 
-// TestMopUpTerm verifies the hand-worked mop-up scores: active only against a
-// bare king, rewarding its distance from center and the winning king's
+// TestMopUp verifies the hand-worked mop-up counts: active only against a
+// bare king, counting its distance from center and the winning king's
 // proximity.
-func TestMopUpTerm(t *testing.T) {
+func TestMopUp(t *testing.T) {
 	cases := []struct {
-		name string
-		ofen string
-		want float64 // relative to white
+		name              string
+		ofen              string
+		center, proximity int // relative to white
 	}{
 		{
-			// black king cornered on d4 (center distance 2 -> 12); white king
-			// a1 at maximum Manhattan distance 6 (proximity 0)
-			name: "cornered, distant king",
-			ofen: "3k/4/1Q2/K3 w - - 0 1",
-			want: MopUpCenterWeight * 2,
+			// black king cornered on d4 (center distance 2); white king a1 at
+			// maximum Manhattan distance 6 (proximity 0)
+			name:   "cornered, distant king",
+			ofen:   "3k/4/1Q2/K3 w - - 0 1",
+			center: 2,
 		},
 		{
-			// same corner (12), white king closed to b2: distance 4 ->
-			// proximity 2 * (6-4) = 4
-			name: "cornered, king closing in",
-			ofen: "3k/4/1KQ1/4 w - - 0 1",
-			want: MopUpCenterWeight*2 + MopUpProximityWeight*2,
+			// same corner, white king closed to b2: distance 4 -> proximity
+			// 6-4 = 2
+			name:      "cornered, king closing in",
+			ofen:      "3k/4/1KQ1/4 w - - 0 1",
+			center:    2,
+			proximity: 2,
 		},
 		{
 			// both sides still armed: term inactive
 			name: "normal position",
 			ofen: "ppkn/4/4/NKPP w NCFncf - 0 1",
-			want: 0,
 		},
 		{
 			// both kings bare: no winner, term inactive
 			name: "bare kings",
 			ofen: "3k/4/4/K3 w - - 0 1",
-			want: 0,
 		},
 	}
 	for _, c := range cases {
 		squares := squaresFromOFEN(t, c.ofen)
-		if got := mopUpTerm(squares, octad.White); got != c.want {
-			t.Errorf("%s (%s): mopUpTerm(white) = %.1f, want %.1f", c.name, c.ofen, got, c.want)
+		if center, proximity := mopUp(squares, octad.White); center != c.center || proximity != c.proximity {
+			t.Errorf("%s (%s): mopUp(white) = %d, %d, want %d, %d",
+				c.name, c.ofen, center, proximity, c.center, c.proximity)
 		}
 	}
 }
 
 // TestMopUpAntisymmetric verifies mop-up is a pure differential like the other
-// positional features, over both mop-up positions and normal ones (where it is
+// board terms, over both mop-up positions and normal ones (where it is
 // zero for both sides).
 func TestMopUpAntisymmetric(t *testing.T) {
 	ofens := append(randomPositions(t, 30, 8),
@@ -164,10 +169,10 @@ func TestMopUpAntisymmetric(t *testing.T) {
 	)
 	for _, ofen := range ofens {
 		squares := squaresFromOFEN(t, ofen)
-		w := mopUpTerm(squares, octad.White)
-		b := mopUpTerm(squares, octad.Black)
-		if w != -b {
-			t.Errorf("OFEN %s: mopUpTerm white=%.1f black=%.1f (not antisymmetric)", ofen, w, b)
+		wc, wp := mopUp(squares, octad.White)
+		bc, bp := mopUp(squares, octad.Black)
+		if wc != -bc || wp != -bp {
+			t.Errorf("OFEN %s: mopUp white=%d, %d black=%d, %d (not antisymmetric)", ofen, wc, wp, bc, bp)
 		}
 	}
 }
@@ -23,12 +23,13 @@ import (
  *   X depth limiting for capping rating
  */
 
-type materialValues = map[octad.Color]float64
+// materialValues holds a sum per color, indexed by octad.Color.
+type materialValues = [3]float64
 
 const WinVal float64 = 10000
 
 // PieceVals contains the material evaluation value
-// of each piece type in octad
+// of each piece type in octad (HandTuned's Material)
 var PieceVals = map[octad.PieceType]float64{
 	octad.King:        1000,
 	octad.Queen:       90,
@@ -41,7 +42,8 @@ var PieceVals = map[octad.PieceType]float64{
 
 // Positional term weights. These are intentionally small relative to material
 // (a pawn is worth 10) so that piece count remains the dominant signal; they
-// are tunable knobs for engine strength, not correctness.
+// are tunable knobs for engine strength, not correctness. All but CheckPenalty
+// are HandTuned's Terms, the starting point lio --tune fits from.
 const (
 	// CheckPenalty is applied to the side to move when it is in check: a
 	// minor positional liability (restricted mobility, forced response).
@@ -66,7 +68,7 @@ const (
 	KingSafetyWeight float64 = 3
 	// MopUpCenterWeight rewards, per square of center distance, having pushed
 	// a bare enemy king toward the board edge/corner where it can be mated
-	// (see mopUpTerm). Only active when the opponent has just a king left.
+	// (see mopUp). Only active when the opponent has just a king left.
 	MopUpCenterWeight float64 = 6
 	// MopUpProximityWeight rewards the winning king closing the Manhattan
 	// distance to the bare enemy king; mates need the kings near each other.
@@ -93,8 +95,18 @@ func castleRightsCount(situation *octad.Game, c octad.Color) int {
 // the side to move: positive means the player whose turn it is is winning,
 // negative means they are losing, and zero is a completely drawn game. The
 // minimax search relies on this side-to-move-relative convention (see the
-// sign flip in mmABMin / the color multiplier in negamax).
+// sign flip in mmABMin / the color multiplier in negamax). It evaluates with
+// DefaultWeights.
 func Evaluate(situation *octad.Game) float64 {
+	return DefaultWeights.Evaluate(situation)
+}
+
+// Evaluate is Evaluate with weights w. A nil *Weights is DefaultWeights, so a
+// persona without weights of its own searches with the default.
+func (w *Weights) Evaluate(situation *octad.Game) float64 {
+	if w == nil {
+		w = DefaultWeights
+	}
 	color := situation.Position().Turn()
 
 	switch situation.Outcome() {
@@ -114,7 +126,7 @@ func Evaluate(situation *octad.Game) float64 {
 		break
 	}
 
-	eval := staticEval(situation, color)
+	eval := w.staticEval(situation, color)
 
 	// InCheck always refers to the side to move, and being in check is bad
 	// for the side to move, so always penalize regardless of color. (The
@@ -128,23 +140,24 @@ func Evaluate(situation *octad.Game) float64 {
 }
 
 // staticEval is the non-terminal, non-check portion of Evaluate: material,
-// piece-square tables, mobility, promotion threat, castling rights, and the
-// pawn-structure / connectivity / king-safety features. It is kept separate so
-// the check term can be verified in isolation. The result is relative to the
-// side to move (color), so each term is built as (us - them) or rewards the
-// mover directly.
-func staticEval(situation *octad.Game, color octad.Color) float64 {
-	squareMap := situation.Position().Board().SquareMap()
+// piece-square tables, and the positional terms (mobility, promotion threat,
+// castling rights, pawn structure, connectivity, king safety and mop-up; see
+// positionTerms). It is kept separate so the check term can be verified in
+// isolation. The result is relative to the side to move (color), so each term
+// is built as (us - them) or rewards the mover directly.
+func (w *Weights) staticEval(situation *octad.Game, color octad.Color) float64 {
+	board := squaresOf(situation.Position().Board())
 
 	// calculate material values and piece position values
-	material := make(materialValues)
-	posValues := make(materialValues)
-	for square, piece := range squareMap {
-		material[piece.Color()] += PieceVals[piece.Type()]
+	var material, posValues materialValues
+	for square, piece := range board {
+		if piece == octad.NoPiece {
+			continue
+		}
+		material[piece.Color()] += w.Material[piece.Type()]
 		// calc piece position values for pieces with square tables
-		if PieceSquareTables[piece.Color()][piece.Type()] != nil {
-			posValues[piece.Color()] +=
-				PieceSquareTables[piece.Color()][piece.Type()][square]
+		if table := w.Tables[piece.Color()][piece.Type()]; table != nil {
+			posValues[piece.Color()] += table[octad.Square(square)]
 		}
 	}
 
@@ -154,38 +167,5 @@ func staticEval(situation *octad.Game, color octad.Color) float64 {
 	// positional value difference
 	eval += posValues[color] - posValues[color.Other()]
 
-	// the position is non-terminal here, so the side to move has moves
-	moves := situation.ValidMoves()
-
-	// mobility: reward the side to move for having more legal options. Only
-	// the mover's move list is cheaply available, but at a fixed search depth
-	// every non-terminal leaf shares the same side to move, so this stays a
-	// consistent signal across the search tree.
-	eval += MobilityWeight * float64(len(moves))
-
-	// promotion threat: reward having a pawn that can legally promote right
-	// now. Dedupe by origin square, so a pawn with several promotion choices
-	// (queen, rook, ...) is only counted once.
-	promoters := make(map[octad.Square]bool)
-	for _, m := range moves {
-		if m.Promo() != octad.NoPieceType {
-			promoters[m.S1()] = true
-		}
-	}
-	eval += PromoWeight * float64(len(promoters))
-
-	// castling rights: reward retaining the flexibility to castle, relative
-	// to the opponent
-	eval += CastleWeight *
-		float64(castleRightsCount(situation, color)-castleRightsCount(situation, color.Other()))
-
-	// pawn structure, connectivity, and king safety (all differential)
-	eval += positionalFeatures(squareMap, color)
-
-	// mop-up: with a bare enemy king, reward driving it to the edge and
-	// closing in with our own king, so a won endgame has a progress gradient
-	// instead of an eval-flat shuffle into the threefold-repetition draw
-	eval += mopUpTerm(squareMap, color)
-
-	return eval
+	return eval + positionTerms(situation, &board, color).score(w.Terms)
 }
@@ -29,7 +29,7 @@ func TestCheckTermSign(t *testing.T) {
 			continue
 		}
 
-		want := staticEval(g, g.Position().Turn())
+		want := DefaultWeights.staticEval(g, g.Position().Turn())
 		if g.Position().InCheck() {
 			want -= CheckPenalty
 		}
@@ -50,3 +50,26 @@ func TestCheckTermSign(t *testing.T) {
 		t.Fatalf("did not sample both colors in check (white=%d black=%d)", checkedW, checkedB)
 	}
 }
+
+// TestWeightsEvaluate checks that nil weights are the default evaluation and
+// that a search's own weights are the ones scored: a heavier knight is worth
+// its extra value to whoever has one more.
+func TestWeightsEvaluate(t *testing.T) {
+	o, _ := octad.OFEN("ppk1/4/4/NKPP w - - 0 1")
+	g, _ := octad.NewGame(o)
+
+	var none *Weights
+	if got, want := none.Evaluate(g), Evaluate(g); got != want {
+		t.Errorf("nil weights evaluate %.2f, default %.2f", got, want)
+	}
+
+	heavy := HandTuned
+	heavy.Material = map[octad.PieceType]float64{}
+	for pt, v := range HandTuned.Material {
+		heavy.Material[pt] = v
+	}
+	heavy.Material[octad.Knight] += 10
+	if got, want := heavy.Evaluate(g), HandTuned.Evaluate(g)+10; got != want {
+		t.Errorf("heavier knight evaluates %.2f, want %.2f", got, want)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/dechristopher/lio/clock"
 	"github.com/dechristopher/lio/rng"
 	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/tablebase"
 	"github.com/dechristopher/lio/util"
 )
 
@@ -33,8 +34,6 @@ type minimaxABParams struct {
 	move      octad.Move
 	isWhite   bool
 	depth     int
-	evalChan  chan MoveEval
-	wg        *sync.WaitGroup
 	// stop aborts the search when set: every node returns immediately and the
 	// iteration's results are discarded by the caller (see deepeningRoot)
 	stop *atomic.Bool
@@ -42,6 +41,60 @@ type minimaxABParams struct {
 	// RepetitionHistory); nil disables repetition scoring. It is shared
 	// read-only across the root goroutines — each builds its own repTracker.
 	repHist map[string]int
+	// w is the evaluation the leaves are scored with (nil = DefaultWeights)
+	w *Weights
+	// key is the Zobrist key of situation (before move), and tt the search's
+	// shared transposition table (nil disables it)
+	key uint64
+	tt  *transTable
+	// halfMoves is situation's halfmove clock (before move), read once per
+	// search and carried down from there (see tablebaseEval)
+	halfMoves int
+}
+
+// searchLine is the state one root goroutine threads through its recursion:
+// the shared stop flag, evaluation weights and transposition table, its own
+// repetition tracker, and a private node count (summed into searchedNodes once the line is done,
+// so the hot path never contends on a shared counter).
+type searchLine struct {
+	stop  *atomic.Bool
+	rep   *repTracker
+	w     *Weights
+	tt    *transTable
+	nodes uint64
+	// clocked counts the decisive tablebase results the line has checked
+	// against the halfmove clock (see tablebaseEval)
+	clocked uint64
+}
+
+// mark is the line's count of values only its own path and clock produced:
+// threefolds scored within the line, and tablebase results the clock decided.
+// A node takes it on entry, and store keeps nothing when it has moved since.
+func (l *searchLine) mark() uint64 {
+	return l.rep.draws() + l.clocked
+}
+
+// store records a node's result in the line's table — unless the search was
+// aborted, in which case the node's value is bogus and must not be kept, or
+// the line's mark moved below the node since mark (its mark() on entering
+// it): a threefold within the line, which makes the value one only this path
+// has, or a decisive tablebase result, which makes it one only this halfmove
+// clock has. The Zobrist key has neither in it (zobrist.go).
+func (l *searchLine) store(key uint64, depth int, bound ttBound, eval float64, move uint16, mark uint64) {
+	if l.stop.Load() || l.mark() != mark {
+		return
+	}
+	l.tt.store(key, depth, bound, eval, move)
+}
+
+// searchedNodes is the running total of nodes visited by the minimax search,
+// for the benchmarks and for monitoring (see NodesSearched).
+var searchedNodes atomic.Uint64
+
+// NodesSearched returns how many nodes the minimax search has visited since
+// the process started.
+func NodesSearched() uint64 {
+	return searchedNodes.Load()
 }
 
 // noStop is a shared, never-set stop flag for searches without a deadline.
@@ -52,42 +105,61 @@ var noStop = new(atomic.Bool)
 // deadline bounds the search: it runs iterative deepening up to depth and
 // returns the best move of the last fully completed depth, so the engine
 // always answers in time instead of flagging on deep searches. repHist is the
-// real game's position occurrence counts (nil = repetition-blind).
-func searchMinimaxAB(situation *octad.Game, depth int, deadline time.Time, repHist map[string]int) MoveEval {
-	handicapSleep(deadline)
-
+// real game's position occurrence counts (nil = repetition-blind), and w the
+// evaluation the leaves are scored with (nil = DefaultWeights). tt is the
+// search's table, shared with any Lazy SMP helpers, and threads bounds the
+// root split (see evaluateRootMoves). The second result is the depth the
+// returned move was searched to.
+func searchMinimaxAB(situation *octad.Game, depth int, deadline time.Time, repHist map[string]int, w *Weights, tt *transTable, threads int) (MoveEval, int) {
 	// add a little opening variety: on the first move of the game the engine
 	// otherwise always plays its single best move (e.g. P-c2 as white), which
 	// gets repetitive to play against. Pick randomly among the near-best
 	// opening moves instead. Later moves always take the single best move.
 	if deadline.IsZero() {
 		if isOpeningPosition(situation) {
-			return pickOpeningMove(situation, depth, repHist)
+			return pickOpeningMove(situation, depth, repHist, w, tt, threads), depth
 		}
-		return minimaxABRoot(situation, depth, repHist)
+		return bestRootMove(situation, depth, repHist, w, tt, threads), depth
 	}
 
-	best, results := deepeningRoot(situation, depth, deadline, repHist)
+	best, results, reached := deepeningRoot(situation, depth, deadline, repHist, w, tt, threads)
+	// reached is 0 when only deepeningRoot's depth-1 fallback ran
+	reached = max(reached, 1)
 	if isOpeningPosition(situation) && len(results) > 0 {
-		return pickVariety(situation, results)
+		return pickVariety(situation, results), reached
 	}
-	return best
+	return best, reached
 }
 
 // handicapSleep pauses for a random moment to make the engine feel less
 // machine-instant, anywhere from a fraction of a second to 1.2 seconds — but
 // never more than a quarter of the remaining budget, so the handicap can't eat
-// the search time on a low clock.
-func handicapSleep(deadline time.Time) {
+// the search time on a low clock. It returns how long it slept.
+func handicapSleep(deadline time.Time) time.Duration {
 	sleep := clock.Centisecond * 5 * time.Duration(rng.Intn(25))
 	if !deadline.IsZero() {
 		if maxSleep := time.Until(deadline) / 4; sleep > maxSleep {
 			sleep = maxSleep
 		}
 	}
-	if sleep > 0 {
-		time.Sleep(sleep)
+	if sleep <= 0 {
+		return 0
+	}
+	time.Sleep(sleep)
+	return sleep
+}
+
+// Pause takes the handicap pause a search with the given budget would start
+// with, and returns how long it slept. The dispatcher pauses before it queues
+// a bot search and passes SMP.Paced, so a bot that is only pretending to think
+// does not hold one of the pool's workers while it does; the caller takes the
+// pause out of the budget it then searches with.
+func Pause(budget time.Duration) time.Duration {
+	var deadline time.Time
+	if budget > 0 {
+		deadline = time.Now().Add(budget)
 	}
+	return handicapSleep(deadline)
 }
 
 // deepeningRoot runs the parallel root search with iterative deepening from
@@ -97,12 +169,18 @@ func handicapSleep(deadline time.Time) {
 // bounds, so partial results can't be trusted. Depth 1 on a 4x4 board takes
 // microseconds, so a move is effectively always found in budget; if even that
 // is interrupted, a final depth-1 pass without a deadline guarantees one.
-func deepeningRoot(situation *octad.Game, maxDepth int, deadline time.Time, repHist map[string]int) (MoveEval, []MoveEval) {
+//
+// Every iteration shares tt (nil disables it), so each depth starts with the
+// previous one's best moves to order by and its subtrees to reuse. threads
+// bounds each iteration's root split (see evaluateRootMoves). The third result
+// is the deepest depth that completed (0 when only the fallback ran).
+func deepeningRoot(situation *octad.Game, maxDepth int, deadline time.Time, repHist map[string]int, w *Weights, tt *transTable, threads int) (MoveEval, []MoveEval, int) {
 	isWhite := situation.Position().Turn() == octad.White
 	moves := orderMoves(situation)
 
 	var best MoveEval
 	var results []MoveEval
+	reached := 0
 
 	for depth := 1; depth <= maxDepth; depth++ {
 		remaining := time.Until(deadline)
@@ -113,7 +191,7 @@ func deepeningRoot(situation *octad.Game, maxDepth int, deadline time.Time, repH
 		stop := new(atomic.Bool)
 		timer := time.AfterFunc(remaining, func() { stop.Store(true) })
 		iterStart := time.Now()
-		iterResults := evaluateRootMoves(situation, moves, depth, stop, repHist)
+		iterResults := evaluateRootMoves(situation, moves, depth, stop, repHist, w, tt, threads)
 		timer.Stop()
 
 		if stop.Load() {
@@ -122,6 +200,7 @@ func deepeningRoot(situation *octad.Game, maxDepth int, deadline time.Time, repH
 
 		results = iterResults
 		best = bestOf(results, moves, isWhite)
+		reached = depth
 
 		iterTime := time.Since(iterStart)
 		util.DebugFlag("engine", str.CEval, "deepening: depth %d done in %s, best %s (%2f)",
@@ -136,20 +215,30 @@ func deepeningRoot(situation *octad.Game, maxDepth int, deadline time.Time, repH
 	}
 
 	if len(results) == 0 {
-		results = evaluateRootMoves(situation, moves, 1, noStop, repHist)
+		results = evaluateRootMoves(situation, moves, 1, noStop, repHist, w, tt, threads)
 		best = bestOf(results, moves, isWhite)
 	}
 
-	return best, results
+	return best, results, reached
 }
 
 // minimaxABRoot runs the parallel alpha-beta root search and returns the single
 // best move. It is the pure, side-effect-free core of searchMinimaxAB (no
 // handicap sleep) so it can be exercised directly by tests. repHist carries the
-// real game's position occurrence counts for repetition scoring (nil disables).
-func minimaxABRoot(situation *octad.Game, depth int, repHist map[string]int) MoveEval {
+// real game's position occurrence counts for repetition scoring (nil disables),
+// and w is the evaluation (nil = DefaultWeights).
+func minimaxABRoot(situation *octad.Game, depth int, repHist map[string]int, w *Weights) MoveEval {
+	tt := acquireTransTable()
+	defer releaseTransTable(tt)
+
+	return bestRootMove(situation, depth, repHist, w, tt, 0)
+}
+
+// bestRootMove is minimaxABRoot on a caller's table and root split, for the
+// unbudgeted search, whose table may be shared with Lazy SMP helpers.
+func bestRootMove(situation *octad.Game, depth int, repHist map[string]int, w *Weights, tt *transTable, threads int) MoveEval {
 	moves := orderMoves(situation)
-	results := evaluateRootMoves(situation, moves, depth, noStop, repHist)
+	results := evaluateRootMoves(situation, moves, depth, noStop, repHist, w, tt, threads)
 	bestMove := bestOf(results, moves, situation.Position().Turn() == octad.White)
 
 	util.DebugFlag("engine", str.CEval, "chose best move: %s (%2f) for OFEN: %s",
@@ -197,38 +286,55 @@ func bestOf(results []MoveEval, moves []octad.Move, isWhite bool) MoveEval {
 // Setting stop aborts the in-flight search; the returned results are then
 // partial/unreliable and must be discarded (pass noStop for an unbounded search).
 // repHist enables repetition scoring against the real game's positions (nil
-// disables); it is read-only here and in every goroutine it fans out to.
-func evaluateRootMoves(situation *octad.Game, moves []octad.Move, depth int, stop *atomic.Bool, repHist map[string]int) []MoveEval {
+// disables); it is read-only here and in every goroutine it fans out to. tt is
+// shared by every root goroutine (nil searches without a table).
+//
+// threads bounds the goroutines the root moves are split over, each taking the
+// next unsearched move until none are left; 0 runs one per move. A search
+// queued through the dispatcher's worker pool runs on the single worker it
+// holds (threads 1), so the pool's size really is the engine's CPU bound — its
+// extra cores come from Lazy SMP helpers instead (see smp.go). Results are in
+// moves order either way.
+func evaluateRootMoves(situation *octad.Game, moves []octad.Move, depth int, stop *atomic.Bool, repHist map[string]int, w *Weights, tt *transTable, threads int) []MoveEval {
 	isWhite := situation.Position().Turn() == octad.White
+	key := zobristHash(situation.Position())
+	halfMoves := tablebase.HalfMoveClock(situation.Position())
 
-	evaluations := make(chan MoveEval, len(moves))
-	wg := &sync.WaitGroup{}
-
-	// run search for each legal move in parallel
-	for _, move := range moves {
-		wg.Add(1)
-		go minimaxABAsync(minimaxABParams{
-			situation: *situation,
-			move:      move,
-			isWhite:   isWhite,
-			depth:     depth,
-			evalChan:  evaluations,
-			wg:        wg,
-			stop:      stop,
-			repHist:   repHist,
-		})
+	workers := len(moves)
+	if threads > 0 && threads < workers {
+		workers = threads
 	}
 
-	// wait for evaluation routines to finish
-	go func() {
-		wg.Wait()
-		close(evaluations)
-	}()
+	results := make([]MoveEval, len(moves))
+	var next atomic.Int64
+	wg := &sync.WaitGroup{}
 
-	results := make([]MoveEval, 0, len(moves))
-	for evaluation := range evaluations {
-		results = append(results, evaluation)
+	// run search for the legal moves in parallel
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(moves) {
+					return
+				}
+				results[i] = minimaxABRootMove(minimaxABParams{
+					situation: *situation,
+					move:      moves[i],
+					isWhite:   isWhite,
+					depth:     depth,
+					stop:      stop,
+					repHist:   repHist,
+					w:         w,
+					key:       key,
+					tt:        tt,
+					halfMoves: halfMoves,
+				})
+			}
+		}()
 	}
+	wg.Wait()
 
 	return results
 }
@@ -245,13 +351,13 @@ func isOpeningPosition(situation *octad.Game) bool {
 // top OpeningVarietyMoves, dropping any that trail the best move by more than
 // OpeningVarietyMargin. The best move always qualifies, so a candidate is
 // always returned; positions with a single sensible move simply play it.
-func pickOpeningMove(situation *octad.Game, depth int, repHist map[string]int) MoveEval {
+func pickOpeningMove(situation *octad.Game, depth int, repHist map[string]int, w *Weights, tt *transTable, threads int) MoveEval {
 	moves := orderMoves(situation)
-	results := evaluateRootMoves(situation, moves, depth, noStop, repHist)
+	results := evaluateRootMoves(situation, moves, depth, noStop, repHist, w, tt, threads)
 	if len(results) == 0 {
 		// no moves searched (shouldn't happen for a live position); defer to
 		// the standard best-move logic and its losing-position fallback
-		return minimaxABRoot(situation, depth, repHist)
+		return minimaxABRoot(situation, depth, repHist, w)
 	}
 
 	return pickVariety(situation, results)
@@ -307,26 +413,41 @@ func pickAmong(situation *octad.Game, results []MoveEval, maxMoves int, margin f
 	return choice
 }
 
-// minimaxABAsync is a parallel wrapper for minimaxAB
-func minimaxABAsync(params minimaxABParams) {
-	defer params.wg.Done()
+// minimaxABRootMove searches one root move for evaluateRootMoves.
+func minimaxABRootMove(params minimaxABParams) MoveEval {
+	eval := searchRootMove(params)
+
+	util.DebugFlag("engine", str.CEval, "root eval: %s (%2f)",
+		params.move.String(), eval)
+
+	return MoveEval{
+		Eval: eval,
+		Move: params.move,
+	}
+}
 
+// searchRootMove plays params.move on its copy of the root and searches the
+// result. It is shared by the root split and the Lazy SMP helpers, so a helper
+// fills the table with exactly the entries the main search will probe for.
+func searchRootMove(params minimaxABParams) float64 {
+	before := params.situation.Position()
 	err := params.situation.Move(&params.move)
 	if err != nil {
 		panic(fmt.Errorf("pos: %+v, move: %+v: %w", params.situation, params.move, err))
 	}
+	key := zobristUpdate(params.key, before, params.situation.Position(), &params.move)
+	halfMoves := nextHalfMoves(params.halfMoves, before, params.situation.Position(), &params.move)
 
 	// each root goroutine gets its own path tracker over the shared history
-	eval := minimaxAB(&params.situation, &params.move, !params.isWhite, params.depth,
-		params.stop, newRepTracker(params.repHist))
-
-	util.DebugFlag("engine", str.CEval, "root eval: %s (%2f)",
-		params.move.String(), eval)
-
-	params.evalChan <- MoveEval{
-		Eval: eval,
-		Move: params.move,
+	line := &searchLine{
+		stop: params.stop,
+		rep:  newRepTracker(params.repHist),
+		w:    params.w,
+		tt:   params.tt,
 	}
+	eval := minimaxAB(&params.situation, &params.move, !params.isWhite, params.depth, key, halfMoves, line)
+	searchedNodes.Add(line.nodes)
+	return eval
 }
 
 // minimaxAB is a recursive minimax search implementation that
@@ -338,43 +459,72 @@ func minimaxAB(
 	move *octad.Move,
 	isMaxi bool,
 	depth int,
-	stop *atomic.Bool,
-	rep *repTracker,
+	key uint64,
+	halfMoves int,
+	line *searchLine,
 ) float64 {
 	if isMaxi {
-		return mmABMax(node, move, depth, -WinVal, WinVal, stop, rep)
+		return mmABMax(node, move, depth, -WinVal, WinVal, key, halfMoves, line)
 	}
-	return mmABMin(node, move, depth, -WinVal, WinVal, stop, rep)
+	return mmABMin(node, move, depth, -WinVal, WinVal, key, halfMoves, line)
 }
 
-// mmABMax is the maximizing routine for minimax with alpha-beta pruning
-func mmABMax(node *octad.Game, lastMove *octad.Move, depth int, alpha, beta float64, stop *atomic.Bool, rep *repTracker) float64 {
+// mmABMax is the maximizing routine for minimax with alpha-beta pruning. key
+// is node's Zobrist key, kept in step with each move via zobristUpdate, and
+// halfMoves its halfmove clock, kept the same way via nextHalfMoves.
+func mmABMax(node *octad.Game, lastMove *octad.Move, depth int, alpha, beta float64, key uint64, halfMoves int, line *searchLine) float64 {
 	// search aborted: unwind immediately; the caller discards the result
-	if stop.Load() {
+	if line.stop.Load() {
 		return alpha
 	}
+	line.nodes++
 
 	// a node that revisits a real-game position (or completes a threefold
 	// within this line) scores as the draw octad will eventually rule it:
 	// 0 in the search's absolute white-positive space
-	if rep != nil {
-		key := repetitionKey(node.Position().String())
-		if rep.isDrawnRepetition(key) {
+	if line.rep != nil {
+		repKey := repetitionKey(node.Position().String())
+		if line.rep.isDrawnRepetition(repKey) {
 			return 0
 		}
-		rep.enter(key)
-		defer rep.leave(key)
+		line.rep.enter(repKey)
+		defer line.rep.leave(repKey)
+	}
+
+	// a solved endgame needs no search at all: its tablebase score is exact
+	// at every depth
+	mark := line.mark()
+	eval, ok, clocked := tablebaseEval(node, halfMoves)
+	if clocked || !clockFree(halfMoves, depth) {
+		line.clocked++
+	}
+	if ok {
+		return eval
+	}
+
+	// a transposition already searched this deep needs no second search
+	entry, hit := line.tt.probe(key)
+	if hit && clockFree(halfMoves, entry.depth) {
+		if eval, ok := entry.cutoff(depth, alpha, beta); ok {
+			return eval
+		}
 	}
 
 	moves := node.ValidMoves()
 
 	if depth == 0 || len(moves) == 0 {
-		eval := Evaluate(node)
+		eval := line.w.Evaluate(node)
 		util.DebugFlag("eng-v", str.CEval, "minimax: d0: MAX move=%s eval=%2f",
 			lastMove.String(), eval)
+		line.store(key, depth, ttExact, eval, 0, mark)
 		return eval
 	}
 
+	ttOrder(moves, entry.move)
+	pos := node.Position()
+	alphaOrig := alpha
+	best := entry.move
+
 	// perform calculations as white (maximizing player)
 	for _, move := range moves {
 		err := node.Move(move)
@@ -382,53 +532,90 @@ func mmABMax(node *octad.Game, lastMove *octad.Move, depth int, alpha, beta floa
 			panic(fmt.Errorf("pos: %+v, move: %+v: %w", node, move, err))
 		}
 
-		eval := mmABMin(node, move, depth-1, alpha, beta, stop, rep)
+		eval := mmABMin(node, move, depth-1, alpha, beta,
+			zobristUpdate(key, pos, node.Position(), move),
+			nextHalfMoves(halfMoves, pos, node.Position(), move), line)
 		node.UndoMove()
 
 		util.DebugFlag("eng-v", str.CEval, "minimax: d%d: MAX move=%s eval=%2f",
 			depth, move.String(), eval)
 
 		if eval >= beta {
+			line.store(key, depth, ttLower, beta, packTTMove(move), mark)
 			return beta
 		}
 		if eval > alpha {
 			alpha = eval
+			best = packTTMove(move)
 		}
 	}
 
 	util.DebugFlag("eng-v", str.CEval, "minimax: d%d: MAX best eval=%2f",
 		depth, alpha)
 
+	// nothing beat the window's floor: alpha only bounds the value from above
+	bound := ttUpper
+	if alpha > alphaOrig {
+		bound = ttExact
+	}
+	line.store(key, depth, bound, alpha, best, mark)
+
 	return alpha
 }
 
 // mmABMin is the minimizing routine for minimax with alpha-beta pruning
-func mmABMin(node *octad.Game, lastMove *octad.Move, depth int, alpha, beta float64, stop *atomic.Bool, rep *repTracker) float64 {
+func mmABMin(node *octad.Game, lastMove *octad.Move, depth int, alpha, beta float64, key uint64, halfMoves int, line *searchLine) float64 {
 	// search aborted: unwind immediately; the caller discards the result
-	if stop.Load() {
+	if line.stop.Load() {
 		return beta
 	}
+	line.nodes++
 
 	// repetition draw: see the matching check in mmABMax (0 is a draw in both
 	// the relative and absolute conventions, so no sign flip is needed here)
-	if rep != nil {
-		key := repetitionKey(node.Position().String())
-		if rep.isDrawnRepetition(key) {
+	if line.rep != nil {
+		repKey := repetitionKey(node.Position().String())
+		if line.rep.isDrawnRepetition(repKey) {
 			return 0
 		}
-		rep.enter(key)
-		defer rep.leave(key)
+		line.rep.enter(repKey)
+		defer line.rep.leave(repKey)
+	}
+
+	// tablebase hit: see mmABMax (the score is side-to-move relative)
+	mark := line.mark()
+	eval, ok, clocked := tablebaseEval(node, halfMoves)
+	if clocked || !clockFree(halfMoves, depth) {
+		line.clocked++
+	}
+	if ok {
+		return -eval
+	}
+
+	// table entries are absolute white-positive, so the probe is the same as
+	// in mmABMax
+	entry, hit := line.tt.probe(key)
+	if hit && clockFree(halfMoves, entry.depth) {
+		if eval, ok := entry.cutoff(depth, alpha, beta); ok {
+			return eval
+		}
 	}
 
 	moves := node.ValidMoves()
 
 	if depth == 0 || len(moves) == 0 {
-		eval := -Evaluate(node)
+		eval := -line.w.Evaluate(node)
 		util.DebugFlag("eng-v", str.CEval, "minimax: d0: MIN move=%s eval=%2f",
 			lastMove.String(), eval)
+		line.store(key, depth, ttExact, eval, 0, mark)
 		return eval
 	}
 
+	ttOrder(moves, entry.move)
+	pos := node.Position()
+	betaOrig := beta
+	best := entry.move
+
 	// perform calculations as black (minimizing player)
 	for _, move := range moves {
 		err := node.Move(move)
@@ -436,22 +623,33 @@ func mmABMin(node *octad.Game, lastMove *octad.Move, depth int, alpha, beta floa
 			panic(fmt.Errorf("pos: %+v, move: %+v: %w", node, move, err))
 		}
 
-		eval := mmABMax(node, move, depth-1, alpha, beta, stop, rep)
+		eval := mmABMax(node, move, depth-1, alpha, beta,
+			zobristUpdate(key, pos, node.Position(), move),
+			nextHalfMoves(halfMoves, pos, node.Position(), move), line)
 		node.UndoMove()
 
 		util.DebugFlag("eng-v", str.CEval, "minimax: d%d: MIN move=%s eval=%2f",
 			depth, move.String(), eval)
 
 		if eval <= alpha {
+			line.store(key, depth, ttUpper, alpha, packTTMove(move), mark)
 			return alpha
 		}
 		if eval < beta {
 			beta = eval
+			best = packTTMove(move)
 		}
 	}
 
 	util.DebugFlag("eng-v", str.CEval, "minimax: d%d: MIN best eval=%2f",
 		depth, beta)
 
+	// nothing got under the window's ceiling: beta only bounds from below
+	bound := ttLower
+	if beta < betaOrig {
+		bound = ttExact
+	}
+	line.store(key, depth, bound, beta, best, mark)
+
 	return beta
 }
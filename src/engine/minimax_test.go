@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/tablebase"
 )
 
 // absEval mirrors the engine's leaf convention: an absolute,
@@ -23,6 +25,15 @@ func absEval(g *octad.Game) float64 {
 // minimax operating in absolute white-positive space. It is the
 // ground truth we compare the parallel alpha-beta engine against.
 func refMinimax(g *octad.Game, depth int) float64 {
+	// solved endgames take their tablebase score at any depth, as in the
+	// engine (flipped to absolute like absEval); the clock is read off each
+	// position, where the engine carries it down the tree
+	if v, ok, _ := tablebaseEval(g, tablebase.HalfMoveClock(g.Position())); ok {
+		if g.Position().Turn() == octad.Black {
+			v = -v
+		}
+		return v
+	}
 	moves := g.ValidMoves()
 	if depth == 0 || len(moves) == 0 {
 		return absEval(g)
@@ -82,7 +93,7 @@ func refBest(g *octad.Game, depth int) (float64, map[string]bool) {
 
 // randomPositions plays random legal moves to generate a spread of
 // non-terminal test positions.
-func randomPositions(t *testing.T, n, maxPlies int) []string {
+func randomPositions(t testing.TB, n, maxPlies int) []string {
 	t.Helper()
 	rng := rand.New(rand.NewSource(42))
 	var ofens []string
@@ -122,7 +133,7 @@ func TestMinimaxMatchesReference(t *testing.T) {
 
 		o2, _ := octad.OFEN(ofen)
 		g2, _ := octad.NewGame(o2)
-		got := minimaxABRoot(g2, diagDepth, nil)
+		got := minimaxABRoot(g2, diagDepth, nil, nil)
 
 		evalOK := got.Eval == wantBest
 		moveOK := bestMoves[got.Move.String()]
@@ -182,7 +193,7 @@ func TestMinimaxDeterminism(t *testing.T) {
 		for i := 0; i < trials; i++ {
 			o2, _ := octad.OFEN(ofen)
 			g2, _ := octad.NewGame(o2)
-			got := minimaxABRoot(g2, diagDepth, nil)
+			got := minimaxABRoot(g2, diagDepth, nil, nil)
 			evals[got.Eval]++
 			if !bestMoves[got.Move.String()] {
 				badMove++
@@ -207,7 +218,7 @@ func TestDeepeningRootMatchesFixedDepth(t *testing.T) {
 
 		o2, _ := octad.OFEN(ofen)
 		g2, _ := octad.NewGame(o2)
-		got, results := deepeningRoot(g2, diagDepth, time.Now().Add(time.Minute), nil)
+		got, results, _ := deepeningRoot(g2, diagDepth, time.Now().Add(time.Minute), nil, nil, acquireTransTable(), 0)
 
 		if got.Eval != wantBest || !bestMoves[got.Move.String()] {
 			t.Errorf("OFEN %s\n  deepening: move=%s eval=%.1f\n  ref:       best=%.1f optimalMoves=%v",
@@ -229,7 +240,7 @@ func TestDeepeningRootDeadline(t *testing.T) {
 		g, _ := octad.NewGame(o)
 
 		start := time.Now()
-		got, _ := deepeningRoot(g, 20, time.Now().Add(budget), nil)
+		got, _, _ := deepeningRoot(g, 20, time.Now().Add(budget), nil, nil, acquireTransTable(), 0)
 		elapsed := time.Since(start)
 
 		// generous slack over the budget: the abort must unwind promptly, but
@@ -251,7 +262,7 @@ func TestDeepeningRootExpiredDeadline(t *testing.T) {
 		o, _ := octad.OFEN(ofen)
 		g, _ := octad.NewGame(o)
 
-		got, _ := deepeningRoot(g, 7, time.Now().Add(-time.Second), nil)
+		got, _, _ := deepeningRoot(g, 7, time.Now().Add(-time.Second), nil, nil, nil, 0)
 		if !legalMove(g, got.Move.String()) {
 			t.Errorf("OFEN %s: returned illegal move %s", ofen, got.Move.String())
 		}
@@ -7,6 +7,7 @@ import (
 	"github.com/dechristopher/octad/v2"
 
 	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/tablebase"
 	"github.com/dechristopher/lio/util"
 )
 
@@ -25,6 +26,13 @@ func searchNegamaxAB(situation *octad.Game, depth int) MoveEval {
 	maxScore := math.Inf(-1)
 	var bestMove octad.Move
 
+	tt := acquireTransTable()
+	defer releaseTransTable(tt)
+	line := &searchLine{stop: noStop, tt: tt}
+	root := situation.Position()
+	key := zobristHash(root)
+	halfMoves := tablebase.HalfMoveClock(root)
+
 	for _, move := range situation.ValidMoves() {
 		if err := situation.Move(move); err != nil {
 			panic(fmt.Errorf("pos: %+v, move: %+v: %w", situation, move, err))
@@ -32,7 +40,9 @@ func searchNegamaxAB(situation *octad.Game, depth int) MoveEval {
 
 		// negamaxAB scores the child relative to its side to move (the
 		// opponent), so negate to score from the root player's perspective.
-		eval := -negamaxAB(situation, move, depth, math.Inf(-1), math.Inf(1))
+		eval := -negamaxAB(situation, move, depth, math.Inf(-1), math.Inf(1),
+			zobristUpdate(key, root, situation.Position(), move),
+			nextHalfMoves(halfMoves, root, situation.Position(), move), line)
 
 		situation.UndoMove()
 
@@ -59,24 +69,61 @@ func searchNegamaxAB(situation *octad.Game, depth int) MoveEval {
 // the value of the node relative to the side to move there, relying on the
 // side-to-move-relative convention of Evaluate. Each ply negates the child
 // score and swaps/negates the (alpha, beta) window.
-func negamaxAB(node *octad.Game, move *octad.Move, depth int, alpha, beta float64) float64 {
+//
+// key is node's Zobrist key, halfMoves its halfmove clock, and line carries
+// the search's transposition table (nil disables it); negamax tracks no
+// repetitions, so line's mark only ever moves for the clock. The table holds absolute white-positive values, shared with
+// the minimax search's convention, so entries are flipped on the way in and
+// out for black — which also swaps which side of the window a bound limits.
+func negamaxAB(node *octad.Game, move *octad.Move, depth int, alpha, beta float64, key uint64, halfMoves int, line *searchLine) float64 {
+	color := float64(colorMulti[node.Position().Turn()])
+
+	// solved endgame: the exact score, at any depth (see mmABMax)
+	mark := line.mark()
+	eval, ok, clocked := tablebaseEval(node, halfMoves)
+	if clocked || !clockFree(halfMoves, depth) {
+		line.clocked++
+	}
+	if ok {
+		return eval
+	}
+
+	entry, hit := line.tt.probe(key)
+	if hit && clockFree(halfMoves, entry.depth) {
+		if value, ok := negamaxCutoff(entry, color, depth, alpha, beta); ok {
+			return value
+		}
+	}
+
 	moves := node.ValidMoves()
 
 	if depth == 0 || len(moves) == 0 {
 		eval := Evaluate(node)
 		util.DebugFlag("eng-v", str.CEval, "negamax: d0|term: move=%s eval=%2f",
 			move.String(), eval)
+		line.store(key, depth, ttExact, color*eval, 0, mark)
 		return eval
 	}
 
+	ttOrder(moves, entry.move)
+	pos := node.Position()
+	alphaOrig := alpha
+	best := entry.move
+
 	value := math.Inf(-1)
 	for _, m := range moves {
 		if err := node.Move(m); err != nil {
 			panic(fmt.Errorf("pos: %+v, move: %+v: %w", node, m, err))
 		}
-		value = math.Max(value, -negamaxAB(node, m, depth-1, -beta, -alpha))
+		eval := -negamaxAB(node, m, depth-1, -beta, -alpha,
+			zobristUpdate(key, pos, node.Position(), m),
+			nextHalfMoves(halfMoves, pos, node.Position(), m), line)
 		node.UndoMove()
 
+		if eval > value {
+			value = eval
+			best = packTTMove(m)
+		}
 		alpha = math.Max(alpha, value)
 
 		util.DebugFlag("eng-v", str.CEval, "negamax: d%d: move=%s eval=%2f",
@@ -88,5 +135,49 @@ func negamaxAB(node *octad.Game, move *octad.Move, depth int, alpha, beta float6
 		}
 	}
 
+	bound := ttExact
+	if value <= alphaOrig {
+		bound = ttUpper
+	} else if value >= beta {
+		bound = ttLower
+	}
+	if color < 0 {
+		bound = flipBound(bound)
+	}
+	line.store(key, depth, bound, color*value, best, mark)
+
 	return value
 }
+
+// negamaxCutoff is ttEntry.cutoff for the fail-soft, side-to-move-relative
+// negamax: it converts the absolute entry to the node's perspective (color is
+// +1 for white to move, -1 for black) and returns the bound itself rather than
+// the window edge.
+func negamaxCutoff(e ttEntry, color float64, depth int, alpha, beta float64) (float64, bool) {
+	if e.depth < depth {
+		return 0, false
+	}
+	value, bound := color*e.eval, e.bound
+	if color < 0 {
+		bound = flipBound(bound)
+	}
+	switch {
+	case bound == ttExact,
+		bound == ttLower && value >= beta,
+		bound == ttUpper && value <= alpha:
+		return value, true
+	}
+	return 0, false
+}
+
+// flipBound converts a bound between the absolute and black-relative views:
+// negating a value turns a floor into a ceiling and vice versa.
+func flipBound(b ttBound) ttBound {
+	switch b {
+	case ttLower:
+		return ttUpper
+	case ttUpper:
+		return ttLower
+	}
+	return b
+}
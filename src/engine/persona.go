@@ -54,6 +54,14 @@ type Persona struct {
 	// engine-scored one (see RandomDeployment) — about a third of random
 	// arrangements are materially inferior.
 	RandomDeploy bool
+	// Takebacks is how many takebacks the bot grants per game in a room that
+	// allows them (0 = none): the gentle rungs forgive a slip or several, the
+	// full-strength Queen never does. The room counts the grants.
+	Takebacks int
+	// Weights is the evaluation the bot searches with (nil = DefaultWeights).
+	// The ladder plays the default; lio --tune hands a fitted candidate in
+	// here to measure it against the current evaluation in self-play.
+	Weights *Weights
 }
 
 // Personas is the fixed difficulty ladder, weakest first. The chess pieces
@@ -72,6 +80,7 @@ var Personas = []Persona{
 		BlunderRate:   0.35,
 		TimeReserve:   0.85,
 		RandomDeploy:  true,
+		Takebacks:     5,
 	},
 	{
 		Key:           "knight",
@@ -85,6 +94,7 @@ var Personas = []Persona{
 		BlunderRate:   0.12,
 		TimeReserve:   0.65,
 		RandomDeploy:  true,
+		Takebacks:     3,
 	},
 	{
 		Key:           "bishop",
@@ -96,6 +106,7 @@ var Personas = []Persona{
 		VarietyMoves:  3,
 		VarietyMargin: 10,
 		TimeReserve:   0.4,
+		Takebacks:     2,
 	},
 	{
 		Key:           "rook",
@@ -107,6 +118,7 @@ var Personas = []Persona{
 		VarietyMoves:  2,
 		VarietyMargin: 4,
 		TimeReserve:   0.25,
+		Takebacks:     1,
 	},
 	{
 		Key:      "queen",
@@ -148,34 +160,35 @@ func (p Persona) capDepth(depth int) int {
 }
 
 // searchPersonaAB is the persona-handicapped counterpart of searchMinimaxAB:
-// the same paced sleep and (budgeted) root search, but the move is picked
+// the same (budgeted) root search, but the move is picked
 // imperfectly — a BlunderRate roll falls through to a uniform random legal
 // move, and everything else picks among the top VarietyMoves root moves within
 // VarietyMargin of best on every move of the game (which subsumes the opening
-// variety of the full-strength path).
-func searchPersonaAB(situation *octad.Game, depth int, deadline time.Time, repHist map[string]int, p Persona) MoveEval {
-	handicapSleep(deadline)
-
+// variety of the full-strength path). tt and threads are searchMinimaxAB's. The
+// second result is the depth searched, 0 for a blunder, which searches nothing.
+func searchPersonaAB(situation *octad.Game, depth int, deadline time.Time, repHist map[string]int, p Persona, tt *transTable, threads int) (MoveEval, int) {
 	if p.BlunderRate > 0 && rng.Float64() < p.BlunderRate {
 		moves := situation.ValidMoves()
 		choice := MoveEval{Move: *moves[rng.Intn(len(moves))]}
 		util.DebugFlag("engine", str.CEval, "persona %s blundered: %s for OFEN: %s",
 			p.Key, choice.Move.String(), situation.Position().String())
-		return choice
+		return choice, 0
 	}
 
 	var results []MoveEval
+	reached := depth
 	if deadline.IsZero() {
 		moves := orderMoves(situation)
-		results = evaluateRootMoves(situation, moves, depth, noStop, repHist)
+		results = evaluateRootMoves(situation, moves, depth, noStop, repHist, p.Weights, tt, threads)
 	} else {
-		_, results = deepeningRoot(situation, depth, deadline, repHist)
+		_, results, reached = deepeningRoot(situation, depth, deadline, repHist, p.Weights, tt, threads)
+		reached = max(reached, 1)
 	}
 	if len(results) == 0 {
 		// no moves searched (shouldn't happen for a live position); defer to
 		// the standard best-move logic and its losing-position fallback
-		return minimaxABRoot(situation, depth, repHist)
+		return minimaxABRoot(situation, depth, repHist, p.Weights), depth
 	}
 
-	return pickAmong(situation, results, p.VarietyMoves, p.VarietyMargin)
+	return pickAmong(situation, results, p.VarietyMoves, p.VarietyMargin), reached
 }
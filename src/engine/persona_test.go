@@ -25,7 +25,7 @@ func TestMain(m *testing.M) {
 // may pick a non-mate.
 const mateInOneOFEN = "k3/2Q1/K3/4 w - - 0 10"
 
-func gameFromOFEN(t *testing.T, ofen string) *octad.Game {
+func gameFromOFEN(t testing.TB, ofen string) *octad.Game {
 	t.Helper()
 	o, err := octad.OFEN(ofen)
 	if err != nil {
@@ -144,7 +144,7 @@ func TestPickAmong(t *testing.T) {
 // margin, so the variety pick can never select one.
 func TestPersonaNeverMissesForcedMate(t *testing.T) {
 	// sanity: the position really is mate in one at the shallowest depth
-	if best := minimaxABRoot(gameFromOFEN(t, mateInOneOFEN), 2, nil); best.Eval < WinVal/2 {
+	if best := minimaxABRoot(gameFromOFEN(t, mateInOneOFEN), 2, nil, nil); best.Eval < WinVal/2 {
 		t.Fatalf("fixture broken: best eval %.1f is not a forced mate", best.Eval)
 	}
 
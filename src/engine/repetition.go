@@ -48,6 +48,10 @@ func RepetitionHistory(ofens []string) map[string]int {
 type repTracker struct {
 	hist map[string]int
 	path map[string]int
+	// pathDraws counts the draws scored for a threefold within the line. A
+	// node whose subtree raised it has a path-dependent value and is kept out
+	// of the transposition table.
+	pathDraws uint64
 }
 
 // newRepTracker returns a tracker over hist, or nil when there is no history
@@ -65,7 +69,22 @@ func newRepTracker(hist map[string]int) *repTracker {
 // steering back until the automatic threefold lands), or the search line
 // itself contains a genuine threefold (two prior occurrences plus this one).
 func (rt *repTracker) isDrawnRepetition(key string) bool {
-	return rt.hist[key] > 0 || rt.path[key] >= 2
+	if rt.hist[key] > 0 {
+		return true
+	}
+	if rt.path[key] >= 2 {
+		rt.pathDraws++
+		return true
+	}
+	return false
+}
+
+// draws returns pathDraws, 0 for a nil tracker.
+func (rt *repTracker) draws() uint64 {
+	if rt == nil {
+		return 0
+	}
+	return rt.pathDraws
 }
 
 // enter/leave bracket a node's time on the current search line, mirroring the
@@ -80,7 +80,7 @@ func playEngineMove(t *testing.T, g *octad.Game, depth int) MoveEval {
 	if err != nil {
 		t.Fatalf("NewGame(%q): %v", g.Position().String(), err)
 	}
-	best := minimaxABRoot(fresh, depth, hist)
+	best := minimaxABRoot(fresh, depth, hist, nil)
 	for _, m := range g.ValidMoves() {
 		if m.String() == best.Move.String() {
 			if err := g.Move(m); err != nil {
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/tablebase"
+)
+
+// Lazy SMP. A search split only at the root keeps every core busy at the start
+// of an iteration and few of them by its end: the root moves finish at very
+// different times, and the last, hardest subtree runs alone. Lazy SMP puts the
+// idle cores to work the simple way — helper threads run the same iterative
+// deepening over the same root, writing into the search's own transposition
+// table, and the main search finds the subtrees they have already settled.
+//
+// Helpers are staggered so they do not all walk the same tree in lockstep:
+// each starts the root at a different move, and every other one starts a ply
+// deeper, working on the iteration the main search will reach next. Their
+// results are thrown away, but what they store is not: an entry settles any
+// node searched no deeper than it (see transtable.go), so the helper a ply
+// ahead hands the main search deeper values than it would have computed
+// itself, as well as sooner.
+
+// SMP is the share of the machine one search may use. The zero value is the
+// unpooled search: one goroutine per root move and no helpers, which is what
+// the callers outside the dispatcher (analysis, the evaluator, puzzles) run.
+type SMP struct {
+	// Threads bounds the goroutines the root moves are split over; 0 runs one
+	// per move.
+	Threads int
+	// Helpers is the number of Lazy SMP helper threads run beside the search.
+	Helpers int
+	// Yield, when closed, stops the helpers early so their cores can go to
+	// another search. The search itself carries on to its own result.
+	Yield <-chan struct{}
+	// Paced reports that the caller has already taken the handicap pause (see
+	// Pause), so the search starts at once.
+	Paced bool
+}
+
+// helperSet is one search's helper threads.
+type helperSet struct {
+	stop atomic.Bool
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startHelpers launches n helpers on situation, sharing tt, and returns the
+// set for the search to finish. They stop at the deadline, when yield closes
+// or when finish is called, whichever comes first. n <= 0 starts none.
+func startHelpers(situation *octad.Game, maxDepth int, deadline time.Time, repHist map[string]int, w *Weights, tt *transTable, n int, yield <-chan struct{}) *helperSet {
+	h := &helperSet{done: make(chan struct{})}
+	if n <= 0 || tt == nil {
+		return h
+	}
+
+	// warms the root validMoves cache before any goroutine copies the game,
+	// as evaluateRootMoves' callers must
+	moves := orderMoves(situation)
+	if len(moves) == 0 {
+		return h
+	}
+
+	for i := range n {
+		// rotate the root so each helper opens on a different move
+		k := (i + 1) % len(moves)
+		order := append(append(make([]octad.Move, 0, len(moves)), moves[k:]...), moves[:k]...)
+
+		h.wg.Add(1)
+		go h.run(*situation, order, 1+i%2, maxDepth, repHist, w, tt)
+	}
+	go h.watch(deadline, yield)
+	return h
+}
+
+// run is one helper: iterative deepening from depth from to maxDepth over the
+// root moves in order, until stopped or out of depths.
+func (h *helperSet) run(root octad.Game, order []octad.Move, from, maxDepth int, repHist map[string]int, w *Weights, tt *transTable) {
+	defer h.wg.Done()
+
+	isWhite := root.Position().Turn() == octad.White
+	key := zobristHash(root.Position())
+	halfMoves := tablebase.HalfMoveClock(root.Position())
+
+	for depth := from; depth <= maxDepth; depth++ {
+		for _, move := range order {
+			if h.stop.Load() {
+				return
+			}
+			searchRootMove(minimaxABParams{
+				situation: root,
+				move:      move,
+				isWhite:   isWhite,
+				depth:     depth,
+				stop:      &h.stop,
+				repHist:   repHist,
+				w:         w,
+				key:       key,
+				tt:        tt,
+				halfMoves: halfMoves,
+			})
+		}
+	}
+}
+
+// watch stops the helpers at the deadline or when the search yields them.
+func (h *helperSet) watch(deadline time.Time, yield <-chan struct{}) {
+	var expired <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case <-expired:
+	case <-yield:
+	case <-h.done:
+	}
+	h.stop.Store(true)
+}
+
+// finish stops the helpers and waits for them to leave the table.
+func (h *helperSet) finish() {
+	h.stop.Store(true)
+	close(h.done)
+	h.wg.Wait()
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestRootSplitThreads checks that bounding the root split changes nothing
+// but the parallelism: one thread returns the same evals, in the same order,
+// as one goroutine per move.
+func TestRootSplitThreads(t *testing.T) {
+	const depth = 3
+	for _, ofen := range randomPositions(t, 6, 8) {
+		g := gameFromOFEN(t, ofen)
+		moves := orderMoves(g)
+		want := evaluateRootMoves(g, moves, depth, noStop, nil, nil, nil, 0)
+		got := evaluateRootMoves(g, moves, depth, noStop, nil, nil, nil, 1)
+		if len(got) != len(want) {
+			t.Fatalf("OFEN %s: %d results on one thread, %d split", ofen, len(got), len(want))
+		}
+		for i := range want {
+			if got[i].Move != want[i].Move || got[i].Eval != want[i].Eval {
+				t.Errorf("OFEN %s result %d: %s (%.1f) on one thread, %s (%.1f) split",
+					ofen, i, got[i].Move.String(), got[i].Eval, want[i].Move.String(), want[i].Eval)
+			}
+		}
+	}
+}
+
+// TestHelpersShareTable checks a one-thread search with helpers filling the
+// table alongside it still scores every root move, in order, within the
+// search's bounds. The values may differ from the table-less search's: an
+// entry a helper stored deeper settles the node with its deeper value.
+func TestHelpersShareTable(t *testing.T) {
+	const depth = 4
+	for _, ofen := range randomPositions(t, 6, 8) {
+		g := gameFromOFEN(t, ofen)
+		moves := orderMoves(g)
+
+		tt := acquireTransTable()
+		helpers := startHelpers(g, depth, time.Time{}, nil, nil, tt, 3, nil)
+		got := evaluateRootMoves(g, moves, depth, noStop, nil, nil, tt, 1)
+		helpers.finish()
+		releaseTransTable(tt)
+
+		if len(got) != len(moves) {
+			t.Fatalf("OFEN %s: %d results for %d moves", ofen, len(got), len(moves))
+		}
+		for i, r := range got {
+			if r.Move != moves[i] || r.Eval < -WinVal || r.Eval > WinVal {
+				t.Errorf("OFEN %s result %d: %s (%.1f), want %s within ±%.0f",
+					ofen, i, r.Move.String(), r.Eval, moves[i].String(), WinVal)
+			}
+		}
+	}
+}
+
+// TestHelpersYield checks that closing Yield stops the helpers while the
+// search runs on, and that finish still returns after they are gone.
+func TestHelpersYield(t *testing.T) {
+	g := gameFromOFEN(t, randomPositions(t, 1, 8)[0])
+	tt := acquireTransTable()
+	defer releaseTransTable(tt)
+
+	yield := make(chan struct{})
+	helpers := startHelpers(g, 32, time.Time{}, nil, nil, tt, 2, yield)
+	close(yield)
+
+	stopped := make(chan struct{})
+	go func() {
+		helpers.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("helpers still running after yield")
+	}
+	helpers.finish()
+}
+
+// BenchmarkLazySMP compares the depth a budgeted search reaches when the
+// machine's cores come from splitting the root ("split", the unpooled search)
+// against one thread with the rest lent as helpers ("helpers", the pooled
+// search), with a lone thread ("single") as the floor.
+func BenchmarkLazySMP(b *testing.B) {
+	ofens := randomPositions(b, 6, 8)
+	procs := runtime.GOMAXPROCS(0)
+	for _, c := range []struct {
+		name             string
+		threads, helpers int
+	}{
+		{"split", 0, 0},
+		{"helpers", 1, procs - 1},
+		{"single", 1, 0},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			total := 0
+			for i := 0; i < b.N; i++ {
+				g := gameFromOFEN(b, ofens[i%len(ofens)])
+				deadline := time.Now().Add(benchBudget)
+				tt := acquireTransTable()
+				helpers := startHelpers(g, 32, deadline, nil, nil, tt, c.helpers, nil)
+				_, _, reached := deepeningRoot(g, 32, deadline, nil, nil, tt, c.threads)
+				helpers.finish()
+				releaseTransTable(tt)
+				total += reached
+			}
+			b.ReportMetric(float64(total)/float64(b.N), "depth/op")
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/tablebase"
+)
+
+// tablebasePlyCost is how much of WinVal a tablebase win gives up per ply to
+// mate. Without it every won endgame scores the same WinVal whether mate is one
+// move away or twenty, so the search has no reason to make progress and a bot
+// with a winning position shuffles until the 25-move rule draws it. Charging
+// per ply makes the shortest mate the best move.
+//
+// Forced mates are two plies apart at the least (the loser moves in between),
+// so consecutive mate distances are 2*tablebasePlyCost = 64 apart — more than
+// any persona's margin (see persona.go), so a weakened persona still never
+// prefers a slower mate over the fastest one. And at the tables' longest
+// distance (253 plies) a win still scores far above any heuristic eval.
+const tablebasePlyCost = 32
+
+// tablebaseEval returns node's exact score for the side to move from the
+// endgame tablebase: WinVal less the distance to mate for a win, the negation
+// for a loss, and 0 for a draw — even a draw the heuristics would score as a
+// big material edge (a lone bishop up, say). Games that are already over go
+// to Evaluate, which knows how octad ruled them. A win or loss whose mate
+// lies beyond what the halfmove clock leaves of the 25-move rule is no result
+// at all — it misses, as Probe does — so the search never steers into a win
+// the rule would draw.
+//
+// halfMoves is node's halfmove clock. The search carries it down the tree
+// (nextHalfMoves) rather than have Probe format every node's OFEN to read it.
+// clocked reports a win or loss checked against it, hit or miss: the answer
+// was the clock's as much as the position's, and the table, whose key leaves
+// the clock out, must not keep anything computed from it (searchLine.mark).
+func tablebaseEval(node *octad.Game, halfMoves int) (eval float64, ok, clocked bool) {
+	if node.Outcome() != octad.NoOutcome {
+		return 0, false, false
+	}
+	r, found := tablebase.Lookup(node.Position())
+	if !found {
+		return 0, false, false
+	}
+	if r.WDL == tablebase.Draw {
+		return 0, true, false
+	}
+	if !r.Lands(halfMoves) {
+		return 0, false, true
+	}
+	eval = WinVal - tablebasePlyCost*float64(r.DTM)
+	if r.WDL == tablebase.Loss {
+		eval = -eval
+	}
+	return eval, true, true
+}
+
+// clockFree reports whether a node with halfMoves on the clock, searched depth
+// plies deep, is out of reach of the 25-move rule: nothing in its tree can be
+// drawn by it, so the clock plays no part in its value. Only such values are
+// kept in, or taken from, the transposition table.
+func clockFree(halfMoves, depth int) bool {
+	return halfMoves+depth < 50
+}
+
+// nextHalfMoves is the halfmove clock after m, played from before to after,
+// by octad's own rule (Position.Update): a pawn move, a capture or a change of
+// castling rights resets it, and anything else counts one more ply.
+func nextHalfMoves(halfMoves int, before, after *octad.Position, m *octad.Move) int {
+	if m.HasTag(octad.Capture) || before.Board().Piece(m.S1()).Type() == octad.Pawn ||
+		before.CastleRights() != after.CastleRights() {
+		return 0
+	}
+	return halfMoves + 1
+}
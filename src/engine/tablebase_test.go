@@ -0,0 +1,183 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/tablebase"
+)
+
+// TestTablebaseConvertsWin plays a won rook ending out with the search on
+// both sides. Every win used to score the same WinVal, so the winning side had
+// no reason to make progress; with distance-aware tablebase scores the winner
+// must mate in exactly the tablebase's distance, and the loser must hold out
+// exactly that long.
+func TestTablebaseConvertsWin(t *testing.T) {
+	const ofen = "3k/4/4/KR2 w - - 0 1"
+	r, ok := tablebase.ProbeOFEN(ofen)
+	if !ok || r.WDL != tablebase.Win || r.DTM < 5 {
+		t.Fatalf("fixture broken: %s probes %+v (hit %v), want a win several moves deep", ofen, r, ok)
+	}
+
+	g := gameFromOFEN(t, ofen)
+	plies := 0
+	for g.Outcome() == octad.NoOutcome && plies <= r.DTM {
+		best := minimaxABRoot(g, 1, nil, nil)
+		if err := g.Move(&best.Move); err != nil {
+			t.Fatalf("illegal move %s: %v", best.Move.String(), err)
+		}
+		plies++
+	}
+	if g.Outcome() != octad.WhiteWon || plies != r.DTM {
+		t.Fatalf("game ended %s after %d plies, want white mating in %d", g.Outcome(), plies, r.DTM)
+	}
+}
+
+// TestTablebaseEvalDraw checks a known draw scores 0 though the heuristics
+// see a pawn up: the black king blockades the pawn and white can't shift it.
+func TestTablebaseEvalDraw(t *testing.T) {
+	g := gameFromOFEN(t, "4/1k2/1P2/1K2 w - - 0 1")
+	if Evaluate(g) <= 0 {
+		t.Fatalf("fixture broken: static eval %.1f does not favor white", Evaluate(g))
+	}
+	eval, ok, _ := tablebaseEval(g, 0)
+	if !ok || eval != 0 {
+		t.Fatalf("blockaded KPvK: eval %.1f (hit %v), want a 0 tablebase draw", eval, ok)
+	}
+}
+
+// TestTablebaseEvalHalfmoveClock checks a tablebase win the 25-move rule
+// would overtake is not scored as one: with the clock too far gone for the
+// mate to land, the probe misses and the search values the position on its
+// own, far below any tablebase win.
+func TestTablebaseEvalHalfmoveClock(t *testing.T) {
+	const fresh, late = "3k/4/4/KR2 w - - 0 1", "3k/4/4/KR2 w - - 46 40"
+	r, ok := tablebase.ProbeOFEN(fresh)
+	if !ok || r.WDL != tablebase.Win || r.DTM <= 50-46 {
+		t.Fatalf("fixture broken: %s probes %+v (hit %v), want a win more than 4 plies deep", fresh, r, ok)
+	}
+	if eval, ok, _ := tablebaseEval(gameFromOFEN(t, fresh), 0); !ok || eval != WinVal-tablebasePlyCost*float64(r.DTM) {
+		t.Fatalf("fresh clock: eval %.1f (hit %v), want the tablebase win", eval, ok)
+	}
+
+	g := gameFromOFEN(t, late)
+	if eval, ok, clocked := tablebaseEval(g, 46); ok || !clocked {
+		t.Errorf("halfmove clock 46: tablebase eval %.1f (hit %v, clocked %v), want a miss the clock decided", eval, ok, clocked)
+	}
+	if best := minimaxABRoot(g, 2, nil, nil); best.Eval >= WinVal/2 {
+		t.Errorf("halfmove clock 46: search scores %.1f, a tablebase win", best.Eval)
+	}
+}
+
+// TestTableKeepsClockOut searches a position at a fresh clock and then, on the
+// same table, at a late one. The Zobrist key leaves the clock out, so a value
+// the early search kept would carry over lines the 25-move rule now draws —
+// and a tablebase result the clock decided would carry over the same way. The
+// late search must score exactly what it scores on a table of its own.
+func TestTableKeepsClockOut(t *testing.T) {
+	const fresh, late = "2kr/4/4/RK2 w - - 0 1", "2kr/4/4/RK2 w - - 47 40"
+	const depth = 4
+
+	search := func(ofen string, tt *transTable) []MoveEval {
+		g := gameFromOFEN(t, ofen)
+		return evaluateRootMoves(g, orderMoves(g), depth, noStop, nil, nil, tt, 1)
+	}
+
+	alone := acquireTransTable()
+	want := search(late, alone)
+	releaseTransTable(alone)
+
+	shared := acquireTransTable()
+	defer releaseTransTable(shared)
+	search(fresh, shared)
+	got := search(late, shared)
+
+	for i := range want {
+		if got[i].Move != want[i].Move || math.Float64bits(got[i].Eval) != math.Float64bits(want[i].Eval) {
+			t.Errorf("%s after a fresh-clock search: %.1f, on its own table %.1f",
+				got[i].Move.String(), got[i].Eval, want[i].Eval)
+		}
+	}
+}
+
+// TestNextHalfMovesMatchesOctad plays random games and checks the clock the
+// search carries down the tree against the one octad keeps in the position,
+// move by move: captures, pawn moves and castling-rights changes included.
+func TestNextHalfMovesMatchesOctad(t *testing.T) {
+	rng := rand.New(rand.NewSource(25))
+	for game := 0; game < 50; game++ {
+		g, err := octad.NewGame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		clock := tablebase.HalfMoveClock(g.Position())
+		for ply := 0; ply < 120 && g.Outcome() == octad.NoOutcome; ply++ {
+			moves := g.ValidMoves()
+			m := moves[rng.Intn(len(moves))]
+			before := g.Position()
+			if err := g.Move(m); err != nil {
+				t.Fatalf("illegal move %s: %v", m.String(), err)
+			}
+			clock = nextHalfMoves(clock, before, g.Position(), m)
+			if want := tablebase.HalfMoveClock(g.Position()); clock != want {
+				t.Fatalf("game %d ply %d after %s: carried clock %d, octad's %d", game, ply, m.String(), clock, want)
+			}
+		}
+	}
+}
+
+// tablebaseBenchOFENs are endgames a search reaches near the end of a game,
+// at a spread of clocks: solved ones, and four-piece ones a capture or two
+// away from the tables, where every node of the search probes.
+var tablebaseBenchOFENs = []string{
+	"3k/4/4/KR2 w - - 0 1",
+	"3k/4/4/KR2 b - - 12 30",
+	"4/1k2/1P2/1K2 w - - 0 1",
+	"3k/4/1p2/KR2 w - - 0 1",
+	"k3/2p1/4/KQ2 w - - 6 35",
+	"3k/1P2/4/K1r1 b - - 4 30",
+}
+
+// BenchmarkTablebaseEval compares what a tablebase probe costs a search node:
+// "ofen" is Probe, reading the clock off the OFEN as every node once did, and
+// "carried" is tablebaseEval with the clock the search already holds.
+func BenchmarkTablebaseEval(b *testing.B) {
+	games := make([]*octad.Game, len(tablebaseBenchOFENs))
+	clocks := make([]int, len(tablebaseBenchOFENs))
+	for i, ofen := range tablebaseBenchOFENs {
+		games[i] = gameFromOFEN(b, ofen)
+		clocks[i] = tablebase.HalfMoveClock(games[i].Position())
+	}
+	// the built-in tables are solved on first use: not a probe's cost
+	tablebase.MaxPieces()
+	b.Run("ofen", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tablebase.Probe(games[i%len(games)].Position())
+		}
+	})
+	b.Run("carried", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tablebaseEval(games[i%len(games)], clocks[i%len(games)])
+		}
+	})
+}
+
+// BenchmarkSearchEndgame is BenchmarkSearchFixedDepth over the endgames
+// above, where the probe runs at every node: nodes/s is the number to compare.
+func BenchmarkSearchEndgame(b *testing.B) {
+	tablebase.MaxPieces()
+	start := searchedNodes.Load()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g := gameFromOFEN(b, tablebaseBenchOFENs[i%len(tablebaseBenchOFENs)])
+		tt := acquireTransTable()
+		evaluateRootMoves(g, orderMoves(g), benchDepth, noStop, nil, nil, tt, 0)
+		releaseTransTable(tt)
+	}
+	nodes := float64(searchedNodes.Load() - start)
+	b.ReportMetric(nodes/float64(b.N), "nodes/op")
+	b.ReportMetric(nodes/b.Elapsed().Seconds(), "nodes/s")
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dechristopher/octad/v2"
+)
+
+// The transposition table caches search results by Zobrist key (see
+// zobrist.go). A 4x4 board is dense with transpositions — the same position
+// turns up through different move orders all over the tree — and without the
+// table every one of them was re-searched and every leaf re-evaluated.
+//
+// One table serves a whole Search call: every iteration of deepeningRoot and
+// every parallel root goroutine of evaluateRootMoves probe and fill the same
+// slots, so a subtree one root move searched is free for its siblings, and an
+// iteration's best moves order the next. Tables are pooled and cleared between
+// searches rather than kept process-wide: the repetition scoring (repHist) is
+// per game, and a draw score learned against one game's history must not leak
+// into another's search.
+//
+// An entry cuts off a node searched to its own depth or shallower: a deeper
+// result is at least as good as the one the node would compute, and under
+// iterative deepening most hits are on entries a previous, shallower pass did
+// not write but a deeper sibling subtree did. Entries of any depth supply
+// their best move for ordering.
+//
+// That is a trade, and it costs two things an exact-depth match did not. A
+// deeper entry mixes leaf horizons of different parity into one tree, and the
+// mobility term is only consistent across leaves that share a side to move
+// (see staticEval), so a search no longer returns precisely the value of the
+// table-less search — only one at least as deep. And which entry a node finds
+// depends on what was written first: with the root split over goroutines
+// (evaluateRootMoves) or Lazy SMP helpers sharing the table (smp.go), that is
+// goroutine scheduling, and two runs of one search can differ in the last
+// digits of an eval or in the move they pick between near-equal ones. A search
+// on one thread (threads 1, no helpers) writes in a fixed order, and is
+// reproducible bit for bit (TestSingleThreadSearchReproducible).
+//
+// Repetition draws depend on the path to a node, not just the node, so a value
+// computed under one would carry a draw into every other line that reaches
+// the same position. Revisits of the real game's positions score the same on
+// every path of one search, and the table is per search, so those are safe to
+// keep; a line only declines to store a node whose subtree scored a
+// repetition within the line itself (see repTracker.pathDraws). The halfmove
+// clock is the same kind of exception, and is kept out the same way (see
+// zobrist.go).
+
+// ttSlots is the number of slots in a table (a power of two): 256Ki slots of
+// 24 bytes, ~6 MiB per concurrent search.
+const ttSlots = 1 << 18
+
+// ttBound says how an entry's eval relates to the node's true value.
+type ttBound uint8
+
+const (
+	// ttExact is the node's exact value.
+	ttExact ttBound = iota + 1
+	// ttLower means the true value is at least eval (a beta cutoff).
+	ttLower
+	// ttUpper means the true value is at most eval (nothing beat alpha).
+	ttUpper
+)
+
+// ttSlot is one table entry, written without locks: check holds the key XOR
+// both data words, so a probe racing a store (or two stores racing each
+// other) reads a torn slot as a plain miss instead of a corrupt hit. eval is
+// the raw float64 bits — absolute and white-positive, like the minimax search
+// — and meta packs depth, bound and best move (see packTTMeta).
+type ttSlot struct {
+	check atomic.Uint64
+	eval  atomic.Uint64
+	meta  atomic.Uint64
+}
+
+// ttEntry is a decoded slot.
+type ttEntry struct {
+	eval  float64
+	depth int
+	bound ttBound
+	// move is the node's best move in game.PackMove's layout (origin in bits
+	// 0-3, destination in 4-7, promotion in 8-10); 0 (a1a1) means none.
+	move uint16
+}
+
+// transTable is a fixed-size, concurrency-safe transposition table. A nil
+// *transTable disables caching: probes miss and stores are dropped.
+type transTable struct {
+	slots []ttSlot
+}
+
+// ttPool recycles tables across searches; allocating and zeroing a fresh
+// 6 MiB table for every bot move would dominate short searches.
+var ttPool = sync.Pool{
+	New: func() any { return &transTable{slots: make([]ttSlot, ttSlots)} },
+}
+
+// acquireTransTable returns an empty table for one search. Release it with
+// releaseTransTable once the search (including every root goroutine) is done.
+func acquireTransTable() *transTable {
+	return ttPool.Get().(*transTable)
+}
+
+// releaseTransTable clears tt and returns it to the pool.
+func releaseTransTable(tt *transTable) {
+	if tt == nil {
+		return
+	}
+	for i := range tt.slots {
+		tt.slots[i].check.Store(0)
+	}
+	ttPool.Put(tt)
+}
+
+// packTTMeta packs an entry's depth (bits 0-7), bound (8-9) and move (16-31).
+func packTTMeta(depth int, bound ttBound, move uint16) uint64 {
+	return uint64(uint8(depth)) | uint64(bound)<<8 | uint64(move)<<16
+}
+
+// probe returns the entry stored for key, if any.
+func (tt *transTable) probe(key uint64) (ttEntry, bool) {
+	if tt == nil {
+		return ttEntry{}, false
+	}
+	s := &tt.slots[key&(ttSlots-1)]
+	check, eval, meta := s.check.Load(), s.eval.Load(), s.meta.Load()
+	// a never-written slot reads as all zeroes, which must not pass for the
+	// (astronomically unlikely) key 0
+	if meta == 0 || check^eval^meta != key {
+		return ttEntry{}, false
+	}
+	return ttEntry{
+		eval:  math.Float64frombits(eval),
+		depth: int(uint8(meta)),
+		bound: ttBound(meta >> 8 & 0x3),
+		move:  uint16(meta >> 16),
+	}, true
+}
+
+// store records a search result for key, always replacing the slot's previous
+// occupant: the newest result comes from the current iteration and is the
+// most likely to be probed again.
+func (tt *transTable) store(key uint64, depth int, bound ttBound, eval float64, move uint16) {
+	if tt == nil {
+		return
+	}
+	s := &tt.slots[key&(ttSlots-1)]
+	e := math.Float64bits(eval)
+	m := packTTMeta(depth, bound, move)
+	s.eval.Store(e)
+	s.meta.Store(m)
+	s.check.Store(key ^ e ^ m)
+}
+
+// cutoff reports whether entry e settles a node searched to depth within the
+// fail-hard window (alpha, beta), and the value the node returns if so. An
+// entry searched deeper than depth settles it too, with its deeper value —
+// which is what makes a shared table's results depend on scheduling (see the
+// file comment).
+func (e ttEntry) cutoff(depth int, alpha, beta float64) (float64, bool) {
+	if e.depth < depth {
+		return 0, false
+	}
+	switch e.bound {
+	case ttExact:
+		return math.Min(math.Max(e.eval, alpha), beta), true
+	case ttLower:
+		if e.eval >= beta {
+			return beta, true
+		}
+	case ttUpper:
+		if e.eval <= alpha {
+			return alpha, true
+		}
+	}
+	return 0, false
+}
+
+// packTTMove encodes m in game.PackMove's layout, repeated here so the engine
+// keeps its dependencies to octad and the leaf utility packages.
+func packTTMove(m *octad.Move) uint16 {
+	return uint16(m.S1()&0xF) | uint16(m.S2()&0xF)<<4 | uint16(m.Promo()&0x7)<<8
+}
+
+// ttOrder moves the table's best move for the node, if it is among moves, to
+// the front so it is searched first: the move that was best (or refuted the
+// node) last time usually still is, and searching it first tightens the
+// window for everything after it. moves is reordered in place; octad's
+// ValidMoves hands out a fresh copy of its cache, so that's safe.
+func ttOrder(moves []*octad.Move, move uint16) {
+	if move == 0 {
+		return
+	}
+	for i, m := range moves {
+		if packTTMove(m) == move {
+			copy(moves[1:i+1], moves[:i])
+			moves[0] = m
+			return
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+)
+
+// TestZobristIncrementalMatchesFull plays long random games and checks, after
+// every move, that the key zobristUpdate carried forward equals a from-scratch
+// zobristHash. Random play from the standard start covers captures, castles,
+// en passant and promotions, the moves whose side effects land on squares
+// other than s1/s2.
+func TestZobristIncrementalMatchesFull(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	seen := map[string]bool{}
+	for game := 0; game < 200; game++ {
+		g := gameFromOFEN(t, "ppkn/4/4/NKPP w NCFncf - 0 1")
+		key := zobristHash(g.Position())
+		for ply := 0; ply < 60 && g.Outcome() == octad.NoOutcome; ply++ {
+			moves := g.ValidMoves()
+			m := moves[rng.Intn(len(moves))]
+			before := g.Position()
+			if err := g.Move(m); err != nil {
+				t.Fatal(err)
+			}
+			key = zobristUpdate(key, before, g.Position(), m)
+			if want := zobristHash(g.Position()); key != want {
+				t.Fatalf("after %s from %s: incremental key %x, full key %x",
+					m.String(), before.String(), key, want)
+			}
+			for _, tag := range []octad.MoveTag{octad.NearCastle, octad.CenterCastle,
+				octad.FarCastle, octad.EnPassant, octad.Capture} {
+				if m.HasTag(tag) {
+					seen[tagName(tag)] = true
+				}
+			}
+			if m.Promo() != octad.NoPieceType {
+				seen["promotion"] = true
+			}
+		}
+	}
+	for _, want := range []string{"castle", "en passant", "capture", "promotion"} {
+		if !seen[want] {
+			t.Errorf("random play never exercised a %s; widen the sample", want)
+		}
+	}
+}
+
+func tagName(tag octad.MoveTag) string {
+	switch tag {
+	case octad.EnPassant:
+		return "en passant"
+	case octad.Capture:
+		return "capture"
+	}
+	return "castle"
+}
+
+// TestZobristIdentity checks the key tracks exactly repetitionKey's notion of
+// identity: clocks are ignored, while the side to move and castling rights
+// are not.
+func TestZobristIdentity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		same bool
+	}{
+		{"3k/q3/4/2KN b - - 5 9", "3k/q3/4/2KN b - - 11 12", true},
+		{"3k/q3/4/2KN b - - 5 9", "3k/q3/4/2KN w - - 5 9", false},
+		{"ppkn/4/4/NKPP w NCFncf - 0 1", "ppkn/4/4/NKPP w NCF - 0 1", false},
+	}
+	for _, c := range cases {
+		a := zobristHash(gameFromOFEN(t, c.a).Position())
+		b := zobristHash(gameFromOFEN(t, c.b).Position())
+		if got := a == b; got != c.same {
+			t.Errorf("zobristHash(%q) == zobristHash(%q): got %t, want %t", c.a, c.b, got, c.same)
+		}
+	}
+}
+
+// TestTransTableProbe covers the slot round trip, the torn-write guard, and
+// the nil table.
+func TestTransTableProbe(t *testing.T) {
+	tt := acquireTransTable()
+	defer releaseTransTable(tt)
+
+	const key = 0xdeadbeefcafe
+	if _, ok := tt.probe(key); ok {
+		t.Fatal("empty table reported a hit")
+	}
+
+	tt.store(key, 5, ttLower, -37.5, 0x123)
+	e, ok := tt.probe(key)
+	if !ok || e.depth != 5 || e.bound != ttLower || e.eval != -37.5 || e.move != 0x123 {
+		t.Fatalf("probe = %+v (hit %t), want depth 5, lower bound, -37.5, move 0x123", e, ok)
+	}
+
+	// another key mapping to the same slot must miss, not alias
+	if _, ok := tt.probe(key + ttSlots); ok {
+		t.Fatal("colliding key read another position's entry")
+	}
+
+	// a half-written slot (eval from one store, check from another) misses
+	tt.slots[key&(ttSlots-1)].eval.Store(42)
+	if _, ok := tt.probe(key); ok {
+		t.Fatal("torn slot reported a hit")
+	}
+
+	var none *transTable
+	none.store(key, 1, ttExact, 1, 0)
+	if _, ok := none.probe(key); ok {
+		t.Fatal("nil table reported a hit")
+	}
+}
+
+// TestTransTableCutoff checks each bound only settles a node when it decides
+// the fail-hard window, and only from an entry searched at least as deep.
+func TestTransTableCutoff(t *testing.T) {
+	cases := []struct {
+		name        string
+		e           ttEntry
+		depth       int
+		alpha, beta float64
+		want        float64
+		ok          bool
+	}{
+		{"exact inside", ttEntry{eval: 5, depth: 3, bound: ttExact}, 3, -10, 10, 5, true},
+		{"exact clamps high", ttEntry{eval: 50, depth: 3, bound: ttExact}, 3, -10, 10, 10, true},
+		{"exact clamps low", ttEntry{eval: -50, depth: 3, bound: ttExact}, 3, -10, 10, -10, true},
+		{"exact deeper", ttEntry{eval: 5, depth: 4, bound: ttExact}, 3, -10, 10, 5, true},
+		{"exact shallower", ttEntry{eval: 5, depth: 2, bound: ttExact}, 3, -10, 10, 0, false},
+		{"lower deeper fails high", ttEntry{eval: 12, depth: 6, bound: ttLower}, 3, -10, 10, 10, true},
+		{"upper shallower fails low", ttEntry{eval: -12, depth: 1, bound: ttUpper}, 3, -10, 10, 0, false},
+		{"lower fails high", ttEntry{eval: 12, depth: 3, bound: ttLower}, 3, -10, 10, 10, true},
+		{"lower inside window", ttEntry{eval: 5, depth: 3, bound: ttLower}, 3, -10, 10, 0, false},
+		{"upper fails low", ttEntry{eval: -12, depth: 3, bound: ttUpper}, 3, -10, 10, -10, true},
+		{"upper inside window", ttEntry{eval: 5, depth: 3, bound: ttUpper}, 3, -10, 10, 0, false},
+	}
+	for _, c := range cases {
+		got, ok := c.e.cutoff(c.depth, c.alpha, c.beta)
+		if ok != c.ok || (ok && got != c.want) {
+			t.Errorf("%s: cutoff = (%v, %t), want (%v, %t)", c.name, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+// TestTransTableDeeperEntry checks the search takes a root move's value from
+// an entry searched deeper than it would search the move, and searches the
+// move itself past a shallower one.
+func TestTransTableDeeperEntry(t *testing.T) {
+	const depth = 3
+	const ofen = "ppkn/4/4/NKPP w NCFncf - 0 1"
+	g := gameFromOFEN(t, ofen)
+	moves := orderMoves(g)[:1]
+	want := rootEvals(evaluateRootMoves(g, moves, depth, noStop, nil, nil, nil, 1))
+
+	child := gameFromOFEN(t, ofen)
+	if err := child.Move(&moves[0]); err != nil {
+		t.Fatal(err)
+	}
+	key := zobristHash(child.Position())
+	name := moves[0].String()
+
+	tt := acquireTransTable()
+	defer releaseTransTable(tt)
+
+	tt.store(key, depth-1, ttExact, 123.25, 0)
+	if got := rootEvals(evaluateRootMoves(g, moves, depth, noStop, nil, nil, tt, 1)); got[name] != want[name] {
+		t.Errorf("shallower entry: eval %.2f, want the searched %.2f", got[name], want[name])
+	}
+
+	tt.store(key, depth+2, ttExact, 123.25, 0)
+	if got := rootEvals(evaluateRootMoves(g, moves, depth, noStop, nil, nil, tt, 1)); got[name] != 123.25 {
+		t.Errorf("deeper entry: eval %.2f, want the stored 123.25", got[name])
+	}
+}
+
+// TestTransTablePathDraws checks a node whose subtree scored a threefold
+// within the search line is not stored, while a revisit of the real game's
+// positions, which scores the same on every line, does not hold stores back.
+func TestTransTablePathDraws(t *testing.T) {
+	tt := acquireTransTable()
+	defer releaseTransTable(tt)
+
+	const game, path = "game position", "line position"
+	line := &searchLine{stop: noStop, rep: newRepTracker(map[string]int{game: 1}), tt: tt}
+
+	mark := line.mark()
+	if !line.rep.isDrawnRepetition(game) {
+		t.Fatal("a real-game position did not score as a draw")
+	}
+	line.store(1, 2, ttExact, 0, 0, mark)
+	if _, ok := tt.probe(1); !ok {
+		t.Error("a real-game repetition kept the node out of the table")
+	}
+
+	mark = line.mark()
+	line.rep.enter(path)
+	line.rep.enter(path)
+	if !line.rep.isDrawnRepetition(path) {
+		t.Fatal("a threefold within the line did not score as a draw")
+	}
+	line.store(2, 2, ttExact, 0, 0, mark)
+	if _, ok := tt.probe(2); ok {
+		t.Error("a node valued by a threefold within the line was stored")
+	}
+}
+
+// TestSingleThreadSearchReproducible runs the same iterative deepening twice
+// on one thread, each on a fresh table, and requires the same evals bit for
+// bit. Deeper entries cut off (see transtable.go), so a shared table's results
+// depend on the order it was written in; on one thread that order is fixed.
+func TestSingleThreadSearchReproducible(t *testing.T) {
+	const depth = 4
+	deepen := func(ofen string) []MoveEval {
+		g := gameFromOFEN(t, ofen)
+		moves := orderMoves(g)
+		tt := acquireTransTable()
+		defer releaseTransTable(tt)
+		var results []MoveEval
+		for d := 1; d <= depth; d++ {
+			results = evaluateRootMoves(g, moves, d, noStop, nil, nil, tt, 1)
+		}
+		return results
+	}
+
+	for _, ofen := range randomPositions(t, 6, 8) {
+		first, second := deepen(ofen), deepen(ofen)
+		for i := range first {
+			if first[i].Move != second[i].Move || math.Float64bits(first[i].Eval) != math.Float64bits(second[i].Eval) {
+				t.Errorf("%s: %s scored %v, then %s %v", ofen,
+					first[i].Move.String(), first[i].Eval, second[i].Move.String(), second[i].Eval)
+			}
+		}
+	}
+}
+
+func rootEvals(results []MoveEval) map[string]float64 {
+	out := make(map[string]float64, len(results))
+	for _, r := range results {
+		out[r.Move.String()] = r.Eval
+	}
+	return out
+}
+
+// benchDepth is deep enough that the table-less search takes a noticeable
+// fraction of a second per position, which is where the table earns its keep.
+const benchDepth = 5
+
+// benchBudget is a typical mid-game bot think time (see calcSearchLocked).
+const benchBudget = 300 * time.Millisecond
+
+// benchTables runs fn once with a pooled table and once without, as the
+// "table" and "none" sub-benchmarks.
+func benchTables(b *testing.B, fn func(b *testing.B, tt func() *transTable)) {
+	b.Run("table", func(b *testing.B) { fn(b, acquireTransTable) })
+	b.Run("none", func(b *testing.B) { fn(b, func() *transTable { return nil }) })
+}
+
+// BenchmarkSearchFixedDepth measures a full fixed-depth root search over a
+// spread of positions, reporting nodes visited per search and nodes/sec. The
+// table cuts the node count (transpositions are searched once); nodes/sec
+// shows what the probes and incremental hashing cost per node.
+func BenchmarkSearchFixedDepth(b *testing.B) {
+	ofens := randomPositions(b, 6, 8)
+	benchTables(b, func(b *testing.B, table func() *transTable) {
+		start := searchedNodes.Load()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			g := gameFromOFEN(b, ofens[i%len(ofens)])
+			tt := table()
+			evaluateRootMoves(g, orderMoves(g), benchDepth, noStop, nil, nil, tt, 0)
+			releaseTransTable(tt)
+		}
+		nodes := float64(searchedNodes.Load() - start)
+		b.ReportMetric(nodes/float64(b.N), "nodes/op")
+		b.ReportMetric(nodes/b.Elapsed().Seconds(), "nodes/s")
+	})
+}
+
+// BenchmarkSearchDepthReached runs the budgeted iterative-deepening search the
+// bot plays with and reports the average depth it completes within
+// benchBudget: the headline number for how much stronger the same clock makes
+// the Queen persona.
+func BenchmarkSearchDepthReached(b *testing.B) {
+	ofens := randomPositions(b, 6, 8)
+	benchTables(b, func(b *testing.B, table func() *transTable) {
+		total := 0
+		for i := 0; i < b.N; i++ {
+			g := gameFromOFEN(b, ofens[i%len(ofens)])
+			tt := table()
+			_, _, reached := deepeningRoot(g, 32, time.Now().Add(benchBudget), nil, nil, tt, 0)
+			releaseTransTable(tt)
+			total += reached
+		}
+		b.ReportMetric(float64(total)/float64(b.N), "depth/op")
+	})
+}
@@ -0,0 +1,140 @@
+package engine
+
+import "github.com/dechristopher/octad/v2"
+
+// Term is one positional term of the static evaluation: everything staticEval
+// adds beyond material and the piece-square tables. Each term is a count (of
+// legal moves, doubled pawns, safe king squares, ...) relative to the side to
+// move, scored as the count times the term's weight. Keeping the evaluation
+// linear in its weights is what lets lio --tune fit them (see package tune).
+type Term int
+
+// The positional terms, in Terms order. The hand-tuned weight of each is the
+// like-named constant in evaluate.go.
+const (
+	TermMobility Term = iota
+	TermPromotion
+	TermCastling
+	TermDoubledPawns
+	TermIsolatedPawns
+	TermPassedPawns
+	TermConnectivity
+	TermKingSafety
+	TermMopUpCenter
+	TermMopUpProximity
+	NumTerms
+)
+
+// termNames are the terms' Go identifiers without the Term prefix, as String
+// and the generated weights file (tune.Write) spell them.
+var termNames = [NumTerms]string{
+	TermMobility:       "Mobility",
+	TermPromotion:      "Promotion",
+	TermCastling:       "Castling",
+	TermDoubledPawns:   "DoubledPawns",
+	TermIsolatedPawns:  "IsolatedPawns",
+	TermPassedPawns:    "PassedPawns",
+	TermConnectivity:   "Connectivity",
+	TermKingSafety:     "KingSafety",
+	TermMopUpCenter:    "MopUpCenter",
+	TermMopUpProximity: "MopUpProximity",
+}
+
+func (t Term) String() string {
+	if t < 0 || t >= NumTerms {
+		return "Term(?)"
+	}
+	return termNames[t]
+}
+
+// Terms holds a value for every positional term: a position's counts
+// (PositionTerms) or the weights they are scored with (Weights.Terms).
+type Terms [NumTerms]float64
+
+// Weights is every parameter of the static evaluation. A search scores its
+// leaves with one Weights throughout (see Persona.Weights); the in-check
+// penalty and the terminal scores are not part of it, since no fit could move
+// them (the tuner only sees quiet positions).
+type Weights struct {
+	// Material is each piece type's value. The pawn's 10 is the unit every
+	// other weight is measured in, so the tuner holds it fixed.
+	Material map[octad.PieceType]float64
+	// Tables are the piece-square tables by color and piece type. A table both
+	// colors share (the bishop's, rook's and queen's) is one map under both.
+	Tables map[octad.Color]PieceTypeTable
+	// Terms are the positional term weights.
+	Terms Terms
+}
+
+// HandTuned is the evaluation as written by hand: PieceVals, the tables in
+// engine_tables.go and the weight constants in evaluate.go.
+var HandTuned = Weights{
+	Material: PieceVals,
+	Tables:   PieceSquareTables,
+	Terms: Terms{
+		TermMobility:       MobilityWeight,
+		TermPromotion:      PromoWeight,
+		TermCastling:       CastleWeight,
+		TermDoubledPawns:   DoubledPawnPenalty,
+		TermIsolatedPawns:  IsolatedPawnPenalty,
+		TermPassedPawns:    PassedPawnBonus,
+		TermConnectivity:   ConnectivityWeight,
+		TermKingSafety:     KingSafetyWeight,
+		TermMopUpCenter:    MopUpCenterWeight,
+		TermMopUpProximity: MopUpProximityWeight,
+	},
+}
+
+// DefaultWeights is the evaluation every search uses unless its persona
+// carries its own. It is HandTuned until a weights file generated by
+// lio --tune is compiled into the package: that file's init replaces it.
+var DefaultWeights = &HandTuned
+
+// PositionTerms returns the positional term counts of a position that has not
+// ended, relative to the side to move: the values staticEval scores with
+// Weights.Terms. It is exported for the tuner, which fits the weights to them.
+func PositionTerms(situation *octad.Game) Terms {
+	color := situation.Position().Turn()
+	board := squaresOf(situation.Position().Board())
+	return positionTerms(situation, &board, color)
+}
+
+// positionTerms is PositionTerms on the caller's copy of the board.
+func positionTerms(situation *octad.Game, board *squares, color octad.Color) Terms {
+	terms := boardTerms(board, color)
+
+	// the position is non-terminal here, so the side to move has moves
+	moves := situation.ValidMoves()
+
+	// mobility: reward the side to move for having more legal options. Only
+	// the mover's move list is cheaply available, but at a fixed search depth
+	// every non-terminal leaf shares the same side to move, so this stays a
+	// consistent signal across the search tree.
+	terms[TermMobility] = float64(len(moves))
+
+	// promotion threat: reward having a pawn that can legally promote right
+	// now. Dedupe by origin square, so a pawn with several promotion choices
+	// (queen, rook, ...) is only counted once.
+	var promoters squareSet
+	for _, m := range moves {
+		if m.Promo() != octad.NoPieceType {
+			promoters.add(m.S1())
+		}
+	}
+	terms[TermPromotion] = float64(promoters.len())
+
+	// castling rights: reward retaining the flexibility to castle, relative
+	// to the opponent
+	terms[TermCastling] = float64(castleRightsCount(situation, color) - castleRightsCount(situation, color.Other()))
+
+	return terms
+}
+
+// score is the terms scored with weights w.
+func (t Terms) score(w Terms) float64 {
+	eval := 0.0
+	for i, v := range t {
+		eval += w[i] * v
+	}
+	return eval
+}
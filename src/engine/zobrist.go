@@ -0,0 +1,153 @@
+package engine
+
+import "github.com/dechristopher/octad/v2"
+
+// Zobrist hashing for the transposition table. A position's key is the XOR of
+// one random 64-bit value per (piece, square) pair on the board, plus values
+// for the side to move, the castling rights, and the en passant square. The
+// XOR structure is what makes the key incremental: a move only touches a few
+// squares, so the child's key is the parent's with those squares' terms (and
+// the turn/castling/en passant terms) swapped out, rather than a walk of the
+// whole board — or the string formatting and md5 of octad's Position.Hash().
+//
+// Like repetitionKey, the key ignores the halfmove clock and fullmove number.
+// The fullmove number never changes a search; the halfmove clock can, through
+// the 25-move rule and the tablebase results it cuts short, so the search
+// keeps values it decided out of the table rather than key on it (clockFree,
+// and searchLine.mark for tablebase results).
+
+// zobristPieceKeys is indexed by octad.Piece (1-12; NoPiece stays zero so an
+// empty square contributes nothing) and then by square.
+var zobristPieceKeys [13][boardDim * boardDim]uint64
+
+// zobristCastleKeys is indexed by the 6-bit castling-rights mask (see
+// castleMask).
+var zobristCastleKeys [1 << 6]uint64
+
+// zobristEnPassantKeys is indexed by the en passant target square.
+var zobristEnPassantKeys [boardDim * boardDim]uint64
+
+// zobristBlackKey is folded in when black is to move.
+var zobristBlackKey uint64
+
+func init() {
+	// a fixed seed keeps keys stable across runs, so a search is reproducible
+	// and benchmarks compare like with like
+	seed := uint64(0x6f63746164) // "octad"
+	for p := range zobristPieceKeys {
+		if octad.Piece(p) == octad.NoPiece {
+			continue
+		}
+		for sq := range zobristPieceKeys[p] {
+			zobristPieceKeys[p][sq] = splitMix64(&seed)
+		}
+	}
+	for i := range zobristCastleKeys {
+		zobristCastleKeys[i] = splitMix64(&seed)
+	}
+	for i := range zobristEnPassantKeys {
+		zobristEnPassantKeys[i] = splitMix64(&seed)
+	}
+	zobristBlackKey = splitMix64(&seed)
+}
+
+// splitMix64 advances state and returns the next value of the SplitMix64
+// sequence: a tiny, well-mixed generator that is all key generation needs.
+func splitMix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// castleMask packs octad's castling rights (a string like "NCFncf") into six
+// bits, one per color/side.
+func castleMask(cr octad.CastleRights) uint {
+	var mask uint
+	for _, c := range string(cr) {
+		switch c {
+		case 'N':
+			mask |= 1 << 0
+		case 'C':
+			mask |= 1 << 1
+		case 'F':
+			mask |= 1 << 2
+		case 'n':
+			mask |= 1 << 3
+		case 'c':
+			mask |= 1 << 4
+		case 'f':
+			mask |= 1 << 5
+		}
+	}
+	return mask
+}
+
+// pieceKey is the Zobrist term for piece p standing on sq (zero when empty).
+func pieceKey(p octad.Piece, sq octad.Square) uint64 {
+	return zobristPieceKeys[p][sq]
+}
+
+// enPassantKey is the Zobrist term for an en passant target (zero for none).
+func enPassantKey(sq octad.Square) uint64 {
+	if sq == octad.NoSquare {
+		return 0
+	}
+	return zobristEnPassantKeys[sq]
+}
+
+// zobristHash computes pos's key from scratch. The search does this once at
+// the root and derives every other key with zobristUpdate.
+func zobristHash(pos *octad.Position) uint64 {
+	board := pos.Board()
+	var h uint64
+	for sq := octad.Square(0); sq < boardDim*boardDim; sq++ {
+		h ^= pieceKey(board.Piece(sq), sq)
+	}
+	if pos.Turn() == octad.Black {
+		h ^= zobristBlackKey
+	}
+	h ^= zobristCastleKeys[castleMask(pos.CastleRights())]
+	h ^= enPassantKey(pos.EnPassantSquare())
+	return h
+}
+
+// zobristUpdate returns the key of after, the position reached by playing m
+// in before, given before's key h. Only the squares m can have changed are
+// re-read: its origin and destination, the pawn an en passant capture removes
+// (one rank either side of the destination), and — for octad's castles, where
+// the king and partner can land on squares other than s1/s2 — the whole home
+// rank. Re-reading a square that didn't change XORs the same term in and out,
+// so over-covering is harmless and keeps this independent of octad's board
+// internals.
+func zobristUpdate(h uint64, before, after *octad.Position, m *octad.Move) uint64 {
+	bb, ab := before.Board(), after.Board()
+	swap := func(sq octad.Square) {
+		h ^= pieceKey(bb.Piece(sq), sq) ^ pieceKey(ab.Piece(sq), sq)
+	}
+
+	if m.HasTag(octad.NearCastle) || m.HasTag(octad.CenterCastle) || m.HasTag(octad.FarCastle) {
+		rank := m.S1() / boardDim * boardDim
+		for sq := rank; sq < rank+boardDim; sq++ {
+			swap(sq)
+		}
+	} else {
+		swap(m.S1())
+		swap(m.S2())
+		if m.HasTag(octad.EnPassant) {
+			if sq := m.S2() + boardDim; sq < boardDim*boardDim {
+				swap(sq)
+			}
+			if sq := m.S2() - boardDim; sq >= 0 {
+				swap(sq)
+			}
+		}
+	}
+
+	h ^= zobristBlackKey
+	h ^= zobristCastleKeys[castleMask(before.CastleRights())] ^
+		zobristCastleKeys[castleMask(after.CastleRights())]
+	h ^= enPassantKey(before.EnPassantSquare()) ^ enPassantKey(after.EnPassantSquare())
+	return h
+}
@@ -0,0 +1,226 @@
+package fairplay
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dechristopher/lio/db"
+)
+
+// Fair play. Every rated game between two accounts is reviewed (package
+// analysis), and each side of it is measured against that review: how many of
+// its moves were the engine's own choice, how many cost next to nothing, what
+// it gave away on average, and how evenly it spent its clock. An account's
+// recent samples roll up into a suspicion score, 0-100, and accounts scoring
+// high enough are listed on the moderation page with the evidence.
+//
+// That is all it does. Nothing in this package — or anywhere the score is read
+// — files a report, restricts an account or touches a rating: engine-like play
+// is also what a strong player's good day looks like, so the score only decides
+// what a moderator looks at first. This file is the arithmetic; observe.go
+// gathers the inputs and stores the results.
+
+const (
+	// bookPlies are left out at the start of every game: the opening is
+	// memorised as often as it is found, so matching the engine there proves
+	// nothing.
+	bookPlies = 6
+
+	// decidedCp is the eval (from the mover's side) past which a position is
+	// decided either way: with a piece up or down, many moves are "best", and
+	// matching the engine there is no signal.
+	decidedCp = 600
+
+	// topLoss is the most a move can give away and still count as a top
+	// choice: the review's threshold for "best".
+	topLoss = 10
+
+	// lossCap bounds the loss charged for one move, as the review's does.
+	lossCap = 1000
+
+	// minTimed is how many timed moves a side needs before its think times
+	// are described: a spread of three numbers says nothing.
+	minTimed = 5
+)
+
+const (
+	// Window is how many of an account's most recent samples its score is
+	// rolled from: enough to smooth one lucky game, few enough that an account
+	// that starts cheating shows up within a couple of sessions.
+	Window = 20
+
+	// minGameMoves is how many judged moves a game needs to count toward the
+	// score; a game decided in the opening has too few to judge anything by.
+	minGameMoves = 6
+
+	// confidentGames is how many counted games the score needs to be taken at
+	// face value; below it the score is scaled down in proportion.
+	confidentGames = 10
+
+	// FlagScore and FlagGames are the moderation page's bar: a score at least
+	// FlagScore over at least FlagGames counted games. FlagMargin is how far
+	// past the score it was dismissed at an account must climb to come back.
+	FlagScore  = 70
+	FlagGames  = 5
+	FlagMargin = 10
+)
+
+// Ply is one move of a reviewed game as the sampler sees it.
+type Ply struct {
+	// Matched reports the move was the engine's best in the position it was
+	// played in; Forced that it was the only legal move.
+	Matched bool
+	Forced  bool
+	// ThinkMs is the think time spent on the move, nil when not recorded.
+	ThinkMs *int32
+}
+
+// Measure takes one side's sample from a reviewed game. evals are the review's
+// white-positive centipawns of the position after each ply, so evals[i-1] is
+// the position ply i was played in (ply 0's is not needed: it is in the book).
+// whiteFirst says who made the first move, white which side is measured.
+func Measure(evals []int16, plies []Ply, whiteFirst, white bool) db.FairPlaySample {
+	var s db.FairPlaySample
+	var loss float64
+	var thinks []float64
+	for i, p := range plies {
+		// ply i is white's when white moved first and i is even
+		if (whiteFirst == (i%2 == 0)) != white {
+			continue
+		}
+		if p.ThinkMs != nil && i >= bookPlies {
+			thinks = append(thinks, float64(*p.ThinkMs))
+		}
+		if i < bookPlies || p.Forced || i >= len(evals) {
+			continue
+		}
+		before, after := float64(evals[i-1]), float64(evals[i])
+		if !white {
+			before, after = -before, -after
+		}
+		if math.Abs(before) >= decidedCp {
+			continue
+		}
+
+		l := math.Max(0, clampLoss(before)-clampLoss(after))
+		s.Moves++
+		loss += l
+		if p.Matched {
+			s.EngineMatches++
+		}
+		if l <= topLoss {
+			s.TopMatches++
+		}
+	}
+	if s.Moves > 0 {
+		s.ACPL = int(math.Round(loss / float64(s.Moves)))
+	}
+	if len(thinks) >= minTimed {
+		mean, sd := meanStdDev(thinks)
+		m := int32(math.Round(mean))
+		s.ThinkMeanMs = &m
+		if mean > 0 {
+			cv := float32(sd / mean)
+			s.ThinkCV = &cv
+		}
+	}
+	return s
+}
+
+// Roll builds an account's score from its recent samples (any order). Each
+// signal is scaled onto 0-1 between the level a strong human commonly reaches
+// and the level engine play sits at, and weighted:
+//
+//	match rate   35  the engine's own move, of the judged moves
+//	top rate     20  moves costing next to nothing
+//	ACPL         15  average centipawns given away
+//	think times  15  how evenly the clock was spent (a bot relaying moves
+//	                 spends it like a metronome)
+//	consistency  15  how little the match rate moves from game to game, and
+//	                 only as far as the match rate is itself high: a steady
+//	                 mediocre player is not suspicious
+//
+// The total is scaled down while there are fewer than confidentGames games
+// behind it. The levels are starting points for a 4x4 board; the moderation
+// page shows every input, so a moderator is never asked to trust the number.
+func Roll(samples []db.FairPlaySample) db.FairPlayScore {
+	var out db.FairPlayScore
+	var moves, matches, tops, loss float64
+	var rates []float64
+	var cvs []float64
+	for _, s := range samples {
+		if s.Moves < minGameMoves {
+			continue
+		}
+		out.Games++
+		moves += float64(s.Moves)
+		matches += float64(s.EngineMatches)
+		tops += float64(s.TopMatches)
+		loss += float64(s.ACPL * s.Moves)
+		rates = append(rates, float64(s.EngineMatches)/float64(s.Moves))
+		if s.ThinkCV != nil {
+			cvs = append(cvs, float64(*s.ThinkCV))
+		}
+	}
+	if out.Games == 0 {
+		return out
+	}
+
+	match, top, acpl := matches/moves, tops/moves, loss/moves
+	out.MatchRate, out.TopRate, out.ACPL = float32(match), float32(top), float32(acpl)
+
+	matchPart := ramp(match, 0.55, 0.85)
+	score := 35*matchPart + 20*ramp(top, 0.75, 0.95) + 15*ramp(-acpl, -40, -8)
+	if len(cvs) >= FlagGames {
+		cv := median(cvs)
+		out.ThinkCV = f32(cv)
+		score += 15 * ramp(-cv, -0.8, -0.3)
+	}
+	if len(rates) >= 3 {
+		_, spread := meanStdDev(rates)
+		out.MatchSpread = f32(spread)
+		score += 15 * ramp(-spread, -0.2, -0.05) * matchPart
+	}
+
+	score *= math.Min(1, float64(out.Games)/confidentGames)
+	out.Score = float32(math.Round(10*score) / 10)
+	return out
+}
+
+// ramp maps x onto 0-1, linearly from lo to hi and flat beyond them.
+func ramp(x, lo, hi float64) float64 {
+	return math.Max(0, math.Min(1, (x-lo)/(hi-lo)))
+}
+
+// clampLoss bounds an eval to ±lossCap for loss accounting.
+func clampLoss(cp float64) float64 {
+	return math.Max(-lossCap, math.Min(lossCap, cp))
+}
+
+// meanStdDev returns the mean and population standard deviation of xs.
+func meanStdDev(xs []float64) (mean, sd float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for _, x := range xs {
+		sd += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(sd / float64(len(xs)))
+}
+
+// median returns the median of xs, which it sorts.
+func median(xs []float64) float64 {
+	sort.Float64s(xs)
+	n := len(xs)
+	if n%2 == 1 {
+		return xs[n/2]
+	}
+	return (xs[n/2-1] + xs[n/2]) / 2
+}
+
+// f32 rounds x to three places for storage.
+func f32(x float64) *float32 {
+	v := float32(math.Round(1000*x) / 1000)
+	return &v
+}
@@ -0,0 +1,109 @@
+package fairplay
+
+import (
+	"testing"
+
+	"github.com/dechristopher/lio/db"
+)
+
+// relayGame builds n plies, every one matching the engine and timed at ms, over a
+// level position (every eval 0): the shape of a perfect relay.
+func relayGame(n int, ms int32) ([]int16, []Ply) {
+	evals := make([]int16, n)
+	plies := make([]Ply, n)
+	for i := range plies {
+		t := ms
+		plies[i] = Ply{Matched: true, ThinkMs: &t}
+	}
+	return evals, plies
+}
+
+// TestMeasureSkipsBookForcedAndDecided: only the measured side's moves count,
+// and of those only the ones past the book, with a choice, in a live position.
+func TestMeasureSkipsBookForcedAndDecided(t *testing.T) {
+	evals, plies := relayGame(20, 1000)
+	plies[8].Forced = true // white's fifth move
+	// white wins a piece with its sixth move, and black's reply leaves it
+	// there: white's seventh move and black's sixth and seventh are decided
+	evals[10], evals[11], evals[12] = 900, 900, 900
+	// white's eighth move misses the engine's choice and gives away 120, which
+	// stands to the end of the game
+	plies[14].Matched = false
+	for i := 14; i < len(evals); i++ {
+		evals[i] = -120
+	}
+
+	s := Measure(evals, plies, true, true)
+	// white has plies 0,2,...,18: three in the book, one forced, one decided
+	if s.Moves != 5 {
+		t.Fatalf("moves = %d, want 5", s.Moves)
+	}
+	if s.EngineMatches != 4 || s.TopMatches != 4 {
+		t.Errorf("matches = %d/%d, want 4/4", s.EngineMatches, s.TopMatches)
+	}
+	if s.ACPL != 24 {
+		t.Errorf("acpl = %d, want 24", s.ACPL)
+	}
+	if s.ThinkMeanMs == nil || *s.ThinkMeanMs != 1000 || s.ThinkCV == nil || *s.ThinkCV != 0 {
+		t.Errorf("think = %v / %v, want 1000ms with no spread", s.ThinkMeanMs, s.ThinkCV)
+	}
+
+	// black's side: two decided moves out, and nothing given away
+	b := Measure(evals, plies, true, false)
+	if b.Moves != 5 || b.ACPL != 0 {
+		t.Errorf("black = %+v, want 5 moves at no loss", b)
+	}
+}
+
+// TestMeasureUntimed: a game archived without think times describes none.
+func TestMeasureUntimed(t *testing.T) {
+	evals, plies := relayGame(20, 0)
+	for i := range plies {
+		plies[i].ThinkMs = nil
+	}
+	if s := Measure(evals, plies, true, true); s.ThinkMeanMs != nil || s.ThinkCV != nil {
+		t.Errorf("think = %v / %v, want none", s.ThinkMeanMs, s.ThinkCV)
+	}
+}
+
+// sample is a game with matches of moves engine moves, top moves and loss
+// alike, and the given think-time variation.
+func sample(matches, moves int, cv float32) db.FairPlaySample {
+	return db.FairPlaySample{Moves: moves, EngineMatches: matches, TopMatches: matches,
+		ACPL: 5 * (moves - matches), ThinkCV: &cv}
+}
+
+// TestRollSeparatesRelayFromHuman: a run of engine-perfect games with
+// metronome timing scores far above a strong but human run, and both are
+// scaled down while there are few games behind them.
+func TestRollSeparatesRelayFromHuman(t *testing.T) {
+	var relay, human []db.FairPlaySample
+	for i := 0; i < confidentGames; i++ {
+		relay = append(relay, sample(10, 10, 0.1))
+		human = append(human, sample(5+i%3, 10, 0.9))
+	}
+	r, h := Roll(relay), Roll(human)
+	if r.Score < FlagScore {
+		t.Errorf("relay score = %.1f, want at least %d", r.Score, FlagScore)
+	}
+	if h.Score >= 30 {
+		t.Errorf("human score = %.1f, want well under the bar", h.Score)
+	}
+	if r.MatchRate != 1 || r.Games != confidentGames || r.ThinkCV == nil || r.MatchSpread == nil {
+		t.Errorf("relay figures = %+v", r)
+	}
+
+	few := Roll(relay[:confidentGames/2])
+	if few.Score >= r.Score {
+		t.Errorf("half the games = %.1f, want below the full %.1f", few.Score, r.Score)
+	}
+}
+
+// TestRollIgnoresShortGames: a game decided in the opening has nothing to
+// judge and does not count.
+func TestRollIgnoresShortGames(t *testing.T) {
+	s := Roll([]db.FairPlaySample{sample(2, 2, 0.1), sample(0, 0, 0.1)})
+	if s.Games != 0 || s.Score != 0 {
+		t.Errorf("short games = %+v, want nothing counted", s)
+	}
+}
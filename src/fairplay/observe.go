@@ -0,0 +1,131 @@
+package fairplay
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Sampling rides on the post-game review: when the lio_pg_fairplay secret/env
+// is "1", every rated game between two accounts is queued for review as it is
+// archived, and the review worker hands each finished review here. Off by
+// default, like automatic review, because it is an engine search per position
+// of every rated game.
+
+// enabled reports fair play is on in this process.
+var enabled atomic.Bool
+
+// Up turns fair play on when it is enabled and Postgres is configured. Call
+// before analysis.Up, whose worker feeds it.
+func Up() {
+	if db.Pool == nil || config.ReadSecretFallback("lio_pg_fairplay") != "1" {
+		return
+	}
+	enabled.Store(true)
+	db.EnableFairPlay()
+	util.Debug(str.CFair, "fair-play sampling online")
+}
+
+// Enabled reports whether reviews are being sampled.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Observe samples both sides of a freshly reviewed game and re-rolls both
+// accounts' scores. evals are the review's, one per ply. A game that is not
+// rated between two accounts is ignored. Errors are logged, never returned:
+// a sample that could not be taken costs the score one game, not the review.
+func Observe(gameRef int32, evals []int16) {
+	if !Enabled() {
+		return
+	}
+	if err := observe(gameRef, evals); err != nil {
+		util.Error(str.CFair, "fair-play sample failed game=%d error=%s", gameRef, err.Error())
+	}
+}
+
+// observe takes and stores the samples of one reviewed game.
+func observe(gameRef int32, evals []int16) error {
+	g, err := db.GetFairPlayGame(gameRef)
+	if err != nil || !g.Sampled {
+		return err
+	}
+	rows, err := db.FairPlayPlies(gameRef)
+	if err != nil {
+		return err
+	}
+	plies, whiteFirst, err := replay(g.StartingOFEN, rows)
+	if err != nil {
+		return err
+	}
+
+	for _, side := range []struct {
+		userID int64
+		white  bool
+	}{{g.WhiteUserID, true}, {g.BlackUserID, false}} {
+		s := Measure(evals, plies, whiteFirst, side.white)
+		s.GameRef = gameRef
+		if err := db.SaveFairPlaySample(side.userID, s); err != nil {
+			return err
+		}
+		if err := reroll(side.userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reroll recomputes an account's score from its recent samples.
+func reroll(userID int64) error {
+	samples, err := db.FairPlaySamples(userID, Window)
+	if err != nil {
+		return err
+	}
+	return db.SaveFairPlayScore(userID, Roll(samples))
+}
+
+// replay walks a game from its starting position, marking each move that was
+// the only legal one and each that matched the engine's best move in the
+// position it was played in — which is the best move cached for the position
+// the previous ply reached (the start position's is never needed: it is in the
+// book). It also reports who moved first.
+func replay(startOFEN string, rows []db.FairPlayPly) ([]Ply, bool, error) {
+	opt, err := octad.OFEN(startOFEN)
+	if err != nil {
+		return nil, false, err
+	}
+	g, err := octad.NewGame(opt)
+	if err != nil {
+		return nil, false, err
+	}
+	whiteFirst := g.Position().Turn() == octad.White
+
+	plies := make([]Ply, len(rows))
+	for i, r := range rows {
+		valid := g.ValidMoves()
+		plies[i] = Ply{
+			Forced:  len(valid) == 1,
+			Matched: i > 0 && rows[i-1].Best != nil && *rows[i-1].Best == r.Move,
+			ThinkMs: r.ThinkMs,
+		}
+		uoi := game.UnpackMoveUOI(r.Move)
+		played := false
+		for _, m := range valid {
+			if m.String() == uoi {
+				played = g.Move(m) == nil
+				break
+			}
+		}
+		if !played {
+			return nil, false, errors.New("illegal move in archive: " + uoi)
+		}
+	}
+	return plies, whiteFirst, nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/dechristopher/lio/bus"
 	"github.com/dechristopher/lio/clock"
+	"github.com/dechristopher/lio/util"
 	"github.com/dechristopher/lio/variant"
 )
 
@@ -62,6 +63,7 @@ func NewOctadGame(config OctadGameConfig) (*OctadGame, error) {
 		White:   config.White,
 		Black:   config.Black,
 	}
+	g.Clock.SetLogger(util.With(util.GameID(g.ID)))
 
 	return &g, nil
 }
@@ -114,6 +116,10 @@ func RestoreOctadGame(config OctadGameConfig, id string, start time.Time,
 		Black:     config.Black,
 		MoveTimes: restored,
 	}
+	// a correspondence game is restored without a clock: days, not seconds
+	if clk != nil {
+		clk.SetLogger(util.With(util.GameID(id)))
+	}
 
 	return &g, nil
 }
@@ -195,6 +201,27 @@ func (g *OctadGame) OFENHistory() []string {
 	return ofens
 }
 
+// Takeback unwinds the last plies of an undecided game: the moves and the
+// positions they produced are dropped, so OFENHistory — the engine's
+// repetition history — reads as if they were never played, and MoveTimes is
+// truncated to stay parallel to the move list. It reports false, changing
+// nothing, when the game is decided or has fewer than plies moves. The clock
+// is the caller's to rewind (see clock.Clock.Rewind).
+func (g *OctadGame) Takeback(plies int) bool {
+	moves := len(g.Game.Moves())
+	if plies < 1 || plies > moves || g.Game.Outcome() != octad.NoOutcome {
+		return false
+	}
+	for i := 0; i < plies; i++ {
+		g.Game.UndoMove()
+	}
+	if len(g.MoveTimes) > moves-plies {
+		g.MoveTimes = g.MoveTimes[:moves-plies]
+	}
+	g.ToMove = g.Game.Position().Turn()
+	return true
+}
+
 // genGame creates a new game, optionally from an ofen
 func genGame(ofen ...string) (*octad.Game, error) {
 	if ofen[0] != "" {
@@ -70,3 +70,49 @@ func TestHistoryHelpers(t *testing.T) {
 		}
 	}
 }
+
+// TestTakebackUnwindsHistory verifies a takeback drops the unwound plies from
+// every history the game reports — moves, positions and timing — so the
+// position, the side to move and the engine's repetition history all read as
+// before the moves were played.
+func TestTakebackUnwindsHistory(t *testing.T) {
+	g, err := NewOctadGame(OctadGameConfig{Variant: variant.HalfOneBlitz})
+	if err != nil {
+		t.Fatalf("NewOctadGame failed: %v", err)
+	}
+
+	var before []string
+	for i := 0; i < 4; i++ {
+		if i == 2 {
+			before = g.OFENHistory()
+		}
+		moves := g.Game.ValidMoves()
+		if err := g.Game.Move(moves[0]); err != nil {
+			t.Fatalf("playing move %s failed: %v", moves[0], err)
+		}
+		g.MoveTimes = append(g.MoveTimes, MoveTime{ThinkMs: int64(i), ClockMs: 30000})
+	}
+
+	if g.Takeback(5) || g.Takeback(0) {
+		t.Fatal("takeback accepted an out-of-range ply count")
+	}
+	if !g.Takeback(2) {
+		t.Fatal("takeback of two plies refused")
+	}
+
+	ofens := g.OFENHistory()
+	if len(ofens) != len(before) {
+		t.Fatalf("OFEN history has %d positions, want %d", len(ofens), len(before))
+	}
+	for i := range before {
+		if ofens[i] != before[i] {
+			t.Fatalf("OFEN history[%d] = %q, want %q", i, ofens[i], before[i])
+		}
+	}
+	if len(g.MoveHistory()) != 2 || len(g.MoveTimes) != 2 || g.MoveTimes[1].ThinkMs != 1 {
+		t.Fatalf("moves = %v, times = %v after taking back two of four", g.MoveHistory(), g.MoveTimes)
+	}
+	if g.ToMove != g.Position().Turn() || g.OFEN() != before[len(before)-1] {
+		t.Fatalf("to move %v at %q, want the pre-takeback position", g.ToMove, g.OFEN())
+	}
+}
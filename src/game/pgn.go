@@ -25,6 +25,8 @@ import (
 //
 // There is deliberately no Event field: the Event tag is *derived* from the
 // situation fields below (see EventName), so the two paths cannot drift on it.
+// A tournament game names its event instead (Tournament), which both paths
+// resolve from the game's tournament reference.
 type PGNMeta struct {
 	Site               string
 	Variant, Group     string
@@ -45,6 +47,10 @@ type PGNMeta struct {
 	Rated  bool // affected Glicko-2 ratings
 	RaceTo int  // >0 for a race-to match: the points target
 	VsBot  bool // either seat is the engine
+	// Tournament is the name of the event a tournament game was played in
+	// (games.tournament_id resolved to tournaments.name), "" otherwise. When
+	// set it is the Event tag verbatim.
+	Tournament string
 }
 
 // PGNSeatName formats a seat's PGN display name, space-separated (no brackets):
@@ -106,7 +112,13 @@ func SeatIsBot(uid string, userID *int64) bool {
 // pre-game is deliberately unnamed: it *is* octad now, so saying so would be
 // noise on every game. A future non-standard gamemode is what belongs beside
 // the speed here ("Rated Atomic Blitz game").
+//
+// A tournament game is the exception: its event has a name of its own, which
+// is what the tag carries ("Friday Night Arena").
 func (m PGNMeta) EventName() string {
+	if m.Tournament != "" {
+		return m.Tournament
+	}
 	var sb strings.Builder
 	if m.Rated {
 		sb.WriteString("Rated ")
@@ -150,6 +162,8 @@ func eventSpeed(group, variantName string) string {
 	case variant.UnlimitedGroup:
 		// the untimed variants; "Casual" is what the site calls this mode
 		return "Casual"
+	case variant.CorrespondenceGroup:
+		return "Correspondence"
 	case variant.DeployGroup:
 		for _, c := range pools.CreateControls {
 			if c.Label == variantName {
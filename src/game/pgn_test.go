@@ -111,6 +111,7 @@ func TestBuildPGNEventTag(t *testing.T) {
 		{"vs bot", func(m *PGNMeta) { m.VsBot = true }, "Unrated Blitz game vs Computer"},
 		{"bullet", func(m *PGNMeta) { m.Group = "bullet" }, "Unrated Bullet game"},
 		{"untimed casual", func(m *PGNMeta) { m.Group = "unlimited" }, "Unrated Casual game"},
+		{"correspondence", func(m *PGNMeta) { m.Group, m.Variant, m.Rated = "correspondence", "3 days", true }, "Rated Correspondence game"},
 		{"race to match", func(m *PGNMeta) { m.Rated, m.RaceTo = true, 3 }, "Rated Blitz match (race to 3)"},
 		// the deploy start alone says nothing about the game: it is how every
 		// game starts now
@@ -139,6 +140,12 @@ func TestBuildPGNEventTag(t *testing.T) {
 			func(m *PGNMeta) { m.Group, m.StartOFEN, m.VsBot = "unlimited", deployOFEN, true },
 			"Unrated Casual game vs Computer",
 		},
+		{
+			// a tournament names its own event, whatever the situation
+			"tournament",
+			func(m *PGNMeta) { m.Rated, m.Tournament = true, "Friday Night Arena" },
+			"Friday Night Arena",
+		},
 		// an unknown group (a newer variant group reaching an old binary) still
 		// produces a sane Event rather than a mislabeled one
 		{"unknown group", func(m *PGNMeta) { m.Group = "marathon" }, "Unrated marathon game"},
@@ -0,0 +1,83 @@
+package home
+
+import "sync/atomic"
+
+// The grid across nodes. On a multi-node deployment each node runs its own
+// rooms, and the grid must still show every live game on the site, so Publish
+// also hands each event to the relay (package cluster), which republishes it
+// to every other node's hub through Receive. Each hub then holds the whole
+// site's live set and runs the same featured-slot logic over it; since every
+// hub sees the same events, they agree on what is live, if not always on the
+// order slots were backfilled in.
+//
+// A remote room's events stop when its node dies, and no RoomClosed follows.
+// The hub therefore remembers which node each remote room is on (origin), and
+// NodeGone closes every room of a node whose heartbeat has lapsed. A room that
+// another node adopts in the meantime re-announces itself from there, which
+// moves its origin, so a late NodeGone for its old node leaves it alone.
+//
+// The digest stays per node: it is re-derived from this node's rooms and
+// sockets, and relaying it would mean merging a second, non-event-sourced
+// picture. Only the grid, which was event-sourced all along, crosses over.
+
+var relay atomic.Pointer[func(Event)]
+
+// SetRelay installs the hook every published event is handed to. Called once
+// at boot by package cluster.
+func SetRelay(fn func(Event)) {
+	relay.Store(&fn)
+}
+
+// relayOut hands an event to the relay, if one is installed.
+func relayOut(e Event) {
+	if fn := relay.Load(); fn != nil {
+		(*fn)(e)
+	}
+}
+
+// Receive hands the hub an event relayed from another node. Node must be set.
+// Like Publish it never blocks, and unlike Publish it is not relayed again.
+func Receive(e Event) {
+	if e.Node == "" {
+		return
+	}
+	select {
+	case theHub.in <- hubMsg{ev: &e}:
+	default:
+	}
+}
+
+// NodeGone tells the hub a node has left the cluster, closing its rooms.
+func NodeGone(node string) {
+	if node == "" {
+		return
+	}
+	theHub.in <- hubMsg{gone: node}
+}
+
+// noteOrigin records which node a room is on. A local event, or any room's
+// close, clears it.
+func (h *hub) noteOrigin(ev Event) {
+	if ev.Node == "" || ev.Kind == RoomClosed {
+		delete(h.origin, ev.RoomID)
+		return
+	}
+	h.origin[ev.RoomID] = ev.Node
+}
+
+// dropNode closes every room the hub knows to be on a departed node,
+// broadcasting the deltas, and returns their ids.
+func (h *hub) dropNode(node string) []string {
+	var closed []string
+	for rid, n := range h.origin {
+		if n == node {
+			closed = append(closed, rid)
+		}
+	}
+	for _, rid := range closed {
+		for _, p := range h.handle(Event{Kind: RoomClosed, RoomID: rid}) {
+			h.broadcast(p)
+		}
+	}
+	return closed
+}
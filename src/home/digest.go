@@ -91,6 +91,33 @@ func MarkDirty() {
 	Publish(Event{Kind: dirtyOnly})
 }
 
+// Block tells the hub that a and b have blocked one another, so that neither
+// reads as followed by the other in the Following section or the header badge
+// from the next tick on. db.Block has already removed the edges; this removes
+// them from the follow sets the two accounts' open sockets loaded at connect,
+// which would otherwise keep them until a reload.
+func Block(a, b int64) {
+	theHub.in <- hubMsg{block: &blockPair{a, b}}
+}
+
+// blockPair is the two accounts a Block names.
+type blockPair [2]int64
+
+// applyBlock drops each account of the pair from the other's sockets' follow
+// sets. The sets are written here, on the hub goroutine, which is the only one
+// that reads them once a socket is tracked.
+func (h *hub) applyBlock(p blockPair) {
+	channel.EachSocket(func(_ string, s *channel.Socket) {
+		switch s.Acct.ID {
+		case p[0]:
+			delete(s.Follows, p[1])
+		case p[1]:
+			delete(s.Follows, p[0])
+		}
+	})
+	h.digest.dirty = true
+}
+
 // sources carries the injected supplier over the hub's inbound channel, so it
 // is installed by the owning goroutine like everything else rather than written
 // under a lock.
@@ -214,9 +241,10 @@ func (h *hub) pushFollowing() {
 
 	channel.EachSocket(func(chanName string, s *channel.Socket) {
 		live[s.ID] = struct{}{}
-		if len(s.Follows) == 0 {
+		if _, sent := d.followed[s.ID]; len(s.Follows) == 0 && !sent {
 			// an anonymous visitor, or an account that follows nobody: there is
-			// nothing to say and nothing to remember
+			// nothing to say and nothing to remember. One that followed somebody
+			// until a block emptied its set falls through, to be sent the zero.
 			return
 		}
 
@@ -283,3 +283,44 @@ func TestFollowBadgeSkipsViewersWithNoFollows(t *testing.T) {
 		t.Errorf("a socket with no follow set must be sent nothing, got %d frames", n)
 	}
 }
+
+// A block takes each account out of the other's follow set on the sockets
+// already open, and the next tick carries the change — including the zero that
+// clears the badge of a viewer whose only follow it was.
+func TestBlockDropsFollowsFromOpenSockets(t *testing.T) {
+	h := newTestHub()
+	a := followSocket(t, "block-a", "c1", 1, 2)
+	b := followSocket(t, Channel, "c2", 2, 1, 3)
+	other := followSocket(t, "block-c", "c3", 4, 2)
+
+	h.digest.src = func() (proto.HomePayload, Follows) {
+		return proto.HomePayload{Stats: stats(0, 0, 0)}, Follows{
+			Count: func(f map[int64]struct{}) int { return len(f) },
+		}
+	}
+	h.tick()
+	aFrames := frames(a)
+	aFrames() // the first tick's count
+
+	h.applyBlock(blockPair{1, 2})
+	if _, ok := a.Follows[2]; ok {
+		t.Error("the blocker still follows the blocked account")
+	}
+	if _, ok := b.Follows[1]; ok {
+		t.Error("the blocked account still follows the blocker")
+	}
+	if _, ok := b.Follows[3]; !ok {
+		t.Error("a block dropped a follow outside the pair")
+	}
+	if _, ok := other.Follows[2]; !ok {
+		t.Error("a block dropped a third account's follow")
+	}
+	if !h.digest.dirty {
+		t.Fatal("a block must mark the digest dirty")
+	}
+
+	h.tick()
+	if n := aFrames(); n != 1 {
+		t.Errorf("an emptied follow set must be sent its zero once, got %d frames", n)
+	}
+}
@@ -127,18 +127,23 @@ type Event struct {
 	Deploying  bool
 	PhaseLeft  int64
 	PhaseTotal int64
+	// Node is the node running the room, set on an event relayed from another
+	// node and empty for this node's own rooms (see cluster.go).
+	Node string `json:",omitempty"`
 }
 
 // hubMsg multiplexes the inbound request kinds onto the hub's single inbound
 // channel: a room lifecycle event, a new viewer asking for a snapshot, a hover
-// card's room watch or live-game lookup (watch.go), or the one-shot injection
-// of the digest source.
+// card's room watch or live-game lookup (watch.go), a new block (digest.go),
+// or the one-shot injection of the digest source.
 type hubMsg struct {
 	ev      *Event
 	sock    *channel.Socket
 	sources *sources
 	watch   *watchReq
 	query   *gameQuery
+	block   *blockPair
+	gone    string
 }
 
 // hub owns the live-game registry, the featured slots, and the activity digest.
@@ -150,6 +155,7 @@ type hub struct {
 	featured []string                 // ordered featured room ids, len <= Cap
 	digest   digestState              // the activity region; see digest.go
 	watch    watchState               // per-connection room watches; see watch.go
+	origin   map[string]string        // another node's rooms, to that node; see cluster.go
 }
 
 var theHub = &hub{
@@ -157,6 +163,7 @@ var theHub = &hub{
 	games:    make(map[string]*proto.TVGame),
 	featured: make([]string, 0, Cap),
 	watch:    newWatchState(),
+	origin:   make(map[string]string),
 }
 
 // Up starts the hub goroutine and pre-creates the home channel's SockMap so it
@@ -171,14 +178,18 @@ func Up() {
 	go theHub.run()
 }
 
-// Publish hands a room lifecycle event to the hub. It never blocks the caller
-// (the room routine): if the hub's inbound queue is full the event is dropped.
+// Publish hands a room lifecycle event to the hub, and to every other node's
+// hub (cluster.go). It never blocks the caller (the room routine): if the hub's
+// inbound queue is full the event is dropped.
 func Publish(e Event) {
 	select {
 	case theHub.in <- hubMsg{ev: &e}:
 	default:
 		// hub saturated; drop. The next event / a reconnect snapshot reconciles.
 	}
+	if e.Kind != dirtyOnly {
+		relayOut(e)
+	}
 }
 
 // Connect asks the hub to send the current grid snapshot and activity digest to
@@ -221,6 +232,13 @@ func (h *hub) run() {
 				h.applyWatch(m.watch)
 			case m.query != nil:
 				m.query.reply <- h.liveGameFor(m.query.username)
+			case m.block != nil:
+				h.applyBlock(*m.block)
+			case m.gone != "":
+				h.digest.dirty = true
+				for _, rid := range h.dropNode(m.gone) {
+					h.pushWatch(rid)
+				}
 			case m.ev != nil:
 				// every room event moves the digest as well as the grid: a game
 				// starting changes the live count, a room closing may free an
@@ -251,6 +269,7 @@ func (h *hub) broadcast(p proto.TVPayload) {
 // network — all fan-out happens in run/broadcast — which keeps it unit-testable
 // without any sockets.
 func (h *hub) handle(ev Event) []proto.TVPayload {
+	h.noteOrigin(ev)
 	switch ev.Kind {
 	case Start:
 		g := tvGameFrom(ev, false)
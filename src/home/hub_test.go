@@ -13,6 +13,7 @@ func newTestHub() *hub {
 		games:    make(map[string]*proto.TVGame),
 		featured: make([]string, 0, Cap),
 		watch:    newWatchState(),
+		origin:   make(map[string]string),
 	}
 }
 
@@ -199,3 +200,34 @@ func TestSnapshotReflectsFeaturedSet(t *testing.T) {
 		t.Fatalf("snapshot should preserve slot order, got %#v", snap.Snapshot)
 	}
 }
+
+// TestNodeGoneClosesItsRooms: a relayed room is closed when its node leaves
+// the cluster, and a room another node adopted in the meantime is not.
+func TestNodeGoneClosesItsRooms(t *testing.T) {
+	h := newTestHub()
+
+	local := start("rLocal", "g1")
+	h.handle(local)
+	remote := start("rRemote", "g2")
+	remote.Node = "n1"
+	h.handle(remote)
+	moved := start("rMoved", "g3")
+	moved.Node = "n1"
+	h.handle(moved)
+	// n2 adopts rMoved from n1 and re-announces it
+	moved.Node = "n2"
+	h.handle(moved)
+
+	closed := h.dropNode("n1")
+	if len(closed) != 1 || closed[0] != "rRemote" {
+		t.Fatalf("dropNode closed %v, want [rRemote]", closed)
+	}
+	if _, ok := h.games["rRemote"]; ok {
+		t.Fatal("the departed node's room is still live")
+	}
+	for _, rid := range []string{"rLocal", "rMoved"} {
+		if _, ok := h.games[rid]; !ok {
+			t.Fatalf("%s was closed with a node it is not on", rid)
+		}
+	}
+}
@@ -113,22 +113,11 @@ func Do(req Request) (*Response, error) {
 		return resp, nil
 	}
 
-	mv := findMove(g, req.UOI)
-	if mv == nil {
+	played, mv := Play(g, req.UOI)
+	if played == nil {
 		return nil, ErrBadRequest
 	}
-	before := g.Position()
-	if err := g.Move(mv); err != nil {
-		return nil, ErrBadRequest
-	}
-	resp.Move = &Move{
-		OFEN:     g.Position().String(),
-		SAN:      octad.AlgebraicNotation{}.Encode(before, mv),
-		Check:    g.Position().InCheck(),
-		UOI:      req.UOI,
-		LastMove: []string{mv.S1().String(), mv.S2().String()},
-		Capture:  mv.HasTag(octad.Capture),
-	}
+	resp.Move = played
 
 	// judge the move that was just played, in the position it produced
 	met := judge(step, mv, g)
@@ -153,20 +142,7 @@ func Do(req Request) (*Response, error) {
 
 	// the opponent answers
 	if answer := reply(step, g, req.UOI); answer != "" {
-		rmv := findMove(g, answer)
-		if rmv != nil {
-			rbefore := g.Position()
-			if err := g.Move(rmv); err == nil {
-				resp.Reply = &Move{
-					OFEN:     g.Position().String(),
-					SAN:      octad.AlgebraicNotation{}.Encode(rbefore, rmv),
-					Check:    g.Position().InCheck(),
-					UOI:      answer,
-					LastMove: []string{rmv.S1().String(), rmv.S2().String()},
-					Capture:  rmv.HasTag(octad.Capture),
-				}
-			}
-		}
+		resp.Reply, _ = Play(g, answer)
 	}
 
 	// a Solo step hands the move straight back so one piece can be practised
@@ -202,6 +178,30 @@ func Do(req Request) (*Response, error) {
 	return resp, nil
 }
 
+// Play applies a UOI move to the game and describes it the way the client
+// renders it, returning the applied move alongside for judging. Both are nil
+// when the move is not legal in the position, which leaves the game untouched.
+// The training page's puzzles round-trip their moves through here as well, so
+// both boards speak one move shape.
+func Play(g *octad.Game, uoi string) (*Move, *octad.Move) {
+	mv := findMove(g, uoi)
+	if mv == nil {
+		return nil, nil
+	}
+	before := g.Position()
+	if err := g.Move(mv); err != nil {
+		return nil, nil
+	}
+	return &Move{
+		OFEN:     g.Position().String(),
+		SAN:      octad.AlgebraicNotation{}.Encode(before, mv),
+		Check:    g.Position().InCheck(),
+		UOI:      uoi,
+		LastMove: []string{mv.S1().String(), mv.S2().String()},
+		Capture:  mv.HasTag(octad.Capture),
+	}, mv
+}
+
 // describe fills the response's view of the position the learner now faces.
 func describe(resp *Response, g *octad.Game) {
 	pos := g.Position()
@@ -211,14 +211,14 @@ func describe(resp *Response, g *octad.Game) {
 	if pos.Turn() == octad.Black {
 		resp.Turn = "black"
 	}
-	resp.Dests = legalDests(g)
+	resp.Dests = LegalDests(g)
 }
 
-// legalDests is the position's legal-move map in the shape octadground wants.
+// LegalDests is the position's legal-move map in the shape octadground wants.
 // A promotion push generates one move per piece choice, all to the same square;
 // the board wants each destination once, and the piece is chosen in the
 // promotion picker after the drag.
-func legalDests(g *octad.Game) map[string][]string {
+func LegalDests(g *octad.Game) map[string][]string {
 	dests := map[string][]string{}
 	if g.Outcome() != octad.NoOutcome {
 		return dests
@@ -32,6 +32,7 @@ import (
 
 	"github.com/dechristopher/octad/v2"
 
+	"github.com/dechristopher/lio/dispatch"
 	"github.com/dechristopher/lio/engine"
 )
 
@@ -236,7 +237,7 @@ func init() {
 			if err != nil {
 				panic(fmt.Sprintf("learn: lesson %q step %d: %v", l.Slug, si, err))
 			}
-			s.startDests = legalDests(g)
+			s.startDests = LegalDests(g)
 			if s.PriorMove != "" && !validSquarePair(s.PriorMove) {
 				panic(fmt.Sprintf("learn: lesson %q step %d: malformed PriorMove %q",
 					l.Slug, si, s.PriorMove))
@@ -362,6 +363,13 @@ func reply(s Step, g *octad.Game, uoi string) string {
 	if len(g.ValidMoves()) == 0 {
 		return ""
 	}
-	me := engine.SearchPersona(g.Position().String(), nil, botDepth, botBudget, tutorBot)
+	me := dispatch.Search(dispatch.SearchRequest{
+		Kind:    "tutor",
+		OFEN:    g.Position().String(),
+		Depth:   botDepth,
+		Budget:  botBudget,
+		Persona: tutorBot,
+		Pause:   true,
+	})
 	return me.Move.String()
 }
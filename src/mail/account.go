@@ -0,0 +1,97 @@
+package mail
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// The account messages: the link that verifies an address, and the link that
+// resets a forgotten password.
+
+// link builds an absolute link to a site path carrying a token.
+func link(path, token string) string {
+	return config.SiteURL() + strings.TrimPrefix(path, "/") + "?token=" + url.QueryEscape(token)
+}
+
+// SendVerification queues the link that verifies addr for an account. Called
+// whenever an account sets a new address, and again on request.
+func SendVerification(userID int64, username, addr string) error {
+	token := auth.EmailToken(auth.EmailVerify, userID, auth.VerifyBinding(addr))
+	return enqueue(db.OutboundMail{
+		UserID:  userID,
+		Kind:    db.MailVerify,
+		To:      addr,
+		Subject: "Confirm your email for " + config.SiteName(),
+		Body: render(verifyText, map[string]string{
+			"Username": username,
+			"Site":     config.SiteName(),
+			"Link":     link("/account/verify", token),
+		}),
+	})
+}
+
+// SendPasswordReset queues a reset link for every account matching login, a
+// username or an email address, whose address is verified. Nothing it returns
+// tells the caller whether any account matched — the page answers the same
+// either way — so an error is only for the log.
+func SendPasswordReset(login string) error {
+	login = strings.TrimSpace(login)
+	if login == "" {
+		return nil
+	}
+	var accounts []db.EmailAccount
+	if strings.Contains(login, "@") {
+		found, err := db.UsersByEmail(login)
+		if err != nil {
+			return err
+		}
+		accounts = found
+	} else {
+		rec, found, err := db.GetUserByUsername(login)
+		if err != nil {
+			return err
+		}
+		if a, ok := resettable(rec); found && ok {
+			accounts = append(accounts, a)
+		}
+	}
+	for _, a := range accounts {
+		token := auth.EmailToken(auth.EmailReset, a.ID, auth.ResetBinding(a.PasswordHash))
+		err := enqueue(db.OutboundMail{
+			UserID:  a.ID,
+			Kind:    db.MailReset,
+			To:      a.Email,
+			Subject: "Reset your " + config.SiteName() + " password",
+			Body: render(resetText, map[string]string{
+				"Username": a.Username,
+				"Site":     config.SiteName(),
+				"Link":     link("/account/reset", token),
+			}),
+		})
+		if err != nil && err != ErrRateLimited {
+			util.Error(str.CMail, "reset queue failed user=%d error=%s", a.ID, err.Error())
+		}
+	}
+	return nil
+}
+
+// resettable returns where a reset link for rec goes, and false when it has
+// nowhere to go: no address, or one never proven to reach the account — the
+// same rule the lookup by address applies (db.UsersByEmail).
+func resettable(rec db.UserRecord) (db.EmailAccount, bool) {
+	if rec.Email == nil || !rec.EmailVerified {
+		return db.EmailAccount{}, false
+	}
+	return db.EmailAccount{
+		ID:           rec.ID,
+		Username:     rec.Username,
+		Email:        *rec.Email,
+		PasswordHash: rec.PasswordHash,
+	}, true
+}
@@ -0,0 +1,172 @@
+package mail
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// The weekly digest: a summary of a player's unread notifications, for the
+// players who turned it on (prefs.KeyEmailDigest) and confirmed their address.
+//
+// There is no fixed send day. Each account is owed one once a week has passed
+// since its last, and only when something arrived in that week that it has
+// not read — a digest of nothing is not sent. The schedule lives in the outbox
+// itself: the last digest queued for an account is its last row of that kind.
+//
+// Every node runs the scheduler. Two that reach the same account at once
+// queue the same dedupe key, account and ISO week, and the second writes
+// nothing.
+
+const (
+	// digestTick is how often the scheduler looks for accounts that are due.
+	digestTick = time.Hour
+
+	// digestPeriod is the gap between one account's digests.
+	digestPeriod = 7 * 24 * time.Hour
+
+	// digestBatch is how many accounts one query returns.
+	digestBatch = 100
+
+	// digestItems is how many notifications a digest lists; the rest are
+	// counted.
+	digestItems = 10
+
+	// digestScan is how many of an account's latest notifications are read to
+	// find the unread ones.
+	digestScan = 50
+)
+
+// digestItem is one listed notification.
+type digestItem struct {
+	Body string
+	Link string
+}
+
+// digestData fills digestText.
+type digestData struct {
+	Username    string
+	Site        string
+	SiteURL     string
+	Unsubscribe string
+	Items       []digestItem
+	More        int
+}
+
+// digests runs the digest scheduler until the process exits.
+func digests() {
+	ticker := time.NewTicker(digestTick)
+	defer ticker.Stop()
+	for range ticker.C {
+		safeQueueDigests()
+	}
+}
+
+// safeQueueDigests runs one scheduler pass, converting a panic into an error
+// log so one bad account cannot stop everybody else's digest.
+func safeQueueDigests() {
+	defer func() {
+		if r := recover(); r != nil {
+			util.Error(str.CMail, "digest pass panicked: %v", r)
+		}
+	}()
+	queueDigests(time.Now())
+}
+
+// queueDigests queues a digest for every account due one at now.
+func queueDigests(now time.Time) {
+	since := now.Add(-digestPeriod)
+	var after int64
+	queued := 0
+	for {
+		due, err := db.DigestRecipients(after, since, digestBatch)
+		if err != nil {
+			util.Error(str.CMail, "digest recipients failed: %s", err.Error())
+			return
+		}
+		for _, r := range due {
+			after = r.ID
+			if queueDigest(r, since, now) {
+				queued++
+			}
+		}
+		if len(due) < digestBatch {
+			break
+		}
+	}
+	if queued > 0 {
+		util.Info(str.CMail, "queued %d weekly digests", queued)
+	}
+}
+
+// queueDigest queues one account's digest, reporting whether it did.
+func queueDigest(r db.DigestRecipient, since, now time.Time) bool {
+	notes, err := db.ListNotifications(r.ID, digestScan)
+	if err != nil {
+		util.Error(str.CMail, "digest notifications failed user=%d error=%s", r.ID, err.Error())
+		return false
+	}
+	items, more := digestEntries(notes, since, now)
+	if len(items) == 0 {
+		return false
+	}
+	unsubscribe := link("/account/unsubscribe", auth.EmailToken(auth.EmailUnsubscribe, r.ID, ""))
+	year, week := now.ISOWeek()
+	err = enqueue(db.OutboundMail{
+		UserID:  r.ID,
+		Kind:    db.MailDigest,
+		To:      r.Email,
+		Subject: "Your week on " + config.SiteName(),
+		Body: render(digestText, digestData{
+			Username:    r.Username,
+			Site:        config.SiteName(),
+			SiteURL:     config.SiteURL(),
+			Unsubscribe: unsubscribe,
+			Items:       items,
+			More:        more,
+		}),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribe + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+		DedupeKey: fmt.Sprintf("digest:%d:%d-W%02d", r.ID, year, week),
+	})
+	if err != nil {
+		util.Error(str.CMail, "digest queue failed user=%d error=%s", r.ID, err.Error())
+		return false
+	}
+	return true
+}
+
+// digestEntries picks the notifications a digest lists: unread, arrived after
+// since, and still worth acting on at now, newest first, at most digestItems
+// of them. more counts the rest.
+func digestEntries(notes []db.Notification, since, now time.Time) (items []digestItem, more int) {
+	for _, n := range notes {
+		if !n.Unread() || !n.Created.After(since) {
+			continue
+		}
+		if !n.Expires.IsZero() && !n.Expires.After(now) {
+			continue
+		}
+		if len(items) == digestItems {
+			more++
+			continue
+		}
+		item := digestItem{Body: n.Body}
+		if n.Actor != "" && !strings.Contains(n.Body, n.Actor) {
+			item.Body = n.Body + " (" + n.Actor + ")"
+		}
+		if n.Link != "" {
+			item.Link = config.SiteOrigin() + n.Link
+		}
+		items = append(items, item)
+	}
+	return items, more
+}
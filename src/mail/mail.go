@@ -0,0 +1,94 @@
+// Package mail sends the site's email: address verification, password reset
+// links, and the opt-in weekly digest of unread notifications.
+//
+// Nothing here sends from a request. A message is rendered and written to the
+// mail_outbox table, and a worker (outbox.go) delivers due rows through the
+// configured Transport, retrying a failure with backoff. That keeps a slow or
+// down mail server off every request path, and means a message queued just
+// before a deploy is still sent after it — the row is the message.
+//
+// The transport is chosen at boot (Up):
+//
+//   - SMTP, when lio_smtp_addr is set: production's relay
+//   - otherwise, outside production, a sink that writes each message to
+//     lio_mail_dir, or to stdout when that is unset, so a developer can follow
+//     a reset link without a mail server
+//
+// Production without an SMTP relay runs with mail disabled: the pages that
+// would send say so, and nothing is queued that could not go out.
+//
+// The links a message carries are signed tokens minted in the auth package
+// (auth/email.go); this package only builds the messages around them.
+package mail
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/env"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Transport delivers one composed message.
+type Transport interface {
+	Send(m Message) error
+}
+
+// transport is the configured transport, nil while mail is disabled.
+var transport atomic.Pointer[Transport]
+
+// from is the envelope and header sender.
+var from string
+
+// Up chooses the transport and starts the outbox worker and the digest
+// scheduler. Mail needs Postgres for its outbox; without it, or without a
+// transport, the site runs with mail disabled.
+func Up() {
+	if !db.Ready() {
+		return
+	}
+	from = config.ReadSecretFallback("lio_mail_from")
+	if from == "" {
+		from = config.SiteName() + " <noreply@" + hostOnly(config.SiteHost()) + ">"
+	}
+
+	var t Transport
+	if addr := config.ReadSecretFallback("lio_smtp_addr"); addr != "" {
+		t = newSMTP(addr,
+			config.ReadSecretFallback("lio_smtp_user"),
+			config.ReadSecretFallback("lio_smtp_password"))
+		util.Info(str.CMail, "mail via smtp %s", addr)
+	} else if !env.IsProd() {
+		dir := config.ReadSecretFallback("lio_mail_dir")
+		t = newSink(dir)
+		if dir == "" {
+			util.Info(str.CMail, "mail to stdout (no lio_smtp_addr)")
+		} else {
+			util.Info(str.CMail, "mail to %s (no lio_smtp_addr)", dir)
+		}
+	} else {
+		util.Info(str.CMail, "no smtp relay configured (lio_smtp_addr); mail disabled")
+		return
+	}
+	transport.Store(&t)
+
+	go worker()
+	go digests()
+}
+
+// Enabled reports whether this site can send mail. The pages that offer a
+// link by email check it first, rather than promise one that never comes.
+func Enabled() bool {
+	return transport.Load() != nil
+}
+
+// hostOnly strips a port from a host, for the sender's domain in local dev.
+func hostOnly(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i > 0 && !strings.HasSuffix(host, "]") {
+		return host[:i]
+	}
+	return host
+}
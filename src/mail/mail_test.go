@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dechristopher/lio/db"
+)
+
+var t0 = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// A header value cannot start a header of its own: a subject or a
+// List-Unsubscribe link carrying a line break stays one line.
+func TestBytesKeepsHeadersOnOneLine(t *testing.T) {
+	m := Message{
+		From:    "lio <noreply@lioctad.org>",
+		To:      "alice@example.com",
+		Subject: "hi\r\nBcc: mallory@example.com",
+		Body:    "line one\nline two\n",
+		Headers: map[string]string{"List-Unsubscribe": "<https://x>\nBcc: mallory@example.com"},
+	}
+	raw := string(m.Bytes(t0))
+	head, body, ok := strings.Cut(raw, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("no header/body split in %q", raw)
+	}
+	for _, line := range strings.Split(head, "\r\n") {
+		if strings.HasPrefix(line, "Bcc:") {
+			t.Fatalf("injected header line %q", line)
+		}
+	}
+	if !strings.Contains(body, "line one\r\nline two\r\n") {
+		t.Fatalf("body lines not CRLF terminated: %q", body)
+	}
+}
+
+// Retries back off from a minute, doubling, and settle at the ceiling.
+func TestRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:   time.Minute,
+		1:   time.Minute,
+		2:   2 * time.Minute,
+		5:   16 * time.Minute,
+		9:   256 * time.Minute,
+		10:  6 * time.Hour,
+		100: 6 * time.Hour,
+	}
+	for attempt, want := range cases {
+		if got := retryDelay(attempt); got != want {
+			t.Errorf("retryDelay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+// A digest lists the unread notifications of its week that are still worth
+// acting on, and counts what does not fit.
+func TestDigestEntries(t *testing.T) {
+	since := t0.Add(-digestPeriod)
+	var notes []db.Notification
+	notes = append(notes,
+		db.Notification{Created: t0.Add(-time.Hour), Body: "read already", Read: t0},
+		db.Notification{Created: since.Add(-time.Hour), Body: "last week"},
+		db.Notification{Created: t0.Add(-time.Hour), Body: "expired", Expires: t0.Add(-time.Minute)},
+		db.Notification{Created: t0.Add(-time.Hour), Body: "followed you", Actor: "bob", Link: "/@/bob"},
+		db.Notification{Created: t0.Add(-time.Hour), Body: "bob accepted", Actor: "bob"},
+	)
+	for i := 0; i < digestItems+2; i++ {
+		notes = append(notes, db.Notification{Created: t0.Add(-time.Hour), Body: "filler"})
+	}
+
+	items, more := digestEntries(notes, since, t0)
+	if len(items) != digestItems || more != 4 {
+		t.Fatalf("got %d items and %d more, want %d and 4", len(items), more, digestItems)
+	}
+	if items[0].Body != "followed you (bob)" || !strings.HasSuffix(items[0].Link, "/@/bob") {
+		t.Fatalf("first item = %+v", items[0])
+	}
+	if items[1].Body != "bob accepted" || items[1].Link != "" {
+		t.Fatalf("second item = %+v", items[1])
+	}
+}
+
+// A reset link by username goes only to a verified address: an unverified one
+// is whatever somebody typed into the account.
+func TestResettableNeedsVerifiedEmail(t *testing.T) {
+	addr := "alice@example.com"
+	rec := db.UserRecord{ID: 7, Username: "alice", Email: &addr, PasswordHash: "h"}
+	if _, ok := resettable(rec); ok {
+		t.Error("reset offered to an unverified address")
+	}
+	if _, ok := resettable(db.UserRecord{ID: 7, EmailVerified: true}); ok {
+		t.Error("reset offered with no address")
+	}
+	rec.EmailVerified = true
+	a, ok := resettable(rec)
+	if !ok || a.Email != addr || a.ID != 7 || a.PasswordHash != "h" {
+		t.Errorf("verified address: %+v, %v", a, ok)
+	}
+}
@@ -0,0 +1,101 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is one email as a transport sends it. Plain text only: every
+// message is a sentence or two and a link, which needs no HTML and renders
+// the same in every client.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+	// Headers are extra headers, e.g. List-Unsubscribe.
+	Headers map[string]string
+}
+
+// Recipient returns the bare address the message is for.
+func (m Message) Recipient() (string, error) {
+	a, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return "", err
+	}
+	return a.Address, nil
+}
+
+// Sender returns the bare address the message is from.
+func (m Message) Sender() (string, error) {
+	a, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return "", err
+	}
+	return a.Address, nil
+}
+
+// Bytes composes the message in RFC 5322 form, body quoted-printable.
+//
+// Every header value has its line breaks removed. The values are built here
+// from an account's address and the site's own strings, but an address is
+// something a person typed, and a header split on a newline is a header
+// somebody else wrote.
+func (m Message) Bytes(now time.Time) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) {
+		b.WriteString(k + ": " + oneLine(v) + "\r\n")
+	}
+	header("From", m.From)
+	header("To", m.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", "<"+messageID()+"@"+domainOf(m.From)+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	keys := make([]string, 0, len(m.Headers))
+	for k := range m.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		header(oneLine(k), m.Headers[k])
+	}
+	b.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	_, _ = qp.Write([]byte(strings.ReplaceAll(m.Body, "\n", "\r\n")))
+	_ = qp.Close()
+	return b.Bytes()
+}
+
+// oneLine strips the line breaks out of a header value.
+func oneLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// messageID is a random Message-ID local part.
+func messageID() string {
+	raw := make([]byte, 12)
+	_, _ = rand.Read(raw)
+	return hex.EncodeToString(raw)
+}
+
+// domainOf returns the domain of a sender address, for the Message-ID.
+func domainOf(addr string) string {
+	if a, err := mail.ParseAddress(addr); err == nil {
+		addr = a.Address
+	}
+	if i := strings.LastIndexByte(addr, '@'); i >= 0 {
+		return addr[i+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,193 @@
+package mail
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+const (
+	// workTick is how often the worker looks for due mail when nothing woke
+	// it: the pace of retries, since a fresh message wakes it at once.
+	workTick = 15 * time.Second
+
+	// workBatch bounds the messages claimed at once.
+	workBatch = 20
+
+	// workLease is how long a claimed message is this worker's. Longer than a
+	// whole batch of slow SMTP sends, so another node never takes a message
+	// that is still being sent.
+	workLease = 15 * time.Minute
+
+	// maxAttempts is how many failed sends a message gets before it gives up.
+	// With retryDelay's doubling that spans about eight hours: long enough to
+	// ride out a relay outage, and a verification link is still good at the
+	// end of it.
+	maxAttempts = 10
+
+	// keepSent is how long a finished message stays in the outbox. Longer
+	// than digestPeriod: the digest schedule reads the last digest queued from
+	// here.
+	keepSent = 14 * 24 * time.Hour
+
+	// pruneEvery is how often finished messages are deleted.
+	pruneEvery = time.Hour
+)
+
+// ErrRateLimited is a message refused by the send limits. Callers whose
+// visitor must not learn whether an address exists swallow it.
+var ErrRateLimited = errors.New("too many emails sent to that address - try again later")
+
+var (
+	// addressLimiter bounds the messages one address can be sent per kind: a
+	// reset or verification link requested again and again would otherwise be
+	// a way to flood somebody's inbox from this site.
+	addressLimiter = auth.NewLimiter(3, time.Hour)
+
+	// siteLimiter bounds what this node hands the relay per minute, under the
+	// relay's own sending limits. What it holds back waits out its lease.
+	siteLimiter = auth.NewLimiter(120, time.Minute)
+)
+
+// wake nudges the worker to send a just-queued message now rather than at
+// the next tick.
+var wake = make(chan struct{}, 1)
+
+// enqueue queues a message through the outbox, subject to the per-address
+// limit unless it carries a dedupe key (the digest, which is once a week by
+// construction).
+func enqueue(m db.OutboundMail) error {
+	if !Enabled() {
+		return errors.New("mail is disabled")
+	}
+	if m.Body == "" {
+		return errors.New("mail body is empty")
+	}
+	if m.DedupeKey == "" && !addressLimiter.Allow(m.Kind+"|"+strings.ToLower(m.To)) {
+		return ErrRateLimited
+	}
+	if _, err := db.EnqueueMail(m); err != nil {
+		return err
+	}
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// worker delivers due mail until the process exits.
+func worker() {
+	ticker := time.NewTicker(workTick)
+	defer ticker.Stop()
+	lastPrune := time.Time{}
+	for {
+		select {
+		case <-ticker.C:
+		case <-wake:
+		}
+		safeDeliver()
+		if time.Since(lastPrune) >= pruneEvery {
+			lastPrune = time.Now()
+			prune()
+		}
+	}
+}
+
+// safeDeliver runs one delivery pass, converting a panic into an error log so
+// one bad message cannot stop the rest.
+func safeDeliver() {
+	defer func() {
+		if r := recover(); r != nil {
+			util.Error(str.CMail, "mail delivery panicked: %v", r)
+		}
+	}()
+	deliver(time.Now())
+}
+
+// deliver sends the due messages, a batch at a time, until none are left or
+// the site limit is reached.
+func deliver(now time.Time) {
+	t := transport.Load()
+	if t == nil {
+		return
+	}
+	for {
+		batch, err := db.ClaimDueMail(now.Add(workLease), maxAttempts, workBatch)
+		if err != nil {
+			util.Error(str.CMail, "outbox claim failed: %s", err.Error())
+			return
+		}
+		for _, m := range batch {
+			if !siteLimiter.Allow("") {
+				// over the limit: the rest of the batch is retried when its
+				// lease runs out, with no attempt counted against it
+				return
+			}
+			send(*t, m)
+		}
+		if len(batch) < workBatch {
+			return
+		}
+	}
+}
+
+// send delivers one claimed message and records the outcome.
+func send(t Transport, m db.QueuedMail) {
+	err := t.Send(Message{
+		From:    from,
+		To:      m.To,
+		Subject: m.Subject,
+		Body:    m.Body,
+		Headers: m.Headers,
+	})
+	if err == nil {
+		if err := db.MarkMailSent(m.ID); err != nil {
+			util.Error(str.CMail, "outbox mark sent failed id=%d error=%s", m.ID, err.Error())
+		}
+		return
+	}
+	attempt := m.Attempts + 1
+	util.Error(str.CMail, "send failed id=%d kind=%s attempt=%d error=%s",
+		m.ID, m.Kind, attempt, err.Error())
+	if attempt >= maxAttempts {
+		util.Error(str.CMail, "giving up on id=%d kind=%s", m.ID, m.Kind)
+	}
+	if err := db.MarkMailFailed(m.ID, err.Error(), time.Now().Add(retryDelay(attempt))); err != nil {
+		util.Error(str.CMail, "outbox mark failed failed id=%d error=%s", m.ID, err.Error())
+	}
+}
+
+// retryDelay is the wait after a message's attempt-th failed send: a minute,
+// doubling, capped at six hours.
+func retryDelay(attempt int) time.Duration {
+	const ceiling = 6 * time.Hour
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 10 {
+		return ceiling
+	}
+	d := time.Minute << (attempt - 1)
+	if d > ceiling {
+		return ceiling
+	}
+	return d
+}
+
+// prune deletes finished messages older than keepSent.
+func prune() {
+	n, err := db.PruneMail(time.Now().Add(-keepSent), maxAttempts)
+	if err != nil {
+		util.Error(str.CMail, "outbox prune failed: %s", err.Error())
+		return
+	}
+	if n > 0 {
+		util.Debug(str.CMail, "pruned %d finished messages", n)
+	}
+}
@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// smtpTimeout bounds one whole delivery: dial, handshake and data. A relay
+// slower than this is retried later rather than holding the worker.
+const smtpTimeout = 30 * time.Second
+
+// smtpTransport delivers through an SMTP relay: implicit TLS on port 465,
+// STARTTLS everywhere else. A relay that offers neither is refused rather than
+// sent credentials and reset links in the clear.
+type smtpTransport struct {
+	addr string
+	host string
+	user string
+	pass string
+}
+
+func newSMTP(addr, user, pass string) *smtpTransport {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return &smtpTransport{addr: addr, host: host, user: user, pass: pass}
+}
+
+// Send delivers one message.
+func (t *smtpTransport) Send(m Message) error {
+	sender, err := m.Sender()
+	if err != nil {
+		return err
+	}
+	rcpt, err := m.Recipient()
+	if err != nil {
+		return err
+	}
+
+	conn, err := t.dial()
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(smtpTimeout))
+	c, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if _, implicit := conn.(*tls.Conn); !implicit {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("smtp relay does not offer STARTTLS")
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: t.host}); err != nil {
+			return err
+		}
+	}
+	if t.user != "" {
+		if err := c.Auth(smtp.PlainAuth("", t.user, t.pass, t.host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(sender); err != nil {
+		return err
+	}
+	if err := c.Rcpt(rcpt); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(m.Bytes(time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// dial opens the connection, already in TLS on the implicit-TLS port.
+func (t *smtpTransport) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	if _, port, _ := net.SplitHostPort(t.addr); port == "465" {
+		return tls.DialWithDialer(dialer, "tcp", t.addr, &tls.Config{ServerName: t.host})
+	}
+	return dialer.Dial("tcp", t.addr)
+}
@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"strings"
+	"text/template"
+)
+
+// The message bodies. Plain text, short, and each one says why it was sent, so
+// a message somebody did not ask for explains itself and what to do about it.
+
+var verifyText = template.Must(template.New("verify").Parse(`Hi {{.Username}},
+
+Confirm that this is your email address for {{.Site}}:
+
+{{.Link}}
+
+The link works for 48 hours. A confirmed address is what lets you reset a
+forgotten password, and receive the weekly digest if you turn it on.
+
+If you did not add this address to a {{.Site}} account, ignore this email and
+nothing will be sent to it again.
+`))
+
+var resetText = template.Must(template.New("reset").Parse(`Hi {{.Username}},
+
+Somebody asked to reset the password of your {{.Site}} account. To choose a
+new one, follow this link within the hour:
+
+{{.Link}}
+
+Resetting signs you out everywhere. Your authenticator app or passkey, if you
+use one, is still asked for when you next log in.
+
+If it was not you, ignore this email: your password has not changed.
+`))
+
+var digestText = template.Must(template.New("digest").Parse(`Hi {{.Username}},
+
+Here is what happened on {{.Site}} this week while you were away.
+{{range .Items}}
+- {{.Body}}{{if .Link}}
+  {{.Link}}{{end}}
+{{end}}{{if .More}}
+...and {{.More}} more.
+{{end}}
+See them all: {{.SiteURL}}
+
+You are getting this because you turned on the weekly digest. To stop it:
+{{.Unsubscribe}}
+`))
+
+// render executes a body template. The templates are fixed and their data is
+// built here, so a failure is a programming error; it yields an empty body,
+// which enqueue refuses.
+func render(t *template.Template, data any) string {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return ""
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package matchmaking
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/variant"
+)
+
+const (
+	// minWindow is the narrowest rating window anyone starts with. An
+	// established player's window opens at their rating deviation, so an
+	// uncertain (new) rating is matched more loosely from the first second.
+	minWindow = 50.0
+	// maxStartWindow caps where an uncertain rating's window starts: a new
+	// account's 350 deviation would otherwise accept anyone straight away.
+	maxStartWindow = 200.0
+	// widenPerSecond is how fast a window opens while its player waits.
+	widenPerSecond = 10.0
+	// openAfter is the wait after which a player accepts any rating in their
+	// pool — and, for an account, an anonymous opponent in an unrated game. A
+	// game against somebody far away is better than no game at all.
+	openAfter = 45 * time.Second
+	// rematchAfter is the wait after which two players who have just played
+	// each other may be paired again: the pool is evidently just the two of
+	// them.
+	rematchAfter = 30 * time.Second
+)
+
+// entry is one queued session.
+type entry struct {
+	Identity player.Identity
+	Variant  variant.Variant
+	// Rating / RD are the player's Glicko-2 rating in the pool's category, or
+	// the unrated default for an anonymous player.
+	Rating float64
+	RD     float64
+	Joined time.Time
+	// seen is when the session last had a socket open. A queued player whose
+	// every page has gone away is dropped rather than paired into a room
+	// nobody will open.
+	seen time.Time
+}
+
+// accountID is the entry's account, 0 for an anonymous session.
+func (e *entry) accountID() int64 {
+	if e.Identity.UserID == nil {
+		return 0
+	}
+	return *e.Identity.UserID
+}
+
+// key is the identity rematches are remembered by: the account when there is
+// one, so a second tab or device is the same opponent, otherwise the session.
+func (e *entry) key() string {
+	if id := e.accountID(); id != 0 {
+		return "a:" + strconv.FormatInt(id, 10)
+	}
+	return "u:" + e.Identity.UID
+}
+
+// window is how far from their own rating a player accepts an opponent after
+// waiting until now. Infinite once they have waited openAfter.
+func (e *entry) window(now time.Time) float64 {
+	waited := now.Sub(e.Joined)
+	if waited >= openAfter {
+		return math.Inf(1)
+	}
+	start := math.Min(math.Max(e.RD, minWindow), maxStartWindow)
+	return start + widenPerSecond*waited.Seconds()
+}
+
+// compatible reports whether two entries of one pool may be paired now. last
+// maps each key to the key of the opponent they were most recently paired
+// with; blocked holds the pairs of accounts that share a block.
+func compatible(a, b *entry, now time.Time, last map[string]string, blocked map[db.BlockPair]bool) bool {
+	// two sessions of one account never play each other
+	if a.Identity.UID == b.Identity.UID || (a.accountID() != 0 && a.accountID() == b.accountID()) {
+		return false
+	}
+	// an account plays an anonymous opponent (unrated) only once it would
+	// take anybody
+	waitedA, waitedB := now.Sub(a.Joined), now.Sub(b.Joined)
+	if (a.accountID() == 0) != (b.accountID() == 0) {
+		if a.accountID() != 0 && waitedA < openAfter {
+			return false
+		}
+		if b.accountID() != 0 && waitedB < openAfter {
+			return false
+		}
+	}
+	if last[a.key()] == b.key() || last[b.key()] == a.key() {
+		if waitedA < rematchAfter || waitedB < rematchAfter {
+			return false
+		}
+	}
+	// a block is never waited out: a seat the pairing would fill is one
+	// room.SeatBlocked refuses, so the queue does not fill it either
+	if a.accountID() != 0 && b.accountID() != 0 && blocked[db.PairOf(a.accountID(), b.accountID())] {
+		return false
+	}
+	// both sides have to accept the gap
+	return math.Abs(a.Rating-b.Rating) <= math.Min(a.window(now), b.window(now))
+}
+
+// match pairs one pool: every acceptable pairing is ranked by its rating gap,
+// and the closest are taken first. Anyone left over waits for the next pass
+// with a wider window.
+func match(entries []*entry, now time.Time, last map[string]string, blocked map[db.BlockPair]bool) [][2]*entry {
+	type candidate struct {
+		a, b *entry
+		gap  float64
+	}
+	var cands []candidate
+	for i := 0; i < len(entries); i++ {
+		for j := i + 1; j < len(entries); j++ {
+			if compatible(entries[i], entries[j], now, last, blocked) {
+				cands = append(cands, candidate{
+					a:   entries[i],
+					b:   entries[j],
+					gap: math.Abs(entries[i].Rating - entries[j].Rating),
+				})
+			}
+		}
+	}
+	// closest first; between equal gaps whoever has waited longest, then by
+	// uid so a pass is deterministic
+	earliest := func(c candidate) time.Time {
+		if c.a.Joined.Before(c.b.Joined) {
+			return c.a.Joined
+		}
+		return c.b.Joined
+	}
+	sort.SliceStable(cands, func(i, j int) bool {
+		if cands[i].gap != cands[j].gap {
+			return cands[i].gap < cands[j].gap
+		}
+		if ei, ej := earliest(cands[i]), earliest(cands[j]); !ei.Equal(ej) {
+			return ei.Before(ej)
+		}
+		return cands[i].a.Identity.UID+cands[i].b.Identity.UID < cands[j].a.Identity.UID+cands[j].b.Identity.UID
+	})
+
+	taken := make(map[*entry]bool)
+	var out [][2]*entry
+	for _, c := range cands {
+		if taken[c.a] || taken[c.b] {
+			continue
+		}
+		taken[c.a], taken[c.b] = true, true
+		out = append(out, [2]*entry{c.a, c.b})
+	}
+	return out
+}
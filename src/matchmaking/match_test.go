@@ -0,0 +1,189 @@
+package matchmaking
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/variant"
+)
+
+var t0 = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// queued builds an account's entry that joined waited ago, with an
+// established (narrow) rating deviation.
+func queued(uid string, acct int64, r float64, waited time.Duration) *entry {
+	e := &entry{
+		Identity: player.Identity{UID: uid},
+		Variant:  variant.HalfOneBlitzDeploy,
+		Rating:   r,
+		RD:       60,
+		Joined:   t0.Add(-waited),
+	}
+	if acct != 0 {
+		id := acct
+		e.Identity.UserID = &id
+	}
+	return e
+}
+
+func pairIDs(p [2]*entry) [2]string {
+	a, b := p[0].Identity.UID, p[1].Identity.UID
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// TestWindowWidens locks the window's shape: it opens at the rating deviation
+// (clamped), widens with the wait, and takes anybody after openAfter.
+func TestWindowWidens(t *testing.T) {
+	e := queued("a", 1, 1500, 0)
+	if got := e.window(t0); got != 60 {
+		t.Fatalf("window at join = %v, want the RD 60", got)
+	}
+	e.RD = 20
+	if got := e.window(t0); got != minWindow {
+		t.Fatalf("window for a tiny RD = %v, want %v", got, minWindow)
+	}
+	e.RD = 350
+	if got := e.window(t0); got != maxStartWindow {
+		t.Fatalf("window for a new rating = %v, want %v", got, maxStartWindow)
+	}
+	e = queued("a", 1, 1500, 10*time.Second)
+	if got := e.window(t0); got != 60+10*widenPerSecond {
+		t.Fatalf("window after 10s = %v, want %v", got, 60+10*widenPerSecond)
+	}
+	e = queued("a", 1, 1500, openAfter)
+	if !math.IsInf(e.window(t0), 1) {
+		t.Fatal("window after openAfter is not open")
+	}
+}
+
+// TestMatchClosestFirst pairs the closest ratings rather than the first
+// arrivals.
+func TestMatchClosestFirst(t *testing.T) {
+	entries := []*entry{
+		queued("a", 1, 1500, 20*time.Second),
+		queued("b", 2, 1700, 20*time.Second),
+		queued("c", 3, 1520, 0),
+		queued("d", 4, 1690, 0),
+	}
+	pairs := match(entries, t0, nil, nil)
+	if len(pairs) != 2 {
+		t.Fatalf("got %d pairs, want 2", len(pairs))
+	}
+	got := map[[2]string]bool{pairIDs(pairs[0]): true, pairIDs(pairs[1]): true}
+	if !got[[2]string{"a", "c"}] || !got[[2]string{"b", "d"}] {
+		t.Fatalf("pairs = %v, want a-c and b-d", got)
+	}
+}
+
+// TestMatchBothSidesAcceptTheGap keeps a newcomer's narrow window from being
+// overridden by a long waiter's wide one.
+func TestMatchBothSidesAcceptTheGap(t *testing.T) {
+	entries := []*entry{
+		queued("a", 1, 1500, 30*time.Second), // window 360
+		queued("b", 2, 1800, 0),              // window 60
+	}
+	if pairs := match(entries, t0, nil, nil); len(pairs) != 0 {
+		t.Fatalf("paired a 300-point gap against a fresh 60-point window: %v", pairIDs(pairs[0]))
+	}
+	entries[1].Joined = t0.Add(-25 * time.Second) // window 310
+	if pairs := match(entries, t0, nil, nil); len(pairs) != 1 {
+		t.Fatal("both windows cover the gap but nothing was paired")
+	}
+}
+
+// TestMatchAvoidsImmediateRematch skips the opponent a player just had while
+// somebody else is available, and allows it once both have waited.
+func TestMatchAvoidsImmediateRematch(t *testing.T) {
+	a := queued("a", 1, 1500, 5*time.Second)
+	b := queued("b", 2, 1500, 5*time.Second)
+	c := queued("c", 3, 1540, 5*time.Second)
+	last := map[string]string{a.key(): b.key(), b.key(): a.key()}
+
+	pairs := match([]*entry{a, b, c}, t0, last, nil)
+	if len(pairs) != 1 || pairIDs(pairs[0]) == [2]string{"a", "b"} {
+		t.Fatalf("pairs = %v, want one pair that is not a-b", pairs)
+	}
+
+	if pairs := match([]*entry{a, b}, t0, last, nil); len(pairs) != 0 {
+		t.Fatal("rematch paired straight away")
+	}
+	a.Joined, b.Joined = t0.Add(-rematchAfter), t0.Add(-rematchAfter)
+	if pairs := match([]*entry{a, b}, t0, last, nil); len(pairs) != 1 {
+		t.Fatal("rematch still refused after both waited rematchAfter")
+	}
+}
+
+// TestMatchAnonymous pairs anonymous sessions with each other, and with an
+// account only once that account would take anybody.
+func TestMatchAnonymous(t *testing.T) {
+	x := queued("x", 0, 1500, 0)
+	y := queued("y", 0, 1500, 0)
+	if pairs := match([]*entry{x, y}, t0, nil, nil); len(pairs) != 1 {
+		t.Fatal("two anonymous sessions were not paired")
+	}
+
+	acct := queued("a", 1, 1500, 10*time.Second)
+	if pairs := match([]*entry{x, acct}, t0, nil, nil); len(pairs) != 0 {
+		t.Fatal("an account was paired with an anonymous session before openAfter")
+	}
+	acct.Joined = t0.Add(-openAfter)
+	if pairs := match([]*entry{x, acct}, t0, nil, nil); len(pairs) != 1 {
+		t.Fatal("an open account was not paired with an anonymous session")
+	}
+}
+
+// TestMatchSameAccount never seats one account on both sides.
+func TestMatchSameAccount(t *testing.T) {
+	entries := []*entry{
+		queued("laptop", 7, 1500, time.Minute),
+		queued("phone", 7, 1500, time.Minute),
+	}
+	if pairs := match(entries, t0, nil, nil); len(pairs) != 0 {
+		t.Fatal("one account was paired against itself")
+	}
+}
+
+// TestPoolCoversEveryControl checks every curated time control can be queued
+// for, in both modes, and the untimed casual variants cannot.
+func TestPoolCoversEveryControl(t *testing.T) {
+	for _, v := range []variant.Variant{
+		variant.QuarterZeroBullet, variant.HalfOneBlitz, variant.OneTwoRapid, variant.ThreeFiveRapid,
+		variant.QuarterZeroBulletDeploy, variant.HalfOneBlitzDeploy, variant.OneTwoRapidDeploy, variant.ThreeFiveRapidDeploy,
+	} {
+		if !Pool(v) {
+			t.Errorf("%s has no pool", v.HTMLName)
+		}
+	}
+	if Pool(variant.UnlimitedCasualDeploy) {
+		t.Error("the casual variant has a pool")
+	}
+}
+
+// TestMatchBlockedPair never pairs two accounts with a block between them,
+// however long they wait, and pairs each with somebody else instead.
+func TestMatchBlockedPair(t *testing.T) {
+	a := queued("a", 1, 1500, time.Minute)
+	b := queued("b", 2, 1500, time.Minute)
+	blocked := map[db.BlockPair]bool{db.PairOf(2, 1): true}
+	if pairs := match([]*entry{a, b}, t0, nil, blocked); len(pairs) != 0 {
+		t.Fatal("a blocked pair was paired")
+	}
+
+	c := queued("c", 3, 1510, time.Minute)
+	d := queued("d", 4, 1490, time.Minute)
+	pairs := match([]*entry{a, b, c, d}, t0, nil, blocked)
+	if len(pairs) != 2 {
+		t.Fatalf("got %d pairs, want 2", len(pairs))
+	}
+	for _, p := range pairs {
+		if pairIDs(p) == [2]string{"a", "b"} {
+			t.Fatalf("pairs = %v, paired the blocked a-b", pairs)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// Package matchmaking is the quick-pairing queue. A player picks a time
+// control and waits; a matcher pairs the queue every second, closest ratings
+// first, and seats both players in a fresh room.
+//
+// There is one pool per variant in pools.RatingPools, because that is what a
+// rating is kept for: each player enters with their Glicko-2 rating in the
+// pool's own category (the variant's HTMLName, as db.RatingOrDefault and the
+// rating update both key it), or the unrated default when anonymous.
+//
+// A window bounds how far apart two ratings may be. It opens at the player's
+// rating deviation — an uncertain rating is matched loosely from the start —
+// and widens while they wait, until after openAfter anybody in the pool will
+// do. Both sides have to accept the gap, so a newcomer does not drag somebody
+// who has waited a while into a lopsided game the moment they arrive.
+//
+// Accounts are paired with accounts and the game is rated, as any logged-in
+// creator's competitive game is by default. An anonymous player is paired with
+// another anonymous player, or with an account that has waited long enough to
+// take anybody; that game is unrated, since a rating needs two accounts.
+//
+// Everything is keyed by the session uid, like the seats the pairing ends in.
+// A queued player stays queued while any page of theirs holds a socket, so
+// they can wait on the queue page or wander off to the home page, and the
+// pairing frame (proto.MatchTag) is sent to every socket the session has.
+// A session that has had no socket for queueGrace is dropped, and so is one
+// that has since sat down in another room.
+//
+// The queue is this process's memory, and so is the seat index (room.Engaged,
+// room.AccountBusy) it checks against. On a multi-node deployment only the
+// sessions queued on one node are paired with each other, and a game on
+// another node does not keep an account out of this queue. Pairing across
+// nodes is unsupported; see the cluster package doc.
+package matchmaking
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/pools"
+	"github.com/dechristopher/lio/rating"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/settings"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/variant"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+const (
+	// tick is the matcher's cadence.
+	tick = time.Second
+	// queueGrace is how long a queued session may go without a socket before
+	// it is dropped: long enough to survive a page navigation or a reconnect.
+	queueGrace = 15 * time.Second
+	// recentFor is how long a pairing is remembered for rematch avoidance.
+	recentFor = 30 * time.Minute
+)
+
+var (
+	// ErrBadControl refuses a time control that is not a rating pool.
+	ErrBadControl = errors.New("that time control has no pairing pool")
+)
+
+// recent is the opponent a player was last paired with.
+type recent struct {
+	opponent string
+	at       time.Time
+}
+
+// queue is every queued session, keyed by uid, and the last pairing of every
+// player the matcher has recently paired.
+var queue = struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	last    map[string]recent
+}{
+	entries: make(map[string]*entry),
+	last:    make(map[string]recent),
+}
+
+var upOnce sync.Once
+
+// Status is a queued session's place in the queue, for the queue page.
+type Status struct {
+	Variant variant.Variant
+	Since   time.Time
+	Rating  float64
+}
+
+// Pool reports whether a variant can be queued for: every timed variant in
+// pools.RatingPools.
+func Pool(v variant.Variant) bool {
+	if v.Casual {
+		return false
+	}
+	for _, pool := range pools.RatingPools {
+		for _, control := range pool {
+			if control.HTMLName == v.HTMLName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Up starts the matcher.
+func Up() {
+	upOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(tick)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				pass(now)
+			}
+		}()
+	})
+}
+
+// Enter queues a session for a time control, replacing any entry it (or
+// another session of the same account) already had. The caller has already
+// refused a player who is engaged in a game.
+func Enter(id player.Identity, v variant.Variant) error {
+	if !Pool(v) {
+		return ErrBadControl
+	}
+	r := rating.New()
+	if id.UserID != nil {
+		r = db.RatingOrDefault(*id.UserID, v.HTMLName)
+	}
+	now := time.Now()
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if id.UserID != nil {
+		for uid, e := range queue.entries {
+			if e.accountID() == *id.UserID {
+				delete(queue.entries, uid)
+			}
+		}
+	}
+	queue.entries[id.UID] = &entry{
+		Identity: id,
+		Variant:  v,
+		Rating:   r.R,
+		RD:       r.RD,
+		Joined:   now,
+		seen:     now,
+	}
+	util.DebugFlag("matchmaking", str.CMatch, "uid %s queued for %s at %.0f", id.UID, v.HTMLName, r.R)
+	return nil
+}
+
+// Leave takes a session out of the queue, reporting whether it was in it.
+func Leave(uid string) bool {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	_, ok := queue.entries[uid]
+	delete(queue.entries, uid)
+	return ok
+}
+
+// Queued reports a session's entry.
+func Queued(uid string) (Status, bool) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	e, ok := queue.entries[uid]
+	if !ok {
+		return Status{}, false
+	}
+	return Status{Variant: e.Variant, Since: e.Joined, Rating: e.Rating}, true
+}
+
+// Waiting counts the sessions queued for each variant, by HTMLName.
+func Waiting() map[string]int {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	out := make(map[string]int)
+	for _, e := range queue.entries {
+		out[e.Variant.HTMLName]++
+	}
+	return out
+}
+
+// pass is one matcher tick: prune the queue, pair each pool, and start the
+// games outside the lock.
+func pass(now time.Time) {
+	connected := channel.Connected()
+
+	queue.mu.Lock()
+	for uid, e := range queue.entries {
+		if _, ok := connected[uid]; ok {
+			e.seen = now
+		} else if now.Sub(e.seen) > queueGrace {
+			delete(queue.entries, uid)
+			util.DebugFlag("matchmaking", str.CMatch, "uid %s dropped from the queue: gone", uid)
+			continue
+		}
+		// playing since queueing: a challenge accepted, a tournament game,
+		// another tab's quick pairing (on this node; see the package doc)
+		if room.Engaged(uid, e.accountID()) {
+			delete(queue.entries, uid)
+			util.DebugFlag("matchmaking", str.CMatch, "uid %s dropped from the queue: playing", uid)
+		}
+	}
+	for k, r := range queue.last {
+		if now.Sub(r.at) > recentFor {
+			delete(queue.last, k)
+		}
+	}
+
+	// maintenance mode stops games starting; the queue waits it out
+	if settings.Current().Maintenance {
+		queue.mu.Unlock()
+		return
+	}
+
+	last := make(map[string]string, len(queue.last))
+	for k, r := range queue.last {
+		last[k] = r.opponent
+	}
+	byPool := make(map[string][]*entry)
+	for _, e := range queue.entries {
+		// An account holding a seat on this node sits this pass out rather than
+		// leaving the queue: the seek its own Enter superseded is torn down
+		// asynchronously and may still be indexed for a moment, and a seat
+		// in somebody's waiting room is a commitment to that game until it
+		// starts or is cancelled.
+		if room.AccountBusy(e.accountID()) {
+			continue
+		}
+		byPool[e.Variant.HTMLName] = append(byPool[e.Variant.HTMLName], e)
+	}
+	var pairs [][2]*entry
+	for _, entries := range byPool {
+		for _, p := range match(entries, now, last, blocksAmong(entries)) {
+			delete(queue.entries, p[0].Identity.UID)
+			delete(queue.entries, p[1].Identity.UID)
+			queue.last[p[0].key()] = recent{opponent: p[1].key(), at: now}
+			queue.last[p[1].key()] = recent{opponent: p[0].key(), at: now}
+			pairs = append(pairs, p)
+		}
+	}
+	queue.mu.Unlock()
+
+	for _, p := range pairs {
+		if err := start(p[0], p[1]); err != nil {
+			util.Error(str.CMatch, "pairing %s vs %s failed: %s", p[0].Identity.UID, p[1].Identity.UID, err.Error())
+			requeue(p[0], p[1])
+		}
+	}
+}
+
+// blocksAmong returns the pairs of accounts in one pool that share a block,
+// which the matcher never pairs: it pre-seats both players, so the seat check
+// a joining player meets (room.SeatBlocked) never runs. When the blocks cannot
+// be read no two accounts are paired this pass, as that check fails closed too.
+func blocksAmong(entries []*entry) map[db.BlockPair]bool {
+	var ids []int64
+	for _, e := range entries {
+		if id := e.accountID(); id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	blocked, err := db.BlockedPairs(ids)
+	if err != nil {
+		util.Error(str.CMatch, "block lookup failed: %s", err.Error())
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				blocked[db.PairOf(a, b)] = true
+			}
+		}
+	}
+	return blocked
+}
+
+// requeue returns the players of a pairing that could not be started, keeping
+// their place (and their widened windows).
+func requeue(entries ...*entry) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	for _, e := range entries {
+		if _, ok := queue.entries[e.Identity.UID]; !ok {
+			queue.entries[e.Identity.UID] = e
+		}
+	}
+}
+
+// start creates the pairing's room with both players seated and sends each of
+// them there.
+func start(a, b *entry) error {
+	white, black := a, b
+	if util.RandomColor() == octad.Black {
+		white, black = b, a
+	}
+	v := a.Variant
+
+	params := room.NewParams(white.Identity, v)
+	params.Players[octad.White] = seat(white)
+	params.Players[octad.Black] = seat(black)
+	// rated when both are accounts, like a logged-in creator's competitive
+	// game; the site-wide switch applies as it does to every new room
+	params.Rated = settings.Current().RatedEnabled &&
+		white.accountID() != 0 && black.accountID() != 0
+
+	r, err := room.Create(params)
+	if err != nil {
+		return err
+	}
+
+	send := func(to, opp *entry) {
+		channel.SendToUID(to.Identity.UID, proto.MatchMessage(proto.MatchPayload{
+			RoomID:   r.ID,
+			Opponent: displayName(opp),
+		}))
+	}
+	send(white, black)
+	send(black, white)
+
+	util.Info(str.CMatch, "[%s] paired %s (%.0f) vs %s (%.0f) in %s, rated=%v", r.ID,
+		displayName(white), white.Rating, displayName(black), black.Rating, v.HTMLName, params.Rated)
+	return nil
+}
+
+// seat is an entry's player in the room.
+func seat(e *entry) *player.Player {
+	return &player.Player{
+		ID:       e.Identity.UID,
+		UserID:   e.Identity.UserID,
+		Username: e.Identity.Username,
+		Title:    e.Identity.Title,
+	}
+}
+
+// displayName is how a pairing names a player: their username, or
+// "Anonymous".
+func displayName(e *entry) string {
+	if e.Identity.Username != "" {
+		return e.Identity.Username
+	}
+	return "Anonymous"
+}
@@ -83,6 +83,22 @@ type RoomTemplatePayload struct {
 	// name, and the bot rematch fallback URL carries it so a fresh room keeps
 	// the same difficulty. Empty and unused for human games.
 	BotPersona string
+	// Tournament / TournamentName identify the tournament that paired the game
+	// (room.Params.Tournament), empty for every other room. The page links back
+	// to the event, and the copy button's fallback PGN names it in the Event
+	// tag. Berserk shows the berserk button (an Arena option).
+	Tournament     string
+	TournamentName string
+	Berserk        bool
+	// Simul is the simul the game is a board of (room.Params.Simul), empty
+	// for every other room; SimulHost reports the viewer is its host, whose
+	// page links on to their next board.
+	Simul     string
+	SimulHost bool
+	// Takeback shows the takeback button: the room allows takebacks (a casual
+	// or bot game that is neither rated nor a tournament's) and the viewer is
+	// seated.
+	Takeback bool
 	// H2HWhite / H2HBlack are each seat's all-time head-to-head score (win = 1,
 	// draw = ½) against the current opponent, shown beside the match-timeline
 	// names with the leader greened. H2HShow gates rendering: set only when both
@@ -99,8 +115,17 @@ type RoomTemplatePayload struct {
 type RoomMove struct {
 	Player string
 	GameID string // optional game identifier used for filtering out engine moves from previous games
-	Move   proto.MovePayload
-	Ctx    channel.SocketContext
+	// OFEN is the position an engine move was searched in (optional, like
+	// GameID): a takeback can rewind the game under a search in flight, and
+	// its move must not land on whatever position the game reaches next.
+	OFEN string
+	Move proto.MovePayload
+	Ctx  channel.SocketContext
+
+	// Premove marks a move the room played from the player's premove queue
+	// rather than one that arrived over the wire; its clock flip charges the
+	// premove think time (clock.Premove)
+	Premove bool
 }
 
 type RoomControl struct {
@@ -150,4 +175,8 @@ const (
 	// and start the next game now. Both seats must ask before the room advances
 	// early; the interlude's timer still starts it if only one does.
 	NextGame
+	// Takeback asks to unwind the requester's last move in a casual or bot
+	// game (room/takeback.go); from the opponent of a standing request, it
+	// accepts it.
+	Takeback
 )
@@ -0,0 +1,351 @@
+// Package metrics keeps the process's event counters and latency histograms
+// and renders them in the Prometheus text exposition format, served at /metrics
+// on the loopback health listener (www/health.go).
+//
+// It is deliberately small rather than a client library: lio needs counters,
+// histograms and a handful of scrape-time gauges, all with at most one label,
+// and the exposition format for that is a page of code. Every family is
+// declared by the package that owns the event, next to the code that records
+// it, the way lag.Move lives beside the room routine it times.
+//
+// Gauges are not kept here. A gauge is a reading of state that already exists
+// somewhere (the room map, the socket maps, the Go runtime), so the listener
+// samples it at scrape time and writes it with WriteGauge — there is no second
+// copy to drift out of step with the first.
+//
+// Like sysinfo, everything is process-local: on a multi-node deployment each
+// node reports its own figures and the scraper sums them.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the histogram bounds, in seconds, for anything timed in
+// the millisecond-to-seconds range: a bot's search, an archive transaction, a
+// wait for the dispatcher.
+var LatencyBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+// family is one named metric and everything recorded under it.
+type family interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// registry holds every family declared in the process, keyed by name.
+var registry = struct {
+	sync.Mutex
+	families map[string]family
+}{families: make(map[string]family)}
+
+// register adds a family to the registry. Two packages declaring the same name
+// is a programmer error that would silently merge unrelated series, so it
+// panics at init rather than producing a confusing scrape.
+func register(f family) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.families[f.name()]; ok {
+		panic("metrics: duplicate metric " + f.name())
+	}
+	registry.families[f.name()] = f
+}
+
+// Write renders every registered family, sorted by name.
+func Write(w io.Writer) error {
+	registry.Lock()
+	families := make([]family, 0, len(registry.families))
+	for _, f := range registry.families {
+		families = append(families, f)
+	}
+	registry.Unlock()
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name() < families[j].name()
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// WriteGauge renders a single scrape-time gauge.
+func WriteGauge(w io.Writer, name, help string, value float64) {
+	_, _ = fmt.Fprint(w, header(name, help, "gauge"))
+	_, _ = fmt.Fprintf(w, "%s %s\n", name, formatFloat(value))
+}
+
+// WriteGaugeVec renders a scrape-time gauge with one label, a series per key
+// of values, sorted so consecutive scrapes diff cleanly.
+func WriteGaugeVec(w io.Writer, name, help, label string, values map[string]float64) {
+	_, _ = fmt.Fprint(w, header(name, help, "gauge"))
+	for _, key := range sortedKeys(values) {
+		_, _ = fmt.Fprintf(w, "%s{%s} %s\n", name, labelPair(label, key), formatFloat(values[key]))
+	}
+}
+
+// Counter is a monotonically increasing count of events.
+type Counter struct {
+	n atomic.Uint64
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() {
+	c.n.Add(1)
+}
+
+// Add adds n to the counter.
+func (c *Counter) Add(n uint64) {
+	c.n.Add(n)
+}
+
+// Value returns the current count.
+func (c *Counter) Value() uint64 {
+	return c.n.Load()
+}
+
+// counterFamily is an unlabelled counter.
+type counterFamily struct {
+	Counter
+	fName, help string
+}
+
+// NewCounter declares an unlabelled counter.
+func NewCounter(name, help string) *Counter {
+	f := &counterFamily{fName: name, help: help}
+	register(f)
+	return &f.Counter
+}
+
+func (f *counterFamily) name() string { return f.fName }
+
+func (f *counterFamily) write(w *bufio.Writer) {
+	w.WriteString(header(f.fName, f.help, "counter"))
+	fmt.Fprintf(w, "%s %d\n", f.fName, f.Value())
+}
+
+// CounterVec is a counter partitioned by one label. Children are created on
+// first use, so a label value only appears once something has been counted
+// under it.
+type CounterVec struct {
+	fName, help, label string
+
+	mu       sync.Mutex
+	children map[string]*Counter
+}
+
+// NewCounterVec declares a counter with one label.
+func NewCounterVec(name, help, label string) *CounterVec {
+	v := &CounterVec{
+		fName:    name,
+		help:     help,
+		label:    label,
+		children: make(map[string]*Counter),
+	}
+	register(v)
+	return v
+}
+
+// With returns the counter for the given label value.
+func (v *CounterVec) With(value string) *Counter {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, ok := v.children[value]
+	if !ok {
+		c = &Counter{}
+		v.children[value] = c
+	}
+	return c
+}
+
+func (v *CounterVec) name() string { return v.fName }
+
+func (v *CounterVec) write(w *bufio.Writer) {
+	v.mu.Lock()
+	values := make(map[string]uint64, len(v.children))
+	for key, c := range v.children {
+		values[key] = c.Value()
+	}
+	v.mu.Unlock()
+
+	w.WriteString(header(v.fName, v.help, "counter"))
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s{%s} %d\n", v.fName, labelPair(v.label, key), values[key])
+	}
+}
+
+// Histogram counts observations into cumulative buckets and keeps their sum.
+// Observations are a mutex away from each other, which is the right trade at
+// the rates lio records them (a search, a transaction — never a per-frame
+// figure).
+type Histogram struct {
+	bounds []float64
+
+	mu     sync.Mutex
+	counts []uint64 // per bound, non-cumulative; the +Inf bucket is count
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+// Observe records one value.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.mu.Lock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+// Since records the seconds elapsed since start.
+func (h *Histogram) Since(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// write renders the histogram's series. labels is the rendered label pair of
+// a HistogramVec child, or empty for an unlabelled histogram.
+func (h *Histogram) write(w *bufio.Writer, name, labels string) {
+	h.mu.Lock()
+	counts := append([]uint64(nil), h.counts...)
+	sum, count := h.sum, h.count
+	h.mu.Unlock()
+
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += counts[i]
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, sep, formatFloat(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, count)
+
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatFloat(sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, count)
+}
+
+// histogramFamily is an unlabelled histogram.
+type histogramFamily struct {
+	*Histogram
+	fName, help string
+}
+
+// NewHistogram declares an unlabelled histogram with the given ascending
+// bucket bounds.
+func NewHistogram(name, help string, bounds []float64) *Histogram {
+	f := &histogramFamily{Histogram: newHistogram(bounds), fName: name, help: help}
+	register(f)
+	return f.Histogram
+}
+
+func (f *histogramFamily) name() string { return f.fName }
+
+func (f *histogramFamily) write(w *bufio.Writer) {
+	w.WriteString(header(f.fName, f.help, "histogram"))
+	f.Histogram.write(w, f.fName, "")
+}
+
+// HistogramVec is a histogram partitioned by one label.
+type HistogramVec struct {
+	fName, help, label string
+	bounds             []float64
+
+	mu       sync.Mutex
+	children map[string]*Histogram
+}
+
+// NewHistogramVec declares a histogram with one label.
+func NewHistogramVec(name, help, label string, bounds []float64) *HistogramVec {
+	v := &HistogramVec{
+		fName:    name,
+		help:     help,
+		label:    label,
+		bounds:   bounds,
+		children: make(map[string]*Histogram),
+	}
+	register(v)
+	return v
+}
+
+// With returns the histogram for the given label value.
+func (v *HistogramVec) With(value string) *Histogram {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	h, ok := v.children[value]
+	if !ok {
+		h = newHistogram(v.bounds)
+		v.children[value] = h
+	}
+	return h
+}
+
+func (v *HistogramVec) name() string { return v.fName }
+
+func (v *HistogramVec) write(w *bufio.Writer) {
+	v.mu.Lock()
+	children := make(map[string]*Histogram, len(v.children))
+	for key, h := range v.children {
+		children[key] = h
+	}
+	v.mu.Unlock()
+
+	w.WriteString(header(v.fName, v.help, "histogram"))
+	for _, key := range sortedKeys(children) {
+		children[key].write(w, v.fName, labelPair(v.label, key))
+	}
+}
+
+// header renders a family's HELP and TYPE lines.
+func header(name, help, typ string) string {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	return fmt.Sprintf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// labelPair renders name="value" with the value escaped per the exposition
+// format.
+func labelPair(name, value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return name + `="` + value + `"`
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
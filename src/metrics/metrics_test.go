@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// scrape renders the registry and returns it, failing the test on a write
+// error.
+func scrape(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return buf.String()
+}
+
+func wantLines(t *testing.T, out string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing line %q in:\n%s", line, out)
+		}
+	}
+}
+
+func TestCounterExposition(t *testing.T) {
+	c := NewCounter("test_plain_total", "Plain events.")
+	c.Inc()
+	c.Add(2)
+
+	wantLines(t, scrape(t),
+		"# HELP test_plain_total Plain events.",
+		"# TYPE test_plain_total counter",
+		"test_plain_total 3",
+	)
+}
+
+func TestCounterVecExposition(t *testing.T) {
+	v := NewCounterVec("test_vec_total", "Labelled events.", "kind")
+	v.With("b").Inc()
+	v.With("a").Add(4)
+	v.With(`q"uote`).Inc()
+
+	out := scrape(t)
+	wantLines(t, out,
+		`test_vec_total{kind="a"} 4`,
+		`test_vec_total{kind="b"} 1`,
+		`test_vec_total{kind="q\"uote"} 1`,
+	)
+	if strings.Index(out, `kind="a"`) > strings.Index(out, `kind="b"`) {
+		t.Errorf("label values not sorted:\n%s", out)
+	}
+}
+
+func TestHistogramBucketsAreCumulative(t *testing.T) {
+	h := NewHistogram("test_latency_seconds", "Latency.", []float64{.1, 1})
+	h.Observe(.05)
+	h.Observe(.1) // a bound is inclusive
+	h.Observe(.5)
+	h.Observe(3)
+
+	wantLines(t, scrape(t),
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{le="0.1"} 2`,
+		`test_latency_seconds_bucket{le="1"} 3`,
+		`test_latency_seconds_bucket{le="+Inf"} 4`,
+		"test_latency_seconds_sum 3.65",
+		"test_latency_seconds_count 4",
+	)
+}
+
+func TestHistogramVecExposition(t *testing.T) {
+	v := NewHistogramVec("test_wait_seconds", "Waits.", "queue", []float64{1})
+	v.With("move").Observe(.5)
+	v.With("draw").Observe(2)
+
+	wantLines(t, scrape(t),
+		`test_wait_seconds_bucket{queue="draw",le="1"} 0`,
+		`test_wait_seconds_bucket{queue="draw",le="+Inf"} 1`,
+		`test_wait_seconds_bucket{queue="move",le="1"} 1`,
+		`test_wait_seconds_sum{queue="move"} 0.5`,
+		`test_wait_seconds_count{queue="draw"} 1`,
+	)
+}
+
+func TestWriteGaugeVec(t *testing.T) {
+	var buf bytes.Buffer
+	WriteGaugeVec(&buf, "test_rooms", "Rooms.", "state", map[string]float64{
+		"game_ongoing": 3,
+		"game_over":    1,
+	})
+
+	want := "# HELP test_rooms Rooms.\n" +
+		"# TYPE test_rooms gauge\n" +
+		`test_rooms{state="game_ongoing"} 3` + "\n" +
+		`test_rooms{state="game_over"} 1` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDuplicateNamePanics(t *testing.T) {
+	NewCounter("test_dup_total", "First.")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate name did not panic")
+		}
+	}()
+	NewCounter("test_dup_total", "Second.")
+}
@@ -11,11 +11,11 @@
 // sender knows an account, and channel.SendToAccount finds that account's
 // connections wherever they are.
 //
-// Delivery is in-process, which is correct for a deployment of one host (see
-// arch/STATE_PERSISTENCE_SCALING.md). It is also not the only path: every
-// message is a database row first, and every socket connect reads the count, so
-// a frame that never arrives corrects itself the next time the reader opens a
-// page.
+// Delivery walks this process's sockets, and on a deployment of several nodes
+// channel relays the same send to the others, which walk theirs (package
+// cluster). It is also not the only path: every message is a database row
+// first, and every socket connect reads the count, so a frame that never
+// arrives corrects itself the next time the reader opens a page.
 package notify
 
 import (
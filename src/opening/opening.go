@@ -133,6 +133,29 @@ func Names(startingOFEN string) (white, black, matchup string, ok bool) {
 	return formationNames[whiteKey], formationNames[blackKey], matchups[wi][bi], true
 }
 
+// StartOFEN is the inverse of Names: the starting OFEN of the matchup in which
+// White deploys formation white and Black formation black, each keyed from its
+// owner's own perspective (e.g. "nkpp"). It is the position the room assembles
+// from the two deployments, so an archived game that started there matches it
+// byte for byte. ok is false unless both keys are formations.
+func StartOFEN(white, black string) (string, bool) {
+	white, black = strings.ToLower(white), strings.ToLower(black)
+	if _, ok := formationIndex[white]; !ok {
+		return "", false
+	}
+	if _, ok := formationIndex[black]; !ok {
+		return "", false
+	}
+	return reverse(black) + "/4/4/" + strings.ToUpper(white) + " w NCFncf - 0 1", true
+}
+
+// Formations returns the 12 formation keys in canonical order, for callers
+// that list every matchup.
+func Formations() []string {
+	keys := formationKeys // a copy: callers must not reorder the index
+	return keys[:]
+}
+
 // reverse returns s with its bytes reversed. Formation keys are 4 ASCII bytes,
 // so a byte reversal is a rune reversal.
 func reverse(s string) string {
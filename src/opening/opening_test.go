@@ -113,3 +113,28 @@ func TestNamesInvalid(t *testing.T) {
 		}
 	}
 }
+
+// TestStartOFENRoundTrip verifies StartOFEN builds the start every matchup's
+// names resolve back from, in the room's deploy OFEN format, and rejects
+// anything that is not a formation.
+func TestStartOFENRoundTrip(t *testing.T) {
+	for _, w := range Formations() {
+		for _, b := range Formations() {
+			ofen, ok := StartOFEN(w, b)
+			if !ok {
+				t.Fatalf("StartOFEN(%q, %q) not ok", w, b)
+			}
+			white, black, _, ok := Names(ofen)
+			if !ok || white != formationNames[w] || black != formationNames[b] {
+				t.Fatalf("%s resolves to %q vs %q, want %q vs %q", ofen, white, black,
+					formationNames[w], formationNames[b])
+			}
+		}
+	}
+	if ofen, _ := StartOFEN("nkpp", "nkpp"); ofen != "ppkn/4/4/NKPP w NCFncf - 0 1" {
+		t.Fatalf("standard matchup start = %q", ofen)
+	}
+	if _, ok := StartOFEN("kkpp", "nkpp"); ok {
+		t.Fatal("an illegal army resolved to a start")
+	}
+}
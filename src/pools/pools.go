@@ -19,6 +19,21 @@ func init() {
 	// NewCustomRoom only reaches them through the casual toggle.
 	Map[variant.UnlimitedCasual.HTMLName] = variant.UnlimitedCasual
 	Map[variant.UnlimitedCasualDeploy.HTMLName] = variant.UnlimitedCasualDeploy
+
+	// the correspondence variants resolve the same way, but are not rating
+	// pools either: the matchmaking queue pairs players who are here now, and a
+	// correspondence game is created as a seek or a direct challenge instead
+	for _, v := range Correspondence {
+		Map[v.HTMLName] = v
+	}
+}
+
+// Correspondence is the days-per-move offering (see package correspondence),
+// fastest first. Each is its own rating category.
+var Correspondence = []variant.Variant{
+	variant.OneDayCorrespondence,
+	variant.ThreeDayCorrespondence,
+	variant.SevenDayCorrespondence,
 }
 
 // RatingPools is a map of all active competitive octad variants on the site
@@ -122,6 +137,40 @@ func init() {
 			Order:       i,
 		}
 	}
+	// correspondence rates apart from every realtime control — a week to think
+	// says nothing about a blitz game — and sorts after them
+	for i, v := range Correspondence {
+		ratingCategories[v.HTMLName] = RatingCategoryInfo{
+			TimeControl: v.Name,
+			Speed:       v.Group.String(),
+			Order:       len(CreateControls) + i,
+		}
+	}
+	// games against bot accounts rate in a pool of their own, per control like
+	// the human one, and sort after everything else
+	for i, ctrl := range CreateControls {
+		ratingCategories[BotCategory(ctrl.Deploy.HTMLName)] = RatingCategoryInfo{
+			TimeControl: ctrl.Label,
+			Speed:       ctrl.Group.String(),
+			Mode:        "Bot",
+			Order:       len(CreateControls) + len(Correspondence) + i,
+		}
+		ratingCategories[BotCategory(ctrl.Classic.HTMLName)] = RatingCategoryInfo{
+			TimeControl: ctrl.Label,
+			Speed:       ctrl.Group.String(),
+			Mode:        "Classic Bot",
+			Order:       len(CreateControls) + len(Correspondence) + i,
+		}
+	}
+}
+
+// BotCategory is the rating category a game at the given variant plays for
+// when a bot account holds one of its seats. Bots rate apart from the human
+// ladder on both sides of the board: an engine's strength is not a person's,
+// and a player farming a weak bot, or a strong one feeding its losses into the
+// ladder, would move human ratings with games no human played.
+func BotCategory(htmlName string) string {
+	return "bot-" + htmlName
 }
 
 // LookupRatingCategory resolves a rating category (a variant HTMLName) to its
@@ -17,6 +17,7 @@ func TestSpeedFor(t *testing.T) {
 		{"½ + 1", "blitz", "blitz Classic"},
 		{"1 + 2", "rapid", "rapid Classic"},
 		{"∞", "unlimited", "unlimited"},
+		{"3 days", "correspondence", "correspondence"},
 		// a retired pool still reads as something
 		{"9 + 9", "nonesuch", "nonesuch"},
 	}
@@ -26,3 +27,50 @@ func TestSpeedFor(t *testing.T) {
 		}
 	}
 }
+
+// TestCorrespondenceCategories: every correspondence variant is its own rating
+// category, resolvable by HTMLName, and sorts after every realtime control.
+func TestCorrespondenceCategories(t *testing.T) {
+	last := -1
+	for _, v := range Correspondence {
+		if _, ok := Map[v.HTMLName]; !ok {
+			t.Errorf("%s is not resolvable by HTMLName", v.HTMLName)
+		}
+		info, ok := LookupRatingCategory(v.HTMLName)
+		if !ok {
+			t.Fatalf("%s has no rating category", v.HTMLName)
+		}
+		if info.TimeControl != v.Name || info.Speed != "correspondence" {
+			t.Errorf("%s resolves to %+v", v.HTMLName, info)
+		}
+		if info.Order < len(CreateControls) || info.Order <= last {
+			t.Errorf("%s sorts at %d", v.HTMLName, info.Order)
+		}
+		last = info.Order
+	}
+}
+
+// TestBotCategories: every realtime control has a bot pool apart from its human
+// one, labelled as such and sorted after every human pool.
+func TestBotCategories(t *testing.T) {
+	human := len(CreateControls) + len(Correspondence)
+	for _, ctrl := range CreateControls {
+		for _, v := range []string{ctrl.Deploy.HTMLName, ctrl.Classic.HTMLName} {
+			cat := BotCategory(v)
+			if cat == v {
+				t.Fatalf("BotCategory(%q) is the human category", v)
+			}
+			info, ok := LookupRatingCategory(cat)
+			if !ok {
+				t.Errorf("%s has no display info", cat)
+				continue
+			}
+			if info.Mode != "Bot" && info.Mode != "Classic Bot" {
+				t.Errorf("%s mode = %q, want a Bot mode", cat, info.Mode)
+			}
+			if info.Order < human {
+				t.Errorf("%s order = %d, sorts among the human pools", cat, info.Order)
+			}
+		}
+	}
+}
@@ -51,13 +51,20 @@ const (
 	// explainer and its demo board. On by default, and worth turning off once
 	// you know what Octad is.
 	KeyHomeAbout = "home.about"
+
+	// KeyEmailDigest is whether the account is mailed a weekly digest of its
+	// unread notifications (package mail). Off by default: nobody is emailed
+	// anything they did not ask for. The digest query reads this key directly,
+	// so its stored form ("1") is fixed.
+	KeyEmailDigest = "email.digest"
 )
 
 // flags maps each boolean preference to the value a player gets when they have
 // never chosen. It is also the accepted-key set: the write endpoint validates
 // against this map rather than a second list that could drift from it.
 var flags = map[string]bool{
-	KeyHomeAbout: true,
+	KeyHomeAbout:   true,
+	KeyEmailDigest: false,
 }
 
 // Valid reports whether key is a preference this site stores. Anything else is
@@ -94,6 +101,9 @@ func (s Snapshot) Flag(key string) bool {
 // ShowHomeAbout reports whether the home page's "What is Octad?" card renders.
 func (s Snapshot) ShowHomeAbout() bool { return s.Flag(KeyHomeAbout) }
 
+// EmailDigest reports whether the account gets the weekly email digest.
+func (s Snapshot) EmailDigest() bool { return s.Flag(KeyEmailDigest) }
+
 // With returns a copy of s with one preference set. It is how a caller with no
 // database — a component test arranging the Viewer it renders against — states
 // what a player chose, without this package having to expose how the choice is
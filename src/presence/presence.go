@@ -51,6 +51,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dechristopher/lio/channel"
@@ -92,6 +93,21 @@ const (
 	idsTTL = 2 * time.Second
 )
 
+// elsewhere reports the accounts holding a socket on the site's other nodes,
+// for a multi-node deployment (package cluster); unset on a single node.
+//
+// Only the Online answers read it. They gate challenges, and somebody reading
+// the site through another node is just as here. The roster and LastSeen stay
+// this node's: they are built from departures this node watched, and another
+// node's heartbeat carries ids, not histories.
+var elsewhere atomic.Pointer[func() []int64]
+
+// SetElsewhere installs the other nodes' online set. Called once at boot by
+// package cluster.
+func SetElsewhere(fn func() []int64) {
+	elsewhere.Store(&fn)
+}
+
 var idsCache struct {
 	sync.Mutex
 	ids     map[int64]struct{}
@@ -112,6 +128,11 @@ func onlineSet() map[int64]struct{} {
 			seen[acct.ID] = struct{}{}
 		}
 	}
+	if fn := elsewhere.Load(); fn != nil {
+		for _, id := range (*fn)() {
+			seen[id] = struct{}{}
+		}
+	}
 	idsCache.ids = seen
 	idsCache.fetched = time.Now()
 	return seen
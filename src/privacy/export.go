@@ -0,0 +1,352 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+const (
+	// exportPage bounds the games held in memory at once while games.pgn
+	// streams, and the follows read per page.
+	exportPage = 500
+	// exportNotifications is more messages than an account keeps in practice;
+	// the list is read whole rather than paged.
+	exportNotifications = 10000
+)
+
+// readme is the export's README.txt: what each file is, for a reader who
+// opens the archive long after downloading it.
+const readme = `Your lio data, as of %s.
+
+profile.json        your account: username, email, title, role, when it was made
+ratings.json        your current rating in each time control, and its history
+games.pgn           every archived game you played, in the form the archive serves
+follows.json        the accounts you follow, and the accounts that follow you
+blocks.json         the accounts you blocked
+prefs.json          the display preferences you changed from their defaults
+reports.json        the reports you filed, and how they were resolved
+notifications.json  the messages in your notification list
+
+Passwords, second factors, sessions and API tokens are never exported: they
+are secrets, or stand for one, and are no use outside %s.
+`
+
+type exportProfile struct {
+	ID            int64     `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email,omitempty"`
+	EmailVerified bool      `json:"emailVerified"`
+	Title         string    `json:"title,omitempty"`
+	Role          string    `json:"role"`
+	CreatedAt     time.Time `json:"createdAt"`
+	TOTP          bool      `json:"totp"`
+}
+
+type exportRating struct {
+	Category    string        `json:"category"`
+	Rating      float64       `json:"rating"`
+	Deviation   float64       `json:"deviation"`
+	Volatility  float64       `json:"volatility"`
+	Games       int           `json:"games"`
+	Provisional bool          `json:"provisional"`
+	History     []exportPoint `json:"history"`
+}
+
+type exportPoint struct {
+	Day         string `json:"day"`
+	Rating      int    `json:"rating"`
+	Provisional bool   `json:"provisional"`
+}
+
+type exportFollows struct {
+	Following []string `json:"following"`
+	Followers []string `json:"followers"`
+}
+
+type exportBlock struct {
+	Username string    `json:"username"`
+	Since    time.Time `json:"since"`
+}
+
+type exportReport struct {
+	Filed      time.Time  `json:"filed"`
+	Against    string     `json:"against"`
+	Category   string     `json:"category"`
+	Note       string     `json:"note"`
+	Game       string     `json:"game,omitempty"`
+	Resolved   *time.Time `json:"resolved,omitempty"`
+	Resolution string     `json:"resolution,omitempty"`
+}
+
+type exportNotification struct {
+	Created  time.Time  `json:"created"`
+	Kind     string     `json:"kind"`
+	Body     string     `json:"body"`
+	Link     string     `json:"link,omitempty"`
+	From     string     `json:"from,omitempty"`
+	Read     *time.Time `json:"read,omitempty"`
+	Response string     `json:"response,omitempty"`
+}
+
+// Export writes everything lio keeps on an account to w as a zip archive.
+// Every file is built from the same accessor the page that shows it uses, so
+// the export says what the site says. games.pgn streams page by page: it is
+// the one file that grows without bound.
+//
+// An error can arrive after part of the archive has been written, so a caller
+// streaming it into a response can only abandon it, not replace it.
+func Export(ctx context.Context, w io.Writer, userID int64) error {
+	rec, found, err := db.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+	z := zip.NewWriter(w)
+	now := time.Now().UTC()
+
+	if err = writeFile(z, "README.txt", now, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, readme, now.Format(time.RFC3339), config.SiteOrigin())
+		return err
+	}); err != nil {
+		return err
+	}
+
+	profile := exportProfile{
+		ID:            rec.ID,
+		Username:      rec.Username,
+		EmailVerified: rec.EmailVerified,
+		Title:         rec.Title.Name,
+		Role:          rec.Role.String(),
+		CreatedAt:     rec.CreatedAt.UTC(),
+		TOTP:          rec.TOTPConfirmed,
+	}
+	if rec.Email != nil {
+		profile.Email = *rec.Email
+	}
+	if err = writeJSON(z, "profile.json", now, profile); err != nil {
+		return err
+	}
+
+	ratings, err := exportRatings(userID)
+	if err != nil {
+		return err
+	}
+	if err = writeJSON(z, "ratings.json", now, ratings); err != nil {
+		return err
+	}
+
+	if err = writeFile(z, "games.pgn", now, func(w io.Writer) error {
+		return exportGames(ctx, w, userID)
+	}); err != nil {
+		return err
+	}
+
+	follows, err := exportFollowLists(userID)
+	if err != nil {
+		return err
+	}
+	if err = writeJSON(z, "follows.json", now, follows); err != nil {
+		return err
+	}
+
+	blocks, err := db.ListBlocks(userID)
+	if err != nil {
+		return err
+	}
+	blocked := make([]exportBlock, 0, len(blocks))
+	for _, b := range blocks {
+		blocked = append(blocked, exportBlock{Username: b.Username, Since: b.Since.UTC()})
+	}
+	if err = writeJSON(z, "blocks.json", now, blocked); err != nil {
+		return err
+	}
+
+	stored, err := db.LoadUserPrefs(userID)
+	if err != nil {
+		return err
+	}
+	if err = writeJSON(z, "prefs.json", now, stored); err != nil {
+		return err
+	}
+
+	reports, err := db.ReportsFiledBy(userID)
+	if err != nil {
+		return err
+	}
+	filed := make([]exportReport, 0, len(reports))
+	for _, r := range reports {
+		e := exportReport{
+			Filed:      r.Created.UTC(),
+			Against:    r.Target,
+			Category:   r.Category,
+			Note:       r.Note,
+			Game:       r.GameID,
+			Resolution: r.Resolution,
+		}
+		if !r.Resolved.IsZero() {
+			at := r.Resolved.UTC()
+			e.Resolved = &at
+		}
+		filed = append(filed, e)
+	}
+	if err = writeJSON(z, "reports.json", now, filed); err != nil {
+		return err
+	}
+
+	notes, err := db.ListNotifications(userID, exportNotifications)
+	if err != nil {
+		return err
+	}
+	inbox := make([]exportNotification, 0, len(notes))
+	for _, n := range notes {
+		e := exportNotification{
+			Created:  n.Created.UTC(),
+			Kind:     n.Kind,
+			Body:     n.Body,
+			Link:     n.Link,
+			From:     n.Actor,
+			Response: n.Response,
+		}
+		if !n.Read.IsZero() {
+			at := n.Read.UTC()
+			e.Read = &at
+		}
+		inbox = append(inbox, e)
+	}
+	if err = writeJSON(z, "notifications.json", now, inbox); err != nil {
+		return err
+	}
+
+	return z.Close()
+}
+
+// exportRatings joins the current ratings with their history. A category with
+// history but no current row (one since retired) still gets its history.
+func exportRatings(userID int64) ([]exportRating, error) {
+	current, err := db.ListRatingsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := db.RatingHistoryForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]exportRating, 0, len(current))
+	at := make(map[string]int, len(current))
+	for _, c := range current {
+		at[c.Category] = len(out)
+		out = append(out, exportRating{
+			Category:    c.Category,
+			Rating:      c.Rating.R,
+			Deviation:   c.Rating.RD,
+			Volatility:  c.Rating.Sigma,
+			Games:       c.Rating.Games,
+			Provisional: c.Rating.Provisional(),
+			History:     []exportPoint{},
+		})
+	}
+	for _, s := range history {
+		i, ok := at[s.Category]
+		if !ok {
+			i = len(out)
+			out = append(out, exportRating{Category: s.Category})
+		}
+		points := make([]exportPoint, 0, len(s.Points))
+		for _, p := range s.Points {
+			points = append(points, exportPoint{
+				Day:         p.Day.Format(time.DateOnly),
+				Rating:      p.Rating,
+				Provisional: p.Provisional,
+			})
+		}
+		out[i].History = points
+	}
+	return out, nil
+}
+
+// exportGames streams every game the account played as PGN, oldest first. A
+// game that no longer replays is logged and left out, as the dumps do.
+func exportGames(ctx context.Context, w io.Writer, userID int64) error {
+	var after int32
+	for {
+		rows, err := db.AccountGamesPage(ctx, userID, after, exportPage)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			after = row.ID
+			replayed, err := game.ReplayArchive(row.StartingOfen, row.Moves)
+			if err != nil {
+				util.Error(str.CPriv, "export replay failed game=%s: %s",
+					row.GameID.String(), err.Error())
+				continue
+			}
+			if _, err := io.WriteString(w, db.ArchivedPGN(row, replayed)+"\n\n"); err != nil {
+				return err
+			}
+		}
+		if len(rows) < exportPage {
+			return nil
+		}
+	}
+}
+
+// exportFollowLists reads both follow lists whole, page by page.
+func exportFollowLists(userID int64) (exportFollows, error) {
+	out := exportFollows{Following: []string{}, Followers: []string{}}
+	for _, list := range []struct {
+		read func(int64, int32, int32) ([]db.FollowMember, error)
+		into *[]string
+	}{
+		{db.ListFollowing, &out.Following},
+		{db.ListFollowers, &out.Followers},
+	} {
+		for offset := int32(0); ; offset += exportPage {
+			page, err := list.read(userID, exportPage, offset)
+			if err != nil {
+				return out, err
+			}
+			for _, m := range page {
+				*list.into = append(*list.into, m.Username)
+			}
+			if len(page) < exportPage {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// writeFile adds one file to the archive, written by fill.
+func writeFile(z *zip.Writer, name string, modified time.Time, fill func(io.Writer) error) error {
+	w, err := z.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	return fill(w)
+}
+
+// writeJSON adds one indented JSON file to the archive.
+func writeJSON(z *zip.Writer, name string, modified time.Time, v any) error {
+	return writeFile(z, name, modified, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	})
+}
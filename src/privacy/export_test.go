@@ -0,0 +1,77 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+// Each file lands in the archive under its own name, compressed, and a JSON
+// file reads back as what was written.
+func TestWriteJSONRoundTrips(t *testing.T) {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := writeJSON(z, "prefs.json", at, map[string]string{"theme": "dark"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(z, "games.pgn", at, func(w io.Writer) error {
+		_, err := io.WriteString(w, "[Event \"Casual\"]\n\n")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.File) != 2 || r.File[0].Name != "prefs.json" || r.File[1].Name != "games.pgn" {
+		t.Fatalf("archive holds %d files", len(r.File))
+	}
+	f := r.File[0]
+	if f.Method != zip.Deflate || !f.Modified.Equal(at) {
+		t.Fatalf("prefs.json method=%d modified=%s", f.Method, f.Modified)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rc.Close() }()
+	var got map[string]string
+	if err := json.NewDecoder(rc).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["theme"] != "dark" {
+		t.Fatalf("prefs.json = %v", got)
+	}
+}
+
+// An account with nothing in a list exports an empty array, not null: a
+// reader should not have to tell "none" from "missing".
+func TestEmptyListsExportAsArrays(t *testing.T) {
+	follows, err := exportFollowLists(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(follows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != `{"following":[],"followers":[]}` {
+		t.Fatalf("follows.json = %s", raw)
+	}
+	ratings, err := exportRatings(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := json.Marshal(ratings); string(raw) != "[]" {
+		t.Fatalf("ratings.json = %s", raw)
+	}
+}
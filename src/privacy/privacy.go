@@ -0,0 +1,168 @@
+// Package privacy is what a player can do about the data lio keeps on them:
+// take a copy of all of it (Export), or have the account deleted (Delete).
+//
+// Deletion removes the person, not the record of play. The users row goes, and
+// with it everything that was only the account's; the archived games stay,
+// with the player's seat reading "Anonymous" exactly as a logged-out player's
+// always has (db/migrations/00036_account_deletion.sql). Everything that serves
+// a game's PGN rebuilds it from the row through db.ArchivedPGN, so the archive
+// stays reproducible; the two copies that were written out ahead of time — the
+// game's stored PGN object and the monthly dumps — are rewritten to match.
+//
+// The re-authentication in front of both lives with the rest of the account
+// API (www/handlers/api/account); this package trusts its caller to have done
+// it.
+package privacy
+
+import (
+	"errors"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/prefs"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/store"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/tournament"
+	"github.com/dechristopher/lio/util"
+)
+
+// rewriteBatch bounds the archived rows held in memory at once while a deleted
+// account's stored PGN objects are rewritten.
+const rewriteBatch = 200
+
+var (
+	// ErrPlaying refuses a deletion while the account holds a seat in a room:
+	// the game would archive against an account that no longer exists.
+	ErrPlaying = errors.New("finish or leave your current game before deleting your account")
+	// ErrCorrespondence refuses a deletion while a correspondence game is still
+	// running, for the same reason over days instead of minutes.
+	ErrCorrespondence = errors.New("finish or resign your correspondence games before deleting your account")
+	// ErrTournament refuses a deletion while the account is on the roster of an
+	// event that has not finished. A withdrawn player still counts: their
+	// results stay in the standings until the event ends.
+	ErrTournament = errors.New("wait for your tournaments to finish before deleting your account")
+	// ErrBanned refuses a deletion while a ban is in force: deleting the
+	// account would free its username and email to register again, clean.
+	ErrBanned = errors.New("your account cannot be deleted while it is suspended")
+	// ErrReported refuses a deletion while a report against the account is
+	// still open, so leaving is not a way to end a moderator's review of it.
+	ErrReported = errors.New("your account cannot be deleted while a report about it is under review")
+	// ErrNotFound is a deletion of an account that is already gone.
+	ErrNotFound = errors.New("that account no longer exists")
+)
+
+// Deletable reports why an account cannot be deleted right now, or nil if it
+// can. Every reason is something in flight that still expects the account to
+// exist when it finishes: a sanction or a review the account must not walk
+// away from, or a game that would archive against it. Each clears by itself,
+// by a moderator, or by the player's own hand.
+func Deletable(userID int64) error {
+	ban, err := db.BanStateOf(userID)
+	if err != nil {
+		return err
+	}
+	if ban.Banned {
+		return ErrBanned
+	}
+	reports, err := db.OpenReportsAgainst(userID)
+	if err != nil {
+		return err
+	}
+	if reports > 0 {
+		return ErrReported
+	}
+	if room.AccountBusy(userID) {
+		return ErrPlaying
+	}
+	n, err := db.ActiveCorrespondenceCount(userID)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrCorrespondence
+	}
+	for _, s := range tournament.List() {
+		if s.Status == tournament.Finished {
+			continue
+		}
+		if t := tournament.Get(s.ID); t != nil {
+			if entered, _ := t.Entry(userID); entered {
+				return ErrTournament
+			}
+		}
+	}
+	return nil
+}
+
+// Delete deletes an account, once Deletable allows it. The audit log records
+// the deletion under the username it had, since nothing else will.
+//
+// The account's sessions go with its row; dropping the cached ones makes that
+// immediate on every device rather than within the cache's lifetime. The
+// stored PGN objects are rewritten in the background: the account is already
+// gone from every page, and a long history is a long job.
+func Delete(userID int64, username string) error {
+	if err := Deletable(userID); err != nil {
+		return err
+	}
+	// one fresh uid, in the session uid shape, stands in for every uid the
+	// account played under
+	anon := config.GenerateCode(16, config.Base58)
+	games, found, err := db.DeleteAccount(userID, anon, map[string]any{"username": username})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+	auth.DropUserSessions(userID)
+	prefs.Invalidate(userID)
+	util.Info(str.CPriv, "account deleted user=%d games=%d", userID, len(games))
+
+	go rewriteArchive(userID, games)
+	return nil
+}
+
+// rewriteArchive replaces the stored PGN object of each game the deleted
+// account played with the rebuild from its now anonymised row, so the object
+// says what the archive page serves. Failures are logged and skipped: the row,
+// which every reader prefers, is already right.
+func rewriteArchive(userID int64, ids []int32) {
+	defer func() {
+		if r := recover(); r != nil {
+			util.Error(str.CPriv, "archive rewrite panic user=%d: %v", userID, r)
+		}
+	}()
+	if !store.Configured() {
+		return
+	}
+	failed := 0
+	for start := 0; start < len(ids); start += rewriteBatch {
+		end := min(start+rewriteBatch, len(ids))
+		rows, err := db.GamesByID(ids[start:end])
+		if err != nil {
+			util.Error(str.CPriv, "archive rewrite read failed user=%d: %s", userID, err.Error())
+			failed += end - start
+			continue
+		}
+		for _, row := range rows {
+			if row.PgnObjectKey == "" {
+				continue
+			}
+			replayed, err := game.ReplayArchive(row.StartingOfen, row.Moves)
+			if err != nil {
+				util.Error(str.CPriv, "archive rewrite replay failed game=%s: %s",
+					row.GameID.String(), err.Error())
+				failed++
+				continue
+			}
+			if err := store.PGNBucket.PutObject(row.PgnObjectKey, []byte(db.ArchivedPGN(row, replayed))); err != nil {
+				failed++
+			}
+		}
+	}
+	util.Info(str.CPriv, "archive rewritten user=%d games=%d failed=%d", userID, len(ids), failed)
+}
@@ -0,0 +1,175 @@
+package puzzle
+
+import (
+	"errors"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/learn"
+	"github.com/dechristopher/lio/rng"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// servePool is how many of the nearest-rated unseen puzzles Next picks among,
+// so two players at the same rating are not handed the same sequence.
+const servePool = 20
+
+// Request is one move at a puzzle. Step is how many of the solver's moves have
+// already been played; the position is rebuilt from the puzzle and its
+// solution, never taken from the client, so the only way to reach a later step
+// is to have played the earlier ones. A request with no move describes the
+// step's position, for a client resyncing after a failed request.
+type Request struct {
+	Puzzle int64  `json:"puzzle"`
+	Step   int    `json:"step"`
+	UOI    string `json:"uoi"`
+}
+
+// Response is the judge's answer, in the tutorial's shape (see learn.Response)
+// so both boards render moves with the same code.
+type Response struct {
+	// Move is the solver's move as applied; Reply is the answer to it.
+	Move  *learn.Move `json:"mv,omitempty"`
+	Reply *learn.Move `json:"rp,omitempty"`
+	// OFEN, Dests, Turn and Check describe the position the solver now faces.
+	OFEN  string              `json:"o"`
+	Dests map[string][]string `json:"v"`
+	Turn  string              `json:"t"`
+	Check bool                `json:"k,omitempty"`
+
+	// Solved and Failed end the puzzle. Either way the whole solution is
+	// revealed, with the puzzle's rating.
+	Solved       bool     `json:"done,omitempty"`
+	Failed       bool     `json:"failed,omitempty"`
+	Solution     []string `json:"sol,omitempty"`
+	PuzzleRating string   `json:"pr,omitempty"`
+	// Rating and Delta are the solver's new puzzle rating and its change, on a
+	// signed-in player's first attempt — the only rated one.
+	Rating string `json:"r,omitempty"`
+	Delta  *int   `json:"d,omitempty"`
+}
+
+// ErrBadRequest marks a request the caller should reject with a 4xx.
+var ErrBadRequest = errors.New("puzzle: bad request")
+
+// ErrNotFound marks a request for a puzzle that does not exist.
+var ErrNotFound = errors.New("puzzle: not found")
+
+// Next picks a puzzle for a player at rating r that they have not attempted,
+// or nil when there is none. userID 0 is an anonymous player.
+func Next(userID int64, r float64) (*db.Puzzle, error) {
+	near, err := db.PuzzlesNear(userID, r, servePool)
+	if err != nil || len(near) == 0 {
+		return nil, err
+	}
+	p := near[rng.Intn(len(near))]
+	return &p, nil
+}
+
+// Start describes a puzzle's opening position, as the trainer first shows it.
+func Start(p db.Puzzle) (*Response, error) {
+	return judge(p, Request{Puzzle: p.ID})
+}
+
+// Do judges one move at a puzzle for the given player (0 when anonymous),
+// recording the attempt once it ends.
+func Do(req Request, userID int64) (*Response, error) {
+	p, err := db.GetPuzzle(req.Puzzle)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrNotFound
+	}
+	resp, err := judge(*p, req)
+	if err != nil {
+		return nil, err
+	}
+	if userID != 0 && (resp.Solved || resp.Failed) {
+		res, err := db.RecordPuzzleAttempt(userID, p.ID, resp.Solved)
+		if err != nil {
+			// the verdict stands either way; only the rating is lost
+			util.Error(str.CPuzl, "puzzle attempt record failed puzzle=%d error=%s", p.ID, err.Error())
+		} else if res != nil {
+			resp.Rating, resp.Delta = res.Display, &res.Delta
+		}
+	}
+	return resp, nil
+}
+
+// judge applies a move to the puzzle's position at the request's step.
+func judge(p db.Puzzle, req Request) (*Response, error) {
+	if req.Step < 0 || 2*req.Step >= len(p.Solution) {
+		return nil, ErrBadRequest
+	}
+	g, err := stepGame(p, req.Step)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{}
+	if req.UOI == "" {
+		describe(resp, g)
+		return resp, nil
+	}
+
+	move, mv := learn.Play(g, req.UOI)
+	if move == nil {
+		return nil, ErrBadRequest
+	}
+	resp.Move = move
+
+	expected := p.Solution[2*req.Step]
+	mated := g.Outcome() != octad.NoOutcome && g.Method() == octad.Checkmate
+	switch {
+	case mated:
+		// any mate solves a puzzle, whichever line it was mined with
+		resp.Solved = true
+	case mv.String() != expected:
+		resp.Failed = true
+	case 2*req.Step+1 == len(p.Solution):
+		resp.Solved = true
+	default:
+		resp.Reply, _ = learn.Play(g, p.Solution[2*req.Step+1])
+	}
+
+	describe(resp, g)
+	if resp.Solved || resp.Failed {
+		resp.Dests = map[string][]string{}
+		resp.Solution = p.Solution
+		resp.PuzzleRating = p.Rating.Display()
+	}
+	return resp, nil
+}
+
+// stepGame rebuilds the position a puzzle is at after step solver moves.
+func stepGame(p db.Puzzle, step int) (*octad.Game, error) {
+	pos, err := octad.OFEN(p.OFEN)
+	if err != nil {
+		return nil, err
+	}
+	g, err := octad.NewGame(pos)
+	if err != nil {
+		return nil, err
+	}
+	for _, uoi := range p.Solution[:2*step] {
+		if move, _ := learn.Play(g, uoi); move == nil {
+			return nil, ErrBadRequest
+		}
+	}
+	return g, nil
+}
+
+// describe fills the response's view of the position the solver faces.
+func describe(resp *Response, g *octad.Game) {
+	pos := g.Position()
+	resp.OFEN = pos.String()
+	resp.Check = pos.InCheck()
+	resp.Turn = "white"
+	if pos.Turn() == octad.Black {
+		resp.Turn = "black"
+	}
+	resp.Dests = learn.LegalDests(g)
+}
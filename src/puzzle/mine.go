@@ -0,0 +1,70 @@
+package puzzle
+
+import (
+	"strings"
+
+	"github.com/dechristopher/lio/db"
+)
+
+const (
+	// swingCp is how far, in centipawns, one move must swing the eval toward
+	// the side it hands the move to before the position is worth a look.
+	swingCp = 300
+	// decisiveCp is the eval, from the solver's side, a candidate must reach:
+	// a position that is merely better is not one with a solution.
+	decisiveCp = 300
+	// mateCp is the cached eval of a forced mate: the evaluator saturates mate
+	// scores at the column's cap (db's evalCap).
+	mateCp = 32000
+)
+
+// Candidate is a position worth validating: the side to move has just been
+// handed a win.
+type Candidate struct {
+	GameRef    int32
+	PositionID int32
+	OFEN       string
+	// LastMove is the opponent's move that led here (UOI).
+	LastMove string
+}
+
+// Candidates scans one fully evaluated game for positions where the last move
+// turned the game: the side now to move gained at least swingCp and is now
+// winning by decisiveCp or more, having not been winning before — or has just
+// been walked into a forced mate. The first ply has no evaluated position
+// before it, so it is never a candidate. Plies without an eval are skipped;
+// the miner only hands over games the evaluator has finished.
+func Candidates(gameRef int32, plies []db.PuzzlePly) []Candidate {
+	var out []Candidate
+	for i := 1; i < len(plies); i++ {
+		prev, cur := plies[i-1], plies[i]
+		if prev.EvalCp == nil || cur.EvalCp == nil {
+			continue
+		}
+		sign := solverSign(cur.OFEN)
+		before := sign * int(*prev.EvalCp)
+		after := sign * int(*cur.EvalCp)
+
+		swing := after-before >= swingCp && after >= decisiveCp && before < decisiveCp
+		mate := after >= mateCp && before < mateCp
+		if !swing && !mate {
+			continue
+		}
+		out = append(out, Candidate{
+			GameRef:    gameRef,
+			PositionID: cur.PositionID,
+			OFEN:       cur.OFEN,
+			LastMove:   cur.Move,
+		})
+	}
+	return out
+}
+
+// solverSign is +1 when white is to move in ofen and -1 when black is, which
+// turns a white-positive eval into the side to move's.
+func solverSign(ofen string) int {
+	if fields := strings.Fields(ofen); len(fields) > 1 && fields[1] == "b" {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,134 @@
+package puzzle
+
+import (
+	"time"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/rating"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// The miner walks the archive in game order behind the background evaluator.
+// It is opt-in (the lio_pg_puzzles secret/env = "1") for the same reason the
+// evaluator is: validation is real engine time, and a box under game load
+// should only spend it when asked. It depends on the evaluator's evals, so it
+// does nothing useful unless lio_pg_evaluator is on as well.
+const (
+	mineTick  = time.Minute // batch cadence
+	mineBatch = 4           // games per tick (each candidate is a dozen searches)
+)
+
+// cursor is the last game the miner has finished with. It lives in memory and
+// resumes from the newest game a puzzle came from, so a restart rescans at
+// most the games mined since then; a position that is already a puzzle is
+// never validated twice.
+var cursor int32
+
+// Up starts the miner loop when Postgres is configured and mining is enabled.
+// No-op otherwise.
+func Up() {
+	if db.Pool == nil || config.ReadSecretFallback("lio_pg_puzzles") != "1" {
+		return
+	}
+	latest, err := db.LatestPuzzleGame()
+	if err != nil {
+		util.Error(str.CPuzl, "puzzle miner cursor load failed: %s", err.Error())
+		return
+	}
+	cursor = latest
+	go func() {
+		ticker := time.NewTicker(mineTick)
+		defer ticker.Stop()
+		for range ticker.C {
+			safeMine()
+		}
+	}()
+	util.Debug(str.CPuzl, "puzzle miner online from game %d", cursor)
+}
+
+// safeMine runs one batch, converting a panic into an error log: like the
+// evaluator, nothing depends on the miner, so nothing it trips over should be
+// allowed to take the process down with it.
+func safeMine() {
+	defer func() {
+		if r := recover(); r != nil {
+			util.Error(str.CPuzl, "puzzle miner batch panicked: %v", r)
+		}
+	}()
+	mineOnce()
+}
+
+// mineOnce scans up to mineBatch games past the cursor. It stops at the first
+// game the evaluator has not finished, without advancing past it: the swings
+// are read off cached evals, and a half-evaluated game would be mined as if
+// its gaps were quiet.
+func mineOnce() {
+	games, err := db.GamesAfter(cursor, mineBatch)
+	if err != nil {
+		util.Error(str.CPuzl, "puzzle miner list failed: %s", err.Error())
+		return
+	}
+	for _, ref := range games {
+		plies, err := db.GamePlies(ref)
+		if err != nil {
+			util.Error(str.CPuzl, "puzzle miner read failed game=%d: %s", ref, err.Error())
+			return
+		}
+		if !evaluated(plies) {
+			return
+		}
+		for _, c := range Candidates(ref, plies) {
+			mine(c)
+		}
+		cursor = ref
+	}
+}
+
+// mine validates one candidate and stores it if it holds up.
+func mine(c Candidate) {
+	seen, err := db.PuzzleAt(c.PositionID)
+	if err != nil || seen {
+		return
+	}
+	line, theme, ok := Solve(c.OFEN)
+	if !ok {
+		return
+	}
+	ref := c.GameRef
+	err = db.SavePuzzle(db.Puzzle{
+		PositionID: c.PositionID,
+		GameRef:    &ref,
+		OFEN:       c.OFEN,
+		LastMove:   c.LastMove,
+		Solution:   line,
+		Theme:      theme,
+		Rating:     seedRating(line),
+	})
+	if err != nil {
+		util.Error(str.CPuzl, "puzzle save failed position=%d: %s", c.PositionID, err.Error())
+		return
+	}
+	util.DebugFlag("puzzle", str.CPuzl, "mined a %s puzzle from game %d (%d moves)", theme, c.GameRef, len(line)/2+1)
+}
+
+// seedRating is a new puzzle's starting rating: a longer line is a harder
+// puzzle, and starting it higher gets it to the right players sooner. The
+// deviation stays at the unrated default, so the first few attempts still
+// move it a long way.
+func seedRating(line []string) rating.Rating {
+	r := rating.New()
+	r.R = 1300 + 200*float64(len(line)/2)
+	return r
+}
+
+// evaluated reports whether every ply of a game has a cached eval.
+func evaluated(plies []db.PuzzlePly) bool {
+	for _, p := range plies {
+		if p.EvalCp == nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+// Package puzzle is the training puzzle pipeline: puzzles mined from archived
+// games, validated by the engine, rated, and served at a player's own level on
+// the /training page.
+//
+// A puzzle is a moment a game turned. The background evaluator has already
+// scored every distinct position the archive holds; the miner (miner.go) walks
+// each game's plies looking for a move that handed the opponent a decisive
+// advantage they did not have before — a blunder, or a step into a forced
+// mate — and takes the position it left behind as a candidate (Candidates).
+//
+// A candidate is kept only if it has one answer. Solve searches every legal
+// move from it and requires exactly one to stay decisive; it then follows the
+// engine's best defence for as long as the solver's move stays unique, so a
+// puzzle is a short forced line that always ends on the solver's move. A
+// position with two good answers is not a puzzle, it is a quiz with a wrong
+// answer key, and is thrown away.
+//
+// Every puzzle carries its own Glicko-2 rating. A signed-in player's first
+// attempt at one is a rated game between the two of them, so puzzles that fool
+// people climb and puzzles that do not sink, and the trainer serves each player
+// puzzles from near their own puzzle rating (Next). Moves round-trip through
+// the stateless judge (Do) the way the tutorial's do through learn.Do.
+package puzzle
+
+const (
+	// ThemeMate is a puzzle whose solution delivers checkmate.
+	ThemeMate = "mate"
+	// ThemeAdvantage is a puzzle whose solution wins decisively short of mate.
+	ThemeAdvantage = "advantage"
+)
@@ -0,0 +1,167 @@
+package puzzle
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/dechristopher/lio/bus"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/rating"
+)
+
+// TestMain brings the in-process event bus up: Solve searches through the
+// full engine.Search path, whose engine-channel Publish spin-waits forever
+// without it (the established bus.Up test gotcha).
+func TestMain(m *testing.M) {
+	bus.Up()
+	os.Exit(m.Run())
+}
+
+// mateInOne is black king a4 against king b2 and queen d1: Qd4 and Qb3 both
+// mate, while Qa1+ lets the king out through b4.
+const mateInOne = "k3/4/1K2/3Q w - - 0 1"
+
+func cp(v int16) *int16 { return &v }
+
+func TestCandidatesFindsTheTurningMove(t *testing.T) {
+	plies := []db.PuzzlePly{
+		{Ply: 1, Move: "a1a2", PositionID: 1, OFEN: "x b", EvalCp: cp(20)},
+		{Ply: 2, Move: "a4a3", PositionID: 2, OFEN: "x w", EvalCp: cp(10)},
+		// black hands white 450cp: the position after it is the puzzle
+		{Ply: 3, Move: "b1b2", PositionID: 3, OFEN: "x b", EvalCp: cp(-30)},
+		{Ply: 4, Move: "c4c3", PositionID: 4, OFEN: "x w", EvalCp: cp(420)},
+		// white stays winning: not a new turning point
+		{Ply: 5, Move: "d1d2", PositionID: 5, OFEN: "x b", EvalCp: cp(500)},
+		{Ply: 6, Move: "a3a2", PositionID: 6, OFEN: "x w", EvalCp: cp(900)},
+		// but walking into a forced mate always is one
+		{Ply: 7, Move: "d2d3", PositionID: 7, OFEN: "x b", EvalCp: cp(600)},
+		{Ply: 8, Move: "a2a1", PositionID: 8, OFEN: "x w", EvalCp: cp(mateCp)},
+	}
+	got := Candidates(9, plies)
+	if len(got) != 2 {
+		t.Fatalf("candidates = %+v, want two", got)
+	}
+	if got[0].PositionID != 4 || got[0].LastMove != "c4c3" || got[0].GameRef != 9 {
+		t.Errorf("first candidate = %+v, want position 4 after c4c3", got[0])
+	}
+	if got[1].PositionID != 8 {
+		t.Errorf("second candidate = %+v, want the forced mate at position 8", got[1])
+	}
+}
+
+func TestCandidatesReadsTheSideToMove(t *testing.T) {
+	// a swing toward black, with black to move
+	plies := []db.PuzzlePly{
+		{Ply: 1, PositionID: 1, OFEN: "x b", EvalCp: cp(0)},
+		{Ply: 2, PositionID: 2, OFEN: "x w", EvalCp: cp(40)},
+		{Ply: 3, PositionID: 3, OFEN: "x b", EvalCp: cp(-350)},
+	}
+	got := Candidates(1, plies)
+	if len(got) != 1 || got[0].PositionID != 3 {
+		t.Fatalf("candidates = %+v, want position 3", got)
+	}
+	// the same swing toward the side that just moved is nothing
+	plies[2].OFEN = "x w"
+	if got := Candidates(1, plies); len(got) != 0 {
+		t.Fatalf("candidates = %+v, want none", got)
+	}
+}
+
+func TestSolveMateInOne(t *testing.T) {
+	line, theme, ok := Solve(mateInOne)
+	if !ok {
+		t.Fatal("mate in one rejected")
+	}
+	// either mate will do: two mates in one are not ambiguity (see Solve)
+	if theme != ThemeMate || len(line) != 1 || (line[0] != "d1d4" && line[0] != "d1b3") {
+		t.Fatalf("Solve = %v %s, want a single mating move", line, theme)
+	}
+}
+
+func TestSolveRejectsAQuietPosition(t *testing.T) {
+	if line, _, ok := Solve("ppkn/4/4/NKPP w NCFncf - 0 1"); ok {
+		t.Fatalf("the starting position solved as %v", line)
+	}
+}
+
+// line is a hand-built three-move puzzle on the mate-in-one board: the judge
+// takes the solution as given, so it need not be the engine's.
+func line() db.Puzzle {
+	return db.Puzzle{
+		ID:       1,
+		OFEN:     mateInOne,
+		Solution: []string{"d1c1", "a4b4", "c1c4"},
+		Theme:    ThemeAdvantage,
+		Rating:   rating.New(),
+	}
+}
+
+func TestJudgeWalksTheLine(t *testing.T) {
+	p := line()
+	resp, err := judge(p, Request{Puzzle: 1, Step: 0, UOI: "d1c1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Solved || resp.Failed || resp.Reply == nil || resp.Reply.UOI != "a4b4" {
+		t.Fatalf("first move: %+v, want the reply a4b4", resp)
+	}
+	if resp.Turn != "white" || len(resp.Dests) == 0 {
+		t.Fatalf("first move left %s to move with %v", resp.Turn, resp.Dests)
+	}
+
+	resp, err = judge(p, Request{Puzzle: 1, Step: 1, UOI: "c1c4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Solved || !reflect.DeepEqual(resp.Solution, p.Solution) || resp.PuzzleRating == "" {
+		t.Fatalf("last move: %+v, want solved with the solution revealed", resp)
+	}
+}
+
+func TestJudgeFailsAWrongMove(t *testing.T) {
+	resp, err := judge(line(), Request{Puzzle: 1, Step: 0, UOI: "d1a1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Failed || resp.Solved || resp.Reply != nil || len(resp.Dests) != 0 {
+		t.Fatalf("wrong move: %+v, want a failure and a frozen board", resp)
+	}
+	if resp.Move == nil || resp.Move.UOI != "d1a1" {
+		t.Fatalf("wrong move not shown: %+v", resp.Move)
+	}
+}
+
+func TestJudgeAcceptsAnyMate(t *testing.T) {
+	// the line wants Qc1, but Qd4 mates on the spot
+	resp, err := judge(line(), Request{Puzzle: 1, Step: 0, UOI: "d1d4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Solved {
+		t.Fatalf("mate not accepted: %+v", resp)
+	}
+}
+
+func TestJudgeRejectsBadRequests(t *testing.T) {
+	for _, req := range []Request{
+		{Step: 2, UOI: "c1c4"},  // past the last solver move
+		{Step: -1, UOI: "d1c1"}, // before the first
+		{Step: 0, UOI: "a1a4"},  // illegal
+	} {
+		if _, err := judge(line(), req); !errors.Is(err, ErrBadRequest) {
+			t.Errorf("%+v: err = %v, want ErrBadRequest", req, err)
+		}
+	}
+}
+
+func TestJudgeDescribes(t *testing.T) {
+	resp, err := judge(line(), Request{Step: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Move != nil || resp.OFEN == mateInOne || len(resp.Dests) == 0 {
+		t.Fatalf("describe at step 1: %+v, want the position after d1c1 a4b4", resp)
+	}
+}
@@ -0,0 +1,171 @@
+package puzzle
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/dispatch"
+	"github.com/dechristopher/lio/engine"
+)
+
+const (
+	// solveDepth is the depth each root move is judged at. Every legal move is
+	// searched separately, so this is the engine's depth one ply deeper than
+	// the child searches actually run.
+	solveDepth = 6
+	// solveBudget caps each child search's wall clock: validation runs on the
+	// miner's background goroutine, never on a request, but a batch should not
+	// take minutes either.
+	solveBudget = 250 * time.Millisecond
+	// maxSolverMoves bounds how long a line is followed. Past three moves a
+	// forced line is usually the engine's taste rather than the only way.
+	maxSolverMoves = 3
+	// decisive is decisiveCp in the engine's own units (a pawn is 10).
+	decisive = decisiveCp / 10
+	// uniqueMargin is how far, in engine units, the best move must stand clear
+	// of the runner-up for the runner-up to count as a wrong answer.
+	uniqueMargin = 20
+	// mateScore is where the engine's scores stop being material and start
+	// being a forced mate.
+	mateScore = engine.WinVal / 2
+)
+
+// Solve validates a candidate position and returns its solution: UOI moves,
+// the solver's first, alternating with the engine's best defence, ending on a
+// solver move. ok is false when the position has no unique winning move, or
+// when the only way on is a draw.
+//
+// A checkmating move always ends the line, and any mate is as good as another
+// — the judge accepts them all — so several mates in one are not ambiguity.
+func Solve(ofen string) (line []string, theme string, ok bool) {
+	pos, err := octad.OFEN(ofen)
+	if err != nil {
+		return nil, "", false
+	}
+	g, err := octad.NewGame(pos)
+	if err != nil {
+		return nil, "", false
+	}
+
+	for n := 0; n < maxSolverMoves; n++ {
+		best, found := uniqueBest(g, n == 0)
+		if !found {
+			break
+		}
+		line = append(line, best.String())
+		if err := g.Move(best); err != nil {
+			return nil, "", false
+		}
+		if g.Outcome() != octad.NoOutcome {
+			if g.Method() == octad.Checkmate {
+				return line, ThemeMate, true
+			}
+			// the only move that kept the win drew instead: no puzzle
+			return nil, "", false
+		}
+
+		reply := solveSearch(g.Position().String(), solveDepth)
+		if reply.Move.String() == "a1a1" {
+			break
+		}
+		mv := findMove(g, reply.Move.String())
+		if mv == nil {
+			break
+		}
+		line = append(line, mv.String())
+		if err := g.Move(mv); err != nil {
+			break
+		}
+	}
+
+	// the line always ends on the solver's move: drop a trailing reply
+	if len(line)%2 == 0 && len(line) > 0 {
+		line = line[:len(line)-1]
+	}
+	if len(line) == 0 {
+		return nil, "", false
+	}
+	return line, ThemeAdvantage, true
+}
+
+// solveSearch searches ofen to depth for the solver, on the engine worker pool
+// behind every game's request (see dispatch.Search).
+func solveSearch(ofen string, depth int) engine.MoveEval {
+	return dispatch.Search(dispatch.SearchRequest{
+		Kind:   "puzzle",
+		OFEN:   ofen,
+		Depth:  depth,
+		Budget: solveBudget,
+	})
+}
+
+// scored is one root move and its eval from the mover's side.
+type scored struct {
+	move  *octad.Move
+	score float64
+}
+
+// uniqueBest searches every legal move in g and returns the only one that wins,
+// or false when none does or more than one does. A move that mates on the spot
+// is returned straight away. first marks the puzzle's opening move, which must
+// also not be the only legal move: a forced reply is not a decision.
+func uniqueBest(g *octad.Game, first bool) (*octad.Move, bool) {
+	moves := g.ValidMoves()
+	if len(moves) == 0 || (first && len(moves) == 1) {
+		return nil, false
+	}
+	sign := 1.0
+	if g.Position().Turn() == octad.Black {
+		sign = -1
+	}
+
+	all := make([]scored, 0, len(moves))
+	for _, m := range moves {
+		child := g.Clone()
+		if err := child.Move(m); err != nil {
+			continue
+		}
+		if child.Outcome() != octad.NoOutcome && child.Method() == octad.Checkmate {
+			return m, true
+		}
+		e := solveSearch(child.Position().String(), solveDepth-1)
+		all = append(all, scored{move: m, score: sign * e.Eval})
+	}
+	if len(all) == 0 {
+		return nil, false
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].score > all[j].score })
+
+	best := all[0]
+	if best.score < decisive {
+		return nil, false
+	}
+	if len(all) == 1 {
+		return best.move, true
+	}
+	second := all[1]
+	// a forced mate asks for the mate: a runner-up that only wins material is
+	// the wrong answer, one that mates as well is a second right one
+	if best.score >= mateScore {
+		if second.score >= mateScore {
+			return nil, false
+		}
+		return best.move, true
+	}
+	if second.score >= decisive || best.score-second.score < uniqueMargin {
+		return nil, false
+	}
+	return best.move, true
+}
+
+// findMove resolves a UOI string to a legal move in the position, or nil.
+func findMove(g *octad.Game, uoi string) *octad.Move {
+	for _, m := range g.ValidMoves() {
+		if m.String() == uoi {
+			return m
+		}
+	}
+	return nil
+}
@@ -79,6 +79,27 @@ func (r Rating) Update(opp Rating, score float64) Rating {
 	return r.updatePeriod([]opponent{{r: opp, score: score}}, tau)
 }
 
+// Result is one game of a multi-game rating period: the opponent's rating
+// going into the period and the subject's score against them.
+type Result struct {
+	Opp   Rating
+	Score float64
+}
+
+// UpdatePeriod returns the player's new rating after a rating period of several
+// games, every one rated against the opponents' ratings as they stood at the
+// period's start. Live play never needs it — every game is its own period — but
+// a batch of games played together (the self-play harness's round-robin, see
+// package selfplay) is rated this way, so the order they finished in does not
+// move anyone's rating.
+func (r Rating) UpdatePeriod(results []Result) Rating {
+	opps := make([]opponent, len(results))
+	for i, res := range results {
+		opps[i] = opponent{r: res.Opp, score: res.Score}
+	}
+	return r.updatePeriod(opps, tau)
+}
+
 // opponent pairs an opponent's rating with the subject's score against them.
 type opponent struct {
 	r     Rating
@@ -69,6 +69,23 @@ func TestSymmetry(t *testing.T) {
 	}
 }
 
+// TestUpdatePeriodOrderFree: a period rates every game against the
+// opponents' pre-period ratings, so the order its games are listed in cannot
+// change the result — unlike the same games applied one at a time.
+func TestUpdatePeriodOrderFree(t *testing.T) {
+	p := New()
+	strong := Rating{R: 1800, RD: 80, Sigma: DefaultVol}
+	weak := Rating{R: 1300, RD: 80, Sigma: DefaultVol}
+	a := p.UpdatePeriod([]Result{{Opp: strong, Score: Loss}, {Opp: weak, Score: Win}, {Opp: New(), Score: Draw}})
+	b := p.UpdatePeriod([]Result{{Opp: New(), Score: Draw}, {Opp: weak, Score: Win}, {Opp: strong, Score: Loss}})
+	if !approx(a.R, b.R, 1e-9) || !approx(a.RD, b.RD, 1e-9) {
+		t.Errorf("period depends on order: %.4f/%.4f vs %.4f/%.4f", a.R, a.RD, b.R, b.RD)
+	}
+	if a.Games != 3 {
+		t.Errorf("games = %d, want 3", a.Games)
+	}
+}
+
 // TestRDInflation: a player who sits out a period sees RD grow (toward, but
 // capped at, the unrated default).
 func TestRDInflation(t *testing.T) {
@@ -59,6 +59,14 @@ import (
 //
 // A bot holds no uid and is never indexed. Neither is the empty seat of a room
 // still waiting for an opponent.
+//
+// # One node only
+//
+// The index lives in this process and covers the rooms this node owns. On a
+// multi-node deployment (package cluster) a seat on another node is not in it:
+// Busy, Engaged and the one-game rule they back answer for this node alone,
+// and an account whose requests reach two nodes can hold a game on each. That
+// is unsupported rather than handled — see the cluster package doc.
 
 // seat is one room's contribution for one player: the session holding the seat
 // and the account behind it (0 for an anonymous seat).
@@ -0,0 +1,31 @@
+package room
+
+// The room's part in in-game chat (the chat package): who is seated, and which
+// seat has muted the other. The lines themselves never pass through the room —
+// chat is not game state, and the room routine has no business being woken by
+// it.
+
+// MuteChat records whether a seated uid has muted the room's player chat.
+// Anyone else's call is ignored: a spectator has no player chat to mute.
+func (r *Instance) MuteChat(uid string, muted bool) {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	if !r.players.IsPlayer(uid) {
+		return
+	}
+	if !muted {
+		delete(r.chatMuted, uid)
+		return
+	}
+	if r.chatMuted == nil {
+		r.chatMuted = make(map[string]bool)
+	}
+	r.chatMuted[uid] = true
+}
+
+// ChatMuted reports whether uid has muted the room's player chat.
+func (r *Instance) ChatMuted(uid string) bool {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	return r.chatMuted[uid]
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/dechristopher/lio/message"
 	"github.com/dechristopher/lio/player"
 	"github.com/dechristopher/lio/str"
-	"github.com/dechristopher/lio/util"
 	"github.com/dechristopher/lio/www/ws/proto"
 )
 
@@ -58,8 +57,8 @@ func (r *Instance) RequestDraw(meta channel.SocketContext) {
 // ever blocking the WS read loop: it selects on room teardown and drops on a
 // full buffer. During an ongoing game the controlChannel has no other producer
 // (rematch is only accepted at game-over, cancel only while waiting), so the two
-// buffer slots comfortably hold a resign/draw pair; a dropped control just means
-// the player clicks again.
+// buffer slots comfortably hold a pair of resign/draw/takeback clicks; a dropped
+// control just means the player clicks again.
 func (r *Instance) sendControl(control message.RoomControl) {
 	// shutdown drain: no mutations after the final snapshot capture
 	if Draining() {
@@ -70,12 +69,11 @@ func (r *Instance) sendControl(control message.RoomControl) {
 	case r.controlChannel <- control:
 	case <-r.done:
 	default:
-		util.DebugFlag("room", str.CRoom, "[%s] dropped control %d from %s: buffer full",
-			r.ID, control.Type, control.Player)
+		r.log().DebugFlag("room", str.CRoom, "dropped control %d from %s: buffer full", control.Type, control.Player)
 	}
 }
 
-// handleGameControl processes an in-game control (resign or draw) received while
+// handleGameControl processes an in-game control (resign, draw or takeback) received while
 // the game is ongoing. It returns whether the game ended and, if so, the FSM
 // transition event to fire. Rematch/cancel controls are not produced in this
 // state and are ignored.
@@ -85,6 +83,8 @@ func (r *Instance) handleGameControl(control message.RoomControl) (bool, *fsm.Ev
 		return r.resignControl(control)
 	case message.Draw:
 		return r.drawControl(control)
+	case message.Takeback:
+		return r.takebackControl(control)
 	default:
 		return false, nil
 	}
@@ -101,7 +101,7 @@ func (r *Instance) resignControl(control message.RoomControl) (bool, *fsm.EventD
 	r.game.Resign(color)
 	r.stateMu.Unlock()
 
-	util.DebugFlag("room", str.CRoom, "[%s] %s resigned", r.ID, color)
+	r.log().DebugFlag("room", str.CRoom, "%s resigned", color)
 	return r.tryGameOver(control.Ctx, false)
 }
 
@@ -158,14 +158,14 @@ func (r *Instance) applyAgreedDraw(meta channel.SocketContext) (bool, *fsm.Event
 	}
 	if err := r.game.Draw(octad.DrawOffer); err != nil {
 		r.stateMu.Unlock()
-		util.Error(str.CRoom, "[%s] draw by agreement failed: %s", r.ID, err.Error())
+		r.log().Error(str.CRoom, "draw by agreement failed: %s", err.Error())
 		return false, nil
 	}
 	r.drawOffer = octad.NoColor
 	r.draw = player.NewAgreement()
 	r.stateMu.Unlock()
 
-	util.DebugFlag("room", str.CRoom, "[%s] draw agreed", r.ID)
+	r.log().DebugFlag("room", str.CRoom, "draw agreed")
 	return r.tryGameOver(meta, false)
 }
 
@@ -176,13 +176,15 @@ func (r *Instance) applyAgreedDraw(meta channel.SocketContext) (bool, *fsm.Event
 // send never blocks even if the game ends first.
 func (r *Instance) requestEngineDraw() {
 	r.stateMu.Lock()
-	depth, budget := r.calcSearchLocked(r.game.ToMove)
+	depth, budget, remaining := r.calcSearchLocked(r.game.ToMove)
 	req := dispatch.DrawRequest{
+		RoomID:          r.ID,
 		GameID:          r.game.ID,
 		OFEN:            r.game.OFEN(),
 		History:         r.game.OFENHistory(),
 		Depth:           depth,
 		Budget:          budget,
+		Clock:           remaining,
 		ResponseChannel: r.drawEvalChannel,
 		Done:            r.done,
 	}
@@ -214,7 +216,7 @@ func (r *Instance) handleDrawEval(eval *message.RoomDrawEval) (bool, *fsm.EventD
 		r.drawOffer = octad.NoColor
 		r.draw = player.NewAgreement()
 		r.stateMu.Unlock()
-		util.DebugFlag("room", str.CRoom, "[%s] bot declined draw", r.ID)
+		r.log().DebugFlag("room", str.CRoom, "bot declined draw")
 		proto.DrawOfferPayload{Declined: true}.Broadcast(channel.SocketContext{Channel: r.ID, MT: 1})
 		return false, nil
 	}
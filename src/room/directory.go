@@ -0,0 +1,169 @@
+package room
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// ownerDirectory is the site-wide room directory of a multi-node deployment:
+// which node owns each live room (package cluster). Implemented by
+// cache.RoomOwners (Redis) and by the in-memory fake in the directory tests;
+// like snapshotStore, the room package stays storage-agnostic.
+//
+// A room is owned by exactly one node, the one running its actor. Ownership
+// is a lease: the owner renews it on every leaseTick, and releases it when the
+// room's snapshot is deleted. A node that dies stops renewing, and once its
+// own heartbeat lapses another node may claim its rooms and restore them from
+// their snapshots (Adopt) — which is what makes a crash or a deploy cost each
+// player one reconnect rather than their game.
+type ownerDirectory interface {
+	// ClaimRoom takes ownership of a room for this node. It succeeds when the
+	// room is unowned, already ours, or owned by a node whose heartbeat has
+	// lapsed; false means a live node holds it.
+	ClaimRoom(id string, ttl time.Duration) (bool, error)
+	// RenewRooms extends this node's leases on a batch of rooms. A lease this
+	// node no longer holds is left alone.
+	RenewRooms(ids []string, ttl time.Duration) error
+	// ReleaseRoom gives up this node's lease on a room, if it holds one.
+	ReleaseRoom(id string) error
+}
+
+const (
+	// leaseTTL bounds how long a room stays pinned to a node that stopped
+	// renewing it. Adoption does not wait for it — a claim succeeds as soon as
+	// the owner's heartbeat is gone — so it only matters when the heartbeat
+	// outlives the node's interest in a room, which a release normally ends.
+	leaseTTL = 30 * time.Second
+
+	// leaseTick is how often the persister renews every live room's lease. A
+	// third of the TTL, so two missed renewals still leave the lease standing.
+	leaseTick = leaseTTL / 3
+
+	// claimRetry is the pause before a failed claim's one retry: long enough
+	// to ride out a dropped connection, short enough not to hold up Create.
+	claimRetry = 100 * time.Millisecond
+)
+
+// activeDirectory is the process-wide room directory, nil on a single node
+// (no Redis). Set once at boot by UpDirectory before rehydration, and read
+// lock-free by Create, the persister and Adopt.
+var activeDirectory atomic.Pointer[ownerDirectory]
+
+// UpDirectory installs the room directory. Called once at boot, before
+// RehydrateAll, so rehydration only restores the rooms this node can claim.
+func UpDirectory(d ownerDirectory) {
+	activeDirectory.Store(&d)
+	util.Debug(str.CRoom, "room directory online")
+}
+
+// directory returns the active room directory, or nil on a single node.
+func directory() ownerDirectory {
+	if d := activeDirectory.Load(); d != nil {
+		return *d
+	}
+	return nil
+}
+
+// claimRoom takes ownership of a room id for this node. Without a directory
+// every room is ours. A directory error is retried once, then fails the claim:
+// serving a room nobody else can find, or one a live node already runs, would
+// split a game between two nodes. The error is returned so Create can refuse
+// the room (the player is sent back to try again) rather than pick another id.
+func claimRoom(id string) (bool, error) {
+	d := directory()
+	if d == nil {
+		return true, nil
+	}
+	ok, err := d.ClaimRoom(id, leaseTTL)
+	if err != nil {
+		time.Sleep(claimRetry)
+		ok, err = d.ClaimRoom(id, leaseTTL)
+	}
+	if err != nil {
+		util.With(util.RoomID(id)).Error(str.CRoom, "room claim failed: %v", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+// renewLeases extends this node's lease on every live room.
+func renewLeases() {
+	d := directory()
+	if d == nil {
+		return
+	}
+	var ids []string
+	rooms.Range(func(k, _ interface{}) bool {
+		ids = append(ids, k.(string))
+		return true
+	})
+	if len(ids) == 0 {
+		return
+	}
+	if err := d.RenewRooms(ids, leaseTTL); err != nil {
+		util.Error(str.CRoom, "lease renewal failed (%d rooms), will retry: %v", len(ids), err)
+	}
+}
+
+// releaseRoom gives up this node's lease on a room. Called by the persister
+// after the room's snapshot is deleted, never before: a lease released while
+// the snapshot still exists would let another node adopt a finished room.
+func releaseRoom(id string) {
+	if d := directory(); d != nil {
+		if err := d.ReleaseRoom(id); err != nil {
+			util.With(util.RoomID(id)).Error(str.CRoom, "lease release failed: %v", err)
+		}
+	}
+}
+
+// releaseAll gives up every lease this node holds: the drain's hand-off. The
+// final snapshots are already flushed, so the next node a reconnecting player
+// reaches can adopt the room straight away instead of waiting out the lease.
+func releaseAll() {
+	rooms.Range(func(k, _ interface{}) bool {
+		releaseRoom(k.(string))
+		return true
+	})
+}
+
+// Adopt restores a room another node owned and can no longer serve: its
+// owner died, or drained for a deploy. It claims the room and rebuilds it
+// from its snapshot. Returns false when there is nothing to adopt — no
+// snapshot (the room closed, or never outlived the waiting phase), or a live
+// node still holds it.
+//
+// It is the lazy half of failover. Boot rehydration restores what is unowned
+// when a node comes up; this is what a player's reconnect reaches for once the
+// node they were on has gone.
+func Adopt(id string) (*Instance, bool) {
+	p := activePersister.Load()
+	if p == nil || directory() == nil || Draining() {
+		return nil, false
+	}
+	data, found, err := p.store.LoadRoom(id)
+	if err != nil || !found {
+		return nil, false
+	}
+	// a failed claim is not adopted here: the player's next request retries
+	if ok, _ := claimRoom(id); !ok {
+		return nil, false
+	}
+	// two requests for the same orphan can race here on one node; the loser
+	// finds the winner's room
+	if r, err := Get(id); err == nil {
+		return r, true
+	}
+	r, ok := restore(p.store, id, data)
+	if !ok {
+		// a room another request restored between the Get above and here
+		if r, err := Get(id); err == nil {
+			return r, true
+		}
+		return nil, false
+	}
+	util.With(util.RoomID(id)).Info(str.CRoom, "adopted room from its snapshot")
+	return r, true
+}
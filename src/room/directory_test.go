@@ -0,0 +1,197 @@
+package room
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/variant"
+)
+
+// memDirectory is an in-memory ownerDirectory for one node, self, with a set
+// of other nodes whose heartbeats are live.
+type memDirectory struct {
+	mu     sync.Mutex
+	self   string
+	owners map[string]string
+	live   map[string]bool
+	// down fails every claim, like an unreachable Redis.
+	down bool
+}
+
+func newMemDirectory(self string) *memDirectory {
+	return &memDirectory{
+		self:   self,
+		owners: make(map[string]string),
+		live:   make(map[string]bool),
+	}
+}
+
+func (d *memDirectory) ClaimRoom(id string, _ time.Duration) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.down {
+		return false, errors.New("memdirectory: simulated outage")
+	}
+	if owner, ok := d.owners[id]; ok && owner != d.self && d.live[owner] {
+		return false, nil
+	}
+	d.owners[id] = d.self
+	return true, nil
+}
+
+func (d *memDirectory) RenewRooms([]string, time.Duration) error {
+	return nil
+}
+
+func (d *memDirectory) ReleaseRoom(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.owners[id] == d.self {
+		delete(d.owners, id)
+	}
+	return nil
+}
+
+func (d *memDirectory) owner(id string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.owners[id]
+}
+
+// installDirectory wires a directory into the global hook and tears it down
+// after.
+func installDirectory(t *testing.T, d *memDirectory) {
+	t.Helper()
+	UpDirectory(d)
+	t.Cleanup(func() { activeDirectory.Store(nil) })
+}
+
+// snapshotAs persists an ongoing test room under another room id.
+func snapshotAs(t *testing.T, id string) []byte {
+	t.Helper()
+	r := newTestInstance(t, "wp", "bp")
+	r.ID = "snapsource"
+	driveToOngoing(t, r)
+	r.game.Clock.Start()
+	playTestMoves(t, r, 1)
+	data, ok := r.Persist()
+	r.game.Clock.Stop(false, true)
+	if !ok {
+		t.Fatal("persist failed")
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	doc["id"] = id
+	out, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// TestRehydrateAllRestoresOnlyClaimableRooms: every node sees every snapshot
+// at boot. A room a live node owns is left to it, snapshot and all; a room
+// whose owner's heartbeat has lapsed is an orphan, and this node adopts it.
+func TestRehydrateAllRestoresOnlyClaimableRooms(t *testing.T) {
+	d := newMemDirectory("n1")
+	d.owners["dirowned"] = "n2"
+	d.owners["dirorphan"] = "n3"
+	d.live["n2"] = true
+	installDirectory(t, d)
+
+	ms := newMemStore()
+	ms.snaps["dirowned"] = snapshotAs(t, "dirowned")
+	ms.snaps["dirorphan"] = snapshotAs(t, "dirorphan")
+
+	restored := RehydrateAll(ms)
+	t.Cleanup(func() {
+		rooms.Delete("dirowned")
+		rooms.Delete("dirorphan")
+	})
+
+	if restored != 1 {
+		t.Fatalf("restored %d rooms, want 1", restored)
+	}
+	if _, err := Get("dirowned"); err == nil {
+		t.Fatal("a room owned by a live node was restored here too")
+	}
+	if _, ok := ms.get("dirowned"); !ok {
+		t.Fatal("a room owned by a live node lost its snapshot")
+	}
+	if _, err := Get("dirorphan"); err != nil {
+		t.Fatal("the orphaned room was not adopted")
+	}
+	if got := d.owner("dirorphan"); got != "n1" {
+		t.Fatalf("orphan is owned by %q, want n1", got)
+	}
+}
+
+// TestFlushReleasesLeaseAfterDelete: a closed room's lease is given up with
+// its snapshot, not before — a lease released first would leave a finished
+// room's snapshot adoptable.
+func TestFlushReleasesLeaseAfterDelete(t *testing.T) {
+	d := newMemDirectory("n1")
+	installDirectory(t, d)
+	ms := newMemStore()
+	p := installPersister(t, ms)
+
+	ms.snaps["dirclosed"] = []byte(`{}`)
+	d.owners["dirclosed"] = "n1"
+
+	p.forget("dirclosed")
+	if got := d.owner("dirclosed"); got != "n1" {
+		t.Fatalf("lease released before the flush: owner %q", got)
+	}
+	p.flush()
+	if _, ok := ms.get("dirclosed"); ok {
+		t.Fatal("snapshot not deleted")
+	}
+	if got := d.owner("dirclosed"); got != "" {
+		t.Fatalf("lease not released after the delete: owner %q", got)
+	}
+}
+
+// TestClaimFailsWhenDirectoryDown: a room this node cannot claim is not served
+// here. Boot leaves its snapshot for a later Adopt, and neither an adoption
+// nor a creation goes ahead while the directory is unreachable.
+func TestClaimFailsWhenDirectoryDown(t *testing.T) {
+	d := newMemDirectory("n1")
+	d.down = true
+	installDirectory(t, d)
+
+	if ok, err := claimRoom("dirdown"); ok || err == nil {
+		t.Fatalf("claim with the directory down = %v, %v; want false and the error", ok, err)
+	}
+
+	ms := newMemStore()
+	ms.snaps["dirdown"] = snapshotAs(t, "dirdown")
+	installPersister(t, ms)
+	if restored := RehydrateAll(ms); restored != 0 {
+		t.Fatalf("restored %d rooms with the directory down, want 0", restored)
+	}
+	if _, ok := ms.get("dirdown"); !ok {
+		t.Fatal("an unclaimed room lost its snapshot")
+	}
+	if _, ok := Adopt("dirdown"); ok {
+		t.Fatal("adopted a room with the directory down")
+	}
+	params := Params{
+		Players: player.Players{
+			octad.White: &player.Player{ID: "wp"},
+			octad.Black: &player.Player{ID: "bp"},
+		},
+		GameConfig: game.OctadGameConfig{Variant: variant.HalfOneBlitz},
+	}
+	if _, err := Create(params); !errors.As(err, &ErrDirectory{}) {
+		t.Fatalf("Create with the directory down returned %v, want ErrDirectory", err)
+	}
+}
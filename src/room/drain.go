@@ -23,9 +23,10 @@ func Draining() bool {
 // Drain quiesces the process for shutdown: gate all inbound mutations, freeze
 // every room's clock (Stop without publishing a flag state — the paused,
 // as-of-last-flip clock is precisely what the restore path expects), then
-// synchronously flush every room's final snapshot. The caller (the www signal
-// handler) then closes all sockets with 1012 Service Restart and shuts the
-// listener down. Total wall time is a couple of Redis round trips.
+// synchronously flush every room's final snapshot and release the rooms'
+// leases. The caller (the www signal handler) then closes all sockets with
+// 1012 Service Restart and shuts the listener down. Total wall time is a
+// couple of Redis round trips.
 func Drain() {
 	draining.Store(true)
 
@@ -36,6 +37,10 @@ func Drain() {
 
 	FlushSnapshots()
 
+	// hand the rooms on: with their final snapshots written, whichever node a
+	// reconnecting player reaches next may adopt them (directory.go)
+	releaseAll()
+
 	util.Info(str.CRoom, "drained %d room(s): mutations gated, clocks frozen, snapshots flushed", Count())
 }
 
@@ -35,3 +35,12 @@ type ErrDraining struct{}
 func (e ErrDraining) Error() string {
 	return "room:create: server is restarting"
 }
+
+// ErrDirectory is returned by Create when the room directory of a multi-node
+// deployment cannot be reached: a room this node could not claim would be
+// invisible to the others.
+type ErrDirectory struct{}
+
+func (e ErrDirectory) Error() string {
+	return "room:create: room directory unavailable"
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/dechristopher/lio/channel"
 	"github.com/dechristopher/lio/message"
 	"github.com/dechristopher/lio/str"
-	"github.com/dechristopher/lio/util"
 )
 
 // vacancyGrace returns how long the room should wait, once it has gone empty,
@@ -114,17 +113,17 @@ func (r *Instance) handleWaitingForPlayers() {
 	reconcileCleanup := func() {
 		if occupants() > 0 {
 			connected = true
-			util.DebugFlag("room", str.CRoom, "[%s] stopped cleanup timer, players connected", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "stopped cleanup timer, players connected")
 			stopCleanup()
 			return
 		}
 
 		grace := r.vacancyGrace(connected)
-		util.DebugFlag("room", str.CRoom, "[%s] room empty, teardown in %s unless someone (re)connects", r.ID, grace)
+		r.log().DebugFlag("room", str.CRoom, "room empty, teardown in %s unless someone (re)connects", grace)
 		armCleanup(grace)
 	}
 
-	util.DebugFlag("room", str.CRoom, "[%s] waiting for players", r.ID)
+	r.log().DebugFlag("room", str.CRoom, "waiting for players")
 
 	for {
 		select {
@@ -145,7 +144,7 @@ func (r *Instance) handleWaitingForPlayers() {
 			// handoff (seats claimed, joiner's socket not yet open) can never
 			// trigger a premature start.
 			if r.HasBot() || r.bothPlayersConnected() {
-				util.DebugFlag("room", str.CRoom, "[%s] players connected, game ready", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "players connected, game ready")
 				if err := r.event(EventPlayersConnected); err != nil {
 					panic(err)
 				}
@@ -158,12 +157,12 @@ func (r *Instance) handleWaitingForPlayers() {
 			// grace, or a joiner who arrived) — so don't tear down a room that is
 			// no longer empty. The pending listener event re-reconciles next loop.
 			if occupants() > 0 {
-				util.DebugFlag("room", str.CRoom, "[%s] cleanup fire raced a (re)connect, keeping room", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "cleanup fire raced a (re)connect, keeping room")
 				continue
 			}
 			r.abandoned = true
 			// room expired, clean up
-			util.DebugFlag("room", str.CRoom, "[%s] room expired, cleaning up", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "room expired, cleaning up")
 			err := r.event(EventPlayerAbandons)
 			if err != nil {
 				panic(err)
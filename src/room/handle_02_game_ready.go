@@ -28,15 +28,15 @@ func (r *Instance) handleGameReady() {
 		// discard) a real submission arriving right after the transition.
 		r.drainDeployChannel()
 
-		util.DebugFlag("room", str.CRoom, "[%s] starting deploy phase", r.ID)
+		r.log().DebugFlag("room", str.CRoom, "starting deploy phase")
 		if err := r.event(EventStartDeploy); err != nil {
 			panic(err)
 		}
 		return
 	}
 
-	// one-minute abandon timer after game start
-	cleanupTimer := time.NewTimer(time.Minute)
+	// one-minute abandon timer after game start (longer on a simul board)
+	cleanupTimer := time.NewTimer(r.firstMoveWindow())
 	defer cleanupTimer.Stop()
 
 	// stop/reset helpers for the cleanup timer, draining any pending fire first
@@ -93,7 +93,7 @@ func (r *Instance) handleGameReady() {
 	// state), so a rematch streams its new game into the same TV slot.
 	home.Publish(r.homeEvent(home.Start))
 
-	util.DebugFlag("room", str.CRoom, "[%s] waiting for white to move", r.ID)
+	r.log().DebugFlag("room", str.CRoom, "waiting for white to move")
 
 	// When the bot plays White it owns the first move, so nothing forces a human
 	// action before an engine search is dispatched. Gate that search on the human
@@ -108,7 +108,7 @@ func (r *Instance) handleGameReady() {
 		if !engineToMove || engineRequested || !r.bothPlayersConnected() {
 			return
 		}
-		util.DebugFlag("room", str.CRoom, "[%s] engine making first move..", r.ID)
+		r.log().DebugFlag("room", str.CRoom, "engine making first move..")
 		r.requestEngineMove()
 		engineRequested = true
 	}
@@ -123,7 +123,7 @@ func (r *Instance) handleGameReady() {
 			// casual rooms retime the cleanup timer off presence
 			syncCasualCleanup()
 		case move := <-r.moveChannel:
-			util.DebugFlag("room", str.CRoom, "[%s] got move %s from %s (%s / %s)", r.ID, move.Move.UOI, move.Player, r.game.White, r.game.Black)
+			r.log().DebugFlag("room", str.CRoom, "got move %s from %s (%s / %s)", move.Move.UOI, move.Player, r.game.White, r.game.Black)
 
 			// don't allow moves out of order
 			if !r.isTurn(move) {
@@ -131,16 +131,16 @@ func (r *Instance) handleGameReady() {
 				continue
 			}
 
-			util.DebugFlag("room", str.CRoom, "[%s] white (%s) trying to make first move", r.ID, move.Player)
+			r.log().DebugFlag("room", str.CRoom, "white (%s) trying to make first move", move.Player)
 			// start game clock on first move
 			if r.game.Clock.State(true).IsPaused {
-				util.DebugFlag("room", str.CRoom, "[%s] starting clock", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "starting clock")
 				r.game.Clock.Start()
 			}
 
 			// make move and continue routine if move failed
 			if ok := r.makeMove(move); !ok {
-				util.DebugFlag("room", str.CRoom, "[%s] invalid first move, resetting clock", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "invalid first move, resetting clock")
 				r.game.Clock.Reset()
 
 				// re-request engine first move
@@ -150,7 +150,7 @@ func (r *Instance) handleGameReady() {
 				continue
 			}
 
-			util.DebugFlag("room", str.CRoom, "[%s] white made first move, game (%s) starting", r.ID, r.game.ID)
+			r.log().With(util.GameID(r.game.ID)).DebugFlag("room", str.CRoom, "white made first move, game starting")
 
 			// transition game state to GameOngoing
 			err := r.event(EventStartGame)
@@ -183,8 +183,12 @@ func (r *Instance) handleGameReady() {
 				continue
 			}
 			r.abandoned = true
+			// in a tournament, the player who never moved forfeits the game
+			r.stateMu.Lock()
+			r.publishResultLocked(octad.BlackWon, "forfeit", 0)
+			r.stateMu.Unlock()
 			// game expired, white timed out making first move
-			util.DebugFlag("room", str.CRoom, "[%s] game expired, white timed out making first move, cleaning up", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "game expired, white timed out making first move, cleaning up")
 			err := r.event(EventPlayerAbandons)
 			if err != nil {
 				panic(err)
@@ -21,7 +21,7 @@ import (
 // which case any missing arrangement is auto-filled with the standard ordering —
 // the game is rebuilt from the deployed OFEN and normal play begins.
 func (r *Instance) handleDeploy() {
-	util.DebugFlag("room", str.CRoom, "[%s] deploy phase started", r.ID)
+	r.log().DebugFlag("room", str.CRoom, "deploy phase started")
 
 	// record the deploy deadline so a (re)connecting client can be told the
 	// correct remaining time via DeployStateMessage, and reset the committed
@@ -81,7 +81,7 @@ func (r *Instance) handleDeploy() {
 			}
 			d, err := parseDeployment(sub.Order)
 			if err != nil {
-				util.DebugFlag("room", str.CRoom, "[%s] rejected deploy from %s: %v", r.ID, sub.Player, err)
+				r.log().DebugFlag("room", str.CRoom, "rejected deploy from %s: %v", sub.Player, err)
 				// resync the player to the deploy state so they can retry
 				channel.Unicast(r.DeployStateMessage(sub.Player), sub.Ctx)
 				continue
@@ -94,19 +94,19 @@ func (r *Instance) handleDeploy() {
 			// full send buffer can drop) or the next 2s announce. Harmless if the
 			// client already saw its lock via the broadcast; both are idempotent.
 			channel.Unicast(r.DeployStateMessage(sub.Player), sub.Ctx)
-			util.DebugFlag("room", str.CRoom, "[%s] %s deployed", r.ID, color)
+			r.log().DebugFlag("room", str.CRoom, "%s deployed", color)
 		case bot := <-botDeployCh:
 			d := deploymentFromPlacement(bot.Color, bot.Placement)
 			got[bot.Color] = d
 			r.recordAndLock(bot.Color, d)
-			util.DebugFlag("room", str.CRoom, "[%s] bot (%s) deployed %s", r.ID, bot.Color, d.order())
+			r.log().DebugFlag("room", str.CRoom, "bot (%s) deployed %s", bot.Color, d.order())
 		case <-announce.C:
 			channel.Broadcast(r.deployAnnounceMessage(), channel.SocketContext{Channel: r.ID, MT: 1})
 			// resync the grid's dial off the same tick, so a TV viewer who
 			// connected mid-phase converges within one interval
 			home.Publish(r.homeEvent(home.Deploy))
 		case <-deployTimer.C:
-			util.DebugFlag("room", str.CRoom, "[%s] deploy timer expired, auto-filling", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "deploy timer expired, auto-filling")
 			r.deployAndStart(got, botColor)
 			return
 		case control := <-r.controlChannel:
@@ -137,11 +137,11 @@ func (r *Instance) deployAndStart(got map[octad.Color]Deployment, botColor octad
 	if err != nil {
 		// deployments are validated on submit and the auto-fill is always legal,
 		// so this is effectively unreachable; fall back to the standard start
-		util.Error(str.CRoom, "[%s] deploy assembly failed: %v", r.ID, err)
+		r.log().Error(str.CRoom, "deploy assembly failed: %v", err)
 		ofen, _ = assembleDeployedOFEN(standardDeployment, standardDeployment)
 	}
 
-	util.DebugFlag("room", str.CRoom, "[%s] deploy complete, starting from %s", r.ID, ofen)
+	r.log().DebugFlag("room", str.CRoom, "deploy complete, starting from %s", ofen)
 
 	// rebuild the game from the deployed position, preserving variant and players
 	r.stateMu.Lock()
@@ -150,7 +150,7 @@ func (r *Instance) deployAndStart(got map[octad.Color]Deployment, botColor octad
 	ng, gerr := game.NewOctadGame(cfg)
 	if gerr != nil {
 		r.stateMu.Unlock()
-		util.Error(str.CRoom, "[%s] failed to build deployed game: %v", r.ID, gerr)
+		r.log().Error(str.CRoom, "failed to build deployed game: %v", gerr)
 		r.abandoned = true
 		if eErr := r.event(EventPlayerAbandons); eErr != nil {
 			panic(eErr)
@@ -159,6 +159,8 @@ func (r *Instance) deployAndStart(got map[octad.Color]Deployment, botColor octad
 	}
 	r.game = ng
 	r.game.ToMove = ng.Position().Turn()
+	// a berserk chosen during the deploy carries over to the real clock
+	r.applyBerserkLocked()
 	// a freshly deployed game has had no human move yet
 	r.humanMoved = false
 	// the deploy phase is over; clear the deadline and committed arrangements
@@ -188,10 +190,10 @@ func (r *Instance) deployAndStart(got map[octad.Color]Deployment, botColor octad
 	// with the game ID, so a stale result is dropped by makeMove's guard.
 	if botColor == octad.White {
 		if cfg.Variant.Control.PreStart.Milli() > 0 {
-			util.DebugFlag("room", str.CRoom, "[%s] bot to move, holding opening move %s", r.ID, botRevealHold)
+			r.log().DebugFlag("room", str.CRoom, "bot to move, holding opening move %s", botRevealHold)
 			time.AfterFunc(botRevealHold, r.requestEngineMove)
 		} else {
-			util.DebugFlag("room", str.CRoom, "[%s] bot to move, requesting opening move", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "bot to move, requesting opening move")
 			r.requestEngineMove()
 		}
 	}
@@ -98,7 +98,7 @@ func (r *Instance) handleGameOngoing() {
 		}
 		r.resumeClockPending = false
 
-		util.DebugFlag("room", str.CRoom, "[%s] resuming restored clock", r.ID)
+		r.log().DebugFlag("room", str.CRoom, "resuming restored clock")
 		r.game.Clock.Resume()
 
 		channel.Broadcast(r.CurrentGameStateMessage(false, false),
@@ -142,7 +142,7 @@ func (r *Instance) handleGameOngoing() {
 			// otherwise block forever on a clock goroutine that isn't running
 			maybeResume()
 
-			util.DebugFlag("room", str.CRoom, "[%s] got move %+v", r.ID, move)
+			r.log().DebugFlag("room", str.CRoom, "got move %+v", move)
 
 			// make move and continue routine if move failed
 			if ok := r.makeMove(move); !ok {
@@ -163,7 +163,19 @@ func (r *Instance) handleGameOngoing() {
 
 			// track move lag for later compensation
 			go lag.Move.Track(moveStart)
-			util.DebugFlag("lag", str.CRoom, "move lag avg: %s", lag.Move.Get())
+			r.log().DebugFlag("lag", str.CRoom, "move lag avg: %s", lag.Move.Get())
+
+			// the move may have put a player with a premove queued on move:
+			// play it now, in this same pass of the routine, so no network
+			// round trip separates it from the move it answers
+			if isOver, event := r.playPremoves(); isOver {
+				if err := r.event(*event); err != nil {
+					panic(err)
+				}
+
+				stopAbandon()
+				return
+			}
 
 			// re-evaluate idle state: a human move disarms the idle timer for
 			// good, while the bot's move (re)arms it as we wait on the human
@@ -182,7 +194,8 @@ func (r *Instance) handleGameOngoing() {
 				return
 			}
 			// a control that didn't end the game may still have mutated the
-			// draw-offer state; keep the snapshot current
+			// draw-offer or takeback state, or unwound the game; keep the
+			// snapshot current
 			markDirty(r)
 
 		// handle the engine's verdict on a draw offer in a bot game: an accepted
@@ -217,7 +230,7 @@ func (r *Instance) handleGameOngoing() {
 			// it somehow didn't, abandon the room rather than nil-deref on
 			// event or spin on the now-buffered clock state channel
 			if !isOver || event == nil {
-				util.Error(str.CRoom, "[%s] clock flagged but game not over (victor=%d), abandoning", r.ID, flaggedState.Victor)
+				r.log().Error(str.CRoom, "clock flagged but game not over (victor=%d), abandoning", flaggedState.Victor)
 				r.abandoned = true
 				if err := r.event(EventPlayerAbandons); err != nil {
 					panic(err)
@@ -237,13 +250,13 @@ func (r *Instance) handleGameOngoing() {
 		case <-connectionListener:
 			// both players connected? a bot counts as always-connected
 			if r.bothPlayersConnected() {
-				util.DebugFlag("room", str.CRoom, "[%s] both players connected, cancelling abandon timer", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "both players connected, cancelling abandon timer")
 				maybeResume()
 				stopAbandon()
 				continue
 			}
 
-			util.DebugFlag("room", str.CRoom, "[%s] players not connected, starting abandon timer", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "players not connected, starting abandon timer")
 			// start abandon timer if both players are not connected
 			armAbandon()
 		// figure out who abandoned and resign the game
@@ -253,8 +266,9 @@ func (r *Instance) handleGameOngoing() {
 
 			util.DoBothColors(func(color octad.Color) {
 				id, isBot := r.playerInfo(color)
-				if isBot {
-					// a bot has no socket; it is always considered connected.
+				if isBot || r.simulHostSeat(color) {
+					// a bot has no socket, and a simul host is on another
+					// board; both are always considered connected.
 					// The early return is required — without it the channel
 					// lookup below would overwrite this with false (the
 					// original dead-code bug).
@@ -269,7 +283,7 @@ func (r *Instance) handleGameOngoing() {
 			// both players reconnected before the timer fired logic: don't end
 			// the game out from under two present players
 			if connected[octad.White] && connected[octad.Black] {
-				util.DebugFlag("room", str.CRoom, "[%s] both players reconnected, abandon cancelled", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "both players reconnected, abandon cancelled")
 				continue
 			}
 
@@ -277,16 +291,16 @@ func (r *Instance) handleGameOngoing() {
 			r.stateMu.Lock()
 			if !connected[octad.White] && !connected[octad.Black] {
 				// neither player is connected: draw the abandoned game
-				util.DebugFlag("room", str.CRoom, "[%s] both players abandoned, game drawn", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "both players abandoned, game drawn")
 				if err := r.game.Draw(octad.DrawOffer); err != nil {
 					r.stateMu.Unlock()
 					panic(err)
 				}
 			} else if !connected[octad.White] {
-				util.DebugFlag("room", str.CRoom, "[%s] white abandoned, black wins", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "white abandoned, black wins")
 				r.game.Resign(octad.White)
 			} else {
-				util.DebugFlag("room", str.CRoom, "[%s] black abandoned, white wins", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "black abandoned, white wins")
 				r.game.Resign(octad.Black)
 			}
 			r.stateMu.Unlock()
@@ -310,7 +324,7 @@ func (r *Instance) handleGameOngoing() {
 				continue
 			}
 
-			util.DebugFlag("room", str.CRoom, "[%s] human idle (no move this game), abandoning bot game", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "human idle (no move this game), abandoning bot game")
 
 			// resign the idle human so the game has a terminal outcome, then run
 			// the same abandon path the disconnect timer uses
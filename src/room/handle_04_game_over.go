@@ -68,6 +68,12 @@ var nextGameReadyBeat = 600 * time.Millisecond
 // with no countdown, shortened to the disconnect grace once the player leaves,
 // then closes.
 func (r *Instance) handleGameOver() {
+	// a tournament game or simul board is a single game: nothing to rematch
+	if r.singleGame() {
+		r.handleTournamentGameOver()
+		return
+	}
+
 	// an undecided race-to match auto-advances instead of negotiating a rematch
 	if decided, _ := r.MatchDecided(); r.params.RaceTo > 0 && !decided {
 		r.handleMatchInterlude()
@@ -159,7 +165,7 @@ func (r *Instance) handleGameOver() {
 		select {
 		case <-closeTimeout.C:
 			// the window lapsed (or a departed player's grace elapsed): close
-			util.DebugFlag("room", str.CRoom, "[%s] no rematch, room over", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "no rematch, room over")
 			err := r.event(EventNoRematch)
 			if err != nil {
 				panic(err)
@@ -176,7 +182,7 @@ func (r *Instance) handleGameOver() {
 				if deadline.After(fullDeadline) {
 					deadline = fullDeadline
 				}
-				util.DebugFlag("room", str.CRoom, "[%s] player left finished game, shortening close window", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "player left finished game, shortening close window")
 				resetTimeout(deadline)
 				if !isBot {
 					r.setRematchDeadline(deadline)
@@ -185,7 +191,7 @@ func (r *Instance) handleGameOver() {
 			case bothConnected && shortened:
 				shortened = false
 				deadline = fullDeadline
-				util.DebugFlag("room", str.CRoom, "[%s] player returned to finished game, restoring close window", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "player returned to finished game, restoring close window")
 				resetTimeout(deadline)
 				if !isBot {
 					r.setRematchDeadline(deadline)
@@ -287,9 +293,16 @@ func (r *Instance) resetForNextGameLocked(resetScore bool) error {
 	// and the interlude's "ready for the next game" flags, which belong to the
 	// pause that just ended
 	r.nextGame = player.NewAgreement()
+	// berserk is a per-game choice
+	r.berserk = player.NewAgreement()
 	// clear any draw-offer state so it can't carry into the next game
 	r.draw = player.NewAgreement()
 	r.drawOffer = octad.NoColor
+	// and the takeback request and allowance, which are per game too
+	r.takebackOffer = octad.NoColor
+	r.takebacks = 0
+	// and the premove queues, set against the finished game's positions
+	r.premoves = nil
 	// the new game has no human move yet; reset engagement so the
 	// next game-over re-evaluates idle-abandon fresh
 	r.humanMoved = false
@@ -345,7 +358,7 @@ func (r *Instance) handleMatchInterlude() {
 	// routine to handleGameReady (which sweeps the deploy channel and re-enters
 	// the deploy/first-move flow).
 	advance := func() {
-		util.DebugFlag("room", str.CRoom, "[%s] match continues, starting next game", r.ID)
+		r.log().DebugFlag("room", str.CRoom, "match continues, starting next game")
 
 		r.stateMu.Lock()
 		err := r.resetForNextGameLocked(false)
@@ -375,7 +388,7 @@ func (r *Instance) handleMatchInterlude() {
 				// a player is missing at the deadline: hold the advance for one
 				// grace so a momentary drop doesn't forfeit the match
 				graceArmed = true
-				util.DebugFlag("room", str.CRoom, "[%s] player missing at match interlude end, holding for grace", r.ID)
+				r.log().DebugFlag("room", str.CRoom, "player missing at match interlude end, holding for grace")
 				timer.Reset(rematchDisconnectGrace)
 				continue
 			}
@@ -384,7 +397,7 @@ func (r *Instance) handleMatchInterlude() {
 			// mid-match game-over broadcast carried no RoomOver, so tell the
 			// clients the room is closing before the abandon transition (whose
 			// handleRoomOver path is silent once a game has finished).
-			util.DebugFlag("room", str.CRoom, "[%s] player left mid-match, room over", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "player left mid-match, room over")
 			payload := proto.GameOverPayload{
 				Status:   "PLAYER LEFT THE MATCH - MATCH OVER",
 				RoomOver: true,
@@ -445,7 +458,7 @@ func (r *Instance) handleMatchInterlude() {
 			// both players want on with it: hold one beat so the second check
 			// registers on both screens, then start the next game early. Bail
 			// if the room is being torn down under us.
-			util.DebugFlag("room", str.CRoom, "[%s] both players ready, starting next game early", r.ID)
+			r.log().DebugFlag("room", str.CRoom, "both players ready, starting next game early")
 			t := time.NewTimer(nextGameReadyBeat)
 			select {
 			case <-t.C:
@@ -0,0 +1,45 @@
+package room
+
+import (
+	"github.com/dechristopher/lio/metrics"
+	"github.com/dechristopher/lio/variant"
+)
+
+// Game lifecycle counters for /metrics, labelled by the variant's speed class
+// (bullet / blitz / rapid / unlimited / correspondence) rather than its exact
+// control: the class is what an alert is written against, and it keeps the
+// label set small and fixed.
+var (
+	gamesStarted = metrics.NewCounterVec("lio_games_started_total",
+		"Games that entered play, by speed class.", "category")
+	gamesFinished = metrics.NewCounterVec("lio_games_finished_total",
+		"Games that reached a result, by speed class.", "category")
+
+	persistFailures = metrics.NewCounter("lio_persister_tick_failures_total",
+		"Snapshot persister writes and deletes that failed and were queued for retry.")
+)
+
+// GameStarted counts a game beginning. A room counts its own games as its
+// state machine enters play (see event); a correspondence game, which has no
+// room, is counted by its package when the second player joins.
+func GameStarted(v variant.Variant) {
+	gamesStarted.With(v.SpeedGroup().String()).Inc()
+}
+
+// GameFinished counts a game reaching a result, the counterpart of
+// GameStarted. Aborted rooms never reach one and are not counted.
+func GameFinished(v variant.Variant) {
+	gamesFinished.With(v.SpeedGroup().String()).Inc()
+}
+
+// CountByState returns the number of live rooms in each state, keyed by the
+// state's name. It walks the room map like Count, so it is a sample rather
+// than a consistent snapshot.
+func CountByState() map[string]int {
+	counts := make(map[string]int)
+	rooms.Range(func(_, v interface{}) bool {
+		counts[string(v.(*Instance).State())]++
+		return true
+	})
+	return counts
+}
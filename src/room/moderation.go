@@ -123,8 +123,7 @@ func (r *Instance) forfeitSeat(userID int64) bool {
 		// function comment. game_over / room_over: already reaping.
 	}
 
-	util.DebugFlag("room", str.CRoom, "[%s] moderation: banned user %d seat %s in state %s",
-		r.ID, userID, seatUID, state)
+	r.log().DebugFlag("room", str.CRoom, "moderation: banned user %d seat %s in state %s", userID, seatUID, state)
 	return true
 }
 
@@ -190,7 +189,7 @@ func CloseRoom(roomID string) bool {
 		return false
 	}
 
-	util.Info(str.CRoom, "[%s] room closed by moderator (state %s)", roomID, state)
+	util.With(util.RoomID(roomID)).Info(str.CRoom, "room closed by moderator (state %s)", state)
 	return true
 }
 
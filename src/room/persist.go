@@ -16,7 +16,6 @@ import (
 	"github.com/dechristopher/lio/player"
 	"github.com/dechristopher/lio/str"
 	"github.com/dechristopher/lio/title"
-	"github.com/dechristopher/lio/util"
 	"github.com/dechristopher/lio/variant"
 )
 
@@ -52,6 +51,7 @@ type PersistedRoom struct {
 	State            State  `json:"state"`
 	Public           bool   `json:"public,omitempty"`
 	Rated            bool   `json:"rated,omitempty"`
+	BotPool          bool   `json:"botPool,omitempty"`
 	JoinToken        string `json:"jt,omitempty"`
 	CancelToken      string `json:"ct,omitempty"`
 
@@ -64,6 +64,13 @@ type PersistedRoom struct {
 	Deploy     bool            `json:"deploy,omitempty"`
 	Casual     bool            `json:"casual,omitempty"`
 	BotPersona string          `json:"botPersona,omitempty"`
+	// tournament pairing (Params.Tournament/TournamentName/Berserk)
+	Tournament     string `json:"tournament,omitempty"`
+	TournamentName string `json:"tournamentName,omitempty"`
+	Berserk        bool   `json:"berserk,omitempty"`
+	// simul board (Params.Simul/SimulHost)
+	Simul     string      `json:"simul,omitempty"`
+	SimulHost octad.Color `json:"simulHost,omitempty"`
 
 	White player.Snapshot `json:"white"`
 	Black player.Snapshot `json:"black"`
@@ -84,9 +91,19 @@ type PersistedRoom struct {
 	DrawBlack    bool        `json:"drawB,omitempty"`
 	RematchWhite bool        `json:"rematchW,omitempty"`
 	RematchBlack bool        `json:"rematchB,omitempty"`
+
+	// the standing takeback request and the takebacks granted this game
+	TakebackOffer octad.Color `json:"takebackOffer,omitempty"`
+	Takebacks     int         `json:"takebacks,omitempty"`
 	// per-seat "skip the interlude" readiness of an undecided match's pause
 	NextGameWhite bool `json:"nextGameW,omitempty"`
 	NextGameBlack bool `json:"nextGameB,omitempty"`
+	// per-seat berserk of a tournament game; the restored clock carries the
+	// charge, but a game_ready restore builds a fresh one
+	BerserkWhite bool `json:"berserkW,omitempty"`
+	BerserkBlack bool `json:"berserkB,omitempty"`
+	// the tournament game's Result was already published
+	ResultSent bool `json:"resultSent,omitempty"`
 
 	// absolute deadlines; consumed relative on restore (a lapsed window is
 	// floored/refreshed rather than instantly expiring the room)
@@ -135,6 +152,7 @@ func (r *Instance) Persist() ([]byte, bool) {
 		CreatorTitleName: r.params.CreatorTitle.Name,
 		Public:           r.public,
 		Rated:            r.params.Rated,
+		BotPool:          r.params.BotPool,
 		JoinToken:        r.joinToken,
 		CancelToken:      r.cancelToken,
 
@@ -145,6 +163,13 @@ func (r *Instance) Persist() ([]byte, bool) {
 		Casual:     r.params.Casual,
 		BotPersona: r.params.BotPersona,
 
+		Tournament:     r.params.Tournament,
+		TournamentName: r.params.TournamentName,
+		Berserk:        r.params.Berserk,
+
+		Simul:     r.params.Simul,
+		SimulHost: r.params.SimulHost,
+
 		White: wp.Snapshot(),
 		Black: bp.Snapshot(),
 
@@ -155,9 +180,16 @@ func (r *Instance) Persist() ([]byte, bool) {
 		RematchWhite: r.rematch.AgreedBy(octad.White),
 		RematchBlack: r.rematch.AgreedBy(octad.Black),
 
+		TakebackOffer: r.takebackOffer,
+		Takebacks:     r.takebacks,
+
 		NextGameWhite: r.nextGame.AgreedBy(octad.White),
 		NextGameBlack: r.nextGame.AgreedBy(octad.Black),
 
+		BerserkWhite: r.berserk.AgreedBy(octad.White),
+		BerserkBlack: r.berserk.AgreedBy(octad.Black),
+		ResultSent:   r.resultSent.Load(),
+
 		RematchDeadline:  r.rematchDeadline,
 		NextGameDeadline: r.nextGameDeadline,
 	}
@@ -187,7 +219,7 @@ func (r *Instance) Persist() ([]byte, bool) {
 
 	data, err := json.Marshal(p)
 	if err != nil {
-		util.Error(str.CRoom, "[%s] snapshot marshal failed: %v", r.ID, err)
+		r.log().Error(str.CRoom, "snapshot marshal failed: %v", err)
 		return nil, false
 	}
 	return data, true
@@ -234,10 +266,18 @@ func Rehydrate(data []byte) (*Instance, error) {
 		},
 		Public:     p.Public,
 		Rated:      p.Rated,
+		BotPool:    p.BotPool,
 		Deploy:     p.Deploy,
 		RaceTo:     p.RaceTo,
 		Casual:     p.Casual,
 		BotPersona: p.BotPersona,
+
+		Tournament:     p.Tournament,
+		TournamentName: p.TournamentName,
+		Berserk:        p.Berserk,
+
+		Simul:     p.Simul,
+		SimulHost: p.SimulHost,
 	}
 
 	var g *game.OctadGame
@@ -276,9 +316,13 @@ func Rehydrate(data []byte) (*Instance, error) {
 		players:   players,
 		rematch:   player.NewAgreement(),
 		nextGame:  player.NewAgreement(),
+		berserk:   player.NewAgreement(),
 		draw:      player.NewAgreement(),
 		drawOffer: p.DrawOffer,
 
+		takebackOffer: p.TakebackOffer,
+		takebacks:     p.Takebacks,
+
 		humanMoved: p.HumanMoved,
 		public:     p.Public,
 
@@ -311,6 +355,17 @@ func Rehydrate(data []byte) (*Instance, error) {
 	if p.NextGameBlack {
 		r.nextGame.Agree(octad.Black)
 	}
+	if p.BerserkWhite {
+		r.berserk.Agree(octad.White)
+	}
+	if p.BerserkBlack {
+		r.berserk.Agree(octad.Black)
+	}
+	r.resultSent.Store(p.ResultSent)
+	if p.State == StateGameReady {
+		// the fresh game's clock has not been charged
+		r.applyBerserkLocked()
+	}
 
 	switch p.State {
 	case StateGameOngoing:
@@ -319,6 +374,11 @@ func Rehydrate(data []byte) (*Instance, error) {
 		r.resumeClockPending = true
 
 	case StateGameOver:
+		if p.Tournament != "" || p.Simul != "" {
+			// a tournament game's (or simul board's) short result window
+			// simply runs again
+			break
+		}
 		if decided, _ := r.MatchDecided(); p.RaceTo > 0 && !decided {
 			// undecided race-to interlude: a deadline that lapsed during the
 			// restart is refreshed to a full interlude so returning players are
@@ -382,7 +442,7 @@ func (r *Instance) StartRehydrated() error {
 
 	go r.routine()
 
-	util.Info(str.CRoom, "[%s] room rehydrated (state %s)", r.ID, r.State())
+	r.log().Info(str.CRoom, "room rehydrated (state %s)", r.State())
 	return nil
 }
 
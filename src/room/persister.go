@@ -23,6 +23,9 @@ type snapshotStore interface {
 	DeleteRoom(id string) error
 	// LoadRooms returns every stored snapshot keyed by room id (boot).
 	LoadRooms() (map[string][]byte, error)
+	// LoadRoom returns one room's snapshot, found=false when there is none
+	// (adoption, see Adopt).
+	LoadRoom(id string) (data []byte, found bool, err error)
 }
 
 const (
@@ -137,29 +140,15 @@ func RehydrateAll(s snapshotStore) int {
 
 	restored := 0
 	for id, data := range snaps {
-		// staleness check on the envelope alone, so a snapshot too old to be
-		// worth restoring is dropped without a full rebuild
-		var envelope struct {
-			At time.Time `json:"at"`
-		}
-		if err := json.Unmarshal(data, &envelope); err != nil ||
-			time.Since(envelope.At) > maxSnapshotAge {
-			util.Info(str.CRoom, "[%s] dropping stale/unreadable snapshot", id)
-			_ = s.DeleteRoom(id)
+		// on a multi-node deployment every node sees every snapshot; restore
+		// only the rooms no live node already holds. One whose claim failed
+		// keeps its snapshot, for Adopt to restore on the first reconnect.
+		if ok, _ := claimRoom(id); !ok {
 			continue
 		}
-
-		r, err := Rehydrate(data)
-		if err != nil {
-			util.Error(str.CRoom, "[%s] rehydration failed, dropping snapshot: %v", id, err)
-			_ = s.DeleteRoom(id)
-			continue
+		if _, ok := restore(s, id, data); ok {
+			restored++
 		}
-		if err := r.StartRehydrated(); err != nil {
-			util.Error(str.CRoom, "[%s] rehydrated room failed to start: %v", id, err)
-			continue
-		}
-		restored++
 	}
 
 	if restored > 0 {
@@ -168,6 +157,37 @@ func RehydrateAll(s snapshotStore) int {
 	return restored
 }
 
+// restore rebuilds one room from its snapshot and starts it. Stale and
+// unreadable snapshots are dropped (and deleted), and so is this node's claim
+// on them.
+func restore(s snapshotStore, id string, data []byte) (*Instance, bool) {
+	// staleness check on the envelope alone, so a snapshot too old to be
+	// worth restoring is dropped without a full rebuild
+	var envelope struct {
+		At time.Time `json:"at"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil ||
+		time.Since(envelope.At) > maxSnapshotAge {
+		util.With(util.RoomID(id)).Info(str.CRoom, "dropping stale/unreadable snapshot")
+		_ = s.DeleteRoom(id)
+		releaseRoom(id)
+		return nil, false
+	}
+
+	r, err := Rehydrate(data)
+	if err != nil {
+		util.With(util.RoomID(id)).Error(str.CRoom, "rehydration failed, dropping snapshot: %v", err)
+		_ = s.DeleteRoom(id)
+		releaseRoom(id)
+		return nil, false
+	}
+	if err := r.StartRehydrated(); err != nil {
+		util.With(util.RoomID(id)).Error(str.CRoom, "rehydrated room failed to start: %v", err)
+		return nil, false
+	}
+	return r, true
+}
+
 func (p *persister) mark(r *Instance) {
 	p.mu.Lock()
 	p.dirty[r.ID] = r
@@ -181,13 +201,16 @@ func (p *persister) forget(roomID string) {
 	p.mu.Unlock()
 }
 
-// run is the persister loop: flush the dirty set every tick, and periodically
-// re-mark all live rooms so idle rooms keep their snapshot TTLs fresh.
+// run is the persister loop: flush the dirty set every tick, periodically
+// re-mark all live rooms so idle rooms keep their snapshot TTLs fresh, and
+// renew this node's room leases (directory.go).
 func (p *persister) run() {
 	tick := time.NewTicker(persistTick)
 	defer tick.Stop()
 	sweep := time.NewTicker(sweepInterval)
 	defer sweep.Stop()
+	lease := time.NewTicker(leaseTick)
+	defer lease.Stop()
 
 	for {
 		select {
@@ -200,6 +223,8 @@ func (p *persister) run() {
 				p.mark(v.(*Instance))
 				return true
 			})
+		case <-lease.C:
+			renewLeases()
 		}
 	}
 }
@@ -231,6 +256,7 @@ func (p *persister) flush() {
 		if len(batch) > 0 {
 			if err := p.store.PutRooms(batch, snapshotTTL); err != nil {
 				util.Error(str.CRoom, "snapshot write failed (%d rooms), will retry: %v", len(batch), err)
+				persistFailures.Inc()
 				p.mu.Lock()
 				for id, r := range dirty {
 					if _, gone := p.deleted[id]; !gone {
@@ -244,11 +270,15 @@ func (p *persister) flush() {
 
 	for id := range deleted {
 		if err := p.store.DeleteRoom(id); err != nil {
-			util.Error(str.CRoom, "[%s] snapshot delete failed, will retry: %v", id, err)
+			util.With(util.RoomID(id)).Error(str.CRoom, "snapshot delete failed, will retry: %v", err)
+			persistFailures.Inc()
 			p.mu.Lock()
 			p.deleted[id] = struct{}{}
 			p.mu.Unlock()
+			continue
 		}
+		// only once the snapshot is gone, so no other node can adopt it
+		releaseRoom(id)
 	}
 }
 
@@ -48,6 +48,11 @@ func (m *memStore) LoadRooms() (map[string][]byte, error) {
 	return out, nil
 }
 
+func (m *memStore) LoadRoom(id string) ([]byte, bool, error) {
+	data, ok := m.get(id)
+	return data, ok, nil
+}
+
 func (m *memStore) get(id string) ([]byte, bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
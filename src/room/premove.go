@@ -0,0 +1,198 @@
+package room
+
+import (
+	"github.com/dechristopher/octad/v2"
+	"github.com/looplab/fsm"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/message"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// Premoves. While the opponent is on move a player may queue a premove, or a
+// conditional line ("if they play X, I reply Y"), and the room routine plays
+// it the instant the opponent's move lands: in fast bullet the network round
+// trip otherwise decides games. A premove is an ordinary move in every other
+// respect — validated, broadcast, persisted — except that its clock flip
+// charges the configured premove think time (clock.Premove), not the moment
+// it took. A step that meets a move it does not answer, or a position it is
+// illegal in, discards the whole queue, and the player is told.
+
+// maxPremoveSteps caps a conditional line. Bullet lines worth queueing are a
+// few moves deep; the cap bounds the validation work a single frame can ask of
+// the room.
+const maxPremoveSteps = 8
+
+// SetPremoves replaces the requesting player's premove queue; an empty queue
+// cancels it. Only a seated player may queue, during an ongoing game, while
+// the opponent is on move — on their own move they simply move. A
+// conditional line is checked against the position it starts from, so a
+// queue the room accepts can only be discarded by the opponent playing
+// something else. The room's answer is acknowledged to the player either way:
+// the queue it now holds, or Dropped when the queue was refused.
+func (r *Instance) SetPremoves(meta channel.SocketContext, steps []proto.PremoveStep) {
+	if r.State() != StateGameOngoing {
+		return
+	}
+
+	r.stateMu.Lock()
+	_, color := r.players.Lookup(meta.UID)
+	if color == octad.NoColor {
+		r.stateMu.Unlock()
+		return
+	}
+	gameID := r.game.ID
+	valid := r.game.Outcome() == octad.NoOutcome && r.game.ToMove != color &&
+		r.premovesValidLocked(steps)
+	if valid && len(steps) > 0 {
+		if r.premoves == nil {
+			r.premoves = make(map[octad.Color][]proto.PremoveStep, 2)
+		}
+		r.premoves[color] = steps
+	} else {
+		delete(r.premoves, color)
+	}
+	r.stateMu.Unlock()
+
+	r.log().DebugFlag("room", str.CRoom, "%s premoves %+v (valid=%t)", color, steps, valid)
+
+	ack := proto.PremovePayload{GameID: gameID, Dropped: !valid}
+	if valid {
+		ack.Steps = steps
+	}
+	ack.Unicast(channel.SocketContext{Channel: r.ID, UID: meta.UID, MT: 1})
+}
+
+// premovesValidLocked reports whether a premove queue may be registered in the
+// current position, with the opponent on move: within the step cap, a single
+// unconditional step, or a conditional line each of whose moves — the
+// opponent's and the replies — is legal in turn. A lone unconditional premove
+// can't be checked before the move it answers exists; it is checked when it is
+// played. The caller must hold stateMu.
+func (r *Instance) premovesValidLocked(steps []proto.PremoveStep) bool {
+	if len(steps) > maxPremoveSteps {
+		return false
+	}
+	if len(steps) == 1 && steps[0].If == "" {
+		return steps[0].Move != ""
+	}
+
+	line := r.game.Clone()
+	for _, step := range steps {
+		if !playUOI(line, step.If) || !playUOI(line, step.Move) {
+			return false
+		}
+	}
+	return true
+}
+
+// playUOI plays the move given in UOI on g, reporting whether it was legal
+func playUOI(g *octad.Game, uoi string) bool {
+	for _, mov := range g.ValidMoves() {
+		if mov.String() == uoi {
+			return g.Move(mov) == nil
+		}
+	}
+	return false
+}
+
+// playPremoves plays the premove queued by the side now on move, and keeps
+// going while each premove puts another side with one queued on move — both
+// players may hold a queue. It runs on the room routine right after a move
+// lands, and reports whether a premove ended the game (with the transition to
+// make) exactly like tryGameOver.
+func (r *Instance) playPremoves() (bool, *fsm.EventDesc) {
+	for {
+		move, ack := r.nextPremove()
+		if ack != nil {
+			ack.Unicast(move.Ctx)
+		}
+		if ack == nil || ack.Dropped {
+			return false, nil
+		}
+
+		r.log().DebugFlag("room", str.CRoom, "playing premove %s for %s", move.Move.UOI, move.Player)
+
+		// the step was checked legal under the same lock makeMove takes, and
+		// only this routine moves the game, so this cannot fail in practice
+		if !r.makeMove(move) {
+			r.dropPremoves(move.Ctx)
+			return false, nil
+		}
+
+		if isOver, event := r.tryGameOver(move.Ctx, false); isOver {
+			return true, event
+		}
+	}
+}
+
+// nextPremove takes the next step of the premove queue of the side on move and
+// returns it as the move to play, with the acknowledgement of the queue left
+// behind. A step that doesn't answer the move just played, or that is illegal
+// in the position, discards the queue instead: the acknowledgement is then
+// marked Dropped. A nil acknowledgement means no premove is queued.
+func (r *Instance) nextPremove() (*message.RoomMove, *proto.PremovePayload) {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+
+	color := r.game.ToMove
+	queue := r.premoves[color]
+	if len(queue) == 0 || r.game.Outcome() != octad.NoOutcome {
+		return nil, nil
+	}
+
+	uid := r.players[color].ID
+	move := &message.RoomMove{
+		Player:  uid,
+		GameID:  r.game.ID,
+		Move:    proto.MovePayload{UOI: queue[0].Move},
+		Ctx:     channel.SocketContext{Channel: r.ID, RoomID: r.ID, UID: uid, MT: 1},
+		Premove: true,
+	}
+
+	answered := ""
+	if moves := r.game.Moves(); len(moves) > 0 {
+		answered = moves[len(moves)-1].String()
+	}
+	if (queue[0].If != "" && queue[0].If != answered) ||
+		r.legalMoveLocked(move.Move) == nil {
+		delete(r.premoves, color)
+		return move, &proto.PremovePayload{GameID: r.game.ID, Dropped: true}
+	}
+
+	r.premoves[color] = queue[1:]
+	return move, &proto.PremovePayload{GameID: r.game.ID, Steps: queue[1:]}
+}
+
+// dropPremoves discards the queue of the player in meta and tells them
+func (r *Instance) dropPremoves(meta channel.SocketContext) {
+	r.stateMu.Lock()
+	_, color := r.players.Lookup(meta.UID)
+	delete(r.premoves, color)
+	gameID := r.game.ID
+	r.stateMu.Unlock()
+
+	proto.PremovePayload{GameID: gameID, Dropped: true}.Unicast(meta)
+}
+
+// clearPremovesLocked discards every queued premove, returning the sockets to
+// tell (see ackDropped), for a position change no queue was set against — a
+// takeback. The caller must hold stateMu.
+func (r *Instance) clearPremovesLocked() []channel.SocketContext {
+	var owners []channel.SocketContext
+	for color, queue := range r.premoves {
+		if len(queue) > 0 {
+			owners = append(owners, channel.SocketContext{Channel: r.ID, UID: r.players[color].ID, MT: 1})
+		}
+	}
+	r.premoves = nil
+	return owners
+}
+
+// ackDropped tells each of owners their premove queue was discarded
+func (r *Instance) ackDropped(owners []channel.SocketContext, gameID string) {
+	for _, owner := range owners {
+		proto.PremovePayload{GameID: gameID, Dropped: true}.Unicast(owner)
+	}
+}
@@ -0,0 +1,133 @@
+package room
+
+import (
+	"testing"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/message"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// premoveLine returns a conditional premove step valid in the current
+// position: the opponent's index-th legal move, and black's first legal reply.
+func premoveLine(t *testing.T, r *Instance, index int) proto.PremoveStep {
+	t.Helper()
+	line := r.game.Clone()
+	opponent := line.ValidMoves()[index]
+	if err := line.Move(opponent); err != nil {
+		t.Fatal(err)
+	}
+	return proto.PremoveStep{If: opponent.String(), Move: line.ValidMoves()[0].String()}
+}
+
+// whiteMoves plays white's index-th legal move through makeMove, like the room
+// routine does for a move off the wire.
+func whiteMoves(t *testing.T, r *Instance, index int) string {
+	t.Helper()
+	uoi := r.game.ValidMoves()[index].String()
+	if !r.makeMove(&message.RoomMove{
+		Move: proto.MovePayload{UOI: uoi},
+		Ctx:  channel.SocketContext{Channel: r.ID, UID: "w", MT: 1},
+	}) {
+		t.Fatalf("white's %s rejected", uoi)
+	}
+	return uoi
+}
+
+// TestSetPremovesGuards covers registration: only the seated player waiting on
+// the opponent may queue, a conditional line must be legal throughout, and an
+// empty queue cancels.
+func TestSetPremovesGuards(t *testing.T) {
+	r := newTestInstance(t, "w", "b")
+	driveToOngoing(t, r)
+
+	r.SetPremoves(channel.SocketContext{UID: "spectator"}, []proto.PremoveStep{{Move: "a1a2"}})
+	r.SetPremoves(channel.SocketContext{UID: "w"}, []proto.PremoveStep{{Move: "a1a2"}})
+	if len(r.premoves) != 0 {
+		t.Fatalf("queued off-turn or by a non-player: %+v", r.premoves)
+	}
+
+	line := premoveLine(t, r, 0)
+	r.SetPremoves(channel.SocketContext{UID: "b"}, []proto.PremoveStep{line})
+	if q := r.premoves[octad.Black]; len(q) != 1 || q[0] != line {
+		t.Fatalf("valid line not queued: %+v", r.premoves)
+	}
+
+	// a line whose reply is illegal is refused, and replaces the queue
+	r.SetPremoves(channel.SocketContext{UID: "b"}, []proto.PremoveStep{{If: line.If, Move: line.If}})
+	if len(r.premoves[octad.Black]) != 0 {
+		t.Fatalf("illegal line queued: %+v", r.premoves)
+	}
+
+	// a lone unconditional premove waits to be checked until it is played,
+	// and an empty queue cancels it
+	r.SetPremoves(channel.SocketContext{UID: "b"}, []proto.PremoveStep{{Move: line.Move}})
+	if len(r.premoves[octad.Black]) != 1 {
+		t.Fatalf("unconditional premove not queued: %+v", r.premoves)
+	}
+	r.SetPremoves(channel.SocketContext{UID: "b"}, nil)
+	if len(r.premoves[octad.Black]) != 0 {
+		t.Fatalf("cancel left %+v", r.premoves)
+	}
+}
+
+// TestPlayPremovesAnswersMove verifies a queued reply is played the moment the
+// move it answers lands, and charged the configured premove think time.
+func TestPlayPremovesAnswersMove(t *testing.T) {
+	t.Setenv("PREMOVE_THINK", "200ms")
+	r := newTestInstance(t, "w", "b")
+	driveToOngoing(t, r)
+	r.game.Clock.Start()
+	defer r.game.Clock.Stop(false, true)
+
+	line := premoveLine(t, r, 0)
+	r.SetPremoves(channel.SocketContext{UID: "b"}, []proto.PremoveStep{line})
+	whiteMoves(t, r, 0)
+
+	if over, _ := r.playPremoves(); over {
+		t.Fatal("premove ended the game")
+	}
+	moves := r.game.Moves()
+	if len(moves) != 2 || moves[1].String() != line.Move {
+		t.Fatalf("game moves = %v, want the premove %s second", moves, line.Move)
+	}
+	if think := r.game.MoveTimes[1].ThinkMs; think < 200 {
+		t.Fatalf("premove charged %dms, want at least 200ms", think)
+	}
+	if len(r.premoves[octad.Black]) != 0 || r.game.ToMove != octad.White {
+		t.Fatalf("after premove: queue %+v, to move %v", r.premoves, r.game.ToMove)
+	}
+}
+
+// TestPlayPremovesDiscards verifies a line that doesn't answer the move played,
+// and a premove illegal in the position it meets, are discarded unplayed.
+func TestPlayPremovesDiscards(t *testing.T) {
+	r := newTestInstance(t, "w", "b")
+	driveToOngoing(t, r)
+	r.game.Clock.Start()
+	defer r.game.Clock.Stop(false, true)
+
+	r.SetPremoves(channel.SocketContext{UID: "b"}, []proto.PremoveStep{premoveLine(t, r, 0)})
+	whiteMoves(t, r, 1)
+	r.playPremoves()
+	if len(r.game.Moves()) != 1 || len(r.premoves[octad.Black]) != 0 {
+		t.Fatalf("unanswered line: %d plies, queue %+v", len(r.game.Moves()), r.premoves)
+	}
+
+	// black replies by hand, then queues a premove that can never be legal
+	black := r.game.ValidMoves()[0].String()
+	if !r.makeMove(&message.RoomMove{
+		Move: proto.MovePayload{UOI: black},
+		Ctx:  channel.SocketContext{Channel: r.ID, UID: "b", MT: 1},
+	}) {
+		t.Fatalf("black's %s rejected", black)
+	}
+	r.SetPremoves(channel.SocketContext{UID: "b"}, []proto.PremoveStep{{Move: "a1a1"}})
+	whiteMoves(t, r, 0)
+	r.playPremoves()
+	if len(r.game.Moves()) != 3 || len(r.premoves[octad.Black]) != 0 {
+		t.Fatalf("illegal premove: %d plies, queue %+v", len(r.game.Moves()), r.premoves)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/dechristopher/lio/message"
 	"github.com/dechristopher/lio/opening"
 	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/pools"
 	"github.com/dechristopher/lio/store"
 	"github.com/dechristopher/lio/str"
 	"github.com/dechristopher/lio/title"
@@ -129,6 +130,17 @@ type Instance struct {
 	// resetForNextGameLocked, and guarded by stateMu like every other seat state.
 	nextGame player.Agreement
 
+	// berserk records which seats of a tournament game halved their clock
+	// (RequestBerserk). The clock carries the charge itself; this is what the
+	// Result reports and what a rebuilt game's fresh clock is charged from (see
+	// applyBerserkLocked). Reset with the game. Guarded by stateMu.
+	berserk player.Agreement
+
+	// resultSent marks a tournament room's Result as published, so the game
+	// over and the room's teardown never both report it. Atomic because the
+	// first may run with stateMu held and the second without.
+	resultSent atomic.Bool
+
 	// busySeats is the set of seats this room currently contributes to the
 	// package-wide seat index (busy.go), so the room can reconcile exactly what
 	// it added and never remove somebody else's seat. Guarded by stateMu, like
@@ -157,6 +169,30 @@ type Instance struct {
 	draw      player.Agreement
 	drawOffer octad.Color
 
+	// takebackOffer names the color with a standing takeback request awaiting
+	// the human opponent (NoColor when none); like drawOffer it is withdrawn by
+	// the next move and reset with the game. takebacks counts the takebacks
+	// granted in the current game: it caps what a bot grants
+	// (engine.Persona.Takebacks) and rides every board state so clients can
+	// tell a rewound position from a stale one. Guarded by stateMu. See
+	// room/takeback.go.
+	takebackOffer octad.Color
+	takebacks     int
+
+	// premoves holds each seat's premove queue (proto.PremoveStep), played by
+	// the room routine the moment the opponent's move lands. Queues belong to
+	// the position they were set against, so a takeback and a new game clear
+	// them, and they are not persisted: a restart drops them like a page
+	// reload drops a client-side premove. Allocated on first use. Guarded by
+	// stateMu. See room/premove.go.
+	premoves map[octad.Color][]proto.PremoveStep
+
+	// chatMuted is the set of seated uids that have muted the room's player
+	// chat (MuteChat): the chat package stops delivering the other seat's lines
+	// to them. Per room, so it ends with the room, and it survives rematches
+	// because the seats do. Allocated on first use. Guarded by stateMu.
+	chatMuted map[string]bool
+
 	// humanMoved reports whether the human player has made at least one move in
 	// the current game. It is reset to false when a new game begins (Create's
 	// fresh game starts false; a rematch resets it alongside the game swap) and
@@ -307,6 +343,26 @@ type Params struct {
 	// to the full-strength Queen — the pre-persona behavior of every legacy
 	// room and snapshot. Meaningless (and left empty) for human games.
 	BotPersona string
+	// Tournament is the id of the tournament that paired the room, "" for
+	// every other room (see tournament.go). A tournament room plays a single
+	// game — no rematch — publishes its Result for the event's standings, and
+	// archives the game with the tournament reference. TournamentName is the
+	// event's name, for the PGN Event tag and the room page.
+	Tournament     string
+	TournamentName string
+	// Simul is the id of the simul the room is a board of, "" for every other
+	// room (see simul.go). Like a tournament room it plays a single game,
+	// publishes its Result, and archives the game with the simul reference.
+	// SimulHost is the host's colour: that seat counts as always present.
+	Simul     string
+	SimulHost octad.Color
+	// Berserk lets either player halve their clock before their first move
+	// (RequestBerserk) — an Arena tournament option. Defaults to false.
+	Berserk bool
+	// BotPool rates the room in the bot pool (pools.BotCategory) rather than
+	// the human ladder: set when a bot account is challenged, which is the only
+	// way one takes a seat. Meaningless for an unrated room.
+	BotPool bool
 }
 
 // NewParams returns a new parameters object configured using the given variant
@@ -334,7 +390,12 @@ func NewParams(creator player.Identity, variant variant.Variant) Params {
 // exact time control (and thus per game mode): the variant's HTMLName uniquely
 // encodes mode + time control (e.g. "one-two-rapid-deploy"), so a bullet game
 // and a rapid game move separate ratings instead of a single "deploy" pool.
+//
+// A room with a bot account in it rates in that variant's bot pool instead.
 func (params Params) ratingCategory() string {
+	if params.BotPool {
+		return pools.BotCategory(params.GameConfig.Variant.HTMLName)
+	}
 	return params.GameConfig.Variant.HTMLName
 }
 
@@ -382,11 +443,21 @@ func Create(params Params) (*Instance, error) {
 
 	// room URLs are permanent permalinks once a game archives, so a candidate
 	// ID must be unused all-time: absent from the live registry AND from the
-	// archived rooms table (db check degrades to a pass when unconfigured)
+	// archived rooms table (db check degrades to a pass when unconfigured).
+	// On a multi-node deployment it must also be claimable in the room
+	// directory, which both pins the room to this node and rules out a live
+	// room of the same id on another (directory.go). A directory that cannot
+	// be reached refuses the room rather than loop through fresh ids.
 	for {
 		_, exists := rooms.Load(roomId)
 		if !exists && !db.RoomIDExists(roomId) {
-			break
+			ok, err := claimRoom(roomId)
+			if err != nil {
+				return nil, ErrDirectory{}
+			}
+			if ok {
+				break
+			}
 		}
 		roomId = config.GenerateCode(7, config.Base58)
 	}
@@ -424,9 +495,12 @@ func Create(params Params) (*Instance, error) {
 		players:   params.Players,
 		rematch:   player.Agreement{},
 		nextGame:  player.Agreement{},
+		berserk:   player.Agreement{},
 		draw:      player.Agreement{},
 		drawOffer: octad.NoColor,
 
+		takebackOffer: octad.NoColor,
+
 		public:     params.Public,
 		blindColor: params.BlindColor,
 
@@ -487,7 +561,7 @@ func Create(params Params) (*Instance, error) {
 	home.MarkDirty()
 
 	// log room creation
-	util.Info(str.CRoom, "[%s] room created by uid %s", r.ID, params.Creator)
+	r.log().With(util.UID(params.Creator)).Info(str.CRoom, "room created")
 
 	return r, nil
 }
@@ -509,14 +583,14 @@ func (r *Instance) routine() {
 	defer func() {
 		err := recover()
 		if err != nil {
-			util.Error(str.CRoom, "[%s] recovered panicked room routine: %v", r.ID, err)
+			r.log().Error(str.CRoom, "recovered panicked room routine: %v", err)
 		}
 	}()
 	// defer room cleanup, still runs in case of a panic, thanks go
 	defer r.cleanup()
 
 	for {
-		util.DebugFlag("room", str.CRoom, "[%s] room state transition - %s", r.ID, r.State())
+		r.log().DebugFlag("room", str.CRoom, "room state transition - %s", r.State())
 		// snapshot the room at every state boundary (restart persistence); a
 		// non-persistable state (waiting) is skipped inside Persist
 		markDirty(r)
@@ -556,7 +630,7 @@ func (r *Instance) routine() {
 // cleanup finishes, closes, and finalizes the room. It runs exactly once,
 // from the room routine's deferred call, so the close(r.done) is safe.
 func (r *Instance) cleanup() {
-	util.DebugFlag("room", str.CRoom, "[%s] cleaning up", r.ID)
+	r.log().DebugFlag("room", str.CRoom, "cleaning up")
 	// release any goroutines blocked sending into the room's channels
 	// (SendMove / Cancel / engine dispatcher) before tearing anything down
 	close(r.done)
@@ -591,14 +665,28 @@ func (r *Instance) cleanup() {
 	// that never archived a game, or without Postgres); off the routine so
 	// teardown never waits on the database
 	go db.MarkRoomClosed(r.ID)
+	// a tournament room closing without a result (nobody came, or it was
+	// closed by hand) still owes its event one, or the pairing never ends
+	r.stateMu.Lock()
+	r.publishResultLocked(octad.NoOutcome, "", 0)
+	r.stateMu.Unlock()
 }
 
-// event runs a state machine transition using the given EventDesc and args
+// event runs a state machine transition using the given EventDesc and args.
+// Entering play and entering game over are where a game starts and finishes,
+// so the lifecycle counters are taken here rather than in each handler that
+// fires those events.
 func (r *Instance) event(event fsm.EventDesc, args ...interface{}) error {
 	err := r.stateMachine.Event(context.TODO(), event.Name, args)
 	if err != nil {
 		return err
 	}
+	switch State(event.Dst) {
+	case StateGameOngoing:
+		GameStarted(r.params.GameConfig.Variant)
+	case StateGameOver:
+		GameFinished(r.params.GameConfig.Variant)
+	}
 	return nil
 }
 
@@ -782,6 +870,14 @@ func (r *Instance) Join(seat player.Identity, joinToken string) bool {
 	if Engaged(seat.UID, acctID) {
 		return false
 	}
+	// A block between the joiner and whoever holds a seat refuses the join
+	// (db/blocks.go), so a blocked account cannot answer the blocker's open
+	// seek — nor the reverse. Before stateMu for the reason Engaged is: it is a
+	// query, and the seat it reads can only fill, not change hands, before the
+	// lock is taken.
+	if r.SeatBlocked(acctID) {
+		return false
+	}
 
 	r.stateMu.Lock()
 	defer r.stateMu.Unlock()
@@ -935,6 +1031,14 @@ func (r *Instance) setNextGameDeadlineLocked(at time.Time) {
 	r.inInterlude.Store(!at.IsZero())
 }
 
+// log returns a logger whose lines carry the room's ID. It deliberately
+// carries no game ID: the game pointer is guarded by stateMu, and a log line
+// must be writable with or without it held. Lines about a particular game add
+// util.GameID themselves.
+func (r *Instance) log() util.Logger {
+	return util.With(util.RoomID(r.ID))
+}
+
 // State returns the current room state
 func (r *Instance) State() State {
 	return State(r.stateMachine.Current())
@@ -975,6 +1079,12 @@ func (r *Instance) IsRated() bool {
 	return r.params.Rated
 }
 
+// Variant returns the variant the room plays. Set once at creation; safe to
+// read without the lock.
+func (r *Instance) Variant() variant.Variant {
+	return r.params.GameConfig.Variant
+}
+
 // HasBot returns true if the room is configured with a bot player
 func (r *Instance) HasBot() bool {
 	r.stateMu.Lock()
@@ -1037,7 +1147,8 @@ func (r *Instance) playerInfo(color octad.Color) (id string, isBot bool) {
 }
 
 // bothPlayersConnected reports whether every human seat currently holds a live
-// connection on the room channel; a bot seat counts as always-connected. It is
+// connection on the room channel; a bot seat (and a simul host's, see
+// simul.go) counts as always-connected. It is
 // the presence primitive shared by the abandon detection (handleGameOngoing) and
 // the engine-move gating (handleGameReady): we never dispatch an engine search,
 // or keep a game alive, for a position nobody is watching. playerInfo locks
@@ -1046,7 +1157,7 @@ func (r *Instance) playerInfo(color octad.Color) (id string, isBot bool) {
 func (r *Instance) bothPlayersConnected() bool {
 	return util.BothColors(func(color octad.Color) bool {
 		id, isBot := r.playerInfo(color)
-		if isBot {
+		if isBot || r.simulHostSeat(color) {
 			return true
 		}
 		return channel.Map.GetSockMap(r.ID).Connected(id)
@@ -1152,14 +1263,14 @@ func (r *Instance) SendMove(move *message.RoomMove) {
 	// shutdown drain: the final snapshot is (being) captured; nothing may
 	// mutate after it. The client resyncs the dropped move on reconnect.
 	if Draining() {
-		util.DebugFlag("room", str.CRoom, "[%s] dropped move %s: draining", r.ID, move.Move.UOI)
+		r.log().DebugFlag("room", str.CRoom, "dropped move %s: draining", move.Move.UOI)
 		return
 	}
 
 	switch r.State() {
 	case StateGameReady, StateGameOngoing:
 	default:
-		util.DebugFlag("room", str.CRoom, "[%s] dropped move %s: room in state %s", r.ID, move.Move.UOI, r.State())
+		r.log().DebugFlag("room", str.CRoom, "dropped move %s: room in state %s", move.Move.UOI, r.State())
 		return
 	}
 
@@ -1170,7 +1281,7 @@ func (r *Instance) SendMove(move *message.RoomMove) {
 	}
 	r.stateMu.Unlock()
 	if decided {
-		util.DebugFlag("room", str.CRoom, "[%s] dropped move %s: game already decided", r.ID, move.Move.UOI)
+		r.log().DebugFlag("room", str.CRoom, "dropped move %s: game already decided", move.Move.UOI)
 		return
 	}
 
@@ -1202,12 +1313,12 @@ func (r *Instance) SendMove(move *message.RoomMove) {
 // arch/DEPLOY_REMATCH_RACES.md (race #2).
 func (r *Instance) SubmitDeploy(deploy *message.RoomDeploy) {
 	if Draining() {
-		util.DebugFlag("room", str.CRoom, "[%s] dropped deploy from %s: draining", r.ID, deploy.Player)
+		r.log().DebugFlag("room", str.CRoom, "dropped deploy from %s: draining", deploy.Player)
 		return
 	}
 
 	if r.State() != StateDeploy {
-		util.DebugFlag("room", str.CRoom, "[%s] dropped deploy from %s: room not in deploy phase", r.ID, deploy.Player)
+		r.log().DebugFlag("room", str.CRoom, "dropped deploy from %s: room not in deploy phase", deploy.Player)
 		return
 	}
 
@@ -1219,7 +1330,7 @@ func (r *Instance) SubmitDeploy(deploy *message.RoomDeploy) {
 		// buffer full or the deploy phase is ending (deployAndStart no longer
 		// reading): the submission is stale, so drop it rather than block the WS
 		// read loop. The client resyncs from the reveal / a board query.
-		util.DebugFlag("room", str.CRoom, "[%s] dropped deploy from %s: phase ending or buffer full", r.ID, deploy.Player)
+		r.log().DebugFlag("room", str.CRoom, "dropped deploy from %s: phase ending or buffer full", deploy.Player)
 	}
 }
 
@@ -1467,7 +1578,8 @@ func (r *Instance) currentGameStateMessageLocked(addLast bool, gameStart bool) [
 		// carry the game identity so a client that missed the single game-start
 		// broadcast recognizes the new game from any later snapshot (its
 		// gs/ply staleness guards would otherwise drop it forever)
-		GameID: r.game.ID,
+		GameID:    r.game.ID,
+		Takebacks: r.takebacks,
 	}
 
 	// set legal moves if we're in GameReady or GameOngoing
@@ -1546,8 +1658,10 @@ func (r *Instance) isTurn(move *message.RoomMove) bool {
 func (r *Instance) makeMove(move *message.RoomMove) bool {
 	r.stateMu.Lock()
 
-	// don't allow engine dispatched moves not for this game
-	if move.GameID != "" && move.GameID != r.game.ID {
+	// don't allow engine dispatched moves not for this game, or searched in a
+	// position a takeback has since unwound
+	if (move.GameID != "" && move.GameID != r.game.ID) ||
+		(move.OFEN != "" && move.OFEN != r.game.OFEN()) {
 		r.stateMu.Unlock()
 		return false
 	}
@@ -1565,7 +1679,7 @@ func (r *Instance) makeMove(move *message.RoomMove) bool {
 		} else {
 			// engine gave bad move, major issue
 			// TODO handle this somehow if we ever see it
-			util.Error(str.CRoom, "engine provided bad move ofen=%s move=%s", ofen, move.Move.UOI)
+			r.log().Error(str.CRoom, "engine provided bad move ofen=%s move=%s", ofen, move.Move.UOI)
 		}
 		return false
 	}
@@ -1574,7 +1688,7 @@ func (r *Instance) makeMove(move *message.RoomMove) bool {
 	if errMove := r.game.Move(mov); errMove != nil {
 		r.stateMu.Unlock()
 		// bad if this happens
-		util.Error(str.CRoom, "bad move given err=%s", errMove.Error())
+		r.log().Error(str.CRoom, "bad move given err=%s", errMove.Error())
 		return false
 	}
 
@@ -1593,12 +1707,20 @@ func (r *Instance) makeMove(move *message.RoomMove) bool {
 		r.drawOffer = octad.NoColor
 		r.draw = player.NewAgreement()
 	}
+	// and any standing takeback request, which named the move just superseded
+	r.takebackOffer = octad.NoColor
 
 	// flip the game clock. This blocks briefly on the clock acknowledgement,
 	// but the clock has its own mutex and never calls back into the room, so
 	// holding stateMu here cannot deadlock. The ack carries the ply's timing,
 	// recorded parallel to the move list for replay/analysis and the archive.
-	ack := r.flipClock()
+	// A premove is charged the premove think time instead of the moment it
+	// actually took (see room/premove.go).
+	cmd := clock.Flip
+	if move.Premove {
+		cmd = clock.Premove
+	}
+	ack := r.flipClock(cmd)
 	r.game.MoveTimes = append(r.game.MoveTimes, game.MoveTime{
 		ThinkMs: ack.Think.Milli(),
 		ClockMs: ack.Remaining.Milli(),
@@ -1654,7 +1776,7 @@ func (r *Instance) botPersona() engine.Persona {
 // by handleGameReady), so it locks to read the game fields it needs.
 func (r *Instance) requestEngineMove() {
 	r.stateMu.Lock()
-	depth, budget := r.calcSearchLocked(r.game.ToMove)
+	depth, budget, remaining := r.calcSearchLocked(r.game.ToMove)
 	req := dispatch.EngineRequest{
 		Ctx: channel.SocketContext{
 			Channel: r.ID,
@@ -1672,6 +1794,7 @@ func (r *Instance) requestEngineMove() {
 		History: r.game.OFENHistory(),
 		Depth:   depth,
 		Budget:  budget,
+		Clock:   remaining,
 		Persona: r.botPersona(),
 	}
 	r.stateMu.Unlock()
@@ -1687,6 +1810,7 @@ func (r *Instance) requestEngineMove() {
 // already ended (see handleDeploy).
 func (r *Instance) requestEngineDeploy(botColor octad.Color, ch chan *message.RoomBotDeploy) {
 	go dispatch.SubmitDeploy(dispatch.DeployRequest{
+		RoomID:          r.ID,
 		Color:           botColor,
 		Random:          r.botPersona().RandomDeploy,
 		ResponseChannel: ch,
@@ -1710,8 +1834,9 @@ func (r *Instance) legalMoveLocked(move proto.MovePayload) *octad.Move {
 // acknowledgement, returning the flip's per-move timing (think time charged +
 // remaining clock). A skipped flip — stopped or paused clock — reports the
 // mover's current remaining time with zero think time, so the caller can keep
-// its per-ply timing record 1:1 with the move list.
-func (r *Instance) flipClock() clock.FlipAck {
+// its per-ply timing record 1:1 with the move list. cmd is clock.Flip, or
+// clock.Premove for a move played from a premove queue.
+func (r *Instance) flipClock(cmd clock.Command) clock.FlipAck {
 	// don't flip a clock that has already stopped on a flag (the flagged state
 	// is handled separately via the clock StateChannel), and don't flip a
 	// paused clock at all: paused means no clock goroutine is consuming
@@ -1730,7 +1855,7 @@ func (r *Instance) flipClock() clock.FlipAck {
 	}
 	ackChannel := r.game.Clock.GetAck()
 	// handle clock flipping
-	r.game.Clock.ControlChannel <- clock.Flip
+	r.game.Clock.ControlChannel <- cmd
 	// wait for acknowledgement
 	return <-ackChannel
 }
@@ -1765,23 +1890,43 @@ const (
 )
 
 // calcSearchLocked returns the depth ceiling and time budget for an engine
-// search on behalf of color. The depth ceiling comes from the time control;
-// the budget comes from the bot's remaining clock: time above the configured
-// reserve (the persona's pacing knob, see engine.Persona.TimeReserve) is spread across a
-// horizon of future moves, plus any per-move increment/delay regain. The
-// caller must hold stateMu (it reads the game's variant and clock).
-func (r *Instance) calcSearchLocked(color octad.Color) (int, time.Duration) {
-	// depth 7 is about the best we can do in a reasonable timeframe
-	// on a good CPU, but it won't work well for bullet
-	var depth int
-
+// search on behalf of color (see BotSearchLimits). The third result is the
+// bot's remaining clock, which queues the request in the engine's worker pool
+// (0 for a casual game, which has none to run out). The caller must hold
+// stateMu (it reads the game's variant and clock).
+func (r *Instance) calcSearchLocked(color octad.Color) (int, time.Duration, time.Duration) {
 	// casual games play at full strength with a fixed budget: the infinite
-	// clock makes the pacing arithmetic below meaningless
+	// clock makes the pacing arithmetic meaningless
 	if r.params.Casual {
-		return 7, botCasualBudget
+		return 7, botCasualBudget, 0
+	}
+
+	var remaining time.Duration
+	clockState := r.game.Clock.State(true)
+	if color == octad.White {
+		remaining = time.Duration(clockState.WhiteTime.Centi()) * clock.Centisecond
+	} else {
+		remaining = time.Duration(clockState.BlackTime.Centi()) * clock.Centisecond
 	}
 
-	control := r.game.Variant.Control
+	depth, budget := BotSearchLimits(r.game.Variant.Control, remaining, r.botPersona())
+
+	r.log().DebugFlag("engine", str.CEng, "selected depth %d budget %s (%s remaining)",
+		depth, budget, remaining)
+	return depth, budget, remaining
+}
+
+// BotSearchLimits is the bot's search policy on a timed control: the depth
+// ceiling comes from the time control, and the budget from the bot's remaining
+// clock — time above the configured reserve (the persona's pacing knob, see
+// engine.Persona.TimeReserve) is spread across a horizon of future moves, plus
+// any per-move increment/delay regain. It is exported for the self-play
+// harness (package selfplay), which must pace its bots exactly as a room does
+// for the ratings it measures to be the ones players meet.
+func BotSearchLimits(control clock.TimeControl, remaining time.Duration, p engine.Persona) (int, time.Duration) {
+	// depth 7 is about the best we can do in a reasonable timeframe
+	// on a good CPU, but it won't work well for bullet
+	var depth int
 	switch tc := control.Time.Centi(); {
 	case tc >= 6000:
 		depth = 7
@@ -1793,15 +1938,7 @@ func (r *Instance) calcSearchLocked(color octad.Color) (int, time.Duration) {
 		depth = 4
 	}
 
-	var remaining time.Duration
-	clockState := r.game.Clock.State(true)
-	if color == octad.White {
-		remaining = time.Duration(clockState.WhiteTime.Centi()) * clock.Centisecond
-	} else {
-		remaining = time.Duration(clockState.BlackTime.Centi()) * clock.Centisecond
-	}
-
-	reserveFraction := r.botPersona().TimeReserve
+	reserveFraction := p.TimeReserve
 	if reserveFraction <= 0 {
 		reserveFraction = DefaultBotTimeReserve
 	}
@@ -1824,9 +1961,6 @@ func (r *Instance) calcSearchLocked(color octad.Color) (int, time.Duration) {
 	if budget < botMinBudget {
 		budget = botMinBudget
 	}
-
-	util.DebugFlag("engine", str.CEng, "selected depth %d budget %s for game %s (%s remaining)",
-		depth, budget, r.ID, remaining)
 	return depth, budget
 }
 
@@ -1908,12 +2042,26 @@ func (r *Instance) tryGameOver(meta channel.SocketContext, abandoned bool) (bool
 		BlackUserID: r.players[octad.Black].UserID,
 		WhiteName:   seatArchiveName(r.players[octad.White], botPersonaKey),
 		BlackName:   seatArchiveName(r.players[octad.Black], botPersonaKey),
+		// the pool the seats' ratings were captured from, which a bot account's
+		// room does not share with its variant
+		RatingCategory: r.params.ratingCategory(),
 	}
 	// stamp the bot's difficulty on the archived game (games.bot_persona); ""
 	// (NULL) for human games
 	if r.players.HasBot() {
 		archiveRec.BotPersona = botPersonaKey
 	}
+	// a tournament game references its event (games.tournament_id) and is
+	// named after it in the Event tag
+	if r.IsTournament() {
+		archiveRec.TournamentID = r.params.Tournament
+		archiveRec.TournamentName = r.params.TournamentName
+	}
+	// every board of a simul shares its reference (games.simul_id)
+	if r.IsSimul() {
+		archiveRec.SimulID = r.params.Simul
+	}
+	r.publishResultLocked(r.game.Outcome(), archiveRec.Reason, len(r.game.Moves()))
 
 	// build the canonical PGN once, under the lock, from the finished game copy
 	// and the archive record. The same string is archived to object storage and
@@ -1997,7 +2145,7 @@ func (r *Instance) updateScoreLocked() {
 // anonymous human. Unlike the live-view DisplayName (which returns "" for anon
 // so the view can pick You/Anonymous), the archive has no viewer, so anon is
 // spelled out. A nil seat (shouldn't happen at game over) is "Anonymous". The
-// archive-page rebuild reproduces this exactly (see handlers.archiveSeatName)
+// archive-page rebuild reproduces this exactly (see db.archivedSeatName)
 // so the White/Black PGN tags match.
 func seatArchiveName(p *player.Player, personaKey string) string {
 	if p == nil {
@@ -2060,6 +2208,7 @@ func buildArchivePGN(g game.OctadGame, rec db.GameRecord, end time.Time) string
 		RaceTo: rec.RaceTo,
 		VsBot: game.SeatIsBot(g.White, rec.WhiteUserID) ||
 			game.SeatIsBot(g.Black, rec.BlackUserID),
+		Tournament: rec.TournamentName,
 	}, &g.Game, g.MoveTimes)
 }
 
@@ -2085,12 +2234,22 @@ func storeGame(g game.OctadGame, rec db.GameRecord, pgn string, end time.Time) {
 
 	err := store.PGNBucket.PutObject(key, []byte(pgn))
 	if err != nil {
-		util.Error(str.CHMov, str.ERecord, err.Error())
+		util.With(util.RoomID(rec.RoomID), util.GameID(g.ID)).Error(str.CHMov, str.ERecord, err.Error())
 	}
 
 	archiveToDatabase(g, rec, key, end)
 }
 
+// Archive stores a game that finished outside any room actor — a
+// correspondence game, which lives in Postgres between moves rather than in a
+// room — through the same PGN build and the same two writes a room's game
+// takes, so the archive cannot tell the two apart. rec carries what a room
+// would have captured under stateMu; end is the finish time. It runs on the
+// caller's goroutine, which is already off any game's hot path.
+func Archive(g game.OctadGame, rec db.GameRecord, end time.Time) {
+	storeGame(g, rec, buildArchivePGN(g, rec, end), end)
+}
+
 // archiveToDatabase fills the game-derived archive fields and the per-ply
 // analytics rows from the finished game copy, then persists the relational
 // record (a no-op when Postgres is unconfigured). The moves list is packed to a
@@ -2106,8 +2265,12 @@ func archiveToDatabase(g game.OctadGame, rec db.GameRecord, pgnKey string, end t
 	rec.VariantGroup = string(g.Variant.Group)
 	// RatingCategory keys the Glicko-2 update per exact time control (the
 	// variant HTMLName), decoupled from VariantGroup, which stays the speed
-	// group ("rapid"/"deploy") for archive/OG display.
-	rec.RatingCategory = g.Variant.HTMLName
+	// group ("rapid"/"deploy") for archive/OG display. The room captures it
+	// (a bot account's room rates in the bot pool); the variant is the
+	// fallback for a record built without one.
+	if rec.RatingCategory == "" {
+		rec.RatingCategory = g.Variant.HTMLName
+	}
 	rec.Casual = g.Variant.Casual
 	rec.Outcome = g.Outcome().String()
 	rec.Method = int16(g.Method())
@@ -2127,7 +2290,7 @@ func archiveToDatabase(g game.OctadGame, rec db.GameRecord, pgnKey string, end t
 	defer cancel()
 	res, err := db.ArchiveGame(ctx, rec, plies)
 	if err != nil {
-		util.Error(str.CDB, "archive failed error=%s", err.Error())
+		util.With(util.RoomID(rec.RoomID), util.GameID(g.ID)).Error(str.CDB, "archive failed error=%s", err.Error())
 		return
 	}
 	// a rated game moved both players' ratings: refresh the live room's seat
@@ -2193,6 +2356,27 @@ func (r *Instance) SeatUserIDs() (white, black *int64) {
 	return white, black
 }
 
+// SeatBlocked reports whether the account shares a block with either seat's
+// account (db/blocks.go), which bars it from taking the other seat. A failed
+// read counts as a block: a seat is what the block exists to keep. An
+// anonymous joiner (0) is never blocked. Locks stateMu briefly, via
+// SeatUserIDs, and queries outside it.
+func (r *Instance) SeatBlocked(acctID int64) bool {
+	if acctID == 0 {
+		return false
+	}
+	white, black := r.SeatUserIDs()
+	for _, seated := range []*int64{white, black} {
+		if seated == nil {
+			continue
+		}
+		if blocked, err := db.Blocked(acctID, *seated); err != nil || blocked {
+			return true
+		}
+	}
+	return false
+}
+
 // BotSeat reports a bot room's makeup for the timeline's score-vs-persona
 // lookup: whether the engine sits White, the opposing human's account id (nil
 // when that human is anonymous), and the bot's persona key. ok is false for a
@@ -2312,6 +2496,13 @@ func (r *Instance) GenTemplatePayload(id string) message.RoomTemplatePayload {
 		BlindColor:    r.blindColor,
 		RaceTo:        r.params.RaceTo,
 		BotPersona:    r.params.BotPersona,
+
+		Tournament:     r.params.Tournament,
+		TournamentName: r.params.TournamentName,
+		Berserk:        r.params.Berserk,
+		Simul:          r.params.Simul,
+		SimulHost:      r.IsSimul() && playerColor == r.params.SimulHost,
+		Takeback:       r.takebacksAllowedLocked() && playerColor != octad.NoColor,
 	}
 }
 
@@ -2461,7 +2652,8 @@ func genBlackWinState(g *game.OctadGame) (int, string) {
 func (r *Instance) gameOverMessageLocked(abandoned bool, pgn string) []byte {
 	rematchWin := 0
 	nextGameIn := 0
-	if !abandoned && !r.players.HasBot() {
+	// a tournament game or simul board has no rematch to count down to
+	if !abandoned && !r.players.HasBot() && !r.singleGame() {
 		if decided, _ := r.matchDecidedLocked(); r.params.RaceTo > 0 && !decided {
 			nextGameIn = int(matchInterludeWindow.Seconds())
 		} else {
@@ -2527,6 +2719,10 @@ func (r *Instance) buildGameOverMessageLocked(abandoned bool, rematchWin int, ne
 		// the finished game's canonical PGN (live finish only), so the copy
 		// button copies exactly what was archived
 		PGN: pgn,
+		// a tournament game's players head back to the event
+		Tournament: r.params.Tournament,
+		// a simul board's host heads on to their next board
+		Simul: r.params.Simul,
 	}
 
 	return gameOver.Marshal()
@@ -0,0 +1,92 @@
+package room
+
+import (
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+)
+
+// Simul rooms. The simul package seats its host in one ordinary room per
+// participant (Params.Simul set, the host on Params.SimulHost everywhere) and,
+// like a tournament, learns how each board went from the Result published on
+// ResultChannel. A simul room plays one game: no rematch, no race-to.
+//
+// The one thing a simul room does differently is presence. A host cannot hold
+// a socket on every board at once — they are on one board, or the dashboard,
+// at a time — so the host's seat counts as always present, the way a bot seat
+// does: their absence never stalls the start nor arms the abandon timer. What
+// keeps a host honest is their clock, which on each board runs only while it is
+// their move there. The participant's seat is gated like any other player's.
+
+// simulFirstMoveWindow replaces the one-minute first-move box on a simul
+// board. The host makes the first move on every board in turn when they play
+// White, and the participant on every board at once when they play Black; a
+// minute is not enough for a host going round a full room.
+var simulFirstMoveWindow = 5 * time.Minute
+
+// IsSimul reports whether the room is a simul board.
+func (r *Instance) IsSimul() bool {
+	return r.params.Simul != ""
+}
+
+// SimulID returns the id of the simul the room is a board of, "" otherwise.
+// Set once at creation; safe to read without the lock.
+func (r *Instance) SimulID() string {
+	return r.params.Simul
+}
+
+// simulHostSeat reports whether color is a simul host's seat. Params never
+// change after creation, so this needs no lock.
+func (r *Instance) simulHostSeat(color octad.Color) bool {
+	return r.IsSimul() && r.params.SimulHost == color
+}
+
+// singleGame reports whether the room plays exactly one game, with no rematch:
+// a tournament pairing or a simul board.
+func (r *Instance) singleGame() bool {
+	return r.IsTournament() || r.IsSimul()
+}
+
+// firstMoveWindow is how long White has to make the first move before the
+// game is forfeited.
+func (r *Instance) firstMoveWindow() time.Duration {
+	if r.IsSimul() {
+		return simulFirstMoveWindow
+	}
+	return time.Minute
+}
+
+// BoardStatus is a simul board as the host's dashboard shows it.
+type BoardStatus struct {
+	// Live reports the game is still being played; ToMove whose move it is.
+	Live   bool
+	ToMove octad.Color
+	Plies  int
+	// WhiteCenti / BlackCenti are the seats' clocks, in centi-seconds.
+	WhiteCenti int64
+	BlackCenti int64
+}
+
+// BoardStatus snapshots the room's game for a simul dashboard. It is safe to
+// call from handlers concurrently with the room routine.
+func (r *Instance) BoardStatus() BoardStatus {
+	var live bool
+	switch r.State() {
+	case StateGameReady, StateDeploy, StateGameOngoing:
+		live = true
+	}
+
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	if r.game == nil {
+		return BoardStatus{}
+	}
+	clk := r.game.Clock.State(true)
+	return BoardStatus{
+		Live:       live && r.game.Outcome() == octad.NoOutcome,
+		ToMove:     r.game.Position().Turn(),
+		Plies:      len(r.game.Moves()),
+		WhiteCenti: clk.WhiteTime.Centi(),
+		BlackCenti: clk.BlackTime.Centi(),
+	}
+}
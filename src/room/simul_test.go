@@ -0,0 +1,51 @@
+package room
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/channel"
+)
+
+// TestSimulHostPresence is the simul host's seat in miniature: it counts as
+// present without a socket on the board, the way a bot seat does, while the
+// participant's seat is gated like any other player's.
+func TestSimulHostPresence(t *testing.T) {
+	r := newTestInstance(t, "host", "guest")
+	r.ID = "simultest-presence" // unique game channel in the global map
+	r.params.Simul = "sm1"
+	r.params.SimulHost = octad.White
+	sm := channel.Map.GetSockMap(r.ID)
+	defer sm.Cleanup()
+
+	if r.bothPlayersConnected() {
+		t.Fatal("the participant has not connected, but both seats read present")
+	}
+	sm.Track(channel.NewSocket(nil, "guest", "c1", "", channel.Account{}))
+	if !r.bothPlayersConnected() {
+		t.Fatal("the host's seat must read present without a socket on the board")
+	}
+
+	r.params.SimulHost = octad.Black
+	if r.bothPlayersConnected() {
+		t.Fatal("with the host on Black, White's absent seat must not read present")
+	}
+}
+
+// TestSimulSingleGame locks what a simul board shares with a tournament game:
+// one game, no rematch, and a longer first-move window.
+func TestSimulSingleGame(t *testing.T) {
+	r := newTestInstance(t, "host", "guest")
+	if r.singleGame() || r.firstMoveWindow() != time.Minute {
+		t.Fatal("a plain room is not a single game")
+	}
+	r.params.Simul = "sm1"
+	if !r.singleGame() || !r.IsSimul() || r.SimulID() != "sm1" {
+		t.Fatal("a simul board is a single game")
+	}
+	if r.firstMoveWindow() != simulFirstMoveWindow {
+		t.Fatalf("first-move window = %s, want %s", r.firstMoveWindow(), simulFirstMoveWindow)
+	}
+}
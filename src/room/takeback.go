@@ -0,0 +1,209 @@
+package room
+
+import (
+	"github.com/dechristopher/octad/v2"
+	"github.com/looplab/fsm"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/clock"
+	"github.com/dechristopher/lio/home"
+	"github.com/dechristopher/lio/message"
+	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// Takebacks. In a casual or bot game a player may ask to take back their last
+// move: a human opponent accepts by asking in turn (the standing request works
+// like a draw offer, and the next move withdraws it), while a bot grants
+// requests on its own, up to its persona's allowance per game. The game is
+// unwound in place — same game id, same clock — so the rewound position goes
+// out as an ordinary board state on the move protocol. Rated and tournament
+// games never allow takebacks: their results count for more than the one game.
+
+// RequestTakeback enqueues a takeback request (or acceptance) on behalf of the
+// requesting player. Like RequestDraw it validates a seated player during an
+// ongoing game, and never blocks the caller; a room that does not allow
+// takebacks drops the request here.
+func (r *Instance) RequestTakeback(meta channel.SocketContext) {
+	if r.State() != StateGameOngoing || r.GameState() != octad.NoOutcome {
+		return
+	}
+
+	// only seated players may ask, and only where takebacks are allowed
+	r.stateMu.Lock()
+	_, color := r.players.Lookup(meta.UID)
+	allowed := r.takebacksAllowedLocked()
+	r.stateMu.Unlock()
+	if color == octad.NoColor || !allowed {
+		return
+	}
+
+	r.sendControl(message.RoomControl{Type: message.Takeback, Player: meta.UID, Ctx: meta})
+}
+
+// takebacksAllowedLocked reports whether the room's games allow takebacks: a
+// casual or bot game that is neither rated nor part of a tournament. The
+// caller must hold stateMu (it reads players).
+func (r *Instance) takebacksAllowedLocked() bool {
+	if r.params.Rated || r.IsTournament() {
+		return false
+	}
+	return r.params.Casual || r.players.HasBot()
+}
+
+// takebackPliesLocked returns how many plies a takeback for color unwinds:
+// their own last move while the opponent is still to reply, or that move and
+// the reply once it has landed. Zero when color has no move on the board to
+// take back. The caller must hold stateMu.
+func (r *Instance) takebackPliesLocked(color octad.Color) int {
+	plies := 1
+	if r.game.ToMove == color {
+		plies = 2
+	}
+	if plies > len(r.game.Moves()) {
+		return 0
+	}
+	return plies
+}
+
+// takebackControl handles a takeback request from the requesting player. A
+// request answering the opponent's standing one accepts it. Against a bot the
+// request is granted at once while the persona has takebacks to give, and
+// declined after. Otherwise it becomes the standing request, broadcast so the
+// human opponent can accept. A takeback never ends the game.
+func (r *Instance) takebackControl(control message.RoomControl) (bool, *fsm.EventDesc) {
+	r.stateMu.Lock()
+	_, color := r.players.Lookup(control.Ctx.UID)
+	if color == octad.NoColor || r.game.Outcome() != octad.NoOutcome ||
+		!r.takebacksAllowedLocked() {
+		r.stateMu.Unlock()
+		return false, nil
+	}
+
+	// answering the opponent's standing request accepts it
+	if r.takebackOffer == color.Other() {
+		r.stateMu.Unlock()
+		r.applyTakeback(color.Other())
+		return false, nil
+	}
+
+	// re-asking while our own request stands, or with no move of ours on the
+	// board, is a no-op
+	if r.takebackOffer == color || r.takebackPliesLocked(color) == 0 {
+		r.stateMu.Unlock()
+		return false, nil
+	}
+
+	if r.players.GetBotColor() == color.Other() {
+		grant := r.takebacks < r.botPersona().Takebacks
+		r.stateMu.Unlock()
+		if grant {
+			r.applyTakeback(color)
+		} else {
+			r.log().DebugFlag("room", str.CRoom, "bot declined takeback")
+			proto.TakebackPayload{Declined: true}.Broadcast(channel.SocketContext{Channel: r.ID, MT: 1})
+		}
+		return false, nil
+	}
+
+	r.takebackOffer = color
+	r.stateMu.Unlock()
+
+	// surface the standing request: the requester's client shows it pending,
+	// the opponent's an "accept takeback" affordance (each keys off By)
+	proto.TakebackPayload{By: control.Ctx.UID}.Broadcast(channel.SocketContext{Channel: r.ID, MT: 1})
+	return false, nil
+}
+
+// applyTakeback unwinds color's last move, and the reply to it if one landed,
+// rewinds the clock to match, and broadcasts the rewound board state. The
+// standing draw offer and takeback request, and any queued premoves, are
+// withdrawn with the position they referred to. An engine search still running for the unwound position
+// is dropped when it returns (makeMove's OFEN guard).
+func (r *Instance) applyTakeback(color octad.Color) {
+	r.stateMu.Lock()
+	plies := r.takebackPliesLocked(color)
+	if plies == 0 || r.game.Outcome() != octad.NoOutcome {
+		r.stateMu.Unlock()
+		return
+	}
+
+	// the clock first, then the board: a flag can land right up to the
+	// rewind, and a refused rewind means the game is about to end on the
+	// position as it stands, so that position must still be on the board.
+	// Like flipClock, this holds stateMu across the clock handshake: the
+	// clock has its own mutex and never calls back into the room
+	if !r.game.Clock.Rewind(r.rewoundClockLocked(plies)) {
+		r.stateMu.Unlock()
+		return
+	}
+	// checked above under the same lock: plies are on the board, undecided
+	r.game.Takeback(plies)
+
+	r.takebacks++
+	r.takebackOffer = octad.NoColor
+	if r.drawOffer != octad.NoColor {
+		r.drawOffer = octad.NoColor
+		r.draw = player.NewAgreement()
+	}
+	// queued premoves answered positions that are no longer on the board
+	premovers := r.clearPremovesLocked()
+	gameID := r.game.ID
+	requestEngine := r.players.GetBotColor() == r.game.ToMove
+	tvMove := r.homeEventLocked(home.Move)
+	r.stateMu.Unlock()
+
+	r.log().DebugFlag("room", str.CRoom, "%s took back %d plies", color, plies)
+
+	// the home-page TV shows the rewound position like any other
+	home.Publish(tvMove)
+
+	// the board state carries the raised takeback count, which is what lets
+	// clients accept a position with fewer plies than the one they show
+	channel.Broadcast(r.CurrentGameStateMessage(false, false), channel.SocketContext{Channel: r.ID, MT: 1})
+	r.ackDropped(premovers, gameID)
+
+	if requestEngine {
+		r.requestEngineMove()
+	}
+}
+
+// rewoundClockLocked derives the clock for the position plies back from the
+// game's per-ply timing: each side's clock reads as it did after their last
+// move still on the board once those plies are gone — so the side to move
+// gets back exactly what they had when it was last their turn — and a side
+// with no move left has its full budget. A ply without a timing record (from a
+// snapshot that predates them) leaves that side's elapsed time as it is. The
+// caller must hold stateMu; the game is not unwound yet.
+func (r *Instance) rewoundClockLocked(plies int) clock.Snapshot {
+	current := r.game.Clock.Snapshot()
+	moves := len(r.game.Moves()) - plies
+	positions := r.game.Positions()
+	budget := r.game.Variant.Control.Time.Milli()
+
+	elapsed := map[octad.Color]int64{octad.White: 0, octad.Black: 0}
+	seen := map[octad.Color]bool{}
+	for i := moves - 1; i >= 0 && len(seen) < 2; i-- {
+		// positions[i] is the position ply i+1 was played in
+		mover := positions[i].Turn()
+		if seen[mover] {
+			continue
+		}
+		seen[mover] = true
+		if i < len(r.game.MoveTimes) && r.game.MoveTimes[i].ClockMs > 0 {
+			elapsed[mover] = budget - r.game.MoveTimes[i].ClockMs
+		} else if mover == octad.White {
+			elapsed[mover] = current.WhiteElapsedMs
+		} else {
+			elapsed[mover] = current.BlackElapsedMs
+		}
+	}
+
+	return clock.Snapshot{
+		WhiteElapsedMs: elapsed[octad.White],
+		BlackElapsedMs: elapsed[octad.Black],
+		Turn:           positions[moves].Turn(),
+		FirstMove:      moves == 0,
+	}
+}
@@ -0,0 +1,187 @@
+package room
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/clock"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/message"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// playTimedPlies plays n legal moves straight onto the game, each recorded as
+// a second of think time off the mover's 30s budget, without touching the
+// clock — which stays paused, so a takeback's rewind applies to it directly.
+func playTimedPlies(t *testing.T, r *Instance, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		moves := r.game.ValidMoves()
+		if len(moves) == 0 {
+			t.Fatalf("no legal moves at ply %d", len(r.game.Moves()))
+		}
+		if err := r.game.Move(moves[0]); err != nil {
+			t.Fatal(err)
+		}
+		ply := int64(len(r.game.MoveTimes))
+		r.game.MoveTimes = append(r.game.MoveTimes, game.MoveTime{ThinkMs: 1000, ClockMs: 30000 - 1000*(ply/2+1)})
+		r.game.ToMove = r.game.Position().Turn()
+	}
+}
+
+// TestRequestTakebackGuards covers where a takeback may be asked for: a seated
+// player in an ongoing casual game, never a spectator, and never in a rated
+// game.
+func TestRequestTakebackGuards(t *testing.T) {
+	r := newTestInstance(t, "w", "b")
+	r.params.Casual = true
+	r.controlChannel = make(chan message.RoomControl, 2)
+	driveToOngoing(t, r)
+
+	r.RequestTakeback(channel.SocketContext{UID: "spectator"})
+	if _, ok := drainControl(t, r); ok {
+		t.Fatal("takeback accepted from a non-player")
+	}
+
+	r.RequestTakeback(channel.SocketContext{UID: "w"})
+	ctrl, ok := drainControl(t, r)
+	if !ok || ctrl.Type != message.Takeback {
+		t.Fatalf("seated player's takeback = %+v, %v", ctrl, ok)
+	}
+
+	r.params.Rated = true
+	r.RequestTakeback(channel.SocketContext{UID: "w"})
+	if _, ok := drainControl(t, r); ok {
+		t.Fatal("takeback accepted in a rated game")
+	}
+}
+
+// TestTakebackHumanRequestAndAccept verifies the human flow: a request stands
+// until the opponent answers it, and accepting unwinds the requester's move
+// with the clock rewound to where it stood before it.
+func TestTakebackHumanRequestAndAccept(t *testing.T) {
+	r := newTestInstance(t, "w", "b")
+	r.params.Casual = true
+	driveToOngoing(t, r)
+	playTimedPlies(t, r, 3)
+	before := r.game.OFENHistory()[2]
+
+	// black has a move on the board, but white's reply is to move: taking it
+	// back unwinds white's last move alone
+	r.takebackControl(message.RoomControl{Type: message.Takeback, Ctx: channel.SocketContext{UID: "w"}})
+	if r.takebackOffer != octad.White || len(r.game.Moves()) != 3 {
+		t.Fatalf("request: offer = %v, plies = %d", r.takebackOffer, len(r.game.Moves()))
+	}
+
+	r.takebackControl(message.RoomControl{Type: message.Takeback, Ctx: channel.SocketContext{UID: "b"}})
+	if r.takebackOffer != octad.NoColor || r.takebacks != 1 {
+		t.Fatalf("accept: offer = %v, takebacks = %d", r.takebackOffer, r.takebacks)
+	}
+	if len(r.game.Moves()) != 2 || len(r.game.MoveTimes) != 2 || r.game.OFEN() != before {
+		t.Fatalf("accept left %d plies at %q, want 2 at %q", len(r.game.Moves()), r.game.OFEN(), before)
+	}
+
+	// white is back on move with the 29s they had after their first move, and
+	// black keeps the 29s recorded after theirs
+	snap := r.game.Clock.Snapshot()
+	if snap.Turn != octad.White || snap.WhiteElapsedMs != 1000 || snap.BlackElapsedMs != 1000 || snap.FirstMove {
+		t.Fatalf("rewound clock = %+v", snap)
+	}
+
+	// the board state carries the raised count clients key the rewind off
+	if msg := string(r.CurrentGameStateMessage(false, false)); !strings.Contains(msg, `"tb":1`) {
+		t.Fatalf("board state without the takeback count: %s", msg)
+	}
+}
+
+// TestTakebackRacesFlag accepts a takeback while the side to move is flagging
+// on a running clock. Whichever lands first, board and clock must agree: the
+// rewind wins and both are back at the start, or the flag wins and the board
+// keeps the position the game ends on.
+func TestTakebackRacesFlag(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		r := newTestInstance(t, "w", "b")
+		r.params.Casual = true
+		driveToOngoing(t, r)
+		playTimedPlies(t, r, 2)
+
+		// white is to move with a few milliseconds left
+		budget := r.game.Variant.Control.Time.Milli()
+		r.game.Clock = clock.Restore(r.game.Variant.Control, clock.Snapshot{
+			WhiteElapsedMs: budget - 5,
+			BlackElapsedMs: 1000,
+			Turn:           octad.White,
+		})
+		r.game.Clock.Resume()
+		time.Sleep(time.Duration(i%10) * time.Millisecond)
+
+		// white asks to take back their move and black's reply; black accepts
+		r.takebackControl(message.RoomControl{Type: message.Takeback, Ctx: channel.SocketContext{UID: "w"}})
+		r.takebackControl(message.RoomControl{Type: message.Takeback, Ctx: channel.SocketContext{UID: "b"}})
+
+		snap := r.game.Clock.Snapshot()
+		r.game.Clock.Stop(false, true)
+		switch {
+		case r.takebacks == 1:
+			if len(r.game.Moves()) != 0 || snap.Victor != clock.NoVictor || !snap.FirstMove {
+				t.Fatalf("rewind won: %d plies, clock %+v", len(r.game.Moves()), snap)
+			}
+		case r.takebacks == 0:
+			if len(r.game.Moves()) != 2 || snap.Victor != clock.Black {
+				t.Fatalf("flag won: %d plies, clock %+v", len(r.game.Moves()), snap)
+			}
+		default:
+			t.Fatalf("takebacks = %d", r.takebacks)
+		}
+	}
+}
+
+// TestTakebackBotAllowance verifies a bot grants takebacks on its own, two
+// plies at a time once it has replied, and declines once its persona's
+// allowance for the game is spent.
+func TestTakebackBotAllowance(t *testing.T) {
+	r := newBotTestInstance(t, "human", octad.Black)
+	r.params.BotPersona = "rook" // one takeback per game
+	driveToOngoing(t, r)
+	playTimedPlies(t, r, 2)
+
+	r.takebackControl(message.RoomControl{Type: message.Takeback, Ctx: channel.SocketContext{UID: "human"}})
+	if len(r.game.Moves()) != 0 || r.takebacks != 1 {
+		t.Fatalf("granted takeback left %d plies, takebacks = %d", len(r.game.Moves()), r.takebacks)
+	}
+	if snap := r.game.Clock.Snapshot(); !snap.FirstMove || snap.Turn != octad.White {
+		t.Fatalf("clock rewound to the start = %+v", snap)
+	}
+
+	playTimedPlies(t, r, 2)
+	r.takebackControl(message.RoomControl{Type: message.Takeback, Ctx: channel.SocketContext{UID: "human"}})
+	if len(r.game.Moves()) != 2 || r.takebacks != 1 {
+		t.Fatalf("spent allowance: %d plies, takebacks = %d", len(r.game.Moves()), r.takebacks)
+	}
+}
+
+// TestMakeMoveDropsUnwoundEngineMove verifies an engine move searched in a
+// position a takeback has since unwound is dropped, not played.
+func TestMakeMoveDropsUnwoundEngineMove(t *testing.T) {
+	r := newTestInstance(t, "w", "b")
+	driveToOngoing(t, r)
+	stale := r.game.OFEN()
+	playTimedPlies(t, r, 1)
+
+	moves := r.game.ValidMoves()
+	if r.makeMove(&message.RoomMove{
+		GameID: r.game.ID,
+		OFEN:   stale,
+		Move:   proto.MovePayload{UOI: moves[0].String()},
+		Ctx:    channel.SocketContext{Channel: r.ID, IsBot: true},
+	}) {
+		t.Fatal("an engine move for an unwound position was played")
+	}
+	if len(r.game.Moves()) != 1 {
+		t.Fatalf("game has %d plies, want 1", len(r.game.Moves()))
+	}
+}
@@ -0,0 +1,163 @@
+package room
+
+import (
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/bus"
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Tournament rooms. The tournament package pairs its players into ordinary
+// rooms (Params.Tournament set, both seats filled) and learns how each game
+// went from a Result published on ResultChannel — the room never imports the
+// tournament package, which already imports room. A tournament room plays one
+// game: no rematch and no race-to, just a short look at the result before the
+// players are sent back to the event for their next pairing.
+
+// ResultChannel carries the Result of every tournament game and simul board.
+const ResultChannel bus.Channel = "lio:result"
+
+// tournamentGameOverWindow is how long a finished tournament game's room stays
+// open: long enough to read the result, and for a reconnecting player to see
+// it. The client heads back to the tournament on its own well before this.
+var tournamentGameOverWindow = 15 * time.Second
+
+var resultPub = bus.NewPublisher("result", ResultChannel)
+
+// Result is how a tournament game or simul board ended, published exactly
+// once per room.
+type Result struct {
+	RoomID     string
+	Tournament string
+	// Simul is the simul the room is a board of; "" for a tournament game.
+	Simul string
+	// WhiteUserID / BlackUserID are the seats' accounts; tournament players
+	// are always logged in.
+	WhiteUserID *int64
+	BlackUserID *int64
+	// Outcome is NoOutcome for a void game: the room closed before the game
+	// produced a result (nobody turned up, or the room was closed by hand).
+	Outcome octad.Outcome
+	// Reason is the DB-canonical method token, as archived ("forfeit" for a
+	// player who never made their first move).
+	Reason string
+	// Plies is the number of moves played. Arena only rewards a berserk win
+	// that was actually played out.
+	Plies        int
+	BerserkWhite bool
+	BerserkBlack bool
+}
+
+// IsTournament reports whether the room was paired by a tournament.
+func (r *Instance) IsTournament() bool {
+	return r.params.Tournament != ""
+}
+
+// publishResultLocked announces a tournament game's or simul board's result,
+// once. A plain room publishes nothing. The caller must hold stateMu (it reads
+// players and the berserk flags).
+func (r *Instance) publishResultLocked(outcome octad.Outcome, reason string, plies int) {
+	if !r.singleGame() || !r.resultSent.CompareAndSwap(false, true) {
+		return
+	}
+	res := Result{
+		RoomID:       r.ID,
+		Tournament:   r.params.Tournament,
+		Simul:        r.params.Simul,
+		Outcome:      outcome,
+		Reason:       reason,
+		Plies:        plies,
+		BerserkWhite: r.berserk.AgreedBy(octad.White),
+		BerserkBlack: r.berserk.AgreedBy(octad.Black),
+	}
+	if p := r.players[octad.White]; p != nil {
+		res.WhiteUserID = p.UserID
+	}
+	if p := r.players[octad.Black]; p != nil {
+		res.BlackUserID = p.UserID
+	}
+	go resultPub.Publish(res)
+}
+
+// RequestBerserk halves the requesting player's clock in an Arena game that
+// allows it. Like the other controls it is called from the WS read loop and
+// never blocks it. It is only accepted before the player's first move — White
+// with no move on the board, Black with at most White's — and only once.
+func (r *Instance) RequestBerserk(meta channel.SocketContext) {
+	if !r.params.Berserk || Draining() {
+		return
+	}
+	switch r.State() {
+	case StateGameReady, StateDeploy, StateGameOngoing:
+	default:
+		return
+	}
+
+	r.stateMu.Lock()
+	_, color := r.players.Lookup(meta.UID)
+	if color == octad.NoColor || r.berserk.AgreedBy(color) ||
+		r.game.Outcome() != octad.NoOutcome {
+		r.stateMu.Unlock()
+		return
+	}
+	plies := len(r.game.Moves())
+	if (color == octad.White && plies > 0) || (color == octad.Black && plies > 1) {
+		r.stateMu.Unlock()
+		return
+	}
+	r.berserk.Agree(color)
+	clk := r.game.Clock
+	r.stateMu.Unlock()
+
+	// outside stateMu: the clock takes its own lock, which the clock goroutine
+	// can hold while it waits on the room routine
+	if !clk.Berserk(color) {
+		return
+	}
+
+	r.log().DebugFlag("room", str.CRoom, "%s berserked", color)
+	markDirty(r)
+	channel.Broadcast(r.CurrentGameStateMessage(false, false), channel.SocketContext{Channel: r.ID, MT: 1})
+}
+
+// applyBerserkLocked charges the recorded berserks to the current game's
+// clock. A game rebuilt before its first move (the deploy start, a restart
+// rehydrating a game that had not begun) starts from a fresh clock, and the
+// players' choice must carry over to it. The caller must hold stateMu, and the
+// clock must not be running yet.
+func (r *Instance) applyBerserkLocked() {
+	util.DoBothColors(func(c octad.Color) {
+		if r.berserk.AgreedBy(c) {
+			r.game.Clock.Berserk(c)
+		}
+	})
+}
+
+// handleTournamentGameOver holds a finished tournament game's (or simul
+// board's) room open for tournamentGameOverWindow, then closes it. There is
+// nothing to negotiate: any rematch click is dropped.
+func (r *Instance) handleTournamentGameOver() {
+	window := tournamentGameOverWindow
+	if r.restoredWindow > 0 {
+		window = r.restoredWindow
+		r.restoredWindow = 0
+	}
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			r.log().DebugFlag("room", str.CRoom, "tournament game over, room over")
+			if err := r.event(EventNoRematch); err != nil {
+				panic(err)
+			}
+			return
+		case <-r.controlChannel:
+		}
+	}
+}
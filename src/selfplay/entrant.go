@@ -0,0 +1,240 @@
+package selfplay
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dechristopher/lio/engine"
+)
+
+// Entrant is one self-play player: a ladder persona, or a variant of one with
+// some of its parameters changed.
+type Entrant struct {
+	// Name labels the entrant in the report and on its PGN seat: the persona's
+	// name, followed by a variant's changes ("Knight BlunderRate=0.08").
+	Name string
+	// Base is the key of the ladder persona the entrant is, or varies.
+	Base    string
+	Persona engine.Persona
+	// Changes are a variant's parameter changes against Base, in the order
+	// given. Empty for a ladder entrant.
+	Changes []Change
+	// Eval names the evaluation a variant searches with in place of the
+	// engine's default (Persona.Weights), as "tuned" for lio --tune's fit.
+	// Empty for the default.
+	Eval string
+}
+
+// Change is one persona parameter a variant sets.
+type Change struct {
+	Knob  string
+	Value float64
+}
+
+// variant reports whether the entrant is a variant rather than a ladder rung.
+func (e Entrant) variant() bool {
+	return len(e.Changes) > 0 || e.Eval != ""
+}
+
+// key identifies the entrant in the PGN's WhiteUID/BlackUID tags: the persona
+// key, followed by a variant's changes ("knight:BlunderRate=0.08",
+// "queen:eval=tuned").
+func (e Entrant) key() string {
+	if !e.variant() {
+		return e.Base
+	}
+	return e.Base + ":" + e.changes(",")
+}
+
+// changes renders the entrant's changes as Knob=value pairs joined by sep,
+// and its evaluation as eval=name.
+func (e Entrant) changes(sep string) string {
+	parts := make([]string, 0, len(e.Changes)+1)
+	for _, c := range e.Changes {
+		parts = append(parts, c.Knob+"="+strconv.FormatFloat(c.Value, 'g', -1, 64))
+	}
+	if e.Eval != "" {
+		parts = append(parts, "eval="+e.Eval)
+	}
+	return strings.Join(parts, sep)
+}
+
+// knob is a persona parameter a variant may change.
+type knob struct {
+	name string
+	// lo and hi bound the values the knob accepts.
+	lo, hi float64
+	// integer knobs take whole values, and a fitted setting is rounded.
+	integer bool
+	// fit marks a strength dial the calibration can interpolate along.
+	// RandomDeploy is a switch: there is no setting halfway between.
+	fit bool
+	get func(engine.Persona) float64
+	set func(*engine.Persona, float64)
+}
+
+// knobs are the persona parameters a variant may change: the handicaps, not
+// the display fields. Takebacks never come up in self-play.
+var knobs = []knob{
+	{
+		name: "MaxDepth", lo: 0, hi: 32, integer: true, fit: true,
+		get: func(p engine.Persona) float64 { return float64(p.MaxDepth) },
+		set: func(p *engine.Persona, v float64) { p.MaxDepth = int(v) },
+	},
+	{
+		name: "VarietyMoves", lo: 1, hi: 64, integer: true, fit: true,
+		get: func(p engine.Persona) float64 { return float64(p.VarietyMoves) },
+		set: func(p *engine.Persona, v float64) { p.VarietyMoves = int(v) },
+	},
+	{
+		name: "VarietyMargin", lo: 0, hi: 1000, fit: true,
+		get: func(p engine.Persona) float64 { return p.VarietyMargin },
+		set: func(p *engine.Persona, v float64) { p.VarietyMargin = v },
+	},
+	{
+		name: "BlunderRate", lo: 0, hi: 1, fit: true,
+		get: func(p engine.Persona) float64 { return p.BlunderRate },
+		set: func(p *engine.Persona, v float64) { p.BlunderRate = v },
+	},
+	{
+		// a reserve of the whole clock would leave nothing to think with
+		name: "TimeReserve", lo: 0, hi: 0.95, fit: true,
+		get: func(p engine.Persona) float64 { return p.TimeReserve },
+		set: func(p *engine.Persona, v float64) { p.TimeReserve = v },
+	},
+	{
+		name: "RandomDeploy", lo: 0, hi: 1, integer: true,
+		get: func(p engine.Persona) float64 {
+			if p.RandomDeploy {
+				return 1
+			}
+			return 0
+		},
+		set: func(p *engine.Persona, v float64) { p.RandomDeploy = v != 0 },
+	},
+}
+
+// knobByName looks a knob up by name, ignoring case.
+func knobByName(name string) (knob, bool) {
+	for _, k := range knobs {
+		if strings.EqualFold(k.name, name) {
+			return k, true
+		}
+	}
+	return knob{}, false
+}
+
+// Ladder returns the persona ladder as entrants, weakest first.
+func Ladder() []Entrant {
+	out := make([]Entrant, len(engine.Personas))
+	for i, p := range engine.Personas {
+		out[i] = Entrant{Name: p.Name, Base: p.Key, Persona: p}
+	}
+	return out
+}
+
+// ParseVariants parses a --selfplay-variants spec: semicolon-separated
+// variants, each a persona key and its changes, as in
+//
+//	knight:BlunderRate=0.08;rook:MaxDepth=5,VarietyMargin=2
+//
+// Knob names are the engine.Persona field names, in any case. RandomDeploy
+// takes 0 or 1.
+func ParseVariants(spec string) ([]Entrant, error) {
+	var out []Entrant
+	for _, v := range strings.Split(spec, ";") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		key, sets, ok := strings.Cut(v, ":")
+		if !ok || strings.TrimSpace(sets) == "" {
+			return nil, fmt.Errorf("variant %q changes nothing (want persona:Knob=value,...)", v)
+		}
+		base, ok := personaByKey(strings.TrimSpace(key))
+		if !ok {
+			return nil, fmt.Errorf("variant %q: no persona %q", v, key)
+		}
+
+		var changes []Change
+		for _, set := range strings.Split(sets, ",") {
+			name, value, ok := strings.Cut(set, "=")
+			if !ok {
+				return nil, fmt.Errorf("variant %q: %q is not Knob=value", v, set)
+			}
+			k, ok := knobByName(strings.TrimSpace(name))
+			if !ok {
+				return nil, fmt.Errorf("variant %q: no knob %q", v, name)
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || f < k.lo || f > k.hi || (k.integer && f != math.Trunc(f)) {
+				return nil, fmt.Errorf("variant %q: bad %s value %q", v, k.name, value)
+			}
+			changes = append(changes, Change{Knob: k.name, Value: f})
+		}
+		out = append(out, newVariant(base, changes))
+	}
+	return out, nil
+}
+
+// Sweep returns a pair of variants for every rung below the top, one each
+// side of the rung's own setting of its main dial: BlunderRate where the rung
+// blunders, VarietyMargin otherwise. Played beside the ladder they give the
+// calibration a measured line through every rung. The full-strength top rung
+// has nothing to dial; it anchors the ladder instead.
+func Sweep() []Entrant {
+	var out []Entrant
+	for _, p := range engine.Personas[:len(engine.Personas)-1] {
+		k, _ := knobByName("VarietyMargin")
+		if p.BlunderRate > 0 {
+			k, _ = knobByName("BlunderRate")
+		}
+		at := k.get(p)
+		for _, f := range []float64{0.5, 1.5} {
+			v := math.Min(round(at*f, 3), k.hi)
+			out = append(out, newVariant(p, []Change{{Knob: k.name, Value: v}}))
+		}
+	}
+	return out
+}
+
+// newVariant applies changes to base.
+func newVariant(base engine.Persona, changes []Change) Entrant {
+	e := Entrant{Base: base.Key, Persona: base, Changes: changes}
+	for _, c := range changes {
+		k, _ := knobByName(c.Knob)
+		k.set(&e.Persona, c.Value)
+	}
+	e.Name = base.Name + " " + e.changes(" ")
+	return e
+}
+
+// EvalVariant is a variant of base that searches with weights w in place of
+// the default evaluation, named eval. Played beside the ladder, its rating
+// against base's measures the evaluation alone: every other setting is base's.
+func EvalVariant(base engine.Persona, eval string, w *engine.Weights) Entrant {
+	e := Entrant{Base: base.Key, Persona: base, Eval: eval}
+	e.Persona.Weights = w
+	e.Name = base.Name + " " + e.changes(" ")
+	return e
+}
+
+// personaByKey finds a ladder persona by key. engine.PersonaByKey falls back
+// to the Queen on a miss, which would quietly turn a typo into a variant of
+// the wrong rung.
+func personaByKey(key string) (engine.Persona, bool) {
+	for _, p := range engine.Personas {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return engine.Persona{}, false
+}
+
+// round rounds v to places decimal places.
+func round(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
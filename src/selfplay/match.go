@@ -0,0 +1,259 @@
+package selfplay
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/clock"
+	"github.com/dechristopher/lio/dispatch"
+	"github.com/dechristopher/lio/engine"
+	"github.com/dechristopher/lio/game"
+	"github.com/dechristopher/lio/message"
+	"github.com/dechristopher/lio/opening"
+	"github.com/dechristopher/lio/rating"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/variant"
+)
+
+// site is the PGN Site tag of a self-play game.
+const site = "lio self-play"
+
+// pairing is one scheduled game: the entrants by index and the round (rating
+// period) it belongs to.
+type pairing struct {
+	round        int
+	white, black int
+}
+
+// record is one played game.
+type record struct {
+	pairing
+	// result is the PGN result token, and score white's score in it:
+	// rating.Win, Draw or Loss.
+	result string
+	score  float64
+	reason string
+	plies  int
+	pgn    string
+}
+
+// play plays one game between white and black at v, the way a room plays a
+// bot game: a blind deploy first if v has one, then every move searched by the
+// engine dispatcher on a budget paced off the mover's running clock.
+func play(v variant.Variant, p pairing, white, black Entrant) (record, error) {
+	rec := record{pairing: p}
+
+	var ofen string
+	if v.Deploy {
+		ofen = deploy(white.Persona, black.Persona)
+	}
+	g, err := game.NewOctadGame(game.OctadGameConfig{
+		White:   white.key(),
+		Black:   black.key(),
+		Variant: v,
+		OFEN:    ofen,
+	})
+	if err != nil {
+		return rec, err
+	}
+
+	g.Clock.Start()
+	defer g.Clock.Stop(false, true)
+
+	var flagged *clock.State
+	for g.Outcome() == octad.NoOutcome && flagged == nil {
+		mover := white
+		if g.Position().Turn() == octad.Black {
+			mover = black
+		}
+		var uoi string
+		if uoi, flagged = think(g, mover.Persona); flagged != nil {
+			break
+		}
+		if flagged, err = move(g, uoi); err != nil {
+			return rec, err
+		}
+	}
+
+	// a move that ended the game on the board stands, even if it also flagged
+	// its mover, as it does in a room; otherwise the flagged side loses
+	if g.Outcome() == octad.NoOutcome && flagged != nil {
+		if flagged.Victor == clock.White {
+			g.Resign(octad.Black)
+		} else {
+			g.Resign(octad.White)
+		}
+		rec.reason = "time"
+	} else {
+		rec.reason = boardReason(&g.Game)
+	}
+
+	switch g.Outcome() {
+	case octad.WhiteWon:
+		rec.score = rating.Win
+	case octad.BlackWon:
+		rec.score = rating.Loss
+	default:
+		rec.score = rating.Draw
+	}
+	rec.result = string(g.Outcome())
+	rec.plies = len(g.Moves())
+	rec.pgn = buildPGN(g, white, black, rec.reason)
+	return rec, nil
+}
+
+// deploy runs both bots' blind deploy through the dispatcher, as a room's
+// deploy phase does, and returns the position the game starts from.
+func deploy(white, black engine.Persona) string {
+	response := make(chan *message.RoomBotDeploy, 2)
+	dispatch.SubmitDeploy(dispatch.DeployRequest{
+		RoomID:          site,
+		Color:           octad.White,
+		Random:          white.RandomDeploy,
+		ResponseChannel: response,
+	})
+	dispatch.SubmitDeploy(dispatch.DeployRequest{
+		RoomID:          site,
+		Color:           octad.Black,
+		Random:          black.RandomDeploy,
+		ResponseChannel: response,
+	})
+
+	var placements [2]engine.DeployPlacement
+	for range 2 {
+		d := <-response
+		if d.Color == octad.White {
+			placements[0] = d.Placement
+		} else {
+			placements[1] = d.Placement
+		}
+	}
+	return engine.DeployOFEN(placements[0], placements[1])
+}
+
+// think asks the engine for the reply of the side to move, paced as a room
+// paces its bot, and waits for the move or for that side's flag, whichever
+// comes first. On a flag it returns the clock's final state instead.
+func think(g *game.OctadGame, p engine.Persona) (string, *clock.State) {
+	remaining := remainingClock(g.Clock.State(true), g.Position().Turn())
+	depth, budget := room.BotSearchLimits(g.Variant.Control, remaining, p)
+
+	response := make(chan *message.RoomMove)
+	done := make(chan struct{})
+	defer close(done)
+
+	dispatch.SubmitEngine(dispatch.EngineRequest{
+		GameID:          g.ID,
+		OFEN:            g.OFEN(),
+		History:         g.OFENHistory(),
+		Depth:           depth,
+		Budget:          budget,
+		Clock:           remaining,
+		Persona:         p,
+		ResponseChannel: response,
+		Done:            done,
+		Ctx:             channel.SocketContext{Channel: site},
+	})
+
+	select {
+	case m := <-response:
+		return m.Move.UOI, nil
+	case st := <-g.Clock.StateChannel:
+		return "", &st
+	}
+}
+
+// move plays uoi and flips the clock, recording the ply's timing as a room
+// does. It returns the clock's final state if the mover flagged.
+func move(g *game.OctadGame, uoi string) (*clock.State, error) {
+	var mov *octad.Move
+	for _, m := range g.ValidMoves() {
+		if m.String() == uoi {
+			mov = m
+			break
+		}
+	}
+	if mov == nil {
+		return nil, fmt.Errorf("engine played illegal move %q in %s", uoi, g.OFEN())
+	}
+	if err := g.Move(mov); err != nil {
+		return nil, err
+	}
+	g.ToMove = g.Position().Turn()
+
+	// the clock may flag the mover between the search returning and the flip
+	// landing, and then no clock goroutine is left to take the flip
+	ack := g.Clock.GetAck()
+	select {
+	case g.Clock.ControlChannel <- clock.Flip:
+	case st := <-g.Clock.StateChannel:
+		g.MoveTimes = append(g.MoveTimes, game.MoveTime{})
+		return &st, nil
+	}
+	a := <-ack
+	g.MoveTimes = append(g.MoveTimes, game.MoveTime{
+		ThinkMs: a.Think.Milli(),
+		ClockMs: a.Remaining.Milli(),
+	})
+
+	// a flip that flags the mover is acknowledged before the clock stops
+	if st := g.Clock.State(true); st.Victor != clock.NoVictor {
+		return &st, nil
+	}
+	return nil, nil
+}
+
+// remainingClock is color's remaining time in st, at the clock's centisecond
+// resolution, as a room reads it when it budgets a search.
+func remainingClock(st clock.State, color octad.Color) time.Duration {
+	if color == octad.White {
+		return time.Duration(st.WhiteTime.Centi()) * clock.Centisecond
+	}
+	return time.Duration(st.BlackTime.Centi()) * clock.Centisecond
+}
+
+// boardReason is the games.reason token of a game the board decided, as the
+// room's gameOverReasonLocked names it.
+func boardReason(b *octad.Game) string {
+	switch b.Method() {
+	case octad.Checkmate:
+		return "checkmate"
+	case octad.InsufficientMaterial:
+		return "insufficient"
+	case octad.Stalemate:
+		return "stalemate"
+	case octad.ThreefoldRepetition:
+		return "repetition"
+	case octad.TwentyFiveMoveRule:
+		return "moverule"
+	}
+	return ""
+}
+
+// buildPGN is the game's PGN, tagged as the archive tags a bot game.
+func buildPGN(g *game.OctadGame, white, black Entrant, reason string) string {
+	startOFEN := g.Game.Positions()[0].String()
+	whiteFormation, blackFormation, matchup, _ := opening.Names(startOFEN)
+
+	return game.BuildPGN(game.PGNMeta{
+		Site:           site,
+		Variant:        g.Variant.Name,
+		Group:          string(g.Variant.Group),
+		White:          game.PGNSeatName("", "", white.Persona.Glyph, white.Name, true),
+		Black:          game.PGNSeatName("", "", black.Persona.Glyph, black.Name, true),
+		WhiteUID:       white.key(),
+		BlackUID:       black.key(),
+		Result:         string(g.Outcome()),
+		Reason:         reason,
+		Start:          g.Start,
+		End:            time.Now(),
+		StartOFEN:      startOFEN,
+		WhiteFormation: whiteFormation,
+		BlackFormation: blackFormation,
+		Matchup:        matchup,
+		VsBot:          true,
+	}, &g.Game, g.MoveTimes)
+}
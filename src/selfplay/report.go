@@ -0,0 +1,265 @@
+package selfplay
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dechristopher/lio/rating"
+	"github.com/dechristopher/lio/variant"
+)
+
+// Report is a finished self-play run.
+type Report struct {
+	Variant variant.Variant
+	Rounds  int
+	// Played counts the games rated, and Failed the games left out.
+	Played  int
+	Failed  int
+	Elapsed time.Duration
+	// Standings are every entrant's results, in Config.Entrants order.
+	Standings []Standing
+	// Rungs place the ladder against its targets, weakest first.
+	Rungs []Rung
+	// Gap is the target gap the rungs were placed against.
+	Gap float64
+}
+
+// Standing is one entrant's results.
+type Standing struct {
+	Entrant Entrant
+	Rating  rating.Rating
+	Games   int
+	// Points is the entrant's score: a point a win, half a draw.
+	Points float64
+}
+
+// Rung is one ladder rung against the evenly spaced ladder hung from the top
+// rung: the rung n places below the top is aimed at n gaps under the top's
+// measured rating. The top rung is the full-strength engine, which no setting
+// can make stronger, so it is the fixed point the others are placed from.
+type Rung struct {
+	Key            string
+	Rating, Target float64
+	// Settings are the values that would put the rung on target, one for each
+	// dial its variants measured.
+	Settings []Setting
+}
+
+// Setting is a knob value fitted to put a rung on its target rating.
+type Setting struct {
+	Knob     string
+	From, To float64
+	// Extrapolated marks a value outside the range the variants played: none
+	// of them reached the target, so the line through the nearest two is
+	// extended to it. Worth a confirming run before it goes in the ladder.
+	Extrapolated bool
+}
+
+// newReport rates the records and places the ladder.
+func newReport(cfg Config, records []record, failed int) *Report {
+	ratings := rate(len(cfg.Entrants), cfg.Rounds, records)
+
+	r := &Report{
+		Variant: cfg.Variant,
+		Rounds:  cfg.Rounds,
+		Played:  len(records),
+		Failed:  failed,
+		Gap:     cfg.Gap,
+	}
+	r.Standings = make([]Standing, len(cfg.Entrants))
+	for i, e := range cfg.Entrants {
+		r.Standings[i] = Standing{Entrant: e, Rating: ratings[i]}
+	}
+	for _, rec := range records {
+		r.Standings[rec.white].Games++
+		r.Standings[rec.black].Games++
+		r.Standings[rec.white].Points += rec.score
+		r.Standings[rec.black].Points += 1 - rec.score
+	}
+	r.Rungs = calibrate(cfg.Entrants, ratings, cfg.Gap)
+	return r
+}
+
+// rate runs the Glicko-2 rating periods: every entrant starts unrated, and
+// each round's games are rated together against the ratings the round began
+// with, so the order the games finished in changes nothing.
+func rate(entrants, rounds int, records []record) []rating.Rating {
+	ratings := make([]rating.Rating, entrants)
+	for i := range ratings {
+		ratings[i] = rating.New()
+	}
+
+	for round := range rounds {
+		period := make([][]rating.Result, entrants)
+		for _, rec := range records {
+			if rec.round != round {
+				continue
+			}
+			period[rec.white] = append(period[rec.white], rating.Result{Opp: ratings[rec.black], Score: rec.score})
+			period[rec.black] = append(period[rec.black], rating.Result{Opp: ratings[rec.white], Score: 1 - rec.score})
+		}
+
+		next := make([]rating.Rating, entrants)
+		for i, r := range ratings {
+			next[i] = r.UpdatePeriod(period[i])
+		}
+		ratings = next
+	}
+	return ratings
+}
+
+// ladder returns the indexes of the ladder rungs among entrants, in order.
+func ladder(entrants []Entrant) []int {
+	var out []int
+	for i, e := range entrants {
+		if !e.variant() {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// calibrate places every rung below the top against its target. A rung's
+// settings come from its variants that change a single dial: each such dial
+// gets a line of measured (value, rating) points, the rung's own included,
+// and the value the line reaches the target at.
+func calibrate(entrants []Entrant, ratings []rating.Rating, gap float64) []Rung {
+	rungs := ladder(entrants)
+	top := ratings[rungs[len(rungs)-1]].R
+
+	out := make([]Rung, 0, len(rungs))
+	for n, i := range rungs {
+		base := entrants[i]
+		rung := Rung{
+			Key:    base.Base,
+			Rating: ratings[i].R,
+			Target: top - gap*float64(len(rungs)-1-n),
+		}
+		if n == len(rungs)-1 {
+			out = append(out, rung)
+			continue
+		}
+
+		for _, k := range knobs {
+			if !k.fit {
+				continue
+			}
+			line := []point{{value: k.get(base.Persona), rating: ratings[i].R}}
+			for j, e := range entrants {
+				if e.Base == base.Base && len(e.Changes) == 1 && e.Changes[0].Knob == k.name {
+					line = append(line, point{value: e.Changes[0].Value, rating: ratings[j].R})
+				}
+			}
+			to, extrapolated, ok := solve(line, rung.Target)
+			if !ok {
+				continue
+			}
+			to = math.Min(math.Max(to, k.lo), k.hi)
+			if k.integer {
+				to = math.Round(to)
+			} else {
+				to = round(to, 3)
+			}
+			rung.Settings = append(rung.Settings, Setting{
+				Knob:         k.name,
+				From:         k.get(base.Persona),
+				To:           to,
+				Extrapolated: extrapolated,
+			})
+		}
+		out = append(out, rung)
+	}
+	return out
+}
+
+// point is one measured (knob value, rating) pair.
+type point struct {
+	value, rating float64
+}
+
+// solve finds the knob value at which the piecewise-linear line through pts
+// reaches target. When no segment spans the target, the end segment nearer to
+// it is extended, and extrapolated is set. ok is false when the line has fewer
+// than two distinct values, or is flat where it would have to be extended.
+func solve(pts []point, target float64) (value float64, extrapolated, ok bool) {
+	// merge repeated values: the same setting measured twice is one point
+	sort.Slice(pts, func(i, j int) bool { return pts[i].value < pts[j].value })
+	var line []point
+	for i := 0; i < len(pts); {
+		j, sum := i, 0.0
+		for ; j < len(pts) && pts[j].value == pts[i].value; j++ {
+			sum += pts[j].rating
+		}
+		line = append(line, point{value: pts[i].value, rating: sum / float64(j-i)})
+		i = j
+	}
+	if len(line) < 2 {
+		return 0, false, false
+	}
+
+	at := func(a, b point) (float64, bool) {
+		if a.rating == b.rating {
+			return 0, false
+		}
+		return a.value + (target-a.rating)*(b.value-a.value)/(b.rating-a.rating), true
+	}
+
+	for i := 0; i+1 < len(line); i++ {
+		a, b := line[i], line[i+1]
+		if (a.rating-target)*(b.rating-target) <= 0 {
+			if v, ok := at(a, b); ok {
+				return v, false, true
+			}
+		}
+	}
+
+	a, b := line[0], line[1]
+	if last := len(line) - 1; math.Abs(line[last].rating-target) < math.Abs(line[0].rating-target) {
+		a, b = line[last-1], line[last]
+	}
+	v, ok := at(a, b)
+	return v, true, ok
+}
+
+// String renders the report as report.txt has it.
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Self-play at %s (%s): %d games in %d rounds", r.Variant.Name, r.Variant.HTMLName, r.Played, r.Rounds)
+	if r.Failed > 0 {
+		fmt.Fprintf(&sb, ", %d failed", r.Failed)
+	}
+	fmt.Fprintf(&sb, ", %s\n\n", r.Elapsed.Round(time.Second))
+
+	width := len("Entrant")
+	for _, s := range r.Standings {
+		width = max(width, len([]rune(s.Entrant.Name)))
+	}
+	fmt.Fprintf(&sb, "%-*s  %6s  %4s  %5s  %6s\n", width, "Entrant", "Rating", "RD", "Games", "Score")
+	for _, s := range r.Standings {
+		pct := 0.0
+		if s.Games > 0 {
+			pct = 100 * s.Points / float64(s.Games)
+		}
+		fmt.Fprintf(&sb, "%-*s  %6.0f  %4.0f  %5d  %5.1f%%\n",
+			width, s.Entrant.Name, s.Rating.R, s.Rating.RD, s.Games, pct)
+	}
+
+	fmt.Fprintf(&sb, "\nLadder, %.0f points a rung from the top\n", r.Gap)
+	for _, rung := range r.Rungs {
+		fmt.Fprintf(&sb, "%-8s  %6.0f  target %6.0f  off %+5.0f\n", rung.Key, rung.Rating, rung.Target, rung.Rating-rung.Target)
+		if len(rung.Settings) == 0 && rung.Rating != rung.Target {
+			sb.WriteString("          no variants measured: add some with --selfplay-variants or --selfplay-sweep\n")
+		}
+		for _, s := range rung.Settings {
+			fmt.Fprintf(&sb, "          %s %g -> %g", s.Knob, s.From, s.To)
+			if s.Extrapolated {
+				sb.WriteString(" (extrapolated)")
+			}
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
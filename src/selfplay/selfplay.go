@@ -0,0 +1,196 @@
+// Package selfplay is the bot calibration harness behind `lio --selfplay`. It
+// plays the persona ladder (engine.Personas) and parameter variants of its
+// rungs against each other, rates every entrant with Glicko-2, and reports how
+// far each rung sits from an evenly spaced ladder and which setting would close
+// the distance. The ladder's Strength pips say which rung is stronger; a run
+// says by how much, and gives a new persona a reproducible way to be placed.
+//
+// Games run the production bot path end to end: the blind deploy and every
+// move go through the engine dispatcher's worker pool, and each side's search
+// is budgeted off a real, running clock by room.BotSearchLimits, handicap
+// pause included. A side that overruns its clock flags. A persona measured
+// here is the persona a player meets.
+package selfplay
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/dechristopher/lio/pools"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/variant"
+)
+
+// Defaults for the --selfplay flags.
+const (
+	// DefaultControl is the time control games are played at: the blitz deploy
+	// control, the one bot games are most often created with.
+	DefaultControl = "half-one-blitz-deploy"
+	// DefaultRounds plays every pairing ten times, five with each color.
+	DefaultRounds = 10
+	// DefaultGap is the rating gap between adjacent rungs the report aims
+	// for: a rung scores about three games in four against the one below.
+	DefaultGap = 200.0
+	// DefaultOut is the directory the PGNs and report are written to.
+	DefaultOut = "selfplay-results"
+)
+
+// Config is one self-play run.
+type Config struct {
+	// Variant is the time control every game is played at. A deploy variant
+	// opens every game with both bots' blind deploy.
+	Variant variant.Variant
+	// Entrants are the players: the ladder rungs, weakest first, and the
+	// variants. The rungs play a full round-robin; a variant plays every rung
+	// but no other variant, since it is there to be measured against the
+	// ladder.
+	Entrants []Entrant
+	// Rounds is how many times every pairing is played, colors alternating
+	// from round to round. Each round is one Glicko-2 rating period.
+	Rounds int
+	// Parallel is how many games are played at once. The games share the
+	// engine's worker pool, so more than there are cores squeezes every bot's
+	// think time, as a busy instance does.
+	Parallel int
+	// Gap is the target rating gap between adjacent rungs.
+	Gap float64
+	// Out is the directory games.pgn and report.txt are written to.
+	Out string
+}
+
+// NewConfig is a run at the named time control (a variant HTMLName) with the
+// whole ladder, the variants of a ParseVariants spec, and Sweep's variants
+// when sweep is set. The numeric settings take their defaults.
+func NewConfig(control, variants string, sweep bool) (Config, error) {
+	v, ok := pools.Map[control]
+	if !ok || v.Casual || v.Days > 0 {
+		return Config{}, fmt.Errorf("no realtime time control %q", control)
+	}
+
+	entrants := Ladder()
+	parsed, err := ParseVariants(variants)
+	if err != nil {
+		return Config{}, err
+	}
+	entrants = append(entrants, parsed...)
+	if sweep {
+		entrants = append(entrants, Sweep()...)
+	}
+
+	return Config{
+		Variant:  v,
+		Entrants: entrants,
+		Rounds:   DefaultRounds,
+		Parallel: runtime.GOMAXPROCS(0),
+		Gap:      DefaultGap,
+		Out:      DefaultOut,
+	}, nil
+}
+
+// Run plays the schedule cfg describes, writes every game to Out/games.pgn as
+// it finishes and the report to Out/report.txt, and returns the report. The
+// engine dispatcher must be up. A game that fails (the engine answering with
+// an illegal move) is logged and left out of the ratings.
+func Run(cfg Config) (*Report, error) {
+	if cfg.Rounds < 1 || cfg.Parallel < 1 {
+		return nil, errors.New("self-play needs at least one round and one game at a time")
+	}
+	if len(ladder(cfg.Entrants)) < 2 {
+		return nil, errors.New("self-play needs at least two ladder rungs")
+	}
+	if err := os.MkdirAll(cfg.Out, 0o755); err != nil {
+		return nil, err
+	}
+	pgns, err := os.Create(filepath.Join(cfg.Out, "games.pgn"))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = pgns.Close() }()
+
+	games := schedule(cfg.Entrants, cfg.Rounds)
+	util.Info(str.CSelf, "playing %d games of %s between %d entrants over %d rounds, %d at a time",
+		len(games), cfg.Variant.Name, len(cfg.Entrants), cfg.Rounds, cfg.Parallel)
+
+	var (
+		mu       sync.Mutex
+		records  []record
+		finished int
+		writeErr error
+	)
+	start := time.Now()
+	next := make(chan pairing)
+	var wg sync.WaitGroup
+	for range cfg.Parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range next {
+				white, black := cfg.Entrants[p.white], cfg.Entrants[p.black]
+				rec, err := play(cfg.Variant, p, white, black)
+
+				mu.Lock()
+				finished++
+				if err != nil {
+					util.Error(str.CSelf, "game %d/%d %s - %s failed: %s",
+						finished, len(games), white.Name, black.Name, err.Error())
+				} else {
+					records = append(records, rec)
+					if _, err := fmt.Fprintf(pgns, "%s\n\n", rec.pgn); err != nil && writeErr == nil {
+						writeErr = err
+					}
+					util.Info(str.CSelf, "game %d/%d %s - %s %s by %s in %d plies",
+						finished, len(games), white.Name, black.Name, rec.result, rec.reason, rec.plies)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, p := range games {
+		next <- p
+	}
+	close(next)
+	wg.Wait()
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	report := newReport(cfg, records, len(games)-len(records))
+	report.Elapsed = time.Since(start)
+	if err := os.WriteFile(filepath.Join(cfg.Out, "report.txt"), []byte(report.String()), 0o644); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// schedule lists every game of the run, round by round. Ladder rungs meet
+// every other rung and variants meet every rung; within a round the pairings
+// alternate colors, and each pairing swaps colors from one round to the next.
+func schedule(entrants []Entrant, rounds int) []pairing {
+	var pairs [][2]int
+	for i := range entrants {
+		for j := i + 1; j < len(entrants); j++ {
+			if entrants[i].variant() && entrants[j].variant() {
+				continue
+			}
+			pairs = append(pairs, [2]int{i, j})
+		}
+	}
+
+	out := make([]pairing, 0, rounds*len(pairs))
+	for r := range rounds {
+		for k, p := range pairs {
+			white, black := p[0], p[1]
+			if (r+k)%2 == 1 {
+				white, black = black, white
+			}
+			out = append(out, pairing{round: r, white: white, black: black})
+		}
+	}
+	return out
+}
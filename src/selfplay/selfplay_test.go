@@ -0,0 +1,217 @@
+package selfplay
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/bus"
+	"github.com/dechristopher/lio/clock"
+	"github.com/dechristopher/lio/dispatch"
+	"github.com/dechristopher/lio/engine"
+	"github.com/dechristopher/lio/rating"
+	"github.com/dechristopher/lio/variant"
+)
+
+// TestMain brings up what a self-play game runs on: the bus (clock flips
+// publish to it) and the engine dispatcher, with the deploy search shallow so
+// the deploy cache warms quickly. Strength is not under test here.
+func TestMain(m *testing.M) {
+	bus.Up()
+	engine.DeploySearchDepth = 1
+	dispatch.UpEngine()
+	os.Exit(m.Run())
+}
+
+// TestParseVariants checks a spec applies its changes to the named rung and
+// rejects what it cannot apply.
+func TestParseVariants(t *testing.T) {
+	got, err := ParseVariants("knight:BlunderRate=0.08; rook:maxdepth=5,VarietyMargin=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("%d variants, want 2", len(got))
+	}
+	if p := got[0].Persona; p.Key != "knight" || p.BlunderRate != 0.08 || p.MaxDepth != engine.PersonaByKey("knight").MaxDepth {
+		t.Errorf("knight variant = %+v", p)
+	}
+	if p := got[1].Persona; p.MaxDepth != 5 || p.VarietyMargin != 2 {
+		t.Errorf("rook variant = %+v", p)
+	}
+	if got[1].Name != "Rook MaxDepth=5 VarietyMargin=2" || got[1].key() != "rook:MaxDepth=5,VarietyMargin=2" {
+		t.Errorf("rook variant named %q / %q", got[1].Name, got[1].key())
+	}
+
+	for _, spec := range []string{
+		"knight",                  // no changes
+		"king:MaxDepth=3",         // no such persona
+		"knight:Speed=3",          // no such knob
+		"knight:BlunderRate=1.5",  // out of range
+		"knight:MaxDepth=2.5",     // not whole
+		"knight:BlunderRate",      // no value
+		"knight:RandomDeploy=yes", // not a number
+	} {
+		if _, err := ParseVariants(spec); err == nil {
+			t.Errorf("ParseVariants(%q) accepted", spec)
+		}
+	}
+}
+
+// TestSweep checks every rung below the top gets a variant each side of its
+// main dial.
+func TestSweep(t *testing.T) {
+	sweep := Sweep()
+	if want := 2 * (len(engine.Personas) - 1); len(sweep) != want {
+		t.Fatalf("%d variants, want %d", len(sweep), want)
+	}
+	for i := 0; i < len(sweep); i += 2 {
+		lo, hi := sweep[i], sweep[i+1]
+		k, _ := knobByName(lo.Changes[0].Knob)
+		at := k.get(engine.PersonaByKey(lo.Base))
+		if !(lo.Changes[0].Value < at && at < hi.Changes[0].Value) {
+			t.Errorf("%s %s sweep %g, %g does not straddle %g",
+				lo.Base, k.name, lo.Changes[0].Value, hi.Changes[0].Value, at)
+		}
+	}
+}
+
+// TestSchedule checks the ladder plays a full round-robin with colors
+// balanced over an even number of rounds, and variants meet only the ladder.
+func TestSchedule(t *testing.T) {
+	variants, _ := ParseVariants("pawn:BlunderRate=0.2;knight:BlunderRate=0.05")
+	entrants := append(Ladder(), variants...)
+	games := schedule(entrants, 4)
+
+	whites := map[[2]int]int{}
+	for _, g := range games {
+		if entrants[g.white].variant() && entrants[g.black].variant() {
+			t.Fatalf("variants %s and %s paired", entrants[g.white].Name, entrants[g.black].Name)
+		}
+		whites[[2]int{g.white, g.black}]++
+	}
+	rungs := len(engine.Personas)
+	if want := 4 * (rungs*(rungs-1)/2 + 2*rungs); len(games) != want {
+		t.Errorf("%d games, want %d", len(games), want)
+	}
+	for pair, n := range whites {
+		if back := whites[[2]int{pair[1], pair[0]}]; n != 2 || back != 2 {
+			t.Errorf("%s - %s: %d games white, %d black; want 2 each",
+				entrants[pair[0]].Name, entrants[pair[1]].Name, n, back)
+		}
+	}
+}
+
+// TestRateOrdersByResults checks a chain of decisive results rates the chain
+// in order, whatever order the games are listed in.
+func TestRateOrdersByResults(t *testing.T) {
+	var records []record
+	for round := range 5 {
+		for a := 0; a < 3; a++ {
+			for b := a + 1; b < 3; b++ {
+				// the higher index always wins
+				records = append(records, record{pairing: pairing{round: round, white: a, black: b}, score: rating.Loss})
+			}
+		}
+	}
+	ratings := rate(3, 5, records)
+	if !(ratings[0].R < ratings[1].R && ratings[1].R < ratings[2].R) {
+		t.Errorf("ratings %.0f, %.0f, %.0f not in order", ratings[0].R, ratings[1].R, ratings[2].R)
+	}
+
+	reversed := make([]record, len(records))
+	for i, rec := range records {
+		reversed[len(records)-1-i] = rec
+	}
+	for i, r := range rate(3, 5, reversed) {
+		if math.Abs(r.R-ratings[i].R) > 1e-9 {
+			t.Errorf("entrant %d rated %.4f listed backwards, %.4f forwards", i, r.R, ratings[i].R)
+		}
+	}
+}
+
+// TestSolve checks the fitted value interpolates between the measured points
+// that span the target, and extends the nearer end when none do.
+func TestSolve(t *testing.T) {
+	// blundering more rates lower
+	line := []point{{0.12, 1300}, {0.06, 1400}, {0.18, 1150}}
+
+	v, extrapolated, ok := solve(append([]point(nil), line...), 1350)
+	if !ok || extrapolated || math.Abs(v-0.09) > 1e-9 {
+		t.Errorf("solve 1350 = %g (extrapolated %t, ok %t), want 0.09", v, extrapolated, ok)
+	}
+	v, extrapolated, ok = solve(append([]point(nil), line...), 1000)
+	if !ok || !extrapolated || math.Abs(v-0.24) > 1e-9 {
+		t.Errorf("solve 1000 = %g (extrapolated %t, ok %t), want 0.24 extrapolated", v, extrapolated, ok)
+	}
+	if _, _, ok := solve([]point{{0.1, 1300}, {0.1, 1310}}, 1200); ok {
+		t.Error("solved a line with one distinct value")
+	}
+}
+
+// TestCalibrate checks each rung is aimed at its place below the top and
+// gets a setting from its single-dial variants.
+func TestCalibrate(t *testing.T) {
+	variants, _ := ParseVariants("pawn:BlunderRate=0.2")
+	entrants := append(Ladder(), variants...)
+	ratings := make([]rating.Rating, len(entrants))
+	for i := range engine.Personas {
+		ratings[i] = rating.Rating{R: 1000 + 150*float64(i)}
+	}
+	// the pawn variant blunders less and rates higher than the pawn
+	ratings[len(ratings)-1] = rating.Rating{R: 1100}
+
+	rungs := calibrate(entrants, ratings, 200)
+	top := 1000 + 150*float64(len(engine.Personas)-1)
+	for n, rung := range rungs {
+		if want := top - 200*float64(len(rungs)-1-n); rung.Target != want {
+			t.Errorf("%s target %.0f, want %.0f", rung.Key, rung.Target, want)
+		}
+	}
+
+	pawn := rungs[0]
+	if len(pawn.Settings) != 1 || pawn.Settings[0].Knob != "BlunderRate" {
+		t.Fatalf("pawn settings = %+v, want one BlunderRate", pawn.Settings)
+	}
+	// the target sits below both measured points: blunder more than the pawn
+	if s := pawn.Settings[0]; !s.Extrapolated || s.To <= s.From {
+		t.Errorf("pawn BlunderRate %g -> %g (extrapolated %t), want higher and extrapolated", s.From, s.To, s.Extrapolated)
+	}
+	if len(rungs[1].Settings) != 0 {
+		t.Errorf("knight got settings %+v with no variants", rungs[1].Settings)
+	}
+}
+
+// TestPlay plays one real game on a short clock and checks it reaches a
+// result with a PGN that records it.
+func TestPlay(t *testing.T) {
+	v := variant.Variant{
+		Name:     "3 s",
+		HTMLName: "three-second-test",
+		Group:    variant.BulletGroup,
+		Control:  clock.TimeControl{Time: clock.ToCTime(3 * time.Second)},
+		Deploy:   true,
+	}
+	ladder := Ladder()
+	white, black := ladder[0], ladder[len(ladder)-1]
+
+	rec, err := play(v, pairing{white: 0, black: len(ladder) - 1}, white, black)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.plies == 0 || rec.reason == "" {
+		t.Errorf("game ended after %d plies by %q", rec.plies, rec.reason)
+	}
+	if rec.result == string(octad.NoOutcome) {
+		t.Error("game recorded without a result")
+	}
+	for _, tag := range []string{`[Result "` + rec.result + `"]`, `[White "BOT ♟︎ Pawn"]`, `[WhiteUID "pawn"]`, `[SetUp "1"]`} {
+		if !strings.Contains(rec.pgn, tag) {
+			t.Errorf("PGN missing %s:\n%s", tag, rec.pgn)
+		}
+	}
+}
@@ -0,0 +1,919 @@
+// Package simul runs simultaneous exhibitions on top of ordinary rooms.
+//
+// A simul is one host against up to MaxBoards participants at once, the host on
+// the same colour on every board. The host creates it, accounts join it from
+// its page, and when the host starts it every participant on the page gets a
+// board: a room.Create'd room with both seats filled, Params.Simul set and the
+// host's seat marked as such (see room/simul.go — the host cannot be present on
+// every board at once, so their seat never counts as absent). Each board has
+// its own clocks, so the host's clock on a board runs only while it is their
+// move there.
+//
+// The host plays from the simul's page, which doubles as their dashboard: every
+// board with its clocks and whose move it is, and a "next board" jump to the
+// next one waiting on them (Next). As in a tournament, the room never calls back
+// in here; it publishes a room.Result on room.ResultChannel when its game ends,
+// and Up subscribes to that. When every board has a result the simul finishes,
+// and its row — the host's tally — is the results summary every board's archived
+// game references through games.simul_id.
+//
+// Each simul runs on its own goroutine, ticking once a second: the tick expires
+// a simul nobody started, and broadcasts the boards to /socket/simul/<id> when
+// they have changed since the last. State is guarded by the simul's own mutex
+// and written through to Postgres (db/simuls.go), which Up restores from.
+package simul
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/bus"
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/pools"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/title"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/variant"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// Status is where a simul is in its life.
+type Status string
+
+const (
+	Created  Status = "created"
+	Started  Status = "started"
+	Finished Status = "finished"
+)
+
+const (
+	// tick is the simul loop's cadence: the most a board change waits before
+	// the dashboard hears of it.
+	tick = time.Second
+	// startDeadline closes a simul its host never started.
+	startDeadline = 2 * time.Hour
+	// finishedRetention is how long a finished simul stays in memory, serving
+	// its page from here rather than from Postgres.
+	finishedRetention = time.Hour
+
+	// MaxBoards bounds how many participants a host may take on.
+	MaxBoards = 30
+	// MaxNameLength bounds a simul's name.
+	MaxNameLength = 60
+)
+
+var (
+	// ErrNotFound is an unknown or no longer running simul.
+	ErrNotFound = errors.New("no such simul")
+	// ErrStarted refuses an entry to a simul already underway.
+	ErrStarted = errors.New("that simul has already started")
+	// ErrFull refuses an entry past the host's board count.
+	ErrFull = errors.New("that simul is full")
+	// ErrHost refuses the host a board against themselves.
+	ErrHost = errors.New("you are hosting this simul")
+	// ErrBlocked refuses an entry when either the host or the participant has
+	// blocked the other. It does not say which.
+	ErrBlocked = errors.New("you cannot join this simul")
+	// ErrUnchecked refuses a start whose block check failed; the host can try
+	// again.
+	ErrUnchecked = errors.New("the simul could not be started, try again")
+	// ErrNotHost refuses a start or cancel from anyone but the host.
+	ErrNotHost = errors.New("only the host can do that")
+	// ErrHosting refuses a second simul to a host with one open.
+	ErrHosting = errors.New("you are already hosting a simul")
+	// ErrHostBusy refuses a start while the host is in another game.
+	ErrHostBusy = errors.New("finish your game in progress first")
+	// ErrNobody refuses a start with no participant on the page.
+	ErrNobody = errors.New("nobody is here to play yet")
+	// ErrBadConfig refuses a creation it cannot run.
+	ErrBadConfig = errors.New("that simul configuration is not valid")
+)
+
+var resultSub sync.Once
+
+// registry holds every simul this process runs, keyed by id.
+var registry sync.Map
+
+// Config is what a simul is created with. None of it changes afterwards.
+type Config struct {
+	Name    string
+	Variant variant.Variant
+	// HostColor is the host's colour on every board.
+	HostColor octad.Color
+	MaxBoards int
+	// Host is the hosting account; its UID is unused (the host's session is
+	// the one that starts the simul).
+	Host player.Identity
+}
+
+// Board is one participant's board.
+type Board struct {
+	UserID   int64
+	Username string
+	Title    title.Title
+	// RoomID is "" until the simul starts. Result is "" while the board is
+	// on, then "w", "b", "d", or "v" for a board that never finished.
+	RoomID string
+	Result string
+}
+
+// Simul is one running exhibition.
+type Simul struct {
+	ID  string
+	cfg Config
+
+	mu         sync.Mutex
+	status     Status
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+	// boards are in the order their participants joined; byUser indexes them.
+	boards []*Board
+	byUser map[int64]*Board
+	// frame is the last broadcast, so an unchanged tick sends nothing.
+	frame []byte
+}
+
+// Summary is a simul as the /simul list shows it.
+type Summary struct {
+	ID        string
+	Name      string
+	Variant   variant.Variant
+	HostName  string
+	HostColor octad.Color
+	MaxBoards int
+	Boards    int
+	Status    Status
+	Wins      int
+	Draws     int
+	Losses    int
+}
+
+// Up restores every unfinished simul and starts listening for board results.
+// It runs at boot after the rooms are rehydrated, so a restored board can find
+// its room; a board whose room did not survive the restart is void.
+func Up() {
+	resultSub.Do(func() {
+		if err := room.ResultChannel.Subscribe(onResult); err != nil {
+			panic(err)
+		}
+	})
+
+	infos, err := db.OpenSimuls()
+	if err != nil {
+		util.Error(str.CSiml, "simul restore failed: %s", err.Error())
+		return
+	}
+	for _, info := range infos {
+		s, err := restore(info)
+		if err != nil {
+			util.Error(str.CSiml, "[%s] simul restore failed: %s", info.ID, err.Error())
+			continue
+		}
+		registry.Store(s.ID, s)
+		go s.run()
+		util.Info(str.CSiml, "[%s] simul restored (%s, %d boards)", s.ID, s.status, len(s.boards))
+	}
+}
+
+// Create validates and opens a new simul for entries.
+func Create(cfg Config) (*Simul, error) {
+	cfg.Name = strings.TrimSpace(cfg.Name)
+	if cfg.Name == "" || len(cfg.Name) > MaxNameLength || cfg.Variant.Casual ||
+		cfg.Host.UserID == nil || cfg.MaxBoards < 1 || cfg.MaxBoards > MaxBoards ||
+		(cfg.HostColor != octad.White && cfg.HostColor != octad.Black) {
+		return nil, ErrBadConfig
+	}
+	if Hosting(*cfg.Host.UserID) != nil {
+		return nil, ErrHosting
+	}
+
+	s := newSimul(config.GenerateCode(8, config.Base58), cfg)
+	s.createdAt = time.Now()
+	if err := db.InsertSimul(s.info()); err != nil {
+		return nil, err
+	}
+	registry.Store(s.ID, s)
+	go s.run()
+
+	util.Info(str.CSiml, "[%s] simul %q created by %s", s.ID, cfg.Name, cfg.Host.Username)
+	return s, nil
+}
+
+// Get returns a running (or recently finished) simul, nil if there is none.
+func Get(id string) *Simul {
+	if v, ok := registry.Load(id); ok {
+		return v.(*Simul)
+	}
+	return nil
+}
+
+// Hosting returns the unfinished simul an account hosts, nil if none.
+func Hosting(userID int64) *Simul {
+	var out *Simul
+	registry.Range(func(_, v interface{}) bool {
+		s := v.(*Simul)
+		if *s.cfg.Host.UserID == userID && s.Status() != Finished {
+			out = s
+			return false
+		}
+		return true
+	})
+	return out
+}
+
+// List summarises the simuls this process holds, open ones first, each
+// group oldest first.
+func List() []Summary {
+	type entry struct {
+		sum     Summary
+		created time.Time
+	}
+	var all []entry
+	registry.Range(func(_, v interface{}) bool {
+		s := v.(*Simul)
+		s.mu.Lock()
+		created := s.createdAt
+		s.mu.Unlock()
+		all = append(all, entry{s.Summary(), created})
+		return true
+	})
+	sort.SliceStable(all, func(i, j int) bool {
+		if (all[i].sum.Status == Finished) != (all[j].sum.Status == Finished) {
+			return all[j].sum.Status == Finished
+		}
+		return all[i].created.Before(all[j].created)
+	})
+	out := make([]Summary, len(all))
+	for i, e := range all {
+		out[i] = e.sum
+	}
+	return out
+}
+
+// Channel is the socket channel a simul's page listens on.
+func Channel(id string) string {
+	return "simul/" + id
+}
+
+// Connect sends a freshly connected socket the simul's current state.
+func Connect(sock *channel.Socket, id string) {
+	s := Get(id)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	payload := s.payloadLocked()
+	s.mu.Unlock()
+	sock.Enqueue(payload.Marshal())
+}
+
+func newSimul(id string, cfg Config) *Simul {
+	return &Simul{
+		ID:     id,
+		cfg:    cfg,
+		status: Created,
+		byUser: make(map[int64]*Board),
+	}
+}
+
+// Config returns the simul's configuration.
+func (s *Simul) Config() Config {
+	return s.cfg
+}
+
+// Status returns where the simul is in its life.
+func (s *Simul) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// IsHost reports whether an account hosts the simul.
+func (s *Simul) IsHost(userID int64) bool {
+	return *s.cfg.Host.UserID == userID
+}
+
+// Summary describes the simul for the list.
+func (s *Simul) Summary() Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, d, l := s.tallyLocked()
+	return Summary{
+		ID:        s.ID,
+		Name:      s.cfg.Name,
+		Variant:   s.cfg.Variant,
+		HostName:  s.cfg.Host.Username,
+		HostColor: s.cfg.HostColor,
+		MaxBoards: s.cfg.MaxBoards,
+		Boards:    len(s.boards),
+		Status:    s.status,
+		Wins:      w,
+		Draws:     d,
+		Losses:    l,
+	}
+}
+
+// Payload is the simul's live state, as the page is first rendered with.
+func (s *Simul) Payload() proto.SimulPayload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.payloadLocked()
+}
+
+// Entered reports whether an account has a board in the simul.
+func (s *Simul) Entered(userID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.byUser[userID]
+	return ok
+}
+
+// Join gives an account a board, up to the host's count, until the start.
+func (s *Simul) Join(seat player.Identity) error {
+	if seat.UserID == nil {
+		return ErrNotFound
+	}
+	if s.IsHost(*seat.UserID) {
+		return ErrHost
+	}
+	// a board is a pairing like any other: a block either way refuses it, and
+	// so does a failed check (the fail-closed room.SeatBlocked rule)
+	if blocked, err := db.Blocked(*s.cfg.Host.UserID, *seat.UserID); blocked || err != nil {
+		if err != nil {
+			util.Error(str.CSiml, "[%s] block check for %d failed: %s", s.ID, *seat.UserID, err.Error())
+		}
+		return ErrBlocked
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byUser[*seat.UserID]; ok {
+		return nil
+	}
+	if s.status != Created {
+		return ErrStarted
+	}
+	if len(s.boards) >= s.cfg.MaxBoards {
+		return ErrFull
+	}
+	b := &Board{UserID: *seat.UserID, Username: seat.Username, Title: seat.Title}
+	s.boards = append(s.boards, b)
+	s.byUser[b.UserID] = b
+	s.saveBoardLocked(b)
+	s.frame = nil
+	util.Info(str.CSiml, "[%s] %s joined", s.ID, b.Username)
+	return nil
+}
+
+// Leave takes an account's entry back. Only before the start: once a board
+// is on, leaving it is resigning it.
+func (s *Simul) Leave(userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byUser[userID]; !ok || s.status != Created {
+		return nil
+	}
+	s.dropLocked(userID)
+	return nil
+}
+
+// Cancel closes a simul its host no longer wants to give, before the start.
+func (s *Simul) Cancel(userID int64) error {
+	if !s.IsHost(userID) {
+		return ErrNotHost
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != Created {
+		return ErrStarted
+	}
+	s.finishLocked(time.Now())
+	return nil
+}
+
+// Start gives every participant on the simul's page a board against the host,
+// seated for the session it is on the page with. hostUID is the host's session:
+// the one their boards are seated for. A participant who is not on the page,
+// or who is in a game elsewhere, loses their entry — a board nobody is sitting
+// at would only run the host's clock on the first move. So does one with a
+// block against the host made since they joined.
+func (s *Simul) Start(hostUID string) error {
+	host := s.cfg.Host
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status != Created {
+		return ErrStarted
+	}
+	if room.Engaged(hostUID, *host.UserID) {
+		return ErrHostBusy
+	}
+	present := s.presentLocked()
+	ids := make([]int64, 0, len(present))
+	for id := range present {
+		ids = append(ids, id)
+	}
+	blocked, err := db.BlockedAmong(*host.UserID, ids)
+	if err != nil {
+		util.Error(str.CSiml, "[%s] block check failed: %s", s.ID, err.Error())
+		return ErrUnchecked
+	}
+	for id := range blocked {
+		delete(present, id)
+	}
+	if len(present) == 0 {
+		return ErrNobody
+	}
+
+	s.status = Started
+	s.startedAt = time.Now()
+	for _, b := range append([]*Board(nil), s.boards...) {
+		uid, ok := present[b.UserID]
+		if !ok {
+			s.dropLocked(b.UserID)
+			continue
+		}
+		if err := s.startBoardLocked(b, hostUID, uid); err != nil {
+			util.Error(str.CSiml, "[%s] board for %s failed to start: %s", s.ID, b.Username, err.Error())
+			b.Result = "v"
+			s.saveBoardLocked(b)
+		}
+	}
+	s.saveStateLocked()
+	s.frame = nil
+	util.Info(str.CSiml, "[%s] simul started with %d boards", s.ID, len(s.boards))
+
+	if s.resolvedLocked() {
+		s.finishLocked(time.Now())
+	}
+	return nil
+}
+
+// Next returns the room of the next board where it is the host's move, going
+// round the boards from the one after from (the board the host is leaving;
+// "" starts at the first). It returns "" when the host is waiting everywhere.
+func (s *Simul) Next(from string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextLocked(from)
+}
+
+// presentLocked maps every participant on the simul's page to the session it
+// is there with, leaving out anyone already committed to a game.
+func (s *Simul) presentLocked() map[int64]string {
+	out := make(map[int64]string)
+	for _, sock := range channel.Map.GetSockMap(Channel(s.ID)).Sockets() {
+		if _, ok := s.byUser[sock.Acct.ID]; !ok || sock.Acct.ID == 0 {
+			continue
+		}
+		if _, seen := out[sock.Acct.ID]; seen {
+			continue
+		}
+		if room.Engaged(sock.UID, sock.Acct.ID) {
+			continue
+		}
+		out[sock.Acct.ID] = sock.UID
+	}
+	return out
+}
+
+// dropLocked removes an entry outright.
+func (s *Simul) dropLocked(userID int64) {
+	delete(s.byUser, userID)
+	for i, b := range s.boards {
+		if b.UserID == userID {
+			s.boards = append(s.boards[:i], s.boards[i+1:]...)
+			break
+		}
+	}
+	if err := db.DeleteSimulBoard(s.ID, userID); err != nil {
+		util.Error(str.CDB, "[%s] simul entry delete failed user=%d: %s", s.ID, userID, err.Error())
+	}
+	s.frame = nil
+}
+
+// startBoardLocked creates a participant's room and sends them to it.
+func (s *Simul) startBoardLocked(b *Board, hostUID, uid string) error {
+	host := s.cfg.Host
+	hostSeat := &player.Player{
+		ID:       hostUID,
+		UserID:   host.UserID,
+		Username: host.Username,
+		Title:    host.Title,
+	}
+	guestSeat := &player.Player{
+		ID:       uid,
+		UserID:   &b.UserID,
+		Username: b.Username,
+		Title:    b.Title,
+	}
+
+	params := room.NewParams(player.Identity{
+		UID:      hostUID,
+		UserID:   host.UserID,
+		Username: host.Username,
+		Title:    host.Title,
+	}, s.cfg.Variant)
+	params.Players[s.cfg.HostColor] = hostSeat
+	params.Players[s.cfg.HostColor.Other()] = guestSeat
+	// an exhibition is played for the occasion, not the ladder
+	params.Rated = false
+	params.Simul = s.ID
+	params.SimulHost = s.cfg.HostColor
+
+	r, err := room.Create(params)
+	if err != nil {
+		return err
+	}
+	b.RoomID = r.ID
+	s.saveBoardLocked(b)
+
+	redirect := proto.RedirectMessage{Location: "/" + r.ID}
+	frame := redirect.Marshal()
+	for _, sock := range channel.Map.GetSockMap(Channel(s.ID)).SocketsFor(uid) {
+		sock.Enqueue(frame)
+	}
+	util.DebugFlag("simul", str.CSiml, "[%s] board %s vs %s in %s", s.ID, host.Username, b.Username, r.ID)
+	return nil
+}
+
+// run is the simul's loop. It exits once the simul has finished and its final
+// state has gone out.
+func (s *Simul) run() {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for range ticker.C {
+		if s.step(time.Now()) {
+			time.AfterFunc(finishedRetention, func() { registry.Delete(s.ID) })
+			return
+		}
+	}
+}
+
+// step advances the simul by one tick, reporting whether it is over.
+func (s *Simul) step(now time.Time) (over bool) {
+	s.mu.Lock()
+	var frame []byte
+	defer func() {
+		over = s.status == Finished
+		s.mu.Unlock()
+		if frame != nil {
+			channel.Broadcast(frame, channel.SocketContext{Channel: Channel(s.ID)})
+		}
+	}()
+
+	switch s.status {
+	case Created:
+		if now.Sub(s.createdAt) >= startDeadline {
+			util.Info(str.CSiml, "[%s] simul never started, closing", s.ID)
+			s.finishLocked(now)
+		}
+	case Started:
+		// a board whose room is gone without having reported is void
+		for _, b := range s.boards {
+			if b.Result != "" || b.RoomID == "" {
+				continue
+			}
+			if _, err := room.Get(b.RoomID); err != nil {
+				b.Result = "v"
+				s.saveBoardLocked(b)
+			}
+		}
+		if s.resolvedLocked() {
+			s.finishLocked(now)
+		}
+	}
+
+	// the boards' clocks move every tick while the simul is on, so the frame
+	// is compared rather than flagged dirty
+	payload := s.payloadLocked()
+	if next := payload.Marshal(); !bytes.Equal(next, s.frame) {
+		s.frame = next
+		frame = next
+	}
+	return
+}
+
+// onResult is the room.ResultChannel subscriber.
+func onResult(e bus.Event) {
+	if len(e.Data) == 0 {
+		return
+	}
+	res, ok := e.Data[0].(room.Result)
+	if !ok || res.Simul == "" {
+		return
+	}
+	if s := Get(res.Simul); s != nil {
+		s.applyResult(res)
+	}
+}
+
+// applyResult records a finished board. A result for a board already
+// resolved (a restored room re-announcing itself) is ignored.
+func (s *Simul) applyResult(res room.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.boards {
+		if b.RoomID != res.RoomID || b.Result != "" {
+			continue
+		}
+		b.Result = resultCode(res.Outcome)
+		s.saveBoardLocked(b)
+		s.saveStateLocked()
+		s.frame = nil
+		if s.status == Started && s.resolvedLocked() {
+			s.finishLocked(time.Now())
+		}
+		return
+	}
+}
+
+// resultCode is the simul_boards result for an outcome.
+func resultCode(o octad.Outcome) string {
+	switch o {
+	case octad.WhiteWon:
+		return "w"
+	case octad.BlackWon:
+		return "b"
+	case octad.Draw:
+		return "d"
+	}
+	return "v"
+}
+
+// hostResult reads a board's result from the host's side: "win", "draw",
+// "loss", "void", or "" while it is on.
+func hostResult(result string, host octad.Color) string {
+	switch result {
+	case "":
+		return ""
+	case "d":
+		return "draw"
+	case "v":
+		return "void"
+	}
+	if result == host.String() {
+		return "win"
+	}
+	return "loss"
+}
+
+// tallyLocked is the host's wins, draws and losses so far.
+func (s *Simul) tallyLocked() (wins, draws, losses int) {
+	for _, b := range s.boards {
+		switch hostResult(b.Result, s.cfg.HostColor) {
+		case "win":
+			wins++
+		case "draw":
+			draws++
+		case "loss":
+			losses++
+		}
+	}
+	return
+}
+
+// resolvedLocked reports every board has a result.
+func (s *Simul) resolvedLocked() bool {
+	for _, b := range s.boards {
+		if b.Result == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// finishLocked closes the simul and writes its summary.
+func (s *Simul) finishLocked(now time.Time) {
+	s.status = Finished
+	s.finishedAt = now
+	s.saveStateLocked()
+	s.frame = nil
+	w, d, l := s.tallyLocked()
+	util.Info(str.CSiml, "[%s] simul finished +%d =%d -%d", s.ID, w, d, l)
+}
+
+// nextLocked is Next under the lock. Reading each board takes its room's
+// lock; a room never calls in here, so the order cannot invert.
+func (s *Simul) nextLocked(from string) string {
+	start := 0
+	for i, b := range s.boards {
+		if b.RoomID != "" && b.RoomID == from {
+			start = i + 1
+			break
+		}
+	}
+	for n := 0; n < len(s.boards); n++ {
+		b := s.boards[(start+n)%len(s.boards)]
+		if b.Result != "" || b.RoomID == "" {
+			continue
+		}
+		r, err := room.Get(b.RoomID)
+		if err != nil {
+			continue
+		}
+		if st := r.BoardStatus(); st.Live && st.ToMove == s.cfg.HostColor {
+			return b.RoomID
+		}
+	}
+	return ""
+}
+
+// payloadLocked builds the live-state frame.
+func (s *Simul) payloadLocked() proto.SimulPayload {
+	w, d, l := s.tallyLocked()
+	out := proto.SimulPayload{
+		ID:     s.ID,
+		Status: string(s.status),
+		Wins:   w,
+		Draws:  d,
+		Losses: l,
+		Boards: make([]proto.SimulBoard, 0, len(s.boards)),
+	}
+	for _, b := range s.boards {
+		row := proto.SimulBoard{
+			Name:      b.Username,
+			Title:     b.Title.Code,
+			TitleName: b.Title.Name,
+			RoomID:    b.RoomID,
+			Result:    hostResult(b.Result, s.cfg.HostColor),
+		}
+		if b.Result == "" && b.RoomID != "" {
+			if r, err := room.Get(b.RoomID); err == nil {
+				st := r.BoardStatus()
+				row.HostToMove = st.Live && st.ToMove == s.cfg.HostColor
+				row.Plies = st.Plies
+				row.HostClock, row.PlayerClock = st.WhiteCenti, st.BlackCenti
+				if s.cfg.HostColor == octad.Black {
+					row.HostClock, row.PlayerClock = st.BlackCenti, st.WhiteCenti
+				}
+			}
+		}
+		out.Boards = append(out.Boards, row)
+	}
+	if s.status == Started {
+		out.Next = s.nextLocked("")
+	}
+	return out
+}
+
+// info is the simul's simuls row.
+func (s *Simul) info() db.SimulInfo {
+	w, d, l := s.tallyLocked()
+	return db.SimulInfo{
+		ID:         s.ID,
+		Name:       s.cfg.Name,
+		Variant:    s.cfg.Variant.HTMLName,
+		HostUserID: s.cfg.Host.UserID,
+		HostName:   s.cfg.Host.Username,
+		HostColor:  colorName(s.cfg.HostColor),
+		MaxBoards:  s.cfg.MaxBoards,
+		Status:     string(s.status),
+		Wins:       w,
+		Draws:      d,
+		Losses:     l,
+		CreatedAt:  s.createdAt,
+		StartedAt:  s.startedAt,
+		FinishedAt: s.finishedAt,
+	}
+}
+
+// saveStateLocked writes the simul's progress and the host's tally.
+func (s *Simul) saveStateLocked() {
+	if err := db.UpdateSimulState(s.info()); err != nil {
+		util.Error(str.CDB, "[%s] simul state write failed: %s", s.ID, err.Error())
+	}
+}
+
+// saveBoardLocked writes one board.
+func (s *Simul) saveBoardLocked(b *Board) {
+	err := db.SaveSimulBoard(s.ID, db.SimulBoard{
+		UserID:   b.UserID,
+		Username: b.Username,
+		RoomID:   b.RoomID,
+		Result:   b.Result,
+	})
+	if err != nil {
+		util.Error(str.CDB, "[%s] simul board write failed user=%d: %s", s.ID, b.UserID, err.Error())
+	}
+}
+
+// colorName is a colour's simuls.host_color value.
+func colorName(c octad.Color) string {
+	if c == octad.Black {
+		return "black"
+	}
+	return "white"
+}
+
+// parseColor reads a simuls.host_color value.
+func parseColor(name string) octad.Color {
+	if name == "black" {
+		return octad.Black
+	}
+	return octad.White
+}
+
+// restore rebuilds an unfinished simul from its rows.
+func restore(info db.SimulInfo) (*Simul, error) {
+	v, ok := pools.Map[info.Variant]
+	if !ok {
+		return nil, errors.New("unknown variant " + info.Variant)
+	}
+	if info.HostUserID == nil {
+		return nil, errors.New("host account is gone")
+	}
+	s := newSimul(info.ID, Config{
+		Name:      info.Name,
+		Variant:   v,
+		HostColor: parseColor(info.HostColor),
+		MaxBoards: info.MaxBoards,
+		Host: player.Identity{
+			UserID:   info.HostUserID,
+			Username: info.HostName,
+		},
+	})
+	s.status = Status(info.Status)
+	s.createdAt = info.CreatedAt
+	s.startedAt = info.StartedAt
+
+	boards, err := db.SimulBoards(info.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range boards {
+		b := &Board{UserID: r.UserID, Username: r.Username, RoomID: r.RoomID, Result: r.Result}
+		s.boards = append(s.boards, b)
+		s.byUser[b.UserID] = b
+		if b.Result != "" || b.RoomID == "" {
+			continue
+		}
+		// a board whose room survived the restart finishes and reports as
+		// usual; one whose room did not is void
+		if _, err := room.Get(b.RoomID); err != nil {
+			b.Result = "v"
+			s.saveBoardLocked(b)
+		}
+	}
+	if s.status == Started && s.resolvedLocked() {
+		s.finishLocked(time.Now())
+	}
+	return s, nil
+}
+
+// Archived reads a finished simul this process no longer holds, for its page.
+// ok is false when there is no such simul.
+func Archived(id string) (Summary, proto.SimulPayload, bool, error) {
+	info, ok, err := db.GetSimul(id)
+	if err != nil || !ok {
+		return Summary{}, proto.SimulPayload{}, false, err
+	}
+	boards, err := db.SimulBoards(id)
+	if err != nil {
+		return Summary{}, proto.SimulPayload{}, false, err
+	}
+
+	host := parseColor(info.HostColor)
+	sum := Summary{
+		ID:        info.ID,
+		Name:      info.Name,
+		Variant:   pools.Map[info.Variant],
+		HostName:  info.HostName,
+		HostColor: host,
+		MaxBoards: info.MaxBoards,
+		Boards:    len(boards),
+		Status:    Status(info.Status),
+		Wins:      info.Wins,
+		Draws:     info.Draws,
+		Losses:    info.Losses,
+	}
+	payload := proto.SimulPayload{
+		ID:     info.ID,
+		Status: info.Status,
+		Wins:   info.Wins,
+		Draws:  info.Draws,
+		Losses: info.Losses,
+		Boards: make([]proto.SimulBoard, 0, len(boards)),
+	}
+	for _, b := range boards {
+		payload.Boards = append(payload.Boards, proto.SimulBoard{
+			Name:   b.Username,
+			RoomID: b.RoomID,
+			Result: hostResult(b.Result, host),
+		})
+	}
+	return sum, payload, true, nil
+}
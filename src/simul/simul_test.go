@@ -0,0 +1,125 @@
+package simul
+
+import (
+	"testing"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/player"
+)
+
+func testSimul(host octad.Color, maxBoards int) *Simul {
+	uid := int64(1)
+	return newSimul("sm1", Config{
+		Name:      "Exhibition",
+		HostColor: host,
+		MaxBoards: maxBoards,
+		Host:      player.Identity{UID: "host", UserID: &uid, Username: "host"},
+	})
+}
+
+func participant(id int64, name string) player.Identity {
+	return player.Identity{UID: name, UserID: &id, Username: name}
+}
+
+// TestHostResult reads a board's stored result from either side of the board.
+func TestHostResult(t *testing.T) {
+	cases := []struct {
+		result string
+		host   octad.Color
+		want   string
+	}{
+		{"", octad.White, ""},
+		{"w", octad.White, "win"},
+		{"b", octad.White, "loss"},
+		{"w", octad.Black, "loss"},
+		{"b", octad.Black, "win"},
+		{"d", octad.Black, "draw"},
+		{"v", octad.White, "void"},
+	}
+	for _, c := range cases {
+		if got := hostResult(c.result, c.host); got != c.want {
+			t.Errorf("hostResult(%q, %s) = %q, want %q", c.result, c.host, got, c.want)
+		}
+	}
+	if got := resultCode(octad.NoOutcome); got != "v" {
+		t.Errorf("resultCode(NoOutcome) = %q, want v", got)
+	}
+}
+
+// TestTally counts the host's score; a void board counts for nobody, and an
+// unfinished one keeps the simul open.
+func TestTally(t *testing.T) {
+	s := testSimul(octad.Black, 5)
+	for i, r := range []string{"b", "b", "w", "d", "v"} {
+		s.boards = append(s.boards, &Board{UserID: int64(i + 2), Result: r})
+	}
+	if w, d, l := s.tallyLocked(); w != 2 || d != 1 || l != 1 {
+		t.Fatalf("tally = +%d =%d -%d, want +2 =1 -1", w, d, l)
+	}
+	if !s.resolvedLocked() {
+		t.Fatal("every board has a result, but the simul is not resolved")
+	}
+	s.boards[0].Result = ""
+	if s.resolvedLocked() {
+		t.Fatal("a board is still on, but the simul is resolved")
+	}
+}
+
+// TestJoin holds entries to the host's board count, refuses the host a board
+// against themselves, and closes once the simul has started.
+func TestJoin(t *testing.T) {
+	s := testSimul(octad.White, 2)
+	if err := s.Join(participant(1, "host")); err != ErrHost {
+		t.Fatalf("host join = %v, want ErrHost", err)
+	}
+	if err := s.Join(participant(2, "a")); err != nil {
+		t.Fatalf("join a: %v", err)
+	}
+	if err := s.Join(participant(2, "a")); err != nil {
+		t.Fatalf("joining twice should be a no-op, got %v", err)
+	}
+	if err := s.Join(participant(3, "b")); err != nil {
+		t.Fatalf("join b: %v", err)
+	}
+	if err := s.Join(participant(4, "c")); err != ErrFull {
+		t.Fatalf("join past the count = %v, want ErrFull", err)
+	}
+	if err := s.Leave(2); err != nil || s.Entered(2) {
+		t.Fatalf("leave a: err %v, still entered %v", err, s.Entered(2))
+	}
+	if err := s.Join(participant(4, "c")); err != nil {
+		t.Fatalf("join c into the freed board: %v", err)
+	}
+
+	s.status = Started
+	if err := s.Join(participant(5, "d")); err != ErrStarted {
+		t.Fatalf("join after the start = %v, want ErrStarted", err)
+	}
+	if err := s.Cancel(1); err != ErrStarted {
+		t.Fatalf("cancel after the start = %v, want ErrStarted", err)
+	}
+	if err := s.Cancel(3); err != ErrNotHost {
+		t.Fatalf("cancel by a participant = %v, want ErrNotHost", err)
+	}
+}
+
+// TestPayload reports the host's side of every board.
+func TestPayload(t *testing.T) {
+	s := testSimul(octad.White, 3)
+	s.boards = []*Board{
+		{UserID: 2, Username: "a", RoomID: "r1", Result: "w"},
+		{UserID: 3, Username: "b", RoomID: "r2", Result: "b"},
+		{UserID: 4, Username: "c"},
+	}
+	p := s.payloadLocked()
+	if p.Wins != 1 || p.Losses != 1 || p.Draws != 0 || len(p.Boards) != 3 {
+		t.Fatalf("payload = %+v", p)
+	}
+	if p.Boards[0].Result != "win" || p.Boards[1].Result != "loss" || p.Boards[2].Result != "" {
+		t.Fatalf("board results = %q %q %q", p.Boards[0].Result, p.Boards[1].Result, p.Boards[2].Result)
+	}
+	if p.Next != "" {
+		t.Fatalf("no board is live, but next = %q", p.Next)
+	}
+}
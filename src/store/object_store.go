@@ -6,6 +6,8 @@ import (
 	"errors"
 	"io"
 	"log"
+	"net/url"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -25,6 +27,12 @@ type Bucket string
 // PGNBucket is the name of the game PGN storage bucket
 var PGNBucket Bucket
 
+// DumpBucket is the name of the monthly PGN database dump bucket, kept beside
+// PGNBucket rather than inside it so the --backfill replay (which parses every
+// key in PGNBucket as one game) never trips over a month of them. Empty when
+// unconfigured: the dump job then stays off and /db lists nothing.
+var DumpBucket Bucket
+
 // C is the object storage client instance
 var C *minio.Client
 
@@ -35,6 +43,7 @@ func Up() {
 	objectStoreEndpoint = config.ReadSecretFallback("lio_obj_endpoint")
 
 	PGNBucket = Bucket(config.ReadSecretFallback("lio_obj_bucket_pgn"))
+	DumpBucket = Bucket(config.ReadSecretFallback("lio_obj_bucket_db"))
 
 	// a local dev boot without an object store configured is fine: warn and
 	// skip. Game archival (the only consumer) degrades to a logged error per
@@ -138,3 +147,33 @@ func (b Bucket) PutObject(key string, value []byte) error {
 
 	return note("put", key, err, &putOK, &putFail)
 }
+
+// PutStream inserts an object of the given size read from r, so a large object
+// can be uploaded from disk without first being held in memory.
+func (b Bucket) PutStream(key string, r io.Reader, size int64) error {
+	if C == nil {
+		return errors.New("store: no object store configured")
+	}
+
+	_, err := C.PutObject(context.Background(), string(b), key, r, size, minio.PutObjectOptions{})
+	return note("put", key, err, &putOK, &putFail)
+}
+
+// PresignGet returns a time-limited public URL for downloading an object, so a
+// large file is served by the object store itself instead of being buffered
+// through the app. filename, when set, becomes the download's suggested name.
+func (b Bucket) PresignGet(key, filename string, ttl time.Duration) (string, error) {
+	if C == nil {
+		return "", errors.New("store: no object store configured")
+	}
+
+	params := url.Values{}
+	if filename != "" {
+		params.Set("response-content-disposition", `attachment; filename="`+filename+`"`)
+	}
+	u, err := C.PresignedGetObject(context.Background(), string(b), key, ttl, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
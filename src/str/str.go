@@ -5,10 +5,42 @@ const (
 	FHealth      = "health"
 	FHealthUsage = "Server does not run. Instead, a health" +
 		" check runs for any local servers"
-	FBackfill        = "backfill"
-	FBackfillUsage   = "Replay the object-store PGN archive into Postgres, then exit"
-	FDebugFlags      = "debug"
-	FDebugFlagsUsage = "Comma separated debug flags [foo,bar,baz]"
+	FBackfill              = "backfill"
+	FBackfillUsage         = "Replay the object-store PGN archive into Postgres, then exit"
+	FGenTablebase          = "gen-tablebase"
+	FGenTablebaseUsage     = "Solve the endgame tablebase into TABLEBASE_DIR, then exit"
+	FTablebasePieces       = "tablebase-pieces"
+	FTablebasePiecesUsage  = "Largest piece count (kings included) --gen-tablebase solves"
+	FSelfPlay              = "selfplay"
+	FSelfPlayUsage         = "Play the bot personas against each other, rate them and report, then exit"
+	FSelfPlayControl       = "selfplay-control"
+	FSelfPlayControlUsage  = "Time control --selfplay games are played at, by variant name"
+	FSelfPlayVariants      = "selfplay-variants"
+	FSelfPlayVariantsUsage = "Persona variants --selfplay measures [knight:BlunderRate=0.08;rook:MaxDepth=5]"
+	FSelfPlaySweep         = "selfplay-sweep"
+	FSelfPlaySweepUsage    = "Measure a variant each side of every rung's main dial in --selfplay"
+	FSelfPlayRounds        = "selfplay-rounds"
+	FSelfPlayRoundsUsage   = "Times --selfplay plays every pairing"
+	FSelfPlayParallel      = "selfplay-parallel"
+	FSelfPlayParallelUsage = "Games --selfplay plays at once"
+	FSelfPlayGap           = "selfplay-gap"
+	FSelfPlayGapUsage      = "Rating gap between adjacent rungs --selfplay fits settings for"
+	FSelfPlayOut           = "selfplay-out"
+	FSelfPlayOutUsage      = "Directory --selfplay writes its PGNs and report to"
+	FTune                  = "tune"
+	FTuneUsage             = "Fit the evaluation weights to the archived games, write them and a report, then exit"
+	FTunePositions         = "tune-positions"
+	FTunePositionsUsage    = "Most quiet archive positions --tune fits to"
+	FTuneIterations        = "tune-iterations"
+	FTuneIterationsUsage   = "Passes over the positions --tune makes"
+	FTuneControl           = "tune-control"
+	FTuneControlUsage      = "Time control the --tune before/after self-play is played at, by variant name"
+	FTuneRounds            = "tune-rounds"
+	FTuneRoundsUsage       = "Times the --tune before/after self-play plays every pairing (0 skips it)"
+	FTuneOut               = "tune-out"
+	FTuneOutUsage          = "Directory --tune writes its weights file and report to"
+	FDebugFlags            = "debug"
+	FDebugFlagsUsage       = "Comma separated debug flags [foo,bar,baz]"
 
 	InfoFormat  = "INFO  [%s] %s\n"
 	DebugFormat = "DEBUG [%s] %s\n"
@@ -38,6 +70,21 @@ const (
 	CCache = "Cach"
 	CDB    = "DB"
 	CNotif = "Notf"
+	CDump  = "Dump"
+	CTB    = "TBas"
+	CTour  = "Tour"
+	CMatch = "Mtch"
+	CChat  = "Chat"
+	CPuzl  = "Puzl"
+	CCorr  = "Corr"
+	CNode  = "Node"
+	CMail  = "Mail"
+	CPriv  = "Priv"
+	CAnly  = "Anly"
+	CFair  = "Fair"
+	CSiml  = "Siml"
+	CSelf  = "Self"
+	CTune  = "Tune"
 )
 
 // (E) Error messages
@@ -67,8 +114,8 @@ const (
 	MStarted  = "started in %s [env: %s][http: %s][health: %s]"
 	MShutdown = "shutting down"
 	MExit     = "exit"
-	MWSConn   = "[%s @ %s] ws: new conn from %s (spectator=%t)"
-	MWSDisc   = "[%s @ %s] ws: disconnected %s"
+	MWSConn   = "ws: new conn on %s from %s (spectator=%t)"
+	MWSDisc   = "ws: disconnected from %s (%s)"
 )
 
 // (D) Debug log messages
@@ -15,12 +15,16 @@ package sysinfo
 import (
 	"runtime"
 	"runtime/debug"
+	"strings"
 	"time"
 
 	"github.com/dechristopher/lio/channel"
 	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/dispatch"
 	"github.com/dechristopher/lio/env"
+	"github.com/dechristopher/lio/home"
 	"github.com/dechristopher/lio/lag"
+	"github.com/dechristopher/lio/notify"
 	"github.com/dechristopher/lio/room"
 )
 
@@ -69,14 +73,23 @@ type Runtime struct {
 
 	// Workload: what this instance is actually carrying.
 	Rooms int
+	// RoomStates breaks Rooms down by room state name (room.CountByState).
+	RoomStates map[string]int
 	// Sockets is the number of connected websockets summed across channels, and
 	// Channels how many channels hold at least one. Sockets exceeds the number
 	// of people — one player with two tabs is two sockets.
 	Sockets  int
 	Channels int
+	// Connections counts live websocket connections by channel type: "room",
+	// "wait", "tournament", "simul", and the two site-wide channels "home" and
+	// "me". Unlike Sockets it counts every tab.
+	Connections map[string]int
 	// MoveLag is the EWMA (~1min) of server move-processing time, the same
 	// figure that drives clock lag compensation.
 	MoveLag time.Duration
+	// Engine is the engine worker pool: how busy it is and how long bot
+	// searches are waiting for a worker.
+	Engine dispatch.Stats
 }
 
 // Sample reads the process. runtime.ReadMemStats briefly stops the world, which
@@ -87,7 +100,7 @@ func Sample() Runtime {
 	var mem runtime.MemStats
 	runtime.ReadMemStats(&mem)
 
-	sockets, channels := socketCounts()
+	sockets, channels, connections := socketCounts()
 
 	r := Runtime{
 		Version:    config.VersionString(),
@@ -114,10 +127,13 @@ func Sample() Runtime {
 		GCCPUFrac:  mem.GCCPUFraction,
 		ForcedGC:   mem.NumForcedGC,
 
-		Rooms:    room.Count(),
-		Sockets:  sockets,
-		Channels: channels,
-		MoveLag:  lag.Move.Get(),
+		Rooms:       room.Count(),
+		RoomStates:  room.CountByState(),
+		Sockets:     sockets,
+		Channels:    channels,
+		Connections: connections,
+		MoveLag:     lag.Move.Get(),
+		Engine:      dispatch.GetStats(),
 	}
 
 	// PauseNs is a 256-entry ring indexed by (NumGC+255)%256; reading it before
@@ -144,8 +160,9 @@ func memLimit() int64 {
 // sync.Map of *channel.SockMap, each independently locked, so this is safe to
 // walk from any goroutine — it is a sample, not a consistent snapshot, which is
 // the right trade for a counter nobody acts on transactionally.
-func socketCounts() (sockets, channels int) {
-	channel.Map.Range(func(_, raw any) bool {
+func socketCounts() (sockets, channels int, connections map[string]int) {
+	connections = make(map[string]int)
+	channel.Map.Range(func(key, raw any) bool {
 		sockMap, ok := raw.(*channel.SockMap)
 		if !ok {
 			return true
@@ -154,7 +171,24 @@ func socketCounts() (sockets, channels int) {
 			sockets += n
 			channels++
 		}
+		if n := sockMap.Connections(); n > 0 {
+			name, _ := key.(string)
+			connections[channelType(name)] += n
+		}
 		return true
 	})
-	return sockets, channels
+	return sockets, channels, connections
+}
+
+// channelType classifies a channel name the way ws.ConnHandler built it: a
+// typed channel is "<type>/<id>", the home and notification channels are
+// bare names, and anything else is a room.
+func channelType(name string) string {
+	if typ, _, ok := strings.Cut(name, "/"); ok {
+		return typ
+	}
+	if home.IsHome(name) || notify.IsNotify(name) {
+		return name
+	}
+	return "room"
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/dechristopher/lio/home"
 	"github.com/dechristopher/lio/store"
 	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/tablebase"
 	"github.com/dechristopher/lio/util"
 )
 
@@ -36,6 +37,7 @@ var Initializers = []func(){
 	store.Up,
 	db.Up, // durable relational game archive; runs migrations, sibling of store.Up
 	dispatch.UpEngine,
+	tablebase.Up, // before any search can run; falls back to built-in tables
 	engine.MonitorSub,
 	clock.MonitorSub,
 	game.MonitorSub,
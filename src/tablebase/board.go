@@ -0,0 +1,362 @@
+package tablebase
+
+import "github.com/dechristopher/octad/v2"
+
+// A small, allocation-light octad rules core for the generator. Retrograde
+// analysis visits every placement of a material signature, and octad's own
+// Position is built for playing games (string OFENs, copied bitboards, cached
+// move lists) — far too heavy to construct millions of times. This file
+// re-implements exactly the rules a tablebase position can exercise: no
+// castling (tablebase positions never carry castling rights) and en passant
+// only immediately after a double push. board_test.go holds it to octad's
+// move generator move for move.
+
+// numSquares is the number of squares on the octad board.
+const numSquares = 16
+
+// board is a position as the generator sees it: a mailbox of pieces indexed
+// like octad.Square (rank*4 + file), the side to move, and the en passant
+// target left by a double push (octad.NoSquare otherwise).
+type board struct {
+	sq  [numSquares]octad.Piece
+	stm octad.Color
+	ep  octad.Square
+}
+
+// tbMove is one legal move. ep marks an en passant capture (the captured pawn
+// is not on to); double marks a pawn's two-square push, which leaves an en
+// passant target behind.
+type tbMove struct {
+	from, to octad.Square
+	promo    octad.PieceType
+	ep       bool
+	double   bool
+}
+
+// Precomputed targets per square, built once in init.
+var (
+	knightTargets [numSquares][]octad.Square
+	kingTargets   [numSquares][]octad.Square
+	// rays holds each sliding direction's squares in order of distance;
+	// orthogonal directions come first (see orthoRays).
+	rays [numSquares][][]octad.Square
+	// pawnAttackers[c][sq] lists the squares a pawn of color c attacks sq from.
+	pawnAttackers [3][numSquares][]octad.Square
+)
+
+// orthoRays is the number of leading entries of rays[sq] that run along a
+// rank or file; the rest are diagonals.
+const orthoRays = 4
+
+var promoTypes = []octad.PieceType{octad.Queen, octad.Rook, octad.Bishop, octad.Knight}
+
+func init() {
+	ortho := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	diag := [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	knight := [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	king := append(append([][2]int{}, ortho...), diag...)
+
+	for s := 0; s < numSquares; s++ {
+		f, r := s%4, s/4
+		for _, d := range knight {
+			if on(f+d[0], r+d[1]) {
+				knightTargets[s] = append(knightTargets[s], at(f+d[0], r+d[1]))
+			}
+		}
+		for _, d := range king {
+			if on(f+d[0], r+d[1]) {
+				kingTargets[s] = append(kingTargets[s], at(f+d[0], r+d[1]))
+			}
+		}
+		for _, d := range append(append([][2]int{}, ortho...), diag...) {
+			var ray []octad.Square
+			for nf, nr := f+d[0], r+d[1]; on(nf, nr); nf, nr = nf+d[0], nr+d[1] {
+				ray = append(ray, at(nf, nr))
+			}
+			rays[s] = append(rays[s], ray)
+		}
+		// a white pawn attacks diagonally up the board, so it attacks sq from
+		// one rank below; a black pawn from one rank above
+		for _, df := range []int{-1, 1} {
+			if on(f+df, r-1) {
+				pawnAttackers[octad.White][s] = append(pawnAttackers[octad.White][s], at(f+df, r-1))
+			}
+			if on(f+df, r+1) {
+				pawnAttackers[octad.Black][s] = append(pawnAttackers[octad.Black][s], at(f+df, r+1))
+			}
+		}
+	}
+}
+
+func on(f, r int) bool { return f >= 0 && f < 4 && r >= 0 && r < 4 }
+
+func at(f, r int) octad.Square { return octad.Square(r*4 + f) }
+
+// pieceOf returns the piece of type t and color c.
+func pieceOf(t octad.PieceType, c octad.Color) octad.Piece {
+	for _, p := range []octad.Piece{
+		octad.WhiteKing, octad.WhiteQueen, octad.WhiteRook, octad.WhiteBishop, octad.WhiteKnight, octad.WhitePawn,
+		octad.BlackKing, octad.BlackQueen, octad.BlackRook, octad.BlackBishop, octad.BlackKnight, octad.BlackPawn,
+	} {
+		if p.Type() == t && p.Color() == c {
+			return p
+		}
+	}
+	return octad.NoPiece
+}
+
+// pawnDir is the square offset of a single pawn step for color c.
+func pawnDir(c octad.Color) octad.Square {
+	if c == octad.White {
+		return 4
+	}
+	return -4
+}
+
+// homeRank and lastRank are the ranks a color's pawns double-push from and
+// promote on.
+func homeRank(c octad.Color) int {
+	if c == octad.White {
+		return 0
+	}
+	return 3
+}
+
+func lastRank(c octad.Color) int {
+	return 3 - homeRank(c)
+}
+
+// kingSquare returns c's king square, or NoSquare.
+func (b *board) kingSquare(c octad.Color) octad.Square {
+	k := pieceOf(octad.King, c)
+	for s, p := range b.sq {
+		if p == k {
+			return octad.Square(s)
+		}
+	}
+	return octad.NoSquare
+}
+
+// attacked reports whether sq is attacked by a piece of color by.
+func (b *board) attacked(sq octad.Square, by octad.Color) bool {
+	for _, t := range knightTargets[sq] {
+		if p := b.sq[t]; p.Color() == by && p.Type() == octad.Knight {
+			return true
+		}
+	}
+	for _, t := range kingTargets[sq] {
+		if p := b.sq[t]; p.Color() == by && p.Type() == octad.King {
+			return true
+		}
+	}
+	for _, t := range pawnAttackers[by][sq] {
+		if p := b.sq[t]; p.Color() == by && p.Type() == octad.Pawn {
+			return true
+		}
+	}
+	for i, ray := range rays[sq] {
+		for _, t := range ray {
+			p := b.sq[t]
+			if p == octad.NoPiece {
+				continue
+			}
+			if p.Color() == by {
+				switch p.Type() {
+				case octad.Queen:
+					return true
+				case octad.Rook:
+					if i < orthoRays {
+						return true
+					}
+				case octad.Bishop:
+					if i >= orthoRays {
+						return true
+					}
+				}
+			}
+			break
+		}
+	}
+	return false
+}
+
+// inCheck reports whether color c's king is attacked.
+func (b *board) inCheck(c octad.Color) bool {
+	k := b.kingSquare(c)
+	return k != octad.NoSquare && b.attacked(k, c.Other())
+}
+
+// apply returns the board after m (assumed pseudo-legal for b).
+func (b *board) apply(m tbMove) board {
+	nb := *b
+	p := nb.sq[m.from]
+	nb.sq[m.from] = octad.NoPiece
+	if m.promo != octad.NoPieceType {
+		p = pieceOf(m.promo, b.stm)
+	}
+	nb.sq[m.to] = p
+	if m.ep {
+		nb.sq[m.to-pawnDir(b.stm)] = octad.NoPiece
+	}
+	nb.ep = octad.NoSquare
+	if m.double {
+		nb.ep = m.from + pawnDir(b.stm)
+	}
+	nb.stm = b.stm.Other()
+	return nb
+}
+
+// isCapture reports whether m removes an enemy piece.
+func (b *board) isCapture(m tbMove) bool {
+	return m.ep || b.sq[m.to] != octad.NoPiece
+}
+
+// legalMoves appends every legal move for the side to move to out.
+func (b *board) legalMoves(out []tbMove) []tbMove {
+	us := b.stm
+	add := func(m tbMove) {
+		nb := b.apply(m)
+		if !nb.inCheck(us) {
+			out = append(out, m)
+		}
+	}
+	target := func(t octad.Square) bool {
+		p := b.sq[t]
+		return p == octad.NoPiece || p.Color() != us
+	}
+
+	for s, p := range b.sq {
+		if p == octad.NoPiece || p.Color() != us {
+			continue
+		}
+		from := octad.Square(s)
+		switch p.Type() {
+		case octad.Knight:
+			for _, t := range knightTargets[from] {
+				if target(t) {
+					add(tbMove{from: from, to: t})
+				}
+			}
+		case octad.King:
+			for _, t := range kingTargets[from] {
+				if target(t) {
+					add(tbMove{from: from, to: t})
+				}
+			}
+		case octad.Queen, octad.Rook, octad.Bishop:
+			for i, ray := range rays[from] {
+				if (p.Type() == octad.Rook && i >= orthoRays) || (p.Type() == octad.Bishop && i < orthoRays) {
+					continue
+				}
+				for _, t := range ray {
+					if target(t) {
+						add(tbMove{from: from, to: t})
+					}
+					if b.sq[t] != octad.NoPiece {
+						break
+					}
+				}
+			}
+		case octad.Pawn:
+			b.pawnMoves(from, add)
+		}
+	}
+	return out
+}
+
+// pawnMoves feeds each pseudo-legal move of the pawn on from to add.
+func (b *board) pawnMoves(from octad.Square, add func(tbMove)) {
+	us := b.stm
+	f, r := int(from)%4, int(from)/4
+	dr := 1
+	if us == octad.Black {
+		dr = -1
+	}
+	if !on(f, r+dr) {
+		return
+	}
+	push := func(to octad.Square, ep, double bool) {
+		if to/4 == octad.Square(lastRank(us)) {
+			for _, pt := range promoTypes {
+				add(tbMove{from: from, to: to, promo: pt, ep: ep})
+			}
+			return
+		}
+		add(tbMove{from: from, to: to, ep: ep, double: double})
+	}
+
+	one := at(f, r+dr)
+	if b.sq[one] == octad.NoPiece {
+		push(one, false, false)
+		if r == homeRank(us) {
+			if two := at(f, r+2*dr); b.sq[two] == octad.NoPiece {
+				push(two, false, true)
+			}
+		}
+	}
+	for _, df := range []int{-1, 1} {
+		if !on(f+df, r+dr) {
+			continue
+		}
+		t := at(f+df, r+dr)
+		if p := b.sq[t]; p != octad.NoPiece && p.Color() != us {
+			push(t, false, false)
+		} else if t == b.ep && p == octad.NoPiece {
+			push(t, true, false)
+		}
+	}
+}
+
+// sufficientMaterial mirrors octad's automatic insufficient-material draw
+// (Board.hasSufficientMaterial) exactly, including its quirks: any queen, rook
+// or pawn suffices; otherwise bare kings, a lone minor, or bishops all on one
+// square color are a draw.
+func (b *board) sufficientMaterial() bool {
+	bishops, knights := 0, 0
+	var bishopColors [3]int
+	for s, p := range b.sq {
+		switch p.Type() {
+		case octad.Queen, octad.Rook, octad.Pawn:
+			return true
+		case octad.Bishop:
+			bishops++
+			bishopColors[octad.Square(s).Color()]++
+		case octad.Knight:
+			knights++
+		}
+	}
+	if bishops+knights == 0 || (bishops+knights == 1) {
+		return false
+	}
+	if knights == 0 && (bishopColors[octad.White] == 0 || bishopColors[octad.Black] == 0) {
+		return false
+	}
+	return true
+}
+
+// boardFromPosition converts an octad position into a board.
+func boardFromPosition(pos *octad.Position) board {
+	b := board{stm: pos.Turn(), ep: pos.EnPassantSquare()}
+	ob := pos.Board()
+	for s := octad.Square(0); s < numSquares; s++ {
+		b.sq[s] = ob.Piece(s)
+	}
+	return b
+}
+
+// mirror returns the color-swapped, rank-flipped twin of b: an equivalent
+// position with the roles of white and black exchanged. octad's rules are
+// symmetric under this (pawn directions, double pushes and promotion ranks all
+// flip with the board), so a signature and its mirror share one table.
+func (b *board) mirror() board {
+	m := board{stm: b.stm.Other(), ep: octad.NoSquare}
+	for s, p := range b.sq {
+		if p != octad.NoPiece {
+			m.sq[s^12] = pieceOf(p.Type(), p.Color().Other())
+		}
+	}
+	if b.ep != octad.NoSquare {
+		m.ep = b.ep ^ 12
+	}
+	return m
+}
@@ -0,0 +1,208 @@
+package tablebase
+
+import (
+	"fmt"
+
+	"github.com/dechristopher/octad/v2"
+)
+
+// Retrograde solving. A table is solved in one go, after every table its
+// captures and promotions convert into (see signatures), in three steps:
+//
+//  1. Build the move graph once: every reachable index becomes a node with its
+//     legal moves as edges. Moves that stay in the table point at the child's
+//     node; captures and promotions leave it, so their child's value is looked
+//     up in an already-solved table and stored in the edge itself (negated, to
+//     tell it from a node). Terminal nodes — mate, stalemate, octad's
+//     automatic insufficient-material draw — are scored here and get no edges.
+//
+//  2. Resolve values in passes. Pass k settles every node whose distance to
+//     mate is exactly k plies: a node wins in k if some child is lost in k-1
+//     (and no child is lost sooner, or the node would have been settled in an
+//     earlier pass), and loses in k if every child is won and the slowest of
+//     those wins takes k-1. A pass only trusts values settled before it (dtm
+//     < k), so every node gets its shortest win and its longest loss.
+//
+//  3. Whatever is left once a pass settles nothing — and no external child
+//     with a longer mate is still to come — can never be forced either way,
+//     and is a draw.
+//
+// A double push that the opponent can answer en passant reaches a position
+// the index cannot spell (indexes carry no en passant square). Each such
+// child becomes an extra, unindexed node of its own with the en passant
+// capture among its edges; the table only stores the indexed nodes.
+
+// maxDTM is the longest distance to mate a table byte can hold.
+const maxDTM = 253
+
+// graph is a table's move graph during solving. Nodes below the table size
+// are indexes; the rest are the en passant nodes. edges[start[v]:end[v]] are
+// v's children: a node number, or the negated value byte of an external child.
+type graph struct {
+	value      []byte
+	open       []bool
+	start, end []int32
+	edges      []int32
+}
+
+// solve returns the values of every index of sig. lookup returns the value
+// byte of a position with other material, from an already-solved table.
+func solve(sig signature, lookup func(*board) (byte, error)) ([]byte, error) {
+	slots := sig.slots()
+	size := sig.size()
+	g := &graph{
+		value: make([]byte, size),
+		open:  make([]bool, size),
+		start: make([]int32, size),
+		end:   make([]int32, size),
+	}
+
+	var (
+		moves  []tbMove
+		queue  []board
+		maxExt int
+	)
+	// expand scores terminal node v or records its edges
+	expand := func(v int, b *board) error {
+		moves = b.legalMoves(moves[:0])
+		switch {
+		case len(moves) == 0 && b.inCheck(b.stm):
+			g.value[v] = 2 // mated: lost in 0
+			return nil
+		case len(moves) == 0, !b.sufficientMaterial():
+			g.value[v] = 1
+			return nil
+		}
+		g.open[v] = true
+		g.start[v] = int32(len(g.edges))
+		for _, m := range moves {
+			c := b.apply(m)
+			switch {
+			case b.isCapture(m) || m.promo != octad.NoPieceType:
+				val, err := lookup(&c)
+				if err != nil {
+					return err
+				}
+				if d := int(val) - 2; d > maxExt {
+					maxExt = d
+				}
+				g.edges = append(g.edges, -int32(val))
+			case m.double && c.epCapturable():
+				g.edges = append(g.edges, int32(len(g.value)))
+				g.value = append(g.value, 0)
+				g.open = append(g.open, false)
+				g.start = append(g.start, 0)
+				g.end = append(g.end, 0)
+				queue = append(queue, c)
+			default:
+				c.ep = octad.NoSquare
+				g.edges = append(g.edges, int32(index(slots, &c)))
+			}
+		}
+		g.end[v] = int32(len(g.edges))
+		return nil
+	}
+
+	for idx := 0; idx < size; idx++ {
+		b, ok := decode(slots, idx)
+		if !ok || !b.valid() {
+			continue
+		}
+		if err := expand(idx, &b); err != nil {
+			return nil, err
+		}
+		// en passant nodes can spawn more of their own (a double push answered
+		// by a double push), so drain until none are left
+		for len(queue) > 0 {
+			v := len(g.value) - len(queue)
+			c := queue[0]
+			queue = queue[1:]
+			if err := expand(v, &c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for k := 1; ; k++ {
+		settled := false
+		for v := range g.value {
+			if !g.open[v] {
+				continue
+			}
+			fastestLoss, slowestWin, allWon := -1, -1, true
+			for _, e := range g.edges[g.start[v]:g.end[v]] {
+				var val byte
+				if e < 0 {
+					val = byte(-e)
+				} else {
+					val = g.value[e]
+				}
+				d := int(val) - 2
+				if val < 2 || d >= k {
+					// a draw, or not known yet
+					allWon = false
+					continue
+				}
+				if d%2 == 0 {
+					if fastestLoss < 0 || d < fastestLoss {
+						fastestLoss = d
+					}
+				} else if d > slowestWin {
+					slowestWin = d
+				}
+			}
+			dtm := -1
+			switch {
+			case fastestLoss >= 0:
+				dtm = fastestLoss + 1
+			case allWon:
+				dtm = slowestWin + 1
+			}
+			if dtm < 0 {
+				continue
+			}
+			if dtm > maxDTM {
+				return nil, fmt.Errorf("%s: distance to mate %d overflows the table format", sig, dtm)
+			}
+			g.value[v] = byte(dtm + 2)
+			g.open[v] = false
+			settled = true
+		}
+		if !settled && k > maxExt {
+			break
+		}
+	}
+
+	for v := range g.open {
+		if g.open[v] {
+			g.value[v] = 1
+		}
+	}
+	return g.value[:size], nil
+}
+
+// valid reports whether a decoded placement can occur in a game: no pawn on
+// its promotion rank, and the side that just moved not left in check.
+func (b *board) valid() bool {
+	for s, p := range b.sq {
+		if p.Type() == octad.Pawn && s/4 == lastRank(p.Color()) {
+			return false
+		}
+	}
+	return !b.inCheck(b.stm.Other())
+}
+
+// epCapturable reports whether the side to move has a legal en passant
+// capture.
+func (b *board) epCapturable() bool {
+	if b.ep == octad.NoSquare {
+		return false
+	}
+	var moves [32]tbMove
+	for _, m := range b.legalMoves(moves[:0]) {
+		if m.ep {
+			return true
+		}
+	}
+	return false
+}
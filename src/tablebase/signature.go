@@ -0,0 +1,248 @@
+package tablebase
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dechristopher/octad/v2"
+)
+
+// A signature is a material balance — which pieces each side has — and names
+// one table, e.g. "KQvK" or "KRvKP". Within a side, pieces are ordered king
+// first and then from most to least valuable (octad's PieceType order), so
+// every material balance has exactly one spelling.
+//
+// A table holds one byte per index, and an index is the side to move followed
+// by one square per piece, in signature order, base 16. Positions the index
+// can spell but the game cannot reach (two pieces on a square, a pawn on its
+// last rank or on a rank it can never occupy, the side not to move in check)
+// hold 0.
+//
+// Only canonical signatures get a table. A signature and its color mirror
+// ("KvKQ" for "KQvK") are the same endgame with the roles swapped, so the
+// mirror is probed by mirroring the position (board.mirror) instead.
+
+// signature is a parsed signature: each side's piece types in canonical order,
+// kings first.
+type signature struct {
+	white, black []octad.PieceType
+}
+
+// pieceLetters spells piece types in signatures.
+var pieceLetters = map[octad.PieceType]byte{
+	octad.King: 'K', octad.Queen: 'Q', octad.Rook: 'R',
+	octad.Bishop: 'B', octad.Knight: 'N', octad.Pawn: 'P',
+}
+
+// String spells s like "KQvK".
+func (s signature) String() string {
+	var sb strings.Builder
+	for _, t := range s.white {
+		sb.WriteByte(pieceLetters[t])
+	}
+	sb.WriteByte('v')
+	for _, t := range s.black {
+		sb.WriteByte(pieceLetters[t])
+	}
+	return sb.String()
+}
+
+// parseSignature parses a signature spelled like "KQvK".
+func parseSignature(str string) (signature, error) {
+	w, b, ok := strings.Cut(str, "v")
+	if !ok {
+		return signature{}, fmt.Errorf("signature %q: missing 'v'", str)
+	}
+	side := func(letters string) ([]octad.PieceType, error) {
+		var types []octad.PieceType
+		for i := 0; i < len(letters); i++ {
+			t := octad.NoPieceType
+			for pt, l := range pieceLetters {
+				if l == letters[i] {
+					t = pt
+				}
+			}
+			if t == octad.NoPieceType {
+				return nil, fmt.Errorf("signature %q: unknown piece %q", str, letters[i])
+			}
+			types = append(types, t)
+		}
+		if len(types) == 0 || types[0] != octad.King || !slices.IsSorted(types) ||
+			slices.Contains(types[1:], octad.King) {
+			return nil, fmt.Errorf("signature %q: not canonical", str)
+		}
+		return types, nil
+	}
+	white, err := side(w)
+	if err != nil {
+		return signature{}, err
+	}
+	black, err := side(b)
+	if err != nil {
+		return signature{}, err
+	}
+	return signature{white: white, black: black}, nil
+}
+
+// signatureOf returns the signature of the material on b.
+func signatureOf(b *board) signature {
+	var s signature
+	for _, p := range b.sq {
+		switch p.Color() {
+		case octad.White:
+			s.white = append(s.white, p.Type())
+		case octad.Black:
+			s.black = append(s.black, p.Type())
+		}
+	}
+	slices.Sort(s.white)
+	slices.Sort(s.black)
+	return s
+}
+
+// pieces is the total piece count, kings included.
+func (s signature) pieces() int {
+	return len(s.white) + len(s.black)
+}
+
+// pawns is the number of pawns on both sides.
+func (s signature) pawns() int {
+	n := 0
+	for _, t := range append(slices.Clone(s.white), s.black...) {
+		if t == octad.Pawn {
+			n++
+		}
+	}
+	return n
+}
+
+// mirror swaps the sides.
+func (s signature) mirror() signature {
+	return signature{white: s.black, black: s.white}
+}
+
+// canonical reports whether s is the spelling that gets a table: white holds
+// the stronger side — more pieces, or as many with the first difference in
+// white's favor. Symmetric signatures (KQvKQ) are their own mirror.
+func (s signature) canonical() bool {
+	if len(s.white) != len(s.black) {
+		return len(s.white) > len(s.black)
+	}
+	for i := range s.white {
+		if s.white[i] != s.black[i] {
+			return s.white[i] < s.black[i]
+		}
+	}
+	return true
+}
+
+// size is the number of indexes (and table bytes) for s.
+func (s signature) size() int {
+	return 2 << (4 * s.pieces())
+}
+
+// slots returns the pieces of s in index order.
+func (s signature) slots() []octad.Piece {
+	out := make([]octad.Piece, 0, s.pieces())
+	for _, t := range s.white {
+		out = append(out, pieceOf(t, octad.White))
+	}
+	for _, t := range s.black {
+		out = append(out, pieceOf(t, octad.Black))
+	}
+	return out
+}
+
+// index returns b's index in s's table, given slots = s.slots(). b must hold
+// exactly s's material. Identical pieces take their slots in square order, so
+// each placement has a single index (the other orders stay unreachable zeroes).
+func index(slots []octad.Piece, b *board) int {
+	var used uint16
+	idx := 0
+	if b.stm == octad.Black {
+		idx = 1
+	}
+	for _, p := range slots {
+		for sq := 0; sq < numSquares; sq++ {
+			if b.sq[sq] == p && used&(1<<sq) == 0 {
+				used |= 1 << sq
+				idx = idx<<4 | sq
+				break
+			}
+		}
+	}
+	return idx
+}
+
+// decode is the inverse of index. It reports false for indexes that spell no
+// placement: two pieces on one square, or identical pieces out of square
+// order.
+func decode(slots []octad.Piece, idx int) (board, bool) {
+	b := board{ep: octad.NoSquare}
+	next := numSquares
+	for i := len(slots) - 1; i >= 0; i-- {
+		sq := idx & 0xF
+		idx >>= 4
+		if b.sq[sq] != octad.NoPiece {
+			return b, false
+		}
+		// identical neighbors must appear in ascending square order
+		if i+1 < len(slots) && slots[i+1] == slots[i] && sq > next {
+			return b, false
+		}
+		b.sq[sq] = slots[i]
+		next = sq
+	}
+	b.stm = octad.White
+	if idx == 1 {
+		b.stm = octad.Black
+	}
+	return b, true
+}
+
+// signatures returns every canonical signature with at most maxPieces pieces
+// (kings included), in generation order: fewer pieces first, then fewer
+// pawns, so every table a capture or promotion converts into is solved before
+// the tables that convert into it.
+func signatures(maxPieces int) []signature {
+	others := []octad.PieceType{octad.Queen, octad.Rook, octad.Bishop, octad.Knight, octad.Pawn}
+	// sides returns every non-decreasing run of n non-king pieces
+	var sides func(n int, from int) [][]octad.PieceType
+	sides = func(n int, from int) [][]octad.PieceType {
+		if n == 0 {
+			return [][]octad.PieceType{nil}
+		}
+		var out [][]octad.PieceType
+		for i := from; i < len(others); i++ {
+			for _, rest := range sides(n-1, i) {
+				out = append(out, append([]octad.PieceType{others[i]}, rest...))
+			}
+		}
+		return out
+	}
+
+	var out []signature
+	for total := 2; total <= maxPieces; total++ {
+		for w := 0; w <= total-2; w++ {
+			for _, ws := range sides(w, 0) {
+				for _, bs := range sides(total-2-w, 0) {
+					s := signature{
+						white: append([]octad.PieceType{octad.King}, ws...),
+						black: append([]octad.PieceType{octad.King}, bs...),
+					}
+					if s.canonical() {
+						out = append(out, s)
+					}
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(out, func(a, b signature) int {
+		if a.pieces() != b.pieces() {
+			return a.pieces() - b.pieces()
+		}
+		return a.pawns() - b.pawns()
+	})
+	return out
+}
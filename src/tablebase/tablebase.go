@@ -0,0 +1,414 @@
+// Package tablebase solves octad endgames outright. For every material
+// balance up to a few pieces it holds the exact game-theoretic result of every
+// position — win, draw or loss for the side to move — and, for decisive
+// positions, the distance to mate in plies under best play (the winner mating
+// as fast as possible, the loser holding out as long as possible).
+//
+// On a 4x4 board a four-piece endgame is only 131,072 indexes per table, so
+// the whole set through four pieces is solved in seconds and fits in a few
+// megabytes: `lio --gen-tablebase` writes it to config.TablebaseDir (the
+// container image bakes it in at build time) and Up loads it at boot. The
+// three-piece tables are small enough to solve in memory on first use, so
+// every instance and every test has at least those even without files on disk.
+//
+// The engine probes at every search node (engine/tablebase.go), the
+// background evaluator stores exact results for archived positions, and the
+// analysis API reports mate-in-N and known draws from it.
+package tablebase
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// DefaultPieces is the largest piece count (kings included) --gen-tablebase
+// solves by default.
+const DefaultPieces = 4
+
+// builtinPieces is the largest piece count solved in memory when no set has
+// been loaded from disk.
+const builtinPieces = 3
+
+// WDL is a position's result for the side to move.
+type WDL int8
+
+const (
+	Loss WDL = iota - 1
+	Draw
+	Win
+)
+
+// Result is a tablebase hit: the result for the side to move and, if it is
+// decisive, the distance to mate in plies (0 when the side to move is already
+// mated).
+type Result struct {
+	WDL WDL
+	DTM int
+}
+
+// MateIn returns the number of moves to mate, counted the usual way (the
+// mating move's, with the loser's replies in between): positive when r is a
+// win, negative when it is a loss, 0 for a draw or a side already mated.
+func (r Result) MateIn() int {
+	return int(r.WDL) * ((r.DTM + 1) / 2)
+}
+
+// White returns r from white's point of view, given the side to move: the
+// WDL flips when black is to move, the distance to mate stays. MateIn on the
+// result is then white-positive, the convention of every stored eval.
+func (r Result) White(turn octad.Color) Result {
+	if turn == octad.Black {
+		r.WDL = -r.WDL
+	}
+	return r
+}
+
+// fileMagic opens every table file, followed by a format version.
+const fileMagic = "OTB\x01"
+
+// fileExt is the table file extension: "KQvK.otb".
+const fileExt = ".otb"
+
+// table is one solved signature.
+type table struct {
+	sig   signature
+	slots []octad.Piece
+	data  []byte
+}
+
+// Set is a collection of solved tables.
+type Set struct {
+	tables    map[string]*table
+	maxPieces int
+}
+
+// MaxPieces is the largest piece count the set covers completely.
+func (s *Set) MaxPieces() int {
+	return s.maxPieces
+}
+
+// value returns b's value byte from the set, mirroring b if its signature is
+// not the canonical spelling. The second result is false when the set has no
+// table for b's material.
+func (s *Set) value(b *board) (byte, bool) {
+	sig := signatureOf(b)
+	if !sig.canonical() {
+		m := b.mirror()
+		b, sig = &m, sig.mirror()
+	}
+	t, ok := s.tables[sig.String()]
+	if !ok {
+		return 0, false
+	}
+	return t.data[index(t.slots, b)], true
+}
+
+// add records a solved table.
+func (s *Set) add(sig signature, data []byte) {
+	s.tables[sig.String()] = &table{sig: sig, slots: sig.slots(), data: data}
+}
+
+// solveSet solves every canonical signature through maxPieces, calling each
+// (if non-nil) as every table is finished.
+func solveSet(maxPieces int, each func(sig signature, data []byte) error) (*Set, error) {
+	set := &Set{tables: map[string]*table{}, maxPieces: maxPieces}
+	lookup := func(b *board) (byte, error) {
+		v, ok := set.value(b)
+		if !ok || v == 0 {
+			return 0, fmt.Errorf("no solved value for %s", signatureOf(b))
+		}
+		return v, nil
+	}
+	for _, sig := range signatures(maxPieces) {
+		data, err := solve(sig, lookup)
+		if err != nil {
+			return nil, err
+		}
+		set.add(sig, data)
+		if each != nil {
+			if err := each(sig, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return set, nil
+}
+
+// Generate solves every table through maxPieces pieces and writes them to dir,
+// one file per signature.
+func Generate(dir string, maxPieces int) error {
+	if maxPieces < 2 {
+		return fmt.Errorf("tablebase needs at least 2 pieces, got %d", maxPieces)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	_, err := solveSet(maxPieces, func(sig signature, data []byte) error {
+		wins, draws, losses := 0, 0, 0
+		for _, v := range data {
+			switch {
+			case v == 1:
+				draws++
+			case v >= 2 && (v-2)%2 == 1:
+				wins++
+			case v >= 2:
+				losses++
+			}
+		}
+		util.Info(str.CTB, "solved %s wins=%d draws=%d losses=%d", sig, wins, draws, losses)
+		return writeTable(filepath.Join(dir, sig.String()+fileExt), sig, data)
+	})
+	return err
+}
+
+// writeTable writes one table file: gzip of the magic, the signature (length
+// prefixed) and the value bytes.
+func writeTable(path string, sig signature, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	name := sig.String()
+	_, err = io.WriteString(zw, fileMagic)
+	if err == nil {
+		err = binary.Write(zw, binary.LittleEndian, uint8(len(name)))
+	}
+	if err == nil {
+		_, err = io.WriteString(zw, name)
+	}
+	if err == nil {
+		_, err = zw.Write(data)
+	}
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// readTable reads one table file written by writeTable.
+func readTable(path string) (signature, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return signature{}, nil, err
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return signature{}, nil, err
+	}
+	r := bufio.NewReader(zr)
+
+	magic := make([]byte, len(fileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != fileMagic {
+		return signature{}, nil, fmt.Errorf("%s: not a tablebase file", path)
+	}
+	n, err := r.ReadByte()
+	if err != nil {
+		return signature{}, nil, err
+	}
+	name := make([]byte, n)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return signature{}, nil, err
+	}
+	sig, err := parseSignature(string(name))
+	if err != nil {
+		return signature{}, nil, err
+	}
+	data := make([]byte, sig.size())
+	if _, err := io.ReadFull(r, data); err != nil {
+		return signature{}, nil, fmt.Errorf("%s: truncated table: %w", path, err)
+	}
+	return sig, data, nil
+}
+
+// Load reads every table file in dir. The set covers the largest piece count
+// for which every canonical signature is present.
+func Load(dir string) (*Set, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+fileExt))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no tablebase files in %s", dir)
+	}
+	set := &Set{tables: map[string]*table{}}
+	for _, path := range paths {
+		sig, data, err := readTable(path)
+		if err != nil {
+			return nil, err
+		}
+		set.add(sig, data)
+	}
+	for n := 2; ; n++ {
+		complete := true
+		for _, sig := range signatures(n) {
+			if _, ok := set.tables[sig.String()]; !ok {
+				complete = false
+				break
+			}
+		}
+		if !complete {
+			break
+		}
+		set.maxPieces = n
+	}
+	if set.maxPieces < 2 {
+		return nil, errors.New("tablebase set is missing KvK")
+	}
+	return set, nil
+}
+
+var (
+	// loaded is the set Up read from disk, if any
+	loaded atomic.Pointer[Set]
+
+	builtinOnce sync.Once
+	builtin     *Set
+)
+
+// Up loads the tablebase from config.TablebaseDir. A missing or broken set is
+// not fatal: the engine falls back to the built-in three-piece tables.
+func Up() {
+	dir := config.TablebaseDir()
+	set, err := Load(dir)
+	if err != nil {
+		util.Info(str.CTB, "no tablebase loaded, using built-in %d-piece tables: %s",
+			builtinPieces, err.Error())
+		return
+	}
+	loaded.Store(set)
+	util.Info(str.CTB, "tablebase online: %d tables through %d pieces from %s",
+		len(set.tables), set.maxPieces, dir)
+}
+
+// active returns the set probes consult: the loaded one, or the built-in
+// tables solved on first use.
+func active() *Set {
+	if set := loaded.Load(); set != nil {
+		return set
+	}
+	builtinOnce.Do(func() {
+		set, err := solveSet(builtinPieces, nil)
+		if err != nil {
+			// the solver is deterministic; failing here is a bug, not an
+			// environment problem
+			panic(err)
+		}
+		builtin = set
+	})
+	return builtin
+}
+
+// MaxPieces is the largest piece count Probe can answer.
+func MaxPieces() int {
+	return active().maxPieces
+}
+
+// Probe returns pos's exact result, if the tablebase has it. It misses for
+// positions with too many pieces, with castling rights (the tables assume
+// none), and with an en passant capture actually available (ditto). It also
+// misses for a decisive result that octad's 25-move rule would turn into a
+// draw before the mate lands — the tables know nothing of the clock.
+//
+// The clock is read off pos's OFEN, which is fine for a page or a game record.
+// The search keeps the clock itself and calls Lookup and Lands instead.
+func Probe(pos *octad.Position) (Result, bool) {
+	r, ok := Lookup(pos)
+	if !ok || !r.Lands(HalfMoveClock(pos)) {
+		return Result{}, false
+	}
+	return r, true
+}
+
+// Lookup is Probe without the 25-move rule: pos's result as the tables have
+// it, whatever the halfmove clock. See Lands for the rule.
+func Lookup(pos *octad.Position) (Result, bool) {
+	if strings.ContainsAny(string(pos.CastleRights()), "NCFncf") {
+		return Result{}, false
+	}
+	set := active()
+	b := boardFromPosition(pos)
+	pieces := 0
+	for _, p := range b.sq {
+		if p != octad.NoPiece {
+			pieces++
+		}
+	}
+	if pieces > set.maxPieces {
+		return Result{}, false
+	}
+	if b.epCapturable() {
+		return Result{}, false
+	}
+	b.ep = octad.NoSquare
+
+	v, ok := set.value(&b)
+	if !ok || v == 0 {
+		return Result{}, false
+	}
+	return resultOf(v), true
+}
+
+// Lands reports whether r is still the result with halfMoves on the clock. A
+// draw always is. A win or loss only is when its mate lands before the 25-move
+// rule draws the game.
+func (r Result) Lands(halfMoves int) bool {
+	return r.WDL == Draw || halfMoves+r.DTM <= 50
+}
+
+// ProbeOFEN is Probe for an OFEN string.
+func ProbeOFEN(ofen string) (Result, bool) {
+	o, err := octad.OFEN(ofen)
+	if err != nil {
+		return Result{}, false
+	}
+	g, err := octad.NewGame(o)
+	if err != nil {
+		return Result{}, false
+	}
+	return Probe(g.Position())
+}
+
+// resultOf decodes a (non-zero) value byte.
+func resultOf(v byte) Result {
+	if v == 1 {
+		return Result{WDL: Draw}
+	}
+	dtm := int(v) - 2
+	if dtm%2 == 1 {
+		return Result{WDL: Win, DTM: dtm}
+	}
+	return Result{WDL: Loss, DTM: dtm}
+}
+
+// HalfMoveClock reads pos's halfmove clock, which octad only exposes through
+// the OFEN string. It formats the whole position, so a caller that visits many
+// positions (the search) reads it once and keeps it up to date itself.
+func HalfMoveClock(pos *octad.Position) int {
+	fields := strings.Fields(pos.String())
+	if len(fields) < 5 {
+		return 0
+	}
+	n, _ := strconv.Atoi(fields[4])
+	return n
+}
@@ -0,0 +1,364 @@
+package tablebase
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dechristopher/octad/v2"
+)
+
+// ofenOf spells b as an OFEN with no castling rights.
+func ofenOf(b *board) string {
+	var sb strings.Builder
+	for r := 3; r >= 0; r-- {
+		empty := 0
+		for f := 0; f < 4; f++ {
+			p := b.sq[at(f, r)]
+			if p == octad.NoPiece {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			l := pieceLetters[p.Type()]
+			if p.Color() == octad.Black {
+				l += 'a' - 'A'
+			}
+			sb.WriteByte(l)
+		}
+		if empty > 0 {
+			sb.WriteByte(byte('0' + empty))
+		}
+		if r > 0 {
+			sb.WriteByte('/')
+		}
+	}
+	ep := "-"
+	if b.ep != octad.NoSquare {
+		ep = b.ep.String()
+	}
+	return fmt.Sprintf("%s %s - %s 0 1", sb.String(), b.stm.String(), ep)
+}
+
+func gameFromOFEN(t testing.TB, ofen string) *octad.Game {
+	t.Helper()
+	o, err := octad.OFEN(ofen)
+	if err != nil {
+		t.Fatalf("bad OFEN %s: %v", ofen, err)
+	}
+	g, err := octad.NewGame(o)
+	if err != nil {
+		t.Fatalf("bad game from %s: %v", ofen, err)
+	}
+	return g
+}
+
+// randomBoard places both kings and up to extra other pieces at random,
+// retrying until the placement is one a game can reach.
+func randomBoard(rng *rand.Rand, extra int) board {
+	pieces := []octad.PieceType{octad.Queen, octad.Rook, octad.Bishop, octad.Knight, octad.Pawn}
+	for {
+		b := board{stm: octad.White, ep: octad.NoSquare}
+		if rng.Intn(2) == 1 {
+			b.stm = octad.Black
+		}
+		place := func(p octad.Piece) {
+			for {
+				if s := rng.Intn(numSquares); b.sq[s] == octad.NoPiece {
+					b.sq[s] = p
+					return
+				}
+			}
+		}
+		place(octad.WhiteKing)
+		place(octad.BlackKing)
+		for i := rng.Intn(extra + 1); i > 0; i-- {
+			c := octad.White
+			if rng.Intn(2) == 1 {
+				c = octad.Black
+			}
+			place(pieceOf(pieces[rng.Intn(len(pieces))], c))
+		}
+		if b.valid() {
+			return b
+		}
+	}
+}
+
+type moveKey struct {
+	s1, s2 octad.Square
+	promo  octad.PieceType
+}
+
+// TestMoveGenMatchesOctad holds the generator's rules core to octad's own:
+// the same legal moves, the same checks and the same insufficient-material
+// draws, over random placements and short random games from them (which is
+// where double pushes, en passant and promotions turn up).
+func TestMoveGenMatchesOctad(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	epSeen, promoSeen := 0, 0
+	for i := 0; i < 3000; i++ {
+		b := randomBoard(rng, 4)
+		g := gameFromOFEN(t, ofenOf(&b))
+		for ply := 0; ply < 8 && g.Outcome() == octad.NoOutcome; ply++ {
+			pos := g.Position()
+			tb := boardFromPosition(pos)
+
+			var want []moveKey
+			for _, m := range g.ValidMoves() {
+				want = append(want, moveKey{m.S1(), m.S2(), m.Promo()})
+			}
+			var got []moveKey
+			for _, m := range tb.legalMoves(nil) {
+				got = append(got, moveKey{m.from, m.to, m.promo})
+				if m.ep {
+					epSeen++
+				}
+				if m.promo != octad.NoPieceType {
+					promoSeen++
+				}
+			}
+			cmp := func(a, b moveKey) int {
+				return int(a.s1)<<8 | int(a.s2)<<4 | int(a.promo) - (int(b.s1)<<8 | int(b.s2)<<4 | int(b.promo))
+			}
+			slices.SortFunc(want, cmp)
+			slices.SortFunc(got, cmp)
+			if !slices.Equal(got, want) {
+				t.Fatalf("%s: moves\n got %v\nwant %v", pos, got, want)
+			}
+			if tb.inCheck(tb.stm) != pos.InCheck() {
+				t.Fatalf("%s: inCheck = %v, octad says %v", pos, tb.inCheck(tb.stm), pos.InCheck())
+			}
+
+			moves := g.ValidMoves()
+			if len(moves) == 0 {
+				break
+			}
+			if err := g.Move(moves[rng.Intn(len(moves))]); err != nil {
+				t.Fatal(err)
+			}
+			// mate and stalemate are decided before material
+			if g.Method() == octad.Checkmate || g.Method() == octad.Stalemate {
+				break
+			}
+			after := boardFromPosition(g.Position())
+			if insufficient := g.Method() == octad.InsufficientMaterial; insufficient == after.sufficientMaterial() {
+				t.Fatalf("%s: sufficientMaterial = %v, octad method %s",
+					g.Position(), after.sufficientMaterial(), g.Method())
+			}
+		}
+	}
+	if epSeen == 0 || promoSeen == 0 {
+		t.Errorf("random games never exercised en passant (%d) or promotion (%d)", epSeen, promoSeen)
+	}
+}
+
+// TestIndexRoundTrip checks index and decode are inverses over every
+// placement of a signature with identical pieces.
+func TestIndexRoundTrip(t *testing.T) {
+	sig, err := parseSignature("KPPvK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slots := sig.slots()
+	seen := 0
+	for idx := 0; idx < sig.size(); idx++ {
+		b, ok := decode(slots, idx)
+		if !ok {
+			continue
+		}
+		seen++
+		if got := index(slots, &b); got != idx {
+			t.Fatalf("index(decode(%d)) = %d", idx, got)
+		}
+	}
+	// 16*15*(14*13/2) placements, both sides to move
+	if want := 2 * 16 * 15 * 91; seen != want {
+		t.Errorf("decoded %d placements, want %d", seen, want)
+	}
+}
+
+// TestSignatures checks generation order: every signature's captures and
+// promotions land in a signature solved before it.
+func TestSignatures(t *testing.T) {
+	done := map[string]bool{}
+	for _, sig := range signatures(4) {
+		if !sig.canonical() {
+			t.Errorf("%s is not canonical", sig)
+		}
+		if _, err := parseSignature(sig.String()); err != nil {
+			t.Errorf("%s does not parse: %v", sig, err)
+		}
+		// drop each non-king piece in turn: those tables must already exist
+		for side, pieces := range [][]octad.PieceType{sig.white, sig.black} {
+			for i := 1; i < len(pieces); i++ {
+				rest := slices.Delete(slices.Clone(pieces), i, i+1)
+				child := signature{white: sig.white, black: rest}
+				if side == 0 {
+					child = signature{white: rest, black: sig.black}
+				}
+				if !child.canonical() {
+					child = child.mirror()
+				}
+				if !done[child.String()] {
+					t.Errorf("%s solved before its capture table %s", sig, child)
+				}
+			}
+		}
+		done[sig.String()] = true
+	}
+}
+
+// TestKnownResults probes hand-checked positions against the built-in
+// three-piece tables.
+func TestKnownResults(t *testing.T) {
+	for _, tc := range []struct {
+		ofen string
+		want Result
+	}{
+		// Qc4# (the engine's mate-in-one fixture)
+		{"k3/2Q1/K3/4 w - - 0 10", Result{WDL: Win, DTM: 1}},
+		// the same, mated
+		{"k1Q1/4/K3/4 b - - 0 10", Result{WDL: Loss, DTM: 0}},
+		// bare kings and a lone minor piece are octad's automatic draws
+		{"k3/4/4/3K w - - 0 1", Result{WDL: Draw}},
+		{"k3/4/4/2NK w - - 0 1", Result{WDL: Draw}},
+		// stalemate: the black king has no square and is not in check
+		{"k3/2Q1/1K2/4 b - - 0 1", Result{WDL: Draw}},
+	} {
+		got, ok := ProbeOFEN(tc.ofen)
+		if !ok {
+			t.Errorf("%s: no tablebase hit", tc.ofen)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.ofen, got, tc.want)
+		}
+	}
+
+	// too many pieces, castling rights, or a mate the 25-move rule overtakes
+	for _, ofen := range []string{
+		"ppkn/4/4/NKPP w NCFncf - 0 1",
+		"k3/2Q1/K3/4 w N - 0 10",
+		"k3/2Q1/K3/4 w - - 50 60",
+	} {
+		if r, ok := ProbeOFEN(ofen); ok {
+			t.Errorf("%s: unexpected hit %+v", ofen, r)
+		}
+	}
+}
+
+// TestTablesConsistent re-derives every solved value from its children: a
+// win in d has a child lost in d-1 and none lost sooner, a loss in d has
+// every child won and the slowest in d-1, and a draw has no lost child and
+// some child that is not won. Together with the move generator test this
+// pins the solver to octad's game tree.
+func TestTablesConsistent(t *testing.T) {
+	set, err := solveSet(4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tbl := range set.tables {
+		for idx, v := range tbl.data {
+			if v == 0 {
+				continue
+			}
+			b, _ := decode(tbl.slots, idx)
+			moves := b.legalMoves(nil)
+			if len(moves) == 0 || !b.sufficientMaterial() {
+				continue
+			}
+			fastestLoss, slowestWin, allWon := -1, -1, true
+			for _, m := range moves {
+				c := b.apply(m)
+				r := childResult(t, set, &c)
+				switch r.WDL {
+				case Loss:
+					if fastestLoss < 0 || r.DTM < fastestLoss {
+						fastestLoss = r.DTM
+					}
+				case Win:
+					slowestWin = max(slowestWin, r.DTM)
+				default:
+					allWon = false
+				}
+			}
+			want := Result{WDL: Draw}
+			switch {
+			case fastestLoss >= 0:
+				want = Result{WDL: Win, DTM: fastestLoss + 1}
+			case allWon:
+				want = Result{WDL: Loss, DTM: slowestWin + 1}
+			}
+			if got := resultOf(v); got != want {
+				t.Fatalf("%s %s: got %+v, want %+v", tbl.sig, ofenOf(&b), got, want)
+			}
+		}
+	}
+}
+
+// childResult is a child's value, following en passant positions (which no
+// table indexes) one ply further.
+func childResult(t *testing.T, set *Set, c *board) Result {
+	if !c.epCapturable() {
+		c.ep = octad.NoSquare
+		v, ok := set.value(c)
+		if !ok || v == 0 {
+			t.Fatalf("no value for %s", ofenOf(c))
+		}
+		return resultOf(v)
+	}
+	fastestLoss, slowestWin, allWon := -1, -1, true
+	for _, m := range c.legalMoves(nil) {
+		gc := c.apply(m)
+		r := childResult(t, set, &gc)
+		switch r.WDL {
+		case Loss:
+			if fastestLoss < 0 || r.DTM < fastestLoss {
+				fastestLoss = r.DTM
+			}
+		case Win:
+			slowestWin = max(slowestWin, r.DTM)
+		default:
+			allWon = false
+		}
+	}
+	switch {
+	case fastestLoss >= 0:
+		return Result{WDL: Win, DTM: fastestLoss + 1}
+	case allWon:
+		return Result{WDL: Loss, DTM: slowestWin + 1}
+	}
+	return Result{WDL: Draw}
+}
+
+// TestGenerateLoad round-trips a generated set through the file format.
+func TestGenerateLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := Generate(dir, 3); err != nil {
+		t.Fatal(err)
+	}
+	set, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.MaxPieces() != 3 {
+		t.Errorf("loaded set covers %d pieces, want 3", set.MaxPieces())
+	}
+	want := active()
+	for name, tbl := range want.tables {
+		got, ok := set.tables[name]
+		if !ok {
+			t.Errorf("%s missing from loaded set", name)
+			continue
+		}
+		if !slices.Equal(got.data, tbl.data) {
+			t.Errorf("%s differs after a round trip", name)
+		}
+	}
+}
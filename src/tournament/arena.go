@@ -0,0 +1,136 @@
+package tournament
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/settings"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// stepArenaLocked is one Arena tick: finish at the end of the clock, and pair
+// whoever is waiting every arenaPairEvery until the last call.
+func (t *Tournament) stepArenaLocked(now time.Time) {
+	end := t.startedAt.Add(t.cfg.Duration)
+	if !now.Before(end) {
+		t.finishLocked(now)
+		return
+	}
+	// maintenance mode stops games starting, and an Arena's clock runs on
+	if end.Sub(now) < arenaLastCall || now.Sub(t.lastPair) < arenaPairEvery ||
+		settings.Current().Maintenance {
+		return
+	}
+	t.lastPair = now
+
+	present := t.presentLocked()
+	playing := t.playingLocked()
+	var waiting []*Player
+	for id := range present {
+		if !playing[id] {
+			waiting = append(waiting, t.players[id])
+		}
+	}
+	if len(waiting) < 2 {
+		return
+	}
+
+	active := 0
+	for _, p := range t.players {
+		if !p.Withdrawn {
+			active++
+		}
+	}
+
+	ids := make([]int64, len(waiting))
+	for i, p := range waiting {
+		ids[i] = p.UserID
+	}
+	blocked, err := db.BlockedPairs(ids)
+	if err != nil {
+		// like room.SeatBlocked, an unreadable block pairs nobody; the next
+		// pass tries again
+		util.Error(str.CTour, "[%s] arena block lookup failed: %s", t.ID, err.Error())
+		return
+	}
+
+	last, whites := t.arenaHistoryLocked()
+	for _, pair := range pairArena(waiting, last, active <= 2, blocked) {
+		white, black := pair[0], pair[1]
+		if whites[black.UserID] < whites[white.UserID] ||
+			(whites[black.UserID] == whites[white.UserID] && util.RandomColor() == octad.Black) {
+			white, black = black, white
+		}
+		if err := t.startGameLocked(white, black, present[white.UserID], present[black.UserID]); err != nil {
+			util.Error(str.CTour, "[%s] arena pairing failed: %s", t.ID, err.Error())
+			return
+		}
+	}
+}
+
+// arenaHistoryLocked returns each player's most recent opponent and how many
+// games they have had White.
+func (t *Tournament) arenaHistoryLocked() (last map[int64]int64, whites map[int64]int) {
+	last = make(map[int64]int64)
+	whites = make(map[int64]int)
+	for _, p := range t.pairings {
+		last[p.white] = p.black
+		last[p.black] = p.white
+		whites[p.white]++
+	}
+	return last, whites
+}
+
+// pairArena pairs the waiting players down the standings: the best-placed
+// plays the next best-placed, and so on. Nobody is paired straight back
+// against the opponent they just played while anyone else is in reach — the
+// next two places down are tried first — unless allowRematch says the event
+// has nobody else. Two players with a block between them are never paired at
+// all. A player left over waits for the next pass.
+func pairArena(waiting []*Player, last map[int64]int64, allowRematch bool, blocked map[db.BlockPair]bool) [][2]*Player {
+	ranked := make([]*Player, len(waiting))
+	copy(ranked, waiting)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Rank != ranked[j].Rank {
+			return ranked[i].Rank < ranked[j].Rank
+		}
+		return ranked[i].UserID < ranked[j].UserID
+	})
+
+	rematch := func(a, b *Player) bool {
+		return last[a.UserID] == b.UserID || last[b.UserID] == a.UserID
+	}
+
+	var out [][2]*Player
+	for len(ranked) >= 2 {
+		a := ranked[0]
+		pick, replay := -1, -1
+		for i := 1; i < len(ranked) && i <= 3; i++ {
+			if blocked[db.PairOf(a.UserID, ranked[i].UserID)] {
+				continue
+			}
+			if !rematch(a, ranked[i]) {
+				pick = i
+				break
+			}
+			if replay < 0 {
+				replay = i
+			}
+		}
+		if pick < 0 && allowRematch {
+			pick = replay
+		}
+		if pick < 0 {
+			// a waits a round rather than replay; the rest carry on
+			ranked = ranked[1:]
+			continue
+		}
+		out = append(out, [2]*Player{a, ranked[pick]})
+		ranked = append(ranked[1:pick:pick], ranked[pick+1:]...)
+	}
+	return out
+}
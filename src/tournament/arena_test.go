@@ -0,0 +1,22 @@
+package tournament
+
+import (
+	"testing"
+
+	"github.com/dechristopher/lio/db"
+)
+
+// TestPairArenaBlocked pairs the leader past a player they share a block with,
+// and never pairs a blocked pair, even where a rematch would be allowed.
+func TestPairArenaBlocked(t *testing.T) {
+	waiting := []*Player{{UserID: 1, Rank: 1}, {UserID: 2, Rank: 2}, {UserID: 3, Rank: 3}, {UserID: 4, Rank: 4}}
+	blocked := map[db.BlockPair]bool{db.PairOf(1, 2): true}
+	pairs := pairArena(waiting, nil, false, blocked)
+	if len(pairs) != 2 || pairs[0][1].UserID != 3 {
+		t.Fatalf("pairs = %v, want 1-3 and 2-4", pairs)
+	}
+
+	if pairs := pairArena(waiting[:2], nil, true, blocked); len(pairs) != 0 {
+		t.Fatal("a blocked pair was paired as a forced rematch")
+	}
+}
@@ -0,0 +1,186 @@
+package tournament
+
+import (
+	"sort"
+	"strings"
+)
+
+// Scoring and standings. Both formats keep a per-player score sheet, one
+// character per game, which is what the standings table renders and what the
+// tournament_players row stores. Everything else a standings row carries —
+// wins, draws and losses, the Arena streak — can be re-derived from it, so a
+// restart restores a player from the sheet alone.
+//
+// Arena sheets record the points a game earned: a loss is "0", a draw "1" (or
+// "D", two points, on fire), a win its points as a digit, "2" through "5". A
+// player is on fire after two consecutive wins, and stays on fire until they
+// fail to win: while on fire every result scores double. A berserk win that
+// was actually played out adds a point.
+//
+// Swiss sheets record the classical result: "1", "=" or "0", "+" for a bye
+// (a full point) and "-" for a forfeit (nothing).
+
+const (
+	// sheetLoss .. sheetFireDraw are the Arena sheet's non-win marks; a win is
+	// its point value as a digit.
+	sheetLoss     = '0'
+	sheetDraw     = '1'
+	sheetFireDraw = 'D'
+
+	// sheetWin .. sheetForfeit are the Swiss sheet's marks.
+	sheetWin     = '1'
+	sheetHalf    = '='
+	sheetZero    = '0'
+	sheetBye     = '+'
+	sheetForfeit = '-'
+
+	// berserkMinPlies is how long a berserked game must run for its win to earn
+	// the bonus point: a berserk is a bet against the clock, and a win on an
+	// opponent's early blunder (or a resignation) is not that bet paid off.
+	berserkMinPlies = 12
+)
+
+// arenaWin reports whether an Arena sheet mark is a win.
+func arenaWin(mark byte) bool {
+	return mark >= '2' && mark <= '5'
+}
+
+// arenaStreak is the number of consecutive wins at the end of an Arena sheet.
+func arenaStreak(sheet string) int {
+	n := 0
+	for i := len(sheet) - 1; i >= 0 && arenaWin(sheet[i]); i-- {
+		n++
+	}
+	return n
+}
+
+// onFire reports whether the next Arena game scores double.
+func onFire(sheet string) bool {
+	return arenaStreak(sheet) >= 2
+}
+
+// arenaMark scores one finished Arena game for one player: points is 1 for a
+// win, ½ for a draw and 0 for a loss. berserkBonus says the player berserked
+// and the game ran long enough to earn the extra point for a win.
+func arenaMark(sheet string, points float64, berserkBonus bool) byte {
+	fire := onFire(sheet)
+	switch points {
+	case 1:
+		pts := 2
+		if fire {
+			pts = 4
+		}
+		if berserkBonus {
+			pts++
+		}
+		return byte('0' + pts)
+	case 0.5:
+		if fire {
+			return sheetFireDraw
+		}
+		return sheetDraw
+	}
+	return sheetLoss
+}
+
+// markPoints is the score a sheet mark is worth in its format.
+func markPoints(format Format, mark byte) float64 {
+	if format == Arena {
+		switch {
+		case mark == sheetFireDraw:
+			return 2
+		case mark >= '0' && mark <= '5':
+			return float64(mark - '0')
+		}
+		return 0
+	}
+	switch mark {
+	case sheetWin, sheetBye:
+		return 1
+	case sheetHalf:
+		return 0.5
+	}
+	return 0
+}
+
+// tally re-derives a player's totals from their sheet. A Swiss bye counts
+// towards the score but is not a game won.
+func tally(format Format, sheet string) (score float64, wins, draws, losses int) {
+	for i := 0; i < len(sheet); i++ {
+		mark := sheet[i]
+		score += markPoints(format, mark)
+		if format == Arena {
+			switch {
+			case arenaWin(mark):
+				wins++
+			case mark == sheetDraw || mark == sheetFireDraw:
+				draws++
+			default:
+				losses++
+			}
+			continue
+		}
+		switch mark {
+		case sheetWin:
+			wins++
+		case sheetHalf:
+			draws++
+		case sheetZero, sheetForfeit:
+			losses++
+		}
+	}
+	return score, wins, draws, losses
+}
+
+// game is one played game from a player's side, for the tiebreaks.
+type game struct {
+	Opponent int64
+	// Points is 1, ½ or 0 from this player's side.
+	Points float64
+}
+
+// tiebreak computes a player's second sort key. Swiss uses Buchholz, the sum
+// of their opponents' scores. Arena uses a performance rating: the opponents'
+// average rating, moved 400 points per net win — the number that says how well
+// a player did against whom, when the score already says how much they played.
+func tiebreak(format Format, games []game, score func(int64) float64, rating func(int64) int) float64 {
+	if len(games) == 0 {
+		return 0
+	}
+	if format == Swiss {
+		total := 0.0
+		for _, g := range games {
+			total += score(g.Opponent)
+		}
+		return total
+	}
+	total := 0.0
+	for _, g := range games {
+		total += float64(rating(g.Opponent)) + 800*(g.Points-0.5)
+	}
+	return float64(int(total/float64(len(games)) + 0.5))
+}
+
+// rankPlayers sorts the standings and numbers them from 1. Withdrawn players
+// keep their games but sink below everybody still playing.
+func rankPlayers(players []*Player) {
+	sort.SliceStable(players, func(i, j int) bool {
+		a, b := players[i], players[j]
+		if a.Withdrawn != b.Withdrawn {
+			return !a.Withdrawn
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Tiebreak != b.Tiebreak {
+			return a.Tiebreak > b.Tiebreak
+		}
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		return strings.ToLower(a.Username) < strings.ToLower(b.Username)
+	})
+	for i, p := range players {
+		p.Rank = i + 1
+	}
+}
@@ -0,0 +1,90 @@
+package tournament
+
+import "testing"
+
+// TestArenaMark walks a player onto fire and off it: two plain wins, then
+// double points until a game is not won, and a berserk bonus on top.
+func TestArenaMark(t *testing.T) {
+	cases := []struct {
+		sheet   string
+		points  float64
+		berserk bool
+		want    byte
+	}{
+		{"", 1, false, '2'},
+		{"2", 1, false, '2'},
+		{"22", 1, false, '4'},
+		{"22", 1, true, '5'},
+		{"22", 0.5, false, 'D'},
+		{"22", 0, false, '0'},
+		{"220", 1, false, '2'},
+		{"", 1, true, '3'},
+		{"", 0.5, true, '1'},
+	}
+	for _, c := range cases {
+		if got := arenaMark(c.sheet, c.points, c.berserk); got != c.want {
+			t.Errorf("arenaMark(%q, %v, %v) = %c, want %c", c.sheet, c.points, c.berserk, got, c.want)
+		}
+	}
+}
+
+// TestArenaStreak counts only the wins at the end of the sheet.
+func TestArenaStreak(t *testing.T) {
+	for sheet, want := range map[string]int{"": 0, "0": 0, "2": 1, "1232": 3, "224D": 0, "02345": 4} {
+		if got := arenaStreak(sheet); got != want {
+			t.Errorf("arenaStreak(%q) = %d, want %d", sheet, got, want)
+		}
+	}
+}
+
+// TestTally re-derives totals in both formats; a Swiss bye scores but is not
+// a win.
+func TestTally(t *testing.T) {
+	score, w, d, l := tally(Arena, "2241D0")
+	if score != 11 || w != 3 || d != 2 || l != 1 {
+		t.Fatalf("arena tally = %v %d/%d/%d, want 11 3/2/1", score, w, d, l)
+	}
+	score, w, d, l = tally(Swiss, "1=0+-")
+	if score != 2.5 || w != 1 || d != 1 || l != 2 {
+		t.Fatalf("swiss tally = %v %d/%d/%d, want 2.5 1/1/2", score, w, d, l)
+	}
+}
+
+// TestTiebreak checks Buchholz and the Arena performance rating.
+func TestTiebreak(t *testing.T) {
+	scores := map[int64]float64{2: 3, 3: 1.5}
+	ratings := map[int64]int{2: 1600, 3: 1400}
+	games := []game{{Opponent: 2, Points: 1}, {Opponent: 3, Points: 0}}
+	score := func(id int64) float64 { return scores[id] }
+	rating := func(id int64) int { return ratings[id] }
+
+	if got := tiebreak(Swiss, games, score, rating); got != 4.5 {
+		t.Errorf("buchholz = %v, want 4.5", got)
+	}
+	// (1600+400 + 1400-400) / 2
+	if got := tiebreak(Arena, games, score, rating); got != 1500 {
+		t.Errorf("performance = %v, want 1500", got)
+	}
+	if got := tiebreak(Arena, nil, score, rating); got != 0 {
+		t.Errorf("no games = %v, want 0", got)
+	}
+}
+
+// TestRankPlayers orders by score, tiebreak, rating and name, with withdrawn
+// players last regardless of score.
+func TestRankPlayers(t *testing.T) {
+	ps := []*Player{
+		{Username: "dave", Score: 9, Withdrawn: true},
+		{Username: "carol", Score: 4, Tiebreak: 10, Rating: 1500},
+		{Username: "Bob", Score: 4, Tiebreak: 10, Rating: 1500},
+		{Username: "alice", Score: 4, Tiebreak: 12},
+		{Username: "erin", Score: 6},
+	}
+	rankPlayers(ps)
+	want := []string{"erin", "alice", "Bob", "carol", "dave"}
+	for i, p := range ps {
+		if p.Username != want[i] || p.Rank != i+1 {
+			t.Fatalf("rank %d = %s (#%d), want %s", i+1, p.Username, p.Rank, want[i])
+		}
+	}
+}
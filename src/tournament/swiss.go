@@ -0,0 +1,362 @@
+package tournament
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/settings"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Swiss pairing, Dutch style. The entrants of a round are ranked by score,
+// then rating; each score group is split into a top half (S1) and a bottom
+// half (S2), and S1's first plays S2's first, S1's second S2's second, and so
+// on. Nobody meets the same opponent twice while any other pairing exists, a
+// player left over in a group floats down to the next, and an odd field gives
+// its lowest-ranked player who has not had one a bye. Two players with a block
+// between them are never paired, rematch or not; in the rare field that leaves
+// somebody with no one to play, they sit the round out with a bye.
+//
+// It is not a FIDE-certified implementation — there is no absolute colour
+// criterion, and floaters are not tracked across rounds — but it is the same
+// shape, and it is a pure function of the entrants so it can be tested on its
+// own.
+
+// maxPairingSteps bounds the backtracking search for a rematch-free pairing.
+// A field where none exists (late rounds of a small event) would otherwise
+// explore every arrangement before giving up; past the budget the search is
+// rerun with rematches allowed.
+const maxPairingSteps = 200000
+
+// entrant is a player as the pairing sees them.
+type entrant struct {
+	ID     int64
+	Score  float64
+	Rating int
+	// Opponents is everybody this player has already been paired against.
+	Opponents map[int64]bool
+	// Colors is the player's colour history, oldest first, byes excluded.
+	Colors []octad.Color
+	HadBye bool
+	// Blocked is everybody this player shares a block with, either way.
+	Blocked map[int64]bool
+}
+
+// board is one Swiss pairing: White against Black.
+type board struct {
+	White *entrant
+	Black *entrant
+}
+
+// pairSwiss pairs one round. bye is nil for an even field; sitOut is whoever
+// blocks leave unpaired, normally nobody.
+func pairSwiss(field []*entrant) (boards []board, bye *entrant, sitOut []*entrant) {
+	ranked := make([]*entrant, len(field))
+	copy(ranked, field)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranksAbove(ranked[i], ranked[j])
+	})
+
+	if len(ranked)%2 == 1 {
+		at := len(ranked) - 1
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if !ranked[i].HadBye {
+				at = i
+				break
+			}
+		}
+		bye = ranked[at]
+		ranked = append(ranked[:at:at], ranked[at+1:]...)
+	}
+
+	pairs, ok := searchPairs(ranked, false)
+	if !ok {
+		pairs, ok = searchPairs(ranked, true)
+	}
+	if !ok {
+		// only blocks make a field unpairable once rematches are allowed
+		pairs, sitOut = pairAround(ranked)
+	}
+	for i, p := range pairs {
+		boards = append(boards, allocateColors(p[0], p[1], i))
+	}
+	return boards, bye, sitOut
+}
+
+// pairAround pairs the ranked field greedily, each player taking the first
+// candidate they share no block with, and returns whoever is left.
+func pairAround(ranked []*entrant) (pairs [][2]*entrant, left []*entrant) {
+	paired := make([]bool, len(ranked))
+	for top := range ranked {
+		if paired[top] {
+			continue
+		}
+		paired[top] = true
+		found := false
+		for _, c := range candidates(ranked, paired, top) {
+			if !ranked[top].Blocked[ranked[c].ID] {
+				paired[c] = true
+				pairs = append(pairs, [2]*entrant{ranked[top], ranked[c]})
+				found = true
+				break
+			}
+		}
+		if !found {
+			left = append(left, ranked[top])
+		}
+	}
+	return pairs, left
+}
+
+// ranksAbove is the pairing order: score, then rating, then id so a tie is
+// still deterministic.
+func ranksAbove(a, b *entrant) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if a.Rating != b.Rating {
+		return a.Rating > b.Rating
+	}
+	return a.ID < b.ID
+}
+
+// searchPairs pairs the ranked field top-down, backtracking out of any dead
+// end. The first unpaired player is always the top of what remains, so the
+// candidate order below reproduces the S1/S2 split as the search descends.
+func searchPairs(ranked []*entrant, allowRematch bool) ([][2]*entrant, bool) {
+	paired := make([]bool, len(ranked))
+	out := make([][2]*entrant, 0, len(ranked)/2)
+	steps := 0
+
+	var solve func() bool
+	solve = func() bool {
+		top := -1
+		for i := range ranked {
+			if !paired[i] {
+				top = i
+				break
+			}
+		}
+		if top < 0 {
+			return true
+		}
+		steps++
+		if steps > maxPairingSteps {
+			return false
+		}
+		paired[top] = true
+		for _, c := range candidates(ranked, paired, top) {
+			if ranked[top].Blocked[ranked[c].ID] {
+				continue
+			}
+			if !allowRematch && ranked[top].Opponents[ranked[c].ID] {
+				continue
+			}
+			paired[c] = true
+			out = append(out, [2]*entrant{ranked[top], ranked[c]})
+			if solve() {
+				return true
+			}
+			out = out[:len(out)-1]
+			paired[c] = false
+		}
+		paired[top] = false
+		return false
+	}
+
+	return out, solve()
+}
+
+// candidates orders the opponents for ranked[top], the highest unpaired
+// player: the head of their score group's bottom half first, then the rest of
+// that half, then the top half from the bottom up, then everybody below the
+// group in rank order (a downfloat).
+func candidates(ranked []*entrant, paired []bool, top int) []int {
+	var group, below []int
+	for i := top + 1; i < len(ranked); i++ {
+		if paired[i] {
+			continue
+		}
+		if ranked[i].Score == ranked[top].Score {
+			group = append(group, i)
+		} else {
+			below = append(below, i)
+		}
+	}
+
+	// the group including top has len(group)+1 players; S2 starts at its half
+	half := (len(group)+1)/2 - 1
+	if half < 0 {
+		half = 0
+	}
+	out := make([]int, 0, len(group)+len(below))
+	out = append(out, group[half:]...)
+	for i := half - 1; i >= 0; i-- {
+		out = append(out, group[i])
+	}
+	return append(out, below...)
+}
+
+// allocateColors gives White to whoever is owed it: the player who has had it
+// less often, then the one who had Black last, then — in round one, or between
+// identical histories — alternately the higher- and lower-ranked player down
+// the boards.
+func allocateColors(high, low *entrant, boardIndex int) board {
+	hb, lb := colorBalance(high.Colors), colorBalance(low.Colors)
+	switch {
+	case hb < lb:
+		return board{White: high, Black: low}
+	case lb < hb:
+		return board{White: low, Black: high}
+	}
+
+	hl, ll := lastColor(high.Colors), lastColor(low.Colors)
+	switch {
+	case hl == ll && hl != octad.NoColor:
+		// both played the same colour last: the higher-ranked player alternates
+		if hl == octad.White {
+			return board{White: low, Black: high}
+		}
+		return board{White: high, Black: low}
+	case hl == octad.Black || ll == octad.White:
+		return board{White: high, Black: low}
+	case hl == octad.White || ll == octad.Black:
+		return board{White: low, Black: high}
+	}
+
+	if boardIndex%2 == 0 {
+		return board{White: high, Black: low}
+	}
+	return board{White: low, Black: high}
+}
+
+// colorBalance is Whites minus Blacks.
+func colorBalance(colors []octad.Color) int {
+	n := 0
+	for _, c := range colors {
+		if c == octad.White {
+			n++
+		} else if c == octad.Black {
+			n--
+		}
+	}
+	return n
+}
+
+// lastColor is the most recent colour played, NoColor before any game.
+func lastColor(colors []octad.Color) octad.Color {
+	if len(colors) == 0 {
+		return octad.NoColor
+	}
+	return colors[len(colors)-1]
+}
+
+// stepSwissLocked is one Swiss tick. Once the round in play has no game left,
+// the event either finishes or, after swissRoundBreak, pairs the next round.
+func (t *Tournament) stepSwissLocked(now time.Time) {
+	if len(t.live) > 0 {
+		return
+	}
+	if t.round >= t.cfg.Rounds {
+		t.finishLocked(now)
+		return
+	}
+	if t.nextRound.IsZero() {
+		t.nextRound = now.Add(swissRoundBreak)
+		t.dirty = true
+		return
+	}
+	// maintenance mode stops games starting: the round waits it out
+	if now.Before(t.nextRound) || settings.Current().Maintenance {
+		return
+	}
+	t.nextRound = time.Time{}
+	t.pairRoundLocked()
+}
+
+// pairRoundLocked pairs and starts the next Swiss round. An entrant who is not
+// on the event page (or is busy elsewhere) forfeits it.
+func (t *Tournament) pairRoundLocked() {
+	present := t.presentLocked()
+	ids := make([]int64, 0, len(present))
+	for id := range present {
+		ids = append(ids, id)
+	}
+	blocked, err := db.BlockedPairs(ids)
+	if err != nil {
+		// like room.SeatBlocked, an unreadable block pairs nobody: the round
+		// waits another break and tries again
+		util.Error(str.CTour, "[%s] swiss block lookup failed: %s", t.ID, err.Error())
+		return
+	}
+	t.round++
+
+	history := make(map[int64]*entrant)
+	var field []*entrant
+	for _, p := range t.players {
+		if p.Withdrawn {
+			continue
+		}
+		if _, ok := present[p.UserID]; !ok {
+			p.Sheet += string(sheetForfeit)
+			continue
+		}
+		e := &entrant{ID: p.UserID, Score: p.Score, Rating: p.Rating,
+			Opponents: make(map[int64]bool), Blocked: make(map[int64]bool)}
+		history[p.UserID] = e
+		field = append(field, e)
+	}
+	for _, p := range t.pairings {
+		if w := history[p.white]; w != nil {
+			if p.black == 0 {
+				w.HadBye = true
+				continue
+			}
+			w.Opponents[p.black] = true
+			w.Colors = append(w.Colors, octad.White)
+		}
+		if b := history[p.black]; b != nil {
+			b.Opponents[p.white] = true
+			b.Colors = append(b.Colors, octad.Black)
+		}
+	}
+
+	for pair := range blocked {
+		if a, b := history[pair[0]], history[pair[1]]; a != nil && b != nil {
+			a.Blocked[b.ID] = true
+			b.Blocked[a.ID] = true
+		}
+	}
+
+	boards, bye, sitOut := pairSwiss(field)
+	if bye != nil {
+		sitOut = append(sitOut, bye)
+	}
+	for _, e := range sitOut {
+		t.players[e.ID].Sheet += string(sheetBye)
+		p := &pairing{round: t.round, white: e.ID, result: "w"}
+		p.id, err = db.InsertTournamentPairing(t.ID, db.TournamentPairing{Round: p.round, White: p.white, Result: p.result})
+		if err != nil {
+			util.Error(str.CDB, "[%s] tournament bye write failed: %s", t.ID, err.Error())
+		}
+		t.pairings = append(t.pairings, p)
+	}
+	for _, b := range boards {
+		white, black := t.players[b.White.ID], t.players[b.Black.ID]
+		if err := t.startGameLocked(white, black, present[white.UserID], present[black.UserID]); err != nil {
+			// the room was refused (a shutdown drain): nobody can play it
+			util.Error(str.CTour, "[%s] swiss pairing failed: %s", t.ID, err.Error())
+			white.Sheet += string(sheetForfeit)
+			black.Sheet += string(sheetForfeit)
+		}
+	}
+
+	t.rankLocked()
+	t.saveAllLocked()
+	t.saveStateLocked()
+	util.Info(str.CTour, "[%s] round %d paired: %d games", t.ID, t.round, len(boards))
+}
@@ -0,0 +1,172 @@
+package tournament
+
+import (
+	"testing"
+
+	"github.com/dechristopher/octad/v2"
+)
+
+func field(ratings ...int) []*entrant {
+	out := make([]*entrant, len(ratings))
+	for i, r := range ratings {
+		out[i] = &entrant{ID: int64(i + 1), Rating: r, Opponents: make(map[int64]bool)}
+	}
+	return out
+}
+
+func met(a, b *entrant) {
+	a.Opponents[b.ID] = true
+	b.Opponents[a.ID] = true
+}
+
+// pairOf normalizes a board to its two ids, lower first.
+func pairOf(b board) [2]int64 {
+	if b.White.ID < b.Black.ID {
+		return [2]int64{b.White.ID, b.Black.ID}
+	}
+	return [2]int64{b.Black.ID, b.White.ID}
+}
+
+// TestPairSwissRoundOne locks the Dutch split: ranked by rating, the top half
+// plays the bottom half in order, and colours alternate down the boards.
+func TestPairSwissRoundOne(t *testing.T) {
+	f := field(2000, 1900, 1800, 1700, 1600, 1500)
+	boards, bye, _ := pairSwiss(f)
+	if bye != nil {
+		t.Fatalf("even field gave a bye to %d", bye.ID)
+	}
+	want := [][2]int64{{1, 4}, {2, 5}, {3, 6}}
+	if len(boards) != len(want) {
+		t.Fatalf("got %d boards, want %d", len(boards), len(want))
+	}
+	for i, b := range boards {
+		if got := pairOf(b); got != want[i] {
+			t.Fatalf("board %d = %v, want %v", i+1, got, want[i])
+		}
+	}
+	if boards[0].White.ID != 1 || boards[1].White.ID != 5 || boards[2].White.ID != 3 {
+		t.Fatalf("colours did not alternate: whites %d %d %d",
+			boards[0].White.ID, boards[1].White.ID, boards[2].White.ID)
+	}
+}
+
+// TestPairSwissAvoidsRematch checks a score group re-pairs around a game its
+// players have already had.
+func TestPairSwissAvoidsRematch(t *testing.T) {
+	f := field(2000, 1900, 1800, 1700)
+	met(f[0], f[2])
+	met(f[1], f[3])
+	boards, _, _ := pairSwiss(f)
+	for _, b := range boards {
+		if b.White.Opponents[b.Black.ID] {
+			t.Fatalf("rematch paired: %v", pairOf(b))
+		}
+	}
+	if got := pairOf(boards[0]); got != [2]int64{1, 4} {
+		t.Fatalf("board 1 = %v, want [1 4]", got)
+	}
+}
+
+// TestPairSwissAllowsRematchWhenForced checks a field with no rematch-free
+// pairing is still paired rather than left idle.
+func TestPairSwissAllowsRematchWhenForced(t *testing.T) {
+	f := field(2000, 1900)
+	met(f[0], f[1])
+	boards, bye, _ := pairSwiss(f)
+	if bye != nil || len(boards) != 1 {
+		t.Fatalf("got %d boards and bye %v, want one board", len(boards), bye)
+	}
+}
+
+// TestPairSwissScoreGroups checks the leaders play each other before anyone
+// floats down.
+func TestPairSwissScoreGroups(t *testing.T) {
+	f := field(1500, 1600, 1700, 1800)
+	f[0].Score, f[1].Score = 1, 1
+	boards, _, _ := pairSwiss(f)
+	if got := pairOf(boards[0]); got != [2]int64{1, 2} {
+		t.Fatalf("top board = %v, want the two leaders [1 2]", got)
+	}
+}
+
+// TestPairSwissBye gives the bye to the lowest-ranked player who has not had
+// one.
+func TestPairSwissBye(t *testing.T) {
+	f := field(2000, 1900, 1800, 1700, 1600)
+	_, bye, _ := pairSwiss(f)
+	if bye == nil || bye.ID != 5 {
+		t.Fatalf("bye = %v, want player 5", bye)
+	}
+
+	f = field(2000, 1900, 1800, 1700, 1600)
+	f[4].HadBye = true
+	boards, bye, _ := pairSwiss(f)
+	if bye == nil || bye.ID != 4 {
+		t.Fatalf("bye = %v, want player 4 once 5 has had one", bye)
+	}
+	if len(boards) != 2 {
+		t.Fatalf("got %d boards, want 2", len(boards))
+	}
+}
+
+// TestAllocateColors covers the three criteria in order: balance, then the
+// last colour, then the board.
+func TestAllocateColors(t *testing.T) {
+	w, b := octad.White, octad.Black
+	high := &entrant{ID: 1, Colors: []octad.Color{w, w, b}}
+	low := &entrant{ID: 2, Colors: []octad.Color{b, b, w}}
+	if got := allocateColors(high, low, 0); got.White != low {
+		t.Fatal("balance: the player owed White did not get it")
+	}
+
+	high = &entrant{ID: 1, Colors: []octad.Color{w, b}}
+	low = &entrant{ID: 2, Colors: []octad.Color{b, w}}
+	if got := allocateColors(high, low, 0); got.White != high {
+		t.Fatal("last colour: the player who had Black last did not get White")
+	}
+
+	high = &entrant{ID: 1, Colors: []octad.Color{b}}
+	low = &entrant{ID: 2, Colors: []octad.Color{b}}
+	if got := allocateColors(high, low, 1); got.White != high {
+		t.Fatal("same last colour: the higher-ranked player did not alternate")
+	}
+
+	high, low = &entrant{ID: 1}, &entrant{ID: 2}
+	if allocateColors(high, low, 0).White != high || allocateColors(high, low, 1).White != low {
+		t.Fatal("no history: colours did not alternate by board")
+	}
+}
+
+func blocks(a, b *entrant) {
+	if a.Blocked == nil {
+		a.Blocked = make(map[int64]bool)
+	}
+	if b.Blocked == nil {
+		b.Blocked = make(map[int64]bool)
+	}
+	a.Blocked[b.ID] = true
+	b.Blocked[a.ID] = true
+}
+
+// TestPairSwissBlocked pairs around a block, and sits out a pair with nobody
+// else to play rather than seat them together, even as a rematch.
+func TestPairSwissBlocked(t *testing.T) {
+	f := field(2000, 1900, 1800, 1700)
+	blocks(f[0], f[2])
+	boards, _, sitOut := pairSwiss(f)
+	if len(sitOut) != 0 || len(boards) != 2 {
+		t.Fatalf("got %d boards and %d sitting out, want 2 and none", len(boards), len(sitOut))
+	}
+	for _, b := range boards {
+		if b.White.Blocked[b.Black.ID] {
+			t.Fatalf("blocked pair paired: %v", pairOf(b))
+		}
+	}
+
+	f = field(2000, 1900)
+	blocks(f[0], f[1])
+	boards, bye, sitOut := pairSwiss(f)
+	if len(boards) != 0 || bye != nil || len(sitOut) != 2 {
+		t.Fatalf("got %d boards, bye %v and %d sitting out, want both sitting out", len(boards), bye, len(sitOut))
+	}
+}
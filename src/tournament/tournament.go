@@ -0,0 +1,983 @@
+// Package tournament runs Arena and Swiss events on top of ordinary rooms.
+//
+// An **Arena** runs for a fixed time. From its start, anybody entered who is
+// sitting on the event page and not already in a game is paired as soon as
+// there is somebody to play, against whoever is nearest them in the standings,
+// and sent straight to the board; a finished game sends them back. Scoring
+// rewards winning streaks and, when the event allows it, berserking (see
+// standings.go).
+//
+// A **Swiss** plays a fixed number of rounds. Each round pairs the whole field
+// at once, Dutch style (see swiss.go), and the next round is paired once every
+// game of the last has ended. A player who is not on the event page when a
+// round is paired forfeits it.
+//
+// Every game is a room.Create'd room with both seats filled and
+// Params.Tournament set. The room never calls back in here (room is imported
+// by this package, not the reverse); it publishes a room.Result on
+// room.ResultChannel when the game ends, and Up subscribes to that.
+//
+// Each event runs on its own goroutine, ticking once a second: that tick starts
+// the event, pairs, finishes it, and broadcasts the standings to
+// /socket/tournament/<id> when they have changed since the last. The event's
+// state is guarded by its own mutex, and written through to Postgres as it
+// changes (db/tournaments.go), which is what Up restores an event from after a
+// restart.
+//
+// Seats are keyed by session uid, like every other room's, but an entry is an
+// account. The two meet on the event page: the session an account has open on
+// /tournament/<id> is the one its next game is seated for.
+package tournament
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/bus"
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/pools"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/settings"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/title"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/variant"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// Format is an event's pairing system.
+type Format string
+
+const (
+	Arena Format = "arena"
+	Swiss Format = "swiss"
+)
+
+// Status is where an event is in its life.
+type Status string
+
+const (
+	Created  Status = "created"
+	Started  Status = "started"
+	Finished Status = "finished"
+)
+
+const (
+	// tick is the event loop's cadence: the resolution of the start and end
+	// times, and the most a standings change waits before it is broadcast.
+	tick = time.Second
+	// arenaPairEvery is how often an Arena pairs its waiting players. Not every
+	// tick: a player back from a game should have a moment to find somebody
+	// near them in the standings rather than whoever came back first.
+	arenaPairEvery = 4 * time.Second
+	// arenaLastCall stops an Arena pairing new games this close to its end. A
+	// game still running at the end does not count, and a pairing nobody can
+	// finish only keeps people from seeing the final standings.
+	arenaLastCall = 30 * time.Second
+	// swissRoundBreak is the pause between a Swiss round's last game ending
+	// and the next round being paired: long enough to look at the standings
+	// and be back on the page.
+	swissRoundBreak = 20 * time.Second
+	// finishedRetention is how long a finished event stays in memory, serving
+	// its page from here rather than from Postgres, for the players still on
+	// it.
+	finishedRetention = time.Hour
+
+	// MaxRounds / MaxDuration bound an event's configuration.
+	MaxRounds   = 15
+	MaxDuration = 4 * time.Hour
+	// MaxNameLength bounds an event's name.
+	MaxNameLength = 60
+)
+
+var (
+	// ErrNotFound is an unknown or no longer running event.
+	ErrNotFound = errors.New("no such tournament")
+	// ErrFinished refuses an entry to an event that is over.
+	ErrFinished = errors.New("that tournament is over")
+	// ErrSwissStarted refuses an entry to a Swiss once it is underway: a late
+	// entrant would sit below everyone on a zero score for the whole event.
+	ErrSwissStarted = errors.New("that tournament has already started")
+	// ErrWithdrawn refuses a Swiss entry that withdrew.
+	ErrWithdrawn = errors.New("you withdrew from this tournament")
+	// ErrBadConfig refuses a creation it cannot run.
+	ErrBadConfig = errors.New("that tournament configuration is not valid")
+)
+
+var resultSub sync.Once
+
+// registry holds every event this process runs, keyed by id.
+var registry sync.Map
+
+// Config is what an event is created with. None of it changes afterwards.
+type Config struct {
+	Name    string
+	Format  Format
+	Variant variant.Variant
+	Rated   bool
+	// Berserk lets Arena players halve their clock for a bonus point.
+	Berserk bool
+	// Rounds is a Swiss event's length, Duration an Arena's.
+	Rounds    int
+	Duration  time.Duration
+	StartsAt  time.Time
+	CreatedBy *int64
+}
+
+// Player is one entrant's standings row. Score, the counts and Rank are
+// derived from Sheet and the event's pairings; only the tournament's goroutine
+// and the mutex-holding methods touch them.
+type Player struct {
+	UserID    int64
+	Username  string
+	Title     title.Title
+	Rating    int
+	Score     float64
+	Tiebreak  float64
+	Wins      int
+	Draws     int
+	Losses    int
+	Sheet     string
+	Rank      int
+	Withdrawn bool
+}
+
+// pairing is one game (or Swiss bye) the event has made.
+type pairing struct {
+	id     int64
+	round  int
+	roomID string
+	white  int64
+	black  int64 // 0 for a bye
+	result string
+}
+
+// Tournament is one running event.
+type Tournament struct {
+	ID  string
+	cfg Config
+
+	mu         sync.Mutex
+	status     Status
+	round      int
+	startedAt  time.Time
+	finishedAt time.Time
+	players    map[int64]*Player
+	pairings   []*pairing
+	// live indexes the unresolved pairings by room id.
+	live map[string]*pairing
+	// nextRound is when the next Swiss round is paired, zero while one is
+	// being played.
+	nextRound time.Time
+	lastPair  time.Time
+	dirty     bool
+}
+
+// Summary is an event as the /tournament list shows it.
+type Summary struct {
+	ID        string
+	Name      string
+	Format    Format
+	Variant   variant.Variant
+	Rated     bool
+	Berserk   bool
+	Rounds    int
+	Duration  time.Duration
+	Status    Status
+	Round     int
+	StartsAt  time.Time
+	CreatedBy *int64
+	Players   int
+}
+
+// Up restores every unfinished event and starts listening for game results.
+// It runs at boot after the rooms are rehydrated, so a restored pairing can
+// find its room; a pairing whose room did not survive the restart is void.
+func Up() {
+	resultSub.Do(func() {
+		if err := room.ResultChannel.Subscribe(onResult); err != nil {
+			panic(err)
+		}
+	})
+
+	infos, err := db.OpenTournaments()
+	if err != nil {
+		util.Error(str.CTour, "tournament restore failed: %s", err.Error())
+		return
+	}
+	for _, info := range infos {
+		t, err := restore(info)
+		if err != nil {
+			util.Error(str.CTour, "[%s] tournament restore failed: %s", info.ID, err.Error())
+			continue
+		}
+		registry.Store(t.ID, t)
+		go t.run()
+		util.Info(str.CTour, "[%s] tournament restored (%s, %d players)", t.ID, t.status, len(t.players))
+	}
+}
+
+// Create validates and starts a new event.
+func Create(cfg Config) (*Tournament, error) {
+	cfg.Name = strings.TrimSpace(cfg.Name)
+	if cfg.Name == "" || len(cfg.Name) > MaxNameLength || cfg.Variant.Casual {
+		return nil, ErrBadConfig
+	}
+	switch cfg.Format {
+	case Arena:
+		if cfg.Duration < time.Minute || cfg.Duration > MaxDuration {
+			return nil, ErrBadConfig
+		}
+		cfg.Rounds = 0
+	case Swiss:
+		if cfg.Rounds < 1 || cfg.Rounds > MaxRounds {
+			return nil, ErrBadConfig
+		}
+		cfg.Duration = 0
+		cfg.Berserk = false
+	default:
+		return nil, ErrBadConfig
+	}
+	if cfg.StartsAt.IsZero() {
+		cfg.StartsAt = time.Now()
+	}
+
+	t := newTournament(config.GenerateCode(8, config.Base58), cfg)
+	if err := db.InsertTournament(t.info()); err != nil {
+		return nil, err
+	}
+	registry.Store(t.ID, t)
+	go t.run()
+
+	util.Info(str.CTour, "[%s] %s tournament %q created", t.ID, cfg.Format, cfg.Name)
+	return t, nil
+}
+
+// Get returns a running (or recently finished) event, nil if there is none.
+func Get(id string) *Tournament {
+	if v, ok := registry.Load(id); ok {
+		return v.(*Tournament)
+	}
+	return nil
+}
+
+// List summarises the events this process holds, soonest first, finished
+// ones last.
+func List() []Summary {
+	var out []Summary
+	registry.Range(func(_, v interface{}) bool {
+		out = append(out, v.(*Tournament).Summary())
+		return true
+	})
+	sort.SliceStable(out, func(i, j int) bool {
+		if (out[i].Status == Finished) != (out[j].Status == Finished) {
+			return out[j].Status == Finished
+		}
+		return out[i].StartsAt.Before(out[j].StartsAt)
+	})
+	return out
+}
+
+// Channel is the socket channel an event's page listens on.
+func Channel(id string) string {
+	return "tournament/" + id
+}
+
+// Connect sends a freshly connected socket the event's current state.
+func Connect(s *channel.Socket, id string) {
+	t := Get(id)
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	payload := t.payloadLocked()
+	t.mu.Unlock()
+	s.Enqueue(payload.Marshal())
+}
+
+func newTournament(id string, cfg Config) *Tournament {
+	return &Tournament{
+		ID:      id,
+		cfg:     cfg,
+		status:  Created,
+		players: make(map[int64]*Player),
+		live:    make(map[string]*pairing),
+		dirty:   true,
+	}
+}
+
+// Config returns the event's configuration.
+func (t *Tournament) Config() Config {
+	return t.cfg
+}
+
+// Summary describes the event for the list.
+func (t *Tournament) Summary() Summary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Summary{
+		ID:        t.ID,
+		Name:      t.cfg.Name,
+		Format:    t.cfg.Format,
+		Variant:   t.cfg.Variant,
+		Rated:     t.cfg.Rated,
+		Berserk:   t.cfg.Berserk,
+		Rounds:    t.cfg.Rounds,
+		Duration:  t.cfg.Duration,
+		Status:    t.status,
+		Round:     t.round,
+		StartsAt:  t.cfg.StartsAt,
+		CreatedBy: t.cfg.CreatedBy,
+		Players:   len(t.players),
+	}
+}
+
+// Payload is the event's live state, as the page is first rendered with.
+func (t *Tournament) Payload() proto.TournamentPayload {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.payloadLocked()
+}
+
+// Entry reports whether an account is entered, and whether it has withdrawn.
+func (t *Tournament) Entry(userID int64) (entered, withdrawn bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.players[userID]
+	if !ok {
+		return false, false
+	}
+	return true, p.Withdrawn
+}
+
+// Join enters an account, or returns a withdrawn Arena player to the event.
+func (t *Tournament) Join(seat player.Identity) error {
+	if seat.UserID == nil {
+		return ErrNotFound
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.status == Finished {
+		return ErrFinished
+	}
+	if p, ok := t.players[*seat.UserID]; ok {
+		if !p.Withdrawn {
+			return nil
+		}
+		if t.cfg.Format == Swiss && t.status != Created {
+			return ErrWithdrawn
+		}
+		p.Withdrawn = false
+		t.saveLocked(p)
+		return nil
+	}
+	if t.cfg.Format == Swiss && t.status != Created {
+		return ErrSwissStarted
+	}
+
+	r := db.RatingOrDefault(*seat.UserID, t.cfg.Variant.HTMLName)
+	p := &Player{
+		UserID:   *seat.UserID,
+		Username: seat.Username,
+		Title:    seat.Title,
+		Rating:   int(r.R + 0.5),
+	}
+	t.players[p.UserID] = p
+	t.rankLocked()
+	t.saveLocked(p)
+	util.Info(str.CTour, "[%s] %s joined", t.ID, p.Username)
+	return nil
+}
+
+// Withdraw takes an account out of the event. Before the start the entry is
+// removed outright; after it the player keeps their games and score, and an
+// Arena player may come back.
+func (t *Tournament) Withdraw(userID int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.players[userID]
+	if !ok || t.status == Finished {
+		return nil
+	}
+	if t.status == Created {
+		delete(t.players, userID)
+		if err := db.DeleteTournamentStanding(t.ID, userID); err != nil {
+			util.Error(str.CDB, "[%s] tournament entry delete failed user=%d: %s", t.ID, userID, err.Error())
+		}
+		t.rankLocked()
+		return nil
+	}
+	p.Withdrawn = true
+	t.rankLocked()
+	t.saveAllLocked()
+	util.Info(str.CTour, "[%s] %s withdrew", t.ID, p.Username)
+	return nil
+}
+
+// run is the event's loop. It exits once the event has finished and its
+// final standings have gone out.
+func (t *Tournament) run() {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for range ticker.C {
+		if t.step(time.Now()) {
+			time.AfterFunc(finishedRetention, func() { registry.Delete(t.ID) })
+			return
+		}
+	}
+}
+
+// step advances the event by one tick, reporting whether it is over.
+func (t *Tournament) step(now time.Time) (over bool) {
+	t.mu.Lock()
+	defer func() {
+		var frame []byte
+		if t.dirty {
+			payload := t.payloadLocked()
+			frame = payload.Marshal()
+			t.dirty = false
+		}
+		over = t.status == Finished
+		t.mu.Unlock()
+		if frame != nil {
+			channel.Broadcast(frame, channel.SocketContext{Channel: Channel(t.ID)})
+		}
+	}()
+
+	if t.status == Finished {
+		return
+	}
+	if t.status == Created {
+		if now.Before(t.cfg.StartsAt) {
+			return
+		}
+		t.startLocked(now)
+	}
+
+	switch t.cfg.Format {
+	case Arena:
+		t.stepArenaLocked(now)
+	case Swiss:
+		t.stepSwissLocked(now)
+	}
+	return
+}
+
+// startLocked moves the event to Started. A Swiss with nobody to pair
+// finishes on the spot.
+func (t *Tournament) startLocked(now time.Time) {
+	t.status = Started
+	t.startedAt = now
+	t.dirty = true
+	if t.cfg.Format == Swiss {
+		if len(t.players) < 2 {
+			t.finishLocked(now)
+			return
+		}
+		t.nextRound = now
+	}
+	t.saveStateLocked()
+	util.Info(str.CTour, "[%s] tournament started with %d players", t.ID, len(t.players))
+}
+
+// finishLocked closes the event. A game still in flight is left to finish on
+// its own, but counts for nothing.
+func (t *Tournament) finishLocked(now time.Time) {
+	t.status = Finished
+	t.finishedAt = now
+	t.dirty = true
+	t.rankLocked()
+	t.saveAllLocked()
+	t.saveStateLocked()
+	util.Info(str.CTour, "[%s] tournament finished", t.ID)
+}
+
+// present maps every account on the event page to the session it is there
+// with, leaving out anyone already committed to a game.
+func (t *Tournament) presentLocked() map[int64]string {
+	out := make(map[int64]string)
+	for _, s := range channel.Map.GetSockMap(Channel(t.ID)).Sockets() {
+		p, ok := t.players[s.Acct.ID]
+		if !ok || p.Withdrawn || s.Acct.ID == 0 {
+			continue
+		}
+		if _, seen := out[s.Acct.ID]; seen {
+			continue
+		}
+		if room.Engaged(s.UID, s.Acct.ID) {
+			continue
+		}
+		out[s.Acct.ID] = s.UID
+	}
+	return out
+}
+
+// playingLocked is the set of accounts in an unresolved pairing.
+func (t *Tournament) playingLocked() map[int64]bool {
+	out := make(map[int64]bool)
+	for _, p := range t.live {
+		out[p.white] = true
+		out[p.black] = true
+	}
+	return out
+}
+
+// startGameLocked creates a pairing's room and sends both players to it.
+func (t *Tournament) startGameLocked(white, black *Player, whiteUID, blackUID string) error {
+	params := room.NewParams(player.Identity{
+		UID:      whiteUID,
+		UserID:   &white.UserID,
+		Username: white.Username,
+		Title:    white.Title,
+	}, t.cfg.Variant)
+	params.Players[octad.White] = &player.Player{
+		ID:       whiteUID,
+		UserID:   &white.UserID,
+		Username: white.Username,
+		Title:    white.Title,
+	}
+	params.Players[octad.Black] = &player.Player{
+		ID:       blackUID,
+		UserID:   &black.UserID,
+		Username: black.Username,
+		Title:    black.Title,
+	}
+	// the site-wide rated switch applies to each game as it is created, like
+	// any other room's
+	params.Rated = t.cfg.Rated && settings.Current().RatedEnabled
+	params.Tournament = t.ID
+	params.TournamentName = t.cfg.Name
+	params.Berserk = t.cfg.Format == Arena && t.cfg.Berserk
+
+	r, err := room.Create(params)
+	if err != nil {
+		return err
+	}
+
+	p := &pairing{round: t.round, roomID: r.ID, white: white.UserID, black: black.UserID}
+	p.id, err = db.InsertTournamentPairing(t.ID, db.TournamentPairing{
+		Round:  p.round,
+		RoomID: p.roomID,
+		White:  p.white,
+		Black:  p.black,
+	})
+	if err != nil {
+		util.Error(str.CDB, "[%s] tournament pairing write failed room=%s: %s", t.ID, r.ID, err.Error())
+	}
+	t.pairings = append(t.pairings, p)
+	t.live[r.ID] = p
+	t.dirty = true
+
+	redirect := proto.RedirectMessage{Location: "/" + r.ID}
+	frame := redirect.Marshal()
+	sockets := channel.Map.GetSockMap(Channel(t.ID))
+	for _, uid := range []string{whiteUID, blackUID} {
+		for _, s := range sockets.SocketsFor(uid) {
+			s.Enqueue(frame)
+		}
+	}
+	util.DebugFlag("tournament", str.CTour, "[%s] paired %s vs %s in %s", t.ID, white.Username, black.Username, r.ID)
+	return nil
+}
+
+// onResult is the room.ResultChannel subscriber.
+func onResult(e bus.Event) {
+	if len(e.Data) == 0 {
+		return
+	}
+	res, ok := e.Data[0].(room.Result)
+	if !ok {
+		return
+	}
+	if t := Get(res.Tournament); t != nil {
+		t.applyResult(res)
+	}
+}
+
+// applyResult scores a finished game. A result for a pairing already resolved
+// (a restored room re-announcing itself) is ignored.
+func (t *Tournament) applyResult(res room.Result) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.live[res.RoomID]
+	if !ok {
+		return
+	}
+	delete(t.live, res.RoomID)
+	p.result = resultCode(res.Outcome)
+	if err := db.SetTournamentPairingResult(p.id, p.result); err != nil {
+		util.Error(str.CDB, "[%s] tournament result write failed room=%s: %s", t.ID, res.RoomID, err.Error())
+	}
+	t.dirty = true
+
+	// an Arena game still running at the end counts for nothing
+	if t.status == Finished {
+		return
+	}
+
+	white, black := t.players[p.white], t.players[p.black]
+	if white == nil || black == nil {
+		return
+	}
+	t.scoreLocked(p, white, black, res)
+	t.rankLocked()
+	t.saveAllLocked()
+}
+
+// scoreLocked writes one game onto both players' sheets.
+func (t *Tournament) scoreLocked(p *pairing, white, black *Player, res room.Result) {
+	if p.result == "v" {
+		// nothing was played. An Arena simply lets both players go again; a
+		// Swiss round is gone, and both forfeit it.
+		if t.cfg.Format == Swiss {
+			white.Sheet += string(sheetForfeit)
+			black.Sheet += string(sheetForfeit)
+		}
+		return
+	}
+
+	wp := 0.5
+	switch p.result {
+	case "w":
+		wp = 1
+	case "b":
+		wp = 0
+	}
+
+	if t.cfg.Format == Arena {
+		played := res.Plies >= berserkMinPlies
+		white.Sheet += string(arenaMark(white.Sheet, wp, res.BerserkWhite && played))
+		black.Sheet += string(arenaMark(black.Sheet, 1-wp, res.BerserkBlack && played))
+		return
+	}
+	white.Sheet += string(swissMark(wp))
+	black.Sheet += string(swissMark(1 - wp))
+}
+
+// swissMark is the Swiss sheet mark for a game's points.
+func swissMark(points float64) byte {
+	switch points {
+	case 1:
+		return sheetWin
+	case 0.5:
+		return sheetHalf
+	}
+	return sheetZero
+}
+
+// resultCode is the tournament_pairings result for an outcome.
+func resultCode(o octad.Outcome) string {
+	switch o {
+	case octad.WhiteWon:
+		return "w"
+	case octad.BlackWon:
+		return "b"
+	case octad.Draw:
+		return "d"
+	}
+	return "v"
+}
+
+// rankLocked re-derives every player's totals and tiebreak, then the ranks.
+func (t *Tournament) rankLocked() {
+	games := make(map[int64][]game)
+	for _, p := range t.pairings {
+		if p.black == 0 || p.result == "" || p.result == "v" {
+			continue
+		}
+		wp := 0.5
+		switch p.result {
+		case "w":
+			wp = 1
+		case "b":
+			wp = 0
+		}
+		games[p.white] = append(games[p.white], game{Opponent: p.black, Points: wp})
+		games[p.black] = append(games[p.black], game{Opponent: p.white, Points: 1 - wp})
+	}
+
+	list := make([]*Player, 0, len(t.players))
+	for _, p := range t.players {
+		p.Score, p.Wins, p.Draws, p.Losses = tally(t.cfg.Format, p.Sheet)
+		list = append(list, p)
+	}
+	score := func(id int64) float64 {
+		if p, ok := t.players[id]; ok {
+			return p.Score
+		}
+		return 0
+	}
+	rating := func(id int64) int {
+		if p, ok := t.players[id]; ok {
+			return p.Rating
+		}
+		return 0
+	}
+	for _, p := range list {
+		p.Tiebreak = tiebreak(t.cfg.Format, games[p.UserID], score, rating)
+	}
+	rankPlayers(list)
+	t.dirty = true
+}
+
+// standingsLocked is the players in rank order.
+func (t *Tournament) standingsLocked() []*Player {
+	list := make([]*Player, 0, len(t.players))
+	for _, p := range t.players {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Rank < list[j].Rank })
+	return list
+}
+
+// payloadLocked builds the live-state frame.
+func (t *Tournament) payloadLocked() proto.TournamentPayload {
+	playing := t.playingLocked()
+	out := proto.TournamentPayload{
+		ID:        t.ID,
+		Status:    string(t.status),
+		Round:     t.round,
+		Rounds:    t.cfg.Rounds,
+		Seconds:   t.secondsLocked(time.Now()),
+		Standings: make([]proto.TournamentRow, 0, len(t.players)),
+	}
+	for _, p := range t.standingsLocked() {
+		out.Standings = append(out.Standings, proto.TournamentRow{
+			Rank:      p.Rank,
+			Name:      p.Username,
+			Title:     p.Title.Code,
+			TitleName: p.Title.Name,
+			Rating:    p.Rating,
+			Score:     p.Score,
+			Tiebreak:  p.Tiebreak,
+			Sheet:     p.Sheet,
+			Fire:      t.cfg.Format == Arena && onFire(p.Sheet),
+			Playing:   playing[p.UserID],
+			Withdrawn: p.Withdrawn,
+		})
+	}
+	for _, p := range t.pairings {
+		if p.result != "" || p.black == 0 {
+			continue
+		}
+		g := proto.TournamentGame{RoomID: p.roomID}
+		if w := t.players[p.white]; w != nil {
+			g.White = w.Username
+		}
+		if b := t.players[p.black]; b != nil {
+			g.Black = b.Username
+		}
+		out.Games = append(out.Games, g)
+	}
+	return out
+}
+
+// secondsLocked is the countdown the page shows: to the start, to an
+// Arena's end, or to the next Swiss round.
+func (t *Tournament) secondsLocked(now time.Time) int {
+	var until time.Time
+	switch {
+	case t.status == Created:
+		until = t.cfg.StartsAt
+	case t.status == Started && t.cfg.Format == Arena:
+		until = t.startedAt.Add(t.cfg.Duration)
+	case t.status == Started && !t.nextRound.IsZero():
+		until = t.nextRound
+	default:
+		return 0
+	}
+	if d := until.Sub(now); d > 0 {
+		return int(d.Round(time.Second) / time.Second)
+	}
+	return 0
+}
+
+// info is the event's tournaments row.
+func (t *Tournament) info() db.TournamentInfo {
+	return db.TournamentInfo{
+		ID:         t.ID,
+		Name:       t.cfg.Name,
+		Format:     string(t.cfg.Format),
+		Variant:    t.cfg.Variant.HTMLName,
+		Rated:      t.cfg.Rated,
+		Berserk:    t.cfg.Berserk,
+		Rounds:     t.cfg.Rounds,
+		Duration:   t.cfg.Duration,
+		Status:     string(t.status),
+		Round:      t.round,
+		CreatedBy:  t.cfg.CreatedBy,
+		StartsAt:   t.cfg.StartsAt,
+		StartedAt:  t.startedAt,
+		FinishedAt: t.finishedAt,
+	}
+}
+
+// saveStateLocked writes the event's progress.
+func (t *Tournament) saveStateLocked() {
+	if err := db.UpdateTournamentState(t.ID, string(t.status), t.round, t.startedAt, t.finishedAt); err != nil {
+		util.Error(str.CDB, "[%s] tournament state write failed: %s", t.ID, err.Error())
+	}
+}
+
+// saveLocked writes one standings row.
+func (t *Tournament) saveLocked(p *Player) {
+	if err := db.SaveTournamentStanding(t.ID, standingRow(p)); err != nil {
+		util.Error(str.CDB, "[%s] tournament standing write failed user=%d: %s", t.ID, p.UserID, err.Error())
+	}
+}
+
+// saveAllLocked writes every standings row: a result moves the ranks, and
+// in a Swiss every opponent's Buchholz, of players who did not play.
+func (t *Tournament) saveAllLocked() {
+	for _, p := range t.players {
+		t.saveLocked(p)
+	}
+}
+
+// standingRow is a Player's tournament_players row.
+func standingRow(p *Player) db.TournamentStanding {
+	return db.TournamentStanding{
+		UserID:    p.UserID,
+		Username:  p.Username,
+		Title:     p.Title.Code,
+		Rating:    p.Rating,
+		Score:     p.Score,
+		Tiebreak:  p.Tiebreak,
+		Wins:      p.Wins,
+		Draws:     p.Draws,
+		Losses:    p.Losses,
+		Sheet:     p.Sheet,
+		Rank:      p.Rank,
+		Withdrawn: p.Withdrawn,
+	}
+}
+
+// restore rebuilds an unfinished event from its rows.
+func restore(info db.TournamentInfo) (*Tournament, error) {
+	v, ok := pools.Map[info.Variant]
+	if !ok {
+		return nil, errors.New("unknown variant " + info.Variant)
+	}
+	t := newTournament(info.ID, Config{
+		Name:      info.Name,
+		Format:    Format(info.Format),
+		Variant:   v,
+		Rated:     info.Rated,
+		Berserk:   info.Berserk,
+		Rounds:    info.Rounds,
+		Duration:  info.Duration,
+		StartsAt:  info.StartsAt,
+		CreatedBy: info.CreatedBy,
+	})
+	t.status = Status(info.Status)
+	t.round = info.Round
+	t.startedAt = info.StartedAt
+
+	standings, err := db.TournamentStandings(info.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range standings {
+		t.players[s.UserID] = &Player{
+			UserID:    s.UserID,
+			Username:  s.Username,
+			Title:     title.Title{Code: s.Title},
+			Rating:    s.Rating,
+			Sheet:     s.Sheet,
+			Withdrawn: s.Withdrawn,
+		}
+	}
+
+	pairings, err := db.TournamentPairings(info.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range pairings {
+		p := &pairing{id: r.ID, round: r.Round, roomID: r.RoomID, white: r.White, black: r.Black, result: r.Result}
+		t.pairings = append(t.pairings, p)
+		if p.result != "" {
+			continue
+		}
+		// a game whose room survived the restart finishes and reports as
+		// usual; one whose room did not is void
+		if _, err := room.Get(p.roomID); err == nil {
+			t.live[p.roomID] = p
+			continue
+		}
+		p.result = "v"
+		if err := db.SetTournamentPairingResult(p.id, p.result); err != nil {
+			util.Error(str.CDB, "[%s] tournament result write failed room=%s: %s", t.ID, p.roomID, err.Error())
+		}
+		if white, black := t.players[p.white], t.players[p.black]; white != nil && black != nil {
+			t.scoreLocked(p, white, black, room.Result{})
+		}
+	}
+
+	// a Swiss round with nothing left to play is waiting on its successor
+	if t.status == Started && t.cfg.Format == Swiss && len(t.live) == 0 {
+		t.nextRound = time.Now().Add(swissRoundBreak)
+	}
+	t.rankLocked()
+	t.saveAllLocked()
+	return t, nil
+}
+
+// Archived reads a finished event this process no longer holds, for its page.
+// ok is false when there is no such event.
+func Archived(id string) (Summary, proto.TournamentPayload, bool, error) {
+	info, ok, err := db.GetTournament(id)
+	if err != nil || !ok {
+		return Summary{}, proto.TournamentPayload{}, false, err
+	}
+	standings, err := db.TournamentStandings(id)
+	if err != nil {
+		return Summary{}, proto.TournamentPayload{}, false, err
+	}
+
+	sum := Summary{
+		ID:        info.ID,
+		Name:      info.Name,
+		Format:    Format(info.Format),
+		Variant:   pools.Map[info.Variant],
+		Rated:     info.Rated,
+		Berserk:   info.Berserk,
+		Rounds:    info.Rounds,
+		Duration:  info.Duration,
+		Status:    Status(info.Status),
+		Round:     info.Round,
+		StartsAt:  info.StartsAt,
+		CreatedBy: info.CreatedBy,
+		Players:   len(standings),
+	}
+	payload := proto.TournamentPayload{
+		ID:        info.ID,
+		Status:    info.Status,
+		Round:     info.Round,
+		Rounds:    info.Rounds,
+		Standings: make([]proto.TournamentRow, 0, len(standings)),
+	}
+	for _, s := range standings {
+		payload.Standings = append(payload.Standings, proto.TournamentRow{
+			Rank:      s.Rank,
+			Name:      s.Username,
+			Title:     s.Title,
+			Rating:    s.Rating,
+			Score:     s.Score,
+			Tiebreak:  s.Tiebreak,
+			Sheet:     s.Sheet,
+			Withdrawn: s.Withdrawn,
+		})
+	}
+	return sum, payload, true, nil
+}
@@ -0,0 +1,174 @@
+package tune
+
+import (
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/tablebase"
+)
+
+// batchGames is how many games one archive read covers.
+const batchGames = 200
+
+// holdoutEvery holds every tenth game out of the fit: its positions measure
+// whether the fitted weights predict games they were not fitted to, which a
+// loss on the fitted positions alone cannot show.
+const holdoutEvery = 10
+
+// entry is one fitted weight's coefficient in a position's evaluation.
+type entry struct {
+	param int32
+	coef  float32
+}
+
+// sample is one training position: its evaluation's fixed base and its
+// entries (dataset.entries[start:end]), and the game's result for the side to
+// move: 1 for a win, 0.5 for a draw, 0 for a loss.
+type sample struct {
+	start, end int32
+	base       float32
+	result     float32
+}
+
+// dataset is a set of training positions, their entries packed in one slice:
+// a million positions are tens of millions of entries, and a slice each would
+// double the memory.
+type dataset struct {
+	entries []entry
+	samples []sample
+}
+
+// add appends a position.
+func (d *dataset) add(base float64, coef map[int]float64, result float64) {
+	s := sample{start: int32(len(d.entries)), base: float32(base), result: float32(result)}
+	for i, c := range coef {
+		d.entries = append(d.entries, entry{param: int32(i), coef: float32(c)})
+	}
+	s.end = int32(len(d.entries))
+	d.samples = append(d.samples, s)
+}
+
+// size is the number of positions in d.
+func (d *dataset) size() int {
+	return len(d.samples)
+}
+
+// eval is sample s's static evaluation under weights v.
+func (d *dataset) eval(s sample, v []float64) float64 {
+	eval := float64(s.base)
+	for _, e := range d.entries[s.start:s.end] {
+		eval += float64(e.coef) * v[e.param]
+	}
+	return eval
+}
+
+// source yields the archive's positions a batch of games at a time, oldest
+// game first. It is db.GamesAfter and db.TuningPlies in production.
+type source struct {
+	gamesAfter func(cursor int32, n int) ([]int32, error)
+	plies      func(after, through int32) ([]db.TuningPly, error)
+}
+
+// archive is the production source.
+var archive = source{gamesAfter: db.GamesAfter, plies: db.TuningPlies}
+
+// loaded is what load read.
+type loaded struct {
+	// games counts the games positions were read from, and positions every
+	// position they reached.
+	games, positions int
+	train, holdout   dataset
+}
+
+// load reads the archive's quiet positions (see quiet) until limit of them are
+// kept or the archive runs out, putting every holdoutEvery'th game's aside.
+func load(src source, l *layout, limit int) (*loaded, error) {
+	out := &loaded{}
+	var cursor int32
+	for out.train.size()+out.holdout.size() < limit {
+		refs, err := src.gamesAfter(cursor, batchGames)
+		if err != nil {
+			return nil, err
+		}
+		if len(refs) == 0 {
+			break
+		}
+		plies, err := src.plies(cursor, refs[len(refs)-1])
+		if err != nil {
+			return nil, err
+		}
+		cursor = refs[len(refs)-1]
+
+		last := int32(-1)
+		for _, ply := range plies {
+			if ply.GameRef != last {
+				out.games++
+				last = ply.GameRef
+			}
+			out.positions++
+
+			white, ok := whiteScore(ply.Outcome)
+			if !ok {
+				continue
+			}
+			g, ok := quiet(ply.OFEN)
+			if !ok {
+				continue
+			}
+			result := white
+			if g.Position().Turn() == octad.Black {
+				result = 1 - white
+			}
+
+			set := &out.train
+			if ply.GameRef%holdoutEvery == 0 {
+				set = &out.holdout
+			}
+			base, coef := l.coefficients(g)
+			set.add(base, coef, result)
+			if out.train.size()+out.holdout.size() >= limit {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// whiteScore is white's score in a PGN result.
+func whiteScore(outcome string) (float64, bool) {
+	switch outcome {
+	case string(octad.WhiteWon):
+		return 1, true
+	case string(octad.BlackWon):
+		return 0, true
+	case string(octad.Draw):
+		return 0.5, true
+	}
+	return 0, false
+}
+
+// quiet parses a position and reports whether its static evaluation is what
+// the search would score it with: the game goes on, the side to move is not in
+// check and has no capture to make, and the tablebase does not know it. A
+// position with a capture pending is worth whatever the capture sequence
+// settles to, which the static evaluation cannot see, and a solved one is
+// never evaluated at all. The in-check penalty is therefore never fitted.
+func quiet(ofen string) (*octad.Game, bool) {
+	o, err := octad.OFEN(ofen)
+	if err != nil {
+		return nil, false
+	}
+	g, err := octad.NewGame(o)
+	if err != nil || g.Outcome() != octad.NoOutcome || g.Position().InCheck() {
+		return nil, false
+	}
+	for _, m := range g.ValidMoves() {
+		if m.HasTag(octad.Capture) {
+			return nil, false
+		}
+	}
+	if _, solved := tablebase.Probe(g.Position()); solved {
+		return nil, false
+	}
+	return g, true
+}
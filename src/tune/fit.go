@@ -0,0 +1,157 @@
+package tune
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// The fit's fixed settings.
+const (
+	// learningRate is Adam's step size, in evaluation units (a pawn is 10):
+	// enough to move a piece value by tens of units over a run. The steps
+	// shrink on their own as a weight settles and its gradient starts to
+	// change sign from one iteration to the next.
+	learningRate = 0.05
+	// pull is the L2 penalty on each weight's distance from where it started.
+	// A square or term the archive barely exercises has almost no gradient of
+	// its own, and without a pull would wander on noise; with it, a weight
+	// moves only as far as the positions give it reason to.
+	pull = 1e-5
+)
+
+// softplus is log(1 + e^z), computed without overflowing.
+func softplus(z float64) float64 {
+	return math.Max(z, 0) + math.Log1p(math.Exp(-math.Abs(z)))
+}
+
+// sigmoid is 1 / (1 + e^-z).
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+// chunks splits n positions into one range per core.
+func chunks(n int) [][2]int {
+	workers := min(runtime.GOMAXPROCS(0), max(n, 1))
+	out := make([][2]int, 0, workers)
+	for w := range workers {
+		out = append(out, [2]int{n * w / workers, n * (w + 1) / workers})
+	}
+	return out
+}
+
+// loss is the mean cross-entropy between d's results and the win expectancy
+// sigmoid(k * eval) the weights v predict: how surprised the evaluation is by
+// how its positions' games actually ended. A draw is half a win, so a position
+// scored even predicts it best.
+func (d *dataset) loss(v []float64, k float64) float64 {
+	if d.size() == 0 {
+		return 0
+	}
+	parts := chunks(d.size())
+	sums := make([]float64, len(parts))
+	var wg sync.WaitGroup
+	for p, r := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, s := range d.samples[r[0]:r[1]] {
+				z := k * d.eval(s, v)
+				sums[p] += softplus(z) - float64(s.result)*z
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0.0
+	for _, s := range sums {
+		total += s
+	}
+	return total / float64(d.size())
+}
+
+// gradient returns the gradient of d's loss at v.
+func (d *dataset) gradient(v []float64, k float64) []float64 {
+	parts := chunks(d.size())
+	grads := make([][]float64, len(parts))
+	var wg sync.WaitGroup
+	for p, r := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g := make([]float64, len(v))
+			for _, s := range d.samples[r[0]:r[1]] {
+				// d loss / d eval for one position
+				delta := k * (sigmoid(k*d.eval(s, v)) - float64(s.result))
+				for _, e := range d.entries[s.start:s.end] {
+					g[e.param] += delta * float64(e.coef)
+				}
+			}
+			grads[p] = g
+		}()
+	}
+	wg.Wait()
+
+	out := make([]float64, len(v))
+	for _, g := range grads {
+		for i, x := range g {
+			out[i] += x
+		}
+	}
+	for i := range out {
+		out[i] /= float64(max(d.size(), 1))
+	}
+	return out
+}
+
+// fitK finds the scale k that turns evaluations into win expectancies best
+// for weights v: the one that minimises d's loss. It is fitted once, to the
+// starting weights, and then held: the weights are fitted to predict results
+// on the scale the current evaluation already has, so their unit — a pawn is
+// 10 — stays put instead of drifting with k.
+func fitK(d *dataset, v []float64) float64 {
+	// golden-section search over log10 k: the loss is unimodal in k, and the
+	// right scale is somewhere between a pawn being worthless and decisive
+	lo, hi := -5.0, 1.0
+	ratio := (math.Sqrt(5) - 1) / 2
+	a, b := hi-ratio*(hi-lo), lo+ratio*(hi-lo)
+	fa, fb := d.loss(v, math.Pow(10, a)), d.loss(v, math.Pow(10, b))
+	for range 60 {
+		if fa < fb {
+			hi, b, fb = b, a, fa
+			a = hi - ratio*(hi-lo)
+			fa = d.loss(v, math.Pow(10, a))
+		} else {
+			lo, a, fa = a, b, fb
+			b = lo + ratio*(hi-lo)
+			fb = d.loss(v, math.Pow(10, b))
+		}
+	}
+	return math.Pow(10, (lo+hi)/2)
+}
+
+// fit minimises d's loss over the weights from start with Adam, for the given
+// number of iterations, each over every position. progress, if set, is called
+// every hundred iterations and at the last with the iteration and its loss.
+func fit(d *dataset, start []float64, k float64, iterations int, progress func(iteration int, loss float64)) []float64 {
+	const beta1, beta2, epsilon = 0.9, 0.999, 1e-8
+
+	v := append([]float64(nil), start...)
+	m := make([]float64, len(v))
+	s := make([]float64, len(v))
+	for it := 1; it <= iterations; it++ {
+		g := d.gradient(v, k)
+		for i := range v {
+			g[i] += pull * (v[i] - start[i])
+			m[i] = beta1*m[i] + (1-beta1)*g[i]
+			s[i] = beta2*s[i] + (1-beta2)*g[i]*g[i]
+			mHat := m[i] / (1 - math.Pow(beta1, float64(it)))
+			sHat := s[i] / (1 - math.Pow(beta2, float64(it)))
+			v[i] -= learningRate * mHat / (math.Sqrt(sHat) + epsilon)
+		}
+		if progress != nil && (it%100 == 0 || it == iterations) {
+			progress(it, d.loss(v, k))
+		}
+	}
+	return v
+}
@@ -0,0 +1,234 @@
+package tune
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/engine"
+)
+
+// squares is every board square, in the row order engine_tables.go writes a
+// table in: a4 through d4 at the top, down to a1 through d1.
+var squares = []octad.Square{
+	octad.A4, octad.B4, octad.C4, octad.D4,
+	octad.A3, octad.B3, octad.C3, octad.D3,
+	octad.A2, octad.B2, octad.C2, octad.D2,
+	octad.A1, octad.B1, octad.C1, octad.D1,
+}
+
+// colors and pieces are the orders the layout numbers tables in.
+var (
+	colors = []octad.Color{octad.White, octad.Black}
+	pieces = []octad.PieceType{octad.King, octad.Queen, octad.Rook, octad.Bishop, octad.Knight, octad.Pawn}
+)
+
+// fitted are the piece types whose value is fitted. The pawn's value is the
+// unit everything else is measured in, and each side always has exactly one
+// king, so its value cancels out of every position.
+var fitted = []octad.PieceType{octad.Queen, octad.Rook, octad.Bishop, octad.Knight}
+
+// layout numbers the fitted weights of an engine.Weights: a vector of the
+// fitted piece values, then every distinct piece-square table's squares, then
+// the positional terms. Every evaluation term is linear in its weight, so a
+// quiet position's static evaluation is a fixed base (the pawns' material)
+// plus the dot product of this vector with the position's coefficients.
+type layout struct {
+	// from is the weights the layout was taken from; the vector starts there.
+	from *engine.Weights
+	// material maps a fitted piece type to its index.
+	material map[octad.PieceType]int
+	// tables are the distinct tables in the order numbered, and table maps a
+	// color and piece type to the index of its first square. A table the
+	// colors share is numbered once, so it stays shared.
+	tables []tableSlot
+	table  map[octad.Color]map[octad.PieceType]int
+	// terms is the index of the first positional term.
+	terms int
+	size  int
+}
+
+// tableSlot is one distinct piece-square table.
+type tableSlot struct {
+	// name is the table's variable name in the generated file: the piece
+	// for a shared table ("bishop"), color and piece otherwise ("whiteKing").
+	name  string
+	start int
+}
+
+// newLayout numbers the weights of w.
+func newLayout(w *engine.Weights) *layout {
+	l := &layout{
+		from:     w,
+		material: map[octad.PieceType]int{},
+		table:    map[octad.Color]map[octad.PieceType]int{},
+	}
+	for _, pt := range fitted {
+		l.material[pt] = l.size
+		l.size++
+	}
+
+	// tables are told apart by identity: the colors share a table when the
+	// weights hold the same map for both
+	owners := map[uintptr][]octad.Color{}
+	for _, c := range colors {
+		for _, pt := range pieces {
+			if t := w.Tables[c][pt]; t != nil {
+				id := reflect.ValueOf(t).Pointer()
+				owners[id] = append(owners[id], c)
+			}
+		}
+	}
+	seen := map[uintptr]int{}
+	for _, c := range colors {
+		l.table[c] = map[octad.PieceType]int{}
+		for _, pt := range pieces {
+			t := w.Tables[c][pt]
+			if t == nil {
+				continue
+			}
+			id := reflect.ValueOf(t).Pointer()
+			start, ok := seen[id]
+			if !ok {
+				start = l.size
+				seen[id] = start
+				l.tables = append(l.tables, tableSlot{name: tableName(pt, owners[id]), start: start})
+				l.size += len(squares)
+			}
+			l.table[c][pt] = start
+		}
+	}
+
+	l.terms = l.size
+	l.size += int(engine.NumTerms)
+	return l
+}
+
+// tableName names a table for the piece and the colors that use it.
+func tableName(pt octad.PieceType, owners []octad.Color) string {
+	name := pieceName(pt)
+	if len(owners) == 1 {
+		return colorName(owners[0]) + strings.ToUpper(name[:1]) + name[1:]
+	}
+	return name
+}
+
+func pieceName(pt octad.PieceType) string {
+	switch pt {
+	case octad.King:
+		return "king"
+	case octad.Queen:
+		return "queen"
+	case octad.Rook:
+		return "rook"
+	case octad.Bishop:
+		return "bishop"
+	case octad.Knight:
+		return "knight"
+	}
+	return "pawn"
+}
+
+func colorName(c octad.Color) string {
+	if c == octad.Black {
+		return "black"
+	}
+	return "white"
+}
+
+// squareIndex is sq's offset within a table's slot.
+func squareIndex(sq octad.Square) int {
+	for i, s := range squares {
+		if s == sq {
+			return i
+		}
+	}
+	return -1
+}
+
+// vector returns the layout's weights as a vector.
+func (l *layout) vector() []float64 {
+	v := make([]float64, l.size)
+	for pt, i := range l.material {
+		v[i] = l.from.Material[pt]
+	}
+	for _, c := range colors {
+		for pt, start := range l.table[c] {
+			for i, sq := range squares {
+				v[start+i] = l.from.Tables[c][pt][sq]
+			}
+		}
+	}
+	for t := range engine.NumTerms {
+		v[l.terms+int(t)] = l.from.Terms[t]
+	}
+	return v
+}
+
+// weights returns the weights v holds, on fresh maps: the piece values it does
+// not fit are copied from the layout's weights, and shared tables stay shared.
+func (l *layout) weights(v []float64) *engine.Weights {
+	w := &engine.Weights{
+		Material: map[octad.PieceType]float64{},
+		Tables:   map[octad.Color]engine.PieceTypeTable{},
+	}
+	for pt, value := range l.from.Material {
+		w.Material[pt] = value
+	}
+	for pt, i := range l.material {
+		w.Material[pt] = v[i]
+	}
+
+	built := map[int]engine.PieceSquareTable{}
+	for _, c := range colors {
+		w.Tables[c] = engine.PieceTypeTable{}
+		for pt, start := range l.table[c] {
+			t, ok := built[start]
+			if !ok {
+				t = engine.PieceSquareTable{}
+				for i, sq := range squares {
+					t[sq] = v[start+i]
+				}
+				built[start] = t
+			}
+			w.Tables[c][pt] = t
+		}
+	}
+
+	for t := range engine.NumTerms {
+		w.Terms[t] = v[l.terms+int(t)]
+	}
+	return w
+}
+
+// coefficients returns a position's evaluation as the layout sees it, relative
+// to the side to move: the part no fitted weight moves (the pawns' material),
+// and each fitted weight's coefficient. The position must not have ended.
+func (l *layout) coefficients(g *octad.Game) (base float64, coef map[int]float64) {
+	mover := g.Position().Turn()
+	coef = map[int]float64{}
+	for sq, p := range g.Position().Board().SquareMap() {
+		sign := 1.0
+		if p.Color() != mover {
+			sign = -1
+		}
+		if i, ok := l.material[p.Type()]; ok {
+			coef[i] += sign
+		} else {
+			base += sign * l.from.Material[p.Type()]
+		}
+		if start, ok := l.table[p.Color()][p.Type()]; ok {
+			coef[start+squareIndex(sq)] += sign
+		}
+	}
+	for t, n := range engine.PositionTerms(g) {
+		coef[l.terms+t] += n
+	}
+	for i, c := range coef {
+		if c == 0 {
+			delete(coef, i)
+		}
+	}
+	return base, coef
+}
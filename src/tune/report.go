@@ -0,0 +1,175 @@
+package tune
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dechristopher/lio/engine"
+	"github.com/dechristopher/lio/selfplay"
+)
+
+// Report is a finished tuning run.
+type Report struct {
+	// Games and Positions count what was read from the archive; Fitted and
+	// HeldOut the quiet positions the fit used and the ones kept from it.
+	Games, Positions int
+	Fitted, HeldOut  int
+	// K is the scale fitted to the starting weights (see fitK).
+	K float64
+	// FittedLoss and HeldOutLoss are the loss before and after the fit.
+	FittedLoss, HeldOutLoss Loss
+	// Material and Terms are the fitted piece values and positional terms.
+	Material, Terms []Change
+	// Tables summarise how far each piece-square table moved.
+	Tables []TableShift
+	// SelfPlay is the before/after self-play, nil when it was skipped.
+	SelfPlay *selfplay.Report
+	Elapsed  time.Duration
+}
+
+// Loss is a loss before and after the fit.
+type Loss struct {
+	Before, After float64
+}
+
+// Change is one weight's starting and fitted value.
+type Change struct {
+	Name     string
+	From, To float64
+}
+
+// TableShift is how far a piece-square table moved: the mean change over its
+// squares, and the largest and where.
+type TableShift struct {
+	Name      string
+	Mean, Max float64
+	At        string
+}
+
+// newReport describes the fit from weights from to weights to.
+func newReport(l *layout, data *loaded, k float64, from, to []float64) *Report {
+	r := &Report{
+		Games:       data.games,
+		Positions:   data.positions,
+		Fitted:      data.train.size(),
+		HeldOut:     data.holdout.size(),
+		K:           k,
+		FittedLoss:  Loss{data.train.loss(from, k), data.train.loss(to, k)},
+		HeldOutLoss: Loss{data.holdout.loss(from, k), data.holdout.loss(to, k)},
+	}
+	for _, pt := range fitted {
+		i := l.material[pt]
+		name := pieceName(pt)
+		r.Material = append(r.Material, Change{strings.ToUpper(name[:1]) + name[1:], from[i], to[i]})
+	}
+	for t := range engine.NumTerms {
+		i := l.terms + int(t)
+		r.Terms = append(r.Terms, Change{t.String(), from[i], to[i]})
+	}
+	for _, t := range l.tables {
+		shift := TableShift{Name: t.name}
+		for i, sq := range squares {
+			d := math.Abs(to[t.start+i] - from[t.start+i])
+			shift.Mean += d / float64(len(squares))
+			if d > shift.Max {
+				shift.Max, shift.At = d, sq.String()
+			}
+		}
+		r.Tables = append(r.Tables, shift)
+	}
+	return r
+}
+
+// notes are the generated file's doc comment.
+func (r *Report) notes() []string {
+	return []string{
+		fmt.Sprintf("Evaluation weights fitted on %s to %d quiet positions from %d archived",
+			time.Now().Format(time.DateOnly), r.Fitted, r.Games),
+		fmt.Sprintf("games, at scale %.5f. Held-out loss %.5f before, %.5f after.",
+			r.K, r.HeldOutLoss.Before, r.HeldOutLoss.After),
+		"",
+		"Copy this file into engine/ to make them the engine's DefaultWeights, and",
+		"delete it to go back to HandTuned.",
+	}
+}
+
+// String renders the report as report.txt has it.
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Tuned on %d quiet positions (%d held out) of %d from %d games, %s\n",
+		r.Fitted+r.HeldOut, r.HeldOut, r.Positions, r.Games, r.Elapsed.Round(time.Second))
+	fmt.Fprintf(&sb, "Scale %.5f\n\n", r.K)
+
+	fmt.Fprintf(&sb, "%-9s  %8s  %8s\n", "Loss", "Before", "After")
+	fmt.Fprintf(&sb, "%-9s  %8.5f  %8.5f\n", "fitted", r.FittedLoss.Before, r.FittedLoss.After)
+	if r.HeldOut > 0 {
+		fmt.Fprintf(&sb, "%-9s  %8.5f  %8.5f\n", "held out", r.HeldOutLoss.Before, r.HeldOutLoss.After)
+	}
+
+	changes := func(title string, cs []Change) {
+		fmt.Fprintf(&sb, "\n%-14s  %8s  %8s\n", title, "From", "To")
+		for _, c := range cs {
+			fmt.Fprintf(&sb, "%-14s  %8.2f  %8.2f\n", c.Name, c.From, c.To)
+		}
+	}
+	changes("Material", r.Material)
+	changes("Term", r.Terms)
+
+	fmt.Fprintf(&sb, "\n%-14s  %8s  %8s\n", "Table", "Mean", "Largest")
+	for _, t := range r.Tables {
+		fmt.Fprintf(&sb, "%-14s  %8.2f  %8.2f at %s\n", t.Name, t.Mean, t.Max, t.At)
+	}
+
+	if r.SelfPlay != nil {
+		sb.WriteByte('\n')
+		sb.WriteString(r.selfPlay())
+	}
+	fmt.Fprintf(&sb, "\nCopy %s into engine/ to adopt the weights.\n", File)
+	return sb.String()
+}
+
+// selfPlay renders the before/after self-play: the tuned Queen's rating beside
+// the ladder's Queen, both measured against the same ladder.
+func (r *Report) selfPlay() string {
+	var before, after *selfplay.Standing
+	top := engine.Personas[len(engine.Personas)-1].Key
+	for i, s := range r.SelfPlay.Standings {
+		switch {
+		case s.Entrant.Base != top:
+		case s.Entrant.Eval == tunedEval:
+			after = &r.SelfPlay.Standings[i]
+		case s.Entrant.Eval == "" && len(s.Entrant.Changes) == 0:
+			before = &r.SelfPlay.Standings[i]
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Self-play at %s: %d games in %d rounds against the ladder",
+		r.SelfPlay.Variant.Name, r.SelfPlay.Played, r.SelfPlay.Rounds)
+	if r.SelfPlay.Failed > 0 {
+		fmt.Fprintf(&sb, ", %d failed", r.SelfPlay.Failed)
+	}
+	sb.WriteString(" (standings in selfplay/report.txt)\n")
+	if before == nil || after == nil {
+		return sb.String()
+	}
+	for _, s := range []*selfplay.Standing{before, after} {
+		fmt.Fprintf(&sb, "%-18s  %6.0f ± %3.0f  %5.1f%% of %d\n",
+			s.Entrant.Name, s.Rating.R, 2*s.Rating.RD, 100*s.Points/float64(max(s.Games, 1)), s.Games)
+	}
+	// two rating deviations of the difference: about 95% confidence
+	diff := after.Rating.R - before.Rating.R
+	margin := 2 * math.Hypot(before.Rating.RD, after.Rating.RD)
+	fmt.Fprintf(&sb, "Tuned %+.0f ± %.0f", diff, margin)
+	switch {
+	case diff > margin:
+		sb.WriteString(": stronger\n")
+	case -diff > margin:
+		sb.WriteString(": weaker\n")
+	default:
+		sb.WriteString(": not separated; play more rounds to tell\n")
+	}
+	return sb.String()
+}
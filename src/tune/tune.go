@@ -0,0 +1,144 @@
+// Package tune is the evaluation tuner behind `lio --tune`. It fits the
+// engine's evaluation weights — piece values, piece-square tables and the
+// positional terms (engine.Weights) — to the results of the archived games:
+// every quiet position a game reached is labelled with how that game ended,
+// and the weights are moved to minimise the logistic loss of predicting the
+// label from the evaluation (Texel's method). The pawn stays at 10, so the
+// fitted weights are in the units the hand-tuned ones are.
+//
+// A run writes the fitted weights as a Go file of package engine whose init
+// makes them the engine's DefaultWeights, and a report of the fit. Unless told
+// not to, it then measures them: the self-play harness plays a Queen that
+// searches with them against the persona ladder, which still plays the
+// current evaluation, and the report puts the two Queens' ratings side by
+// side. Adopting the weights is copying the file into engine/.
+package tune
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/dechristopher/lio/engine"
+	"github.com/dechristopher/lio/selfplay"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Defaults for the --tune flags.
+const (
+	// DefaultPositions caps the positions read. Each holds about forty
+	// coefficients in memory; half a million fit comfortably and are plenty
+	// for the few hundred weights fitted.
+	DefaultPositions = 500_000
+	// DefaultIterations is how many full passes over the positions the fit
+	// makes, each a gradient step on every weight.
+	DefaultIterations = 1000
+	// DefaultRounds is how many times the before/after self-play plays every
+	// pairing.
+	DefaultRounds = 4
+	// DefaultOut is the directory the weights file and reports are written to.
+	DefaultOut = "tune-results"
+)
+
+// tunedEval is the Eval name of the self-play entrant with the fitted weights.
+const tunedEval = "tuned"
+
+// Config is one tuning run.
+type Config struct {
+	// Positions caps the quiet positions read from the archive, oldest game
+	// first.
+	Positions int
+	// Iterations is the number of gradient steps.
+	Iterations int
+	// Control is the time control the before/after self-play is played at (a
+	// variant HTMLName), and Rounds how many times it plays every pairing. No
+	// rounds skips it.
+	Control string
+	Rounds  int
+	// Parallel is how many self-play games are played at once.
+	Parallel int
+	// Out is the directory File, report.txt and the self-play's files (in
+	// selfplay/) are written to.
+	Out string
+}
+
+// NewConfig is a run with every setting at its default.
+func NewConfig() Config {
+	return Config{
+		Positions:  DefaultPositions,
+		Iterations: DefaultIterations,
+		Control:    selfplay.DefaultControl,
+		Rounds:     DefaultRounds,
+		Parallel:   runtime.GOMAXPROCS(0),
+		Out:        DefaultOut,
+	}
+}
+
+// Run fits the engine's current DefaultWeights to the archive, writes the fit
+// to Out/File and the report to Out/report.txt, and returns the report. The
+// database must be up, and for the self-play, the engine dispatcher.
+func Run(cfg Config) (*Report, error) {
+	if cfg.Positions < 1 || cfg.Iterations < 1 || cfg.Rounds < 0 {
+		return nil, errors.New("tuning needs positions to fit and iterations to fit them in")
+	}
+	// a bad time control should fail now, not after the fit
+	var sp selfplay.Config
+	if cfg.Rounds > 0 {
+		var err error
+		if sp, err = selfplay.NewConfig(cfg.Control, "", false); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(cfg.Out, 0o755); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	l := newLayout(engine.DefaultWeights)
+	util.Info(str.CTune, "reading up to %d quiet positions from the archive", cfg.Positions)
+	data, err := load(archive, l, cfg.Positions)
+	if err != nil {
+		return nil, err
+	}
+	if data.train.size() == 0 {
+		return nil, fmt.Errorf("no quiet positions to fit in %d archived games", data.games)
+	}
+	util.Info(str.CTune, "%d quiet positions of %d from %d games, %d held out",
+		data.train.size()+data.holdout.size(), data.positions, data.games, data.holdout.size())
+
+	from := l.vector()
+	k := fitK(&data.train, from)
+	util.Info(str.CTune, "scale %.5f, loss %.5f; fitting %d weights over %d iterations",
+		k, data.train.loss(from, k), l.size, cfg.Iterations)
+	to := roundAll(fit(&data.train, from, k, cfg.Iterations, func(it int, loss float64) {
+		util.Info(str.CTune, "iteration %d/%d loss %.5f", it, cfg.Iterations, loss)
+	}))
+
+	report := newReport(l, data, k, from, to)
+	src, err := generate(l, to, report.notes())
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(cfg.Out, File), src, 0o644); err != nil {
+		return nil, err
+	}
+
+	if cfg.Rounds > 0 {
+		top := engine.Personas[len(engine.Personas)-1]
+		sp.Entrants = append(sp.Entrants, selfplay.EvalVariant(top, tunedEval, l.weights(to)))
+		sp.Rounds, sp.Parallel, sp.Out = cfg.Rounds, cfg.Parallel, filepath.Join(cfg.Out, "selfplay")
+		if report.SelfPlay, err = selfplay.Run(sp); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Elapsed = time.Since(start)
+	if err := os.WriteFile(filepath.Join(cfg.Out, "report.txt"), []byte(report.String()), 0o644); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
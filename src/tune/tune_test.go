@@ -0,0 +1,252 @@
+package tune
+
+import (
+	"go/parser"
+	"go/token"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/engine"
+)
+
+// randomGames plays n random games of up to maxPlies from the standard start,
+// returning every position each reached that has not ended, by game.
+func randomGames(n, maxPlies int) [][]*octad.Game {
+	rng := rand.New(rand.NewSource(42))
+	games := make([][]*octad.Game, n)
+	for i := range games {
+		o, _ := octad.OFEN("ppkn/4/4/NKPP w NCFncf - 0 1")
+		g, _ := octad.NewGame(o)
+		for range rng.Intn(maxPlies) + 1 {
+			moves := g.ValidMoves()
+			if len(moves) == 0 || g.Outcome() != octad.NoOutcome {
+				break
+			}
+			_ = g.Move(moves[rng.Intn(len(moves))])
+			if g.Outcome() != octad.NoOutcome {
+				break
+			}
+			o, _ := octad.OFEN(g.Position().String())
+			at, _ := octad.NewGame(o)
+			games[i] = append(games[i], at)
+		}
+	}
+	return games
+}
+
+// TestCoefficientsMatchEvaluate checks the layout's linear form of a position
+// is the engine's evaluation of it, to the last bit the weights allow: the
+// fit is only as good as this identity.
+func TestCoefficientsMatchEvaluate(t *testing.T) {
+	l := newLayout(&engine.HandTuned)
+	v := l.vector()
+	for _, game := range randomGames(40, 16) {
+		for _, g := range game {
+			want := engine.HandTuned.Evaluate(g)
+			if g.Position().InCheck() {
+				want += engine.CheckPenalty
+			}
+			d := dataset{}
+			base, coef := l.coefficients(g)
+			d.add(base, coef, 0)
+			if got := d.eval(d.samples[0], v); math.Abs(got-want) > 1e-3 {
+				t.Errorf("OFEN %s: layout eval %.4f, engine %.4f", g.Position(), got, want)
+			}
+		}
+	}
+}
+
+// TestLayoutRoundTrip checks the vector holds the weights it was taken from,
+// and that weights rebuilt from it keep the colors' shared tables shared.
+func TestLayoutRoundTrip(t *testing.T) {
+	from := &engine.HandTuned
+	l := newLayout(from)
+	w := l.weights(l.vector())
+
+	if !reflect.DeepEqual(w.Material, from.Material) {
+		t.Errorf("material %v, want %v", w.Material, from.Material)
+	}
+	if !reflect.DeepEqual(w.Tables, from.Tables) {
+		t.Error("tables changed in the round trip")
+	}
+	if w.Terms != from.Terms {
+		t.Errorf("terms %v, want %v", w.Terms, from.Terms)
+	}
+
+	// king, pawn and knight differ by color; bishop, rook and queen are shared
+	if want := 9; len(l.tables) != want {
+		t.Errorf("%d tables, want %d", len(l.tables), want)
+	}
+	if reflect.ValueOf(w.Tables[octad.White][octad.Bishop]).Pointer() != reflect.ValueOf(w.Tables[octad.Black][octad.Bishop]).Pointer() {
+		t.Error("rebuilt bishop tables are not shared")
+	}
+}
+
+// TestFitRecoversWeights fits to positions labelled with the win expectancy of
+// a known evaluation and checks the fit moves toward it.
+func TestFitRecoversWeights(t *testing.T) {
+	l := newLayout(&engine.HandTuned)
+	start := l.vector()
+	truth := append([]float64(nil), start...)
+	knight := l.material[octad.Knight]
+	mobility := l.terms + int(engine.TermMobility)
+	truth[knight] = 40
+	truth[mobility] = 2
+
+	const k = 0.02
+	d := &dataset{}
+	for _, game := range randomGames(300, 16) {
+		for _, g := range game {
+			base, coef := l.coefficients(g)
+			d.add(base, coef, 0)
+			s := &d.samples[len(d.samples)-1]
+			s.result = float32(sigmoid(k * d.eval(*s, truth)))
+		}
+	}
+
+	got := fit(d, start, k, 400, nil)
+	if before, after := d.loss(start, k), d.loss(got, k); after >= before {
+		t.Errorf("loss %.5f after the fit, %.5f before", after, before)
+	}
+	if math.Abs(got[knight]-40) >= math.Abs(start[knight]-40)/2 {
+		t.Errorf("knight fitted to %.2f from %.0f, want near 40", got[knight], start[knight])
+	}
+	if math.Abs(got[mobility]-2) >= math.Abs(start[mobility]-2)/2 {
+		t.Errorf("mobility fitted to %.2f from %.1f, want near 2", got[mobility], start[mobility])
+	}
+}
+
+// TestFitK checks the scale fitted to labels drawn from a known scale is that
+// scale.
+func TestFitK(t *testing.T) {
+	l := newLayout(&engine.HandTuned)
+	v := l.vector()
+	d := &dataset{}
+	for _, game := range randomGames(100, 12) {
+		for _, g := range game {
+			base, coef := l.coefficients(g)
+			d.add(base, coef, 0)
+			s := &d.samples[len(d.samples)-1]
+			s.result = float32(sigmoid(0.03 * d.eval(*s, v)))
+		}
+	}
+	if k := fitK(d, v); math.Abs(k-0.03) > 0.001 {
+		t.Errorf("fitted scale %.5f, want 0.03", k)
+	}
+}
+
+// TestLoad checks positions are read batch by batch, labelled for the side to
+// move, held out by game, and kept only when quiet.
+func TestLoad(t *testing.T) {
+	games := randomGames(25, 12)
+	outcomes := []string{"1-0", "0-1", "1/2-1/2", "*"}
+
+	var calls int
+	src := source{
+		gamesAfter: func(cursor int32, n int) ([]int32, error) {
+			var refs []int32
+			for ref := cursor + 1; ref <= int32(len(games)) && len(refs) < n; ref++ {
+				refs = append(refs, ref)
+			}
+			return refs, nil
+		},
+		plies: func(after, through int32) ([]db.TuningPly, error) {
+			calls++
+			var out []db.TuningPly
+			for ref := after + 1; ref <= through; ref++ {
+				for ply, g := range games[ref-1] {
+					out = append(out, db.TuningPly{
+						GameRef: ref,
+						Ply:     ply + 1,
+						OFEN:    g.Position().String(),
+						Outcome: outcomes[ref%int32(len(outcomes))],
+					})
+				}
+			}
+			return out, nil
+		},
+	}
+
+	l := newLayout(&engine.HandTuned)
+	data, err := load(src, l, math.MaxInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.games != len(games) || calls != 1 {
+		t.Errorf("read %d games in %d batches, want %d in 1", data.games, calls, len(games))
+	}
+
+	var quietTrain, quietHeldOut int
+	var wantResults []float32
+	for ref, game := range games {
+		white, ok := whiteScore(outcomes[(ref+1)%len(outcomes)])
+		for _, g := range game {
+			if _, q := quiet(g.Position().String()); !q || !ok {
+				continue
+			}
+			if (ref+1)%holdoutEvery == 0 {
+				quietHeldOut++
+				continue
+			}
+			quietTrain++
+			if g.Position().Turn() == octad.Black {
+				wantResults = append(wantResults, float32(1-white))
+			} else {
+				wantResults = append(wantResults, float32(white))
+			}
+		}
+	}
+	if data.train.size() != quietTrain || data.holdout.size() != quietHeldOut {
+		t.Fatalf("kept %d + %d held out, want %d + %d",
+			data.train.size(), data.holdout.size(), quietTrain, quietHeldOut)
+	}
+	for i, s := range data.train.samples {
+		if s.result != wantResults[i] {
+			t.Errorf("position %d labelled %.1f, want %.1f", i, s.result, wantResults[i])
+		}
+	}
+
+	if capped, _ := load(src, l, 5); capped.train.size()+capped.holdout.size() != 5 {
+		t.Errorf("limit 5 kept %d", capped.train.size()+capped.holdout.size())
+	}
+}
+
+// TestGenerate checks the weights file is Go that makes the weights the
+// default, with the shared tables written once.
+func TestGenerate(t *testing.T) {
+	l := newLayout(&engine.HandTuned)
+	src, err := generate(l, l.vector(), []string{"Fitted for a test."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(src)
+	if !strings.HasPrefix(out, "// Code generated by lio --tune. DO NOT EDIT.\n") {
+		t.Errorf("no generated-code header:\n%s", out)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), File, src, 0); err != nil {
+		t.Fatalf("generated file does not parse: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"package engine",
+		"// Fitted for a test.",
+		"DefaultWeights = &Weights{",
+		"octad.Queen:       90,",
+		"bishop := PieceSquareTable{",
+		"whiteKing := PieceSquareTable{",
+		"octad.A4: 2, octad.B4: 1, octad.C4: 1, octad.D4: 2,",
+		"TermMobility:       0.5,",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated file missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "octad.Bishop: bishop,"); n != 2 {
+		t.Errorf("bishop table used %d times, want once per color", n)
+	}
+}
@@ -0,0 +1,122 @@
+package tune
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dechristopher/octad/v2"
+
+	"github.com/dechristopher/lio/engine"
+)
+
+// File is the name of the generated weights file.
+const File = "weights_tuned.go"
+
+// places is the decimal places the fitted weights are rounded to.
+const places = 2
+
+// roundAll rounds every weight of v to places decimal places: the weights
+// written are the weights measured.
+func roundAll(v []float64) []float64 {
+	scale := math.Pow(10, places)
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = math.Round(x*scale) / scale
+	}
+	return out
+}
+
+// generate renders weights v as a Go file of package engine whose init makes
+// them the engine's DefaultWeights. The tables are written as engine_tables.go
+// writes them, a row per rank; notes head the file as its doc comment.
+func generate(l *layout, v []float64, notes []string) ([]byte, error) {
+	w := l.weights(v)
+	var b bytes.Buffer
+
+	b.WriteString("// Code generated by lio --tune. DO NOT EDIT.\n\n")
+	for _, n := range notes {
+		b.WriteString(strings.TrimSpace("// " + n))
+		b.WriteByte('\n')
+	}
+	b.WriteString("\npackage engine\n\n")
+	b.WriteString("import \"github.com/dechristopher/octad/v2\"\n\n")
+	b.WriteString("func init() {\n")
+
+	for _, t := range l.tables {
+		fmt.Fprintf(&b, "%s := PieceSquareTable{\n", t.name)
+		for row := 0; row < len(squares); row += 4 {
+			for i, sq := range squares[row : row+4] {
+				if i > 0 {
+					b.WriteByte(' ')
+				}
+				fmt.Fprintf(&b, "%s: %s,", squareConst(sq), number(v[t.start+squareIndex(sq)]))
+			}
+			b.WriteByte('\n')
+		}
+		b.WriteString("}\n")
+	}
+
+	b.WriteString("DefaultWeights = &Weights{\n")
+	b.WriteString("Material: map[octad.PieceType]float64{\n")
+	for _, pt := range append(append([]octad.PieceType(nil), pieces...), octad.NoPieceType) {
+		if value, ok := w.Material[pt]; ok {
+			fmt.Fprintf(&b, "%s: %s,\n", pieceConst(pt), number(value))
+		}
+	}
+	b.WriteString("},\n")
+
+	b.WriteString("Tables: map[octad.Color]PieceTypeTable{\n")
+	for _, c := range colors {
+		fmt.Fprintf(&b, "%s: {\n", colorConst(c))
+		for _, pt := range pieces {
+			start, ok := l.table[c][pt]
+			if !ok {
+				continue
+			}
+			for _, t := range l.tables {
+				if t.start == start {
+					fmt.Fprintf(&b, "%s: %s,\n", pieceConst(pt), t.name)
+				}
+			}
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("},\n")
+
+	b.WriteString("Terms: Terms{\n")
+	for t := range engine.NumTerms {
+		fmt.Fprintf(&b, "Term%s: %s,\n", t, number(w.Terms[t]))
+	}
+	b.WriteString("},\n")
+	b.WriteString("}\n}\n")
+
+	return format.Source(b.Bytes())
+}
+
+// number renders a weight as a Go constant.
+func number(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+func squareConst(sq octad.Square) string {
+	return "octad." + strings.ToUpper(sq.String())
+}
+
+func colorConst(c octad.Color) string {
+	if c == octad.Black {
+		return "octad.Black"
+	}
+	return "octad.White"
+}
+
+func pieceConst(pt octad.PieceType) string {
+	if pt == octad.NoPieceType {
+		return "octad.NoPieceType"
+	}
+	name := pieceName(pt)
+	return "octad." + strings.ToUpper(name[:1]) + name[1:]
+}
@@ -37,6 +37,14 @@ type Account struct {
 	// show the moderation UI; every privileged route re-checks it server-side,
 	// so a hidden control is never the security boundary.
 	Role role.Role
+	// Bot marks a request made by a bot account's program, authenticated with
+	// its bearer token rather than a cookie. The bot API serves only these; the
+	// same account logged in through a browser is not one.
+	Bot bool
+	// TokenID is the personal API token the request presented, zero for a
+	// cookie session. Its scopes were checked before the request got here; the
+	// rate limiter budgets token requests by it.
+	TokenID int64
 }
 
 // GetID is a helper to return the session uid from the request context.
@@ -1,67 +1,415 @@
 package util
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/dechristopher/lio/config"
 	"github.com/dechristopher/lio/env"
 	"github.com/dechristopher/lio/str"
 )
 
+// Logging. Every line is a log/slog record carrying its package, its caller
+// code and whatever identifying fields the call site attached (room, game,
+// uid, account). Records go two places: stdout — human-readable lines in local
+// dev, one JSON object per line everywhere else — and the in-memory ring the
+// /system/log page searches (logbuf.go).
+//
+// The printf-style call shape is kept on purpose. It is what every package
+// already writes, and a field is something a reader filters by, not every
+// value in the sentence: the room a line is about belongs in a field, the move
+// it rejected belongs in the message.
+
 func init() {
-	// microsecond timestamps in local dev logs
-	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+	var out slog.Handler
+	if env.GetEnv() == env.Local {
+		out = &lineHandler{}
+	} else {
+		out = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level:       slog.LevelDebug,
+			ReplaceAttr: jsonAttr,
+		})
+	}
+	logger = slog.New(teeHandler{out, ringHandler{}})
+	slog.SetDefault(logger)
+}
+
+// logger is the process logger every helper below writes through.
+var logger *slog.Logger
+
+// Attribute keys. pkg is what levels are set by and what the log page filters
+// by; caller is the short code each line has always been tagged with.
+const (
+	keyPkg     = "pkg"
+	keyCaller  = "caller"
+	keyRoom    = "room"
+	keyGame    = "game"
+	keyUID     = "uid"
+	keyAccount = "account"
+)
+
+// RoomID tags a line with the room it is about.
+func RoomID(id string) slog.Attr { return slog.String(keyRoom, id) }
+
+// GameID tags a line with the game it is about.
+func GameID(id string) slog.Attr { return slog.String(keyGame, id) }
+
+// UID tags a line with the session uid it is about.
+func UID(uid string) slog.Attr { return slog.String(keyUID, uid) }
+
+// AccountID tags a line with the account it is about.
+func AccountID(id int64) slog.Attr { return slog.Int64(keyAccount, id) }
+
+// Logger writes lines carrying a fixed set of fields. The zero value carries
+// none and is what the package-level helpers use.
+type Logger struct {
+	attrs []slog.Attr
 }
 
-// LogMessage is a json struct used for non-stdout log messages
-type LogMessage struct {
-	Time     int64  `json:"time"`
-	Severity string `json:"severity"`
-	Caller   string `json:"caller"`
-	Message  string `json:"message"`
+// With returns a Logger that tags every line with the given fields.
+func With(fields ...slog.Attr) Logger {
+	return Logger{attrs: fields}
+}
+
+// With returns a copy of the Logger carrying additional fields.
+func (l Logger) With(fields ...slog.Attr) Logger {
+	attrs := make([]slog.Attr, 0, len(l.attrs)+len(fields))
+	attrs = append(attrs, l.attrs...)
+	return Logger{attrs: append(attrs, fields...)}
+}
+
+// Info logs an info message
+func (l Logger) Info(caller, message string, args ...interface{}) {
+	l.write(slog.LevelInfo, PackageOf(caller), caller, false, message, args)
+}
+
+// Debug logs an unscoped debug message. Unlike DebugFlag it is always written:
+// it is the boot-time trace (services coming online) that every instance has
+// printed since before levels existed.
+func (l Logger) Debug(caller, message string, args ...interface{}) {
+	l.write(slog.LevelDebug, PackageOf(caller), caller, true, message, args)
+}
+
+// DebugFlag logs a debug message in the package named by flag, written only
+// while that package's level is debug (see SetLevel).
+func (l Logger) DebugFlag(flag, caller, message string, args ...interface{}) {
+	l.write(slog.LevelDebug, flag, caller, false, message, args)
+}
+
+// Error logs an error message
+func (l Logger) Error(caller, message string, args ...interface{}) {
+	l.write(slog.LevelError, PackageOf(caller), caller, false, message, args)
+}
+
+// write builds and emits one record, unless the package's level is above it.
+// The level check runs before the Sprintf so a disabled debug line costs one
+// map read.
+func (l Logger) write(level slog.Level, pkg, caller string, force bool, message string, args []interface{}) {
+	if !force && level < Level(pkg) {
+		return
+	}
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	r := slog.NewRecord(time.Now(), level, message, 0)
+	r.AddAttrs(slog.String(keyPkg, pkg), slog.String(keyCaller, strings.TrimSpace(caller)))
+	r.AddAttrs(l.attrs...)
+	_ = logger.Handler().Handle(context.Background(), r)
 }
 
 // Info prints an info message to the standard logger
 func Info(caller, message string, args ...interface{}) {
-	printLog("info", str.InfoFormat, caller, message, args...)
+	Logger{}.Info(caller, message, args...)
 }
 
 // Debug prints a debug message to the standard logger
 func Debug(caller, message string, args ...interface{}) {
-	printLog("debug", str.DebugFormat, caller, message, args...)
+	Logger{}.Debug(caller, message, args...)
 }
 
 // DebugFlag prints a debug message to the standard logger if flag is enabled
 func DebugFlag(flag, caller, message string, args ...interface{}) {
-	if config.IsDebugFlag(flag) {
-		printLog("debug", str.DebugFormat, caller, message, args...)
-	}
+	Logger{}.DebugFlag(flag, caller, message, args...)
 }
 
 // Error prints an error message to the standard logger
 func Error(caller, message string, args ...interface{}) {
-	printLog("warn", str.ErrorFormat, caller, message, args...)
+	Logger{}.Error(caller, message, args...)
 }
 
-// printLog prints logs to stdout in the proper format
-// Standard in developer mode and JSON in deploy mode
-func printLog(severity, format, caller, message string, args ...interface{}) {
-	if env.GetEnv() == env.Local {
-		log.Printf(format, caller, fmt.Sprintf(message, args...))
+// --- per-package levels ------------------------------------------------------
+
+// levels maps a package to its level; a package with no entry is at
+// slog.LevelInfo. The map is replaced whole on every change and read without a
+// lock, because the read is on every debug line of every room and the write is
+// an operator changing a dropdown.
+var levels atomic.Pointer[map[string]slog.Level]
+
+// levelsMu serializes writers; readers never take it.
+var levelsMu sync.Mutex
+
+func init() {
+	levels.Store(&map[string]slog.Level{})
+}
+
+// Level returns the package's current level.
+func Level(pkg string) slog.Level {
+	if l, ok := (*levels.Load())[pkg]; ok {
+		return l
+	}
+	return slog.LevelInfo
+}
+
+// SetLevel changes a package's level at runtime. Setting a package back to
+// info removes its entry, so Levels lists only what is overridden.
+func SetLevel(pkg string, level slog.Level) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	next := make(map[string]slog.Level, len(*levels.Load())+1)
+	for k, v := range *levels.Load() {
+		next[k] = v
+	}
+	if level == slog.LevelInfo {
+		delete(next, pkg)
 	} else {
-		logMessage := LogMessage{
-			Time:     MilliTime(),
-			Severity: severity,
-			Caller:   strings.TrimSpace(caller),
-			Message:  fmt.Sprintf(message, args...),
+		next[pkg] = level
+	}
+	levels.Store(&next)
+}
+
+// Levels returns every package whose level differs from info.
+func Levels() map[string]slog.Level {
+	current := *levels.Load()
+	out := make(map[string]slog.Level, len(current))
+	for k, v := range current {
+		out[k] = v
+	}
+	return out
+}
+
+// EnableDebug sets each package named in a --debug flag value (comma
+// separated, e.g. "room,engine") to debug. It is the boot-time spelling of
+// SetLevel, and the scopes it accepts are the same ones DebugFlag is called
+// with.
+func EnableDebug(flags string) {
+	for _, pkg := range strings.Split(flags, ",") {
+		if pkg = strings.TrimSpace(pkg); pkg != "" {
+			SetLevel(pkg, slog.LevelDebug)
+		}
+	}
+}
+
+// ParseLevel reads a level name as the log page and the --debug flag spell
+// it.
+func ParseLevel(name string) (slog.Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "info":
+		return slog.LevelInfo, true
+	case "error":
+		return slog.LevelError, true
+	}
+	return 0, false
+}
+
+// LevelName is the inverse of ParseLevel.
+func LevelName(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return "debug"
+	case level < slog.LevelError:
+		return "info"
+	}
+	return "error"
+}
+
+// callerPackages maps the short caller codes (str.C*) onto the package each is
+// logged from, so an Info or Error line lands in the same package as the
+// DebugFlag lines written beside it.
+var callerPackages = map[string]string{
+	str.CMain:  "main",
+	str.CWS:    "ws",
+	str.CWSC:   "ws",
+	str.CHMov:  "room",
+	str.CRoom:  "room",
+	str.CStor:  "store",
+	str.CEval:  "engine",
+	str.CEng:   "engine",
+	str.CGme:   "game",
+	str.CClk:   "clock",
+	str.CChan:  "channel",
+	str.CUser:  "user",
+	str.CAuth:  "auth",
+	str.CCache: "cache",
+	str.CDB:    "db",
+	str.CNotif: "notify",
+	str.CDump:  "dump",
+	str.CTB:    "tablebase",
+	str.CTour:  "tournament",
+	str.CMatch: "matchmaking",
+	str.CChat:  "chat",
+	str.CPuzl:  "puzzle",
+	str.CCorr:  "correspondence",
+	str.CNode:  "cluster",
+	str.CMail:  "mail",
+	str.CPriv:  "privacy",
+	str.CAnly:  "analysis",
+	str.CFair:  "fairplay",
+	str.CSiml:  "simul",
+}
+
+// debugScopes are the DebugFlag scopes that name a subsystem rather than a
+// package with its own caller code.
+var debugScopes = []string{"dispatch", "crowd", "lag", "pgn", "stat"}
+
+// PackageOf returns the package a caller code logs under. An unlisted code is
+// its own package, lower-cased.
+func PackageOf(caller string) string {
+	caller = strings.TrimSpace(caller)
+	if pkg, ok := callerPackages[caller]; ok {
+		return pkg
+	}
+	return strings.ToLower(caller)
+}
+
+// Packages returns every known package name, sorted: the mapped ones, the
+// debug scopes and any with a level set, for the log page's dropdowns.
+func Packages() []string {
+	seen := make(map[string]struct{})
+	for _, pkg := range callerPackages {
+		seen[pkg] = struct{}{}
+	}
+	for _, pkg := range debugScopes {
+		seen[pkg] = struct{}{}
+	}
+	for pkg := range Levels() {
+		seen[pkg] = struct{}{}
+	}
+	out := make([]string, 0, len(seen))
+	for pkg := range seen {
+		out = append(out, pkg)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// --- handlers ----------------------------------------------------------------
+
+// teeHandler hands every record to each of its handlers.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range t {
+		if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+			first = err
 		}
-		if out, err := json.Marshal(logMessage); err != nil {
-			Error(str.CLog, str.ELogFail, err.Error(), logMessage)
-		} else {
-			fmt.Printf("%s\n", out)
+	}
+	return first
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// jsonAttr shapes the deploy JSON to the keys log ingestion has always read:
+// time in unix milliseconds, severity, caller, message. The fields (pkg, room,
+// game, uid, account) ride alongside as plain keys.
+func jsonAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		return slog.Int64("time", a.Value.Time().UnixMilli())
+	case slog.LevelKey:
+		return slog.String("severity", severity(a.Value.Any().(slog.Level)))
+	case slog.MessageKey:
+		return slog.Attr{Key: "message", Value: a.Value}
+	}
+	return a
+}
+
+// severity is the JSON severity string. Errors have always shipped as "warn",
+// and alerting matches on it.
+func severity(level slog.Level) string {
+	if level >= slog.LevelError {
+		return "warn"
+	}
+	return LevelName(level)
+}
+
+// lineLog is where lineHandler prints. It is its own logger rather than the
+// standard one because slog.SetDefault points the standard logger at this
+// handler: printing through it would come straight back here.
+var lineLog = log.New(os.Stderr, "", log.LstdFlags|log.Lmicroseconds)
+
+// lineHandler prints the local dev format: the old "INFO  [Room] message"
+// line, with any identifying fields appended as key=value.
+type lineHandler struct {
+	attrs []slog.Attr
+}
+
+func (h *lineHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *lineHandler) Handle(_ context.Context, r slog.Record) error {
+	format := str.InfoFormat
+	switch {
+	case r.Level < slog.LevelInfo:
+		format = str.DebugFormat
+	case r.Level >= slog.LevelError:
+		format = str.ErrorFormat
+	}
+
+	caller := ""
+	var fields []string
+	each := func(a slog.Attr) bool {
+		switch a.Key {
+		case keyCaller:
+			caller = a.Value.String()
+		case keyPkg:
+		default:
+			fields = append(fields, a.Key+"="+a.Value.String())
 		}
+		return true
 	}
+	for _, a := range h.attrs {
+		each(a)
+	}
+	r.Attrs(each)
+
+	message := r.Message
+	if len(fields) > 0 {
+		message += " " + strings.Join(fields, " ")
+	}
+	lineLog.Printf(format, caller, message)
+	return nil
 }
+
+func (h *lineHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &lineHandler{attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h *lineHandler) WithGroup(string) slog.Handler { return h }
@@ -0,0 +1,216 @@
+package util
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// withRing swaps in a fresh ring of the given size for one test.
+func withRing(t *testing.T, size int) {
+	t.Helper()
+	saved := ring
+	ring = newLogRing(size)
+	t.Cleanup(func() { ring = saved })
+}
+
+// TestPackageLevels: a package is at info until set, setting it back to info
+// drops the override, and a --debug value turns each named package to debug.
+func TestPackageLevels(t *testing.T) {
+	t.Cleanup(func() {
+		for pkg := range Levels() {
+			SetLevel(pkg, slog.LevelInfo)
+		}
+	})
+
+	if got := Level("room"); got != slog.LevelInfo {
+		t.Fatalf("default level = %v, want info", got)
+	}
+	EnableDebug("room, engine,,")
+	if Level("room") != slog.LevelDebug || Level("engine") != slog.LevelDebug {
+		t.Fatalf("EnableDebug left levels %v", Levels())
+	}
+	SetLevel("room", slog.LevelInfo)
+	if _, ok := Levels()["room"]; ok {
+		t.Error("resetting to info kept an override")
+	}
+	if len(Levels()) != 1 {
+		t.Errorf("levels = %v, want only engine", Levels())
+	}
+}
+
+// TestDebugFlagFollowsLevel: a DebugFlag line is written only while its
+// package is at debug, and carries the logger's fields into the ring.
+func TestDebugFlagFollowsLevel(t *testing.T) {
+	withRing(t, 16)
+	t.Cleanup(func() { SetLevel("room", slog.LevelInfo) })
+
+	log := With(RoomID("r1"), GameID("g1"))
+	log.DebugFlag("room", callerForTest, "dropped")
+	SetLevel("room", slog.LevelDebug)
+	log.DebugFlag("room", callerForTest, "kept %d", 1)
+
+	got := QueryLog(LogQuery{MinLevel: slog.LevelDebug})
+	if len(got) != 1 {
+		t.Fatalf("got %d lines, want 1: %+v", len(got), got)
+	}
+	e := got[0]
+	if e.Message != "kept 1" || e.Package != "room" || e.Room != "r1" || e.Game != "g1" || e.Level != slog.LevelDebug {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+// callerForTest is a caller code with no package mapping of its own.
+const callerForTest = "Test"
+
+// TestQueryLogFilters: results come newest first and each filter narrows them.
+func TestQueryLogFilters(t *testing.T) {
+	withRing(t, 8)
+
+	base := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	for i, e := range []LogEntry{
+		{Level: slog.LevelInfo, Package: "room", Room: "a", Message: "one"},
+		{Level: slog.LevelError, Package: "room", Room: "b", Message: "two"},
+		{Level: slog.LevelInfo, Package: "ws", Room: "a", Message: "three"},
+		{Level: slog.LevelDebug, Package: "room", Room: "a", Message: "four"},
+	} {
+		e.Time = base.Add(time.Duration(i) * time.Minute)
+		ring.add(e)
+	}
+
+	messages := func(q LogQuery) []string {
+		var out []string
+		for _, e := range QueryLog(q) {
+			out = append(out, e.Message)
+		}
+		return out
+	}
+	for name, tc := range map[string]struct {
+		q    LogQuery
+		want []string
+	}{
+		"info and up": {LogQuery{MinLevel: slog.LevelInfo}, []string{"three", "two", "one"}},
+		"errors":      {LogQuery{MinLevel: slog.LevelError}, []string{"two"}},
+		"package":     {LogQuery{MinLevel: slog.LevelDebug, Package: "room"}, []string{"four", "two", "one"}},
+		"room":        {LogQuery{MinLevel: slog.LevelDebug, Room: "a"}, []string{"four", "three", "one"}},
+		"range": {LogQuery{MinLevel: slog.LevelDebug, Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)},
+			[]string{"three", "two"}},
+		"limit": {LogQuery{MinLevel: slog.LevelDebug, Limit: 2}, []string{"four", "three"}},
+	} {
+		got := messages(tc.q)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: got %v, want %v", name, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+// TestLogSpill: entries the ring evicts land in the spill file, and a query
+// that reaches past the ring finds them there, still newest first.
+func TestLogSpill(t *testing.T) {
+	withRing(t, 2)
+	dir := t.TempDir()
+	if err := StartLogSpill(dir); err != nil {
+		t.Fatalf("StartLogSpill: %v", err)
+	}
+	t.Cleanup(func() { spillDir = "" })
+
+	base := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	for i, msg := range []string{"a", "b", "c", "d"} {
+		ring.add(LogEntry{Time: base.Add(time.Duration(i) * time.Second), Level: slog.LevelInfo, Message: msg})
+	}
+
+	// the writer is asynchronous; wait for both evictions to reach the file
+	path := filepath.Join(dir, spillFile)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if got := readSpill(path, LogQuery{}); len(got) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			b, _ := os.ReadFile(path)
+			t.Fatalf("spill file never held both evicted entries:\n%s", b)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	got := QueryLog(LogQuery{})
+	want := []string{"d", "c", "b", "a"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i, e := range got {
+		if e.Message != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, e.Message, want[i])
+		}
+	}
+
+	// a search starting inside the ring does not read the disk
+	if got := QueryLog(LogQuery{Since: base.Add(2 * time.Second)}); len(got) != 2 {
+		t.Errorf("in-ring search returned %d entries, want 2", len(got))
+	}
+}
+
+// TestLogSpillReopenFailure: a spill file that cannot be reopened after a
+// rotation turns spill off, so the ring stops handing entries to a writer
+// that has gone.
+func TestLogSpillReopenFailure(t *testing.T) {
+	withRing(t, 2)
+	dir := t.TempDir()
+	path := filepath.Join(dir, spillFile)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(chan LogEntry, 1)
+	spillDir = dir
+	ring.spill = entries
+	t.Cleanup(func() { spillDir = "" })
+
+	// the directory is gone, so the file cannot be created again once the
+	// next entry rotates it away
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		spillLoop(f, path, spillMaxBytes, entries)
+		close(done)
+	}()
+	entries <- LogEntry{Message: "rotates"}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("spill writer kept running without a file")
+	}
+	if LogSpilling() {
+		t.Error("spill still reported on")
+	}
+	ring.mu.Lock()
+	spill := ring.spill
+	ring.mu.Unlock()
+	if spill != nil {
+		t.Error("the ring still hands entries to the spill writer")
+	}
+}
+
+// TestPackageOf: caller codes map to the package their DebugFlag lines use.
+func TestPackageOf(t *testing.T) {
+	for caller, want := range map[string]string{
+		"Room": "room", "HMov": "room", "Engi": "engine", "Clck": "clock",
+		"WS": "ws", " WSCm ": "ws", "Unmapped": "unmapped",
+	} {
+		if got := PackageOf(caller); got != want {
+			t.Errorf("PackageOf(%q) = %q, want %q", caller, got, want)
+		}
+	}
+}
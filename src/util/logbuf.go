@@ -0,0 +1,320 @@
+package util
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// The log ring. Every record written through this package is also kept in a
+// fixed-size in-memory ring, which is what /system/log searches: an operator
+// chasing a report ("room abc123 froze a minute ago") should not need shell
+// access and a log pipeline to find the lines.
+//
+// The ring holds the most recent LogRingSize records. With a spill directory
+// configured (LOG_SPILL_DIR, see StartLogSpill) the records it evicts are
+// appended to a JSONL file there instead of being dropped, so a query reaching
+// back past the ring keeps going on disk.
+
+// LogRingSize is the number of records kept in memory.
+const LogRingSize = 10000
+
+// spillMaxBytes is the size at which the spill file rotates to a single ".1"
+// backup. Two files of this size bound the disk the spill can use.
+const spillMaxBytes = 64 << 20
+
+// spillFile is the spill file's name inside the spill directory.
+const spillFile = "lio-log.jsonl"
+
+// LogEntry is one record as the ring and spill file keep it.
+type LogEntry struct {
+	Time    time.Time  `json:"time"`
+	Level   slog.Level `json:"level"`
+	Package string     `json:"pkg"`
+	Caller  string     `json:"caller"`
+	Message string     `json:"message"`
+	Room    string     `json:"room,omitempty"`
+	Game    string     `json:"game,omitempty"`
+	UID     string     `json:"uid,omitempty"`
+	Account int64      `json:"account,omitempty"`
+}
+
+// LogQuery selects entries from QueryLog. Zero fields match everything.
+type LogQuery struct {
+	// MinLevel is the lowest level returned.
+	MinLevel slog.Level
+	// Package, Room match exactly when set.
+	Package string
+	Room    string
+	// Since and Until bound the entry time, inclusive.
+	Since time.Time
+	Until time.Time
+	// Limit caps the result; zero means 200.
+	Limit int
+}
+
+func (q LogQuery) match(e LogEntry) bool {
+	switch {
+	case e.Level < q.MinLevel:
+		return false
+	case q.Package != "" && e.Package != q.Package:
+		return false
+	case q.Room != "" && e.Room != q.Room:
+		return false
+	case !q.Since.IsZero() && e.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && e.Time.After(q.Until):
+		return false
+	}
+	return true
+}
+
+// logRing is a fixed-capacity ring of entries.
+type logRing struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	next    int // index the next entry is written to
+	full    bool
+	spill   chan<- LogEntry // nil until StartLogSpill
+}
+
+var ring = newLogRing(LogRingSize)
+
+func newLogRing(size int) *logRing {
+	return &logRing{entries: make([]LogEntry, size)}
+}
+
+// add stores e, handing the entry it displaces to the spill writer if there
+// is one. The hand-off never blocks: a logging call must not wait on a disk,
+// so under a burst the spill drops entries rather than stall the room that
+// wrote them.
+func (r *logRing) add(e LogEntry) {
+	r.mu.Lock()
+	evicted, spill := r.entries[r.next], r.spill
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	wasFull := r.full
+	if r.next == 0 {
+		r.full = true
+	}
+	r.mu.Unlock()
+
+	if wasFull && spill != nil {
+		select {
+		case spill <- evicted:
+		default:
+		}
+	}
+}
+
+// query returns matching entries newest first, at most limit of them, and
+// the time of the oldest entry held (zero when empty).
+func (r *logRing) query(q LogQuery, limit int) ([]LogEntry, time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.next
+	if r.full {
+		n = len(r.entries)
+	}
+	var oldest time.Time
+	if n > 0 {
+		oldest = r.entries[(r.next-n+len(r.entries))%len(r.entries)].Time
+	}
+
+	var out []LogEntry
+	for i := 1; i <= n && len(out) < limit; i++ {
+		e := r.entries[(r.next-i+len(r.entries))%len(r.entries)]
+		if q.match(e) {
+			out = append(out, e)
+		}
+	}
+	return out, oldest
+}
+
+// ringHandler is the slog handler that feeds the ring.
+type ringHandler struct {
+	attrs []slog.Attr
+}
+
+func (h ringHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h ringHandler) Handle(_ context.Context, r slog.Record) error {
+	e := LogEntry{Time: r.Time, Level: r.Level, Message: r.Message}
+	set := func(a slog.Attr) bool {
+		switch a.Key {
+		case keyPkg:
+			e.Package = a.Value.String()
+		case keyCaller:
+			e.Caller = a.Value.String()
+		case keyRoom:
+			e.Room = a.Value.String()
+		case keyGame:
+			e.Game = a.Value.String()
+		case keyUID:
+			e.UID = a.Value.String()
+		case keyAccount:
+			e.Account = a.Value.Int64()
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		set(a)
+	}
+	r.Attrs(set)
+	ring.add(e)
+	return nil
+}
+
+func (h ringHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ringHandler{attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h ringHandler) WithGroup(string) slog.Handler { return h }
+
+// QueryLog returns the entries matching q, newest first. It reads the ring,
+// then — when the ring did not fill the limit, a spill directory is
+// configured and q reaches back past the ring's oldest entry — the spill
+// files. Entries still in flight to the spill writer are not visible to
+// either; at most a few are, and only under a burst.
+func QueryLog(q LogQuery) []LogEntry {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 200
+	}
+
+	out, oldest := ring.query(q, limit)
+	dir := spillDirectory()
+	if len(out) >= limit || dir == "" {
+		return out
+	}
+	if !q.Since.IsZero() && !oldest.IsZero() && !q.Since.Before(oldest) {
+		return out
+	}
+
+	// the current file is newer than its backup; each is in time order
+	for _, name := range []string{spillFile, spillFile + ".1"} {
+		matched := readSpill(filepath.Join(dir, name), q)
+		for i := len(matched) - 1; i >= 0 && len(out) < limit; i-- {
+			out = append(out, matched[i])
+		}
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out
+}
+
+// readSpill returns the matching entries of one spill file, oldest first. A
+// missing file or an unreadable line is skipped: the spill is a convenience
+// for the log page, not a record anything depends on.
+func readSpill(path string, q LogQuery) []LogEntry {
+	spillMu.Lock()
+	defer spillMu.Unlock()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var out []LogEntry
+	scan := bufio.NewScanner(f)
+	scan.Buffer(make([]byte, 64<<10), 1<<20)
+	for scan.Scan() {
+		var e LogEntry
+		if json.Unmarshal(scan.Bytes(), &e) == nil && q.match(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+var (
+	// spillDir is the configured spill directory, empty when spill is off.
+	// It is guarded by spillMu.
+	spillDir string
+	// spillMu guards the spill files between the writer and readSpill, so a
+	// query never reads across a rotation.
+	spillMu sync.Mutex
+)
+
+// StartLogSpill turns on spilling of evicted ring entries to dir, creating it
+// if needed. It is called once at boot when LOG_SPILL_DIR is set; an error
+// leaves spill off and the ring working alone.
+func StartLogSpill(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, spillFile)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	entries := make(chan LogEntry, 1024)
+	spillMu.Lock()
+	spillDir = dir
+	spillMu.Unlock()
+	ring.mu.Lock()
+	ring.spill = entries
+	ring.mu.Unlock()
+
+	go spillLoop(f, path, info.Size(), entries)
+	return nil
+}
+
+// spillLoop appends each evicted entry to the spill file as a JSON line,
+// rotating the file to ".1" once it passes spillMaxBytes. If the file cannot
+// be reopened after a rotation, spill turns itself off and says so once.
+func spillLoop(f *os.File, path string, size int64, entries <-chan LogEntry) {
+	for e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		line = append(line, '\n')
+
+		spillMu.Lock()
+		if size+int64(len(line)) > spillMaxBytes {
+			_ = f.Close()
+			_ = os.Rename(path, path+".1")
+			if f, err = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644); err != nil {
+				spillDir = ""
+				spillMu.Unlock()
+				ring.mu.Lock()
+				ring.spill = nil
+				ring.mu.Unlock()
+				// straight to stderr: logging this would only feed the ring
+				lineLog.Printf("log spill off, cannot reopen %s: %v", path, err)
+				return
+			}
+			size = 0
+		}
+		n, _ := f.Write(line)
+		size += int64(n)
+		spillMu.Unlock()
+	}
+}
+
+// LogSpilling reports whether evicted entries are kept on disk.
+func LogSpilling() bool {
+	return spillDirectory() != ""
+}
+
+// spillDirectory returns spillDir, empty when spill is off.
+func spillDirectory() string {
+	spillMu.Lock()
+	defer spillMu.Unlock()
+	return spillDir
+}
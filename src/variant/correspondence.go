@@ -0,0 +1,49 @@
+package variant
+
+import (
+	"time"
+
+	"github.com/dechristopher/lio/clock"
+)
+
+// OneDayCorrespondence is the one day per move correspondence variant
+var OneDayCorrespondence = Variant{
+	Name:     "1 day",
+	HTMLName: "one-day-correspondence",
+	Group:    CorrespondenceGroup,
+	Control:  correspondenceTC(1),
+	Days:     1,
+}
+
+// ThreeDayCorrespondence is the three days per move correspondence variant
+var ThreeDayCorrespondence = Variant{
+	Name:     "3 days",
+	HTMLName: "three-day-correspondence",
+	Group:    CorrespondenceGroup,
+	Control:  correspondenceTC(3),
+	Days:     3,
+}
+
+// SevenDayCorrespondence is the seven days per move correspondence variant
+var SevenDayCorrespondence = Variant{
+	Name:     "7 days",
+	HTMLName: "seven-day-correspondence",
+	Group:    CorrespondenceGroup,
+	Control:  correspondenceTC(7),
+	Days:     7,
+}
+
+// PerMove is a correspondence variant's allowance for one move, or zero for a
+// realtime variant.
+func (v Variant) PerMove() time.Duration {
+	return time.Duration(v.Days) * 24 * time.Hour
+}
+
+// correspondenceTC restates a days-per-move allowance as a time control, for
+// the views that print one. It never drives a clock.
+func correspondenceTC(days int) clock.TimeControl {
+	return clock.TimeControl{
+		Time:      clock.ToCTime(time.Duration(days) * 24 * time.Hour),
+		Increment: clock.ToCTime(time.Second * 0),
+	}
+}
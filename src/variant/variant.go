@@ -26,6 +26,12 @@ type Variant struct {
 	// LockColors keeps each player on the same side across rematches. By default
 	// subsequent games swap sides; a variant sets this to opt out.
 	LockColors bool `json:"lock_colors,omitempty"`
+	// Days is the per-move allowance of a correspondence variant: each side has
+	// this many days for every move, and the allowance resets when they move.
+	// No room actor or clock runs such a game (see package correspondence), so
+	// Control only restates the allowance for display. Zero for every realtime
+	// variant.
+	Days int `json:"days,omitempty"`
 }
 
 // Group represents a collection of similar variants
@@ -59,4 +65,6 @@ const (
 	DeployGroup Group = "deploy"
 	// UnlimitedGroup collects the untimed casual variants.
 	UnlimitedGroup Group = "unlimited"
+	// CorrespondenceGroup collects the days-per-move variants.
+	CorrespondenceGroup Group = "correspondence"
 )
@@ -85,7 +85,7 @@ func profilePopover(username string, t title.Title) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<div id=\"ratingsSummary\" class=\"mt-2\" data-loaded=\"false\"></div><div class=\"mt-3 flex flex-col gap-1.5 border-t border-line pt-3\"><details class=\"account-section\"><summary class=\"account-summary\">Change password</summary><form id=\"passwordForm\" class=\"account-body flex flex-col gap-2\" novalidate><label class=\"auth-label\">Current password <input class=\"auth-input\" name=\"current\" type=\"password\" autocomplete=\"current-password\" required></label> <label class=\"auth-label\">New password <input class=\"auth-input\" name=\"new\" type=\"password\" autocomplete=\"new-password\" required minlength=\"8\" maxlength=\"128\"></label> <label class=\"auth-label\">Confirm new password <input class=\"auth-input\" name=\"confirm\" type=\"password\" autocomplete=\"new-password\" required disabled></label><p class=\"auth-error hidden\" data-auth-error role=\"alert\"></p><p class=\"auth-ok hidden\" data-auth-ok role=\"status\">Password changed. Other sessions were signed out.</p><button type=\"submit\" class=\"btn btn-primary w-full justify-center py-1.5 text-sm\" disabled>Update password</button></form></details> <details id=\"sessionsDetails\" class=\"account-section\"><summary class=\"account-summary\">Active sessions</summary><div id=\"sessionsBody\" class=\"account-body\" data-loaded=\"false\"><p class=\"auth-hint\">Loading…</p></div></details> <button type=\"button\" id=\"securityButton\" class=\"account-section account-summary w-full\">Account security</button></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<div id=\"ratingsSummary\" class=\"mt-2\" data-loaded=\"false\"></div><div class=\"mt-3 flex flex-col gap-1.5 border-t border-line pt-3\"><details class=\"account-section\"><summary class=\"account-summary\">Change password</summary><form id=\"passwordForm\" class=\"account-body flex flex-col gap-2\" novalidate><label class=\"auth-label\">Current password <input class=\"auth-input\" name=\"current\" type=\"password\" autocomplete=\"current-password\" required></label> <label class=\"auth-label\">New password <input class=\"auth-input\" name=\"new\" type=\"password\" autocomplete=\"new-password\" required minlength=\"8\" maxlength=\"128\"></label> <label class=\"auth-label\">Confirm new password <input class=\"auth-input\" name=\"confirm\" type=\"password\" autocomplete=\"new-password\" required disabled></label><p class=\"auth-error hidden\" data-auth-error role=\"alert\"></p><p class=\"auth-ok hidden\" data-auth-ok role=\"status\">Password changed. Other sessions were signed out.</p><button type=\"submit\" class=\"btn btn-primary w-full justify-center py-1.5 text-sm\" disabled>Update password</button></form></details> <details id=\"sessionsDetails\" class=\"account-section\"><summary class=\"account-summary\">Active sessions</summary><div id=\"sessionsBody\" class=\"account-body\" data-loaded=\"false\"><p class=\"auth-hint\">Loading…</p></div></details> <button type=\"button\" id=\"securityButton\" class=\"account-section account-summary w-full\">Account security</button> <a href=\"/account/email\" class=\"account-section account-summary w-full no-underline\">Email</a> <a href=\"/account/tokens\" class=\"account-section account-summary w-full no-underline\">API tokens</a> <a href=\"/account/blocks\" class=\"account-section account-summary w-full no-underline\">Blocked players</a><a href=\"/api/auth/export\" class=\"account-section account-summary w-full no-underline\" download>Download your data</a> <button type=\"button\" id=\"deleteAccountButton\" class=\"account-section account-summary w-full text-loss\">Delete account</button></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -232,13 +232,69 @@ func editProfileModal(username string) templ.Component {
 		var templ_7745c5c3_Var5 string
 		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.ResolveAttributeValue(username)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/account.templ`, Line: 142, Col: 135}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/account.templ`, Line: 151, Col: 135}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var5)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "\" minlength=\"3\" maxlength=\"20\"><p class=\"auth-hint\" data-username-hint>You can change your username once, and only to change its capitalization.</p><p class=\"auth-error hidden\" data-auth-error role=\"alert\"></p><p class=\"auth-ok hidden\" data-auth-ok role=\"status\">Username updated.</p><button type=\"submit\" class=\"btn btn-primary w-full justify-center py-1.5 text-sm\" data-username-submit>Save username</button></form></section><section class=\"border-t border-line pt-4\"><h3 class=\"text-xs font-semibold uppercase tracking-wide text-fg-muted\">Email address</h3><form id=\"emailForm\" class=\"mt-2 flex flex-col gap-2\" novalidate><label class=\"auth-label\"><span class=\"font-normal text-fg-subtle\">(optional)</span> <input class=\"auth-input\" name=\"email\" type=\"email\" autocomplete=\"email\" placeholder=\"you@example.com\" maxlength=\"254\"></label><p class=\"auth-hint\">Used only for account recovery. There is no email delivery yet — set it now so recovery works once it lands, or leave it blank to remove it.</p><p class=\"auth-error hidden\" data-auth-error role=\"alert\"></p><p class=\"auth-ok hidden\" data-auth-ok role=\"status\">Email saved.</p><button type=\"submit\" class=\"btn btn-primary w-full justify-center py-1.5 text-sm\">Save email</button></form></section></div></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "\" minlength=\"3\" maxlength=\"20\"><p class=\"auth-hint\" data-username-hint>You can change your username once, and only to change its capitalization.</p><p class=\"auth-error hidden\" data-auth-error role=\"alert\"></p><p class=\"auth-ok hidden\" data-auth-ok role=\"status\">Username updated.</p><button type=\"submit\" class=\"btn btn-primary w-full justify-center py-1.5 text-sm\" data-username-submit>Save username</button></form></section><section class=\"border-t border-line pt-4\"><h3 class=\"text-xs font-semibold uppercase tracking-wide text-fg-muted\">Email address</h3><form id=\"emailForm\" class=\"mt-2 flex flex-col gap-2\" novalidate><label class=\"auth-label\"><span class=\"font-normal text-fg-subtle\">(optional)</span> <input class=\"auth-input\" name=\"email\" type=\"email\" autocomplete=\"email\" placeholder=\"you@example.com\" maxlength=\"254\"></label><p class=\"auth-hint\">Used to reset a forgotten password and, if you turn it on, for a weekly digest. A new address is sent a link to confirm it. Leave it blank to remove it.</p><p class=\"auth-error hidden\" data-auth-error role=\"alert\"></p><p class=\"auth-ok hidden\" data-auth-ok role=\"status\">Email saved.</p><button type=\"submit\" class=\"btn btn-primary w-full justify-center py-1.5 text-sm\">Save email</button></form></section></div></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// deleteAccountModal is the account deletion dialog (package privacy), opened
+// from the profile popover's "Delete account" button. The form asks for
+// everything the server will: the username typed out, the password, and —
+// which lio-auth.js only enforces by the server's answer, since the page does
+// not know — a second factor when the account has one. A modal so an outside
+// click cannot dismiss it halfway through a passkey prompt.
+func deleteAccountModal(username string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var6 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var6 == nil {
+			templ_7745c5c3_Var6 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<div id=\"modalDeleteAccount\" class=\"modal-shade\"><div class=\"modal card\"><button type=\"button\" class=\"modal-close\" aria-label=\"Close\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = iconClose().Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "</button><h2>Delete account</h2><form id=\"deleteAccountForm\" class=\"mt-1 flex flex-col gap-2 text-left\" novalidate><p class=\"auth-hint\">This deletes your account, your ratings, follows, preferences and notifications, and cannot be undone. Your games stay in the archive, with your seat shown as Anonymous. <a href=\"/api/auth/export\" download>Download your data</a> first if you want a copy.</p><label class=\"auth-label\">Type <strong>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var7 string
+		templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(username)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/account.templ`, Line: 194, Col: 28}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "</strong> to confirm <input class=\"auth-input\" name=\"confirm\" type=\"text\" autocomplete=\"off\" spellcheck=\"false\" autocapitalize=\"off\" required></label> <label class=\"auth-label\">Password <input class=\"auth-input\" name=\"password\" type=\"password\" autocomplete=\"current-password\" required></label> <label class=\"auth-label\">Two-factor code <input class=\"auth-input\" name=\"code\" type=\"text\" autocomplete=\"one-time-code\" spellcheck=\"false\" autocapitalize=\"off\"></label><p class=\"auth-hint\">Only if you use two-factor authentication: a code from your app, or a recovery code. You can use a passkey instead.</p><p class=\"auth-error hidden\" data-auth-error role=\"alert\"></p><button type=\"submit\" class=\"btn btn-ghost w-full justify-center py-1.5 text-sm text-loss\">Delete my account</button> <button type=\"button\" class=\"btn btn-ghost w-full justify-center py-1.5 text-sm\" data-delete-passkey>Delete with a passkey</button></form></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -265,87 +321,87 @@ func SessionList(sessions []SessionView) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var6 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var6 == nil {
-			templ_7745c5c3_Var6 = templ.NopComponent
+		templ_7745c5c3_Var8 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var8 == nil {
+			templ_7745c5c3_Var8 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		if len(sessions) == 0 {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<p class=\"auth-hint\">No active sessions.</p>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "<p class=\"auth-hint\">No active sessions.</p>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		} else {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "<ul class=\"session-list\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "<ul class=\"session-list\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			for _, s := range sessions {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "<li class=\"session-row\"><div class=\"session-meta\"><span class=\"session-device\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "<li class=\"session-row\"><div class=\"session-meta\"><span class=\"session-device\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				var templ_7745c5c3_Var7 string
-				templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(s.Device)
+				var templ_7745c5c3_Var9 string
+				templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(s.Device)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/account.templ`, Line: 179, Col: 17}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/account.templ`, Line: 226, Col: 17}
 				}
-				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, " ")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, " ")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				if s.Current {
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "<span class=\"session-current\">This device</span>")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "<span class=\"session-current\">This device</span>")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "</span> <span class=\"session-seen\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "</span> <span class=\"session-seen\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				var templ_7745c5c3_Var8 string
-				templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(s.LastSeen)
+				var templ_7745c5c3_Var10 string
+				templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(s.LastSeen)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/account.templ`, Line: 184, Col: 45}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/account.templ`, Line: 231, Col: 45}
 				}
-				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "</span></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "</span></div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				if !s.Current {
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "<button type=\"button\" class=\"session-revoke\" data-session-id=\"")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "<button type=\"button\" class=\"session-revoke\" data-session-id=\"")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					var templ_7745c5c3_Var9 string
-					templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.ResolveAttributeValue(strconv.FormatInt(s.ID, 10))
+					var templ_7745c5c3_Var11 string
+					templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.ResolveAttributeValue(strconv.FormatInt(s.ID, 10))
 					if templ_7745c5c3_Err != nil {
-						return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/account.templ`, Line: 187, Col: 96}
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/account.templ`, Line: 234, Col: 96}
 					}
-					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var9)
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var11)
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "\" title=\"Revoke this session\">Revoke</button>")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "\" title=\"Revoke this session\">Revoke</button>")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "</li>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "</li>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "</ul>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "</ul>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
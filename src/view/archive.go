@@ -5,6 +5,7 @@ import (
 	"golang.org/x/text/language"
 
 	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/pools"
 	"github.com/dechristopher/lio/title"
 	"github.com/dechristopher/lio/www/ws/proto"
 )
@@ -41,6 +42,34 @@ type ArchiveData struct {
 	// (never fetched from the object store). The copy button copies it verbatim,
 	// so a copied PGN is byte-for-byte what was archived.
 	PGN string `json:"pgn,omitempty"`
+
+	// Analysis is the game's post-game review (package analysis), omitted when
+	// none was ever requested. A finished review's evals fill Board's ev.
+	Analysis *ArchiveAnalysis `json:"an,omitempty"`
+}
+
+// ArchiveAnalysis is a game's post-game review as the archive page shows it,
+// and the response of GET /api/game/:uuid/analysis. State is "queued",
+// "done" or "failed"; the rest is filled once done.
+type ArchiveAnalysis struct {
+	State string `json:"s"`
+	Depth int    `json:"d,omitempty"`
+	// Judgements names each ply's verdict (analysis.Judgement), indexed ply-1.
+	Judgements []string `json:"j,omitempty"`
+	// Evals are the review's per-ply evals, white-positive centipawns.
+	Evals []*int16      `json:"ev,omitempty"`
+	White *AnalysisSide `json:"w,omitempty"`
+	Black *AnalysisSide `json:"b,omitempty"`
+}
+
+// AnalysisSide is one side's score in a reviewed game.
+type AnalysisSide struct {
+	Accuracy     float32 `json:"acc"`
+	ACPL         int16   `json:"acpl"`
+	Inaccuracies int     `json:"i"`
+	Mistakes     int     `json:"m"`
+	Blunders     int     `json:"b"`
+	MissedMates  int     `json:"mm"`
 }
 
 // ArchiveGameData is the response of the archived-game JSON endpoint
@@ -160,6 +189,26 @@ func archiveModeLabel(m ArchiveModel) string {
 	return "Competitive"
 }
 
+// explorerCategory is one option of the opening explorer's rating filter
+type explorerCategory struct {
+	Value string
+	Label string
+}
+
+// explorerCategories lists the human rating categories the explorer can be
+// narrowed to, in the create-game order. Bot pools are left out: the explorer
+// is for what people play, and bot games are a filter nobody asked for.
+func explorerCategories() []explorerCategory {
+	out := make([]explorerCategory, 0, 2*len(pools.CreateControls))
+	for _, ctrl := range pools.CreateControls {
+		out = append(out, explorerCategory{Value: ctrl.Deploy.HTMLName, Label: ctrl.Label})
+	}
+	for _, ctrl := range pools.CreateControls {
+		out = append(out, explorerCategory{Value: ctrl.Classic.HTMLName, Label: ctrl.Label + " Classic"})
+	}
+	return out
+}
+
 // ArchiveMeta builds page metadata for an archived room/game permalink. The
 // OG card reuses the room-card route (which falls back to the archive too) so
 // shared links preview the final position.
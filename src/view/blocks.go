@@ -0,0 +1,26 @@
+package view
+
+import (
+	"time"
+
+	"github.com/dechristopher/lio/title"
+)
+
+// BlocksModel is /account/blocks: the accounts the signed-in account has
+// blocked, each with the form that lifts the block.
+type BlocksModel struct {
+	Blocks []BlockedView
+	Notice string
+}
+
+// BlockedView is one row of the block list.
+type BlockedView struct {
+	Username string
+	Title    title.Title
+	Since    time.Time
+}
+
+// blockedSinceLabel says when a block was made.
+func blockedSinceLabel(t time.Time) string {
+	return "blocked " + t.UTC().Format("2 Jan 2006")
+}
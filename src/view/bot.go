@@ -0,0 +1,35 @@
+package view
+
+import "time"
+
+// BotPageModel is /bot: what bot accounts are, and — for the owner of one —
+// the token its program authenticates with.
+type BotPageModel struct {
+	// Available is false without accounts (auth disabled), where there is no
+	// account to flag and nothing below applies.
+	Available bool
+	LoggedIn  bool
+	// IsBot is true when the viewer's account is a bot.
+	IsBot bool
+	// TokenIssued is when the current token was issued; zero when the account
+	// has never had one.
+	TokenIssued time.Time
+	// Token is a freshly issued token, set only on the response to the issuing
+	// post. It is never stored in plaintext, so this is the one time it is seen.
+	Token  string
+	Notice string
+}
+
+// The two socket frames /bot quotes: asking for the state, and a move.
+const (
+	botStateFrame = `{"t":"m","d":{"a":0}}`
+	botMoveFrame  = `{"t":"m","d":{"u":"c2c3","a":1}}`
+)
+
+// botTokenLabel describes the account's current token.
+func botTokenLabel(issued time.Time) string {
+	if issued.IsZero() {
+		return "No token has been issued yet."
+	}
+	return "The current token was issued " + issued.UTC().Format("2 Jan 2006 15:04") + " UTC."
+}
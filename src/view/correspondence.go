@@ -0,0 +1,148 @@
+package view
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dechristopher/lio/correspondence"
+)
+
+// CorrespondenceItem is one game as the /correspondence list shows it. The
+// handler resolves everything to display strings from the viewer's side.
+type CorrespondenceItem struct {
+	ID      string
+	Control string // "3 days"
+	// Opponent is the other player's username, "" while the seat is open.
+	Opponent string
+	// Color is the viewer's side, "white" or "black"; for somebody else's
+	// seek it is the side the viewer would take.
+	Color string
+	Rated bool
+	// Deadline is when the side to move runs out of time, zero unless the game
+	// is in progress.
+	Deadline time.Time
+	// Result is a finished game's result line.
+	Result string
+}
+
+// CorrespondenceList is the /correspondence page.
+type CorrespondenceList struct {
+	// Available is false without Postgres, where there are no correspondence
+	// games at all.
+	Available bool
+	LoggedIn  bool
+	// YourMove and Waiting split the viewer's games in progress by whose move
+	// it is.
+	YourMove []CorrespondenceItem
+	Waiting  []CorrespondenceItem
+	// Challenges are direct challenges to the viewer; Sent are the viewer's
+	// own seeks and challenges nobody has accepted yet.
+	Challenges []CorrespondenceItem
+	Sent       []CorrespondenceItem
+	// Seeks are everybody else's open seeks.
+	Seeks    []CorrespondenceItem
+	Finished []CorrespondenceItem
+	// Controls are the days-per-move choices the create form offers.
+	Controls []TournamentControl
+	Notice   string
+}
+
+// CorrespondenceModel is one game's page.
+type CorrespondenceModel struct {
+	CorrespondenceItem
+	// White and Black are the players' usernames, "" for an open seat.
+	White string
+	Black string
+	State correspondence.State
+	// The viewer's standing in the game, which picks the controls shown.
+	LoggedIn   bool
+	CanJoin    bool
+	CanCancel  bool
+	CanDecline bool
+	Notice     string
+}
+
+// correspondenceURL is a game's page.
+func correspondenceURL(id string) string {
+	return correspondence.URL(id)
+}
+
+// correspondenceArchiveURL is a finished game's permanent archive page.
+func correspondenceArchiveURL(id string) string {
+	return "/" + id + "/1"
+}
+
+// correspondenceTimeLeft says how long the side to move has left, to the
+// hour: "2 days 5 hours left", "40 minutes left".
+func correspondenceTimeLeft(deadline time.Time) string {
+	left := time.Until(deadline)
+	if left <= 0 {
+		return "out of time"
+	}
+	days := int64(left / (24 * time.Hour))
+	hours := int64(left % (24 * time.Hour) / time.Hour)
+	switch {
+	case days > 0 && hours > 0:
+		return plural(days, "day", "days") + " " + plural(hours, "hour", "hours") + " left"
+	case days > 0:
+		return plural(days, "day", "days") + " left"
+	case hours > 0:
+		return plural(hours, "hour", "hours") + " left"
+	}
+	return plural(int64(left/time.Minute)+1, "minute", "minutes") + " left"
+}
+
+// correspondenceHeadline is the line over a game's board: whose move it is,
+// or how it ended.
+func correspondenceHeadline(m CorrespondenceModel) string {
+	switch m.State.Status {
+	case "open":
+		return "Waiting for an opponent"
+	case "active":
+		if m.State.Seat != "" {
+			if m.State.Seat == m.State.Turn {
+				return "Your move"
+			}
+			return "Waiting for your opponent's move"
+		}
+		return capitalise(m.State.Turn) + " to move"
+	}
+	return capitalise(m.State.Result)
+}
+
+// capitalise upper-cases a line's first letter.
+func capitalise(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// correspondenceSeatName is a seat's username, or a placeholder while it is
+// open.
+func correspondenceSeatName(name string) string {
+	if name == "" {
+		return "open seat"
+	}
+	return name
+}
+
+// CorrespondenceTitle names a game by its players: "alice vs bob".
+func CorrespondenceTitle(white, black string) string {
+	return correspondenceSeatName(white) + " vs " + correspondenceSeatName(black)
+}
+
+// correspondenceMoveRows pairs a game's SAN into numbered rows, White's move
+// then Black's.
+func correspondenceMoveRows(san []string) [][]string {
+	rows := make([][]string, 0, (len(san)+1)/2)
+	for i := 0; i < len(san); i += 2 {
+		row := []string{strconv.Itoa(i/2 + 1), san[i], ""}
+		if i+1 < len(san) {
+			row[2] = san[i+1]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
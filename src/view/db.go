@@ -0,0 +1,31 @@
+package view
+
+import (
+	"time"
+
+	"github.com/dechristopher/lio/db"
+)
+
+// dumpURL is a dump's stable download link: /db/<month>, which redirects to a
+// fresh presigned object-store URL on every click (see handlers.DBDumpHandler).
+func dumpURL(d db.Dump) string {
+	return "/db/" + d.Month
+}
+
+// dumpMonthLabel renders a "YYYY-MM" dump key as "September 2026", falling back
+// to the raw key if it somehow does not parse.
+func dumpMonthLabel(month string) string {
+	t, err := time.Parse("2006-01", month)
+	if err != nil {
+		return month
+	}
+	return t.Format("January 2006")
+}
+
+// dumpGamesLabel renders a dump's game count ("1 game", "12,408 games").
+func dumpGamesLabel(n int64) string {
+	if n == 1 {
+		return "1 game"
+	}
+	return commas(n) + " games"
+}
@@ -8,9 +8,12 @@ package view
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-// DB renders the game-database page: what the planned database is, why open
-// game data matters for Octad, and the current (not yet live) status.
-func DB(meta Meta) templ.Component {
+import "github.com/dechristopher/lio/db"
+
+// DB renders the game-database page: what the database is, why open game data
+// matters for Octad, and the published monthly PGN dumps (newest first; see
+// the dump package).
+func DB(meta Meta, dumps []db.Dump) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -51,7 +54,84 @@ func DB(meta Meta) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<main class=\"card mb-4 w-[92vw] max-w-[30rem] text-left\"><h1 class=\"font-display text-xl font-bold text-fg\">Game Database</h1><p class=\"prose mt-2\">Every game played on <span class=\"text-accent\">octad</span>.gg will eventually be published here as free, downloadable monthly dumps: the raw PGN of every finished game, in chronological order.</p><p class=\"prose mt-3\">Octad PGNs read just like chess PGNs — standard algebraic notation plus Octad's own castle symbols (<span class=\"font-mono\">O</span>, <span class=\"font-mono\">O-O</span>, <span class=\"font-mono\">O-O-O</span>) — so existing tooling can parse them with little effort. A finished game looks like this:</p><pre class=\"code\">1. c2 b3  2. Kb2 O-O-O  3. cxb3 cxb3  4. d2 Nc2  5. d3 Nxa1  6. d4=Q#  1-0</pre><p class=\"prose mt-3\">Open game data is the point of collecting it: opening research, engine tuning, and statistics for the community — and since Octad is believed to be a solved game that has never been formally verified, a public archive of real games is raw material for anyone working toward that proof.</p><p class=\"prose mt-3\">The DB is not live yet. Until that day, we will continue to build the site and collect game data.</p></main>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<main class=\"card mb-4 w-[92vw] max-w-[30rem] text-left\"><h1 class=\"font-display text-xl font-bold text-fg\">Game Database</h1><p class=\"prose mt-2\">Every game played on <span class=\"text-accent\">octad</span>.gg is published here as free, downloadable monthly dumps: the raw PGN of every finished game, in chronological order.</p><p class=\"prose mt-3\">Octad PGNs read just like chess PGNs — standard algebraic notation plus Octad's own castle symbols (<span class=\"font-mono\">O</span>, <span class=\"font-mono\">O-O</span>, <span class=\"font-mono\">O-O-O</span>) — so existing tooling can parse them with little effort. A finished game looks like this:</p><pre class=\"code\">1. c2 b3  2. Kb2 O-O-O  3. cxb3 cxb3  4. d2 Nc2  5. d3 Nxa1  6. d4=Q#  1-0</pre><p class=\"prose mt-3\">Open game data is the point of collecting it: opening research, engine tuning, and statistics for the community — and since Octad is believed to be a solved game that has never been formally verified, a public archive of real games is raw material for anyone working toward that proof.</p><h2 class=\"mt-4 font-display text-lg font-bold text-fg\">Downloads</h2>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if len(dumps) == 0 {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<p class=\"prose mt-2\">No month has been published yet. A month's file appears here shortly after it ends.</p>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			} else {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<p class=\"mt-1 text-xs text-fg-subtle\">One gzip-compressed PGN file per calendar month (UTC), rebuilt whenever older games are added to the archive.</p><ul class=\"mt-2 flex flex-col divide-y divide-line\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				for _, d := range dumps {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<li class=\"flex items-baseline justify-between gap-3 py-2\"><a class=\"font-semibold text-accent hover:underline\" href=\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var3 templ.SafeURL
+					templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL(dumpURL(d)))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/db.templ`, Line: 49, Col: 94}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\" rel=\"nofollow\">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var4 string
+					templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(dumpMonthLabel(d.Month))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/db.templ`, Line: 50, Col: 35}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "</a> <span class=\"text-sm text-fg-subtle\">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var5 string
+					templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(dumpGamesLabel(d.Games))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/db.templ`, Line: 53, Col: 35}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, " · ")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var6 string
+					templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(bytes(d.Bytes))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/db.templ`, Line: 53, Col: 57}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "</span></li>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</ul>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "</main>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -59,7 +139,7 @@ func DB(meta Meta) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</div></body>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</div></body>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
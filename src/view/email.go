@@ -0,0 +1,39 @@
+package view
+
+// EmailModel is /account/email: the signed-in account's address, whether it
+// is confirmed, and the weekly digest switch.
+type EmailModel struct {
+	Address  string
+	Verified bool
+	Digest   bool
+	// MailEnabled is false on a site that cannot send mail, which hides the
+	// actions that would send some.
+	MailEnabled bool
+	Notice      string
+}
+
+// ForgotModel is /account/forgot, where a reset link is requested.
+type ForgotModel struct {
+	// Sent is set after a request. The page says the same thing whether or
+	// not an account matched.
+	Sent        bool
+	MailEnabled bool
+	Notice      string
+}
+
+// ResetModel is /account/reset, where a reset link is completed.
+type ResetModel struct {
+	Token string
+	// Done is set once the password has changed; Username prefills the login
+	// pointer.
+	Done     bool
+	Username string
+	Notice   string
+}
+
+// UnsubscribeModel is /account/unsubscribe, the digest's opt-out link.
+type UnsubscribeModel struct {
+	Token  string
+	Done   bool
+	Notice string
+}
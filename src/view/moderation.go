@@ -1,6 +1,7 @@
 package view
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/dechristopher/lio/config"
@@ -18,6 +19,131 @@ type ModerationModel struct {
 	Closed []ReportView
 	// OpenCount is the whole queue, which may exceed the page shown.
 	OpenCount int64
+	// FairPlay are the accounts the fair-play score flagged, highest first.
+	FairPlay []FairPlayView
+}
+
+// FairPlayView is one flagged account with the evidence behind its score: the
+// figures the score was rolled from, a bar per recent game of how often the
+// account played the engine's move, and those games.
+type FairPlayView struct {
+	UserID   string
+	Username string
+	Score    string
+	Games    int
+	// MatchRate, TopRate and ACPL are across the counted games; ThinkCV and
+	// Spread are "—" when too few games carry them.
+	MatchRate string
+	TopRate   string
+	ACPL      string
+	ThinkCV   string
+	Spread    string
+	Updated   string
+	// Dismissed is when a moderator last dismissed the account, "" if never:
+	// it is back because its score has climbed since.
+	Dismissed string
+	Bars      []FairPlayBar
+	Recent    []FairPlayGame
+}
+
+// FairPlayBar is one game's match rate in the evidence chart, oldest first.
+type FairPlayBar struct {
+	X, Y, W, H string
+	Title      string
+}
+
+// FairPlayGame is one sampled game in the evidence list.
+type FairPlayGame struct {
+	URL   string
+	When  string
+	Match string
+	ACPL  string
+	Think string
+}
+
+// fairPlayChart sizes the evidence chart's viewBox.
+const (
+	fairPlayChartW = 240
+	fairPlayChartH = 60
+)
+
+// FairPlayChartViewBox is the evidence chart's viewBox.
+func FairPlayChartViewBox() string {
+	return fmt.Sprintf("0 0 %d %d", fairPlayChartW, fairPlayChartH)
+}
+
+// FairPlayChartMid is the y of the chart's 50% gridline.
+func FairPlayChartMid() string {
+	return strconv.Itoa(fairPlayChartH / 2)
+}
+
+// NewFairPlayView builds a flagged account's row from its score and recent
+// samples (newest first, as db.FairPlaySamples returns them).
+func NewFairPlayView(a db.FlaggedAccount, samples []db.FairPlaySample) FairPlayView {
+	v := FairPlayView{
+		UserID:    strconv.FormatInt(a.UserID, 10),
+		Username:  a.Username,
+		Score:     fmt.Sprintf("%.1f", a.Score),
+		Games:     a.Games,
+		MatchRate: fmt.Sprintf("%.0f%%", 100*a.MatchRate),
+		TopRate:   fmt.Sprintf("%.0f%%", 100*a.TopRate),
+		ACPL:      fmt.Sprintf("%.0f", a.ACPL),
+		ThinkCV:   "—",
+		Spread:    "—",
+		Updated:   RelativeDay(a.Updated),
+	}
+	if a.ThinkCV != nil {
+		v.ThinkCV = fmt.Sprintf("%.2f", *a.ThinkCV)
+	}
+	if a.MatchSpread != nil {
+		v.Spread = fmt.Sprintf("%.2f", *a.MatchSpread)
+	}
+	if !a.Reviewed.IsZero() {
+		v.Dismissed = RelativeDay(a.Reviewed)
+	}
+
+	var judged []db.FairPlaySample
+	for _, s := range samples {
+		if s.Moves > 0 {
+			judged = append(judged, s)
+		}
+	}
+	if len(judged) > 0 {
+		slot := float64(fairPlayChartW) / float64(len(judged))
+		for i := range judged {
+			// drawn oldest first, so the chart reads left to right
+			s := judged[len(judged)-1-i]
+			rate := float64(s.EngineMatches) / float64(s.Moves)
+			h := rate * (fairPlayChartH - 2)
+			v.Bars = append(v.Bars, FairPlayBar{
+				X:     fmt.Sprintf("%.1f", float64(i)*slot+slot*0.15),
+				Y:     fmt.Sprintf("%.1f", fairPlayChartH-h),
+				W:     fmt.Sprintf("%.1f", slot*0.7),
+				H:     fmt.Sprintf("%.1f", h),
+				Title: fmt.Sprintf("%s: %d of %d engine moves", RelativeDay(s.When), s.EngineMatches, s.Moves),
+			})
+		}
+	}
+	for _, s := range samples {
+		g := FairPlayGame{
+			URL:   "/game/" + s.GameID,
+			When:  RelativeDay(s.When),
+			Match: "—",
+			ACPL:  strconv.Itoa(s.ACPL),
+			Think: "—",
+		}
+		if s.Moves > 0 {
+			g.Match = fmt.Sprintf("%d / %d", s.EngineMatches, s.Moves)
+		}
+		if s.ThinkMeanMs != nil {
+			g.Think = fmt.Sprintf("%.1fs", float64(*s.ThinkMeanMs)/1000)
+			if s.ThinkCV != nil {
+				g.Think += fmt.Sprintf(" ± %.0f%%", 100**s.ThinkCV)
+			}
+		}
+		v.Recent = append(v.Recent, g)
+	}
+	return v
 }
 
 // ReportView is one report as rendered.
@@ -36,6 +162,8 @@ type ReportView struct {
 	Target   string
 	// GameURL links the evidence when the report named a game.
 	GameURL string
+	// ChatURL links the chat log of that game's room (ModerationChat).
+	ChatURL string
 	// Resolution fields, set only on closed reports.
 	Resolved   string
 	Resolver   string
@@ -125,3 +253,36 @@ func ModerationMeta() Meta {
 		Description: "Moderation queue.",
 	}
 }
+
+// ChatLogModel is a room's chat log as a moderator reads it: both of the
+// room's conversations interleaved, withheld lines included.
+type ChatLogModel struct {
+	RoomID string
+	// RoomURL is the room's page — the live game, or its archive once over.
+	RoomURL string
+	Lines   []ChatLogLine
+	// Truncated reports the log ran past the lines shown.
+	Truncated bool
+}
+
+// ChatLogLine is one line of the log.
+type ChatLogLine struct {
+	When string
+	// Channel is "player" or "spectator", rendered as a tag on the line.
+	Channel string
+	// From is the name the line was said under; FromURL its account's page,
+	// empty for an anonymous player.
+	From    string
+	FromURL string
+	Body    string
+	// Filtered marks a line the word filter withheld: nobody saw it.
+	Filtered bool
+}
+
+// ChatLogMeta is the page meta for a room's chat log.
+func ChatLogMeta(roomID string) Meta {
+	m := ModerationMeta()
+	m.Title = "Chat " + roomID + " • " + config.SiteName()
+	m.Description = "Room chat log."
+	return m
+}
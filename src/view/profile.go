@@ -58,6 +58,9 @@ type ProfileModel struct {
 	Ratings  []RatingView
 	Total    RecordView
 	Lifetime LifetimeView
+	// Accuracy is the account's average accuracy across its reviewed games
+	// (package analysis), a hero figure beside the lifetime ones.
+	Accuracy AccuracyView
 	Variants []VariantRecordView
 	Bots     []BotRecordView
 	Games    []ProfileGameView
@@ -101,6 +104,13 @@ type ProfileModel struct {
 	// tools right there, and asking themselves to look is not a workflow.
 	ShowReport bool
 
+	// ShowBlock offers the block control (db/blocks.go) to a logged-in visitor
+	// looking at somebody else's open account, and IsBlocking is its state.
+	// Unlike the report control a moderator is offered it too: a block is a
+	// personal choice, not a moderation act.
+	ShowBlock  bool
+	IsBlocking bool
+
 	// Mod is the moderation bar's state, populated only when the viewing
 	// account may moderate this one. Rendering is gated on ShowMod; every
 	// action it offers is independently re-authorized server-side.
@@ -186,6 +196,31 @@ func NewLifetimeView(r db.Record, l db.Lifetime) LifetimeView {
 	}
 }
 
+// AccuracyView is the hero accuracy figure. Title says how many reviewed games
+// it averages: a figure from two games is not one from two hundred.
+type AccuracyView struct {
+	Value string // "87.3%"
+	Title string
+	Show  bool
+}
+
+// NewAccuracyView builds the accuracy figure, hidden until a game of the
+// account's has been reviewed.
+func NewAccuracyView(a db.Accuracy) AccuracyView {
+	if a.Games == 0 {
+		return AccuracyView{}
+	}
+	noun := "games"
+	if a.Games == 1 {
+		noun = "game"
+	}
+	return AccuracyView{
+		Value: strconv.FormatFloat(float64(a.Accuracy), 'f', 1, 32) + "%",
+		Title: "Average accuracy across " + commas(a.Games) + " analysed " + noun,
+		Show:  true,
+	}
+}
+
 // compactPlayed renders time at the board as a figure: "45m", "3h", "2d 3h".
 // Below a minute it is "—": an account can have finished games whose durations
 // round to nothing, and a blank tile beside a filled one reads as broken.
@@ -390,6 +425,11 @@ type ModBarView struct {
 	// CanSetRole gates the role control on the viewer being an admin: mods
 	// appoint nobody. Also false on your own page — no self-demotion.
 	CanSetRole bool
+	// CanFlagBot gates the bot control: admins only, and never on a staff
+	// account (the handler refuses those too). Bot is whether the account is
+	// one now, which picks between flagging and unflagging.
+	CanFlagBot bool
+	Bot        bool
 	// CanBan hides the ban control on your own account. An admin may
 	// administer themselves (title, rename) but not lock themselves out.
 	CanBan bool
@@ -0,0 +1,29 @@
+package view
+
+import (
+	"strconv"
+	"time"
+)
+
+// QueueModel is the matchmaking queue page: what the viewer is waiting for.
+type QueueModel struct {
+	// Control is the time control queued for, "½ + 1 · Blitz".
+	Control string
+	// Rating is the rating the queue pairs by; zero for an anonymous player,
+	// who is paired without one.
+	Rating int
+	Since  time.Time
+	// Waiting counts everyone in the same pool, the viewer included.
+	Waiting int
+}
+
+// queueWaitingLabel says how busy the viewer's pool is.
+func queueWaitingLabel(n int) string {
+	switch n {
+	case 0, 1:
+		return "Nobody else is waiting for this time control yet."
+	case 2:
+		return "1 other player is waiting for this time control."
+	}
+	return strconv.Itoa(n-1) + " other players are waiting for this time control."
+}
@@ -0,0 +1,123 @@
+package view
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dechristopher/lio/title"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// SimulItem is one simul as the /simul list shows it, resolved to display
+// strings by the handler like TournamentItem.
+type SimulItem struct {
+	ID        string
+	Name      string
+	Control   string // "½ + 1 · Blitz"
+	Host      string
+	HostColor string // "White" / "Black"
+	Boards    int
+	MaxBoards int
+	Status    string // "created" / "started" / "finished"
+	Wins      int
+	Draws     int
+	Losses    int
+}
+
+// SimulModel is one simul's page, which is also its host's dashboard.
+type SimulModel struct {
+	SimulItem
+	// Live is a simul this process is running, which the page follows over
+	// /socket/simul/<id>. A finished simul read back from the archive has
+	// nothing to follow.
+	Live  bool
+	State proto.SimulPayload
+	// The viewer: IsHost gets the start and next-board controls, a
+	// participant the leave button. CanJoin is false for an anonymous viewer,
+	// whom the page asks to log in instead.
+	LoggedIn bool
+	IsHost   bool
+	Entered  bool
+	CanJoin  bool
+	// Notice is the refusal from the last action, if any.
+	Notice string
+}
+
+// SimulList is the /simul page.
+type SimulList struct {
+	Simuls   []SimulItem
+	Finished []SimulItem
+	// Controls is set for a viewer who may host a simul, nil otherwise.
+	Controls []TournamentControl
+	// MaxBoards bounds the create form's board count.
+	MaxBoards int
+	Notice    string
+}
+
+// simulURL is a simul's page.
+func simulURL(id string) string {
+	return "/simul/" + id
+}
+
+// simulStatusLabel says where a simul is, for the list and the page head.
+func simulStatusLabel(s SimulItem) string {
+	switch s.Status {
+	case "created":
+		return "Waiting for the host to start"
+	case "started":
+		return "In progress"
+	}
+	return "Finished"
+}
+
+// simulBoardsLabel counts a simul's boards against the host's limit.
+func simulBoardsLabel(s SimulItem) string {
+	if s.Status == "created" {
+		return fmt.Sprintf("%d of %d boards", s.Boards, s.MaxBoards)
+	}
+	if s.Boards == 1 {
+		return "1 board"
+	}
+	return strconv.Itoa(s.Boards) + " boards"
+}
+
+// simulTally prints the host's score, "+3 =1 -2".
+func simulTally(wins, draws, losses int) string {
+	return fmt.Sprintf("+%d =%d -%d", wins, draws, losses)
+}
+
+// simulResultLabel is a board's state from the host's side.
+func simulResultLabel(b proto.SimulBoard) string {
+	switch b.Result {
+	case "win":
+		return "Host won"
+	case "draw":
+		return "Drawn"
+	case "loss":
+		return "Host lost"
+	case "void":
+		return "Not played"
+	}
+	if b.RoomID == "" {
+		return "Waiting"
+	}
+	if b.HostToMove {
+		return "Host to move"
+	}
+	return "Player to move"
+}
+
+// simulClock prints a clock in centi-seconds as m:ss, blank before there is
+// one.
+func simulClock(centi int64) string {
+	if centi <= 0 {
+		return ""
+	}
+	secs := centi / 100
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// simulBoardTitle rebuilds a board's title for the badge.
+func simulBoardTitle(b proto.SimulBoard) title.Title {
+	return title.Title{Code: b.Title, Name: b.TitleName}
+}
@@ -13,10 +13,12 @@ import (
 	"github.com/a-h/templ"
 
 	"github.com/dechristopher/lio/cache"
+	"github.com/dechristopher/lio/correspondence"
 	"github.com/dechristopher/lio/db"
 	"github.com/dechristopher/lio/learn"
 	"github.com/dechristopher/lio/message"
 	"github.com/dechristopher/lio/news"
+	"github.com/dechristopher/lio/pools"
 	"github.com/dechristopher/lio/prefs"
 	"github.com/dechristopher/lio/role"
 	"github.com/dechristopher/lio/settings"
@@ -24,6 +26,7 @@ import (
 	"github.com/dechristopher/lio/sysinfo"
 	"github.com/dechristopher/lio/title"
 	"github.com/dechristopher/lio/variant"
+	"github.com/dechristopher/lio/www/ws/proto"
 )
 
 func renderSmoke(t *testing.T, c templ.Component) string {
@@ -101,6 +104,10 @@ func TestRenderIndex(t *testing.T) {
 	mustContain(t, out, "Quick game")            // home heading (uppercased via CSS)
 	mustContain(t, out, `id="createGameButton"`) // modal opener
 	mustContain(t, out, `id="modalCreateGame"`)
+	// one quick-pairing button per curated time control, each queueing for it
+	for _, ctrl := range pools.CreateControls {
+		mustContain(t, out, `name="tc" value="`+ctrl.Deploy.HTMLName+`"`)
+	}
 	// the dialog's wiring lives in the cached lio-nav.js, not inline
 	mustContain(t, out, "lio-nav")
 	mustNotContain(t, out, "getElementById(\"modalCreateGame\")")
@@ -503,6 +510,11 @@ func TestRenderRoomGame(t *testing.T) {
 	mustContain(t, out, `id="btn-draw" class="ctrl-btn play-ctrl" title="Offer a draw">`)
 	mustNotContain(t, out, "Watching as a spectator")
 
+	// the takeback button only where the room allows takebacks
+	mustNotContain(t, out, `id="btn-takeback"`)
+	p.Takeback = true
+	mustContain(t, renderSmoke(t, Room(RoomMeta(p), p)), `id="btn-takeback"`)
+
 	// the live board ships the eval bar hidden; lio-game.js reveals it only
 	// after a bot game ends and analysis begins (requestLiveEvals)
 	mustContain(t, out, `id="eval-bar"`)
@@ -510,6 +522,42 @@ func TestRenderRoomGame(t *testing.T) {
 	// the copy-PGN button carries this room's PGN Event name, so the client's
 	// fallback PGN names the situation the same way the archived one does
 	mustContain(t, out, `data-event="Unrated Blitz game vs Computer"`)
+
+	// a seat facing a bot has nobody to chat with
+	mustNotContain(t, out, `id="chat"`)
+	mustNotContain(t, out, "lio-chat.js")
+}
+
+// TestRenderRoomChat locks which chat a viewer gets: the player chat with its
+// mute control for a seat facing a human, the spectator chat for a watcher,
+// and no input for an anonymous spectator, who is told why instead.
+func TestRenderRoomChat(t *testing.T) {
+	p := message.RoomTemplatePayload{
+		RoomID:        "abc",
+		PlayerColor:   "w",
+		OpponentColor: "b",
+		VariantName:   "Half One blitz",
+		Variant:       variant.HalfOneBlitz,
+	}
+	out := renderSmoke(t, Room(RoomMeta(p), p))
+	mustContain(t, out, `data-chat="player"`)
+	mustContain(t, out, `id="chat-mute"`)
+	mustContain(t, out, `id="chat-input"`)
+	mustContain(t, out, "lio-chat.js")
+
+	p.IsSpectator, p.PlayerColor, p.OpponentColor = true, "-", ""
+	watcher := Room(RoomMeta(p), p)
+	anon := renderSmoke(t, watcher)
+	mustContain(t, anon, `data-chat="spectator"`)
+	mustContain(t, anon, "Spectator chat")
+	mustNotContain(t, anon, `id="chat-mute"`)
+	mustNotContain(t, anon, `id="chat-input"`)
+	mustContain(t, anon, "Sign in to chat with other spectators.")
+
+	signedIn := renderSmokeViewer(t,
+		Viewer{UID: "u", LoggedIn: true, Username: "drew", AccountsEnabled: true}, watcher)
+	mustContain(t, signedIn, `id="chat-input"`)
+	mustNotContain(t, signedIn, "Sign in to chat with other spectators.")
 }
 
 // TestRenderRoomAnonCta locks the anonymous "create account" shim: it renders
@@ -840,6 +888,14 @@ func TestRenderRoomArchive(t *testing.T) {
 	// explored promotion pushes) and the hidden explore nudge
 	mustContain(t, out, `id="promo-select"`)
 	mustContain(t, out, `id="explore-hint"`)
+
+	// opening explorer: collapsed panel with its rating filter
+	mustContain(t, out, `id="explorer"`)
+	mustContain(t, out, `id="explorer-category"`)
+
+	// post-game review: the panel ships empty, filled from the archive data
+	mustContain(t, out, `id="analysis-panel"`)
+	mustContain(t, out, `id="btn-analysis"`)
 }
 
 // TestArchiveReportControl: a returning player of an archived game gets the
@@ -869,6 +925,24 @@ func TestArchiveReportControl(t *testing.T) {
 	mustContain(t, out, "lio-report")
 }
 
+// TestProfileBlockControl: the block control renders off ShowBlock, labelled
+// by its state, beside the report control or without it.
+func TestProfileBlockControl(t *testing.T) {
+	m := profileFixture()
+	mustNotContain(t, renderSmoke(t, Profile(ProfileMeta(m), m)), "data-block=")
+
+	m.ShowBlock = true
+	out := renderSmoke(t, Profile(ProfileMeta(m), m))
+	mustContain(t, out, `data-block="drewtest"`)
+	mustContain(t, out, "Block drewtest")
+	mustNotContain(t, out, "data-report-target")
+
+	m.IsBlocking = true
+	out = renderSmoke(t, Profile(ProfileMeta(m), m))
+	mustContain(t, out, "Unblock drewtest")
+	mustContain(t, out, `aria-pressed="true"`)
+}
+
 // TestRenderNews locks the paginated news page: the full page shell, the first
 // page of entries, the htmx pager when the feed spans multiple pages, and the
 // oldest entry landing on the last page.
@@ -896,7 +970,240 @@ func TestRenderAboutAndNotFound(t *testing.T) {
 	mustContain(t, renderSmoke(t, About(PageMeta("About"), "rules")), `data-castle-demo="far"`)
 	mustContain(t, renderSmoke(t, About(PageMeta("About"), "notation")), "ppkn/4/4/NKPP w NCFncf - 0 1")
 	mustContain(t, renderSmoke(t, NotFound(PageMeta("404"))), "404")
-	mustContain(t, renderSmoke(t, DB(PageMeta("Game Database"))), "Game Database")
+	mustContain(t, renderSmoke(t, DB(PageMeta("Game Database"), nil)), "No month has been published yet")
+}
+
+// TestRenderDBDumps covers the published-dump list on /db: each month links to
+// its stable /db/<month> download with a readable label, game count and size.
+func TestRenderDBDumps(t *testing.T) {
+	out := renderSmoke(t, DB(PageMeta("Game Database"), []db.Dump{
+		{Month: "2026-09", ObjectKey: "lio_octad_db_2026-09.pgn.gz", Games: 12408, Bytes: 3 << 20},
+		{Month: "2026-08", ObjectKey: "lio_octad_db_2026-08.pgn.gz", Games: 1, Bytes: 512},
+	}))
+	mustContain(t, out, `href="/db/2026-09"`)
+	mustContain(t, out, "September 2026")
+	mustContain(t, out, "12,408 games")
+	mustContain(t, out, "3.00 MB")
+	mustContain(t, out, "1 game ·")
+	mustNotContain(t, out, "No month has been published yet")
+}
+
+// TestRenderQueue covers the matchmaking queue page: the control being
+// waited for, the rating it pairs by (or the unrated note), the pool's size and
+// the way out.
+// TestRenderTokens: the list offers a revoke per token, a fresh token is
+// shown with its name, and the form goes away at the limit.
+func TestRenderTokens(t *testing.T) {
+	m := TokensModel{
+		Tokens: []APITokenView{{ID: "7", Name: "rating scraper", Scopes: "Read games"}},
+		Scopes: []ScopeOption{{Value: "games:read", Label: "Read games"}},
+	}
+	out := renderSmoke(t, Tokens(PageMeta("API tokens"), m))
+	mustContain(t, out, "rating scraper")
+	mustContain(t, out, `action="/account/tokens/7/revoke"`)
+	mustContain(t, out, "never used")
+	mustContain(t, out, `value="games:read"`)
+	mustNotContain(t, out, "cannot be shown again")
+
+	m.Fresh, m.FreshName = "lio_abc", "rating scraper"
+	m.AtLimit = true
+	out = renderSmoke(t, Tokens(PageMeta("API tokens"), m))
+	mustContain(t, out, "lio_abc")
+	mustContain(t, out, "cannot be shown again")
+	mustNotContain(t, out, `action="/account/tokens"`)
+}
+
+// TestRenderBlocks: each blocked account links to its page and carries its
+// own unblock form; an empty list says so.
+func TestRenderBlocks(t *testing.T) {
+	m := BlocksModel{Blocks: []BlockedView{{
+		Username: "pest", Since: time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC),
+	}}}
+	out := renderSmoke(t, Blocks(PageMeta("Blocked players"), m))
+	mustContain(t, out, `href="/@/pest"`)
+	mustContain(t, out, `action="/account/blocks/pest/unblock"`)
+	mustContain(t, out, "blocked 2 Oct 2026")
+	mustNotContain(t, out, "You have not blocked anybody.")
+
+	out = renderSmoke(t, Blocks(PageMeta("Blocked players"), BlocksModel{}))
+	mustContain(t, out, "You have not blocked anybody.")
+}
+
+// TestRenderBot: the token form is a bot owner's only, and a freshly issued
+// token is shown in place of the issue date — once.
+func TestRenderBot(t *testing.T) {
+	out := renderSmoke(t, Bot(PageMeta("Bot accounts"), BotPageModel{Available: true, LoggedIn: true}))
+	mustContain(t, out, "/api/bot/challenges")
+	mustNotContain(t, out, `action="/bot/token"`)
+
+	m := BotPageModel{Available: true, LoggedIn: true, IsBot: true}
+	out = renderSmoke(t, Bot(PageMeta("Bot accounts"), m))
+	mustContain(t, out, `action="/bot/token"`)
+	mustContain(t, out, "No token has been issued yet.")
+	mustContain(t, out, "Issue a token")
+
+	m.Token = "tok-abc"
+	out = renderSmoke(t, Bot(PageMeta("Bot accounts"), m))
+	mustContain(t, out, "tok-abc")
+	mustContain(t, out, "cannot be shown again")
+	mustContain(t, out, "Issue a new token")
+}
+
+func TestRenderQueue(t *testing.T) {
+	m := QueueModel{Control: "½ + 1 · Blitz", Rating: 1612, Since: time.Now(), Waiting: 3}
+	out := renderSmoke(t, Queue(PageMeta("Finding an opponent"), m))
+	mustContain(t, out, "½ + 1 · Blitz")
+	mustContain(t, out, "near 1612")
+	mustContain(t, out, "2 other players are waiting")
+	mustContain(t, out, `action="/queue/cancel"`)
+	mustContain(t, out, "lio-queue.js")
+
+	m.Rating, m.Waiting = 0, 1
+	out = renderSmoke(t, Queue(PageMeta("Finding an opponent"), m))
+	mustContain(t, out, "Unrated")
+	mustContain(t, out, "Nobody else is waiting")
+}
+
+// TestRenderTournament covers an event page: the standings rows with their
+// title badge and sheet, the games in progress, the viewer's entry control,
+// and the live hook lio-tournament.js follows.
+func TestRenderTournament(t *testing.T) {
+	m := TournamentModel{
+		TournamentItem: TournamentItem{
+			ID: "tn1", Name: "Friday Arena", Format: "Arena", Control: "½ + 1 · Blitz",
+			Length: "45 minutes", Status: "started", Players: 2, Rated: true, Berserk: true,
+		},
+		Live: true,
+		State: proto.TournamentPayload{
+			ID: "tn1", Status: "started", Seconds: 600,
+			Standings: []proto.TournamentRow{
+				{Rank: 1, Name: "drewtest", Title: "GM", TitleName: "Grandmaster", Rating: 1712, Score: 8, Tiebreak: 1790, Sheet: "224"},
+				{Rank: 2, Name: "rival", Rating: 1500, Score: 0, Sheet: "0"},
+			},
+			Games: []proto.TournamentGame{{RoomID: "room1", White: "drewtest", Black: "rival"}},
+		},
+		LoggedIn: true,
+		CanJoin:  true,
+	}
+	out := renderSmoke(t, Tournament(PageMeta(m.Name), m))
+	mustContain(t, out, `data-live="true"`)
+	mustContain(t, out, `href="/@/drewtest"`)
+	mustContain(t, out, ">GM</span>")
+	mustContain(t, out, ">224</td>")
+	mustContain(t, out, `href="/room1"`)
+	mustContain(t, out, `action="/tournament/tn1/join"`)
+	mustContain(t, out, "lio-tournament.js")
+	mustNotContain(t, out, "Nobody has joined yet")
+
+	m.Entered = true
+	mustContain(t, renderSmoke(t, Tournament(PageMeta(m.Name), m)), `action="/tournament/tn1/withdraw"`)
+
+	m.Status, m.Live = "finished", false
+	out = renderSmoke(t, Tournament(PageMeta(m.Name), m))
+	mustContain(t, out, `data-live="false"`)
+	mustNotContain(t, out, "/withdraw")
+}
+
+// TestRenderTournaments covers the list: an event links to its page, and the
+// create form is there only when the handler offers controls.
+func TestRenderTournaments(t *testing.T) {
+	list := TournamentList{Events: []TournamentItem{{
+		ID: "tn1", Name: "Sunday Swiss", Format: "Swiss", Control: "1 + 2 · Rapid",
+		Length: "5 rounds", Status: "created", Rounds: 5, Players: 1,
+		StartsAt: time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC),
+	}}}
+	out := renderSmoke(t, Tournaments(PageMeta("Tournaments"), list))
+	mustContain(t, out, `href="/tournament/tn1"`)
+	mustContain(t, out, "Starts 18 Oct 18:00 UTC")
+	mustContain(t, out, "1 player")
+	mustNotContain(t, out, `action="/tournament/new"`)
+
+	list.Controls = []TournamentControl{{Value: "halfoneblitz", Label: "½ + 1"}}
+	mustContain(t, renderSmoke(t, Tournaments(PageMeta("Tournaments"), list)), `action="/tournament/new"`)
+}
+
+// TestRenderSimul covers a simul's page as its host's dashboard: every board
+// with its clocks, the board waiting on the host marked, the next-board jump,
+// and the live hook lio-simul.js follows; then the participant's controls.
+func TestRenderSimul(t *testing.T) {
+	m := SimulModel{
+		SimulItem: SimulItem{
+			ID: "sm1", Name: "Friday Simul", Control: "½ + 1 · Blitz",
+			Host: "drewtest", HostColor: "White", Boards: 2, MaxBoards: 10, Status: "started",
+		},
+		Live: true,
+		State: proto.SimulPayload{
+			ID: "sm1", Status: "started", Next: "room1", Wins: 1,
+			Boards: []proto.SimulBoard{
+				{Name: "rival", Title: "FM", TitleName: "FIDE Master", RoomID: "room1", HostToMove: true, HostClock: 6150, PlayerClock: 3000},
+				{Name: "other", RoomID: "room2", Result: "win"},
+			},
+		},
+		LoggedIn: true,
+		IsHost:   true,
+	}
+	out := renderSmoke(t, Simul(PageMeta(m.Name), m))
+	mustContain(t, out, `data-live="true"`)
+	mustContain(t, out, `data-host="true"`)
+	mustContain(t, out, `href="/@/rival"`)
+	mustContain(t, out, ">FM</span>")
+	mustContain(t, out, `class="simul-host-move"`)
+	mustContain(t, out, "1:01")
+	mustContain(t, out, "Host won")
+	mustContain(t, out, `href="/simul/sm1/next"`)
+	mustContain(t, out, "+1 =0 -0")
+	mustContain(t, out, "lio-simul.js")
+	mustNotContain(t, out, "Nobody has joined yet")
+
+	m.IsHost, m.Status, m.State.Status, m.CanJoin = false, "created", "created", true
+	out = renderSmoke(t, Simul(PageMeta(m.Name), m))
+	mustContain(t, out, `action="/simul/sm1/join"`)
+	mustNotContain(t, out, "/next")
+
+	m.Entered, m.CanJoin = true, false
+	out = renderSmoke(t, Simul(PageMeta(m.Name), m))
+	mustContain(t, out, `action="/simul/sm1/leave"`)
+	mustContain(t, out, "Keep this page open")
+}
+
+// TestRenderSimuls covers the list and the create form, offered only when the
+// handler passes controls.
+func TestRenderSimuls(t *testing.T) {
+	list := SimulList{Simuls: []SimulItem{{
+		ID: "sm1", Name: "Friday Simul", Control: "½ + 1 · Blitz",
+		Host: "drewtest", HostColor: "Black", Boards: 3, MaxBoards: 10, Status: "created",
+	}}, MaxBoards: 30}
+	out := renderSmoke(t, Simuls(PageMeta("Simuls"), list))
+	mustContain(t, out, `href="/simul/sm1"`)
+	mustContain(t, out, "drewtest as Black")
+	mustContain(t, out, "3 of 10 boards")
+	mustNotContain(t, out, `action="/simul/new"`)
+
+	list.Controls = []TournamentControl{{Value: "halfoneblitz", Label: "½ + 1"}}
+	out = renderSmoke(t, Simuls(PageMeta("Simuls"), list))
+	mustContain(t, out, `action="/simul/new"`)
+	mustContain(t, out, `max="30"`)
+}
+
+// TestRenderRoomSimul covers a simul board: the link back to the simul, and
+// the host's jump to their next board, which the participant does not get.
+func TestRenderRoomSimul(t *testing.T) {
+	p := message.RoomTemplatePayload{
+		RoomID:      "abc",
+		PlayerColor: "w", OpponentColor: "b",
+		VariantName: "Half One blitz",
+		Variant:     variant.HalfOneBlitz,
+		Simul:       "sm1",
+		SimulHost:   true,
+	}
+	out := renderSmoke(t, Room(RoomMeta(p), p))
+	mustContain(t, out, `href="/simul/sm1"`)
+	mustContain(t, out, `id="simul-next" href="/simul/sm1/next?from=abc"`)
+
+	p.SimulHost = false
+	out = renderSmoke(t, Room(RoomMeta(p), p))
+	mustContain(t, out, `href="/simul/sm1"`)
+	mustNotContain(t, out, `id="simul-next"`)
 }
 
 // TestNoHTMLComments locks the comment convention: notes in .templ files use
@@ -914,13 +1221,21 @@ func TestNoHTMLComments(t *testing.T) {
 		Variant:     variant.HalfOneBlitz,
 	}
 	pages := map[string]templ.Component{
-		"index": Index(PageMeta("Free Online Octad"), nil, message.SiteStats{}, message.Community{}),
-		"room":  Room(RoomMeta(p), p),
-		"about": About(PageMeta("About"), "board"),
-		"news":  News(PageMeta("News"), 1),
-		"db":    DB(PageMeta("Game Database")),
-		"learn": Learn(PageMeta("Learn to play"), &learn.Lessons[0]),
-		"404":   NotFound(PageMeta("404")),
+		"index":      Index(PageMeta("Free Online Octad"), nil, message.SiteStats{}, message.Community{}),
+		"room":       Room(RoomMeta(p), p),
+		"about":      About(PageMeta("About"), "board"),
+		"news":       News(PageMeta("News"), 1),
+		"db":         DB(PageMeta("Game Database"), nil),
+		"queue":      Queue(PageMeta("Finding an opponent"), QueueModel{}),
+		"tournament": Tournament(PageMeta("Arena"), TournamentModel{Live: true}),
+		"chat log":   ModerationChat(ChatLogMeta("abc"), ChatLogModel{RoomID: "abc", RoomURL: "/abc"}),
+		"learn":      Learn(PageMeta("Learn to play"), &learn.Lessons[0]),
+		"training":   Training(PageMeta("Puzzles"), TrainingModel{Puzzle: &TrainingPuzzle{ID: 1, Turn: "white"}}),
+		"correspondence": Correspondence(PageMeta("Correspondence game"),
+			CorrespondenceModel{CorrespondenceItem: CorrespondenceItem{ID: "abc"}, State: correspondence.State{Status: "active"}}),
+		"bot":    Bot(PageMeta("Bot accounts"), BotPageModel{Available: true, IsBot: true}),
+		"tokens": Tokens(PageMeta("API tokens"), TokensModel{Tokens: []APITokenView{{ID: "1", Name: "x"}}}),
+		"404":    NotFound(PageMeta("404")),
 	}
 	for name, page := range pages {
 		t.Run(name, func(t *testing.T) {
@@ -1000,6 +1315,15 @@ func TestRenderHeaderViewerStates(t *testing.T) {
 	mustContain(t, loggedIn, `id="emailForm"`)
 	mustContain(t, loggedIn, `value="drew"`) // username prefill
 	mustNotContain(t, loggedOut, `id="modalEditProfile"`)
+
+	// the account's own data: the export is a plain download link, and the
+	// deletion dialog asks for the typed-out username
+	mustContain(t, loggedIn, `href="/api/auth/export"`)
+	mustContain(t, loggedIn, `href="/account/blocks"`)
+	mustContain(t, loggedIn, `id="deleteAccountButton"`)
+	mustContain(t, loggedIn, `id="modalDeleteAccount"`)
+	mustContain(t, loggedIn, `id="deleteAccountForm"`)
+	mustNotContain(t, loggedOut, `id="modalDeleteAccount"`)
 }
 
 // TestRenderSessionList covers the active-sessions fragment: the current
@@ -1081,7 +1405,8 @@ func TestRenderProfile(t *testing.T) {
 	mustContain(t, out, ">3<")  // games
 	mustContain(t, out, ">5h<") // played
 	mustNotContain(t, out, "first game")
-	mustContain(t, out, "All games") // lifetime record row
+	mustNotContain(t, out, "analysed games") // no reviewed games, no accuracy figure
+	mustContain(t, out, "All games")         // lifetime record row
 	mustContain(t, out, "Versus the computer")
 	// the ladder's glyphs are wrapped so their baseline can be corrected —
 	// these come from a fallback font that sets them low
@@ -1098,6 +1423,16 @@ func TestRenderProfile(t *testing.T) {
 	mustNotContain(t, out, "lio-mod")
 }
 
+// TestRenderProfileAccuracy: the accuracy figure appears once a game of the
+// account's has been reviewed, and says over how many.
+func TestRenderProfileAccuracy(t *testing.T) {
+	m := profileFixture()
+	m.Accuracy = NewAccuracyView(db.Accuracy{Games: 4, Accuracy: 87.26})
+	out := renderSmoke(t, Profile(ProfileMeta(m), m))
+	mustContain(t, out, ">87.3%<")
+	mustContain(t, out, "Average accuracy across 4 analysed games")
+}
+
 // TestRenderProfileFollow covers the follow block's gating (arch/FOLLOWING.md
 // Phase 1): the counts render on their own, the control only for a viewer who
 // may press it, and the button carries its state in words as well as in a
@@ -1363,6 +1698,17 @@ func TestRenderProfileModBar(t *testing.T) {
 	m.Mod.CanSetRole = true
 	admin := renderSmoke(t, Profile(ProfileMeta(m), m))
 	mustContain(t, admin, `data-mod-action="role"`)
+	mustNotContain(t, admin, `data-mod-action="bot"`)
+
+	// the bot control is admin-only and flips with the flag
+	m.Mod.CanFlagBot = true
+	flag := renderSmoke(t, Profile(ProfileMeta(m), m))
+	mustContain(t, flag, `data-mod-action="bot"`)
+	m.Mod.Bot = true
+	unflag := renderSmoke(t, Profile(ProfileMeta(m), m))
+	mustContain(t, unflag, `data-mod-action="unbot"`)
+	mustNotContain(t, unflag, `data-mod-action="bot"`)
+	m.Mod.CanFlagBot, m.Mod.Bot = false, false
 
 	// a banned target swaps the ban control for the lift control
 	m.Mod.Banned = true
@@ -1601,6 +1947,42 @@ func TestRenderAuditPager(t *testing.T) {
 	mustContain(t, end, "is-disabled") // "Older" is inert at the end
 }
 
+// TestRenderServerLog: the server log card is admin-only, its lines carry their
+// fields as chips with the room linking back into the search, and the level
+// control drives the confirmation flow rather than posting directly.
+func TestRenderServerLog(t *testing.T) {
+	l := ServerLog{
+		Level:    "info",
+		Packages: []string{"engine", "room"},
+		Levels:   []PackageLevel{{Package: "room", Level: "debug"}},
+		Lines: []LogLine{{
+			When:    "12:00:01.250",
+			Level:   "error",
+			Package: "room",
+			Caller:  "Room",
+			Message: "deploy assembly failed",
+			Room:    "abc123",
+			Game:    "g-1",
+			Account: "42",
+		}},
+	}
+
+	admin := renderSmoke(t, System(SystemMeta(), SystemModel{Tab: TabLog, IsAdmin: true, Log: l}))
+	mustContain(t, admin, "Server log")
+	mustContain(t, admin, `hx-get="/system/logs"`)
+	mustContain(t, admin, "deploy assembly failed")
+	mustContain(t, admin, `href="/system/log?room=abc123"`)
+	mustContain(t, admin, `id="logLevelForm"`)
+	mustContain(t, admin, `data-package="room"`)
+
+	mod := renderSmoke(t, System(SystemMeta(), SystemModel{Tab: TabLog, Log: l}))
+	mustNotContain(t, mod, "Server log")
+	mustNotContain(t, mod, "deploy assembly failed")
+
+	empty := ServerLog{Filtered: true, Room: "nope"}
+	mustContain(t, renderSmoke(t, ServerLogBody(empty)), "No lines match that search.")
+}
+
 // TestAuditURL locks the link builder the pager and the player page share: an
 // unfiltered first page is the bare path, and filters survive paging.
 func TestAuditURL(t *testing.T) {
@@ -1985,6 +2367,23 @@ func TestRenderAuditRowFields(t *testing.T) {
 	mustNotContain(t, siteOut, `class="audit-target"`)
 }
 
+// TestRenderAuditRowDeletedAccount: an entry whose accounts have since been
+// deleted says so on both sides, rather than linking to "/@/" or passing off a
+// missing target as a site-wide change.
+func TestRenderAuditRowDeletedAccount(t *testing.T) {
+	f := AuditFeed{Page: 1, Pages: 1, Actions: []ModFeedEntry{{
+		When: "just now", Action: "delete",
+		Details: DetailChipsOf(map[string]any{"username": "leaver", "games": float64(12)}),
+	}}}
+	out := renderSmoke(t, AuditFeedBody(f))
+
+	mustContain(t, out, "deleted account")
+	mustContain(t, out, "leaver")
+	mustNotContain(t, out, "site-wide")
+	mustNotContain(t, out, `href="/@/"`)
+	mustNotContain(t, out, `class="audit-actor"`)
+}
+
 // TestRenderConfirmModal: the site controls carry the change summary and its
 // consequence for the confirmation, and the reason lives in the modal rather
 // than at the top of the form.
@@ -2117,7 +2516,7 @@ func TestRenderReportQueue(t *testing.T) {
 			Category: "cheating", Class: ReportCategoryClass("cheating"),
 			Help: ReportCategoryHelp("cheating"), Note: "instant moves from a lost position",
 			Reporter: "drewtest", Target: "spammer99",
-			GameURL: "/game/abc-123",
+			GameURL: "/game/abc-123", ChatURL: "/moderation/chat/roomX",
 		}},
 		Closed: []ReportView{{
 			ID: "6", Category: "stalling", Class: ReportCategoryClass("stalling"),
@@ -2132,6 +2531,7 @@ func TestRenderReportQueue(t *testing.T) {
 	mustContain(t, out, "spammer99")
 	mustContain(t, out, `href="/@/spammer99"`)  // straight to the account
 	mustContain(t, out, `href="/game/abc-123"`) // the evidence
+	mustContain(t, out, `href="/moderation/chat/roomX"`)
 	mustContain(t, out, "instant moves from a lost position")
 	mustContain(t, out, `data-resolve-report="7"`)
 	mustContain(t, out, "Recently resolved")
@@ -2146,6 +2546,71 @@ func TestRenderReportQueue(t *testing.T) {
 	mustNotContain(t, empty, "Recently resolved")
 }
 
+// TestRenderFairPlayFlags covers the fair-play list on /moderation: a flagged
+// account renders with its figures, a bar per game and the games themselves,
+// and its only controls are a report, a dismissal and the way to its page —
+// never a sanction.
+func TestRenderFairPlayFlags(t *testing.T) {
+	cv, spread := float32(0.12), float32(0.03)
+	ms := int32(1400)
+	a := db.FlaggedAccount{
+		UserID: 42, Username: "relay9",
+		FairPlayScore: db.FairPlayScore{
+			Score: 83.4, Games: 12, MatchRate: 0.91, TopRate: 0.97, ACPL: 6,
+			ThinkCV: &cv, MatchSpread: &spread,
+		},
+		Updated: time.Now(),
+	}
+	samples := []db.FairPlaySample{
+		{GameID: "g-new", When: time.Now(), Moves: 10, EngineMatches: 9, TopMatches: 10, ACPL: 4, ThinkMeanMs: &ms, ThinkCV: &cv},
+		{GameID: "g-old", When: time.Now(), Moves: 8, EngineMatches: 8, TopMatches: 8},
+	}
+	m := ModerationModel{FairPlay: []FairPlayView{NewFairPlayView(a, samples)}}
+	out := renderSmoke(t, Moderation(ModerationMeta(), m))
+
+	mustContain(t, out, `id="fairplay"`)
+	mustContain(t, out, ">83.4<")
+	mustContain(t, out, ">91%<")
+	mustContain(t, out, ">0.12<")
+	mustContain(t, out, `class="fairplay-bar"`)
+	mustContain(t, out, "9 of 10 engine moves")
+	mustContain(t, out, `href="/game/g-new"`)
+	mustContain(t, out, "1.4s ± 12%")
+	mustContain(t, out, `data-fairplay-report="42"`)
+	mustContain(t, out, `data-fairplay-dismiss="42"`)
+	mustContain(t, out, `href="/@/relay9"`)
+	mustNotContain(t, out, `data-mod-action="ban"`)
+
+	// nothing flagged, no section
+	mustNotContain(t, renderSmoke(t, Moderation(ModerationMeta(), ModerationModel{})), `id="fairplay"`)
+}
+
+// TestRenderModerationChat covers a room's chat log: both conversations in one
+// column, account names linked, and a withheld line shown but marked.
+func TestRenderModerationChat(t *testing.T) {
+	m := ChatLogModel{
+		RoomID:  "roomX",
+		RoomURL: "/roomX",
+		Lines: []ChatLogLine{
+			{When: "2026-10-18 12:00:01", Channel: "player", From: "drew", FromURL: "/@/drew", Body: "good luck"},
+			{When: "2026-10-18 12:00:05", Channel: "spectator", From: "Anonymous (u1)", Body: "nice"},
+			{When: "2026-10-18 12:01:00", Channel: "player", From: "spammer99", FromURL: "/@/spammer99", Body: "something vile", Filtered: true},
+		},
+		Truncated: true,
+	}
+	out := renderSmoke(t, ModerationChat(ChatLogMeta("roomX"), m))
+	mustContain(t, out, `href="/roomX"`)
+	mustContain(t, out, `class="chat-log-channel chat-log-player"`)
+	mustContain(t, out, `class="chat-log-channel chat-log-spectator"`)
+	mustContain(t, out, `href="/@/drew"`)
+	mustContain(t, out, "Anonymous (u1)")
+	mustContain(t, out, `class="chat-log-body chat-log-withheld"`)
+	mustContain(t, out, "The log continues past the lines shown.")
+
+	empty := renderSmoke(t, ModerationChat(ChatLogMeta("roomY"), ChatLogModel{RoomID: "roomY", RoomURL: "/roomY"}))
+	mustContain(t, empty, "Nobody said anything in this room.")
+}
+
 // TestReportCategoryMapping: every category the database accepts has a tint,
 // an explanation and a picker label, so a new one cannot be added to the CHECK
 // constraint and render as a bare slug.
@@ -2805,3 +3270,72 @@ func TestLearnSuccessLinesStayServerSide(t *testing.T) {
 		t.Fatalf("expected both judged and click-only steps, got %d and %d", checked, exempt)
 	}
 }
+
+// TestRenderTraining covers the trainer with a puzzle to play, which boots the
+// client from #training-data, and with none, which offers no board at all.
+func TestRenderTraining(t *testing.T) {
+	m := TrainingModel{
+		Puzzle: &TrainingPuzzle{ID: 42, OFEN: "k3/4/1K2/3Q w - - 0 1", Turn: "white", Theme: "mate"},
+		Rating: "1500?",
+	}
+	out := renderSmoke(t, Training(PageMeta("Puzzles"), m))
+	mustContain(t, out, `id="training-board"`)
+	mustContain(t, out, `id="training-data"`)
+	mustContain(t, out, "White to move. Find the mate.")
+	mustContain(t, out, `href="/training/42"`)
+	mustContain(t, out, "1500?")
+	mustContain(t, out, "lio-training.js")
+
+	empty := renderSmoke(t, Training(PageMeta("Puzzles"), TrainingModel{}))
+	mustContain(t, empty, "There are no puzzles for you right now.")
+	mustContain(t, empty, `href="/login"`)
+	mustNotContain(t, empty, `id="training-board"`)
+	mustNotContain(t, empty, "lio-training.js")
+}
+
+// TestRenderCorrespondence covers a game from its player's side, whose move it
+// is, and the list page as a logged-in player and as an anonymous viewer.
+func TestRenderCorrespondence(t *testing.T) {
+	m := CorrespondenceModel{
+		CorrespondenceItem: CorrespondenceItem{ID: "abc1234", Control: "3 days", Rated: true, Deadline: time.Now().Add(50 * time.Hour)},
+		White:              "alice",
+		State: correspondence.State{
+			ID: "abc1234", Status: "active", Seat: "white", Turn: "white",
+			SAN: []string{"c2", "b3", "d2"},
+		},
+		LoggedIn: true,
+	}
+	out := renderSmoke(t, Correspondence(PageMeta("Correspondence game"), m))
+	mustContain(t, out, `id="corr-board"`)
+	mustContain(t, out, `id="correspondence-data"`)
+	mustContain(t, out, "Your move")
+	mustContain(t, out, "2 days 1 hour left")
+	mustContain(t, out, "Open seat")
+	mustContain(t, out, `id="corr-resign"`)
+	mustContain(t, out, "lio-correspondence.js")
+	mustNotContain(t, out, "Review game")
+
+	m.State.Status, m.State.Result = "finished", "drawn by agreement"
+	over := renderSmoke(t, Correspondence(PageMeta("Correspondence game"), m))
+	mustContain(t, over, "Drawn by agreement")
+	mustContain(t, over, `href="/abc1234/1"`)
+	mustNotContain(t, over, `id="corr-resign"`)
+
+	list := CorrespondenceList{
+		Available: true,
+		LoggedIn:  true,
+		YourMove:  []CorrespondenceItem{{ID: "abc1234", Control: "3 days", Opponent: "bob", Color: "white"}},
+		Seeks:     []CorrespondenceItem{{ID: "xyz9876", Control: "1 day", Opponent: "carol", Color: "black"}},
+		Controls:  []TournamentControl{{Value: "three-day-correspondence", Label: "3 days per move"}},
+	}
+	page := renderSmoke(t, Correspondences(PageMeta("Correspondence"), list))
+	mustContain(t, page, "vs bob")
+	mustContain(t, page, `action="/correspondence/xyz9876/join"`)
+	mustContain(t, page, `action="/correspondence/new"`)
+
+	list.LoggedIn = false
+	anon := renderSmoke(t, Correspondences(PageMeta("Correspondence"), list))
+	mustContain(t, anon, "to play correspondence games")
+	mustNotContain(t, anon, `action="/correspondence/xyz9876/join"`)
+	mustNotContain(t, anon, `action="/correspondence/new"`)
+}
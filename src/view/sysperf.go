@@ -7,6 +7,7 @@ import (
 
 	"github.com/dechristopher/lio/cache"
 	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/dispatch"
 	"github.com/dechristopher/lio/store"
 	"github.com/dechristopher/lio/sysinfo"
 )
@@ -105,6 +106,11 @@ const (
 // and is worth an operator's attention.
 const moveLagWarn = 25 * time.Millisecond
 
+// engineWaitWarn is the average engine queue wait above which it is tinted. A
+// bullet bot searches for a few hundred milliseconds a move, so losing a tenth
+// of a second to the queue is already a visibly weaker bot.
+const engineWaitWarn = 100 * time.Millisecond
+
 // SystemStatsOf assembles the panel from one sample of each source.
 func SystemStatsOf(rt sysinfo.Runtime, pg db.Stats, rd cache.Stats, obj store.Stats) SystemStats {
 	return SystemStats{
@@ -154,6 +160,10 @@ func runtimeView(rt sysinfo.Runtime) RuntimeView {
 					Help: "Websocket channels with at least one connection"},
 			},
 		},
+		{
+			Title: "Engine",
+			Rows:  engineRows(rt.Engine),
+		},
 		{
 			Title: "Memory",
 			Rows: []StatRow{
@@ -194,6 +204,27 @@ func runtimeView(rt sysinfo.Runtime) RuntimeView {
 	return v
 }
 
+// engineRows describes the engine worker pool. Everything a bot does — moves,
+// draw verdicts, deploys — queues here, and its clock runs while it waits, so
+// a queue that does not drain is bots flagging.
+func engineRows(s dispatch.Stats) []StatRow {
+	return []StatRow{
+		{Label: "Workers", Value: count(int64(s.Busy)) + " / " + count(int64(s.Workers)),
+			Class: warnIf(s.Workers > 0 && s.Busy >= s.Workers),
+			Help:  "Workers running a bot search, against the pool size. There is one worker per core Go schedules on"},
+		{Label: "Lent as helpers", Value: count(int64(s.Lent)),
+			Help: "Idle workers helping a running search reach its depth sooner. They are taken back as soon as another request arrives"},
+		{Label: "Queued", Value: count(int64(s.Queued)), Class: warnIf(s.Queued > 0),
+			Help: "Requests waiting for a worker, served lowest bot clock first"},
+		{Label: "Longest wait", Value: shortDuration(s.OldestWait),
+			Help: "How long the longest-waiting queued request has been there"},
+		{Label: "Average wait", Value: shortDuration(s.AvgWait), Class: warnIf(s.AvgWait >= engineWaitWarn),
+			Help: "Rolling average time a request waited for a worker. It comes straight out of the bot's think time"},
+		{Label: "Served", Value: count(int64(s.Served)),
+			Help: "Requests taken off the queue since boot"},
+	}
+}
+
 // procsClass flags a runtime scheduling on fewer cores than the machine has —
 // the standard container-CPU-limit trap, and invisible from inside the app
 // unless something says so.
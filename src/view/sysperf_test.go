@@ -7,6 +7,7 @@ import (
 
 	"github.com/dechristopher/lio/cache"
 	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/dispatch"
 	"github.com/dechristopher/lio/store"
 	"github.com/dechristopher/lio/sysinfo"
 )
@@ -243,6 +244,33 @@ func TestCappedSchedulerIsFlagged(t *testing.T) {
 	}
 }
 
+// A queue that is not draining is bots losing think time; an idle pool is not
+// worth anyone's attention.
+func TestEngineQueueIsFlagged(t *testing.T) {
+	tinted := func(rt sysinfo.Runtime) map[string]string {
+		out := make(map[string]string)
+		for _, r := range sectionRows(t, runtimeView(rt).Sections, "Engine") {
+			if r.Class != "" {
+				out[r.Label] = r.Class
+			}
+		}
+		return out
+	}
+
+	if got := tinted(sysinfo.Runtime{Engine: dispatch.Stats{Workers: 4, Busy: 1}}); len(got) != 0 {
+		t.Errorf("idle pool tinted %v", got)
+	}
+
+	got := tinted(sysinfo.Runtime{Engine: dispatch.Stats{
+		Workers: 4, Busy: 4, Queued: 3, AvgWait: 300 * time.Millisecond,
+	}})
+	for _, label := range []string{"Workers", "Queued", "Average wait"} {
+		if got[label] != statWarn {
+			t.Errorf("saturated pool: %q tinted %q, want %q", label, got[label], statWarn)
+		}
+	}
+}
+
 // Every figure on the panel is labelled in internal vocabulary, so every row
 // must carry the sentence that explains it.
 func TestEveryRowIsExplained(t *testing.T) {
@@ -115,6 +115,10 @@ type SystemModel struct {
 	// each one was answered. Admin-only and only populated for an admin — a
 	// moderator cannot send one, so the page does not pay for the read.
 	Broadcasts []BroadcastView
+	// Log is this process's recent log lines with the per-package level
+	// controls. Admin-only and only populated for an admin: server lines name
+	// uids and rooms, and the levels change what every room writes.
+	Log ServerLog
 }
 
 // BroadcastView is one sent broadcast on the console: what was said, whether it
@@ -312,6 +316,22 @@ func ActionHelp(action string) string {
 		return "Message sent to one account's notifications"
 	case "broadcast":
 		return "Message sent to every account's notifications, or one retired"
+	case "bot":
+		return "Account flagged as a bot, for an engine to play through"
+	case "unbot":
+		return "Bot flag removed; the account's token stops working"
+	case "token-create":
+		return "Personal API token created by the account's owner"
+	case "token-revoke":
+		return "Personal API token revoked by the account's owner"
+	case "token-use":
+		return "Personal API token in use, one entry per token every ten minutes"
+	case "password-reset":
+		return "Password reset through an emailed link"
+	case "delete":
+		return "Account deleted by its owner; its games stay in the archive as Anonymous"
+	case "fairplay":
+		return "Fair-play flag worked: turned into a cheating report, or dismissed"
 	}
 	return "Moderation action"
 }
@@ -333,6 +353,10 @@ func detailHelp(key string) string {
 		return "Ban length as chosen"
 	case "forfeited":
 		return "Live games this ban ended as a forfeit"
+	case "username":
+		return "The deleted account's username, which nothing else keeps"
+	case "games":
+		return "Archived games the deleted account played, now shown as Anonymous"
 	case "lifted":
 		return "The sanction that was lifted"
 	case "banReason":
@@ -355,12 +379,28 @@ func detailHelp(key string) string {
 		return "Whether new games count toward ratings"
 	case "ratedEnabledWas":
 		return "Ratings before this change"
+	case "outcome":
+		return "What the moderator made of the fair-play flag"
+	case "score":
+		return "The fair-play score when the flag was worked"
 	case "body":
 		return "The message that was sent"
 	case "asks":
 		return "Answers the message demands before it clears"
 	case "retired":
 		return "The broadcast that was pulled"
+	case "token":
+		return "The personal API token's name"
+	case "tokenId":
+		return "The personal API token's id"
+	case "scopes", "scope":
+		return "What the token may reach"
+	case "request":
+		return "The request that opened this usage window"
+	case "requests":
+		return "Requests made with the token since its last entry"
+	case "logPackage":
+		return "Package whose server log level changed, on the instance that served the request"
 	}
 	return "Recorded with this action"
 }
@@ -531,6 +571,100 @@ func SettingEffect(key string, turningOn bool) string {
 // filter nobody discovers.
 var ModActionKinds = []string{
 	"ban", "unban", "title", "role", "rename", "setting", "notify", "broadcast",
+	"bot", "unbot", "token-create", "token-revoke", "token-use", "password-reset",
+	"delete", "fairplay",
+}
+
+// ServerLog is one search of the server log ring (util.QueryLog), with the
+// filter values echoed back into the form.
+type ServerLog struct {
+	Lines []LogLine
+	// Level / Package / Room / Since / Until are the live filter values. The
+	// times are as typed, "2006-01-02 15:04" in UTC.
+	Level   string
+	Package string
+	Room    string
+	Since   string
+	Until   string
+	// Limit is how many lines one search returns at most; Truncated reports
+	// that it was reached, so the empty-looking bottom of the list is not read
+	// as the start of the log.
+	Limit     int
+	Truncated bool
+	// Packages fills the package dropdowns; Levels is every package whose
+	// level is not the default, for the level control.
+	Packages []string
+	Levels   []PackageLevel
+	// Spill reports whether evicted lines are kept on disk, which is what
+	// decides how far back a search can reach.
+	Spill bool
+	// Filtered reports whether any filter beyond the default level is active.
+	Filtered bool
+}
+
+// LogLine is one rendered log line.
+type LogLine struct {
+	// When is the time of day; WhenExact the full timestamp. A log line is
+	// read against its neighbours, so the short form is clock time rather
+	// than "2 minutes ago".
+	When      string
+	WhenExact string
+	Level     string
+	Package   string
+	Caller    string
+	Message   string
+	Room      string
+	Game      string
+	UID       string
+	Account   string
+}
+
+// PackageLevel is one package's overridden level.
+type PackageLevel struct {
+	Package string
+	Level   string
+}
+
+// LogLevels are the levels the log page offers, lowest first.
+var LogLevels = []string{"debug", "info", "error"}
+
+// LogLevelClass tints a log line's level chip with the audit feed's verb
+// colors: errors read as losses, debug as the quiet neutral.
+func LogLevelClass(level string) string {
+	switch level {
+	case "error":
+		return "act-ban"
+	case "debug":
+		return "act-setting"
+	}
+	return "act-edit"
+}
+
+// serverLogRoomURL is the search for every line about one room.
+func serverLogRoomURL(room string) string {
+	return TabLog.Path() + "?" + url.Values{"room": {room}}.Encode()
+}
+
+// serverLogCountLabel summarizes a search.
+func serverLogCountLabel(l ServerLog) string {
+	switch {
+	case len(l.Lines) == 1:
+		return "1 line"
+	case l.Truncated:
+		return "newest " + strconv.Itoa(len(l.Lines)) + " lines"
+	}
+	return strconv.Itoa(len(l.Lines)) + " lines"
+}
+
+// SiteWide reports whether a verb is aimed at the site rather than at one
+// account. An entry of any other verb with no target names an account that has
+// since been deleted.
+func SiteWide(action string) bool {
+	switch action {
+	case "setting", "broadcast", "room":
+		return true
+	}
+	return false
 }
 
 // AuditPageSize is how many entries one page of the feed shows.
@@ -0,0 +1,40 @@
+package view
+
+import "time"
+
+// TokensModel is /account/tokens: the signed-in account's personal API
+// tokens, and the form that creates one.
+type TokensModel struct {
+	Tokens []APITokenView
+	// Scopes are the scopes the form offers; the moderate scope only to staff.
+	Scopes []ScopeOption
+	// Fresh is a just-created token's plaintext, set only on the response to
+	// the creating post — it is stored as a hash, so this is its one showing.
+	Fresh     string
+	FreshName string
+	Notice    string
+	// AtLimit hides the form once the account holds the maximum.
+	AtLimit bool
+}
+
+// APITokenView is one row of the token list.
+type APITokenView struct {
+	ID       string
+	Name     string
+	Scopes   string
+	LastUsed time.Time
+}
+
+// ScopeOption is one checkbox of the creation form.
+type ScopeOption struct {
+	Value string
+	Label string
+}
+
+// tokenUsedLabel describes when a token was last presented.
+func tokenUsedLabel(t time.Time) string {
+	if t.IsZero() {
+		return "never used"
+	}
+	return "last used " + t.UTC().Format("2 Jan 2006 15:04") + " UTC"
+}
@@ -0,0 +1,110 @@
+package view
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dechristopher/lio/title"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// TournamentItem is one event as the /tournament list shows it. The handler
+// resolves everything to display strings, so the page never has to know the
+// tournament package's types.
+type TournamentItem struct {
+	ID      string
+	Name    string
+	Format  string // "Arena" / "Swiss"
+	Control string // "½ + 1 · Blitz"
+	// Length is the event's size: "45 minutes" or "5 rounds".
+	Length  string
+	Status  string // "created" / "started" / "finished"
+	Round   int
+	Rounds  int
+	Players int
+	Rated   bool
+	Berserk bool
+	// StartsAt is when a created event starts; the page counts down to it.
+	StartsAt time.Time
+}
+
+// TournamentModel is one event's page.
+type TournamentModel struct {
+	TournamentItem
+	// Live is an event this process is running, which the page follows over
+	// /socket/tournament/<id>. A finished event read back from the archive has
+	// nothing to follow.
+	Live  bool
+	State proto.TournamentPayload
+	// The viewer's entry. CanJoin is false for an anonymous viewer, whom the
+	// page asks to log in instead.
+	LoggedIn  bool
+	Entered   bool
+	Withdrawn bool
+	CanJoin   bool
+	// Notice is the refusal from the last join attempt, if any.
+	Notice string
+}
+
+// TournamentControl is one time control the create form offers.
+type TournamentControl struct {
+	Value string // the variant's HTMLName
+	Label string
+}
+
+// TournamentList is the /tournament page.
+type TournamentList struct {
+	Events   []TournamentItem
+	Finished []TournamentItem
+	// Controls is set for a viewer who may create an event, nil otherwise.
+	Controls []TournamentControl
+	Notice   string
+}
+
+// tournamentURL is an event's page.
+func tournamentURL(id string) string {
+	return "/tournament/" + id
+}
+
+// tournamentStatusLabel says where an event is, for the list and the page
+// head.
+func tournamentStatusLabel(t TournamentItem) string {
+	switch t.Status {
+	case "created":
+		return "Starts " + t.StartsAt.UTC().Format("2 Jan 15:04") + " UTC"
+	case "started":
+		if t.Rounds > 0 {
+			return fmt.Sprintf("Round %d of %d", t.Round, t.Rounds)
+		}
+		return "In progress"
+	}
+	return "Finished"
+}
+
+// tournamentPlayersLabel counts an event's entrants.
+func tournamentPlayersLabel(n int) string {
+	if n == 1 {
+		return "1 player"
+	}
+	return strconv.Itoa(n) + " players"
+}
+
+// tournamentScore prints a score: Arena points are whole, Swiss runs in
+// halves.
+func tournamentScore(s float64) string {
+	return strconv.FormatFloat(s, 'f', -1, 64)
+}
+
+// tournamentTiebreak prints a tiebreak, blank before there is one.
+func tournamentTiebreak(tb float64) string {
+	if tb == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(tb, 'f', -1, 64)
+}
+
+// rowTitle rebuilds a standings row's title for the badge.
+func rowTitle(r proto.TournamentRow) title.Title {
+	return title.Title{Code: r.Title, Name: r.TitleName}
+}
@@ -0,0 +1,52 @@
+package view
+
+import (
+	"strconv"
+
+	"github.com/dechristopher/lio/puzzle"
+)
+
+// TrainingModel is the /training page: one puzzle, and the viewer's standing.
+type TrainingModel struct {
+	// Puzzle is nil when there is nothing to serve — no puzzles mined yet, or
+	// the viewer has attempted every one.
+	Puzzle *TrainingPuzzle
+	// Rating is the viewer's puzzle rating display, "" for an anonymous
+	// viewer, whose attempts are not rated.
+	Rating string
+}
+
+// TrainingPuzzle is a puzzle as the board first shows it. It is also the
+// #training-data payload lio-training.js boots from, so field names are the
+// wire's.
+type TrainingPuzzle struct {
+	ID    int64               `json:"id"`
+	OFEN  string              `json:"o"`
+	Turn  string              `json:"t"`
+	Dests map[string][]string `json:"v"`
+	// LastMove is the opponent's move that set the puzzle, from/to.
+	LastMove []string `json:"lm,omitempty"`
+	Theme    string   `json:"theme"`
+}
+
+// trainingPrompt is the instruction over the board.
+func trainingPrompt(p *TrainingPuzzle) string {
+	side := "White"
+	if p.Turn == "black" {
+		side = "Black"
+	}
+	if p.Theme == puzzle.ThemeMate {
+		return side + " to move. Find the mate."
+	}
+	return side + " to move. Find the winning move."
+}
+
+// trainingPermalink is a puzzle's own URL.
+func trainingPermalink(p *TrainingPuzzle) string {
+	return "/training/" + trainingID(p)
+}
+
+// trainingID is a puzzle's number as shown.
+func trainingID(p *TrainingPuzzle) string {
+	return strconv.FormatInt(p.ID, 10)
+}
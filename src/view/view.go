@@ -322,6 +322,8 @@ func pgnEventName(payload message.RoomTemplatePayload) string {
 		Rated:   payload.Rated,
 		RaceTo:  payload.RaceTo,
 		VsBot:   payload.WhiteIsBot || payload.BlackIsBot,
+
+		Tournament: payload.TournamentName,
 	}.EventName()
 }
 
@@ -470,6 +472,27 @@ func reportableOpponent(payload message.RoomTemplatePayload) string {
 	return ""
 }
 
+// chatChannel picks which of the room's two chats the viewer reads (package
+// chat): "player" for a seat facing another human, "spectator" for everybody
+// watching, and "" for a seat facing a bot, which has nobody to talk to.
+func chatChannel(payload message.RoomTemplatePayload) string {
+	if payload.IsSpectator {
+		return "spectator"
+	}
+	if payload.OpponentIsBot {
+		return ""
+	}
+	return "player"
+}
+
+// chatLabel names a room chat panel.
+func chatLabel(channel string) string {
+	if channel == "spectator" {
+		return "Spectator chat"
+	}
+	return "Chat"
+}
+
 // seatColorTitle resolves one seat's optional account display title by "w"/"b"
 // color from the payload. Zero for anonymous/bot seats, untitled accounts, and
 // (harmlessly) whenever seatColorLabel resolves to You/Anonymous/BOT — those
@@ -8,8 +8,11 @@ import (
 
 	"github.com/dechristopher/lio/auth"
 	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/mail"
 	"github.com/dechristopher/lio/role"
 	"github.com/dechristopher/lio/settings"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
 	"github.com/dechristopher/lio/www/middleware"
 )
 
@@ -45,8 +48,8 @@ func unavailable(c fiber.Ctx) error {
 
 // parseEmail normalizes and lightly validates an optional email, shared by
 // registration and the profile email edit. Empty → nil (unset / cleared).
-// There is no email infrastructure yet, so this is a shape check, not a
-// deliverability check.
+// This is a shape check only; whether the address is real and its owner's is
+// settled by the confirmation link sent to it (see sendVerification).
 func parseEmail(raw string) (*string, error) {
 	e := strings.TrimSpace(raw)
 	if e == "" {
@@ -59,6 +62,18 @@ func parseEmail(raw string) (*string, error) {
 	return &e, nil
 }
 
+// sendVerification queues the confirmation link for a just-set address. It
+// never fails the request that set the address: the settings page can send
+// the link again, so a failure here is only logged.
+func sendVerification(userID int64, username, addr string) {
+	if !mail.Enabled() {
+		return
+	}
+	if err := mail.SendVerification(userID, username, addr); err != nil {
+		util.With(util.AccountID(userID)).Error(str.CMail, "verification queue failed error=%s", err.Error())
+	}
+}
+
 // Wire attaches the auth API handlers to the given /api/auth router group.
 func Wire(g fiber.Router) {
 	g.Post("/register", RegisterHandler)
@@ -75,6 +90,9 @@ func Wire(g fiber.Router) {
 
 	// MFA: login-time second factor + management (arch Phase 4)
 	wireMFA(g)
+
+	// the account's own data: export it, or delete the account
+	wirePrivacy(g)
 }
 
 // RegisterHandler creates an account and logs the visitor in by upgrading
@@ -127,6 +145,9 @@ func RegisterHandler(c fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).
 			JSON(errBody{Error: "registration failed"})
 	}
+	if email != nil {
+		sendVerification(id, username, *email)
+	}
 
 	// a just-registered account holds no title and the default player role
 	// (both assigned later, by a moderator)
@@ -0,0 +1,189 @@
+package account
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/privacy"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// The account's own data (package privacy): download all of it, or delete the
+// account. Deletion re-authenticates harder than any other management action
+// — the password, and with MFA on, a second factor as well — because it is
+// the one that cannot be undone.
+
+// exportBudget bounds one export end to end; a long history is thousands of
+// PGN rebuilds.
+const exportBudget = 10 * time.Minute
+
+// exportLimiter caps exports per account. Each one reads the account's whole
+// archive, which is not a request to repeat on a loop.
+var exportLimiter = auth.NewLimiter(3, time.Hour)
+
+// wirePrivacy attaches the data export and account deletion routes to the
+// /api/auth group.
+func wirePrivacy(g fiber.Router) {
+	g.Get("/export", ExportHandler)
+	g.Post("/delete/passkey", DeletePasskeyHandler)
+	g.Post("/delete", DeleteHandler)
+}
+
+// deleteRequest is the account deletion form. Confirm is the username typed
+// out; Code is a TOTP or recovery code; Passkey is the assertion begun by
+// DeletePasskeyHandler. Either second factor will do.
+type deleteRequest struct {
+	Password string          `json:"password"`
+	Confirm  string          `json:"confirm"`
+	Code     string          `json:"code"`
+	Passkey  json.RawMessage `json:"passkey"`
+}
+
+// ExportHandler sends the account's data as a zip archive. The archive is
+// streamed as it is built, so a failure halfway leaves a truncated download
+// (logged here) rather than a complete-looking one.
+func ExportHandler(c fiber.Ctx) error {
+	sess, ok := authed(c)
+	if !ok {
+		return nil
+	}
+	userID := *sess.UserID
+	if !exportLimiter.Allow(strconv.FormatInt(userID, 10)) {
+		return c.Status(fiber.StatusTooManyRequests).
+			JSON(errBody{Error: "you downloaded your data several times already — try again in an hour"})
+	}
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Attachment("lio-" + sess.Username + "-" + time.Now().UTC().Format("2006-01-02") + ".zip")
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), exportBudget)
+		defer cancel()
+		if err := privacy.Export(ctx, w, userID); err != nil {
+			util.With(util.AccountID(userID)).Error(str.CPriv, "export failed error=%s", err.Error())
+			return
+		}
+		if err := w.Flush(); err != nil {
+			util.With(util.AccountID(userID)).Debug(str.CPriv, "export abandoned error=%s", err.Error())
+		}
+	})
+}
+
+// DeletePasskeyHandler starts the passkey assertion a deletion can carry as
+// its second factor (password re-verify, as every management action).
+func DeletePasskeyHandler(c fiber.Ctx) error {
+	sess, ok := authed(c)
+	if !ok {
+		return nil
+	}
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errBody{Error: "malformed request"})
+	}
+	if !checkPassword(*sess.UserID, req.Password) {
+		return wrongPassword(c)
+	}
+	options, err := auth.BeginWebAuthnLogin(delKey(sess), *sess.UserID, sess.Username)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errBody{Error: "could not start passkey check"})
+	}
+	return c.JSON(options)
+}
+
+// DeleteHandler deletes the account and ends every session it holds. The
+// password and the typed-out username are always required; an account with
+// MFA on must pass one of its factors too.
+func DeleteHandler(c fiber.Ctx) error {
+	sess, ok := authed(c)
+	if !ok {
+		return nil
+	}
+	userID := *sess.UserID
+	var req deleteRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errBody{Error: "malformed request"})
+	}
+	if !strings.EqualFold(strings.TrimSpace(req.Confirm), sess.Username) {
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(errBody{Error: "type your username to confirm"})
+	}
+	if !checkPassword(userID, req.Password) {
+		return wrongPassword(c)
+	}
+	if mfaEnabled(userID) {
+		passed, err := deleteSecondFactor(sess, req)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(errBody{Error: "could not delete the account"})
+		}
+		if !passed {
+			return badCode(c)
+		}
+	}
+
+	err := privacy.Delete(userID, sess.Username)
+	switch {
+	case errors.Is(err, privacy.ErrPlaying), errors.Is(err, privacy.ErrCorrespondence),
+		errors.Is(err, privacy.ErrTournament), errors.Is(err, privacy.ErrBanned),
+		errors.Is(err, privacy.ErrReported):
+		return c.Status(fiber.StatusConflict).JSON(errBody{Error: err.Error()})
+	case errors.Is(err, privacy.ErrNotFound):
+		// deleted by a second request racing this one: the outcome stands
+	case err != nil:
+		util.With(util.AccountID(userID)).Error(str.CPriv, "account delete failed error=%s", err.Error())
+		return c.Status(fiber.StatusInternalServerError).JSON(errBody{Error: "could not delete the account"})
+	}
+	// the session rows went with the account; this clears the cookie
+	_ = auth.LogoutAll(c, userID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// deleteSecondFactor checks whichever second factor the deletion carries: a
+// passkey assertion, a TOTP code, or a recovery code, in that order. A code
+// is tried as TOTP first, then as a recovery code, so the form needs a single
+// field for both.
+func deleteSecondFactor(sess *auth.Session, req deleteRequest) (bool, error) {
+	userID := *sess.UserID
+	if len(req.Passkey) > 0 {
+		credID, signCount, err := auth.FinishWebAuthnLogin(delKey(sess), userID, sess.Username, req.Passkey)
+		if err != nil {
+			return false, nil
+		}
+		_ = db.UpdateWebAuthnSignCount(userID, credID, signCount)
+		return true, nil
+	}
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		return false, nil
+	}
+	enc, confirmed, err := db.GetTOTP(userID)
+	if err != nil {
+		return false, err
+	}
+	if confirmed && enc != nil {
+		secret, err := auth.DecryptTOTPSecret(enc)
+		if err != nil {
+			return false, err
+		}
+		if auth.ConsumeTOTP(userID, secret, code) {
+			return true, nil
+		}
+	}
+	return db.UseRecoveryCode(userID, auth.HashRecoveryCode(code))
+}
+
+// delKey scopes a deletion's passkey challenge to the current session, apart
+// from a registration's (regKey).
+func delKey(sess *auth.Session) string {
+	return "del:" + strconv.FormatInt(sess.ID, 10)
+}
@@ -14,9 +14,10 @@ import (
 // setting/clearing the optional email, and the single allowed casing-only
 // username change — plus a GET that prefills the modal. All require a live
 // authenticated session (authed → 401), on top of the group rate limit and the
-// stateless CSRF guard. These deliberately do NOT re-verify the password: email
-// has no recovery value yet and a casing change is cosmetic (unlike the
-// password / MFA management endpoints, which do re-verify).
+// stateless CSRF guard. These deliberately do NOT re-verify the password: a
+// changed email is unconfirmed, so it cannot receive a reset link until its
+// owner follows the confirmation sent to it, and a casing change is cosmetic
+// (unlike the password / MFA management endpoints, which do re-verify).
 
 // wireProfile attaches the profile-edit routes to the /api/auth group.
 func wireProfile(g fiber.Router) {
@@ -30,6 +31,7 @@ func wireProfile(g fiber.Router) {
 type profileBody struct {
 	Username                string `json:"username"`
 	Email                   string `json:"email"`
+	EmailVerified           bool   `json:"emailVerified"`
 	UsernameChangeAvailable bool   `json:"usernameChangeAvailable"`
 }
 
@@ -53,13 +55,15 @@ func ProfileHandler(c fiber.Ctx) error {
 	return c.JSON(profileBody{
 		Username:                user.Username,
 		Email:                   email,
+		EmailVerified:           user.EmailVerified,
 		UsernameChangeAvailable: !user.UsernameChanged,
 	})
 }
 
 // EmailHandler sets, replaces, or clears the account email. An empty value
-// clears it (there is no email verification/recovery yet, so this is a plain
-// overwrite). Echoes back the stored value.
+// clears it. A new address starts unconfirmed and is sent its confirmation
+// link (see package mail); saving the current one again sends nothing. Echoes
+// back the stored value.
 func EmailHandler(c fiber.Ctx) error {
 	sess, ok := authed(c)
 	if !ok {
@@ -75,9 +79,16 @@ func EmailHandler(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(errBody{Error: err.Error()})
 	}
+	user, found, err := db.GetUserByID(*sess.UserID)
+	if err != nil || !found {
+		return c.Status(fiber.StatusInternalServerError).JSON(errBody{Error: "could not update email"})
+	}
 	if err := db.UpdateEmail(*sess.UserID, email); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(errBody{Error: "could not update email"})
 	}
+	if email != nil && (user.Email == nil || *user.Email != *email) {
+		sendVerification(user.ID, user.Username, *email)
+	}
 	out := ""
 	if email != nil {
 		out = *email
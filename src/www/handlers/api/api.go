@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 
 	"github.com/dechristopher/lio/www/handlers/api/account"
+	"github.com/dechristopher/lio/www/handlers/api/block"
 	"github.com/dechristopher/lio/www/handlers/api/card"
 	"github.com/dechristopher/lio/www/handlers/api/feedback"
 	"github.com/dechristopher/lio/www/handlers/api/follow"
@@ -40,6 +41,10 @@ func Wire(a fiber.Router) {
 	// needs no role check either.
 	follow.Wire(a.Group("/follow", middleware.AuthAPILimiter()))
 
+	// blocks (db/blocks.go), scoped to the session like the follow graph: the
+	// blocker is always the caller.
+	block.Wire(a.Group("/block", middleware.AuthAPILimiter()))
+
 	// site feedback, reachable by any logged-in visitor. Its own limiter rather
 	// than the auth one: this is a burst bound on a single deliberate action
 	// (nobody sends feedback twice a second), and the per-account rolling cap
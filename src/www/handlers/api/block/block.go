@@ -0,0 +1,92 @@
+// Package block holds the HTTP surface of blocks (db/blocks.go): blocking an
+// account, and lifting the block.
+//
+// Like follow, the writes are scoped to the session: the blocker is always the
+// account that made the request, and the client names only the other end, by
+// username. There is no public read. Whom somebody blocked is theirs alone,
+// and the one list of it is their own account page (/account/blocks).
+package block
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/home"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/user"
+	"github.com/dechristopher/lio/util"
+)
+
+type errBody struct {
+	Error string `json:"error"`
+}
+
+// stateResponse is the resulting state of the button that was pressed.
+type stateResponse struct {
+	Blocking bool `json:"blocking"`
+}
+
+// Wire attaches the block endpoints to the given group.
+func Wire(g fiber.Router) {
+	g.Post("/:username", Handler)
+	g.Delete("/:username", Handler)
+}
+
+// Handler blocks or unblocks an account, by HTTP method: POST blocks, DELETE
+// lifts the block. Both are idempotent, as follow's are.
+//
+// A banned account can still be blocked. Unlike a follow, a block is not a
+// statement about what the target publishes, and a ban that expires should
+// not bring back somebody the caller had already shut out.
+func Handler(c fiber.Ctx) error {
+	if !auth.Enabled() {
+		return c.Status(fiber.StatusServiceUnavailable).
+			JSON(errBody{Error: "blocking is unavailable in this environment"})
+	}
+	acct := user.GetAccount(c)
+	if acct == nil {
+		return c.Status(fiber.StatusUnauthorized).
+			JSON(errBody{Error: "log in to block players"})
+	}
+
+	target, found, err := db.GetUserByUsername(strings.TrimSpace(c.Params("username")))
+	if err != nil {
+		util.Error(str.CDB, "block lookup failed error=%s", err.Error())
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(errBody{Error: "could not reach that account"})
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(errBody{Error: "no such account"})
+	}
+	if target.ID == acct.ID {
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(errBody{Error: "you cannot block yourself"})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	blocking := c.Method() == fiber.MethodPost
+	if blocking {
+		if _, err = db.Block(acct.ID, target.ID); err == nil {
+			// the follows between them went with the block; the sockets the
+			// two have open still carry them until the hub is told
+			home.Block(acct.ID, target.ID)
+		}
+	} else {
+		_, err = db.Unblock(acct.ID, target.ID)
+	}
+	switch {
+	case errors.Is(err, db.ErrBlockLimit):
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(errBody{Error: "you are blocking as many players as one account can"})
+	case err != nil:
+		util.Error(str.CDB, "block write failed error=%s", err.Error())
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(errBody{Error: "could not save that"})
+	}
+	return c.JSON(stateResponse{Blocking: blocking})
+}
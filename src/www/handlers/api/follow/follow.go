@@ -387,6 +387,9 @@ func Handler(c fiber.Ctx) error {
 	case errors.Is(err, db.ErrFollowLimit):
 		return c.Status(fiber.StatusUnprocessableEntity).
 			JSON(errBody{Error: "you are following as many players as one account can"})
+	case errors.Is(err, db.ErrBlocked):
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(errBody{Error: "you cannot follow this player"})
 	case err != nil:
 		util.Error(str.CDB, "follow write failed error=%s", err.Error())
 		return c.Status(fiber.StatusInternalServerError).
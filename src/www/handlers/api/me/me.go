@@ -21,6 +21,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 
 	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/cluster"
 	"github.com/dechristopher/lio/db"
 	"github.com/dechristopher/lio/notify"
 	"github.com/dechristopher/lio/room"
@@ -340,8 +341,11 @@ func DeclineChallengeHandler(c fiber.Ctx) error {
 		}
 	}
 
-	instance, err := room.Get(req.Room)
-	if err != nil || instance == nil {
+	instance, forwarded, err := cluster.Room(c, req.Room)
+	if forwarded {
+		return err
+	}
+	if instance == nil {
 		// already gone: the refusal has nothing left to do, and saying so would
 		// only report a race the person cannot act on
 		return countAfterWrite(c, acct)
@@ -351,6 +355,15 @@ func DeclineChallengeHandler(c fiber.Ctx) error {
 			JSON(errBody{Error: "that challenge is not yours to decline"})
 	}
 
+	RefuseChallenge(instance)
+	return countAfterWrite(c, acct)
+}
+
+// RefuseChallenge sends a waiting challenger home with the declined notice and
+// closes the room. The caller has already checked the refusal is the invited
+// account's to make. Exported for the bot API, whose programs decline a
+// challenge through it, so the notice key is still written in one place.
+func RefuseChallenge(instance *room.Instance) {
 	// Tell the challenger before the room goes: they are sitting on the waiting
 	// page, and closing the room underneath them would otherwise send them home
 	// with the generic "that room is gone" — which reads like a fault rather
@@ -364,7 +377,6 @@ func DeclineChallengeHandler(c fiber.Ctx) error {
 	// race where the challenger cancelled or somebody already joined. Nothing to
 	// report: the invitation is over either way.
 	instance.Cancel()
-	return countAfterWrite(c, acct)
 }
 
 // countAfterWrite answers with the new unread count and pushes that same count
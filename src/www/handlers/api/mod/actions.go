@@ -66,9 +66,20 @@ func bind(c fiber.Ctx, need role.Role) (*auth.Session, db.UserRecord, banRequest
 // logAction records the action, reporting (but not failing on) a log error.
 func logAction(sess *auth.Session, targetID int64, action string,
 	detail map[string]any, reason string) {
-	if err := db.LogModAction(*sess.UserID, &targetID, action, detail, reason); err != nil {
+	logActionOn(sess, &targetID, action, detail, reason)
+}
+
+// logActionOn is logAction for a target that may no longer exist: nil records
+// the entry against no account.
+func logActionOn(sess *auth.Session, targetID *int64, action string,
+	detail map[string]any, reason string) {
+	if err := db.LogModAction(*sess.UserID, targetID, action, detail, reason); err != nil {
+		target := int64(0)
+		if targetID != nil {
+			target = *targetID
+		}
 		util.Error(str.CDB, "mod action log failed action=%s target=%d error=%s",
-			action, targetID, err.Error())
+			action, target, err.Error())
 	}
 }
 
@@ -129,8 +140,8 @@ func BanHandler(c fiber.Ctx) error {
 	}
 
 	if err := db.DeleteSessionsForUser(rec.ID); err != nil {
-		util.Error(str.CAuth, "ban session revoke failed user=%d error=%s",
-			rec.ID, err.Error())
+		util.With(util.AccountID(rec.ID)).Error(str.CAuth, "ban session revoke failed error=%s",
+			err.Error())
 	}
 	auth.DropUserSessions(rec.ID)
 	forfeited := room.ForfeitUser(rec.ID)
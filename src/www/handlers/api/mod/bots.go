@@ -0,0 +1,70 @@
+package mod
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/role"
+)
+
+// BotHandler flags an account as a bot: an engine plays through it from now
+// on, under the BOT title, in the bot rating pools. Admin-only, like a role
+// change, because it decides who an account's opponents are told they face.
+//
+// Staff accounts are refused. A bot's program holds a long-lived token, and a
+// credential that sits in a config file on somebody's engine box should never
+// be one step from the moderation tools.
+func BotHandler(c fiber.Ctx) error {
+	sess, rec, req, ok := bind(c, role.Admin)
+	if !ok {
+		return nil
+	}
+	if rec.Role.CanModerate() {
+		return c.Status(fiber.StatusConflict).
+			JSON(errBody{Error: "staff accounts cannot be bots — change the role first"})
+	}
+	if db.IsBot(rec.ID) {
+		return c.Status(fiber.StatusConflict).
+			JSON(errBody{Error: "that account is already a bot"})
+	}
+
+	if err := db.FlagBot(rec.ID, config.GenerateCode(16, config.Base58)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(errBody{Error: "could not flag the account"})
+	}
+	// their sessions carry the old title
+	auth.DropUserSessions(rec.ID)
+
+	logAction(sess, rec.ID, "bot", nil, req.Reason)
+	notifyTarget(rec.ID, "Your account is now a bot account. Issue its token from the bot page.",
+		"/bot")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnbotHandler returns a bot to an ordinary account. Its token stops resolving
+// with the row it lived in, and the cached copy is evicted so the program is
+// refused on its next request rather than a cache TTL later. Games already
+// rated in the bot pools stay there.
+func UnbotHandler(c fiber.Ctx) error {
+	sess, rec, req, ok := bind(c, role.Admin)
+	if !ok {
+		return nil
+	}
+
+	was, err := db.UnflagBot(rec.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(errBody{Error: "could not unflag the account"})
+	}
+	if !was {
+		return c.Status(fiber.StatusConflict).
+			JSON(errBody{Error: "that account is not a bot"})
+	}
+	auth.DropUserSessions(rec.ID)
+
+	logAction(sess, rec.ID, "unbot", nil, req.Reason)
+	notifyTarget(rec.ID, "Your account is no longer a bot account.", "")
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package mod
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/fairplay"
+	"github.com/dechristopher/lio/role"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Working the fair-play list on /moderation (package fairplay). A flagged
+// account is one whose rated play tracks the engine closely enough to be worth
+// a look — no more. The two outcomes here are a moderator's: open a cheating
+// report, which moves the account into the ordinary queue with the evidence
+// attached, or dismiss the flag as nothing. Sanctions stay on the player page,
+// as they do for every report.
+
+// FairPlayReportHandler files a cheating report against a flagged account
+// under the moderator's name, with the fair-play figures appended to their
+// note, and takes the account off the list: it is in the queue now.
+func FairPlayReportHandler(c fiber.Ctx) error {
+	sess, rec, req, ok := bind(c, role.Mod)
+	if !ok {
+		return nil
+	}
+
+	samples, err := db.FairPlaySamples(rec.ID, fairplay.Window)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(errBody{Error: "could not load the fair-play evidence"})
+	}
+	score := fairplay.Roll(samples)
+	note := req.Reason + "\n\n" + fairPlaySummary(score)
+
+	err = db.FileReport(*sess.UserID, rec.ID, nil, "cheating", note)
+	if errors.Is(err, db.ErrAlreadyReported) {
+		return c.Status(fiber.StatusConflict).
+			JSON(errBody{Error: "you already have an open report against this account"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(errBody{Error: "could not file the report"})
+	}
+	if _, err := db.DismissFairPlayFlag(rec.ID, *sess.UserID); err != nil {
+		// the report is filed; the account lingering on the list is cosmetic
+		util.With(util.AccountID(rec.ID)).Error(str.CDB, "fair-play dismiss failed error=%s", err.Error())
+	}
+
+	logAction(sess, rec.ID, "fairplay", map[string]any{
+		"outcome": "reported",
+		"score":   fmt.Sprintf("%.1f", score.Score),
+	}, req.Reason)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// FairPlayDismissHandler clears a flagged account from the list. It comes back
+// only if its score climbs fairplay.FlagMargin past where it was dismissed.
+func FairPlayDismissHandler(c fiber.Ctx) error {
+	sess, rec, req, ok := bind(c, role.Mod)
+	if !ok {
+		return nil
+	}
+	found, err := db.DismissFairPlayFlag(rec.ID, *sess.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(errBody{Error: "could not dismiss the flag"})
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).
+			JSON(errBody{Error: "that account has no fair-play score"})
+	}
+	logAction(sess, rec.ID, "fairplay", map[string]any{
+		"outcome": "dismissed",
+	}, req.Reason)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// fairPlaySummary renders a score's figures for a report note, in words a
+// moderator reading the queue understands without the fair-play page open.
+func fairPlaySummary(s db.FairPlayScore) string {
+	out := fmt.Sprintf("Fair play: score %.1f over %d rated games. "+
+		"Engine's move %.0f%% of the time, a top move %.0f%%, average loss %.0f cp.",
+		s.Score, s.Games, 100*s.MatchRate, 100*s.TopRate, s.ACPL)
+	if s.ThinkCV != nil {
+		out += fmt.Sprintf(" Think-time variation %.2f.", *s.ThinkCV)
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package mod
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dechristopher/lio/db"
+)
+
+// TestFairPlaySummary: the note appended to a fair-play report carries the
+// figures in words, and the think-time variation only when there is one.
+func TestFairPlaySummary(t *testing.T) {
+	s := db.FairPlayScore{Score: 81.25, Games: 12, MatchRate: 0.9, TopRate: 0.96, ACPL: 7.4}
+	got := fairPlaySummary(s)
+	for _, want := range []string{"score 81.2", "12 rated games", "90%", "96%", "7 cp"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary %q missing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "Think-time") {
+		t.Errorf("summary %q describes think times it does not have", got)
+	}
+
+	cv := float32(0.15)
+	s.ThinkCV = &cv
+	if got := fairPlaySummary(s); !strings.Contains(got, "Think-time variation 0.15") {
+		t.Errorf("summary %q missing the think-time variation", got)
+	}
+}
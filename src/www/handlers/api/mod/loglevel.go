@@ -0,0 +1,83 @@
+package mod
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/role"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// Runtime log levels, from the server log card on /system/log.
+//
+// Admin-only, like the site controls it sits beside: raising a busy package to
+// debug changes what every room on the instance writes. It is also
+// per-instance and lasts until restart, the same as the --debug flag it
+// overrides. There is deliberately no persisted copy: a level left at debug by
+// a forgotten incident should not survive the next deploy.
+//
+// Logged to mod_actions as a "setting" with a NULL target, because to anyone
+// reading the audit log later it is one: a switch that changed how the site
+// behaved, with a before and an after.
+
+type logLevelRequest struct {
+	Package string `json:"package"`
+	Level   string `json:"level"`
+	Reason  string `json:"reason"`
+}
+
+// LogLevelHandler sets one package's log level on this instance.
+func LogLevelHandler(c fiber.Ctx) error {
+	sess, ok := actor(c, role.Admin)
+	if !ok {
+		return nil
+	}
+
+	var req logLevelRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).
+			JSON(errBody{Error: "malformed request"})
+	}
+	reason, ok := reasonOf(c, req.Reason)
+	if !ok {
+		return nil
+	}
+	pkg := strings.TrimSpace(req.Package)
+	if !knownPackage(pkg) {
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(errBody{Error: "unknown package"})
+	}
+	level, ok := util.ParseLevel(req.Level)
+	if !ok {
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(errBody{Error: "level must be debug, info or error"})
+	}
+
+	was := util.Level(pkg)
+	util.SetLevel(pkg, level)
+	util.Info(str.CLog, "log level of %s set %s -> %s", pkg, util.LevelName(was), util.LevelName(level))
+
+	if err := db.LogModAction(*sess.UserID, nil, "setting", map[string]any{
+		"logPackage": pkg,
+		"from":       util.LevelName(was),
+		"to":         util.LevelName(level),
+	}, reason); err != nil {
+		util.Error(str.CDB, "log level audit log failed error=%s", err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// knownPackage restricts a level change to the packages the log page offers,
+// so a typo cannot leave a level set on a name nothing logs under.
+func knownPackage(pkg string) bool {
+	for _, known := range util.Packages() {
+		if pkg == known {
+			return true
+		}
+	}
+	return false
+}
@@ -42,10 +42,16 @@ func Wire(g fiber.Router) {
 	g.Post("/title", TitleHandler)
 	g.Post("/role", RoleHandler)
 	g.Post("/rename", RenameHandler)
+	g.Post("/bot", BotHandler)
+	g.Post("/unbot", UnbotHandler)
 
 	// report queue (see reports.go)
 	g.Post("/report/resolve", ResolveReportHandler)
 
+	// the fair-play list on /moderation (see fairplay.go)
+	g.Post("/fairplay/report", FairPlayReportHandler)
+	g.Post("/fairplay/dismiss", FairPlayDismissHandler)
+
 	// feedback inbox (see feedback.go). The unread count is a GET because it is
 	// polled by every moderator's open page to keep the badge current.
 	g.Get("/feedback/unread", UnreadFeedbackHandler)
@@ -68,6 +74,9 @@ func Wire(g fiber.Router) {
 	// runtime site controls (admin-only; see settings.go)
 	g.Get("/settings", SettingsHandler)
 	g.Post("/settings", UpdateSettingsHandler)
+
+	// per-package log levels on this instance (admin-only; see loglevel.go)
+	g.Post("/log-level", LogLevelHandler)
 }
 
 // actor is the resolved caller of a moderation route: their session plus the
@@ -172,8 +181,8 @@ func isSelf(sess *auth.Session, userID int64) bool {
 func grantedBy(sess *auth.Session, userID int64) bool {
 	grantor, ok, err := db.AdminGrantor(userID)
 	if err != nil {
-		util.Error(str.CDB, "admin grantor lookup failed user=%d error=%s",
-			userID, err.Error())
+		util.With(util.AccountID(userID)).Error(str.CDB, "admin grantor lookup failed error=%s",
+			err.Error())
 		return false
 	}
 	return ok && sess.UserID != nil && grantor == *sess.UserID
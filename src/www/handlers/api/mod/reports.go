@@ -58,8 +58,9 @@ func ResolveReportHandler(c fiber.Ctx) error {
 	// Logged against the reported account, so the decision shows up in that
 	// account's history alongside any sanction that followed it — a moderator
 	// reading a player page can see both that a report was made and what was
-	// concluded, without going to the queue.
-	logAction(sess, targetID, "report", map[string]any{
+	// concluded, without going to the queue. An account deleted since has no
+	// history to add to, and the entry names no one.
+	logActionOn(sess, targetID, "report", map[string]any{
 		"report": strconv.FormatInt(req.ID, 10),
 	}, resolution)
 
@@ -62,8 +62,8 @@ func CloseRoomHandler(c fiber.Ctx) error {
 	if err := db.LogModAction(*sess.UserID, nil, "room", map[string]any{
 		"room": roomID,
 	}, reason); err != nil {
-		util.Error(str.CDB, "room close audit log failed room=%s error=%s",
-			roomID, err.Error())
+		util.With(util.RoomID(roomID)).Error(str.CDB, "room close audit log failed error=%s",
+			err.Error())
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
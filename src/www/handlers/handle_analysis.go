@@ -8,7 +8,8 @@ import (
 	"github.com/gofiber/fiber/v3"
 
 	"github.com/dechristopher/lio/db"
-	"github.com/dechristopher/lio/engine"
+	"github.com/dechristopher/lio/dispatch"
+	"github.com/dechristopher/lio/tablebase"
 )
 
 // POST /api/analysis — the exploration seam behind the study-style analysis
@@ -62,8 +63,15 @@ type analysisResponse struct {
 	// annotation as a real game end. Empty while the position is playable.
 	Reason string `json:"rr,omitempty"`
 	// CP is the position's white-positive centipawn eval (cache read-through,
-	// else a budgeted live search; exact for terminal positions).
+	// else a budgeted live search; exact for terminal and tablebase positions).
 	CP int16 `json:"cp"`
+	// Mate is the forced mate the endgame tablebase found, in white-positive
+	// full moves: 3 is "white mates in 3", -2 "black mates in 2". Omitted
+	// when there is none.
+	Mate int16 `json:"mate,omitempty"`
+	// TB marks CP (and Mate) as the tablebase's exact result rather than a
+	// search estimate — a known draw reads CP 0 with TB set.
+	TB bool `json:"tb,omitempty"`
 }
 
 // AnalysisHandler applies/evaluates one explored position (see package
@@ -129,7 +137,7 @@ func AnalysisHandler(c fiber.Ctx) error {
 		}
 	}
 
-	resp.CP = analysisEval(resp.OFEN, pos, resp.Over)
+	resp.CP, resp.Mate, resp.TB = analysisEval(resp.OFEN, pos, resp.Over)
 	return c.JSON(resp)
 }
 
@@ -156,31 +164,42 @@ func reasonFromMethod(m octad.Method) string {
 }
 
 // analysisEval scores a position white-positive in centipawns: exact for
-// terminal positions, else the cached eval by position hash, else a budgeted
-// live engine search (nil history — a bare explored position has no game line
-// for repetition scoring).
-func analysisEval(ofen string, pos *octad.Position, over string) int16 {
+// terminal positions, then the endgame tablebase's exact result (with its
+// white-positive moves to mate), else the cached eval by position hash, else a
+// budgeted live engine search (nil history — a bare explored position has no
+// game line for repetition scoring). The last result reports a tablebase hit.
+func analysisEval(ofen string, pos *octad.Position, over string) (int16, int16, bool) {
 	switch over {
 	case "w":
-		return analysisEvalCap
+		return analysisEvalCap, 0, false
 	case "b":
-		return -analysisEvalCap
+		return -analysisEvalCap, 0, false
 	case "d":
-		return 0
+		return 0, 0, false
+	}
+
+	if r, ok := tablebase.Probe(pos); ok {
+		r = r.White(pos.Turn())
+		return analysisEvalCap * int16(r.WDL), int16(r.MateIn()), true
 	}
 
 	hash := pos.Hash()
 	if cached := db.CachedEvalByHash(hash[:]); cached != nil {
-		return *cached
+		return *cached, 0, false
 	}
 
-	me := engine.Search(ofen, nil, analysisDepth, analysisBudget, engine.MinimaxAB)
+	me := dispatch.Search(dispatch.SearchRequest{
+		Kind:   "board",
+		OFEN:   ofen,
+		Depth:  analysisDepth,
+		Budget: analysisBudget,
+	})
 	cp := me.Eval * analysisCentiUnit
 	if cp > analysisEvalCap {
-		return analysisEvalCap
+		return analysisEvalCap, 0, false
 	}
 	if cp < -analysisEvalCap {
-		return -analysisEvalCap
+		return -analysisEvalCap, 0, false
 	}
-	return int16(cp)
+	return int16(cp), 0, false
 }
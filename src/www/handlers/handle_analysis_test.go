@@ -119,6 +119,33 @@ func TestAnalysisTerminalReason(t *testing.T) {
 	}
 }
 
+// TestAnalysisTablebase covers solved endgames: a won rook ending reports its
+// exact forced mate (white-positive, so negative when black mates), and a
+// blockaded pawn ending its draw, both marked as tablebase results.
+func TestAnalysisTablebase(t *testing.T) {
+	app := analysisTestApp()
+	cases := []struct {
+		name string
+		ofen string
+		cp   int16
+		mate int16
+	}{
+		{"white mates", "3k/4/4/KR2 w - - 0 1", analysisEvalCap, 4},
+		{"black mates", "kr2/4/4/3K b - - 0 1", -analysisEvalCap, -4},
+		{"blockade draw", "4/1k2/1P2/1K2 w - - 0 1", 0, 0},
+	}
+	for _, tc := range cases {
+		status, out := postAnalysis(t, app, `{"ofen":"`+tc.ofen+`"}`)
+		if status != fiber.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", tc.name, status)
+		}
+		if !out.TB || out.CP != tc.cp || out.Mate != tc.mate {
+			t.Errorf("%s: tb=%v cp=%d mate=%d, want tb cp=%d mate=%d",
+				tc.name, out.TB, out.CP, out.Mate, tc.cp, tc.mate)
+		}
+	}
+}
+
 // TestAnalysisRejections covers the failure modes: malformed request, invalid
 // position, malformed and illegal moves.
 func TestAnalysisRejections(t *testing.T) {
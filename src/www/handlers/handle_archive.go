@@ -6,7 +6,6 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 
-	"github.com/dechristopher/lio/config"
 	"github.com/dechristopher/lio/db"
 	"github.com/dechristopher/lio/db/gen"
 	"github.com/dechristopher/lio/game"
@@ -166,9 +165,14 @@ func ArchiveGameEvalsHandler(c fiber.Ctx) error {
 		return fiber.ErrNotFound
 	}
 	c.Set(fiber.HeaderCacheControl, "no-store")
+	evals := db.ListGameMoveEvals(g.ID)
+	// a finished post-game review evaluated every ply: prefer it to the cache
+	if an := gameAnalysis(g); an != nil && an.State == "done" {
+		evals = an.Evals
+	}
 	return c.JSON(struct {
 		Evals []*int16 `json:"ev,omitempty"`
-	}{Evals: db.ListGameMoveEvals(g.ID)})
+	}{Evals: evals})
 }
 
 // loadRoomGames fetches a room's archived games in match order, reporting
@@ -466,54 +470,11 @@ func boardPayload(g gen.Game, og *game.OctadGame) proto.MovePayload {
 	return payload
 }
 
-// archivePGN rebuilds a finished game's canonical PGN from its archived row, via
-// the same game.BuildPGN the live archival path uses — so the copy button (which
-// copies this) yields byte-for-byte what was archived, without fetching the
-// stored object. Per-ply %clk comments come from the moves table; a game
-// archived before timing was recorded (or with Postgres unavailable) emits plain
-// movetext. The opening/matchup names derive purely from the starting OFEN.
+// archivePGN rebuilds a finished game's canonical PGN from its archived row
+// (see db.ArchivedPGN, shared with the monthly database dumps) — so the copy
+// button (which copies this) yields byte-for-byte what was archived.
 func archivePGN(g gen.Game, og *game.OctadGame) string {
-	times, err := db.ListGameMoveTimes(g.ID)
-	if err != nil || len(times) != len(og.Moves()) {
-		times = nil
-	}
-	white, black, matchup, _ := opening.Names(g.StartingOfen)
-	persona := derefStr(g.BotPersona)
-	return game.BuildPGN(game.PGNMeta{
-		Site:           config.SiteOrigin(),
-		Variant:        g.VariantName,
-		Group:          g.VariantGroup,
-		White:          archiveSeatName(g.WhiteUid, g.WhiteUserID, persona),
-		Black:          archiveSeatName(g.BlackUid, g.BlackUserID, persona),
-		WhiteUID:       g.WhiteUid,
-		BlackUID:       g.BlackUid,
-		Result:         g.Outcome,
-		Reason:         g.Reason,
-		Start:          g.StartTs.Time,
-		End:            g.EndTs.Time,
-		StartOFEN:      g.StartingOfen,
-		WhiteFormation: white,
-		BlackFormation: black,
-		Matchup:        matchup,
-		// Event-tag situation inputs, straight off the row (the live path
-		// derives the same three from its archive record)
-		Rated:  g.Rated,
-		RaceTo: int(g.RaceTo),
-		VsBot:  isBotSeat(g.WhiteUid, g.WhiteUserID) || isBotSeat(g.BlackUid, g.BlackUserID),
-	}, &og.Game, times)
-}
-
-// archiveSeatName reproduces room.seatArchiveName from the archived row so the
-// rebuilt PGN's White/Black tags match what the live path wrote: "[BOT] <glyph>
-// <persona>" for the engine seat (no uid and no account; persona is the row's
-// bot_persona, NULL/"" resolving to the Queen), "[<title>] <username>" /
-// "<username>" for a logged-in human, else "Anonymous".
-func archiveSeatName(uid string, userID *int64, personaKey string) string {
-	if isBotSeat(uid, userID) {
-		return game.PGNSeatName("", "", view.BotSeatGlyph(personaKey), view.BotSeatLabel(personaKey), true)
-	}
-	name, seatTitle := db.UserDisplayForID(userID)
-	return game.PGNSeatName(name, seatTitle.Code, "", "", false)
+	return db.ArchivedPGN(g, &og.Game)
 }
 
 // buildArchiveData assembles the client hydration payload for the selected
@@ -542,6 +503,17 @@ func buildArchiveData(games []gen.Game, selected gen.Game, og *game.OctadGame) v
 		data.Board.Evals = evals
 	}
 
+	// the game's post-game review, when one was requested. A finished review
+	// evaluated every ply, so its evals replace the cache's (possibly sparse)
+	// ones on the bar, and ride there rather than twice in the payload.
+	if an := gameAnalysis(selected); an != nil {
+		if an.State == "done" && len(an.Evals) == len(data.Board.Moves) {
+			data.Board.Evals = an.Evals
+			an.Evals = nil
+		}
+		data.Analysis = an
+	}
+
 	if selected.RoomID == "" {
 		// standalone game: no match context to score
 		data.N = 1
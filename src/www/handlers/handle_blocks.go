@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/view"
+)
+
+// The /account/blocks page (see db/blocks.go): the accounts the signed-in
+// account has blocked, and undoing one. Blocking itself is the player page's
+// button, through /api/block.
+
+// BlocksHandler renders the viewer's block list.
+func BlocksHandler(c fiber.Ctx) error {
+	acct := signedInAccount(c)
+	if acct == nil {
+		return redirect(c, "/login")
+	}
+	m := view.BlocksModel{Notice: c.Query("notice")}
+	blocks, err := db.ListBlocks(acct.ID)
+	if err != nil {
+		util.With(util.AccountID(acct.ID)).Error(str.CDB, "block list failed error=%s", err.Error())
+		m.Notice = "Could not load your blocks. Try again."
+	}
+	for _, b := range blocks {
+		m.Blocks = append(m.Blocks, view.BlockedView{
+			Username: b.Username,
+			Title:    b.Title,
+			Since:    b.Since,
+		})
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	meta := view.PageMeta("Blocked players")
+	meta.Description = "The players you have blocked."
+	return view.Render(c, fiber.StatusOK, view.Blocks(meta, m))
+}
+
+// UnblockHandler lifts the viewer's block of one account. An account that was
+// not blocked, or no longer exists, is already in the state asked for.
+func UnblockHandler(c fiber.Ctx) error {
+	acct := signedInAccount(c)
+	if acct == nil {
+		return redirect(c, "/login")
+	}
+	target, found, err := db.GetUserByUsername(strings.TrimSpace(c.Params("username")))
+	if err == nil && found {
+		_, err = db.Unblock(acct.ID, target.ID)
+	}
+	if err != nil {
+		util.With(util.AccountID(acct.ID)).Error(str.CDB, "unblock failed error=%s", err.Error())
+		return redirect(c, "/account/blocks?notice="+url.QueryEscape("Could not unblock. Try again."))
+	}
+	return redirect(c, "/account/blocks")
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/cluster"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/player"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/settings"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/user"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/view"
+	"github.com/dechristopher/lio/www/handlers/api/me"
+)
+
+// The bot API: what an external engine needs, beyond the socket, to play
+// through a bot account (an account an admin has flagged; see auth/bot.go for
+// how its program authenticates). It is deliberately small. A bot does not
+// seek games — people challenge it from its profile like anyone else — so the
+// API is the challenge inbox and the two answers to a challenge; everything
+// after acceptance is the socket protocol the board already speaks.
+//
+// Every request carries "Authorization: Bearer <token>", the socket upgrade
+// included. The protocol on /socket/<room>, as a bot meets it:
+//
+//   - Frames are JSON, {"t": tag, "d": data}.
+//   - {"t":"m","d":{"a":0}} asks for the current state; the server answers
+//     with a state frame (tag "m"): "o" the position (OFEN), "v" the legal
+//     moves in UOI keyed by square, "c" the clock (centiseconds), "w"/"b" the
+//     seats' uids — compare with /api/bot/account's uid to learn your color —
+//     and "s"/"m" the move history.
+//   - A move is {"t":"m","d":{"u":"c2c3","a":<ply>}}, any non-zero "a".
+//   - In a deploy game the opening is {"t":"d","d":{"o":"knpp"}}: the four
+//     home-rank pieces, left to right from the player's side.
+//   - "g" is game over; {"t":"r","d":{"rs":true}} resigns, {"dr":true} offers
+//     or accepts a draw, and {"rm":true} offers a rematch.
+//
+// The bot plays one game at a time, like a person (arch/ONE_GAME_AT_A_TIME.md),
+// and its games are rated in the bot pools (pools.BotCategory).
+
+// noticeBotOnly is the refusal for a request that is not a bot's.
+const noticeBotOnly = "a bot token is required"
+
+// botChallenge is one open challenge in the bot's inbox.
+type botChallenge struct {
+	Room       string `json:"room"`
+	Challenger string `json:"challenger"`
+	Variant    string `json:"variant"`
+	Rated      bool   `json:"rated"`
+	// Color is the seat the bot would take.
+	Color   string    `json:"color"`
+	Expires time.Time `json:"expires"`
+}
+
+// botAccount resolves the bot behind the request, or writes the refusal and
+// reports false. Only a bearer token makes an account a bot here: the owner
+// browsing with a cookie is a person, and gets no part of this API.
+func botAccount(c fiber.Ctx) (*user.Account, bool) {
+	if !auth.Enabled() {
+		_ = c.Status(fiber.StatusServiceUnavailable).
+			JSON(fiber.Map{"error": "accounts are not available"})
+		return nil, false
+	}
+	acct := user.GetAccount(c)
+	if acct == nil || !acct.Bot {
+		_ = c.Status(fiber.StatusUnauthorized).
+			JSON(fiber.Map{"error": noticeBotOnly})
+		return nil, false
+	}
+	return acct, true
+}
+
+// BotAccountHandler describes the bot to its own program: chiefly its uid,
+// which is how it finds its color in a state frame.
+func BotAccountHandler(c fiber.Ctx) error {
+	acct, ok := botAccount(c)
+	if !ok {
+		return nil
+	}
+	return c.JSON(fiber.Map{
+		"id":       acct.ID,
+		"username": acct.Username,
+		"uid":      user.GetID(c),
+		"title":    acct.Title.Code,
+	})
+}
+
+// BotChallengesHandler lists the challenges the bot can still accept. The
+// notification rows are the inbox, and the room is the authority on each, as
+// it is for a person's Accept: a row whose room has gone, or started, or was
+// never the bot's to take, is left out.
+func BotChallengesHandler(c fiber.Ctx) error {
+	acct, ok := botAccount(c)
+	if !ok {
+		return nil
+	}
+	rows, err := db.ListNotifications(acct.ID, botInboxLimit)
+	if err != nil {
+		util.With(util.AccountID(acct.ID)).Error(str.CNotif, "bot challenge list failed error=%s",
+			err.Error())
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(fiber.Map{"error": "could not read challenges"})
+	}
+
+	now := time.Now()
+	out := make([]botChallenge, 0)
+	for _, n := range rows {
+		if n.Kind != db.KindChallenge || !n.Unread() || (!n.Expires.IsZero() && now.After(n.Expires)) {
+			continue
+		}
+		r := openChallengeFor(strings.TrimPrefix(n.Link, "/"), acct.ID)
+		if r == nil {
+			continue
+		}
+		out = append(out, botChallenge{
+			Room:       r.ID,
+			Challenger: n.Actor,
+			Variant:    r.Variant().HTMLName,
+			Rated:      r.IsRated(),
+			Color:      r.OpenSeatColor().String(),
+			Expires:    n.Expires,
+		})
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"challenges": out})
+}
+
+// botInboxLimit bounds the notification rows scanned for challenges. Nobody
+// holds more live challenges than this: each expires within
+// room.ChallengeExpiry.
+const botInboxLimit = 50
+
+// openChallengeFor returns the live room behind a challenge, if it names this
+// account and is still waiting for it. Unlike room.IsInvited, a room with no
+// invitation does not qualify: the bot takes seats by challenge only.
+func openChallengeFor(roomID string, userID int64) *room.Instance {
+	r, err := room.Get(roomID)
+	if err != nil || r == nil {
+		return nil
+	}
+	if invited := r.InvitedUserID(); invited == nil || *invited != userID {
+		return nil
+	}
+	if r.State() != room.StateWaitingForPlayers {
+		return nil
+	}
+	return r
+}
+
+// BotAcceptHandler takes the seat a challenge offers: the bot API's join, with
+// the same checks RoomJoinHandler makes, answered in JSON. On success the bot
+// connects to the socket path in the response.
+func BotAcceptHandler(c fiber.Ctx) error {
+	acct, ok := botAccount(c)
+	if !ok {
+		return nil
+	}
+	if settings.Current().Maintenance {
+		return c.Status(fiber.StatusServiceUnavailable).
+			JSON(fiber.Map{"error": "new games are paused for maintenance"})
+	}
+	// a challenge room on another node is answered there
+	if _, forwarded, err := cluster.Room(c, c.Params("id")); forwarded {
+		return err
+	}
+	r := openChallengeFor(c.Params("id"), acct.ID)
+	if r == nil {
+		return c.Status(fiber.StatusNotFound).
+			JSON(fiber.Map{"error": "no open challenge for this bot in that room"})
+	}
+
+	uid := acct.ID
+	joiner := player.Identity{
+		UID:      user.GetID(c),
+		UserID:   &uid,
+		Username: acct.Username,
+		Title:    acct.Title,
+	}
+	if room.Engaged(joiner.UID, acct.ID) {
+		return c.Status(fiber.StatusConflict).
+			JSON(fiber.Map{"error": "the bot is already playing"})
+	}
+	if !r.Join(joiner, r.NewJoinToken()) {
+		return c.Status(fiber.StatusConflict).
+			JSON(fiber.Map{"error": "the challenge is no longer open"})
+	}
+	retireChallengeNotification(c, r, joiner)
+	go r.NotifyWaiting()
+	return c.JSON(fiber.Map{"room": r.ID, "socket": "/socket/" + r.ID})
+}
+
+// BotDeclineHandler turns a challenge down, sending the challenger home with
+// the same notice a person's refusal does.
+func BotDeclineHandler(c fiber.Ctx) error {
+	acct, ok := botAccount(c)
+	if !ok {
+		return nil
+	}
+	if _, forwarded, err := cluster.Room(c, c.Params("id")); forwarded {
+		return err
+	}
+	r := openChallengeFor(c.Params("id"), acct.ID)
+	if r == nil {
+		return c.Status(fiber.StatusNotFound).
+			JSON(fiber.Map{"error": "no open challenge for this bot in that room"})
+	}
+	if _, err := db.MarkChallengeReadForRoom(acct.ID, challengeLink(r.ID)); err != nil {
+		util.With(util.RoomID(r.ID)).Error(str.CNotif, "bot challenge decline read failed error=%s",
+			err.Error())
+	}
+	me.RefuseChallenge(r)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// BotHandler renders /bot: what bot accounts are and, for the owner of one
+// signed in with a browser, the token issuing form.
+func BotHandler(c fiber.Ctx) error {
+	return view.Render(c, fiber.StatusOK, view.Bot(botMeta(), botPage(c)))
+}
+
+// BotTokenHandler issues the signed-in bot account a new token, revoking the
+// last, and renders the page with it shown once. A bot's program cannot reach
+// this — its token does not authenticate outside the bot API — so a leaked
+// token cannot mint its own replacement.
+func BotTokenHandler(c fiber.Ctx) error {
+	m := botPage(c)
+	if !m.IsBot {
+		return redirect(c, "/bot")
+	}
+	token, found, err := auth.IssueBotToken(user.GetAccount(c).ID)
+	switch {
+	case err != nil:
+		util.Error(str.CAuth, "bot token issue failed error=%s", err.Error())
+		m.Notice = "Could not issue a token. Try again."
+	case !found:
+		// unflagged between the page render and the post
+		return redirect(c, "/bot")
+	default:
+		m.Token = token
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return view.Render(c, fiber.StatusOK, view.Bot(botMeta(), m))
+}
+
+// botPage builds /bot's model for the viewer.
+func botPage(c fiber.Ctx) view.BotPageModel {
+	m := view.BotPageModel{Available: auth.Enabled()}
+	acct := user.GetAccount(c)
+	if !m.Available || acct == nil {
+		return m
+	}
+	m.LoggedIn = true
+	bot, found, err := db.GetBotAccount(acct.ID)
+	if err != nil {
+		util.With(util.AccountID(acct.ID)).Error(str.CDB, "bot account read failed error=%s", err.Error())
+	}
+	m.IsBot = found
+	m.TokenIssued = bot.TokenIssued
+	return m
+}
+
+func botMeta() view.Meta {
+	meta := view.PageMeta("Bot accounts")
+	meta.Description = "Connect a chess engine to octad through a bot account."
+	return meta
+}
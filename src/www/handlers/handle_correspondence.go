@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/dechristopher/octad/v2"
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/correspondence"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/pools"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/user"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/view"
+)
+
+// The /correspondence pages (see package correspondence). The list and each
+// game's page are plain renders, and taking or leaving an open game's seat is
+// a form post, like a tournament entry; the board plays through the JSON API
+// at the bottom, which is also what a waiting page polls.
+
+// openSeeks bounds the open seeks /correspondence lists.
+const openSeeks = 50
+
+// recentCorrespondence bounds the viewer's finished games it lists.
+const recentCorrespondence = 20
+
+// CorrespondencesHandler renders /correspondence for the viewer.
+func CorrespondencesHandler(c fiber.Ctx) error {
+	list := view.CorrespondenceList{
+		Available: db.Ready(),
+		Notice:    c.Query("notice"),
+	}
+	if !list.Available {
+		return view.Render(c, fiber.StatusOK, view.Correspondences(correspondenceMeta(), list))
+	}
+
+	names := correspondenceNames{}
+	var viewer int64
+	if acct := user.GetAccount(c); acct != nil {
+		viewer = acct.ID
+		list.LoggedIn = true
+		for _, v := range pools.Correspondence {
+			list.Controls = append(list.Controls, view.TournamentControl{Value: v.HTMLName, Label: v.Name + " per move"})
+		}
+
+		games, err := db.CorrespondenceGamesFor(viewer)
+		if err != nil {
+			util.Error(str.CCorr, "correspondence list read failed: %s", err.Error())
+		}
+		for _, g := range games {
+			item := correspondenceItem(g, viewer, names)
+			switch {
+			case g.Status == db.CorrespondenceActive:
+				if next := correspondence.ToMove(g); next != nil && *next == viewer {
+					list.YourMove = append(list.YourMove, item)
+				} else {
+					list.Waiting = append(list.Waiting, item)
+				}
+			case g.Invited != nil && *g.Invited == viewer:
+				list.Challenges = append(list.Challenges, item)
+			default:
+				list.Sent = append(list.Sent, item)
+			}
+		}
+
+		finished, err := db.FinishedCorrespondenceGamesFor(viewer, recentCorrespondence)
+		if err != nil {
+			util.Error(str.CCorr, "finished correspondence read failed: %s", err.Error())
+		}
+		for _, g := range finished {
+			list.Finished = append(list.Finished, correspondenceItem(g, viewer, names))
+		}
+	}
+
+	seeks, err := db.OpenCorrespondenceSeeks(openSeeks)
+	if err != nil {
+		util.Error(str.CCorr, "open seeks read failed: %s", err.Error())
+	}
+	for _, g := range seeks {
+		// the viewer's own seeks are already listed, under Sent
+		if g.Creator == viewer {
+			continue
+		}
+		list.Seeks = append(list.Seeks, correspondenceItem(g, viewer, names))
+	}
+	return view.Render(c, fiber.StatusOK, view.Correspondences(correspondenceMeta(), list))
+}
+
+// CorrespondenceCreateHandler creates a seek, or a challenge to the named
+// opponent, from the list page's form, and redirects to the new game.
+func CorrespondenceCreateHandler(c fiber.Ctx) error {
+	if user.GetAccount(c) == nil {
+		return redirect(c, "/login")
+	}
+	refuse := func(msg string) error {
+		return redirect(c, "/correspondence?notice="+url.QueryEscape(msg))
+	}
+	if !db.Ready() {
+		return refuse(correspondence.ErrUnavailable.Error())
+	}
+	v, ok := pools.Map[c.FormValue("tc")]
+	if !ok || v.Days <= 0 {
+		return refuse(correspondence.ErrBadConfig.Error())
+	}
+
+	cfg := correspondence.Config{
+		Creator: identityOf(c),
+		Variant: v,
+		Rated:   c.FormValue("rated") != "",
+	}
+	switch c.FormValue("color") {
+	case "white":
+		cfg.Color = octad.White
+	case "black":
+		cfg.Color = octad.Black
+	}
+	if name := strings.TrimSpace(c.FormValue("opponent")); name != "" {
+		opp, found, err := db.GetUserByUsername(name)
+		if err != nil {
+			util.Error(str.CCorr, "opponent lookup failed: %s", err.Error())
+			return refuse("could not look that player up, try again")
+		}
+		if !found {
+			return refuse("there is no player called " + name)
+		}
+		cfg.Invited = &opp.ID
+	}
+
+	g, err := correspondence.Create(cfg)
+	if err != nil {
+		return refuse(err.Error())
+	}
+	return redirect(c, correspondence.URL(g.ID))
+}
+
+// CorrespondenceHandler renders one game for the viewer.
+func CorrespondenceHandler(c fiber.Ctx) error {
+	g, err := correspondence.Get(c.Params("id"))
+	if err != nil {
+		if !errors.Is(err, correspondence.ErrNotFound) && !errors.Is(err, correspondence.ErrUnavailable) {
+			util.Error(str.CCorr, "[%s] correspondence read failed: %s", c.Params("id"), err.Error())
+		}
+		return notFound(c)
+	}
+
+	var viewer int64
+	if acct := user.GetAccount(c); acct != nil {
+		viewer = acct.ID
+	}
+	state, err := correspondence.Describe(g, viewer)
+	if err != nil {
+		util.Error(str.CCorr, "[%s] correspondence rebuild failed: %s", g.ID, err.Error())
+		return view.Render(c, fiber.StatusInternalServerError, view.NotFound(view.PageMeta("404")))
+	}
+
+	names := correspondenceNames{}
+	m := view.CorrespondenceModel{
+		CorrespondenceItem: correspondenceItem(g, viewer, names),
+		White:              names.of(g.White),
+		Black:              names.of(g.Black),
+		State:              state,
+		LoggedIn:           viewer != 0,
+		Notice:             c.Query("notice"),
+	}
+	if g.Status == db.CorrespondenceOpen && viewer != 0 {
+		invited := g.Invited != nil && *g.Invited == viewer
+		m.CanJoin = viewer != g.Creator && (g.Invited == nil || invited)
+		if m.CanJoin {
+			// correspondence.Join refuses a blocked pair; do not offer the seat
+			blocked, err := db.Blocked(viewer, g.Creator)
+			m.CanJoin = err == nil && !blocked
+		}
+		m.CanDecline = invited
+		m.CanCancel = viewer == g.Creator
+	}
+
+	meta := view.PageMeta("Correspondence game")
+	meta.Description = m.Control + " per move correspondence game: " +
+		view.CorrespondenceTitle(m.White, m.Black) + "."
+	return view.Render(c, fiber.StatusOK, view.Correspondence(meta, m))
+}
+
+// CorrespondenceJoinHandler takes an open game's seat for the viewer.
+func CorrespondenceJoinHandler(c fiber.Ctx) error {
+	if user.GetAccount(c) == nil {
+		return redirect(c, "/login")
+	}
+	id := c.Params("id")
+	if _, err := correspondence.Join(id, identityOf(c)); err != nil {
+		return correspondenceRefusal(c, id, err)
+	}
+	return redirect(c, correspondence.URL(id))
+}
+
+// CorrespondenceWithdrawHandler cancels the viewer's open game, or declines a
+// challenge to them, and returns them to the list.
+func CorrespondenceWithdrawHandler(c fiber.Ctx) error {
+	if user.GetAccount(c) == nil {
+		return redirect(c, "/login")
+	}
+	id := c.Params("id")
+	if _, err := correspondence.Withdraw(id, identityOf(c)); err != nil {
+		return correspondenceRefusal(c, id, err)
+	}
+	return redirect(c, "/correspondence")
+}
+
+// correspondenceRefusal sends a refused seat action back to the game's page
+// with the reason, or 404s an unknown game.
+func correspondenceRefusal(c fiber.Ctx, id string, err error) error {
+	if errors.Is(err, correspondence.ErrNotFound) {
+		return notFound(c)
+	}
+	return redirect(c, correspondence.URL(id)+"?notice="+url.QueryEscape(err.Error()))
+}
+
+// correspondenceAction is one board action posted to the API.
+type correspondenceAction struct {
+	// Action is "move", "resign", "draw" (offer, or accept the opponent's
+	// offer) or "decline".
+	Action string `json:"action"`
+	UOI    string `json:"uoi"`
+}
+
+// CorrespondenceStateHandler answers a game's state as the viewer sees it,
+// for the board's poll.
+func CorrespondenceStateHandler(c fiber.Ctx) error {
+	g, err := correspondence.Get(c.Params("id"))
+	if err != nil {
+		return correspondenceAPIError(c, err)
+	}
+	return correspondenceState(c, g)
+}
+
+// CorrespondenceActionHandler applies one board action for the viewer and
+// answers the game's new state.
+func CorrespondenceActionHandler(c fiber.Ctx) error {
+	if user.GetAccount(c) == nil {
+		return c.Status(fiber.StatusUnauthorized).
+			JSON(fiber.Map{"error": "log in to play"})
+	}
+	var req correspondenceAction
+	if err := c.Bind().Body(&req); err != nil || len(req.UOI) > 8 {
+		return c.Status(fiber.StatusBadRequest).
+			JSON(fiber.Map{"error": "malformed request"})
+	}
+
+	id, who := c.Params("id"), identityOf(c)
+	var g db.CorrespondenceGame
+	var err error
+	switch req.Action {
+	case "move":
+		g, err = correspondence.Move(id, who, req.UOI)
+	case "resign":
+		g, err = correspondence.Resign(id, who)
+	case "draw":
+		g, err = correspondence.OfferDraw(id, who)
+	case "decline":
+		g, err = correspondence.DeclineDraw(id, who)
+	default:
+		return c.Status(fiber.StatusBadRequest).
+			JSON(fiber.Map{"error": "malformed request"})
+	}
+	if err != nil {
+		return correspondenceAPIError(c, err)
+	}
+	return correspondenceState(c, g)
+}
+
+// correspondenceState writes the game as the viewer sees it.
+func correspondenceState(c fiber.Ctx, g db.CorrespondenceGame) error {
+	var viewer int64
+	if acct := user.GetAccount(c); acct != nil {
+		viewer = acct.ID
+	}
+	state, err := correspondence.Describe(g, viewer)
+	if err != nil {
+		util.Error(str.CCorr, "[%s] correspondence rebuild failed: %s", g.ID, err.Error())
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(fiber.Map{"error": "could not read that game"})
+	}
+	return c.JSON(state)
+}
+
+// correspondenceAPIError maps a refused action to its status. The refusals
+// are the package's sentences, meant to be shown as they are.
+func correspondenceAPIError(c fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, correspondence.ErrNotFound), errors.Is(err, correspondence.ErrUnavailable):
+		status = fiber.StatusNotFound
+	case errors.Is(err, correspondence.ErrNotPlayer):
+		status = fiber.StatusForbidden
+	case errors.Is(err, correspondence.ErrNotActive), errors.Is(err, correspondence.ErrNotYourTurn):
+		status = fiber.StatusConflict
+	case errors.Is(err, correspondence.ErrIllegal):
+		status = fiber.StatusUnprocessableEntity
+	}
+	if status == fiber.StatusInternalServerError {
+		util.Error(str.CCorr, "[%s] correspondence action failed: %s", c.Params("id"), err.Error())
+		return c.Status(status).JSON(fiber.Map{"error": "could not apply that, try again"})
+	}
+	return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+}
+
+// correspondenceItem resolves a game to its list entry from viewer's side: the
+// opponent is the other seat, a challenge's recipient while it is open, or the
+// creator of somebody else's seek.
+func correspondenceItem(g db.CorrespondenceGame, viewer int64, names correspondenceNames) view.CorrespondenceItem {
+	item := view.CorrespondenceItem{
+		ID:      g.ID,
+		Control: correspondence.VariantOf(g).Name,
+		Rated:   g.Rated,
+	}
+	switch correspondence.SeatOf(g, viewer) {
+	case octad.White:
+		item.Color = "white"
+		item.Opponent = names.of(g.Black)
+	case octad.Black:
+		item.Color = "black"
+		item.Opponent = names.of(g.White)
+	default:
+		// a seek the viewer would join takes the open side
+		item.Color = "white"
+		if g.White != nil {
+			item.Color = "black"
+		}
+		creator := g.Creator
+		item.Opponent = names.of(&creator)
+	}
+	if item.Opponent == "" && g.Invited != nil && *g.Invited != viewer {
+		item.Opponent = names.of(g.Invited)
+	}
+	switch g.Status {
+	case db.CorrespondenceActive:
+		item.Deadline = g.Deadline
+	case db.CorrespondenceFinished, db.CorrespondenceAborted:
+		item.Result = correspondence.ResultLine(g.Outcome, g.Reason)
+	}
+	return item
+}
+
+// correspondenceNames resolves account ids to usernames once per request: a
+// list names the same opponent on many rows.
+type correspondenceNames map[int64]string
+
+func (n correspondenceNames) of(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	if name, ok := n[*id]; ok {
+		return name
+	}
+	name, _ := db.UserDisplayForID(id)
+	n[*id] = name
+	return name
+}
+
+// correspondenceMeta is the list page's meta.
+func correspondenceMeta() view.Meta {
+	meta := view.PageMeta("Correspondence")
+	meta.Description = "Octad games played over days, one move at a time."
+	return meta
+}
@@ -1,12 +1,56 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v3"
 
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/dump"
+	"github.com/dechristopher/lio/store"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
 	"github.com/dechristopher/lio/view"
 )
 
-// DBHandler renders the game database page
+// dumpLinkTTL bounds a presigned dump download link. Long enough to start a
+// slow download (the object store honors a link for as long as the transfer
+// runs once it has begun), short enough that a shared link goes stale — the
+// stable, shareable URL is /db/<month>, which mints a fresh one.
+const dumpLinkTTL = 15 * time.Minute
+
+// DBHandler renders the game database page: the published monthly PGN dumps,
+// newest first. A catalogue read failure renders the page without the list
+// rather than failing it — the prose above the list stands on its own.
 func DBHandler(c fiber.Ctx) error {
-	return view.Render(c, 200, view.DB(view.PageMeta("Game Database")))
+	dumps, err := db.ListDumps()
+	if err != nil {
+		util.Error(str.CDump, "dump list failed: %s", err.Error())
+	}
+	return view.Render(c, 200, view.DB(view.PageMeta("Game Database"), dumps))
+}
+
+// DBDumpHandler serves /db/<YYYY-MM>: a redirect to a short-lived presigned
+// download of that month's dump, so the file streams from the object store
+// rather than through the app. An unknown or unpublished month 404s.
+func DBDumpHandler(c fiber.Ctx) error {
+	month := c.Params("month")
+	if _, _, err := dump.MonthRange(month); err != nil {
+		return notFound(c)
+	}
+	d, found, err := db.GetDump(month)
+	if err != nil {
+		util.Error(str.CDump, "dump lookup failed month=%s: %s", month, err.Error())
+		return notFound(c)
+	}
+	if !found {
+		return notFound(c)
+	}
+	link, err := store.DumpBucket.PresignGet(d.ObjectKey, d.ObjectKey, dumpLinkTTL)
+	if err != nil {
+		util.Error(str.CDump, "dump presign failed month=%s: %s", month, err.Error())
+		return notFound(c)
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Redirect().Status(fiber.StatusFound).To(link)
 }
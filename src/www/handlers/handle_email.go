@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/mail"
+	"github.com/dechristopher/lio/prefs"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/view"
+)
+
+// The email pages (see package mail): the signed-in account's address and
+// digest switch, and the pages the mailed links open. Those last ones need no
+// session — a link is often opened on another device than the one that asked
+// for it — and carry their authority in the signed token instead.
+
+// EmailSettingsHandler renders the viewer's email settings.
+func EmailSettingsHandler(c fiber.Ctx) error {
+	acct := signedInAccount(c)
+	if acct == nil {
+		return redirect(c, "/login")
+	}
+	rec, found, err := db.GetUserByID(acct.ID)
+	if err != nil || !found {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	m := view.EmailModel{
+		Verified:    rec.EmailVerified,
+		Digest:      prefs.For(acct.ID).EmailDigest(),
+		MailEnabled: mail.Enabled(),
+		Notice:      c.Query("notice"),
+	}
+	if rec.Email != nil {
+		m.Address = *rec.Email
+	}
+	meta := view.PageMeta("Email")
+	meta.Description = "Your email address and the weekly digest."
+	return view.Render(c, fiber.StatusOK, view.EmailSettings(meta, m))
+}
+
+// EmailResendHandler sends the viewer's address its confirmation link again.
+func EmailResendHandler(c fiber.Ctx) error {
+	acct := signedInAccount(c)
+	if acct == nil {
+		return redirect(c, "/login")
+	}
+	rec, found, err := db.GetUserByID(acct.ID)
+	if err != nil || !found {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if rec.Email == nil || rec.EmailVerified {
+		return redirect(c, "/account/email")
+	}
+	if err := mail.SendVerification(rec.ID, rec.Username, *rec.Email); err != nil {
+		if errors.Is(err, mail.ErrRateLimited) {
+			return emailNotice(c, "That address was sent several links already. Try again in an hour.")
+		}
+		util.With(util.AccountID(rec.ID)).Error(str.CMail, "verification queue failed error=%s", err.Error())
+		return emailNotice(c, "Could not send the link. Try again.")
+	}
+	return emailNotice(c, "A new link is on its way.")
+}
+
+// EmailDigestHandler turns the viewer's weekly digest on or off. Only a
+// confirmed address may turn it on; the digest query checks that too.
+func EmailDigestHandler(c fiber.Ctx) error {
+	acct := signedInAccount(c)
+	if acct == nil {
+		return redirect(c, "/login")
+	}
+	on := c.FormValue("on") == "1"
+	if on {
+		rec, found, err := db.GetUserByID(acct.ID)
+		if err != nil || !found {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		if rec.Email == nil || !rec.EmailVerified {
+			return emailNotice(c, "Confirm your address before turning the digest on.")
+		}
+	}
+	if err := prefs.SetFlag(acct.ID, prefs.KeyEmailDigest, on); err != nil {
+		util.With(util.AccountID(acct.ID)).Error(str.CDB, "digest pref write failed error=%s", err.Error())
+		return emailNotice(c, "Could not save that. Try again.")
+	}
+	if on {
+		return emailNotice(c, "The weekly digest is on.")
+	}
+	return emailNotice(c, "The weekly digest is off.")
+}
+
+// EmailVerifyHandler completes a confirmation link.
+func EmailVerifyHandler(c fiber.Ctx) error {
+	if !auth.Enabled() {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	notice := "Your email address is confirmed."
+	if err := auth.VerifyEmail(c.Query("token")); err != nil {
+		if !errors.Is(err, auth.ErrEmailLink) {
+			util.Error(str.CMail, "email verify failed error=%s", err.Error())
+		}
+		notice = "That confirmation link has expired or no longer matches your address."
+	}
+	if signedInAccount(c) != nil {
+		return emailNotice(c, notice)
+	}
+	return view.Render(c, fiber.StatusOK, view.EmailVerified(view.PageMeta("Email"), notice))
+}
+
+// ForgotHandler renders the reset request form.
+func ForgotHandler(c fiber.Ctx) error {
+	return renderForgot(c, view.ForgotModel{Sent: c.Query("sent") == "1"})
+}
+
+// ForgotSubmitHandler queues a reset link for whatever account matches. The
+// answer is the same whether one did or not, so the form cannot be used to
+// find out which usernames or addresses have accounts.
+func ForgotSubmitHandler(c fiber.Ctx) error {
+	if !auth.Enabled() || !mail.Enabled() {
+		return renderForgot(c, view.ForgotModel{})
+	}
+	if err := mail.SendPasswordReset(c.FormValue("login")); err != nil {
+		util.Error(str.CMail, "reset request failed error=%s", err.Error())
+	}
+	return redirect(c, "/account/forgot?sent=1")
+}
+
+// ResetHandler renders the new password form a reset link opens. The token is
+// only checked when the form is sent; checking it on open too would cost an
+// account read for an answer the post gives anyway.
+func ResetHandler(c fiber.Ctx) error {
+	m := view.ResetModel{Token: c.Query("token")}
+	if m.Token == "" {
+		m.Notice = "That reset link is incomplete."
+	}
+	return renderReset(c, m)
+}
+
+// ResetSubmitHandler completes a reset link.
+func ResetSubmitHandler(c fiber.Ctx) error {
+	if !auth.Enabled() {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	token := c.FormValue("token")
+	password := c.FormValue("password")
+	if password != c.FormValue("confirm") {
+		return renderReset(c, view.ResetModel{Token: token, Notice: "The passwords do not match."})
+	}
+	username, err := auth.ResetPassword(token, password)
+	switch {
+	case errors.Is(err, auth.ErrEmailLink):
+		return renderReset(c, view.ResetModel{Notice: "That reset link has expired or was already used."})
+	case errors.Is(err, auth.ErrPasswordLength):
+		return renderReset(c, view.ResetModel{Token: token, Notice: err.Error()})
+	case err != nil:
+		util.Error(str.CAuth, "password reset failed error=%s", err.Error())
+		return renderReset(c, view.ResetModel{Token: token, Notice: "Could not reset the password. Try again."})
+	}
+	return renderReset(c, view.ResetModel{Done: true, Username: username})
+}
+
+// UnsubscribeHandler renders the digest opt-out a digest links to.
+func UnsubscribeHandler(c fiber.Ctx) error {
+	return renderUnsubscribe(c, view.UnsubscribeModel{Token: c.Query("token")})
+}
+
+// UnsubscribeSubmitHandler turns the digest off. It is both the page's button
+// and the target of a mail client's one-click List-Unsubscribe post, which
+// sends the token in the query rather than the form.
+func UnsubscribeSubmitHandler(c fiber.Ctx) error {
+	if !auth.Enabled() {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	token := c.FormValue("token")
+	if token == "" {
+		token = c.Query("token")
+	}
+	userID, err := auth.OpenEmailToken(token, auth.EmailUnsubscribe, func(int64) (string, bool) {
+		return "", true
+	})
+	if err != nil {
+		return renderUnsubscribe(c, view.UnsubscribeModel{Notice: err.Error()})
+	}
+	if err := prefs.SetFlag(userID, prefs.KeyEmailDigest, false); err != nil {
+		util.With(util.AccountID(userID)).Error(str.CDB, "digest opt-out failed error=%s", err.Error())
+		return renderUnsubscribe(c, view.UnsubscribeModel{Token: token,
+			Notice: "Could not turn the digest off. Try again."})
+	}
+	return renderUnsubscribe(c, view.UnsubscribeModel{Done: true})
+}
+
+// emailNotice sends the viewer to the email settings with a notice.
+func emailNotice(c fiber.Ctx, msg string) error {
+	return redirect(c, "/account/email?notice="+url.QueryEscape(msg))
+}
+
+func renderForgot(c fiber.Ctx, m view.ForgotModel) error {
+	m.MailEnabled = auth.Enabled() && mail.Enabled()
+	meta := view.PageMeta("Forgot password")
+	meta.Description = "Reset a forgotten password by email."
+	return view.Render(c, fiber.StatusOK, view.Forgot(meta, m))
+}
+
+func renderReset(c fiber.Ctx, m view.ResetModel) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderReferrerPolicy, "no-referrer")
+	meta := view.PageMeta("Reset password")
+	return view.Render(c, fiber.StatusOK, view.Reset(meta, m))
+}
+
+func renderUnsubscribe(c fiber.Ctx, m view.UnsubscribeModel) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	meta := view.PageMeta("Weekly digest")
+	return view.Render(c, fiber.StatusOK, view.Unsubscribe(meta, m))
+}
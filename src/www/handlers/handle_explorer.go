@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"math"
+	"time"
+
+	"github.com/dechristopher/octad/v2"
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/opening"
+	"github.com/dechristopher/lio/pools"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+)
+
+// GET /api/explorer — the opening explorer behind the analysis board's
+// explorer panel: for one position, every continuation the archive has seen
+// played from it, with how those games ended, the average rating of their
+// seats, the cached eval of the position each move reaches, and a few notable
+// games. The position is an OFEN (?ofen=), or a formation matchup's start
+// (?white=nkpp&black=ppkn, each formation keyed from its owner's perspective).
+// Both forms take the same optional filters: ?category= (a rating category;
+// only rated games carry one) and ?since= / ?until= (dates, both inclusive).
+//
+// GET /api/explorer/matchups lists the formation matchups themselves, most
+// played first, under the same filters — the explorer's view of the deploy.
+//
+// Everything here is read-only aggregation over the archive's positions index
+// (db/explorer.go); with Postgres unconfigured the archive is simply empty.
+
+// explorerDate is the format of the since/until filters
+const explorerDate = "2006-01-02"
+
+// explorerResponse is one explored position
+type explorerResponse struct {
+	OFEN string `json:"ofen"`
+	// Matchup names the formations when the position is a matchup's start
+	Matchup *explorerMatchup `json:"matchup,omitempty"`
+	// Games sums the continuations' games: the games that went on from here
+	Games int64          `json:"games"`
+	Moves []explorerMove `json:"moves"`
+	Top   []explorerGame `json:"top"`
+}
+
+// explorerMove is one continuation. White/Draw/Black are whole percentages of
+// its games; Rating is the average of the rated games' seats, omitted when none
+// was rated; CP (white-positive centipawns) and Mate mirror /api/analysis and
+// are omitted until the background evaluator reaches the position.
+type explorerMove struct {
+	UOI    string `json:"uoi"`
+	SAN    string `json:"san"`
+	Games  int64  `json:"n"`
+	White  int    `json:"w"`
+	Draw   int    `json:"d"`
+	Black  int    `json:"b"`
+	Rating int    `json:"r,omitempty"`
+	CP     *int16 `json:"cp,omitempty"`
+	Mate   *int16 `json:"mate,omitempty"`
+}
+
+// explorerGame is one notable game, linked through its permalink
+type explorerGame struct {
+	URL         string `json:"url"`
+	White       string `json:"white"`
+	Black       string `json:"black"`
+	WhiteRating string `json:"wr,omitempty"`
+	BlackRating string `json:"br,omitempty"`
+	Result      string `json:"res"`
+	Date        string `json:"date"`
+}
+
+// explorerMatchup is one formation matchup: its names, start, and — in the
+// matchup list — how its games went
+type explorerMatchup struct {
+	Name  string `json:"name"`
+	White string `json:"white"`
+	Black string `json:"black"`
+	OFEN  string `json:"ofen"`
+	Games int64  `json:"n,omitempty"`
+	WPct  int    `json:"w,omitempty"`
+	DPct  int    `json:"d,omitempty"`
+	BPct  int    `json:"b,omitempty"`
+}
+
+// ExplorerHandler explores one position (see the comment above)
+func ExplorerHandler(c fiber.Ctx) error {
+	filter, ok := explorerFilter(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).
+			JSON(fiber.Map{"error": "malformed filter"})
+	}
+
+	ofen := c.Query("ofen")
+	if white, black := c.Query("white"), c.Query("black"); white != "" || black != "" {
+		if ofen, ok = opening.StartOFEN(white, black); !ok {
+			return c.Status(fiber.StatusUnprocessableEntity).
+				JSON(fiber.Map{"error": "unknown formation"})
+		}
+	}
+	if ofen == "" || len(ofen) > 100 {
+		return c.Status(fiber.StatusBadRequest).
+			JSON(fiber.Map{"error": "malformed request"})
+	}
+	fromPos, err := octad.OFEN(ofen)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(fiber.Map{"error": "invalid position"})
+	}
+	g, err := octad.NewGame(fromPos)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(fiber.Map{"error": "invalid position"})
+	}
+	pos := g.Position()
+
+	moves, games, err := db.Explore(pos, ofen, filter)
+	if err != nil {
+		util.Error(str.CDB, "explorer query failed ofen=%s error=%s", ofen, err.Error())
+		return c.Status(fiber.StatusServiceUnavailable).
+			JSON(fiber.Map{"error": "explorer unavailable"})
+	}
+
+	resp := explorerResponse{OFEN: ofen, Moves: []explorerMove{}, Top: []explorerGame{}}
+	if white, black, matchup, ok := opening.Names(ofen); ok {
+		resp.Matchup = &explorerMatchup{Name: matchup, White: white, Black: black, OFEN: ofen}
+	}
+
+	legal := map[string]*octad.Move{}
+	for _, m := range g.ValidMoves() {
+		legal[m.String()] = m
+	}
+	for _, m := range moves {
+		mv, ok := legal[m.UOI]
+		if !ok {
+			// a hash collision or a stale row; never offer a move the board
+			// would refuse
+			continue
+		}
+		resp.Games += m.Games
+		resp.Moves = append(resp.Moves, explorerMove{
+			UOI:    m.UOI,
+			SAN:    octad.AlgebraicNotation{}.Encode(pos, mv),
+			Games:  m.Games,
+			White:  percent(m.WhiteWins, m.Games),
+			Draw:   percent(m.Draws, m.Games),
+			Black:  percent(m.BlackWins, m.Games),
+			Rating: m.AvgRating,
+			CP:     m.EvalCp,
+			Mate:   m.EvalMate,
+		})
+	}
+
+	for _, top := range games {
+		resp.Top = append(resp.Top, explorerGame{
+			URL:         "/game/" + top.GameID,
+			White:       seatName(top.White),
+			Black:       seatName(top.Black),
+			WhiteRating: top.WhiteRating,
+			BlackRating: top.BlackRating,
+			Result:      top.Outcome,
+			Date:        top.Start.UTC().Format(explorerDate),
+		})
+	}
+
+	return c.JSON(resp)
+}
+
+// ExplorerMatchupsHandler lists the formation matchups the archive has games
+// from, most played first (see the comment above)
+func ExplorerMatchupsHandler(c fiber.Ctx) error {
+	filter, ok := explorerFilter(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).
+			JSON(fiber.Map{"error": "malformed filter"})
+	}
+
+	rows, err := db.ExploreMatchups(filter)
+	if err != nil {
+		util.Error(str.CDB, "explorer matchups query failed error=%s", err.Error())
+		return c.Status(fiber.StatusServiceUnavailable).
+			JSON(fiber.Map{"error": "explorer unavailable"})
+	}
+
+	out := []explorerMatchup{}
+	for _, r := range rows {
+		white, black, matchup, ok := opening.Names(r.StartingOFEN)
+		if !ok {
+			// an unlimited game from a custom position: no matchup to file it under
+			continue
+		}
+		out = append(out, explorerMatchup{
+			Name:  matchup,
+			White: white,
+			Black: black,
+			OFEN:  r.StartingOFEN,
+			Games: r.Games,
+			WPct:  percent(r.WhiteWins, r.Games),
+			DPct:  percent(r.Draws, r.Games),
+			BPct:  percent(r.BlackWins, r.Games),
+		})
+	}
+	return c.JSON(fiber.Map{"matchups": out})
+}
+
+// explorerFilter parses the explorer's optional filters, reporting false for
+// an unknown rating category or a malformed date
+func explorerFilter(c fiber.Ctx) (db.ExplorerFilter, bool) {
+	f := db.ExplorerFilter{Category: c.Query("category")}
+	if f.Category != "" {
+		if _, ok := pools.LookupRatingCategory(f.Category); !ok {
+			return f, false
+		}
+	}
+	if s := c.Query("since"); s != "" {
+		t, err := time.Parse(explorerDate, s)
+		if err != nil {
+			return f, false
+		}
+		f.Since = t
+	}
+	if s := c.Query("until"); s != "" {
+		t, err := time.Parse(explorerDate, s)
+		if err != nil {
+			return f, false
+		}
+		// inclusive: the whole of the named day
+		f.Until = t.AddDate(0, 0, 1)
+	}
+	return f, true
+}
+
+// percent returns n as a whole percentage of total
+func percent(n, total int64) int {
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(100 * float64(n) / float64(total)))
+}
+
+// seatName is how the explorer shows a seat with no account name
+func seatName(name string) string {
+	if name == "" {
+		return "Anonymous"
+	}
+	return name
+}
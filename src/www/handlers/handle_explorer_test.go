@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// explorerTestApp registers the explorer routes as www.Serve does (sans the
+// rate limiter). PG is unconfigured in unit tests, so the archive explored is
+// empty: these tests cover parameter handling and the response shape.
+func explorerTestApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/api/explorer", ExplorerHandler)
+	app.Get("/api/explorer/matchups", ExplorerMatchupsHandler)
+	return app
+}
+
+// getExplorer runs one request through the explorer, returning the status and
+// decoded body.
+func getExplorer(t *testing.T, app *fiber.App, query url.Values) (int, explorerResponse) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/explorer?"+query.Encode(), nil))
+	if err != nil {
+		t.Fatalf("explorer request: %v", err)
+	}
+	var out explorerResponse
+	if resp.StatusCode == fiber.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+	return resp.StatusCode, out
+}
+
+// TestExplorerMatchupStart covers the matchup form: the formations resolve to
+// the start position the room assembles, named, with empty (never null) lists.
+func TestExplorerMatchupStart(t *testing.T) {
+	app := explorerTestApp()
+	status, out := getExplorer(t, app, url.Values{"white": {"nkpp"}, "black": {"nkpp"}})
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if out.OFEN != startOFEN {
+		t.Errorf("ofen = %q, want %q", out.OFEN, startOFEN)
+	}
+	if out.Matchup == nil || out.Matchup.White == "" || out.Matchup.Name == "" {
+		t.Errorf("matchup = %+v, want the formations named", out.Matchup)
+	}
+	if out.Moves == nil || out.Top == nil || out.Games != 0 {
+		t.Errorf("moves = %v, top = %v, games = %d, want an empty archive", out.Moves, out.Top, out.Games)
+	}
+}
+
+// TestExplorerMidGamePosition covers the OFEN form for a position that is no
+// matchup start: explored, but unnamed.
+func TestExplorerMidGamePosition(t *testing.T) {
+	app := explorerTestApp()
+	ofen := "ppkn/4/2P1/NK1P b NCFncf - 0 1"
+	status, out := getExplorer(t, app, url.Values{"ofen": {ofen}, "since": {"2025-01-01"}, "until": {"2025-12-31"}})
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if out.Matchup != nil {
+		t.Errorf("matchup = %+v, want none mid-game", out.Matchup)
+	}
+}
+
+// TestExplorerRejects covers the refused requests.
+func TestExplorerRejects(t *testing.T) {
+	app := explorerTestApp()
+	cases := []struct {
+		name  string
+		query url.Values
+		want  int
+	}{
+		{"no position", url.Values{}, fiber.StatusBadRequest},
+		{"bad ofen", url.Values{"ofen": {"not a position"}}, fiber.StatusUnprocessableEntity},
+		{"unknown formation", url.Values{"white": {"kkkk"}, "black": {"nkpp"}}, fiber.StatusUnprocessableEntity},
+		{"unknown category", url.Values{"ofen": {startOFEN}, "category": {"nope"}}, fiber.StatusBadRequest},
+		{"bad date", url.Values{"ofen": {startOFEN}, "since": {"yesterday"}}, fiber.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		if status, _ := getExplorer(t, app, tc.query); status != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, status, tc.want)
+		}
+	}
+}
+
+// TestExplorerMatchups covers the matchup list over an empty archive.
+func TestExplorerMatchups(t *testing.T) {
+	app := explorerTestApp()
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/explorer/matchups", nil))
+	if err != nil {
+		t.Fatalf("matchups request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var out struct {
+		Matchups []explorerMatchup `json:"matchups"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if out.Matchups == nil || len(out.Matchups) != 0 {
+		t.Errorf("matchups = %v, want an empty list", out.Matchups)
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/analysis"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/db/gen"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/user"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/view"
+)
+
+// The post-game review of an archived game (package analysis). The archive
+// page carries a game's review inline (view.ArchiveData.Analysis); these two
+// endpoints let it ask for one and watch it finish:
+//
+//	GET  /api/game/:uuid/analysis — the review's state, uncacheable
+//	POST /api/game/:uuid/analysis — queue a review (accounts only)
+//
+// A review is a few dozen engine searches, so requesting one takes an account
+// and is rate-limited like the rest of the analysis surface; reading one is
+// free.
+
+// GameAnalysisHandler serves a game's review state; "none" when it was never
+// requested.
+func GameAnalysisHandler(c fiber.Ctx) error {
+	g, ok := analysisGame(c)
+	if !ok {
+		return fiber.ErrNotFound
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if a := gameAnalysis(g); a != nil {
+		return c.JSON(a)
+	}
+	return c.JSON(view.ArchiveAnalysis{State: "none"})
+}
+
+// GameAnalysisRequestHandler queues a review of a game and answers its state.
+// Requesting a game already queued or reviewed is not an error: the answer is
+// simply the state it is in.
+func GameAnalysisRequestHandler(c fiber.Ctx) error {
+	if user.GetAccount(c) == nil {
+		return c.Status(fiber.StatusUnauthorized).
+			JSON(fiber.Map{"error": "log in to request analysis"})
+	}
+	g, ok := analysisGame(c)
+	if !ok {
+		return fiber.ErrNotFound
+	}
+	if len(g.Moves) == 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).
+			JSON(fiber.Map{"error": "no moves to analyse"})
+	}
+	if _, err := analysis.Request(g.ID); err != nil {
+		util.Error(str.CDB, "analysis request failed game=%d error=%s", g.ID, err.Error())
+		return c.Status(fiber.StatusServiceUnavailable).
+			JSON(fiber.Map{"error": "analysis unavailable"})
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if a := gameAnalysis(g); a != nil {
+		return c.JSON(a)
+	}
+	return c.JSON(view.ArchiveAnalysis{State: "queued"})
+}
+
+// analysisGame resolves the :uuid param to its archived game.
+func analysisGame(c fiber.Ctx) (gen.Game, bool) {
+	if !db.Ready() {
+		return gen.Game{}, false
+	}
+	g, found, err := db.GetGameByUUID(c.Params("uuid"))
+	if err != nil || !found {
+		return gen.Game{}, false
+	}
+	return g, true
+}
+
+// gameAnalysis reads a game's review into its page shape, nil when none was
+// ever requested (or it cannot be read — the page then simply offers one).
+func gameAnalysis(g gen.Game) *view.ArchiveAnalysis {
+	a, err := analysis.Get(g.ID)
+	if err != nil {
+		util.Error(str.CDB, "analysis lookup failed game=%d error=%s", g.ID, err.Error())
+		return nil
+	}
+	if a == nil {
+		return nil
+	}
+	switch {
+	case a.Failed:
+		return &view.ArchiveAnalysis{State: "failed"}
+	case !a.Done:
+		return &view.ArchiveAnalysis{State: "queued"}
+	}
+
+	out := &view.ArchiveAnalysis{
+		State:      "done",
+		Depth:      a.Depth,
+		Judgements: make([]string, len(a.Judgements)),
+		Evals:      make([]*int16, len(a.Evals)),
+	}
+	judgements := make([]analysis.Judgement, len(a.Judgements))
+	for i, j := range a.Judgements {
+		judgements[i] = analysis.Judgement(j)
+		out.Judgements[i] = judgements[i].String()
+	}
+	for i := range a.Evals {
+		out.Evals[i] = &a.Evals[i]
+	}
+
+	// the side to move is the OFEN's second field
+	whiteFirst := !strings.Contains(g.StartingOfen, " b ")
+	white, black := analysis.Tally(judgements, whiteFirst)
+	out.White = analysisSide(a.White, white)
+	out.Black = analysisSide(a.Black, black)
+	return out
+}
+
+// analysisSide merges a side's stored scores with its derived counts.
+func analysisSide(scores db.SideAnalysis, counts analysis.Side) *view.AnalysisSide {
+	return &view.AnalysisSide{
+		Accuracy:     scores.Accuracy,
+		ACPL:         scores.ACPL,
+		Inaccuracies: counts.Inaccuracies,
+		Mistakes:     counts.Mistakes,
+		Blunders:     counts.Blunders,
+		MissedMates:  counts.MissedMates,
+	}
+}
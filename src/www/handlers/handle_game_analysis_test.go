@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// gameAnalysisTestApp registers the review routes as www.Serve does (sans the
+// rate limiter). PG is unconfigured in unit tests, so no game resolves: these
+// tests cover the gates in front of the lookup.
+func gameAnalysisTestApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/api/game/:uuid/analysis", GameAnalysisHandler)
+	app.Post("/api/game/:uuid/analysis", GameAnalysisRequestHandler)
+	return app
+}
+
+// TestGameAnalysisGates: requesting a review takes an account before anything
+// else, and a game that does not resolve is a 404 to read.
+func TestGameAnalysisGates(t *testing.T) {
+	app := gameAnalysisTestApp()
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/game/abc123/analysis", nil))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Errorf("anonymous request = %d, want 401", resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/game/abc123/analysis", nil))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("unknown game = %d, want 404", resp.StatusCode)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	"github.com/dechristopher/lio/cluster"
 	"github.com/dechristopher/lio/game"
 	"github.com/dechristopher/lio/og"
 	"github.com/dechristopher/lio/room"
@@ -40,8 +41,11 @@ func OGDefaultHandler(c fiber.Ctx) error {
 func OGRoomHandler(c fiber.Ctx) error {
 	id := strings.TrimSuffix(c.Params("id"), ".png")
 
-	roomInstance, err := room.Get(id)
-	if err != nil || roomInstance == nil {
+	roomInstance, forwarded, err := cluster.Room(c, id)
+	if forwarded {
+		return err
+	}
+	if roomInstance == nil {
 		// closed rooms live on as archive permalinks; give their shared links
 		// a real preview of the final position before falling back to branding
 		return ogArchivedRoom(c, id)
@@ -84,6 +84,7 @@ func ProfileHandler(c fiber.Ctx) error {
 	// after fillModBar: the report control stands down when the viewer already
 	// has the moderation bar on this account
 	fillReportControl(c, &m, rec.ID)
+	fillBlockControl(c, &m, rec.ID)
 
 	return view.Render(c, fiber.StatusOK, view.Profile(view.ProfileMeta(m), m))
 }
@@ -238,6 +239,12 @@ func fillProfileStats(m *view.ProfileModel, userID int64) {
 		}
 		return nil
 	})
+	g.Go(func() error {
+		if acc, err := db.AccuracyForUser(userID); err == nil {
+			m.Accuracy = view.NewAccuracyView(acc)
+		}
+		return nil
+	})
 
 	_ = g.Wait() // never non-nil; every leg degrades in place
 
@@ -350,6 +357,17 @@ func fillReportControl(c fiber.Ctx, m *view.ProfileModel, userID int64) {
 	m.ShowReport = acct != nil && acct.ID != userID && !m.Closed && !m.ShowMod
 }
 
+// fillBlockControl decides whether the page offers the block control, and its
+// state: a logged-in visitor looking at someone else's open account.
+func fillBlockControl(c fiber.Ctx, m *view.ProfileModel, userID int64) {
+	acct := user.GetAccount(c)
+	if acct == nil || acct.ID == userID || m.Closed {
+		return
+	}
+	m.ShowBlock = true
+	m.IsBlocking = db.IsBlocking(acct.ID, userID)
+}
+
 // fillModBar populates the moderator-only controls, but only for a viewer who
 // may actually act on this account. It mirrors the API's rules in
 // www/handlers/api/mod (self-administration for admins, peer admins only for
@@ -381,6 +399,8 @@ func fillModBar(c fiber.Ctx, m *view.ProfileModel, rec db.UserRecord) {
 		// no self-demotion, and no ban on your own account
 		CanSetRole:  acct.Role.CanAdmin() && !self,
 		CanBan:      !self,
+		CanFlagBot:  acct.Role.CanAdmin() && !self && !rec.Role.CanModerate(), // see mod.BotHandler
+		Bot:         db.IsBot(rec.ID),
 		CurrentRole: rec.Role.String(),
 		Banned:      rec.Ban.Banned,
 		BanReason:   rec.Ban.Reason,
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/matchmaking"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/settings"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/user"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/variant"
+	"github.com/dechristopher/lio/view"
+)
+
+// enterQueue queues the requesting session for a time control and sends it to
+// the queue page. It applies the same gates as room creation (newRoom): a
+// session needs a uid, maintenance mode starts no games, and a player already
+// in a game cannot queue for another.
+func enterQueue(c fiber.Ctx, v variant.Variant) error {
+	id := identityOf(c)
+	if id.UID == "" {
+		return redirect(c, "/")
+	}
+	if settings.Current().Maintenance {
+		return redirect(c, "/?notice=maintenance")
+	}
+	if room.Engaged(id.UID, accountID(id)) {
+		return redirect(c, "/?notice=already-playing")
+	}
+
+	// queueing replaces a seek of this session's own, as creating a game does:
+	// the player wants a game, and the queue is now how they will get one
+	supersedeSeeks(id.UID)
+
+	if err := matchmaking.Enter(id, v); err != nil {
+		util.Error(str.CMatch, "failed to queue uid %s: %s", id.UID, err.Error())
+		return redirect(c, "/")
+	}
+	return redirect(c, "/queue")
+}
+
+// QueueHandler renders the queue page for a queued session. A session that is
+// not queued was most likely just paired — the frame that would have taken it
+// to its room can be missed mid-navigation — so it is sent to the game it is
+// in, or home.
+func QueueHandler(c fiber.Ctx) error {
+	uid := user.GetID(c)
+	st, ok := matchmaking.Queued(uid)
+	if !ok {
+		id := identityOf(c)
+		if seat, playing := room.EngagedSeat(uid, accountID(id)); playing && seat.OwnSession {
+			return redirect(c, "/"+seat.RoomID)
+		}
+		return redirect(c, "/")
+	}
+
+	m := view.QueueModel{
+		Control: controlName(st.Variant.HTMLName),
+		Since:   st.Since,
+		Waiting: matchmaking.Waiting()[st.Variant.HTMLName],
+	}
+	if user.GetAccount(c) != nil {
+		m.Rating = int(st.Rating + 0.5)
+	}
+	return view.Render(c, 200, view.Queue(view.PageMeta("Finding an opponent"), m))
+}
+
+// QueueCancelHandler takes the session out of the queue.
+func QueueCancelHandler(c fiber.Ctx) error {
+	if matchmaking.Leave(user.GetID(c)) {
+		util.DebugFlag("matchmaking", str.CMatch, "uid %s left the queue", user.GetID(c))
+	}
+	return redirect(c, "/")
+}
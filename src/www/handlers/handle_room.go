@@ -9,8 +9,10 @@ import (
 	"github.com/gofiber/fiber/v3"
 
 	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/cluster"
 	"github.com/dechristopher/lio/db"
 	"github.com/dechristopher/lio/engine"
+	"github.com/dechristopher/lio/matchmaking"
 	"github.com/dechristopher/lio/notify"
 	"github.com/dechristopher/lio/player"
 	"github.com/dechristopher/lio/pools"
@@ -65,7 +67,7 @@ func supersedeSeeks(uid string) {
 			continue
 		}
 		if r.Cancel() {
-			util.Info(str.CRoom, "[%s] seek superseded by a new game from uid %s", id, uid)
+			util.With(util.RoomID(id), util.UID(uid)).Info(str.CRoom, "seek superseded by a new game")
 		}
 	}
 }
@@ -126,6 +128,15 @@ func redirect(c fiber.Ctx, to string) error {
 	return c.Redirect().To(to)
 }
 
+// signedInAccount is the signed-in account, or nil when there is none or
+// accounts are unavailable: the guard of the account pages.
+func signedInAccount(c fiber.Ctx) *user.Account {
+	if !auth.Enabled() {
+		return nil
+	}
+	return user.GetAccount(c)
+}
+
 // getUserAndRoom returns the uid and room instance based on URL parameters
 // and will redirect the user home or 404 if there is no UID or room
 func getUserAndRoom(c fiber.Ctx) (string, *room.Instance, error, bool) {
@@ -135,9 +146,12 @@ func getUserAndRoom(c fiber.Ctx) (string, *room.Instance, error, bool) {
 		return "", nil, redirect(c, "/"), true
 	}
 
-	// grab room instance
-	roomInstance, err := room.Get(c.Params("id"))
-	if err != nil || roomInstance == nil {
+	// grab room instance, from the node that runs it if that is not this one
+	roomInstance, forwarded, err := cluster.Room(c, c.Params("id"))
+	if forwarded {
+		return "", nil, err, true
+	}
+	if roomInstance == nil {
 		// continue to 404 page if room not found
 		return "", nil, c.Status(fiber.StatusNotFound).Next(), true
 	}
@@ -153,8 +167,12 @@ func RoomHandler(c fiber.Ctx) error {
 		return redirect(c, "/")
 	}
 
-	roomInstance, err := room.Get(c.Params("id"))
-	if err != nil || roomInstance == nil {
+	// a room another node runs is rendered there (package cluster)
+	roomInstance, forwarded, err := cluster.Room(c, c.Params("id"))
+	if forwarded {
+		return err
+	}
+	if roomInstance == nil {
 		// the live room actor is gone (or never existed): serve the permanent
 		// archived match view when the room's games are in Postgres, else the
 		// old 404
@@ -174,6 +192,12 @@ func RoomHandler(c fiber.Ctx) error {
 		!roomInstance.IsInvited(identityOf(c).UserID) {
 		asPlayer, asSpectator = false, true
 	}
+	// Likewise an account that shares a block with whoever is seated: room.Join
+	// refuses it the open seat, so it is not offered one.
+	if asPlayer && !roomInstance.IsPlayer(uid) &&
+		roomInstance.SeatBlocked(accountID(identityOf(c))) {
+		asPlayer, asSpectator = false, true
+	}
 
 	// get template payload for user
 	payload := roomInstance.GenTemplatePayload(uid)
@@ -265,6 +289,12 @@ func RoomJoinHandler(c fiber.Ctx) error {
 		return redirect(c, "/"+roomInstance.ID+"#notInvited")
 	}
 
+	// blocked from the open seat: the room page renders them as a spectator,
+	// which is all the explanation a block gives
+	if roomInstance.SeatBlocked(accountID(joiner)) {
+		return redirect(c, "/"+roomInstance.ID)
+	}
+
 	// attempt to join room, stamping the joiner's account identity onto the seat
 	if roomInstance.Join(joiner, joinPayload.Token) {
 		// taking the seat is what accepting a direct challenge *is*, so the
@@ -311,20 +341,21 @@ func RoomCancelHandler(c fiber.Ctx) error {
 	return redirect(c, "/")
 }
 
-// NewQuickRoomVsHuman creates a game against a human player with the default
-// variant (½ + 1 deploy blitz) and randomized color. Quick games are a public
-// seek by nature —
-// there is no link to share with a specific opponent — so they are always listed.
+// NewQuickRoomVsHuman puts the player in the matchmaking queue for a time
+// control: the form's tc (any rating pool's HTMLName), or the default ½ + 1
+// deploy blitz for the home page's "vs Human" button. The queue pairs them by
+// rating and seats both players in a fresh room; until then they wait on
+// /queue. See package matchmaking.
 func NewQuickRoomVsHuman(c fiber.Ctx) error {
-	return newRoom(newRoomPayload{
-		c:             c,
-		variant:       variant.HalfOneBlitzDeploy,
-		selectedColor: util.RandomColor(),
-		public:        true,
-		// a quick game never lets the creator pick a color, so it is always
-		// blind — the color is revealed only when the game begins
-		blindColor: true,
-	})
+	v := variant.HalfOneBlitzDeploy
+	if tc := c.FormValue("tc"); tc != "" {
+		var ok bool
+		if v, ok = pools.Map[tc]; !ok || !matchmaking.Pool(v) {
+			util.Error(str.CMatch, "failed to queue: invalid time control %q", tc)
+			return redirect(c, "/")
+		}
+	}
+	return enterQueue(c, v)
 }
 
 // NewCustomRoom creates a custom game from the create-game modal: a time control
@@ -537,6 +568,9 @@ func newRoom(payload newRoomPayload) error {
 		// one account can take, and every other visitor would bounce off the
 		// join gate.
 		params.Public = false
+		// a game against a bot account plays for the bot pool, not the human
+		// ladder (pools.BotCategory)
+		params.BotPool = db.IsBot(invited.ID)
 	}
 	params.BlindColor = payload.blindColor
 	params.RaceTo = payload.raceTo
@@ -583,7 +617,7 @@ func newRoom(payload newRoomPayload) error {
 		return redirect(payload.c, "/")
 	}
 
-	util.Info(str.CRoom, "user %s created room %s, vsBot=%v", creator.UID, instance.ID, payload.vsBot)
+	util.With(util.RoomID(instance.ID), util.UID(creator.UID)).Info(str.CRoom, "room created over http, vsBot=%v", payload.vsBot)
 
 	// Tell the invited player. This happens after the room exists, so the
 	// notification can never point at a room that was never created.
@@ -613,6 +647,9 @@ func newRoom(payload newRoomPayload) error {
 //   - A banned account cannot be challenged. It cannot log in to answer, and a
 //     challenge is not a channel for reaching somebody who has been removed.
 //   - A bot game cannot carry an invitation. The seat is already taken.
+//   - A block between the two, either way round, refuses the challenge
+//     (db/blocks.go). A failed read refuses it too: a challenge is the thing a
+//     block exists to stop.
 func resolveInvite(payload newRoomPayload) (*db.UserRecord, bool) {
 	name := strings.TrimSpace(payload.invite)
 	if name == "" {
@@ -637,6 +674,9 @@ func resolveInvite(payload newRoomPayload) (*db.UserRecord, bool) {
 	if !found || rec.ID == *creator.UserID || rec.Ban.Banned {
 		return nil, false
 	}
+	if blocked, err := db.Blocked(*creator.UserID, rec.ID); err != nil || blocked {
+		return nil, false
+	}
 	// The target has to be here. A challenge is an invitation to play now, so
 	// one addressed to somebody who is not on the site creates a room that waits
 	// for a person who is not coming, and a notification they read hours later
@@ -692,8 +732,8 @@ func retireChallengeNotification(c fiber.Ctx, r *room.Instance, joiner player.Id
 	}
 	n, err := db.MarkChallengeReadForRoom(*joiner.UserID, link)
 	if err != nil {
-		util.Error(str.CNotif, "challenge accept read failed room=%s error=%s",
-			r.ID, err.Error())
+		util.With(util.RoomID(r.ID)).Error(str.CNotif, "challenge accept read failed error=%s",
+			err.Error())
 		return
 	}
 	if n == 0 {
@@ -714,6 +754,13 @@ func challengeLink(roomID string) string {
 }
 
 func notifyChallenge(creator player.Identity, invitedID int64, roomID string, v variant.Variant) {
+	// resolveInvite refused a blocked pair already; this catches a block made
+	// while the room was being created, which would otherwise still deliver.
+	if creator.UserID != nil {
+		if blocked, err := db.Blocked(*creator.UserID, invitedID); err != nil || blocked {
+			return
+		}
+	}
 	body := creator.Username + " challenges you to " + v.Name + " octad."
 	if err := notify.Push(db.NewNotification{
 		UserID:  invitedID,
@@ -723,7 +770,7 @@ func notifyChallenge(creator player.Identity, invitedID int64, roomID string, v
 		Link:    challengeLink(roomID),
 		Expires: time.Now().Add(room.ChallengeExpiry),
 	}, creator.Username); err != nil {
-		util.Error(str.CRoom, "challenge notify failed target=%d room=%s error=%s",
-			invitedID, roomID, err.Error())
+		util.With(util.RoomID(roomID), util.AccountID(invitedID)).Error(str.CRoom, "challenge notify failed error=%s",
+			err.Error())
 	}
 }
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/dechristopher/octad/v2"
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/pools"
+	"github.com/dechristopher/lio/simul"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/user"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/view"
+)
+
+// recentSimuls bounds the finished simuls /simul lists beneath the live ones.
+const recentSimuls = 20
+
+// SimulsHandler renders /simul: the simuls this process is running, then the
+// recently finished ones read back from Postgres. Any logged-in account may
+// host one, so it gets the create form.
+func SimulsHandler(c fiber.Ctx) error {
+	list := view.SimulList{Notice: c.Query("notice"), MaxBoards: simul.MaxBoards}
+
+	shown := make(map[string]bool)
+	for _, s := range simul.List() {
+		shown[s.ID] = true
+		if s.Status == simul.Finished {
+			list.Finished = append(list.Finished, simulItem(s))
+		} else {
+			list.Simuls = append(list.Simuls, simulItem(s))
+		}
+	}
+	recent, err := db.RecentSimuls(recentSimuls)
+	if err != nil {
+		util.Error(str.CSiml, "recent simuls read failed: %s", err.Error())
+	}
+	for _, info := range recent {
+		if shown[info.ID] {
+			continue
+		}
+		list.Finished = append(list.Finished, view.SimulItem{
+			ID:        info.ID,
+			Name:      info.Name,
+			Control:   controlName(info.Variant),
+			Host:      info.HostName,
+			HostColor: hostColorName(info.HostColor == "black"),
+			MaxBoards: info.MaxBoards,
+			Status:    info.Status,
+			Wins:      info.Wins,
+			Draws:     info.Draws,
+			Losses:    info.Losses,
+		})
+	}
+
+	if user.GetAccount(c) != nil {
+		list.Controls = make([]view.TournamentControl, 0, len(pools.CreateControls)*2)
+		for _, ctrl := range pools.CreateControls {
+			list.Controls = append(list.Controls,
+				view.TournamentControl{Value: ctrl.Deploy.HTMLName, Label: ctrl.Label + " · " + ctrl.Group.String()},
+				view.TournamentControl{Value: ctrl.Classic.HTMLName, Label: ctrl.Label + " · " + ctrl.Group.String() + " · Classic"},
+			)
+		}
+	}
+	return view.Render(c, 200, view.Simuls(view.PageMeta("Simuls"), list))
+}
+
+// SimulCreateHandler creates a simul hosted by the logged-in viewer and
+// redirects to its page.
+func SimulCreateHandler(c fiber.Ctx) error {
+	if user.GetAccount(c) == nil {
+		return redirect(c, "/login")
+	}
+
+	v, ok := pools.Map[c.FormValue("tc")]
+	if !ok {
+		return redirect(c, "/simul?notice="+url.QueryEscape(simul.ErrBadConfig.Error()))
+	}
+	color := octad.White
+	if c.FormValue("color") == "black" {
+		color = octad.Black
+	}
+	boards, _ := strconv.Atoi(c.FormValue("boards"))
+	host := identityOf(c)
+
+	s, err := simul.Create(simul.Config{
+		Name:      c.FormValue("name"),
+		Variant:   v,
+		HostColor: color,
+		MaxBoards: boards,
+		Host:      host,
+	})
+	if err != nil {
+		return redirect(c, "/simul?notice="+url.QueryEscape(err.Error()))
+	}
+	return redirect(c, "/simul/"+s.ID)
+}
+
+// SimulHandler renders one simul, which is also its host's dashboard: live
+// from the process running it, or read back from Postgres once it has
+// finished and been let go.
+func SimulHandler(c fiber.Ctx) error {
+	id := c.Params("id")
+	m := view.SimulModel{Notice: c.Query("notice")}
+	acct := user.GetAccount(c)
+
+	var sum simul.Summary
+	if s := simul.Get(id); s != nil {
+		sum = s.Summary()
+		m.State = s.Payload()
+		m.Live = sum.Status != simul.Finished
+		if acct != nil {
+			m.IsHost = s.IsHost(acct.ID)
+			m.Entered = s.Entered(acct.ID)
+		}
+	} else {
+		var ok bool
+		var err error
+		sum, m.State, ok, err = simul.Archived(id)
+		if err != nil {
+			util.Error(str.CSiml, "[%s] archived simul read failed: %s", id, err.Error())
+		}
+		if !ok {
+			return notFound(c)
+		}
+	}
+
+	m.SimulItem = simulItem(sum)
+	m.LoggedIn = acct != nil
+	m.CanJoin = m.LoggedIn && !m.IsHost && !m.Entered &&
+		sum.Status == simul.Created && sum.Boards < sum.MaxBoards
+	return view.Render(c, 200, view.Simul(view.PageMeta(sum.Name), m))
+}
+
+// SimulJoinHandler takes a board for the logged-in viewer.
+func SimulJoinHandler(c fiber.Ctx) error {
+	return simulAction(c, "", func(s *simul.Simul) error {
+		return s.Join(identityOf(c))
+	})
+}
+
+// SimulLeaveHandler gives the logged-in viewer's board back before the start.
+func SimulLeaveHandler(c fiber.Ctx) error {
+	return simulAction(c, "", func(s *simul.Simul) error {
+		return s.Leave(accountID(identityOf(c)))
+	})
+}
+
+// SimulStartHandler starts the host's simul and sends them straight to their
+// first board.
+func SimulStartHandler(c fiber.Ctx) error {
+	return simulAction(c, "/next", func(s *simul.Simul) error {
+		id := identityOf(c)
+		if !s.IsHost(accountID(id)) {
+			return simul.ErrNotHost
+		}
+		return s.Start(id.UID)
+	})
+}
+
+// SimulCancelHandler closes a simul its host no longer wants to start.
+func SimulCancelHandler(c fiber.Ctx) error {
+	return simulAction(c, "", func(s *simul.Simul) error {
+		return s.Cancel(accountID(identityOf(c)))
+	})
+}
+
+// SimulNextHandler is the host's "next board" jump: the next board where it
+// is their move, going round from the one they are leaving (?from=<room>), or
+// back to the dashboard when every board is waiting on the other side.
+func SimulNextHandler(c fiber.Ctx) error {
+	id := c.Params("id")
+	back := "/simul/" + id
+	s := simul.Get(id)
+	acct := user.GetAccount(c)
+	if s == nil || acct == nil || !s.IsHost(acct.ID) {
+		return redirect(c, back)
+	}
+	if next := s.Next(c.Query("from")); next != "" {
+		return redirect(c, "/"+next)
+	}
+	return redirect(c, back)
+}
+
+// simulAction is the shared shape of the simul's form posts: each needs an
+// account and a running simul, and lands on the simul's page (plus then, on
+// success), with the refusal as a notice if there was one.
+func simulAction(c fiber.Ctx, then string, apply func(*simul.Simul) error) error {
+	id := c.Params("id")
+	back := "/simul/" + id
+	if user.GetAccount(c) == nil {
+		return redirect(c, "/login")
+	}
+	s := simul.Get(id)
+	if s == nil {
+		return redirect(c, back)
+	}
+	if err := apply(s); err != nil {
+		if !errors.Is(err, simul.ErrStarted) {
+			util.Debug(str.CSiml, "[%s] action refused: %s", id, err.Error())
+		}
+		return redirect(c, back+"?notice="+url.QueryEscape(err.Error()))
+	}
+	return redirect(c, back+then)
+}
+
+// simulItem resolves a Summary to the strings the pages show.
+func simulItem(s simul.Summary) view.SimulItem {
+	return view.SimulItem{
+		ID:        s.ID,
+		Name:      s.Name,
+		Control:   controlName(s.Variant.HTMLName),
+		Host:      s.HostName,
+		HostColor: hostColorName(s.HostColor == octad.Black),
+		Boards:    s.Boards,
+		MaxBoards: s.MaxBoards,
+		Status:    string(s.Status),
+		Wins:      s.Wins,
+		Draws:     s.Draws,
+		Losses:    s.Losses,
+	}
+}
+
+// hostColorName is the colour a simul's host plays, for display.
+func hostColorName(black bool) string {
+	if black {
+		return "Black"
+	}
+	return "White"
+}
@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"log/slog"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 
 	"github.com/dechristopher/lio/cache"
 	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/fairplay"
 	"github.com/dechristopher/lio/presence"
 	"github.com/dechristopher/lio/room"
 	"github.com/dechristopher/lio/settings"
@@ -32,6 +36,8 @@ import (
 const (
 	queueShown    = 50
 	resolvedShown = 20
+	// fairPlayShown bounds the fair-play list, highest scores first.
+	fairPlayShown = 20
 	// liveRoomsShown bounds the live list. A busy site is a good problem, but a
 	// page listing every room becomes unreadable exactly when it matters most;
 	// the remainder is counted rather than dropped silently.
@@ -44,6 +50,10 @@ const (
 	// archive: what an operator needs to see is what is live and what just
 	// ended, and the audit log holds the permanent record of every one.
 	broadcastsShown = 20
+	// chatLogShown bounds a room's chat log. A long match's conversation fits
+	// many times over; a log past it is flooding, and the start of a flood is
+	// what a report is about.
+	chatLogShown = 500
 )
 
 // The console's three pages (arch/ADMIN_MODERATION.md, *The console is three
@@ -99,6 +109,9 @@ func SystemLogHandler(c fiber.Ctx) error {
 		return systemNotFound(c)
 	}
 	m.Feed = auditFeed(c)
+	if m.IsAdmin {
+		m.Log = serverLog(c)
+	}
 	return view.Render(c, fiber.StatusOK, view.System(view.SystemMeta(), m))
 }
 
@@ -296,10 +309,69 @@ func ModerationHandler(c fiber.Ctx) error {
 			m.Closed = append(m.Closed, reportView(r, true))
 		}
 	}
+	m.FairPlay = fairPlayViews()
 
 	return view.Render(c, fiber.StatusOK, view.Moderation(view.ModerationMeta(), m))
 }
 
+// fairPlayViews loads the flagged accounts for the moderation page, each with
+// the recent samples its score was rolled from.
+func fairPlayViews() []view.FairPlayView {
+	flagged, err := db.FlaggedAccounts(fairplay.FlagScore, fairplay.FlagGames,
+		fairplay.FlagMargin, fairPlayShown)
+	if err != nil {
+		util.Error(str.CDB, "fair-play list load failed error=%s", err.Error())
+		return nil
+	}
+	out := make([]view.FairPlayView, 0, len(flagged))
+	for _, a := range flagged {
+		samples, err := db.FairPlaySamples(a.UserID, fairplay.Window)
+		if err != nil {
+			util.With(util.AccountID(a.UserID)).Error(str.CDB, "fair-play samples load failed error=%s", err.Error())
+		}
+		out = append(out, view.NewFairPlayView(a, samples))
+	}
+	return out
+}
+
+// ModerationChatHandler renders a room's chat log, reached from a report that
+// names one of the room's games. Both conversations and the withheld lines are
+// shown: a moderator judging what was said needs all of it.
+func ModerationChatHandler(c fiber.Ctx) error {
+	acct := user.GetAccount(c)
+	if acct == nil || !acct.Role.CanModerate() {
+		return view.Render(c, fiber.StatusNotFound, view.NotFound(view.PageMeta("404")))
+	}
+
+	roomID := c.Params("room")
+	lines, err := db.RoomChat(roomID, chatLogShown+1)
+	if err != nil {
+		util.With(util.RoomID(roomID)).Error(str.CDB, "chat log load failed error=%s", err.Error())
+		return view.Render(c, fiber.StatusInternalServerError, view.NotFound(view.PageMeta("404")))
+	}
+
+	m := view.ChatLogModel{RoomID: roomID, RoomURL: "/" + roomID}
+	if len(lines) > chatLogShown {
+		lines, m.Truncated = lines[:chatLogShown], true
+	}
+	for _, l := range lines {
+		line := view.ChatLogLine{
+			When:     l.Created.UTC().Format("2006-01-02 15:04:05"),
+			Channel:  l.Channel,
+			From:     l.Username,
+			Body:     l.Body,
+			Filtered: l.Filtered,
+		}
+		if l.UserID != nil && l.Username != "" {
+			line.FromURL = "/@/" + l.Username
+		} else {
+			line.From = "Anonymous (" + l.UID + ")"
+		}
+		m.Lines = append(m.Lines, line)
+	}
+	return view.Render(c, fiber.StatusOK, view.ModerationChat(view.ChatLogMeta(roomID), m))
+}
+
 // liveOps assembles the operational picture from what the site already tracks
 // for the home page. No new instrumentation: HomeListing walks the room map
 // under each room's own lock, and presence walks the socket directory.
@@ -398,6 +470,9 @@ func reportView(r db.Report, resolved bool) view.ReportView {
 	if r.GameID != "" {
 		v.GameURL = "/game/" + r.GameID
 	}
+	if r.RoomID != "" {
+		v.ChatURL = "/moderation/chat/" + r.RoomID
+	}
 	if resolved {
 		v.Resolved = view.RelativeDay(r.Resolved)
 		v.WhenExact = r.Resolved.UTC().Format("2006-01-02 15:04:05 MST")
@@ -406,3 +481,83 @@ func reportView(r db.Report, resolved bool) view.ReportView {
 	}
 	return v
 }
+
+// SystemServerLogHandler serves the server log card's results on their own, for
+// the filter form's htmx swaps. Admin-gated like the card, and for the same
+// reason as the other fragments it re-checks rather than trusting its caller.
+func SystemServerLogHandler(c fiber.Ctx) error {
+	acct := user.GetAccount(c)
+	if acct == nil || !acct.Role.CanAdmin() {
+		return systemNotFound(c)
+	}
+	c.Set("Cache-Control", "no-store")
+	return view.Render(c, fiber.StatusOK, view.ServerLogBody(serverLog(c)))
+}
+
+// serverLogLimit is how many lines one search of the server log shows. The
+// newest come first, so a search that hits it is narrowed rather than paged.
+const serverLogLimit = 200
+
+// serverLogTime is the layout the since/until fields are typed in, UTC.
+const serverLogTime = "2006-01-02 15:04"
+
+// serverLog reads the filter query params and searches the server log ring.
+// The params are named apart from the audit feed's (q/action/page) because the
+// two cards share the page's URL. A time that does not parse is dropped and
+// echoed back empty, so the form shows what was actually applied.
+func serverLog(c fiber.Ctx) view.ServerLog {
+	l := view.ServerLog{
+		Level:    "info",
+		Package:  strings.TrimSpace(c.Query("pkg")),
+		Room:     strings.TrimSpace(c.Query("room")),
+		Limit:    serverLogLimit,
+		Packages: util.Packages(),
+		Spill:    util.LogSpilling(),
+	}
+	q := util.LogQuery{
+		MinLevel: slog.LevelInfo,
+		Package:  l.Package,
+		Room:     l.Room,
+		Limit:    serverLogLimit,
+	}
+	if level, ok := util.ParseLevel(c.Query("lvl")); ok {
+		q.MinLevel = level
+		l.Level = util.LevelName(level)
+	}
+	if t, err := time.Parse(serverLogTime, strings.TrimSpace(c.Query("since"))); err == nil {
+		q.Since = t
+		l.Since = t.Format(serverLogTime)
+	}
+	if t, err := time.Parse(serverLogTime, strings.TrimSpace(c.Query("until"))); err == nil {
+		// the minute typed is included whole
+		q.Until = t.Add(time.Minute - time.Nanosecond)
+		l.Until = t.Format(serverLogTime)
+	}
+	l.Filtered = l.Level != "info" || l.Package != "" || l.Room != "" || l.Since != "" || l.Until != ""
+
+	entries := util.QueryLog(q)
+	l.Truncated = len(entries) >= serverLogLimit
+	for _, e := range entries {
+		line := view.LogLine{
+			When:      e.Time.UTC().Format("15:04:05.000"),
+			WhenExact: e.Time.UTC().Format("2006-01-02 15:04:05.000 MST"),
+			Level:     util.LevelName(e.Level),
+			Package:   e.Package,
+			Caller:    e.Caller,
+			Message:   e.Message,
+			Room:      e.Room,
+			Game:      e.Game,
+			UID:       e.UID,
+		}
+		if e.Account != 0 {
+			line.Account = strconv.FormatInt(e.Account, 10)
+		}
+		l.Lines = append(l.Lines, line)
+	}
+
+	for pkg, level := range util.Levels() {
+		l.Levels = append(l.Levels, view.PackageLevel{Package: pkg, Level: util.LevelName(level)})
+	}
+	sort.Slice(l.Levels, func(i, j int) bool { return l.Levels[i].Package < l.Levels[j].Package })
+	return l
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/auth"
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/user"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/view"
+)
+
+// The /account/tokens page (see auth/tokens.go): the signed-in account's
+// personal API tokens. Reached with the cookie session only — no token's
+// scopes name this path — so a token can never create or revoke another.
+
+// TokensHandler renders the viewer's token list and creation form.
+func TokensHandler(c fiber.Ctx) error {
+	acct := signedInAccount(c)
+	if acct == nil {
+		return redirect(c, "/login")
+	}
+	return renderTokens(c, acct, view.TokensModel{Notice: c.Query("notice")})
+}
+
+// TokenCreateHandler creates a token from the form and renders the page with
+// it shown once.
+func TokenCreateHandler(c fiber.Ctx) error {
+	acct := signedInAccount(c)
+	if acct == nil {
+		return redirect(c, "/login")
+	}
+	var values []string
+	for _, v := range c.Request().PostArgs().PeekMulti("scope") {
+		values = append(values, string(v))
+	}
+	scopes, err := auth.ParseScopes(values)
+	if err != nil {
+		return tokensNotice(c, err.Error())
+	}
+	name := strings.TrimSpace(c.FormValue("name"))
+	token, err := auth.IssueAPIToken(acct.ID, acct.Role, name, scopes)
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenName) || errors.Is(err, auth.ErrTokenScopes) ||
+			errors.Is(err, auth.ErrTokenMod) || errors.Is(err, auth.ErrTokenLimit) {
+			return tokensNotice(c, err.Error())
+		}
+		util.With(util.AccountID(acct.ID)).Error(str.CAuth, "api token create failed error=%s", err.Error())
+		return tokensNotice(c, "Could not create the token. Try again.")
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return renderTokens(c, acct, view.TokensModel{Fresh: token, FreshName: name})
+}
+
+// TokenRevokeHandler revokes one of the viewer's tokens.
+func TokenRevokeHandler(c fiber.Ctx) error {
+	acct := signedInAccount(c)
+	if acct == nil {
+		return redirect(c, "/login")
+	}
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return tokensNotice(c, "That token does not exist.")
+	}
+	found, err := auth.RevokeAPIToken(acct.ID, id)
+	switch {
+	case err != nil:
+		util.With(util.AccountID(acct.ID)).Error(str.CAuth, "api token revoke failed error=%s", err.Error())
+		return tokensNotice(c, "Could not revoke the token. Try again.")
+	case !found:
+		return tokensNotice(c, "That token does not exist.")
+	}
+	return redirect(c, "/account/tokens")
+}
+
+// tokensNotice sends the viewer back to the page with a notice.
+func tokensNotice(c fiber.Ctx, msg string) error {
+	return redirect(c, "/account/tokens?notice="+url.QueryEscape(msg))
+}
+
+// renderTokens fills the list and the form into m and renders the page.
+func renderTokens(c fiber.Ctx, acct *user.Account, m view.TokensModel) error {
+	tokens, err := db.ListAPITokens(acct.ID)
+	if err != nil {
+		util.With(util.AccountID(acct.ID)).Error(str.CDB, "api token list failed error=%s", err.Error())
+	}
+	for _, t := range tokens {
+		labels := make([]string, len(t.Scopes))
+		for i, s := range t.Scopes {
+			labels[i] = auth.Scope(s).Label()
+		}
+		m.Tokens = append(m.Tokens, view.APITokenView{
+			ID:       strconv.FormatInt(t.ID, 10),
+			Name:     t.Name,
+			Scopes:   strings.Join(labels, ", "),
+			LastUsed: t.LastUsed,
+		})
+	}
+	m.AtLimit = len(tokens) >= auth.MaxAPITokens
+	for _, s := range auth.Scopes {
+		if s == auth.ScopeMod && !acct.Role.CanModerate() {
+			continue
+		}
+		m.Scopes = append(m.Scopes, view.ScopeOption{Value: string(s), Label: s.Label()})
+	}
+	meta := view.PageMeta("API tokens")
+	meta.Description = "Personal API tokens for your own scripts."
+	return view.Render(c, fiber.StatusOK, view.Tokens(meta, m))
+}
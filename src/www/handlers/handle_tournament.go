@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/pools"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/tournament"
+	"github.com/dechristopher/lio/user"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/view"
+)
+
+// recentTournaments bounds the finished events /tournament lists beneath the
+// live ones.
+const recentTournaments = 20
+
+// TournamentsHandler renders /tournament: the events this process is running,
+// then the recently finished ones read back from Postgres. A moderator also
+// gets the create form.
+func TournamentsHandler(c fiber.Ctx) error {
+	list := view.TournamentList{Notice: c.Query("notice")}
+
+	shown := make(map[string]bool)
+	for _, s := range tournament.List() {
+		shown[s.ID] = true
+		if s.Status == tournament.Finished {
+			list.Finished = append(list.Finished, tournamentItem(s))
+		} else {
+			list.Events = append(list.Events, tournamentItem(s))
+		}
+	}
+	recent, err := db.RecentTournaments(recentTournaments)
+	if err != nil {
+		util.Error(str.CTour, "recent tournaments read failed: %s", err.Error())
+	}
+	for _, info := range recent {
+		if shown[info.ID] {
+			continue
+		}
+		list.Finished = append(list.Finished, view.TournamentItem{
+			ID:      info.ID,
+			Name:    info.Name,
+			Format:  formatName(tournament.Format(info.Format)),
+			Control: controlName(info.Variant),
+			Length:  lengthName(tournament.Format(info.Format), info.Rounds, info.Duration),
+			Status:  info.Status,
+			Round:   info.Round,
+			Rounds:  info.Rounds,
+			Rated:   info.Rated,
+			Berserk: info.Berserk,
+		})
+	}
+
+	if acct := user.GetAccount(c); acct != nil && acct.Role.CanModerate() {
+		list.Controls = make([]view.TournamentControl, 0, len(pools.CreateControls)*2)
+		for _, ctrl := range pools.CreateControls {
+			list.Controls = append(list.Controls,
+				view.TournamentControl{Value: ctrl.Deploy.HTMLName, Label: ctrl.Label + " · " + ctrl.Group.String()},
+				view.TournamentControl{Value: ctrl.Classic.HTMLName, Label: ctrl.Label + " · " + ctrl.Group.String() + " · Classic"},
+			)
+		}
+	}
+	return view.Render(c, 200, view.Tournaments(view.PageMeta("Tournaments"), list))
+}
+
+// TournamentCreateHandler creates an event from the moderator's form and
+// redirects to its page. Anyone else gets the 404 the route would give them
+// without it.
+func TournamentCreateHandler(c fiber.Ctx) error {
+	acct := user.GetAccount(c)
+	if acct == nil || !acct.Role.CanModerate() {
+		return notFound(c)
+	}
+
+	v, ok := pools.Map[c.FormValue("tc")]
+	if !ok {
+		return redirect(c, "/tournament?notice="+url.QueryEscape(tournament.ErrBadConfig.Error()))
+	}
+	rounds, _ := strconv.Atoi(c.FormValue("rounds"))
+	minutes, _ := strconv.Atoi(c.FormValue("minutes"))
+	startsIn, _ := strconv.Atoi(c.FormValue("starts"))
+	if startsIn < 0 {
+		startsIn = 0
+	}
+	creator := acct.ID
+
+	t, err := tournament.Create(tournament.Config{
+		Name:      c.FormValue("name"),
+		Format:    tournament.Format(c.FormValue("format")),
+		Variant:   v,
+		Rated:     c.FormValue("rated") != "",
+		Berserk:   c.FormValue("berserk") != "",
+		Rounds:    rounds,
+		Duration:  time.Duration(minutes) * time.Minute,
+		StartsAt:  time.Now().Add(time.Duration(startsIn) * time.Minute),
+		CreatedBy: &creator,
+	})
+	if err != nil {
+		return redirect(c, "/tournament?notice="+url.QueryEscape(err.Error()))
+	}
+	util.Info(str.CTour, "[%s] tournament created by %s", t.ID, acct.Username)
+	return redirect(c, "/tournament/"+t.ID)
+}
+
+// TournamentHandler renders one event: live from the process running it, or
+// read back from Postgres once it has finished and been let go.
+func TournamentHandler(c fiber.Ctx) error {
+	id := c.Params("id")
+	m := view.TournamentModel{Notice: c.Query("notice")}
+
+	var sum tournament.Summary
+	if t := tournament.Get(id); t != nil {
+		sum = t.Summary()
+		m.State = t.Payload()
+		m.Live = sum.Status != tournament.Finished
+		if acct := user.GetAccount(c); acct != nil {
+			m.Entered, m.Withdrawn = t.Entry(acct.ID)
+		}
+	} else {
+		var ok bool
+		var err error
+		sum, m.State, ok, err = tournament.Archived(id)
+		if err != nil {
+			util.Error(str.CTour, "[%s] archived tournament read failed: %s", id, err.Error())
+		}
+		if !ok {
+			return notFound(c)
+		}
+	}
+
+	m.TournamentItem = tournamentItem(sum)
+	m.LoggedIn = user.GetAccount(c) != nil
+	m.CanJoin = m.LoggedIn && sum.Status != tournament.Finished &&
+		(sum.Format == tournament.Arena || (sum.Status == tournament.Created && !m.Withdrawn))
+	return view.Render(c, 200, view.Tournament(view.PageMeta(sum.Name), m))
+}
+
+// TournamentJoinHandler enters the logged-in viewer and returns them to the
+// event's page, with the refusal as a notice if there was one.
+func TournamentJoinHandler(c fiber.Ctx) error {
+	return tournamentEntry(c, func(t *tournament.Tournament) error {
+		return t.Join(identityOf(c))
+	})
+}
+
+// TournamentWithdrawHandler takes the logged-in viewer out of the event.
+func TournamentWithdrawHandler(c fiber.Ctx) error {
+	return tournamentEntry(c, func(t *tournament.Tournament) error {
+		return t.Withdraw(accountID(identityOf(c)))
+	})
+}
+
+// tournamentEntry is the shared shape of join and withdraw: both need an
+// account and a running event, and both land back on the event's page.
+func tournamentEntry(c fiber.Ctx, apply func(*tournament.Tournament) error) error {
+	id := c.Params("id")
+	back := "/tournament/" + id
+	if user.GetAccount(c) == nil {
+		return redirect(c, "/login")
+	}
+	t := tournament.Get(id)
+	if t == nil {
+		return redirect(c, back)
+	}
+	if err := apply(t); err != nil {
+		if !errors.Is(err, tournament.ErrFinished) {
+			util.Debug(str.CTour, "[%s] entry refused: %s", id, err.Error())
+		}
+		return redirect(c, back+"?notice="+url.QueryEscape(err.Error()))
+	}
+	return redirect(c, back)
+}
+
+// tournamentItem resolves a Summary to the strings the pages show.
+func tournamentItem(s tournament.Summary) view.TournamentItem {
+	return view.TournamentItem{
+		ID:       s.ID,
+		Name:     s.Name,
+		Format:   formatName(s.Format),
+		Control:  controlName(s.Variant.HTMLName),
+		Length:   lengthName(s.Format, s.Rounds, s.Duration),
+		Status:   string(s.Status),
+		Round:    s.Round,
+		Rounds:   s.Rounds,
+		Players:  s.Players,
+		Rated:    s.Rated,
+		Berserk:  s.Berserk,
+		StartsAt: s.StartsAt,
+	}
+}
+
+// formatName is a format's display name.
+func formatName(f tournament.Format) string {
+	if f == tournament.Swiss {
+		return "Swiss"
+	}
+	return "Arena"
+}
+
+// controlName is a variant's clock and pace, "½ + 1 · Blitz", by its
+// HTMLName; a variant this build no longer has reads as its key.
+func controlName(htmlName string) string {
+	v, ok := pools.Map[htmlName]
+	if !ok {
+		return htmlName
+	}
+	group := v.SpeedGroup().String()
+	if group == "" {
+		return v.Name
+	}
+	return v.Name + " · " + strings.ToUpper(group[:1]) + group[1:]
+}
+
+// lengthName is an event's size: its rounds for a Swiss, its clock for an
+// Arena.
+func lengthName(f tournament.Format, rounds int, d time.Duration) string {
+	if f == tournament.Swiss {
+		if rounds == 1 {
+			return "1 round"
+		}
+		return fmt.Sprintf("%d rounds", rounds)
+	}
+	return fmt.Sprintf("%d minutes", int(d/time.Minute))
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/dechristopher/lio/db"
+	"github.com/dechristopher/lio/puzzle"
+	"github.com/dechristopher/lio/rating"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/user"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/view"
+)
+
+// The /training puzzle trainer (see the puzzle package). The page serves one
+// puzzle near the viewer's puzzle rating, or a named one; its moves go through
+// the stateless API below, the way the tutorial's go through /api/learn.
+
+// TrainingHandler renders the next puzzle for the viewer.
+func TrainingHandler(c fiber.Ctx) error {
+	userID, r := trainee(c)
+	p, err := puzzle.Next(userID, r.R)
+	if err != nil {
+		util.Error(str.CPuzl, "puzzle serve failed: %s", err.Error())
+		return view.Render(c, fiber.StatusInternalServerError, view.NotFound(view.PageMeta("404")))
+	}
+	return renderTraining(c, p, userID, r)
+}
+
+// TrainingPuzzleHandler renders a named puzzle, so every puzzle is a URL that
+// can be shared or come back to. A puzzle already attempted can be replayed
+// this way; only the first attempt is ever rated.
+func TrainingPuzzleHandler(c fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return notFound(c)
+	}
+	p, err := db.GetPuzzle(id)
+	if err != nil {
+		util.Error(str.CPuzl, "puzzle read failed id=%d: %s", id, err.Error())
+		return view.Render(c, fiber.StatusInternalServerError, view.NotFound(view.PageMeta("404")))
+	}
+	if p == nil {
+		return notFound(c)
+	}
+	userID, r := trainee(c)
+	return renderTraining(c, p, userID, r)
+}
+
+// renderTraining renders the trainer around p, which may be nil when there is
+// nothing to serve.
+func renderTraining(c fiber.Ctx, p *db.Puzzle, userID int64, r rating.Rating) error {
+	m := view.TrainingModel{}
+	if userID != 0 {
+		m.Rating = r.Display()
+	}
+	if p != nil {
+		start, err := puzzle.Start(*p)
+		if err != nil {
+			util.Error(str.CPuzl, "puzzle start failed id=%d: %s", p.ID, err.Error())
+		} else {
+			m.Puzzle = &view.TrainingPuzzle{
+				ID:    p.ID,
+				OFEN:  start.OFEN,
+				Turn:  start.Turn,
+				Dests: start.Dests,
+				Theme: p.Theme,
+			}
+			if len(p.LastMove) >= 4 {
+				m.Puzzle.LastMove = []string{p.LastMove[0:2], p.LastMove[2:4]}
+			}
+		}
+	}
+	meta := view.PageMeta("Puzzles")
+	meta.Description = "Octad puzzles from real games: find the move that wins."
+	return view.Render(c, fiber.StatusOK, view.Training(meta, m))
+}
+
+// trainee is the viewer's account id (0 when anonymous) and puzzle rating.
+func trainee(c fiber.Ctx) (int64, rating.Rating) {
+	acct := user.GetAccount(c)
+	if acct == nil {
+		return 0, rating.New()
+	}
+	return acct.ID, db.PuzzleRatingOf(acct.ID)
+}
+
+// TrainingAPIHandler is the trainer's move endpoint: it judges one move at a
+// puzzle and answers for the opponent. A signed-in player's first finished
+// attempt at a puzzle is rated here.
+func TrainingAPIHandler(c fiber.Ctx) error {
+	var req puzzle.Request
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).
+			JSON(fiber.Map{"error": "malformed request"})
+	}
+	if len(req.UOI) > 8 {
+		return c.Status(fiber.StatusBadRequest).
+			JSON(fiber.Map{"error": "malformed request"})
+	}
+
+	userID, _ := trainee(c)
+	resp, err := puzzle.Do(req, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, puzzle.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).
+				JSON(fiber.Map{"error": "no such puzzle"})
+		case errors.Is(err, puzzle.ErrBadRequest):
+			return c.Status(fiber.StatusUnprocessableEntity).
+				JSON(fiber.Map{"error": "invalid move or step"})
+		}
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(fiber.Map{"error": "could not judge that move"})
+	}
+	return c.JSON(resp)
+}
@@ -2,10 +2,12 @@ package www
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/dechristopher/lio/config"
+	"github.com/dechristopher/lio/metrics"
 	"github.com/dechristopher/lio/str"
 	"github.com/dechristopher/lio/sysinfo"
 	"github.com/dechristopher/lio/util"
@@ -51,12 +53,47 @@ func currentStatus() status {
 	}
 }
 
+// writeMetrics renders the Prometheus exposition served at /metrics: the
+// scrape-time gauges projected from a sysinfo sample, exactly as currentStatus
+// projects one for the JSON, followed by every counter and histogram the
+// packages that own them have recorded (package metrics).
+func writeMetrics(w io.Writer) error {
+	s := sysinfo.Sample()
+
+	metrics.WriteGauge(w, "lio_uptime_seconds", "Seconds since the process booted.", s.Uptime.Seconds())
+	metrics.WriteGauge(w, "lio_goroutines", "Live goroutines.", float64(s.Goroutines))
+	metrics.WriteGauge(w, "lio_heap_bytes", "Live heap (MemStats.HeapAlloc).", float64(s.HeapAlloc))
+	metrics.WriteGauge(w, "lio_sys_bytes", "Memory obtained from the OS (MemStats.Sys).", float64(s.Sys))
+	metrics.WriteGauge(w, "lio_gc_cpu_fraction", "Share of process CPU spent in GC since boot.", s.GCCPUFrac)
+	metrics.WriteGauge(w, "lio_move_lag_seconds",
+		"EWMA (~1min) of server move-processing time (lag.Move).", s.MoveLag.Seconds())
+	metrics.WriteGaugeVec(w, "lio_rooms", "Rooms held by this node, by state.", "state", floats(s.RoomStates))
+	metrics.WriteGaugeVec(w, "lio_ws_connections",
+		"Open websocket connections, by channel type.", "channel", floats(s.Connections))
+	metrics.WriteGauge(w, "lio_engine_workers", "Engine worker pool size.", float64(s.Engine.Workers))
+	metrics.WriteGauge(w, "lio_engine_workers_busy", "Engine workers running a request.", float64(s.Engine.Busy))
+	metrics.WriteGauge(w, "lio_engine_workers_lent",
+		"Idle engine workers on loan to a running search as Lazy SMP helpers.", float64(s.Engine.Lent))
+	metrics.WriteGauge(w, "lio_engine_queue_depth", "Engine requests waiting for a worker.", float64(s.Engine.Queued))
+
+	return metrics.Write(w)
+}
+
+func floats(counts map[string]int) map[string]float64 {
+	out := make(map[string]float64, len(counts))
+	for k, v := range counts {
+		out[k] = float64(v)
+	}
+	return out
+}
+
 // serveHealth runs the internal health listener: a bare net/http server on
 // the loopback-only config.GetHealthAddr(), separate from the public fiber
 // app so the status endpoint is never exposed outside the container. It
 // serves GET /lio (the lightweight status JSON above) for `lio --health` to
 // probe from inside the same network namespace — the probe only checks for a
-// 200; the payload detail is for operators (and any future scraper).
+// 200; the payload detail is for operators. GET /metrics serves the same
+// figures and the event counters in Prometheus format for the alerting stack.
 // The listener lives and dies with the process; a bind failure is logged and
 // surfaces as the container going unhealthy, not as a crash.
 func serveHealth() {
@@ -65,6 +102,12 @@ func serveHealth() {
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(currentStatus())
 	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := writeMetrics(w); err != nil {
+			util.Error(str.CMain, "metrics write failed: %s", err.Error())
+		}
+	})
 
 	srv := &http.Server{
 		Addr:         config.GetHealthAddr(),
@@ -0,0 +1,48 @@
+package www
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestWriteMetricsExposition checks that the /metrics body is well-formed
+// exposition text: every family announces its type, and every sample line is
+// a name (with optional labels) followed by a single value.
+func TestWriteMetricsExposition(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeMetrics(&buf); err != nil {
+		t.Fatalf("writeMetrics: %v", err)
+	}
+	out := buf.String()
+
+	for _, family := range []string{
+		"lio_uptime_seconds gauge",
+		"lio_rooms gauge",
+		"lio_ws_connections gauge",
+		"lio_games_started_total counter",
+		"lio_games_finished_total counter",
+		"lio_engine_search_seconds histogram",
+		"lio_engine_search_depth histogram",
+		"lio_dispatch_queue_wait_seconds histogram",
+		"lio_persister_tick_failures_total counter",
+		"lio_archive_commit_seconds histogram",
+		"lio_rating_updates_total counter",
+	} {
+		if !strings.Contains(out, "# TYPE "+family+"\n") {
+			t.Errorf("missing family %q", family)
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.HasPrefix(line, "# HELP ") || strings.HasPrefix(line, "# TYPE ") {
+			continue
+		}
+		if i := strings.LastIndexByte(line, '}'); i >= 0 {
+			line = line[:strings.IndexByte(line, '{')] + line[i+1:]
+		}
+		if fields := strings.Fields(line); len(fields) != 2 || !strings.HasPrefix(fields[0], "lio_") {
+			t.Errorf("malformed sample line %q", line)
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/limiter"
+
+	"github.com/dechristopher/lio/user"
 )
 
 // roomCreateMax is the per-client room-creation budget per window. Creating a
@@ -146,6 +149,37 @@ func CardLimiter() fiber.Handler {
 	})
 }
 
+// tokenMax is the per-token budget for requests authenticated by a personal
+// API token (auth/tokens.go), across every path the token reaches. A script is
+// the only thing that presents one, so unlike the per-IP budgets above this
+// one is sized for a program polling, not a person clicking — and keyed by the
+// token, so a script moving between hosts keeps the same budget and two
+// scripts behind one address do not share it.
+const tokenMax = 120
+
+// tokenWindow is the rolling window tokenMax is measured over.
+const tokenWindow = time.Minute
+
+// TokenLimiter rate-limits personal-token requests per token. Every other
+// request passes straight through, to whatever limiter its route carries.
+func TokenLimiter() fiber.Handler {
+	return limiter.New(limiter.Config{
+		Next: func(c fiber.Ctx) bool {
+			acct := user.GetAccount(c)
+			return acct == nil || acct.TokenID == 0
+		},
+		Max:        tokenMax,
+		Expiration: tokenWindow,
+		KeyGenerator: func(c fiber.Ctx) string {
+			return "token:" + strconv.FormatInt(user.GetAccount(c).TokenID, 10)
+		},
+		LimitReached: func(c fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).
+				JSON(fiber.Map{"error": "too many requests for this token - slow down"})
+		},
+	})
+}
+
 // ClientIP exposes the resolved client address to handlers outside this
 // package (the login rate limiter keys off it).
 func ClientIP(c fiber.Ctx) string {
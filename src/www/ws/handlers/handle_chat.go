@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"github.com/valyala/fastjson"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/chat"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// HandlePlayerChat processes the player chat: a line said to the other seat,
+// or the sender muting (or unmuting) it. The chat package checks the sender
+// really holds a seat; the socket's spectator flag is only the first filter.
+func HandlePlayerChat(m []byte, meta channel.SocketContext) []byte {
+	v, err := fastjson.ParseBytes(m)
+	if err != nil {
+		return nil
+	}
+	if mu := v.Get("d", "mu"); mu != nil {
+		chat.Mute(mu.GetBool(), meta)
+		return nil
+	}
+	return chat.Say(proto.PlayerChatTag, string(v.GetStringBytes("d", "x")), meta)
+}
+
+// HandleSpectatorChat processes a line said in the spectator chat.
+func HandleSpectatorChat(m []byte, meta channel.SocketContext) []byte {
+	return chat.Say(proto.SpectatorChatTag, fastjson.GetString(m, "d", "x"), meta)
+}
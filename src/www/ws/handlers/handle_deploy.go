@@ -33,7 +33,7 @@ func HandleDeploy(m []byte, meta channel.SocketContext) []byte {
 		return nil
 	}
 
-	util.DebugFlag("room", str.CHMov, "[%s] deploy %q received from %s", meta.RoomID, msg.Data.Order, meta.UID)
+	util.With(util.RoomID(meta.RoomID), util.UID(meta.UID)).DebugFlag("room", str.CHMov, "deploy %q received", msg.Data.Order)
 
 	thisRoom.SubmitDeploy(&message.RoomDeploy{
 		Player: meta.UID,
@@ -67,7 +67,7 @@ func HandleMove(m []byte, meta channel.SocketContext) []byte {
 	// from one lost in transit (the client never logs an unconfirmed move).
 	// Pair this with the SendMove drop log to follow a move's full lifecycle
 	// under `--debug room`.
-	util.DebugFlag("room", str.CHMov, "[%s] move %s received from %s", meta.RoomID, msg.Data.UOI, meta.UID)
+	util.With(util.RoomID(meta.RoomID), util.UID(meta.UID)).DebugFlag("room", str.CHMov, "move %s received", msg.Data.UOI)
 
 	// send move to room
 	thisRoom.SendMove(&message.RoomMove{
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/str"
+	"github.com/dechristopher/lio/util"
+	"github.com/dechristopher/lio/www/ws/proto"
+)
+
+// HandlePremove processes premove registrations: a player's premove queue is
+// handed to the room, which validates it, holds it until the opponent's move
+// lands, and acknowledges it back to the player.
+func HandlePremove(m []byte, meta channel.SocketContext) []byte {
+	thisRoom, err := room.Get(meta.RoomID)
+	if err != nil {
+		return nil
+	}
+
+	// spectators never premove; the room's seat check remains behind this
+	if meta.IsSpectator {
+		return nil
+	}
+
+	var msg proto.MessagePremove
+	if err := json.Unmarshal(m, &msg); err != nil {
+		util.Error(str.CHMov, "premove unmarshal error: %s %v", m, err)
+		return nil
+	}
+
+	util.With(util.RoomID(meta.RoomID), util.UID(meta.UID)).DebugFlag("room", str.CHMov, "premoves %+v received", msg.Data.Steps)
+
+	thisRoom.SetPremoves(meta, msg.Data.Steps)
+
+	return nil
+}
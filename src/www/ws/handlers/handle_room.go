@@ -20,7 +20,7 @@ func HandleRoom(m []byte, meta channel.SocketContext) []byte {
 		return nil
 	}
 
-	// rematch / resign / draw are controls, not state queries; read them from
+	// rematch / resign / draw / takeback are controls, not state queries; read them from
 	// the message payload directly (like HandleMove) and hand them to the room.
 	// Spectator frames skip the whole control block — only seated players hold
 	// game controls (the room's own seat checks remain as a second layer) — but
@@ -38,10 +38,18 @@ func HandleRoom(m []byte, meta channel.SocketContext) []byte {
 			thisRoom.RequestDraw(meta)
 			return nil
 		}
+		if fastjson.GetBool(m, "d", "tb") {
+			thisRoom.RequestTakeback(meta)
+			return nil
+		}
 		if fastjson.GetBool(m, "d", "ng") {
 			thisRoom.RequestNextGame(meta)
 			return nil
 		}
+		if fastjson.GetBool(m, "d", "bz") {
+			thisRoom.RequestBerserk(meta)
+			return nil
+		}
 	}
 
 	var msg proto.RoomMessage
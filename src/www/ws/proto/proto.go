@@ -32,6 +32,10 @@ const (
 	RatingUpdateTag PayloadTag = "rc"
 	// DrawOfferTag is the message type tag for the DrawOfferPayload
 	DrawOfferTag PayloadTag = "do"
+	// TakebackTag is the message type tag for the TakebackPayload
+	TakebackTag PayloadTag = "tb"
+	// PremoveTag is the message type tag for the PremovePayload
+	PremoveTag PayloadTag = "pm"
 	// RoomTag is the message type tag for the RoomMessage
 	RoomTag PayloadTag = "r"
 	// RedirectTag is the message type tag for the RedirectMessage
@@ -69,6 +73,23 @@ const (
 	// Addressed to one connection, and accepted on every channel — the card
 	// rides whatever socket its page already holds.
 	WatchTag PayloadTag = "wg"
+	// TournamentTag is the message type tag for the TournamentPayload: an
+	// event's live standings and games, broadcast on its tournament channel.
+	TournamentTag PayloadTag = "tn"
+	// SimulTag is the message type tag for the SimulPayload: a simul's boards
+	// and the host's tally, broadcast on its simul channel.
+	SimulTag PayloadTag = "sm"
+	// MatchTag is the message type tag for the MatchPayload: the matchmaking
+	// queue found this session an opponent. Addressed to one session, and
+	// carried on every channel — a queued player may be on any page.
+	MatchTag PayloadTag = "mm"
+	// PlayerChatTag is the message type tag for the ChatPayload of a room's
+	// player chat: the two seats talking to each other. Inbound, a line said or
+	// a mute toggled; outbound, lines delivered and the backlog on connect.
+	PlayerChatTag PayloadTag = "ch"
+	// SpectatorChatTag is the message type tag for the ChatPayload of a room's
+	// spectator chat, which the seats never see.
+	SpectatorChatTag PayloadTag = "cs"
 )
 
 // Message represents our websocket protocol messages container
@@ -198,6 +219,11 @@ type MovePayload struct {
 	// stale-board heuristics (gs flag + ply monotonicity, which break across
 	// game boundaries). See arch/DEPLOY_REMATCH_RACES.md (follow-up findings).
 	GameID string `json:"i,omitempty"`
+	// Takebacks counts the takebacks granted in this game. A takeback is the
+	// one board state that legitimately moves the ply backwards, so clients
+	// accept a lower ply when the count has risen instead of dropping it as
+	// stale — and drop a snapshot carrying a lower count, which predates one.
+	Takebacks int `json:"tb,omitempty"`
 }
 
 // MessageMove contains a MovePayload message
@@ -375,6 +401,14 @@ type GameOverPayload struct {
 	// analyzing client's copy button copy exactly what was archived — built by
 	// the same game.BuildPGN the archive path uses — instead of re-deriving it.
 	PGN string `json:"pgn,omitempty"`
+	// Tournament is the id of the tournament that paired the game, present
+	// only for a tournament game: there is no rematch, and the client sends
+	// its players back to /tournament/<id> for their next pairing.
+	Tournament string `json:"tn,omitempty"`
+	// Simul is the id of the simul the game is a board of, present only for
+	// a simul board: there is no rematch, and the host's client moves on to
+	// their next board.
+	Simul string `json:"sm,omitempty"`
 }
 
 // RematchUpdatePayload retimes the human rematch-window countdown mid-window
@@ -420,6 +454,48 @@ type DrawOfferPayload struct {
 	Declined bool   `json:"dc,omitempty"` // a standing offer was declined/withdrawn
 }
 
+// TakebackPayload signals takeback-request state to clients during a casual or
+// bot game, the takeback analogue of DrawOfferPayload: a standing request names
+// the requesting player (By) so the opponent's client can surface an "accept
+// takeback" affordance, and Declined reports a request refused — a bot that has
+// granted all the takebacks its persona allows — or withdrawn by a move. The
+// takeback itself arrives as a board state (MovePayload.Takebacks).
+type TakebackPayload struct {
+	By       string `json:"by,omitempty"` // uid of the player who asked for a takeback
+	Declined bool   `json:"dc,omitempty"` // a request was declined/withdrawn
+}
+
+// PremovePayload carries a player's premove queue: moves the room plays for
+// them the instant the opponent's move lands, with no round trip in between.
+// Inbound (client to server) it replaces the queue — one premove, or a
+// conditional line in which every step names the opponent move it answers —
+// and an empty queue cancels. Outbound it acknowledges what the room now holds,
+// addressed to the player alone: after a registration, after each premove is
+// played, and when the queue is discarded (Dropped) because a premove met a
+// move it did not answer or a position it is illegal in.
+type PremovePayload struct {
+	Steps   []PremoveStep `json:"s,omitempty"`
+	Dropped bool          `json:"x,omitempty"` // outbound: the queue was discarded
+	GameID  string        `json:"i,omitempty"` // outbound: the game the queue belongs to
+}
+
+// PremoveStep is one step of a premove queue: the reply Move (UOI) to play
+// when the opponent plays If (UOI). An empty If answers any move, and is only
+// allowed on a queue of one step — a line past an unknown move cannot be
+// checked for legality in advance.
+type PremoveStep struct {
+	If   string `json:"if,omitempty"`
+	Move string `json:"m"`
+}
+
+// MessagePremove contains a PremovePayload message
+type MessagePremove struct {
+	Tag          string         `json:"t"`
+	Data         PremovePayload `json:"d"`
+	Version      int            `json:"v,omitempty"`
+	ProtoVersion int            `json:"pv,omitempty"`
+}
+
 // RoomMessage contains room state data
 type RoomMessage struct {
 	RoomID  string `json:"id,omitempty"`
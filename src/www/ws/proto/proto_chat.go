@@ -0,0 +1,39 @@
+package proto
+
+// The in-game chat frames (the chat package). One payload serves both of a
+// room's channels, told apart by the tag: the player chat between the two
+// seats (PlayerChatTag) and the spectator chat of everybody else
+// (SpectatorChatTag).
+
+// ChatPayload carries chat lines to a connection, or tells its sender why a
+// line was not delivered.
+type ChatPayload struct {
+	// Lines are the lines to append, oldest first.
+	Lines []ChatLine `json:"l,omitempty"`
+	// Backlog marks the catch-up sent when a socket joins the room: the client
+	// replaces what it has rather than appending, so a reconnect never
+	// doubles the log.
+	Backlog bool `json:"b,omitempty"`
+	// Muted is the receiving seat's own mute state, echoed with the backlog
+	// and whenever it toggles, so every tab of the session agrees. Absent on
+	// every other frame, which says nothing about it.
+	Muted *bool `json:"mu,omitempty"`
+	// Notice is addressed to the sender alone: a line withheld by the filter,
+	// a rate limit hit, a sanction in force.
+	Notice string `json:"n,omitempty"`
+}
+
+// ChatLine is one line as a reader sees it.
+type ChatLine struct {
+	From string `json:"u"`
+	Text string `json:"x"`
+}
+
+// ChatMessage builds a chat frame for one of the two chat tags.
+func ChatMessage(tag PayloadTag, p ChatPayload) []byte {
+	msg := Message{
+		Tag:  string(tag),
+		Data: p,
+	}
+	return msg.Please()
+}
@@ -0,0 +1,24 @@
+package proto
+
+// The matchmaking frame: the queue paired this session, and here is the room.
+// Like the live-game frame it is addressed to a session rather than a channel —
+// the player may be waiting on the queue page, the home page or anywhere else
+// that holds a socket — and it is sent to both players the moment their room
+// exists.
+
+// MatchPayload names the room a queued player was paired into.
+type MatchPayload struct {
+	RoomID string `json:"r"`
+	// Opponent is the opponent's display name, for the moment between the
+	// frame arriving and the room page loading.
+	Opponent string `json:"o,omitempty"`
+}
+
+// MatchMessage builds the pairing frame.
+func MatchMessage(p MatchPayload) []byte {
+	msg := Message{
+		Tag:  string(MatchTag),
+		Data: p,
+	}
+	return msg.Please()
+}
@@ -0,0 +1,22 @@
+package proto
+
+import (
+	"github.com/dechristopher/lio/channel"
+)
+
+// Marshal fully JSON marshals the PremovePayload and
+// wraps it in a Message struct
+func (p *PremovePayload) Marshal() []byte {
+	message := Message{
+		Tag:  string(PremoveTag),
+		Data: p,
+	}
+
+	return message.Please()
+}
+
+// Unicast will send a Premove message to every socket the uid within the
+// meta given holds on its channel — a premove queue is nobody else's business
+func (p PremovePayload) Unicast(meta channel.SocketContext) {
+	channel.Unicast(p.Marshal(), meta)
+}
@@ -0,0 +1,49 @@
+package proto
+
+// The simul frame: a simul's whole live state, pushed to everyone on
+// /socket/simul/<id> whenever it changes and once on connect. The host's
+// dashboard is built from it, so it carries every board's clocks and whose
+// move it is; like the tournament frame it is the full picture, not a delta.
+
+// SimulPayload is one simul's live state.
+type SimulPayload struct {
+	ID     string `json:"id"`
+	Status string `json:"s"` // "created" / "started" / "finished"
+	// Next is the room of the next board where it is the host's move, "" when
+	// the host is waiting on every board.
+	Next string `json:"nx,omitempty"`
+	// Wins / Draws / Losses are the host's tally so far.
+	Wins   int          `json:"w"`
+	Draws  int          `json:"d"`
+	Losses int          `json:"l"`
+	Boards []SimulBoard `json:"b"`
+}
+
+// SimulBoard is one participant's board.
+type SimulBoard struct {
+	Name      string `json:"n"`
+	Title     string `json:"t,omitempty"`
+	TitleName string `json:"tn,omitempty"`
+	// RoomID is "" until the simul starts.
+	RoomID string `json:"r,omitempty"`
+	// Result is the board's result from the host's side: "win", "draw",
+	// "loss" or "void"; "" while it is being played.
+	Result string `json:"res,omitempty"`
+	// HostToMove reports it is the host's move on a live board.
+	HostToMove bool `json:"h,omitempty"`
+	// HostClock / PlayerClock are the seats' clocks in centi-seconds; the
+	// host's only runs while it is their move on this board.
+	HostClock   int64 `json:"hc,omitempty"`
+	PlayerClock int64 `json:"pc,omitempty"`
+	Plies       int   `json:"p,omitempty"`
+}
+
+// Marshal wraps the payload in a Message.
+func (s *SimulPayload) Marshal() []byte {
+	message := Message{
+		Tag:  string(SimulTag),
+		Data: s,
+	}
+
+	return message.Please()
+}
@@ -0,0 +1,22 @@
+package proto
+
+import (
+	"github.com/dechristopher/lio/channel"
+)
+
+// Marshal fully JSON marshals the TakebackPayload and
+// wraps it in a Message struct
+func (t *TakebackPayload) Marshal() []byte {
+	message := Message{
+		Tag:  string(TakebackTag),
+		Data: t,
+	}
+
+	return message.Please()
+}
+
+// Broadcast will send a Takeback message to all sockets connected
+// to the channel within the meta given
+func (t TakebackPayload) Broadcast(meta channel.SocketContext) {
+	channel.Broadcast(t.Marshal(), meta)
+}
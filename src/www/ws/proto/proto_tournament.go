@@ -0,0 +1,54 @@
+package proto
+
+// The tournament frame: an event's whole live state, pushed to everyone on
+// /socket/tournament/<id> whenever it changes and once on connect. Like the
+// live-game frame it is the full picture rather than a delta — a standings
+// table is small, and a client that missed a frame is corrected by the next.
+
+// TournamentPayload is one event's live state.
+type TournamentPayload struct {
+	ID     string `json:"id"`
+	Status string `json:"s"` // "created" / "started" / "finished"
+	// Round / Rounds are a Swiss event's progress; zero for an Arena.
+	Round  int `json:"r,omitempty"`
+	Rounds int `json:"rs,omitempty"`
+	// Seconds counts down to the start before it, and to an Arena's end (or
+	// the next Swiss round) while it runs. Zero when there is nothing to count.
+	Seconds   int              `json:"sec,omitempty"`
+	Standings []TournamentRow  `json:"st"`
+	Games     []TournamentGame `json:"g,omitempty"`
+}
+
+// TournamentRow is one standings line.
+type TournamentRow struct {
+	Rank      int     `json:"k"`
+	Name      string  `json:"n"`
+	Title     string  `json:"t,omitempty"`
+	TitleName string  `json:"tn,omitempty"`
+	Rating    int     `json:"rg"`
+	Score     float64 `json:"sc"`
+	Tiebreak  float64 `json:"tb,omitempty"`
+	// Sheet is the score sheet, one character per game (see the tournament
+	// package for the marks).
+	Sheet     string `json:"sh,omitempty"`
+	Fire      bool   `json:"f,omitempty"` // Arena: the next game scores double
+	Playing   bool   `json:"p,omitempty"` // in a tournament game right now
+	Withdrawn bool   `json:"w,omitempty"`
+}
+
+// TournamentGame is one game in progress, linked from the event page.
+type TournamentGame struct {
+	RoomID string `json:"r"`
+	White  string `json:"w"`
+	Black  string `json:"b"`
+}
+
+// Marshal wraps the payload in a Message.
+func (t *TournamentPayload) Marshal() []byte {
+	message := Message{
+		Tag:  string(TournamentTag),
+		Data: t,
+	}
+
+	return message.Please()
+}
@@ -16,7 +16,12 @@ var (
 		proto.MoveTag:   handlers.HandleMove,
 		proto.RoomTag:   handlers.HandleRoom,
 		proto.DeployTag: handlers.HandleDeploy,
-		// accepted on every channel, unlike the three above: the hover card
+		// premove queues, acknowledged to the player alone (room/premove.go)
+		proto.PremoveTag: handlers.HandlePremove,
+		// the room's two chats, both on its game channel only (package chat)
+		proto.PlayerChatTag:    handlers.HandlePlayerChat,
+		proto.SpectatorChatTag: handlers.HandleSpectatorChat,
+		// accepted on every channel, unlike those above: the hover card
 		// rides whatever socket its page already holds (arch/PLAYER_CARD.md)
 		proto.WatchTag: handlers.HandleWatch,
 		proto.OFENTag:  Unimplemented,
@@ -10,14 +10,18 @@ import (
 	"github.com/valyala/fastjson"
 
 	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/chat"
+	"github.com/dechristopher/lio/cluster"
 	"github.com/dechristopher/lio/config"
 	"github.com/dechristopher/lio/db"
 	"github.com/dechristopher/lio/env"
 	"github.com/dechristopher/lio/home"
 	"github.com/dechristopher/lio/notify"
 	"github.com/dechristopher/lio/room"
+	"github.com/dechristopher/lio/simul"
 	"github.com/dechristopher/lio/str"
 	"github.com/dechristopher/lio/title"
+	"github.com/dechristopher/lio/tournament"
 	"github.com/dechristopher/lio/user"
 	"github.com/dechristopher/lio/util"
 	"github.com/dechristopher/lio/www/ws/proto"
@@ -152,11 +156,28 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 		thisChannel = fmt.Sprintf("%s/%s", channelType, thisChannel)
 	}
 
+	// every line about this socket carries its uid, its account when signed
+	// in, and its room when it is a game room's — what /system/log filters by
+	log := util.With(util.UID(uid))
+	if acctInfo.ID != 0 {
+		log = log.With(util.AccountID(acctInfo.ID))
+	}
+	if thisChannel == roomId && !home.IsHome(roomId) && !notify.IsNotify(roomId) {
+		log = log.With(util.RoomID(roomId))
+	}
+
 	// ensure room exists for connection. Two channels are not rooms and bypass
 	// this check: the global TV channel (/socket/home), a site-wide read-only
 	// stream, and the notification channel (/socket/me), which a page opens when
 	// it holds no other socket (arch/NOTIFICATIONS.md).
 	//
+	// A tournament page (/socket/tournament/<id>) is not a room either. It
+	// follows one event's standings, and it is where the event finds its players
+	// to pair them; an event this process no longer runs sends the page to its
+	// permanent URL, which renders the final standings from the archive. A simul
+	// page (/socket/simul/<id>) is the same: the host's dashboard, and where the
+	// simul finds its participants when the host starts it.
+	//
 	// isSpectator is decided once, at connect time: a uid with no seat in the
 	// room (or any TV viewer) is a spectator, and every message it sends is
 	// tagged as such so game-affecting handlers drop it outright. Seat
@@ -164,10 +185,43 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 	// over HTTP before opening the game socket, and rematches flip colors, not
 	// uids — so this never goes stale.
 	isSpectator := true
-	if !home.IsHome(roomId) && !notify.IsNotify(roomId) {
-		thisRoom, err := room.Get(roomId)
+	isTournament := ctx.Params("type") == "tournament"
+	isSimul := ctx.Params("type") == "simul"
+	if isTournament {
+		if tournament.Get(roomId) == nil {
+			location := "/tournament/" + roomId
+			return func(conn *websocket.Conn) {
+				_ = conn.SetWriteDeadline(time.Now().Add(channel.WriteWait))
+				redir := proto.RedirectMessage{Location: location}
+				_ = conn.WriteMessage(websocket.TextMessage, redir.Marshal())
+				_ = conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, "tournament gone"))
+				_ = conn.Close()
+			}
+		}
+	} else if isSimul {
+		if simul.Get(roomId) == nil {
+			location := "/simul/" + roomId
+			return func(conn *websocket.Conn) {
+				_ = conn.SetWriteDeadline(time.Now().Add(channel.WriteWait))
+				redir := proto.RedirectMessage{Location: location}
+				_ = conn.WriteMessage(websocket.TextMessage, redir.Marshal())
+				_ = conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, "simul gone"))
+				_ = conn.Close()
+			}
+		}
+	} else if !home.IsHome(roomId) && !notify.IsNotify(roomId) {
+		// a room another node runs is relayed there whole: that node seats the
+		// socket, and every frame of it is read and written there (package
+		// cluster). A socket the owner's node relayed here is never relayed on.
+		thisRoom, owner := cluster.Locate(roomId)
+		if owner != "" && !cluster.Forwarded(ctx) {
+			log.Info(str.CWS, "relaying %s to node at %s", thisChannel, owner)
+			return cluster.ProxySocket(ctx, owner)
+		}
 		if thisRoom == nil {
-			util.Error(str.CWS, str.EWSConn, err.Error())
+			log.Error(str.CWS, str.EWSConn, room.ErrNoRoom{ID: roomId}.Error())
 			// the room this page is bound to no longer exists — either a finished
 			// match whose live actor has been torn down, or an unplayed waiting
 			// room (an open challenge dropped by a server restart). Complete the
@@ -197,7 +251,7 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 		isSpectator = !thisRoom.IsPlayer(uid)
 	}
 
-	util.Info(str.CWS, str.MWSConn, uid, thisChannel, addr, isSpectator)
+	log.Info(str.CWS, str.MWSConn, thisChannel, addr, isSpectator)
 
 	// return websocket handler injected with values from request context
 	return func(c *websocket.Conn) {
@@ -205,7 +259,7 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 		defer func() {
 			err := recover()
 			if err != nil {
-				util.Error(str.CWS, "[%s @ %s] recovered panicked ws handler: %v", uid, roomId, err)
+				log.Error(str.CWS, "recovered panicked ws handler on %s: %v", thisChannel, err)
 			}
 		}()
 
@@ -234,7 +288,7 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 		if acctInfo.ID != 0 {
 			follows, err := db.FollowingIDs(acctInfo.ID)
 			if err != nil {
-				util.Error(str.CDB, "follow set load failed uid=%s error=%s", uid, err.Error())
+				log.Error(str.CDB, "follow set load failed error=%s", err.Error())
 			}
 			socket.SetFollows(follows)
 		}
@@ -282,8 +336,24 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 			home.Connect(socket)
 		}
 
+		// a tournament page gets the event's standings the same way, then each
+		// change as it happens
+		if isTournament {
+			tournament.Connect(socket, roomId)
+		}
+		if isSimul {
+			simul.Connect(socket, roomId)
+		}
+
+		// a room's game page is caught up with the chat it can read — the
+		// player chat for a seat, the spectator chat otherwise — then gets
+		// each line as it is said. Off this goroutine: it reads the log.
+		if thisChannel == roomId && !isTournament && !isSimul && !home.IsHome(roomId) && !notify.IsNotify(roomId) {
+			go chat.Connect(socket, roomId, isSpectator)
+		}
+
 		// UnTrack this socket and stop its writer when the read loop exits
-		defer killSocket(socket, thisChannel, addr)
+		defer killSocket(log, socket, thisChannel, addr)
 
 		// Bound the size of any single inbound frame (see maxInboundMessage).
 		c.SetReadLimit(maxInboundMessage)
@@ -317,7 +387,7 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 				continue
 			}
 
-			util.DebugFlag("ws", str.CWS, str.DWSRecv, string(b))
+			log.DebugFlag("ws", str.CWS, str.DWSRecv, string(b))
 
 			// pull message tag for routing decision
 			tag := fastjson.GetString(b, "t")
@@ -333,6 +403,8 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 				Channel:     thisChannel,
 				RoomID:      roomId,
 				IsSpectator: isSpectator,
+				SocketID:    connID,
+				Acct:        acctInfo,
 				MT:          mt,
 			})
 
@@ -341,7 +413,7 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 			}
 
 			// queue immediate response if any given
-			util.DebugFlag("ws", str.CWS, str.DWSSend, string(resp))
+			log.DebugFlag("ws", str.CWS, str.DWSSend, string(resp))
 			socket.Enqueue(resp)
 		}
 	}
@@ -349,8 +421,8 @@ func connHandler(ctx fiber.Ctx) func(*websocket.Conn) {
 
 // killSocket untracks the connection and signals its writer goroutine to close
 // the underlying websocket.
-func killSocket(socket *channel.Socket, thisChannel, addr string) {
-	util.Info(str.CWS, str.MWSDisc, socket.UID, thisChannel, addr)
+func killSocket(log util.Logger, socket *channel.Socket, thisChannel, addr string) {
+	log.Info(str.CWS, str.MWSDisc, thisChannel, addr)
 	channel.Map.GetSockMap(thisChannel).UnTrack(socket.UID, socket.ID)
 	socket.Close()
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/dechristopher/lio/assets"
 	"github.com/dechristopher/lio/auth"
 	"github.com/dechristopher/lio/channel"
+	"github.com/dechristopher/lio/cluster"
 	"github.com/dechristopher/lio/config"
 	"github.com/dechristopher/lio/demo"
 	"github.com/dechristopher/lio/env"
@@ -90,13 +91,16 @@ func Serve(static embed.FS) {
 		_ = <-c
 		util.Info(str.CMain, str.MShutdown)
 		// shutdown drain (arch/STATE_PERSISTENCE_SCALING.md): gate inbound
-		// mutations, freeze clocks, flush final room snapshots — then tell
-		// every client this is a restart (1012 Service Restart; the browser
+		// mutations, freeze clocks, flush final room snapshots, and leave the
+		// cluster so another node can adopt the rooms — then tell every
+		// client this is a restart (1012 Service Restart; the browser
 		// surfaces the code in onclose and lio.js reconnects promptly instead
 		// of treating it as a network failure). Fiber's Shutdown does not
-		// touch hijacked websocket connections, so the CloseAll sweep is what
-		// actually releases them.
+		// touch hijacked websocket connections, so the CloseAll sweep (and
+		// Leave, for sockets relayed to another node) is what actually
+		// releases them.
 		room.Drain()
+		cluster.Leave()
 		channel.CloseAll(1012, "server restarting")
 		_ = r.Shutdown()
 	}()
@@ -144,6 +148,10 @@ func wireHandlers(r *fiber.App, staticFs fs.FS) {
 	// request — the unified session system (arch/ACCOUNTS_AUTH_RATINGS.md)
 	r.Use(auth.SessionMiddleware)
 
+	// personal API tokens get a budget of their own, keyed by the token; it
+	// must run after the session middleware, which is what resolves them
+	r.Use(middleware.TokenLimiter())
+
 	// websocket upgrade middleware
 	r.Use("/socket", ws.UpgradeHandler)
 
@@ -195,11 +203,86 @@ func wireHandlers(r *fiber.App, staticFs fs.FS) {
 	r.Get("/learn", handlers.LearnHandler)
 	r.Get("/learn/:slug", handlers.LearnLessonHandler)
 
+	// the puzzle trainer: the next puzzle at the viewer's rating, or a named
+	// one (see package puzzle)
+	r.Get("/training", handlers.TrainingHandler)
+	r.Get("/training/:id", handlers.TrainingPuzzleHandler)
+
 	// paginated news feed page
 	r.Get("/news", handlers.NewsHandler)
 
-	// game database page handler
+	// game database page handler, and its monthly PGN dump downloads (each a
+	// redirect to a short-lived presigned object-store link)
 	r.Get("/db", handlers.DBHandler)
+	r.Get("/db/:month", handlers.DBDumpHandler)
+
+	// the matchmaking queue's waiting page, and leaving it. Entering is the
+	// quick-game POST under /new below.
+	r.Get("/queue", handlers.QueueHandler)
+	r.Post("/queue/cancel", handlers.QueueCancelHandler)
+
+	// tournaments: the list (and a moderator's create form), each event's
+	// page, and entering or leaving one. Entries are POSTs for the same CSRF
+	// reason as room creation below.
+	r.Get("/tournament", handlers.TournamentsHandler)
+	r.Post("/tournament/new", handlers.TournamentCreateHandler)
+	r.Get("/tournament/:id", handlers.TournamentHandler)
+	r.Post("/tournament/:id/join", handlers.TournamentJoinHandler)
+	r.Post("/tournament/:id/withdraw", handlers.TournamentWithdrawHandler)
+
+	// simuls: the list (and the create form), each simul's page — its host's
+	// dashboard — entering or leaving one, the host's start and cancel, and
+	// the host's jump to the next board waiting on their move.
+	r.Get("/simul", handlers.SimulsHandler)
+	r.Post("/simul/new", handlers.SimulCreateHandler)
+	r.Get("/simul/:id", handlers.SimulHandler)
+	r.Post("/simul/:id/join", handlers.SimulJoinHandler)
+	r.Post("/simul/:id/leave", handlers.SimulLeaveHandler)
+	r.Post("/simul/:id/start", handlers.SimulStartHandler)
+	r.Post("/simul/:id/cancel", handlers.SimulCancelHandler)
+	r.Get("/simul/:id/next", handlers.SimulNextHandler)
+
+	// correspondence games: the list (and the create form), each game's page,
+	// and taking or leaving an open game's seat. Creation is limited like room
+	// creation, since a challenge notifies somebody else.
+	r.Get("/correspondence", handlers.CorrespondencesHandler)
+	r.Post("/correspondence/new", middleware.RoomCreateLimiter(), handlers.CorrespondenceCreateHandler)
+	r.Get("/correspondence/:id", handlers.CorrespondenceHandler)
+	r.Post("/correspondence/:id/join", handlers.CorrespondenceJoinHandler)
+	r.Post("/correspondence/:id/withdraw", handlers.CorrespondenceWithdrawHandler)
+
+	// personal API tokens (see handle_tokens.go): the account's list, and
+	// creating and revoking one, limited like the auth endpoints. Before the
+	// /:id/:num wildcard, which would otherwise take "account" for a room.
+	tokenWrites := middleware.AuthAPILimiter()
+	r.Get("/account/tokens", handlers.TokensHandler)
+	r.Post("/account/tokens", tokenWrites, handlers.TokenCreateHandler)
+	r.Post("/account/tokens/:id/revoke", tokenWrites, handlers.TokenRevokeHandler)
+
+	// blocks (see handle_blocks.go): the account's list, and lifting one.
+	// Blocking is /api/block, from the player page.
+	r.Get("/account/blocks", handlers.BlocksHandler)
+	r.Post("/account/blocks/:username/unblock", middleware.AuthAPILimiter(), handlers.UnblockHandler)
+
+	// email (see handle_email.go and package mail): the account's address and
+	// digest settings, and the pages the mailed links open. Everything that
+	// can cause a send is limited like the auth endpoints.
+	mailWrites := middleware.AuthAPILimiter()
+	r.Get("/account/email", handlers.EmailSettingsHandler)
+	r.Post("/account/email/verify", mailWrites, handlers.EmailResendHandler)
+	r.Post("/account/email/digest", handlers.EmailDigestHandler)
+	r.Get("/account/verify", handlers.EmailVerifyHandler)
+	r.Get("/account/forgot", handlers.ForgotHandler)
+	r.Post("/account/forgot", mailWrites, handlers.ForgotSubmitHandler)
+	r.Get("/account/reset", handlers.ResetHandler)
+	r.Post("/account/reset", mailWrites, handlers.ResetSubmitHandler)
+	r.Get("/account/unsubscribe", handlers.UnsubscribeHandler)
+	r.Post("/account/unsubscribe", handlers.UnsubscribeSubmitHandler)
+
+	// bot accounts (see handle_bot.go): the page explaining them, and the
+	// owner's token issue, limited like the auth endpoints it resembles
+	r.Get("/bot", handlers.BotHandler)
+	r.Post("/bot/token", middleware.AuthAPILimiter(), handlers.BotTokenHandler)
 
 	// OpenGraph preview cards (the og:image targets scrapers fetch when a
 	// octad.gg link is shared): the site-wide default card and the per-room
@@ -226,9 +309,13 @@ func wireHandlers(r *fiber.App, staticFs fs.FS) {
 	r.Get("/system/log", handlers.SystemLogHandler)
 	// the audit feed on its own, for the filter form + pager's htmx swaps
 	r.Get("/system/actions", handlers.SystemActionsHandler)
+	// the server log search on its own, for its filter form (admin only)
+	r.Get("/system/logs", handlers.SystemServerLogHandler)
 	// the instance panel on its own, for its self-poll (admin only)
 	r.Get("/system/stats", handlers.SystemStatsHandler)
 	r.Get("/moderation", handlers.ModerationHandler)
+	// a room's chat log, linked from a report naming one of its games
+	r.Get("/moderation/chat/:room", handlers.ModerationChatHandler)
 
 	// the public staff page: who holds the moderation tools. Open to everybody
 	// — moderation here is not anonymous — and reachable from the footer.
@@ -253,17 +340,48 @@ func wireHandlers(r *fiber.App, staticFs fs.FS) {
 	// background evaluator fills them lazily; see ArchiveGameEvalsHandler)
 	r.Get("/api/room/:id/game/:num/evals", handlers.ArchiveGameEvalsHandler)
 
+	// a game's post-game review (package analysis): its state, uncacheable,
+	// and the request for one — rate-limited like analysis, because a review
+	// is a few dozen engine searches
+	r.Get("/api/game/:uuid/analysis", handlers.GameAnalysisHandler)
+	r.Post("/api/game/:uuid/analysis", middleware.AnalysisLimiter(), handlers.GameAnalysisRequestHandler)
+
 	// free-exploration analysis (archive pages + post-game bot analysis): the
 	// study-style seam that applies/evaluates one explored position per
 	// request. Rate-limited because a cache-missing eval is real engine CPU.
 	r.Post("/api/analysis", middleware.AnalysisLimiter(), handlers.AnalysisHandler)
 
+	// the opening explorer: read-only aggregation over the archive's positions
+	// index, for the analysis board's explorer panel and per formation matchup.
+	// Rate-limited alongside analysis — every request is a grouped scan.
+	r.Get("/api/explorer", middleware.AnalysisLimiter(), handlers.ExplorerHandler)
+	r.Get("/api/explorer/matchups", middleware.AnalysisLimiter(), handlers.ExplorerMatchupsHandler)
+
 	// the /learn tutorial's move endpoint: applies and judges one lesson move.
 	// Rate-limited on the same reasoning as analysis — the graduation game's
 	// replies are real (if small) engine searches — but with a budget sized for
 	// somebody working through a lesson rather than stepping a line.
 	r.Post("/api/learn", middleware.LearnLimiter(), handlers.LearnAPIHandler)
 
+	// the trainer's move endpoint. Pure rules work plus one write per finished
+	// puzzle, paced like the tutorial, so it is limited like the tutorial.
+	r.Post("/api/training", middleware.LearnLimiter(), handlers.TrainingAPIHandler)
+
+	// a correspondence game's board: its state for the waiting page's poll, and
+	// one action (a move, resignation or draw offer) per request. Paced like the
+	// tutorial: somebody thinking over a move, not a script.
+	r.Get("/api/correspondence/:id", handlers.CorrespondenceStateHandler)
+	r.Post("/api/correspondence/:id", middleware.LearnLimiter(), handlers.CorrespondenceActionHandler)
+
+	// the bot API: a bot account's challenge inbox and its two answers, for
+	// a program authenticated by its bearer token. Rate-limited like the
+	// account groups; play itself is on the socket.
+	botAPI := r.Group(auth.BotAPIPrefix, middleware.AuthAPILimiter())
+	botAPI.Get("/account", handlers.BotAccountHandler)
+	botAPI.Get("/challenges", handlers.BotChallengesHandler)
+	botAPI.Post("/challenge/:id/accept", handlers.BotAcceptHandler)
+	botAPI.Post("/challenge/:id/decline", handlers.BotDeclineHandler)
+
 	// room handlers. /:id serves the live room while its actor exists and
 	// falls back to the archived match view once it's gone; /:id/:num is the
 	// permanent per-game permalink (1-based match ordinal)